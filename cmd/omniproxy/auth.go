@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+)
+
+// proxyAuthConfig combines proxy authentication settings from a config file
+// with the --auth-htpasswd and --auth-token flags.
+func proxyAuthConfig(base proxyauth.Config, htpasswd string, tokens []string, users proxyauth.UserService) (*proxyauth.Config, error) {
+	cfg := base
+	if htpasswd != "" {
+		cfg.HtpasswdFile = htpasswd
+	}
+	for _, v := range tokens {
+		name, token, ok := strings.Cut(v, "=")
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid auth token %q: expected name=token", v)
+		}
+		cfg.Tokens = append(cfg.Tokens, proxyauth.Token{Name: name, Token: token})
+	}
+	cfg.Users = users
+	return &cfg, nil
+}
+
+// describeProxyAuth summarizes the credential sources of cfg.
+func describeProxyAuth(cfg *proxyauth.Config) string {
+	var sources []string
+	if cfg.HtpasswdFile != "" {
+		sources = append(sources, "htpasswd "+cfg.HtpasswdFile)
+	}
+	if len(cfg.Tokens) > 0 {
+		sources = append(sources, fmt.Sprintf("%d tokens", len(cfg.Tokens)))
+	}
+	if cfg.Users != nil {
+		sources = append(sources, "database users")
+	}
+	return strings.Join(sources, ", ")
+}
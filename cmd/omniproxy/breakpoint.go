@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grokify/omniproxy/pkg/breakpoint"
+	"github.com/grokify/omniproxy/pkg/daemon"
+	"github.com/spf13/cobra"
+)
+
+func newDaemonBreakpointCmd() *cobra.Command {
+	var socketPath string
+
+	cmd := &cobra.Command{
+		Use:     "breakpoint",
+		Aliases: []string{"bp"},
+		Short:   "Pause, edit and resume requests in the running daemon",
+		Long: `Manage breakpoints in the running daemon.
+
+A breakpoint pauses matching requests (before they are forwarded) or
+responses (before they are returned to the client). Paused items can be
+inspected, edited, resumed or dropped. Items that are not handled within
+the daemon's --breakpoint-timeout continue unchanged.
+
+Examples:
+  # Pause POST requests to the API
+  omniproxy daemon breakpoint add --host api.example.com --method POST
+
+  # Pause responses instead of requests
+  omniproxy daemon breakpoint add --path "/v1/users/*" --response
+
+  # List and inspect paused items
+  omniproxy daemon breakpoint pending
+  omniproxy daemon breakpoint show 1
+
+  # Edit and resume, or drop
+  omniproxy daemon breakpoint resume 1 --set-header X-Debug=1 --body '{"id":2}'
+  omniproxy daemon breakpoint drop 2`,
+	}
+
+	cmd.PersistentFlags().StringVar(&socketPath, "socket", daemon.DefaultSocketPath, "Unix socket path")
+	client := func() *daemon.Client { return daemon.NewClient(socketPath) }
+
+	cmd.AddCommand(
+		newBreakpointAddCmd(client),
+		newBreakpointListCmd(client),
+		newBreakpointRemoveCmd(client),
+		newBreakpointPendingCmd(client),
+		newBreakpointShowCmd(client),
+		newBreakpointResumeCmd(client),
+		newBreakpointDropCmd(client),
+	)
+
+	return cmd
+}
+
+func newBreakpointAddCmd(client func() *daemon.Client) *cobra.Command {
+	var rule breakpoint.Rule
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a breakpoint rule",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			added, err := client().AddBreakpoint(rule)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added breakpoint %s\n", added.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rule.ID, "id", "", "Breakpoint ID (default: generated)")
+	cmd.Flags().StringSliceVar(&rule.Hosts, "host", nil, "Hosts to match (supports wildcards)")
+	cmd.Flags().StringSliceVar(&rule.Paths, "path", nil, "Paths to match (supports wildcards)")
+	cmd.Flags().StringSliceVar(&rule.Methods, "method", nil, "Methods to match")
+	cmd.Flags().BoolVar(&rule.Request, "request", false, "Pause requests (default unless --response is set)")
+	cmd.Flags().BoolVar(&rule.Response, "response", false, "Pause responses")
+
+	return cmd
+}
+
+func newBreakpointListCmd(client func() *daemon.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List breakpoint rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules, err := client().ListBreakpoints()
+			if err != nil {
+				return err
+			}
+			if len(rules) == 0 {
+				fmt.Println("No breakpoints")
+				return nil
+			}
+			for _, r := range rules {
+				var phases []string
+				if r.Request {
+					phases = append(phases, breakpoint.PhaseRequest)
+				}
+				if r.Response {
+					phases = append(phases, breakpoint.PhaseResponse)
+				}
+				fmt.Printf("%-8s %-17s hosts=%s paths=%s methods=%s\n", r.ID, strings.Join(phases, ","),
+					listOrAny(r.Hosts), listOrAny(r.Paths), listOrAny(r.Methods))
+			}
+			return nil
+		},
+	}
+}
+
+func newBreakpointRemoveCmd(client func() *daemon.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a breakpoint rule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := client().RemoveBreakpoint(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed breakpoint %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newBreakpointPendingCmd(client func() *daemon.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List paused requests and responses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pending, err := client().ListPending()
+			if err != nil {
+				return err
+			}
+			if len(pending) == 0 {
+				fmt.Println("Nothing paused")
+				return nil
+			}
+			for _, p := range pending {
+				status := ""
+				if p.Phase == breakpoint.PhaseResponse {
+					status = fmt.Sprintf(" -> %d", p.Status)
+				}
+				remaining := time.Until(p.ExpiresAt).Round(time.Second)
+				fmt.Printf("%-4s %-8s %s %s%s (continues in %s)\n", p.ID, p.Phase, p.Method, p.URL, status, remaining)
+			}
+			return nil
+		},
+	}
+}
+
+func newBreakpointShowCmd(client func() *daemon.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a paused request or response as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := client().GetPending(args[0])
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(p)
+		},
+	}
+}
+
+type breakpointResumeOptions struct {
+	method       string
+	url          string
+	status       int
+	setHeaders   []string
+	removeHeader []string
+	body         string
+	bodyFile     string
+}
+
+func newBreakpointResumeCmd(client func() *daemon.Client) *cobra.Command {
+	opts := &breakpointResumeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "resume <id>",
+		Short: "Resume a paused request or response, optionally editing it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := client()
+			edit, err := buildBreakpointEdit(c, args[0], opts)
+			if err != nil {
+				return err
+			}
+			if err := c.Resume(args[0], edit); err != nil {
+				return err
+			}
+			fmt.Printf("Resumed %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.method, "method", "", "Replace the request method")
+	cmd.Flags().StringVar(&opts.url, "url", "", "Replace the request URL")
+	cmd.Flags().IntVar(&opts.status, "status", 0, "Replace the response status code")
+	cmd.Flags().StringArrayVar(&opts.setHeaders, "set-header", nil, "Set a header (key=value)")
+	cmd.Flags().StringArrayVar(&opts.removeHeader, "remove-header", nil, "Remove a header")
+	cmd.Flags().StringVar(&opts.body, "body", "", "Replace the body")
+	cmd.Flags().StringVar(&opts.bodyFile, "body-file", "", "Replace the body with the contents of a file")
+
+	return cmd
+}
+
+// buildBreakpointEdit builds the edit for resume. Header changes are applied
+// to the paused item's current headers. It returns nil when nothing changes.
+func buildBreakpointEdit(c *daemon.Client, id string, opts *breakpointResumeOptions) (*breakpoint.Edit, error) {
+	edit := &breakpoint.Edit{
+		Method: opts.method,
+		URL:    opts.url,
+		Status: opts.status,
+	}
+	changed := opts.method != "" || opts.url != "" || opts.status != 0
+
+	if len(opts.setHeaders) > 0 || len(opts.removeHeader) > 0 {
+		p, err := c.GetPending(id)
+		if err != nil {
+			return nil, err
+		}
+		edit.Headers = p.Headers.Clone()
+		if edit.Headers == nil {
+			edit.Headers = make(http.Header)
+		}
+		for _, name := range opts.removeHeader {
+			edit.Headers.Del(name)
+		}
+		for _, h := range opts.setHeaders {
+			key, value, err := parseHeader(h)
+			if err != nil {
+				return nil, fmt.Errorf("invalid header %q: %w", h, err)
+			}
+			edit.Headers.Set(key, value)
+		}
+		changed = true
+	}
+
+	switch {
+	case opts.bodyFile != "":
+		data, err := os.ReadFile(opts.bodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body file: %w", err)
+		}
+		body := string(data)
+		if !utf8.Valid(data) {
+			body = base64.StdEncoding.EncodeToString(data)
+			edit.BodyEncoding = breakpoint.EncodingBase64
+		}
+		edit.Body = &body
+		changed = true
+	case opts.body != "":
+		edit.Body = &opts.body
+		changed = true
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return edit, nil
+}
+
+func newBreakpointDropCmd(client func() *daemon.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "drop <id>",
+		Short: "Drop a paused request or response (the client receives a 502)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := client().Drop(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Dropped %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// listOrAny formats a match list, using * for an empty list.
+func listOrAny(values []string) string {
+	if len(values) == 0 {
+		return "*"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/ca"
+	"github.com/grokify/omniproxy/pkg/observability"
+)
+
+// setupCertCache creates the certificate cache for intercepted hosts. Proxies
+// sharing a redis cache also share a CA, so the returned CA replaces
+// authority; the cache is added to the readiness checks.
+func setupCertCache(ctx context.Context, cfg *backend.CertCacheConfig, authority *ca.CA, health *observability.HealthChecker) (backend.CertCache, *ca.CA, error) {
+	cache, err := backend.NewCertCache(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup certificate cache: %w", err)
+	}
+
+	shared, ok := cache.(*backend.RedisCertCache)
+	if !ok {
+		return cache, authority, nil
+	}
+	if health != nil {
+		health.RegisterCheck("redis", observability.CommonChecks{}.RedisCheck(shared.Ping))
+	}
+	if authority == nil {
+		return cache, nil, nil
+	}
+
+	certPEM, keyPEM, err := shared.ShareCA(ctx, authority.CertPEM(), authority.KeyPEM())
+	if err != nil {
+		_ = cache.Close()
+		return nil, nil, err
+	}
+	if bytes.Equal(certPEM, authority.CertPEM()) {
+		return cache, authority, nil
+	}
+
+	sharedCA, err := ca.LoadFromPEM(certPEM, keyPEM)
+	if err != nil {
+		_ = cache.Close()
+		return nil, nil, fmt.Errorf("failed to load shared CA: %w", err)
+	}
+	if !sharedCA.Certificate.Equal(authority.Certificate) {
+		fmt.Fprintf(os.Stderr, "Warning: using the CA shared through Redis (%s, serial %s) instead of the local CA\n",
+			sharedCA.Certificate.Subject.CommonName, sharedCA.Certificate.SerialNumber)
+	}
+	return cache, sharedCA, nil
+}
@@ -9,11 +9,17 @@ import (
 	"time"
 
 	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/breakpoint"
 	"github.com/grokify/omniproxy/pkg/ca"
 	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/pkg/daemon"
+	"github.com/grokify/omniproxy/pkg/netsim"
 	"github.com/grokify/omniproxy/pkg/observability"
+	"github.com/grokify/omniproxy/pkg/protodecode"
 	"github.com/grokify/omniproxy/pkg/proxy"
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/grokify/omniproxy/ui/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -25,17 +31,24 @@ type daemonOptions struct {
 	logFile    string
 
 	// Proxy options (same as serve)
-	port         int
-	host         string
-	verbose      bool
-	enableMITM   bool
-	caPath       string
-	keyPath      string
-	output       string
-	format       string
-	skipHosts    []string
-	filterHeader []string
-	skipBinary   bool
+	port       int
+	host       string
+	verbose    bool
+	enableMITM bool
+	caPath     string
+	keyPath    string
+	output     string
+	format     string
+	skipHosts  []string
+	redact     redactOptions
+	skipBinary bool
+
+	certCache     string
+	certCacheSize int
+	certCacheTTL  time.Duration
+	certCacheURL  string
+
+	protoDescriptors []string
 
 	includeHosts   []string
 	excludeHosts   []string
@@ -43,17 +56,31 @@ type daemonOptions struct {
 	excludePaths   []string
 	includeMethods []string
 	excludeMethods []string
+	filter         string
 
 	upstream string
 
 	metricsPort   int
 	enableMetrics bool
 
-	db string
+	db          string
+	trafficSink string
+
+	retentionInterval time.Duration
+	stripBodiesAfter  time.Duration
 
 	asyncQueue     int
 	asyncBatchSize int
 	asyncWorkers   int
+
+	breakpointTimeout time.Duration
+
+	networkProfile string
+	networkHosts   []string
+
+	authHtpasswd string
+	authTokens   []string
+	authUsers    bool
 }
 
 func newDaemonCmd() *cobra.Command {
@@ -85,6 +112,8 @@ Examples:
 		newDaemonStopCmd(),
 		newDaemonStatusCmd(),
 		newDaemonReloadCmd(),
+		newDaemonBreakpointCmd(),
+		newDaemonNetworkCmd(),
 	)
 
 	return cmd
@@ -102,7 +131,7 @@ By default, the daemon starts in the background. Use --foreground to run
 in the foreground (useful for debugging or when managed by systemd).
 
 The daemon exposes a Unix socket API at ~/.omniproxy/omniproxyd.sock
-for control operations (status, stop, reload).`,
+for control operations (status, stop, reload, breakpoints, network).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDaemonStart(opts)
 		},
@@ -125,16 +154,22 @@ for control operations (status, stop, reload).`,
 
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file for captured traffic")
 	cmd.Flags().StringVar(&opts.format, "format", "ndjson", "Output format: ndjson, json, har, ir")
-	cmd.Flags().StringSliceVar(&opts.filterHeader, "filter-header", nil, "Headers to filter")
+	opts.redact.addFlags(cmd)
 	cmd.Flags().BoolVar(&opts.skipBinary, "skip-binary", true, "Skip binary content")
+	cmd.Flags().StringSliceVar(&opts.protoDescriptors, "proto-descriptor", nil, "FileDescriptorSet files for decoding gRPC messages")
 
 	cmd.Flags().StringSliceVar(&opts.skipHosts, "skip-host", nil, "Hosts to skip MITM for")
+	cmd.Flags().StringVar(&opts.certCache, "cert-cache", "lru", "Certificate cache: lru, memory, redis")
+	cmd.Flags().IntVar(&opts.certCacheSize, "cert-cache-size", 1000, "Maximum certificates held by the lru cache")
+	cmd.Flags().DurationVar(&opts.certCacheTTL, "cert-cache-ttl", time.Hour, "How long the memory or redis cache keeps certificates")
+	cmd.Flags().StringVar(&opts.certCacheURL, "cert-cache-url", "", "Redis URL for the redis cache")
 	cmd.Flags().StringSliceVar(&opts.includeHosts, "include-host", nil, "Only capture these hosts")
 	cmd.Flags().StringSliceVar(&opts.excludeHosts, "exclude-host", nil, "Exclude these hosts")
 	cmd.Flags().StringSliceVar(&opts.includePaths, "include-path", nil, "Only capture these paths")
 	cmd.Flags().StringSliceVar(&opts.excludePaths, "exclude-path", nil, "Exclude these paths")
 	cmd.Flags().StringSliceVar(&opts.includeMethods, "include-method", nil, "Only capture these methods")
 	cmd.Flags().StringSliceVar(&opts.excludeMethods, "exclude-method", nil, "Exclude these methods")
+	cmd.Flags().StringVar(&opts.filter, "filter", "", "Only capture traffic matching this expression")
 
 	cmd.Flags().StringVar(&opts.upstream, "upstream", "", "Upstream proxy URL")
 
@@ -142,11 +177,23 @@ for control operations (status, stop, reload).`,
 	cmd.Flags().BoolVar(&opts.enableMetrics, "metrics", true, "Enable Prometheus metrics")
 
 	cmd.Flags().StringVar(&opts.db, "db", "", "Database URL (sqlite://... or postgres://...)")
+	cmd.Flags().StringVar(&opts.trafficSink, "traffic-sink", "", "Traffic sink URL (kafka://..., clickhouse://... or a database URL)")
+	cmd.Flags().DurationVar(&opts.retentionInterval, "retention-interval", time.Hour, "How often to roll up and prune database traffic (0 = disabled)")
+	cmd.Flags().DurationVar(&opts.stripBodiesAfter, "strip-bodies-after", 0, "Remove bodies from database traffic older than this")
 
 	cmd.Flags().IntVar(&opts.asyncQueue, "async-queue", 10000, "Async queue size")
 	cmd.Flags().IntVar(&opts.asyncBatchSize, "async-batch", 100, "Async batch size")
 	cmd.Flags().IntVar(&opts.asyncWorkers, "async-workers", 2, "Async workers")
 
+	cmd.Flags().DurationVar(&opts.breakpointTimeout, "breakpoint-timeout", breakpoint.DefaultConfig().Timeout, "Time a paused request waits before continuing unchanged")
+
+	cmd.Flags().StringVar(&opts.networkProfile, "network-profile", "", "Network profile for all traffic (switchable with 'daemon network set')")
+	cmd.Flags().StringArrayVar(&opts.networkHosts, "network-host", nil, "Network profile for matching hosts (host=profile)")
+
+	cmd.Flags().StringVar(&opts.authHtpasswd, "auth-htpasswd", "", "Require Basic proxy credentials from this htpasswd file")
+	cmd.Flags().StringArrayVar(&opts.authTokens, "auth-token", nil, "Accept this bearer token (name=token)")
+	cmd.Flags().BoolVar(&opts.authUsers, "auth-users", false, "Accept users and sessions from the database (requires --db)")
+
 	return cmd
 }
 
@@ -325,6 +372,15 @@ func buildDaemonArgs(opts *daemonOptions) []string {
 	if opts.db != "" {
 		args = append(args, "--db", opts.db)
 	}
+	if opts.trafficSink != "" {
+		args = append(args, "--traffic-sink", opts.trafficSink)
+	}
+	if opts.retentionInterval != time.Hour {
+		args = append(args, "--retention-interval", opts.retentionInterval.String())
+	}
+	if opts.stripBodiesAfter > 0 {
+		args = append(args, "--strip-bodies-after", opts.stripBodiesAfter.String())
+	}
 	if opts.upstream != "" {
 		args = append(args, "--upstream", opts.upstream)
 	}
@@ -334,10 +390,43 @@ func buildDaemonArgs(opts *daemonOptions) []string {
 	if opts.enableMetrics {
 		args = append(args, "--metrics")
 	}
+	if opts.breakpointTimeout > 0 {
+		args = append(args, "--breakpoint-timeout", opts.breakpointTimeout.String())
+	}
+	if opts.networkProfile != "" {
+		args = append(args, "--network-profile", opts.networkProfile)
+	}
+	for _, h := range opts.networkHosts {
+		args = append(args, "--network-host", h)
+	}
+	if opts.authHtpasswd != "" {
+		args = append(args, "--auth-htpasswd", opts.authHtpasswd)
+	}
+	for _, t := range opts.authTokens {
+		args = append(args, "--auth-token", t)
+	}
+	if opts.authUsers {
+		args = append(args, "--auth-users")
+	}
+	for _, d := range opts.protoDescriptors {
+		args = append(args, "--proto-descriptor", d)
+	}
 
 	for _, h := range opts.skipHosts {
 		args = append(args, "--skip-host", h)
 	}
+	if opts.certCache != "lru" {
+		args = append(args, "--cert-cache", opts.certCache)
+	}
+	if opts.certCacheSize != 1000 {
+		args = append(args, "--cert-cache-size", fmt.Sprintf("%d", opts.certCacheSize))
+	}
+	if opts.certCacheTTL != time.Hour {
+		args = append(args, "--cert-cache-ttl", opts.certCacheTTL.String())
+	}
+	if opts.certCacheURL != "" {
+		args = append(args, "--cert-cache-url", opts.certCacheURL)
+	}
 	for _, h := range opts.includeHosts {
 		args = append(args, "--include-host", h)
 	}
@@ -356,6 +445,9 @@ func buildDaemonArgs(opts *daemonOptions) []string {
 	for _, m := range opts.excludeMethods {
 		args = append(args, "--exclude-method", m)
 	}
+	if opts.filter != "" {
+		args = append(args, "--filter", opts.filter)
+	}
 
 	return args
 }
@@ -416,14 +508,30 @@ func runDaemonForeground(opts *daemonOptions) error {
 	if err := filter.Compile(); err != nil {
 		return fmt.Errorf("failed to compile filter: %w", err)
 	}
+	if opts.filter != "" {
+		q, err := query.Parse(opts.filter)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+		filter.Predicate = q.Match
+	}
 
 	// Setup capturer
 	capturerCfg := capture.DefaultConfig()
 	capturerCfg.Filter = filter
 	capturerCfg.SkipBinary = opts.skipBinary
 
-	if len(opts.filterHeader) > 0 {
-		capturerCfg.FilterHeaders = append(capturerCfg.FilterHeaders, opts.filterHeader...)
+	capturerCfg.Redactor, err = opts.redact.redactor(nil)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.protoDescriptors) > 0 {
+		protos, err := protodecode.Load(opts.protoDescriptors...)
+		if err != nil {
+			return err
+		}
+		capturerCfg.Protos = protos
 	}
 
 	capturer := capture.NewCapturer(capturerCfg)
@@ -431,14 +539,46 @@ func runDaemonForeground(opts *daemonOptions) error {
 	// Setup traffic store
 	var trafficStore backend.TrafficStore
 	var trafficQuerier backend.TrafficQuerier
+	var retention *backend.RetentionWorker
 	var backendMetrics backend.Metrics
+	var authUsers proxyauth.UserService
 
 	if obs != nil {
 		backendMetrics = observability.NewBackendMetrics(obs.Metrics)
 	}
 
+	sinkCfg, dbURL, err := resolveTrafficSink(opts.trafficSink, opts.db)
+	if err != nil {
+		return err
+	}
+	opts.db = dbURL
+
 	dbDisplay := "none"
-	if opts.db != "" {
+	if sinkCfg != nil {
+		sinkStore, err := setupSinkStore(ctx, sinkCfg, backendMetrics, health)
+		if err != nil {
+			return err
+		}
+		dbDisplay = sinkCfg.String()
+
+		// ClickHouse answers traffic queries via the daemon API
+		if querier, ok := sinkStore.(backend.TrafficQuerier); ok {
+			trafficQuerier = querier
+		}
+
+		trafficStore = backend.NewAsyncTrafficStore(sinkStore, &backend.AsyncConfig{
+			QueueSize: opts.asyncQueue,
+			BatchSize: opts.asyncBatchSize,
+			Workers:   opts.asyncWorkers,
+			Metrics:   backendMetrics,
+		})
+
+		capturer.AddHandler(func(rec *capture.Record) {
+			if err := trafficStore.Store(context.Background(), rec); err != nil {
+				fmt.Fprintf(os.Stderr, "traffic store error: %v\n", err)
+			}
+		})
+	} else if opts.db != "" {
 		dbCfg, err := backend.ParseDatabaseURL(opts.db)
 		if err != nil {
 			return fmt.Errorf("invalid database URL: %w", err)
@@ -458,6 +598,12 @@ func runDaemonForeground(opts *daemonOptions) error {
 		// Store reference for traffic querying via daemon API
 		trafficQuerier = dbStore
 
+		retention = startRetention(dbStore, opts.retentionInterval, opts.stripBodiesAfter, backendMetrics)
+
+		if opts.authUsers {
+			authUsers = auth.NewService(dbStore.Client(), nil)
+		}
+
 		trafficStore = backend.NewAsyncTrafficStore(dbStore, &backend.AsyncConfig{
 			QueueSize: opts.asyncQueue,
 			BatchSize: opts.asyncBatchSize,
@@ -472,15 +618,67 @@ func runDaemonForeground(opts *daemonOptions) error {
 		})
 	}
 
+	// Setup proxy authentication
+	if opts.authUsers && authUsers == nil {
+		return fmt.Errorf("--auth-users requires --db")
+	}
+	authCfg, err := proxyAuthConfig(proxyauth.Config{}, opts.authHtpasswd, opts.authTokens, authUsers)
+	if err != nil {
+		return err
+	}
+	var authenticator *proxyauth.Authenticator
+	if authCfg.Enabled() {
+		authenticator, err = proxyauth.New(authCfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup proxy authentication: %w", err)
+		}
+	}
+
+	// Setup breakpoints (rules are added through the control API)
+	breakpoints := breakpoint.New(&breakpoint.Config{Timeout: opts.breakpointTimeout})
+
+	// Setup network simulation (profiles can be switched through the control API)
+	networkCfg, err := networkConfig(netsim.Config{}, opts.networkProfile, opts.networkHosts)
+	if err != nil {
+		return err
+	}
+	network, err := netsim.New(networkCfg)
+	if err != nil {
+		return fmt.Errorf("invalid network settings: %w", err)
+	}
+
+	// Setup the certificate cache for intercepted hosts
+	certCache, proxyCA, err := setupCertCache(ctx, &backend.CertCacheConfig{
+		Type:    backend.CertCacheType(opts.certCache),
+		Size:    opts.certCacheSize,
+		TTL:     opts.certCacheTTL,
+		URL:     opts.certCacheURL,
+		Metrics: backendMetrics,
+	}, proxyCA, health)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = certCache.Close() }()
+
+	var proxyMetrics *observability.Metrics
+	if obs != nil {
+		proxyMetrics = obs.Metrics
+	}
+
 	// Setup proxy
 	proxyCfg := &proxy.Config{
-		Port:       opts.port,
-		Verbose:    opts.verbose,
-		EnableMITM: opts.enableMITM,
-		CA:         proxyCA,
-		Capturer:   capturer,
-		SkipHosts:  opts.skipHosts,
-		Upstream:   opts.upstream,
+		Port:        opts.port,
+		Verbose:     opts.verbose,
+		EnableMITM:  opts.enableMITM,
+		CA:          proxyCA,
+		Capturer:    capturer,
+		SkipHosts:   opts.skipHosts,
+		Upstream:    opts.upstream,
+		Breakpoints: breakpoints,
+		Network:     network,
+		Auth:        authenticator,
+		CertCache:   certCache,
+		Metrics:     proxyMetrics,
 	}
 
 	p, err := proxy.New(proxyCfg)
@@ -505,6 +703,11 @@ func runDaemonForeground(opts *daemonOptions) error {
 	if trafficQuerier != nil {
 		d.SetTrafficQuerier(trafficQuerier)
 	}
+	d.SetBreakpoints(breakpoints)
+	d.SetNetwork(network)
+
+	// Push captured records to /traffic/stream clients
+	capturer.AddHandler(d.Publish)
 
 	// Set callbacks
 	var proxyErrCh chan error
@@ -520,6 +723,9 @@ func runDaemonForeground(opts *daemonOptions) error {
 		},
 		func() error {
 			// onStop - stop proxy
+			if retention != nil {
+				retention.Stop()
+			}
 			if trafficStore != nil {
 				if asyncStore, ok := trafficStore.(backend.AsyncTrafficStore); ok {
 					asyncStore.Flush(context.Background())
@@ -546,7 +752,9 @@ func runDaemonForeground(opts *daemonOptions) error {
 	}
 	fmt.Printf("  Socket:  %s\n", opts.socketPath)
 	fmt.Printf("  PID:     %s\n", opts.pidFile)
-	if opts.db != "" {
+	if sinkCfg != nil {
+		fmt.Printf("  Sink:    %s\n", dbDisplay)
+	} else if opts.db != "" {
 		fmt.Printf("  Database: %s\n", dbDisplay)
 	}
 
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the traffic database",
+		Long:  `Manage the SQLite or PostgreSQL traffic database.`,
+	}
+
+	cmd.AddCommand(
+		newDBPruneCmd(),
+	)
+
+	return cmd
+}
+
+type dbPruneOptions struct {
+	db               string
+	batchSize        int
+	stripBodiesAfter time.Duration
+	archiveDir       string
+}
+
+func newDBPruneCmd() *cobra.Command {
+	opts := &dbPruneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Apply traffic retention now",
+		Long: `Apply traffic retention once.
+
+Complete hours of traffic are rolled up into hourly per-host aggregates,
+then traffic older than each org's traffic_retention_days is deleted in
+batches. Statistics keep covering deleted traffic through the rollups.
+'serve' and 'daemon' do the same every --retention-interval.`,
+		Example: `  omniproxy db prune --db sqlite://traffic.db
+  omniproxy db prune --db postgres://... --strip-bodies-after 72h --archive-dir ./archive`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDBPrune(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.db, "db", "", "Database URL (sqlite://path or postgres://...)")
+	cmd.Flags().IntVar(&opts.batchSize, "batch-size", 1000, "Rows deleted or updated per statement")
+	cmd.Flags().DurationVar(&opts.stripBodiesAfter, "strip-bodies-after", 0, "Remove request and response bodies from traffic older than this (0 = keep with the rows)")
+	cmd.Flags().StringVar(&opts.archiveDir, "archive-dir", "", "Write expired traffic to NDJSON files in this directory before deleting it")
+	_ = cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func runDBPrune(opts *dbPruneOptions) error {
+	ctx := context.Background()
+
+	store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{
+		DatabaseURL: opts.db,
+		ProxyName:   "default",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer store.Close()
+
+	worker := backend.NewRetentionWorker(store, &backend.RetentionConfig{
+		BatchSize:     opts.batchSize,
+		BodyRetention: opts.stripBodiesAfter,
+		ArchiveDir:    opts.archiveDir,
+	})
+
+	result, err := worker.RunOnce(ctx)
+	if result != nil {
+		fmt.Printf("Rolled up: %d\n", result.RolledUp)
+		fmt.Printf("Stripped:  %d\n", result.Stripped)
+		if opts.archiveDir != "" {
+			fmt.Printf("Archived:  %d\n", result.Archived)
+		}
+		fmt.Printf("Deleted:   %d\n", result.Deleted)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune traffic: %w", err)
+	}
+
+	return nil
+}
+
+// startRetention starts the retention worker for a database store, or
+// returns nil if interval is zero.
+func startRetention(store *backend.DatabaseTrafficStore, interval, stripBodiesAfter time.Duration, metrics backend.Metrics) *backend.RetentionWorker {
+	if interval <= 0 {
+		return nil
+	}
+
+	cfg := &backend.RetentionConfig{
+		Interval:      interval,
+		BodyRetention: stripBodiesAfter,
+		ErrorHandler: func(err error) {
+			fmt.Fprintf(os.Stderr, "traffic retention error: %v\n", err)
+		},
+	}
+	if m, ok := metrics.(backend.RetentionMetrics); ok {
+		cfg.Metrics = m
+	}
+
+	worker := backend.NewRetentionWorker(store, cfg)
+	worker.Start()
+	return worker
+}
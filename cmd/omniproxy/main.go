@@ -28,10 +28,14 @@ It supports:
 	rootCmd.AddCommand(
 		newServeCmd(),
 		newReverseCmd(),
+		newReplayCmd(),
+		newMockCmd(),
 		newDaemonCmd(),
 		newCACmd(),
 		newSystemCmd(),
 		newConfigCmd(),
+		newDBCmd(),
+		newTrafficCmd(),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
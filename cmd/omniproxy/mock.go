@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/mock"
+	"github.com/spf13/cobra"
+)
+
+type mockOptions struct {
+	port    int
+	host    string
+	verbose bool
+
+	// Sources
+	db    string
+	limit int
+
+	// Selection
+	includeHosts []string
+	includePaths []string
+
+	// Matching
+	matchHost      bool
+	matchQuery     string
+	ignoreQuery    []string
+	matchBody      bool
+	notFoundStatus int
+}
+
+func newMockCmd() *cobra.Command {
+	opts := &mockOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "mock [file...]",
+		Short: "Serve responses from captured traffic",
+		Long: `Start a mock server that answers requests with recorded responses.
+
+Recordings are read from HAR or NDJSON capture files, or from a database with
+--db. Requests are matched by method, path and query string, and optionally by
+host and body. Repeated requests step through matching recordings in capture
+order and then keep returning the last one. Unmatched requests receive
+--not-found-status (404 by default).
+
+To mock inside the forward proxy instead, use: omniproxy serve --mock FILE
+
+Examples:
+  # Serve a recorded backend on port 8081
+  omniproxy mock traffic.ndjson
+
+  # Serve only one API from a HAR file, ignoring a cache-busting parameter
+  omniproxy mock traffic.har --include-host api.example.com --ignore-query _
+
+  # Serve recordings from the database, matching request bodies
+  omniproxy mock --db sqlite://omniproxy.db --match-body
+
+  # Behave like an unavailable gateway for unknown requests
+  omniproxy mock traffic.ndjson --not-found-status 502`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMock(opts, args)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.port, "port", "p", 8081, "Port to listen on")
+	cmd.Flags().StringVar(&opts.host, "host", "127.0.0.1", "Host to bind to")
+	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "Log every request and whether it matched")
+
+	// Source options
+	cmd.Flags().StringVar(&opts.db, "db", "", "Database URL to read recordings from (sqlite://path or postgres://...)")
+	cmd.Flags().IntVar(&opts.limit, "limit", 10000, "Maximum recordings to read from the database")
+
+	// Selection options
+	cmd.Flags().StringSliceVar(&opts.includeHosts, "include-host", nil, "Only serve recordings for these hosts (supports wildcards)")
+	cmd.Flags().StringSliceVar(&opts.includePaths, "include-path", nil, "Only serve recordings matching these paths (supports wildcards)")
+
+	// Matching options
+	cmd.Flags().BoolVar(&opts.matchHost, "match-host", false, "Require the request host to match the recorded host")
+	cmd.Flags().StringVar(&opts.matchQuery, "match-query", "exact", "Query matching: exact, subset, ignore")
+	cmd.Flags().StringSliceVar(&opts.ignoreQuery, "ignore-query", nil, "Query parameters to ignore when matching")
+	cmd.Flags().BoolVar(&opts.matchBody, "match-body", false, "Require the request body to match (JSON compared structurally)")
+	cmd.Flags().IntVar(&opts.notFoundStatus, "not-found-status", http.StatusNotFound, "Status for unmatched requests (e.g. 404 or 502)")
+
+	return cmd
+}
+
+func runMock(opts *mockOptions, files []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server, err := newMockServer(ctx, files, opts.db, opts.limit, opts.includeHosts, opts.includePaths, &mock.Config{
+		MatchHost:         opts.matchHost,
+		Query:             mock.QueryMatch(opts.matchQuery),
+		IgnoreQueryParams: opts.ignoreQuery,
+		MatchBody:         opts.matchBody,
+		NotFoundStatus:    opts.notFoundStatus,
+	})
+	if err != nil {
+		return err
+	}
+
+	var handler http.Handler = server
+	if opts.verbose {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			server.ServeHTTP(rw, r)
+			log.Printf("%s %s -> %d (%s)", r.Method, r.URL.RequestURI(), rw.status, rw.Header().Get(mock.HeaderMock))
+		})
+	}
+
+	addr := fmt.Sprintf("%s:%d", opts.host, opts.port)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Printf("OmniProxy mock serving %d recordings on http://%s\n", server.Len(), addr)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		fmt.Println("\nShutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// newMockServer loads recordings from files or the database into a mock server.
+func newMockServer(ctx context.Context, files []string, db string, limit int, hosts, paths []string, cfg *mock.Config) (*mock.Server, error) {
+	if (db == "") == (len(files) == 0) {
+		return nil, fmt.Errorf("specify capture files or a database (but not both)")
+	}
+
+	filter := capture.NewFilter()
+	filter.IncludeHosts = hosts
+	filter.IncludePaths = paths
+	if err := filter.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var recs []*capture.Record
+	var err error
+	if db != "" {
+		recs, err = loadRecordsFromDB(ctx, db, nil, &backend.TrafficFilter{Limit: limit}, hosts)
+	} else {
+		recs, err = loadRecordsFromFiles(files)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	selected := recs[:0]
+	for _, rec := range recs {
+		if filter.MatchRequest(rec.Request.Host, rec.Request.Path, rec.Request.Method) {
+			selected = append(selected, rec)
+		}
+	}
+
+	server, err := mock.New(cfg, selected)
+	if err != nil {
+		return nil, err
+	}
+	if server.Len() == 0 {
+		return nil, fmt.Errorf("no recordings to serve")
+	}
+	return server, nil
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/daemon"
+	"github.com/grokify/omniproxy/pkg/netsim"
+	"github.com/spf13/cobra"
+)
+
+// networkConfig combines network settings from a config file with the
+// --network-profile and --network-host flags. Flag host profiles take
+// precedence over those from the file.
+func networkConfig(base netsim.Config, profile string, hosts []string) (*netsim.Config, error) {
+	cfg := base
+	if profile != "" {
+		cfg.Default = profile
+	}
+	if len(hosts) > 0 {
+		parsed, err := parseNetworkHosts(hosts)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Hosts = append(parsed, base.Hosts...)
+	}
+	return &cfg, nil
+}
+
+// parseNetworkHosts parses host=profile pairs.
+func parseNetworkHosts(values []string) ([]netsim.HostProfile, error) {
+	hosts := make([]netsim.HostProfile, 0, len(values))
+	for _, v := range values {
+		host, profile, ok := strings.Cut(v, "=")
+		if !ok || host == "" || profile == "" {
+			return nil, fmt.Errorf("invalid network host %q: expected host=profile", v)
+		}
+		hosts = append(hosts, netsim.HostProfile{Host: host, Profile: profile})
+	}
+	return hosts, nil
+}
+
+// describeNetwork summarizes the active network settings.
+func describeNetwork(cfg *netsim.Config) string {
+	var parts []string
+	if cfg.Default != "" && cfg.Default != netsim.ProfileNone {
+		parts = append(parts, "default="+cfg.Default)
+	}
+	for _, h := range cfg.Hosts {
+		parts = append(parts, h.Host+"="+h.Profile)
+	}
+	if len(parts) == 0 {
+		return "off"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func newDaemonNetworkCmd() *cobra.Command {
+	var socketPath string
+
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Show or switch network simulation profiles in the running daemon",
+		Long: `Show or switch network simulation profiles in the running daemon.
+
+Profiles add latency, jitter, bandwidth caps, connection resets and injected
+errors to upstream traffic. Built-in profiles are slow-3g, 3g, 4g and
+flaky-wifi; custom profiles can be defined in the daemon's network settings.
+
+Examples:
+  # Show the active settings and available profiles
+  omniproxy daemon network show
+
+  # Simulate 3G for everything, and a flaky connection to one API
+  omniproxy daemon network set 3g --host api.example.com=flaky-wifi
+
+  # Turn simulation off
+  omniproxy daemon network set none`,
+	}
+
+	cmd.PersistentFlags().StringVar(&socketPath, "socket", daemon.DefaultSocketPath, "Unix socket path")
+
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Show the active network settings and available profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := daemon.NewClient(socketPath).GetNetwork()
+			if err != nil {
+				return err
+			}
+			printNetwork(res)
+			return nil
+		},
+	}
+
+	var hosts []string
+	set := &cobra.Command{
+		Use:   "set [profile]",
+		Short: "Set the default profile and per-host profiles",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := daemon.NewClient(socketPath)
+			current, err := client.GetNetwork()
+			if err != nil {
+				return err
+			}
+
+			// Keep custom profiles and the seed; replace the assignments
+			cfg := *current.Config
+			cfg.Default = ""
+			if len(args) > 0 {
+				cfg.Default = args[0]
+			}
+			if cfg.Hosts, err = parseNetworkHosts(hosts); err != nil {
+				return err
+			}
+
+			res, err := client.SetNetwork(&cfg)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Network simulation: %s\n", describeNetwork(res.Config))
+			return nil
+		},
+	}
+	set.Flags().StringArrayVar(&hosts, "host", nil, "Per-host profile (host=profile, supports wildcards)")
+
+	cmd.AddCommand(show, set)
+
+	return cmd
+}
+
+func printNetwork(res *daemon.NetworkResponse) {
+	fmt.Printf("Network simulation: %s\n\n", describeNetwork(res.Config))
+	fmt.Printf("%-12s %9s %9s %10s %10s %6s %6s\n", "PROFILE", "LATENCY", "JITTER", "DOWN KBPS", "UP KBPS", "RESET", "ERROR")
+	for _, p := range res.Profiles {
+		fmt.Printf("%-12s %7dms %7dms %10s %10s %5.0f%% %5.0f%%\n", p.Name, p.LatencyMs, p.JitterMs,
+			kbps(p.DownloadKbps), kbps(p.UploadKbps), p.ResetRate*100, p.ErrorRate*100)
+	}
+}
+
+// kbps formats a bandwidth cap, where 0 means unlimited.
+func kbps(v int) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", v)
+}
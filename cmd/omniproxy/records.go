@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// loadRecordsFromFiles reads records from HAR or NDJSON capture files.
+func loadRecordsFromFiles(files []string) ([]*capture.Record, error) {
+	var recs []*capture.Record
+	for _, path := range files {
+		fileRecs, err := capture.ReadRecordsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, fileRecs...)
+	}
+	return recs, nil
+}
+
+// loadRecordsFromDB reads HTTP records from the database, either by ID or by
+// filter in capture order. Wildcard hosts cannot be queried directly and are
+// left for the caller to match after loading.
+func loadRecordsFromDB(ctx context.Context, dbURL string, ids []string, filter *backend.TrafficFilter, hosts []string) ([]*capture.Record, error) {
+	store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{
+		DatabaseURL: dbURL,
+		ProxyName:   "default",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer store.Close()
+
+	if len(ids) == 0 {
+		if filter == nil {
+			filter = &backend.TrafficFilter{}
+		}
+		filter.RecordTypes = []string{string(capture.RecordTypeHTTP)}
+		filter.OrderBy = "started_at"
+		if !hasWildcard(hosts) {
+			filter.Hosts = hosts
+		}
+
+		rows, err := store.Query(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+	}
+
+	recs := make([]*capture.Record, 0, len(ids))
+	for _, id := range ids {
+		detail, err := store.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load traffic %s: %w", id, err)
+		}
+		recs = append(recs, detail.Record())
+	}
+	return recs, nil
+}
+
+// parseTimeFlag parses an RFC 3339 timestamp or a duration relative to now.
+func parseTimeFlag(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time or duration, got %q", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// hasWildcard reports whether any pattern contains a wildcard.
+func hasWildcard(patterns []string) bool {
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?") {
+			return true
+		}
+	}
+	return false
+}
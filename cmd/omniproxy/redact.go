@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grokify/omniproxy/pkg/redact"
+	"github.com/spf13/cobra"
+)
+
+// redactKeyEnv names the environment variable holding the redaction hash key,
+// so the key stays out of command lines and config files.
+const redactKeyEnv = "OMNIPROXY_REDACT_KEY"
+
+// redactOptions holds the redaction flags shared by the capturing commands.
+type redactOptions struct {
+	headers  []string
+	query    []string
+	json     []string
+	form     []string
+	patterns []string
+}
+
+func (o *redactOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.headers, "redact-header", nil, "Additional headers to redact")
+	cmd.Flags().StringSliceVar(&o.query, "redact-query", nil, "Additional query parameters to redact")
+	cmd.Flags().StringSliceVar(&o.json, "redact-json", nil, "Additional JSON paths to redact in bodies (e.g. $.user.password, $..token)")
+	cmd.Flags().StringSliceVar(&o.form, "redact-form", nil, "Additional form fields to redact")
+	cmd.Flags().StringSliceVar(&o.patterns, "redact-pattern", nil, "Additional regular expressions to redact (or jwt, aws-access-key, credit-card)")
+
+	// --filter-header predates redaction and now redacts instead of dropping
+	cmd.Flags().StringSliceVar(&o.headers, "filter-header", nil, "Additional headers to redact")
+	_ = cmd.Flags().MarkDeprecated("filter-header", "use --redact-header")
+}
+
+// redactor builds a Redactor from base (the default rules when nil) extended
+// by the flags. The hash key is read from OMNIPROXY_REDACT_KEY when set.
+func (o *redactOptions) redactor(base *redact.Config) (*redact.Redactor, error) {
+	if base == nil {
+		base = redact.DefaultConfig()
+	}
+	cfg := *base
+	cfg.Headers = append(append([]string(nil), cfg.Headers...), o.headers...)
+	cfg.Query = append(append([]string(nil), cfg.Query...), o.query...)
+	cfg.JSON = append(append([]string(nil), cfg.JSON...), o.json...)
+	cfg.Form = append(append([]string(nil), cfg.Form...), o.form...)
+	cfg.Patterns = append(append([]string(nil), cfg.Patterns...), o.patterns...)
+	if key := os.Getenv(redactKeyEnv); key != "" {
+		cfg.HashKey = key
+	}
+
+	r, err := redact.New(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction rules: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/replay"
+	"github.com/spf13/cobra"
+)
+
+type replayOptions struct {
+	// Sources
+	db    string
+	ids   []string
+	limit int
+
+	// Selection
+	hosts   []string
+	paths   []string
+	methods []string
+	since   string
+	until   string
+
+	// Replay options
+	baseURL        string
+	concurrency    int
+	rate           float64
+	originalTiming bool
+	addHeader      []string
+	timeout        time.Duration
+	insecure       bool
+
+	// Comparison options
+	ignoreHeaders   []string
+	ignoreBodyPaths []string
+	noHeaders       bool
+	noBody          bool
+
+	report string
+}
+
+func newReplayCmd() *cobra.Command {
+	opts := &replayOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "replay [file...]",
+		Short: "Re-send captured traffic and diff the responses",
+		Long: `Replay captured requests and compare each new response against the
+recorded one (status, headers and a JSON-aware body diff).
+
+Traffic is read from HAR or NDJSON capture files, or from a database with --db.
+WebSocket records are skipped. The command exits non-zero if any response
+differs or any request fails, so it can be used as a regression check.
+
+Examples:
+  # Replay a capture against the original hosts
+  omniproxy replay traffic.ndjson
+
+  # Replay a HAR file against staging, 4 requests at a time
+  omniproxy replay traffic.har --base-url https://staging.example.com --concurrency 4
+
+  # Replay the last hour of API traffic from the database
+  omniproxy replay --db sqlite://omniproxy.db --host api.example.com --since 1h
+
+  # Replay specific records, preserving the original request timing
+  omniproxy replay --db sqlite://omniproxy.db --id 42 --id 43 --original-timing
+
+  # Ignore volatile fields and emit a JSON report
+  omniproxy replay traffic.ndjson --ignore-body-path '$.updatedAt' --ignore-body-path '$.items[*].id' --report json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runReplay(cmd.Context(), opts, args)
+		},
+	}
+
+	// Source options
+	cmd.Flags().StringVar(&opts.db, "db", "", "Database URL to read traffic from (sqlite://path or postgres://...)")
+	cmd.Flags().StringSliceVar(&opts.ids, "id", nil, "Replay stored records by ID (requires --db)")
+	cmd.Flags().IntVar(&opts.limit, "limit", 100, "Maximum records to read from the database")
+
+	// Selection options
+	cmd.Flags().StringSliceVar(&opts.hosts, "host", nil, "Only replay requests to these hosts (supports wildcards)")
+	cmd.Flags().StringSliceVar(&opts.paths, "path", nil, "Only replay requests matching these paths (supports wildcards)")
+	cmd.Flags().StringSliceVar(&opts.methods, "method", nil, "Only replay these HTTP methods")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only replay requests started after this time (RFC 3339 or duration, e.g. 1h)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only replay requests started before this time (RFC 3339 or duration)")
+
+	// Replay options
+	cmd.Flags().StringVar(&opts.baseURL, "base-url", "", "Send requests to this base URL instead of the original host")
+	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "c", 1, "Number of requests in flight")
+	cmd.Flags().Float64Var(&opts.rate, "rate", 0, "Maximum requests per second (0 = unlimited)")
+	cmd.Flags().BoolVar(&opts.originalTiming, "original-timing", false, "Preserve the recorded gaps between requests")
+	cmd.Flags().StringSliceVar(&opts.addHeader, "add-header", nil, "Headers to set on replayed requests (key=value)")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Second, "Per-request timeout")
+	cmd.Flags().BoolVar(&opts.insecure, "insecure", false, "Skip TLS certificate verification")
+
+	// Comparison options
+	cmd.Flags().StringSliceVar(&opts.ignoreHeaders, "ignore-header", nil, "Response headers to exclude from comparison")
+	cmd.Flags().StringSliceVar(&opts.ignoreBodyPaths, "ignore-body-path", nil, "JSON paths to exclude from body comparison (e.g. $.items[*].id)")
+	cmd.Flags().BoolVar(&opts.noHeaders, "no-headers", false, "Do not compare response headers")
+	cmd.Flags().BoolVar(&opts.noBody, "no-body", false, "Do not compare response bodies")
+
+	cmd.Flags().StringVar(&opts.report, "report", "text", "Report format: text, json")
+
+	return cmd
+}
+
+func runReplay(ctx context.Context, opts *replayOptions, files []string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.report != "text" && opts.report != "json" {
+		return fmt.Errorf("unsupported report format: %s", opts.report)
+	}
+	if (opts.db == "") == (len(files) == 0) {
+		return fmt.Errorf("specify capture files or --db (but not both)")
+	}
+	if len(opts.ids) > 0 && opts.db == "" {
+		return fmt.Errorf("--id requires --db")
+	}
+
+	since, err := parseTimeFlag(opts.since)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseTimeFlag(opts.until)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	filter := &capture.Filter{
+		IncludeHosts:   opts.hosts,
+		IncludePaths:   opts.paths,
+		IncludeMethods: opts.methods,
+		MaxStatusCode:  999,
+	}
+	if err := filter.Compile(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var recs []*capture.Record
+	if opts.db != "" {
+		recs, err = loadRecordsFromDB(ctx, opts.db, opts.ids, &backend.TrafficFilter{
+			StartTime: since,
+			EndTime:   until,
+			Methods:   opts.methods,
+			Limit:     opts.limit,
+		}, opts.hosts)
+	} else {
+		recs, err = loadRecordsFromFiles(files)
+	}
+	if err != nil {
+		return err
+	}
+
+	selected := make([]*capture.Record, 0, len(recs))
+	for _, rec := range recs {
+		if !replay.Replayable(rec) || !filter.MatchRequest(rec.Request.Host, rec.Request.Path, rec.Request.Method) {
+			continue
+		}
+		if !since.IsZero() && rec.StartTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && rec.StartTime.After(until) {
+			continue
+		}
+		selected = append(selected, rec)
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no replayable requests found")
+	}
+
+	headers := make(http.Header)
+	for _, h := range opts.addHeader {
+		key, value, err := parseHeader(h)
+		if err != nil {
+			return fmt.Errorf("invalid header %q: %w", h, err)
+		}
+		headers.Add(key, value)
+	}
+
+	replayer, err := replay.New(&replay.Config{
+		BaseURL:         opts.baseURL,
+		Concurrency:     opts.concurrency,
+		Rate:            opts.rate,
+		OriginalTiming:  opts.originalTiming,
+		Headers:         headers,
+		CompareHeaders:  !opts.noHeaders,
+		CompareBody:     !opts.noBody,
+		IgnoreHeaders:   opts.ignoreHeaders,
+		IgnoreBodyPaths: opts.ignoreBodyPaths,
+		Timeout:         opts.timeout,
+		Insecure:        opts.insecure,
+	})
+	if err != nil {
+		return err
+	}
+
+	report := replayer.Run(ctx, selected)
+
+	if opts.report == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("replay failed: %d failed, %d errors", report.Failed, report.Errors)
+	}
+	return nil
+}
@@ -22,7 +22,7 @@ type reverseOptions struct {
 	redirectHTTP   bool
 	output         string
 	format         string
-	filterHeader   []string
+	redact         redactOptions
 	stripPrefix    string
 	addHeader      []string
 	includeHosts   []string
@@ -88,7 +88,7 @@ Note: Running on ports 80 and 443 typically requires root/sudo.`,
 	// Output options
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file for captured traffic")
 	cmd.Flags().StringVarP(&opts.format, "format", "f", "ndjson", "Output format: ndjson, json, har, ir")
-	cmd.Flags().StringSliceVar(&opts.filterHeader, "filter-header", nil, "Additional headers to filter from output")
+	opts.redact.addFlags(cmd)
 
 	// Routing options
 	cmd.Flags().StringVar(&opts.stripPrefix, "strip-prefix", "", "Strip path prefix before forwarding")
@@ -184,8 +184,9 @@ func runReverse(opts *reverseOptions) error {
 			return fmt.Errorf("unknown format: %s", opts.format)
 		}
 
-		if len(opts.filterHeader) > 0 {
-			capturerCfg.FilterHeaders = append(capturerCfg.FilterHeaders, opts.filterHeader...)
+		capturerCfg.Redactor, err = opts.redact.redactor(nil)
+		if err != nil {
+			return err
 		}
 
 		capturer = capture.NewCapturer(capturerCfg)
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,23 +13,42 @@ import (
 	"github.com/grokify/omniproxy/pkg/backend"
 	"github.com/grokify/omniproxy/pkg/ca"
 	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/config"
+	"github.com/grokify/omniproxy/pkg/mock"
+	"github.com/grokify/omniproxy/pkg/netsim"
 	"github.com/grokify/omniproxy/pkg/observability"
+	"github.com/grokify/omniproxy/pkg/protodecode"
 	"github.com/grokify/omniproxy/pkg/proxy"
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/grokify/omniproxy/pkg/redact"
+	"github.com/grokify/omniproxy/pkg/rewrite"
+	"github.com/grokify/omniproxy/ui/auth"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type serveOptions struct {
-	port         int
-	host         string
-	verbose      bool
-	enableMITM   bool
-	caPath       string
-	keyPath      string
-	output       string
-	format       string
-	skipHosts    []string
-	filterHeader []string
-	skipBinary   bool
+	port       int
+	host       string
+	verbose    bool
+	enableMITM bool
+	caPath     string
+	keyPath    string
+	output     string
+	format     string
+	skipHosts  []string
+	redact     redactOptions
+	skipBinary bool
+
+	// Certificate cache options
+	certCache     string
+	certCacheSize int
+	certCacheTTL  time.Duration
+	certCacheURL  string
+
+	// protoDescriptors are FileDescriptorSet files for decoding gRPC messages
+	protoDescriptors []string
 
 	// Filtering options
 	includeHosts   []string
@@ -37,9 +57,11 @@ type serveOptions struct {
 	excludePaths   []string
 	includeMethods []string
 	excludeMethods []string
+	filter         string
 
 	// Upstream proxy
-	upstream string
+	upstream       string
+	verifyUpstream bool
 
 	// Observability options
 	metricsPort   int
@@ -48,10 +70,41 @@ type serveOptions struct {
 	// Database options (for team/production mode)
 	db string
 
+	// trafficSink is a kafka:// or database URL records are stored to
+	trafficSink string
+
+	// Retention options (database mode)
+	retentionInterval time.Duration
+	stripBodiesAfter  time.Duration
+
 	// Async options
 	asyncQueue     int
 	asyncBatchSize int
 	asyncWorkers   int
+
+	// Mock options
+	mockFiles          []string
+	mockDB             string
+	mockPassthrough    bool
+	mockMatchQuery     string
+	mockMatchBody      bool
+	mockNotFoundStatus int
+
+	// Rewrite options
+	configPath     string
+	recordOriginal bool
+
+	// Network simulation options
+	networkProfile string
+	networkHosts   []string
+
+	// Proxy authentication options
+	authHtpasswd string
+	authTokens   []string
+	authUsers    bool
+
+	// fileConfig is the loaded --config file
+	fileConfig *config.Config
 }
 
 func newServeCmd() *cobra.Command {
@@ -86,19 +139,53 @@ Examples:
   # Capture only API calls
   omniproxy serve --include-path "/api/*" --include-method GET --include-method POST
 
-  # Export to HAR format
+  # Export to HAR format (kept valid while capturing, resumed on restart)
   omniproxy serve --output traffic.har --format har
 
   # Chain through upstream proxy
   omniproxy serve --upstream http://corporate-proxy:8080
 
   # Enable Prometheus metrics
-  omniproxy serve --metrics-port 9090`,
+  omniproxy serve --metrics-port 9090
+
+  # Load settings and rewrite rules from a config file
+  omniproxy serve --config ~/.omniproxy/config.yaml
+
+  # Answer recorded requests from a capture, forward everything else
+  omniproxy serve --mock traffic.ndjson --mock-passthrough
+
+  # Simulate a 3G connection, and a flaky one for a single API
+  omniproxy serve --network-profile 3g --network-host api.example.com=flaky-wifi
+
+  # Decode captured gRPC messages with a descriptor set
+  omniproxy serve --proto-descriptor greeter.protoset
+
+  # Share the proxy with a team, requiring credentials
+  omniproxy serve --host 0.0.0.0 --auth-htpasswd ~/.omniproxy/htpasswd --db sqlite://traffic.db
+
+  # Capture only failed requests to one API
+  omniproxy serve --filter 'host ~ "*.stripe.com" && status >= 400'
+
+  # Keep up to 5000 intercepted-host certificates
+  omniproxy serve --cert-cache lru --cert-cache-size 5000
+
+  # Share certificates and the CA between replicas
+  omniproxy serve --cert-cache redis --cert-cache-url redis://redis:6379/0`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.configPath != "" {
+				cfg, err := config.Load(opts.configPath)
+				if err != nil {
+					return err
+				}
+				applyServeConfig(cmd.Flags(), opts, cfg)
+			}
 			return runServe(opts)
 		},
 	}
 
+	// Config file
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Configuration file (flags override file settings)")
+
 	// Basic options
 	cmd.Flags().IntVarP(&opts.port, "port", "p", 8080, "Port to listen on")
 	cmd.Flags().StringVar(&opts.host, "host", "127.0.0.1", "Host to bind to")
@@ -112,12 +199,19 @@ Examples:
 	// Output options
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file for captured traffic (default: stdout)")
 	cmd.Flags().StringVarP(&opts.format, "format", "f", "ndjson", "Output format: ndjson, json, har, ir")
-	cmd.Flags().StringSliceVar(&opts.filterHeader, "filter-header", nil, "Additional headers to filter from output")
+	opts.redact.addFlags(cmd)
 	cmd.Flags().BoolVar(&opts.skipBinary, "skip-binary", true, "Skip capturing binary content (images, videos, etc.)")
+	cmd.Flags().StringSliceVar(&opts.protoDescriptors, "proto-descriptor", nil, "FileDescriptorSet files for decoding gRPC messages (binary or JSON)")
 
 	// MITM skip options
 	cmd.Flags().StringSliceVar(&opts.skipHosts, "skip-host", nil, "Hosts to skip MITM for (supports wildcards)")
 
+	// Certificate cache options
+	cmd.Flags().StringVar(&opts.certCache, "cert-cache", "lru", "Cache for intercepted-host certificates: lru, memory, redis")
+	cmd.Flags().IntVar(&opts.certCacheSize, "cert-cache-size", 1000, "Maximum certificates held by the lru cache")
+	cmd.Flags().DurationVar(&opts.certCacheTTL, "cert-cache-ttl", time.Hour, "How long the memory or redis cache keeps certificates")
+	cmd.Flags().StringVar(&opts.certCacheURL, "cert-cache-url", "", "Redis URL for the redis cache (redis://host:6379/0, rediss:// for TLS)")
+
 	// Filtering options
 	cmd.Flags().StringSliceVar(&opts.includeHosts, "include-host", nil, "Only capture requests to these hosts (supports wildcards)")
 	cmd.Flags().StringSliceVar(&opts.excludeHosts, "exclude-host", nil, "Exclude requests to these hosts (supports wildcards)")
@@ -125,9 +219,11 @@ Examples:
 	cmd.Flags().StringSliceVar(&opts.excludePaths, "exclude-path", nil, "Exclude requests matching these paths (supports wildcards)")
 	cmd.Flags().StringSliceVar(&opts.includeMethods, "include-method", nil, "Only capture these HTTP methods")
 	cmd.Flags().StringSliceVar(&opts.excludeMethods, "exclude-method", nil, "Exclude these HTTP methods")
+	cmd.Flags().StringVar(&opts.filter, "filter", "", `Only capture traffic matching this expression (e.g. 'host ~ "*.example.com" && status >= 400')`)
 
 	// Upstream proxy
 	cmd.Flags().StringVar(&opts.upstream, "upstream", "", "Upstream proxy URL (e.g., http://proxy:8080)")
+	cmd.Flags().BoolVar(&opts.verifyUpstream, "verify-upstream", false, "Verify the TLS certificates of origin servers")
 
 	// Observability options
 	cmd.Flags().IntVar(&opts.metricsPort, "metrics-port", 0, "Port for metrics/health endpoints (0 = disabled)")
@@ -135,15 +231,98 @@ Examples:
 
 	// Database options
 	cmd.Flags().StringVar(&opts.db, "db", "", "Database URL (sqlite://path or postgres://...)")
+	cmd.Flags().StringVar(&opts.trafficSink, "traffic-sink", "", "Traffic sink URL (kafka://broker:9092/topic, clickhouse://host:8123/db or a database URL)")
+	cmd.Flags().DurationVar(&opts.retentionInterval, "retention-interval", time.Hour, "How often to roll up and prune database traffic per org retention (0 = disabled)")
+	cmd.Flags().DurationVar(&opts.stripBodiesAfter, "strip-bodies-after", 0, "Remove bodies from database traffic older than this (0 = keep with the rows)")
 
 	// Async options
 	cmd.Flags().IntVar(&opts.asyncQueue, "async-queue", 10000, "Async traffic queue size")
 	cmd.Flags().IntVar(&opts.asyncBatchSize, "async-batch", 100, "Async batch size")
 	cmd.Flags().IntVar(&opts.asyncWorkers, "async-workers", 2, "Number of async workers")
 
+	// Mock options
+	cmd.Flags().StringSliceVar(&opts.mockFiles, "mock", nil, "Serve responses from these capture files (HAR or NDJSON)")
+	cmd.Flags().StringVar(&opts.mockDB, "mock-db", "", "Serve responses from this database")
+	cmd.Flags().BoolVar(&opts.mockPassthrough, "mock-passthrough", false, "Forward unmatched requests instead of rejecting them")
+	cmd.Flags().StringVar(&opts.mockMatchQuery, "mock-match-query", "exact", "Mock query matching: exact, subset, ignore")
+	cmd.Flags().BoolVar(&opts.mockMatchBody, "mock-match-body", false, "Require request bodies to match recordings")
+	cmd.Flags().IntVar(&opts.mockNotFoundStatus, "mock-not-found-status", http.StatusNotFound, "Status for unmatched requests (e.g. 404 or 502)")
+
+	// Rewrite options
+	cmd.Flags().BoolVar(&opts.recordOriginal, "record-original", false, "Also record requests/responses as they were before rewrite rules")
+
+	// Network simulation options
+	cmd.Flags().StringVar(&opts.networkProfile, "network-profile", "", "Network profile for all traffic: slow-3g, 3g, 4g, flaky-wifi, none or a custom profile")
+	cmd.Flags().StringArrayVar(&opts.networkHosts, "network-host", nil, "Network profile for matching hosts (host=profile, supports wildcards)")
+
+	// Proxy authentication options
+	cmd.Flags().StringVar(&opts.authHtpasswd, "auth-htpasswd", "", "Require Basic proxy credentials from this htpasswd file")
+	cmd.Flags().StringArrayVar(&opts.authTokens, "auth-token", nil, "Accept this bearer token (name=token)")
+	cmd.Flags().BoolVar(&opts.authUsers, "auth-users", false, "Accept users and sessions from the database (requires --db)")
+
 	return cmd
 }
 
+// applyServeConfig copies config file settings into opts for every flag
+// that was not set explicitly on the command line.
+func applyServeConfig(flags *pflag.FlagSet, opts *serveOptions, cfg *config.Config) {
+	opts.fileConfig = cfg
+
+	setString := func(name string, dst *string, v string) {
+		if !flags.Changed(name) && v != "" {
+			*dst = v
+		}
+	}
+	setStrings := func(name string, dst *[]string, v []string) {
+		if !flags.Changed(name) && len(v) > 0 {
+			*dst = v
+		}
+	}
+
+	setString("host", &opts.host, cfg.Server.Host)
+	if !flags.Changed("port") && cfg.Server.Port > 0 {
+		opts.port = cfg.Server.Port
+	}
+	if !flags.Changed("verbose") {
+		opts.verbose = cfg.Server.Verbose
+	}
+
+	if !flags.Changed("mitm") {
+		opts.enableMITM = cfg.MITM.Enabled
+	}
+	setString("ca-cert", &opts.caPath, cfg.MITM.CertPath)
+	setString("ca-key", &opts.keyPath, cfg.MITM.KeyPath)
+	setStrings("skip-host", &opts.skipHosts, cfg.MITM.SkipHosts)
+	setString("cert-cache", &opts.certCache, string(cfg.MITM.CertCache.Type))
+	if !flags.Changed("cert-cache-size") && cfg.MITM.CertCache.Size > 0 {
+		opts.certCacheSize = cfg.MITM.CertCache.Size
+	}
+	if !flags.Changed("cert-cache-ttl") && cfg.MITM.CertCache.TTL > 0 {
+		opts.certCacheTTL = cfg.MITM.CertCache.TTL
+	}
+	setString("cert-cache-url", &opts.certCacheURL, cfg.MITM.CertCache.URL)
+
+	setString("output", &opts.output, cfg.Capture.Output)
+	setString("format", &opts.format, cfg.Capture.Format)
+	if !flags.Changed("record-original") {
+		opts.recordOriginal = cfg.Capture.RecordOriginal
+	}
+	setStrings("proto-descriptor", &opts.protoDescriptors, cfg.Capture.ProtoDescriptors)
+
+	setStrings("include-host", &opts.includeHosts, cfg.Filter.IncludeHosts)
+	setStrings("exclude-host", &opts.excludeHosts, cfg.Filter.ExcludeHosts)
+	setStrings("include-path", &opts.includePaths, cfg.Filter.IncludePaths)
+	setStrings("exclude-path", &opts.excludePaths, cfg.Filter.ExcludePaths)
+	setStrings("include-method", &opts.includeMethods, cfg.Filter.IncludeMethods)
+	setStrings("exclude-method", &opts.excludeMethods, cfg.Filter.ExcludeMethods)
+	setString("filter", &opts.filter, cfg.Filter.Query)
+
+	setString("upstream", &opts.upstream, cfg.Upstream)
+	if !flags.Changed("verify-upstream") {
+		opts.verifyUpstream = cfg.VerifyUpstream
+	}
+}
+
 func runServe(opts *serveOptions) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -205,24 +384,26 @@ func runServe(opts *serveOptions) error {
 	if err := filter.Compile(); err != nil {
 		return fmt.Errorf("failed to compile filter: %w", err)
 	}
+	if opts.filter != "" {
+		q, err := query.Parse(opts.filter)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+		filter.Predicate = q.Match
+	}
 
 	// Setup capturer
 	var capturer *capture.Capturer
-	var outputFile *os.File
-	var harWriter *capture.HARWriter
 
 	capturerCfg := capture.DefaultConfig()
 	capturerCfg.Filter = filter
 	capturerCfg.SkipBinary = opts.skipBinary
 
-	if opts.output != "" {
-		outputFile, err = os.Create(opts.output)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
-		}
-		defer outputFile.Close()
-		capturerCfg.Output = outputFile
+	sinkCfg, dbURL, err := resolveTrafficSink(opts.trafficSink, opts.db)
+	if err != nil {
+		return err
 	}
+	opts.db = dbURL
 
 	switch opts.format {
 	case "ndjson":
@@ -231,39 +412,96 @@ func runServe(opts *serveOptions) error {
 		capturerCfg.Format = capture.FormatJSON
 	case "har":
 		capturerCfg.Format = capture.FormatHAR
-		// For HAR, we buffer records and write at end
-		if outputFile != nil {
-			harWriter = capture.NewHARWriter(outputFile)
-			capturerCfg.Output = nil // Don't write incrementally
-		}
 	case "ir":
 		capturerCfg.Format = capture.FormatIR
 	default:
 		return fmt.Errorf("unknown format: %s", opts.format)
 	}
 
-	if len(opts.filterHeader) > 0 {
-		capturerCfg.FilterHeaders = append(capturerCfg.FilterHeaders, opts.filterHeader...)
+	if opts.output != "" {
+		if opts.db == "" && sinkCfg == nil {
+			// Written by the file traffic store below
+			capturerCfg.Output = nil
+		} else {
+			output, err := openCaptureOutput(capturerCfg, opts.output)
+			if err != nil {
+				return err
+			}
+			defer output.Close()
+		}
 	}
 
-	capturer = capture.NewCapturer(capturerCfg)
+	var redactCfg *redact.Config
+	if cfg := opts.fileConfig; cfg != nil {
+		capturerCfg.IncludeHeaders = cfg.Capture.IncludeHeaders
+		capturerCfg.IncludeBody = cfg.Capture.IncludeBody
+		if cfg.Capture.MaxBodySize > 0 {
+			capturerCfg.MaxBodySize = cfg.Capture.MaxBodySize
+		}
+		if cfg.Capture.MaxDecodedSize > 0 {
+			capturerCfg.MaxDecodedSize = cfg.Capture.MaxDecodedSize
+		}
+		rules := cfg.Capture.Redact
+		rules.Headers = append(append([]string(nil), rules.Headers...), cfg.Capture.FilterHeaders...)
+		redactCfg = &rules
+	}
+	capturerCfg.Redactor, err = opts.redact.redactor(redactCfg)
+	if err != nil {
+		return err
+	}
 
-	// Add HAR handler if using HAR format
-	if harWriter != nil {
-		capturer.AddHandler(func(rec *capture.Record) {
-			harWriter.AddRecord(rec)
-		})
+	if len(opts.protoDescriptors) > 0 {
+		capturerCfg.Protos, err = protodecode.Load(opts.protoDescriptors...)
+		if err != nil {
+			return err
+		}
 	}
 
+	capturer = capture.NewCapturer(capturerCfg)
+
 	// Setup traffic store backend
 	var trafficStore backend.TrafficStore
+	var retention *backend.RetentionWorker
 	var backendMetrics backend.Metrics
+	var authUsers proxyauth.UserService
 
 	if obs != nil {
 		backendMetrics = observability.NewBackendMetrics(obs.Metrics)
 	}
 
-	if opts.db != "" {
+	if sinkCfg != nil {
+		// Kafka or ClickHouse mode (production)
+		sinkStore, err := setupSinkStore(ctx, sinkCfg, backendMetrics, health)
+		if err != nil {
+			return err
+		}
+
+		// Wrap with async for non-blocking writes; the queue depth includes
+		// records awaiting acknowledgement from Kafka brokers
+		trafficStore = backend.NewAsyncTrafficStore(sinkStore, &backend.AsyncConfig{
+			QueueSize: opts.asyncQueue,
+			BatchSize: opts.asyncBatchSize,
+			Workers:   opts.asyncWorkers,
+			Metrics:   backendMetrics,
+		})
+
+		// Register queue depth callback
+		if obs != nil {
+			if asyncStore, ok := trafficStore.(*backend.AsyncTrafficStoreWrapper); ok {
+				if err := obs.Metrics.RegisterQueueDepthCallback(obs.MeterProvider, func() int64 {
+					return int64(asyncStore.QueueDepth())
+				}); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to register queue depth callback: %v\n", err)
+				}
+			}
+		}
+
+		capturer.AddHandler(func(rec *capture.Record) {
+			if err := trafficStore.Store(context.Background(), rec); err != nil {
+				fmt.Fprintf(os.Stderr, "traffic store error: %v\n", err)
+			}
+		})
+	} else if opts.db != "" {
 		// Database mode (SQLite or PostgreSQL)
 		dbCfg, err := backend.ParseDatabaseURL(opts.db)
 		if err != nil {
@@ -308,6 +546,12 @@ func runServe(opts *serveOptions) error {
 		})
 
 		fmt.Printf("Database connected (proxy_id=%d)\n", dbStore.ProxyID())
+
+		retention = startRetention(dbStore, opts.retentionInterval, opts.stripBodiesAfter, backendMetrics)
+
+		if opts.authUsers {
+			authUsers = auth.NewService(dbStore.Client(), nil)
+		}
 	} else if opts.output != "" {
 		// File mode (laptop mode)
 		fileStore, err := backend.NewFileTrafficStore(&backend.FileTrafficStoreConfig{
@@ -345,6 +589,85 @@ func runServe(opts *serveOptions) error {
 		})
 	}
 
+	// Setup mock responses
+	var mockServer *mock.Server
+	if len(opts.mockFiles) > 0 || opts.mockDB != "" {
+		mockServer, err = newMockServer(ctx, opts.mockFiles, opts.mockDB, 10000, nil, nil, &mock.Config{
+			MatchHost:      true,
+			Query:          mock.QueryMatch(opts.mockMatchQuery),
+			MatchBody:      opts.mockMatchBody,
+			NotFoundStatus: opts.mockNotFoundStatus,
+			Passthrough:    opts.mockPassthrough,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load mock recordings: %w", err)
+		}
+	}
+
+	// Setup rewrite rules
+	var rewriteEngine *rewrite.Engine
+	if opts.fileConfig != nil && len(opts.fileConfig.Rules) > 0 {
+		rewriteEngine, err = rewrite.New(opts.fileConfig.Rules)
+		if err != nil {
+			return fmt.Errorf("invalid rewrite rules: %w", err)
+		}
+	}
+
+	// Setup network simulation
+	var networkBase netsim.Config
+	if opts.fileConfig != nil {
+		networkBase = opts.fileConfig.Network
+	}
+	networkCfg, err := networkConfig(networkBase, opts.networkProfile, opts.networkHosts)
+	if err != nil {
+		return err
+	}
+	var network *netsim.Simulator
+	if networkCfg.Default != "" || len(networkCfg.Hosts) > 0 {
+		network, err = netsim.New(networkCfg)
+		if err != nil {
+			return fmt.Errorf("invalid network settings: %w", err)
+		}
+	}
+
+	// Setup proxy authentication
+	if opts.authUsers && authUsers == nil {
+		return fmt.Errorf("--auth-users requires --db")
+	}
+	var authBase proxyauth.Config
+	if opts.fileConfig != nil {
+		authBase = opts.fileConfig.Auth
+	}
+	authCfg, err := proxyAuthConfig(authBase, opts.authHtpasswd, opts.authTokens, authUsers)
+	if err != nil {
+		return err
+	}
+	var authenticator *proxyauth.Authenticator
+	if authCfg.Enabled() {
+		authenticator, err = proxyauth.New(authCfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup proxy authentication: %w", err)
+		}
+	}
+
+	// Setup the certificate cache for intercepted hosts
+	certCache, proxyCA, err := setupCertCache(ctx, &backend.CertCacheConfig{
+		Type:    backend.CertCacheType(opts.certCache),
+		Size:    opts.certCacheSize,
+		TTL:     opts.certCacheTTL,
+		URL:     opts.certCacheURL,
+		Metrics: backendMetrics,
+	}, proxyCA, health)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = certCache.Close() }()
+
+	var proxyMetrics *observability.Metrics
+	if obs != nil {
+		proxyMetrics = obs.Metrics
+	}
+
 	// Setup proxy
 	proxyCfg := &proxy.Config{
 		Port:       opts.port,
@@ -354,6 +677,18 @@ func runServe(opts *serveOptions) error {
 		Capturer:   capturer,
 		SkipHosts:  opts.skipHosts,
 		Upstream:   opts.upstream,
+		Mock:       mockServer,
+
+		VerifyUpstream: opts.verifyUpstream,
+
+		Rewrite:        rewriteEngine,
+		RecordOriginal: opts.recordOriginal,
+
+		Network: network,
+		Auth:    authenticator,
+
+		CertCache: certCache,
+		Metrics:   proxyMetrics,
 	}
 
 	p, err := proxy.New(proxyCfg)
@@ -376,6 +711,26 @@ func runServe(opts *serveOptions) error {
 		fmt.Printf("Upstream proxy: %s\n", opts.upstream)
 	}
 
+	if rewriteEngine != nil {
+		fmt.Printf("Applying %d rewrite rules\n", rewriteEngine.Len())
+	}
+
+	if authenticator != nil {
+		fmt.Printf("Proxy authentication required: %s\n", describeProxyAuth(authCfg))
+	}
+
+	if network != nil {
+		fmt.Printf("Network simulation: %s\n", describeNetwork(networkCfg))
+	}
+
+	if capturerCfg.Protos != nil {
+		fmt.Printf("Decoding gRPC messages for %d services\n", len(capturerCfg.Protos.Services()))
+	}
+
+	if mockServer != nil {
+		fmt.Printf("Mocking %d recorded responses (passthrough=%v)\n", mockServer.Len(), opts.mockPassthrough)
+	}
+
 	// Print filter info
 	if len(opts.includeHosts) > 0 {
 		fmt.Printf("Including hosts: %v\n", opts.includeHosts)
@@ -390,7 +745,9 @@ func runServe(opts *serveOptions) error {
 		fmt.Printf("Excluding paths: %v\n", opts.excludePaths)
 	}
 
-	if opts.db != "" {
+	if sinkCfg != nil {
+		fmt.Printf("Capturing traffic to: %s\n", sinkCfg.String())
+	} else if opts.db != "" {
 		dbCfg, err := backend.ParseDatabaseURL(opts.db)
 		if err != nil {
 			// This should never happen since we already validated earlier
@@ -449,11 +806,12 @@ func runServe(opts *serveOptions) error {
 			health.SetReady(false)
 		}
 
-		if harWriter != nil {
-			fmt.Println("Writing HAR file...")
-			if err := harWriter.Write(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing HAR: %v\n", err)
-			}
+		if err := capturer.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error finalizing capture output: %v\n", err)
+		}
+
+		if retention != nil {
+			retention.Stop()
 		}
 
 		if trafficStore != nil {
@@ -469,3 +827,26 @@ func runServe(opts *serveOptions) error {
 
 	return p.ListenAndServe(addr)
 }
+
+// openCaptureOutput points the capturer at the --output file when traffic is
+// also sent to a database or sink. As in file mode, earlier captures are
+// kept: HAR files are reopened with capture.OpenHARFile, which repairs a
+// file left by a crash, and other formats are appended to.
+func openCaptureOutput(cfg *capture.Config, path string) (io.Closer, error) {
+	if cfg.Format == capture.FormatHAR {
+		har, err := capture.OpenHARFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open output file: %w", err)
+		}
+		cfg.Output = nil
+		cfg.HAR = har
+		return har, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644) //nolint:gosec // path is provided by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	cfg.Output = f
+	return f, nil
+}
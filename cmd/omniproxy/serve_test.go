@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// captureTo captures one exchange through an output opened as serve does
+// when traffic also goes to a database or sink.
+func captureTo(t *testing.T, path string, format capture.Format, url string) {
+	t.Helper()
+	cfg := capture.DefaultConfig()
+	cfg.Format = format
+	output, err := openCaptureOutput(cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	c := capture.NewCapturer(cfg)
+	req, _ := http.NewRequest("GET", url, nil)
+	rec := c.StartCapture(req)
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("ok"))}
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readCaptured(t *testing.T, path string) []string {
+	t.Helper()
+	recs, err := capture.ReadRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadRecordsFile: %v", err)
+	}
+	urls := make([]string, len(recs))
+	for i, rec := range recs {
+		urls[i] = rec.Request.URL
+	}
+	return urls
+}
+
+func TestOpenCaptureOutput(t *testing.T) {
+	t.Run("har", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traffic.har")
+		captureTo(t, path, capture.FormatHAR, "http://example.com/first")
+
+		// A restart keeps the earlier capture, even after a crash left a
+		// partial entry behind
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(`,{"startedDateTime":"2026-`)
+		f.Close()
+		captureTo(t, path, capture.FormatHAR, "http://example.com/second")
+
+		urls := readCaptured(t, path)
+		if len(urls) != 2 || urls[0] != "http://example.com/first" || urls[1] != "http://example.com/second" {
+			t.Errorf("unexpected entries: %v", urls)
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traffic.ndjson")
+		captureTo(t, path, capture.FormatNDJSON, "http://example.com/first")
+		captureTo(t, path, capture.FormatNDJSON, "http://example.com/second")
+
+		if urls := readCaptured(t, path); len(urls) != 2 {
+			t.Errorf("expected both runs to be kept, got %v", urls)
+		}
+	})
+}
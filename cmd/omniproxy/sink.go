@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/observability"
+)
+
+// resolveTrafficSink parses the --traffic-sink URL. Database sinks are
+// returned as a database URL so they take the --db path; Kafka and
+// ClickHouse sinks return their configuration.
+func resolveTrafficSink(sink, db string) (*backend.TrafficSinkConfig, string, error) {
+	if sink == "" {
+		return nil, db, nil
+	}
+	if db != "" {
+		return nil, "", fmt.Errorf("--traffic-sink and --db are mutually exclusive")
+	}
+
+	sinkCfg, err := backend.ParseTrafficSinkURL(sink)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid traffic sink: %w", err)
+	}
+	if sinkCfg.Type == backend.TrafficSinkDatabase {
+		return nil, sink, nil
+	}
+	return sinkCfg, "", nil
+}
+
+// setupSinkStore connects to a Kafka or ClickHouse sink and adds it to the
+// readiness checks. Records are tagged with the hostname unless the URL sets
+// a proxy ID.
+func setupSinkStore(ctx context.Context, sinkCfg *backend.TrafficSinkConfig, metrics backend.Metrics, health *observability.HealthChecker) (backend.TrafficStore, error) {
+	hostname, _ := os.Hostname()
+
+	fmt.Printf("Connecting to traffic sink: %s\n", sinkCfg.String())
+
+	var (
+		store backend.TrafficStore
+		check observability.HealthCheck
+	)
+	switch sinkCfg.Type {
+	case backend.TrafficSinkKafka:
+		cfg := sinkCfg.Kafka
+		if cfg.ProxyID == "" {
+			cfg.ProxyID = hostname
+		}
+		cfg.Metrics = metrics
+
+		kafkaStore, err := backend.NewKafkaTrafficStore(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		store = kafkaStore
+		check = observability.CommonChecks{}.KafkaCheck(pingWithTimeout(kafkaStore.Ping))
+	case backend.TrafficSinkClickHouse:
+		cfg := sinkCfg.ClickHouse
+		if cfg.ProxyID == "" {
+			cfg.ProxyID = hostname
+		}
+		cfg.Metrics = metrics
+
+		chStore, err := backend.NewClickHouseTrafficStore(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		store = chStore
+		check = observability.CommonChecks{}.DatabaseCheck(pingWithTimeout(chStore.Ping))
+	default:
+		return nil, fmt.Errorf("unsupported traffic sink: %s", sinkCfg.Type)
+	}
+
+	if health != nil {
+		health.RegisterCheck(string(sinkCfg.Type), check)
+	}
+
+	fmt.Printf("Traffic sink connected (%s)\n", sinkCfg.Type)
+	return store, nil
+}
+
+// pingWithTimeout adapts a context-aware ping to a health check function.
+func pingWithTimeout(ping func(context.Context) error) func() error {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return ping(ctx)
+	}
+}
@@ -0,0 +1,744 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/daemon"
+	"github.com/grokify/omniproxy/pkg/export"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/spf13/cobra"
+)
+
+// trafficSource reads stored traffic from the daemon or a traffic store.
+type trafficSource interface {
+	Query(ctx context.Context, filter *backend.TrafficFilter) ([]*backend.TrafficRecord, error)
+	GetByID(ctx context.Context, id string) (*backend.TrafficDetail, error)
+}
+
+// daemonTrafficSource reads stored traffic through the daemon control API.
+type daemonTrafficSource struct {
+	client *daemon.Client
+}
+
+func (s daemonTrafficSource) Query(_ context.Context, filter *backend.TrafficFilter) ([]*backend.TrafficRecord, error) {
+	res, err := s.client.QueryTraffic(filter)
+	if err != nil {
+		return nil, err
+	}
+	return res.Records, nil
+}
+
+func (s daemonTrafficSource) GetByID(_ context.Context, id string) (*backend.TrafficDetail, error) {
+	return s.client.GetTraffic(id)
+}
+
+// trafficSourceOptions selects where traffic commands read from.
+type trafficSourceOptions struct {
+	db     string
+	socket string
+}
+
+// open connects to the traffic store given by --db, or to the daemon.
+func (o *trafficSourceOptions) open(ctx context.Context) (trafficSource, func() error, error) {
+	if o.db == "" {
+		return daemonTrafficSource{client: daemon.NewClient(o.socket)}, func() error { return nil }, nil
+	}
+	return openTrafficQuerier(ctx, o.db)
+}
+
+func newTrafficCmd() *cobra.Command {
+	source := &trafficSourceOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "traffic",
+		Short: "Inspect captured traffic",
+		Long: `Inspect captured traffic from the running daemon, or directly from a
+database or ClickHouse with --db.
+
+Commands filter with flags for common fields and an optional filter
+expression (see 'omniproxy traffic search --help').`,
+		Example: `  # Recent errors, then the details of one request
+  omniproxy traffic list --min-status 500
+  omniproxy traffic show 3f2a9c1e-... --format curl
+
+  # Follow new traffic to the API
+  omniproxy traffic tail -f 'host ~ "api.*"'
+
+  # Export the last day of traffic from the database as HAR
+  omniproxy traffic export --db sqlite://traffic.db --since 24h -o traffic.har`,
+	}
+
+	cmd.PersistentFlags().StringVar(&source.db, "db", "", "Traffic store URL (sqlite://, postgres:// or clickhouse://) instead of the daemon")
+	cmd.PersistentFlags().StringVar(&source.socket, "socket", daemon.DefaultSocketPath, "Daemon Unix socket path")
+
+	cmd.AddCommand(
+		newTrafficListCmd(source),
+		newTrafficSearchCmd(source),
+		newTrafficShowCmd(source),
+		newTrafficTailCmd(source),
+		newTrafficExportCmd(source),
+	)
+
+	return cmd
+}
+
+// trafficFilterOptions are the filter flags shared by traffic commands.
+type trafficFilterOptions struct {
+	hosts     []string
+	methods   []string
+	statuses  []int
+	minStatus int
+	user      string
+	since     string
+	until     string
+}
+
+func (o *trafficFilterOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.hosts, "host", nil, "Only traffic to these hosts")
+	cmd.Flags().StringSliceVar(&o.methods, "method", nil, "Only these HTTP methods")
+	cmd.Flags().IntSliceVar(&o.statuses, "status", nil, "Only these status codes")
+	cmd.Flags().IntVar(&o.minStatus, "min-status", 0, "Only status codes of at least this (e.g. 400 for errors)")
+	cmd.Flags().StringVar(&o.user, "user", "", "Only traffic from this authenticated proxy user")
+	cmd.Flags().StringVar(&o.since, "since", "", "Only traffic after this time (RFC 3339 or duration ago, e.g. 1h)")
+	cmd.Flags().StringVar(&o.until, "until", "", "Only traffic before this time (RFC 3339 or duration ago)")
+}
+
+// filter builds a traffic filter from the flags and an optional expression.
+func (o *trafficFilterOptions) filter(args []string) (*backend.TrafficFilter, error) {
+	filter := &backend.TrafficFilter{
+		Hosts:       o.hosts,
+		StatusCodes: o.statuses,
+		MinStatus:   o.minStatus,
+		User:        o.user,
+	}
+	for _, m := range o.methods {
+		filter.Methods = append(filter.Methods, strings.ToUpper(m))
+	}
+
+	var err error
+	if filter.StartTime, err = parseTimeFlag(o.since); err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.EndTime, err = parseTimeFlag(o.until); err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+	if len(args) > 0 {
+		if filter.Query, err = query.Parse(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+type trafficListOptions struct {
+	trafficFilterOptions
+	limit  int
+	offset int
+	format string
+}
+
+func newTrafficListCmd(source *trafficSourceOptions) *cobra.Command {
+	opts := &trafficListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list [expression]",
+		Short: "List stored traffic, newest first",
+		Example: `  omniproxy traffic list --host api.example.com --since 1h
+  omniproxy traffic list --format json 'status >= 400 && duration > 1s'`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runTrafficList(source, opts, args)
+		},
+	}
+
+	opts.addFlags(cmd)
+	addTrafficListFlags(cmd, opts)
+
+	return cmd
+}
+
+func newTrafficSearchCmd(source *trafficSourceOptions) *cobra.Command {
+	opts := &trafficListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "search <expression>",
+		Short: "Search stored traffic with a filter expression",
+		Long: `Search stored traffic with a filter expression, newest first.
+
+Expressions compare fields with ==, !=, ~ (wildcard), !~, contains, in,
+<, <=, > and >=, and combine them with &&, || and !:
+
+  host ~ "*.stripe.com" && status >= 400 && duration > 500ms
+  req.header["x-tenant"] == "acme" || resp.json["$.error.code"]
+  method in [POST, PUT] && !(path contains "/health")
+
+See the README for the full list of fields.`,
+		Example: `  omniproxy traffic search --db sqlite://traffic.db 'status >= 500'
+  omniproxy traffic search --db clickhouse://localhost:8123/omniproxy --since 1h 'host ~ "*.stripe.com"'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runTrafficList(source, opts, args)
+		},
+	}
+
+	opts.addFlags(cmd)
+	addTrafficListFlags(cmd, opts)
+
+	return cmd
+}
+
+func addTrafficListFlags(cmd *cobra.Command, opts *trafficListOptions) {
+	cmd.Flags().IntVar(&opts.limit, "limit", 100, "Maximum number of records")
+	cmd.Flags().IntVar(&opts.offset, "offset", 0, "Skip this many records")
+	cmd.Flags().StringVarP(&opts.format, "format", "f", "table", "Output format: table, json")
+}
+
+func runTrafficList(source *trafficSourceOptions, opts *trafficListOptions, args []string) error {
+	ctx := context.Background()
+
+	if opts.format != "table" && opts.format != "json" {
+		return fmt.Errorf("unknown format: %s", opts.format)
+	}
+	filter, err := opts.filter(args)
+	if err != nil {
+		return err
+	}
+	filter.Limit, filter.Offset, filter.Desc = opts.limit, opts.offset, true
+
+	src, closeFn, err := source.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	records, err := src.Query(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to query traffic: %w", err)
+	}
+
+	if opts.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	if len(records) == 0 {
+		fmt.Println("No matching traffic")
+		return nil
+	}
+	idWidth := trafficIDWidth(records)
+	printTrafficHeader(idWidth)
+	for _, r := range records {
+		printTrafficRow(r, idWidth)
+	}
+	return nil
+}
+
+// trafficIDWidth returns the width of the ID column for records: database
+// IDs are short integers, ClickHouse IDs are UUIDs.
+func trafficIDWidth(records []*backend.TrafficRecord) int {
+	width := len("ID")
+	for _, r := range records {
+		width = max(width, len(r.ID))
+	}
+	return width
+}
+
+// printTrafficHeader prints the column headings of printTrafficRow.
+func printTrafficHeader(idWidth int) {
+	fmt.Printf("%-*s %-19s %-7s %6s %8s %s\n", idWidth, "ID", "TIME", "METHOD", "STATUS", "DURATION", "URL")
+}
+
+// printTrafficRow prints a traffic record as a table row.
+func printTrafficRow(r *backend.TrafficRecord, idWidth int) {
+	status := fmt.Sprint(r.Status)
+	if r.Error != "" {
+		status = "ERR"
+	}
+	fmt.Printf("%-*s %-19s %-7s %6s %8s %s\n", idWidth, r.ID, r.StartTime.Local().Format("2006-01-02 15:04:05"),
+		r.Method, status, r.Duration.Round(time.Millisecond), r.URL)
+}
+
+func newTrafficShowCmd(source *trafficSourceOptions) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show the full request and response of a stored record",
+		Example: `  omniproxy traffic show 3f2a9c1e-...
+  omniproxy traffic show 3f2a9c1e-... --format curl`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runTrafficShow(source, args[0], format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format: text, json, har, curl")
+
+	return cmd
+}
+
+func runTrafficShow(source *trafficSourceOptions, id, format string) error {
+	ctx := context.Background()
+
+	src, closeFn, err := source.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	detail, err := src.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "text":
+		printTrafficDetail(os.Stdout, detail)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(detail)
+	case "har":
+		w := capture.NewHARWriter(os.Stdout)
+		w.AddRecord(detail.Record())
+		return w.Write()
+	case "curl":
+		snippet, err := export.GenerateDetail(detail, export.FormatCurl, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Println(snippet.Code)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+	return nil
+}
+
+// printTrafficDetail prints a stored request and response as HTTP messages.
+func printTrafficDetail(w io.Writer, d *backend.TrafficDetail) {
+	fmt.Fprintf(w, "%s %s\n", d.Method, d.URL)
+	printHeaders(w, d.RequestHeaders)
+	printBody(w, d.RequestBody, d.RequestIsBinary, d.RequestTruncated, d.RequestBodySize)
+	fmt.Fprintln(w)
+
+	if d.Error != "" {
+		fmt.Fprintf(w, "Error: %s\n", d.Error)
+	} else {
+		status := fmt.Sprint(d.Status)
+		if d.StatusText != "" {
+			status += " " + d.StatusText
+		}
+		fmt.Fprintf(w, "%s (%s)\n", status, d.Duration.Round(time.Millisecond))
+		printHeaders(w, d.ResponseHeaders)
+		printBody(w, d.ResponseBody, d.ResponseIsBinary, d.ResponseTruncated, d.ResponseBodySize)
+		if n := len(d.ResponseEvents); n > 0 {
+			fmt.Fprintf(w, "[%d events, first after %.1fms]\n", n, d.ResponseEvents[0].OffsetMs)
+		}
+	}
+
+	fmt.Fprintf(w, "\nID: %s  Time: %s", d.ID, d.StartTime.Local().Format(time.RFC3339))
+	if d.ClientIP != "" {
+		fmt.Fprintf(w, "  Client: %s", d.ClientIP)
+	}
+	if d.User != "" {
+		fmt.Fprintf(w, "  User: %s", d.User)
+	}
+	if len(d.Tags) > 0 {
+		fmt.Fprintf(w, "  Tags: %s", strings.Join(d.Tags, ","))
+	}
+	fmt.Fprintln(w)
+}
+
+// printHeaders prints headers sorted by name.
+func printHeaders(w io.Writer, headers map[string][]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range headers[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, v)
+		}
+	}
+}
+
+// printBody prints a stored body after a blank line.
+func printBody(w io.Writer, body string, binary, truncated bool, size int64) {
+	switch {
+	case binary:
+		fmt.Fprintf(w, "\n[binary content, %d bytes]\n", size)
+	case body != "":
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(body, "\n"))
+		if truncated {
+			fmt.Fprintf(w, "[truncated, %d bytes in total]\n", size)
+		}
+	}
+}
+
+type trafficTailOptions struct {
+	trafficFilterOptions
+	lines    int
+	follow   bool
+	interval time.Duration
+	format   string
+}
+
+func newTrafficTailCmd(source *trafficSourceOptions) *cobra.Command {
+	opts := &trafficTailOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "tail [expression]",
+		Short: "Show the most recent traffic, optionally following new records",
+		Example: `  omniproxy traffic tail -n 50
+  omniproxy traffic tail -f --host api.example.com
+  omniproxy traffic tail -f --format json 'status >= 500' | jq .URL`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runTrafficTail(source, opts, args)
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().IntVarP(&opts.lines, "lines", "n", 20, "Number of recent records to show")
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, "Keep printing new records as they are captured")
+	cmd.Flags().DurationVar(&opts.interval, "interval", time.Second, "How often to check for new records with --follow")
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Output format: table, json (one record per line)")
+
+	return cmd
+}
+
+func runTrafficTail(source *trafficSourceOptions, opts *trafficTailOptions, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.format != "table" && opts.format != "json" {
+		return fmt.Errorf("unknown format: %s", opts.format)
+	}
+	filter, err := opts.filter(args)
+	if err != nil {
+		return err
+	}
+
+	src, closeFn, err := source.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	// The most recent records, printed oldest first
+	recent := *filter
+	recent.Limit, recent.Desc = opts.lines, true
+	records, err := src.Query(ctx, &recent)
+	if err != nil {
+		return fmt.Errorf("failed to query traffic: %w", err)
+	}
+	slices.Reverse(records)
+
+	// Followed records keep the columns of the first page
+	idWidth := trafficIDWidth(records)
+	if opts.follow {
+		idWidth = max(idWidth, 8)
+	}
+	emit := func(r *backend.TrafficRecord) {
+		if opts.format == "json" {
+			data, _ := json.Marshal(r)
+			fmt.Println(string(data))
+			return
+		}
+		printTrafficRow(r, idWidth)
+	}
+	if opts.format == "table" {
+		printTrafficHeader(idWidth)
+	}
+	cursor := newTrafficCursor(filter.StartTime)
+	for _, r := range records {
+		cursor.advance(r)
+		emit(r)
+	}
+	if !opts.follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Records since the newest one seen, in capture order
+		next := *filter
+		next.StartTime, next.OrderBy, next.Desc, next.Limit = cursor.since, "started_at", false, 1000
+		records, err := src.Query(ctx, &next)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to query traffic: %w", err)
+		}
+		for _, r := range records {
+			if cursor.advance(r) {
+				emit(r)
+			}
+		}
+	}
+}
+
+// trafficCursor tracks the newest start time printed by tail, and the IDs
+// printed at that time, since records can share a timestamp.
+type trafficCursor struct {
+	since time.Time
+	seen  map[string]bool
+}
+
+func newTrafficCursor(since time.Time) *trafficCursor {
+	return &trafficCursor{since: since, seen: make(map[string]bool)}
+}
+
+// advance records r and reports whether it has not been seen before.
+func (c *trafficCursor) advance(r *backend.TrafficRecord) bool {
+	switch {
+	case r.StartTime.Before(c.since), c.seen[r.ID]:
+		return false
+	case r.StartTime.After(c.since):
+		c.since = r.StartTime
+		clear(c.seen)
+	}
+	c.seen[r.ID] = true
+	return true
+}
+
+type trafficExportOptions struct {
+	trafficFilterOptions
+	limit        int
+	format       string
+	as           string
+	placeholders bool
+	output       string
+}
+
+func newTrafficExportCmd(source *trafficSourceOptions) *cobra.Command {
+	opts := &trafficExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export [expression]",
+		Short: "Export stored traffic with full requests and responses",
+		Long: `Export stored traffic in capture order, with headers and bodies, as HAR or
+NDJSON capture records. Exports can be replayed, mocked or opened in browser
+developer tools.
+
+With --as, export code that repeats each request instead: curl, httpie, go
+(net/http), python (requests) or fetch (JavaScript). Binary request bodies are
+read from body-<id>.bin files, written next to the output. Header values
+redacted at capture time (such as authorization) can be replaced with
+placeholders to fill in with --placeholders.`,
+		Example: `  omniproxy traffic export --since 1h -o traffic.har
+  omniproxy traffic export --db sqlite://traffic.db --format ndjson 'host == api.example.com' > api.ndjson
+  omniproxy traffic export --as curl --placeholders --limit 1 'status == 500'`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runTrafficExport(source, opts, args)
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().IntVar(&opts.limit, "limit", 0, "Maximum number of records (0 = all)")
+	cmd.Flags().StringVarP(&opts.format, "format", "f", "har", "Output format: har, ndjson")
+	cmd.Flags().StringVar(&opts.as, "as", "", "Export code snippets instead: curl, httpie, go, python, fetch")
+	cmd.Flags().BoolVar(&opts.placeholders, "placeholders", false, "Replace redacted header values with placeholders (with --as)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file (default: stdout)")
+	cmd.MarkFlagsMutuallyExclusive("format", "as")
+
+	return cmd
+}
+
+// exportPageSize is the number of records requested at a time by export.
+const exportPageSize = 500
+
+func runTrafficExport(source *trafficSourceOptions, opts *trafficExportOptions, args []string) (err error) {
+	ctx := context.Background()
+
+	var snippetFormat export.Format
+	if opts.as != "" {
+		if snippetFormat, err = export.ParseFormat(opts.as); err != nil {
+			return err
+		}
+	} else if opts.format != "har" && opts.format != "ndjson" {
+		return fmt.Errorf("unknown format: %s", opts.format)
+	}
+	filter, err := opts.filter(args)
+	if err != nil {
+		return err
+	}
+	filter.OrderBy, filter.Desc = "started_at", false
+
+	src, closeFn, err := source.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var out io.Writer = os.Stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = f
+	}
+
+	write := func(d *backend.TrafficDetail) error {
+		data, err := json.Marshal(d.Record())
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+	switch {
+	case snippetFormat != "":
+		snippets := &snippetWriter{out: out, format: snippetFormat, dir: ".", placeholders: opts.placeholders}
+		if opts.output != "" {
+			snippets.dir = filepath.Dir(opts.output)
+		}
+		write = snippets.write
+	case opts.format == "har":
+		har := capture.NewHARStreamWriter(out)
+		defer func() {
+			if cerr := har.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		write = func(d *backend.TrafficDetail) error {
+			return har.WriteRecord(d.Record())
+		}
+	}
+
+	exported := 0
+	for {
+		filter.Limit = exportPageSize
+		if opts.limit > 0 {
+			filter.Limit = min(exportPageSize, opts.limit-exported)
+		}
+		records, err := src.Query(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to query traffic: %w", err)
+		}
+		for _, r := range records {
+			detail, err := src.GetByID(ctx, r.ID)
+			if err != nil {
+				return fmt.Errorf("failed to load traffic %s: %w", r.ID, err)
+			}
+			if err := write(detail); err != nil {
+				return fmt.Errorf("failed to write traffic %s: %w", r.ID, err)
+			}
+			exported++
+		}
+		if len(records) < filter.Limit || (opts.limit > 0 && exported >= opts.limit) {
+			break
+		}
+		filter.Offset += len(records)
+	}
+
+	if opts.output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d records to %s\n", exported, opts.output)
+	}
+	return nil
+}
+
+// snippetWriter writes code snippets for exported records, separated by a
+// comment naming each request, and saves binary bodies to files in dir.
+type snippetWriter struct {
+	out          io.Writer
+	format       export.Format
+	dir          string
+	placeholders bool
+	written      int
+}
+
+func (w *snippetWriter) write(d *backend.TrafficDetail) error {
+	snippet, err := export.GenerateDetail(d, w.format, &export.Options{
+		Placeholders: w.placeholders,
+		BodyFile:     "body-" + d.ID + ".bin",
+	})
+	if err != nil {
+		return err
+	}
+
+	comment := "#"
+	if w.format == export.FormatGo || w.format == export.FormatFetch {
+		comment = "//"
+	}
+	if w.written > 0 {
+		fmt.Fprintln(w.out)
+	}
+	w.written++
+	if _, err := fmt.Fprintf(w.out, "%s %s %s (%s)\n%s\n", comment, d.Method, d.URL, d.ID, strings.TrimSuffix(snippet.Code, "\n")); err != nil {
+		return err
+	}
+
+	switch {
+	case snippet.BodyFile == "":
+	case snippet.Body == nil:
+		fmt.Fprintf(os.Stderr, "Request body of %s was not captured; supply it as %s\n", d.ID, snippet.BodyFile)
+	default:
+		if err := os.WriteFile(filepath.Join(w.dir, snippet.BodyFile), snippet.Body, 0o600); err != nil {
+			return fmt.Errorf("failed to write body file: %w", err)
+		}
+	}
+	return nil
+}
+
+// openTrafficQuerier connects to a database or ClickHouse traffic store for
+// querying. Kafka sinks are write-only.
+func openTrafficQuerier(ctx context.Context, storeURL string) (backend.TrafficQuerier, func() error, error) {
+	sinkCfg, err := backend.ParseTrafficSinkURL(storeURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --db: %w", err)
+	}
+
+	switch sinkCfg.Type {
+	case backend.TrafficSinkDatabase:
+		store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{
+			DatabaseURL: storeURL,
+			ProxyName:   "default",
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return store, store.Close, nil
+	case backend.TrafficSinkClickHouse:
+		store, err := backend.NewClickHouseTrafficStore(ctx, sinkCfg.ClickHouse)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
+		}
+		return store, store.Close, nil
+	}
+	return nil, nil, fmt.Errorf("%s stores cannot be queried; use a database or ClickHouse URL", sinkCfg.Type)
+}
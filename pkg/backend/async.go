@@ -108,7 +108,7 @@ func (w *AsyncTrafficStoreWrapper) Store(ctx context.Context, rec *capture.Recor
 
 	select {
 	case w.queue <- rec:
-		w.metrics.SetQueueDepth(len(w.queue))
+		w.metrics.SetQueueDepth(w.QueueDepth())
 		return nil
 	default:
 		// Queue full - drop the record
@@ -127,9 +127,14 @@ func (w *AsyncTrafficStoreWrapper) StoreBatch(ctx context.Context, recs []*captu
 	return nil
 }
 
-// QueueDepth returns the current number of records waiting to be stored.
+// QueueDepth returns the current number of records waiting to be stored,
+// including records buffered by a BufferedTrafficStore.
 func (w *AsyncTrafficStoreWrapper) QueueDepth() int {
-	return len(w.queue)
+	depth := len(w.queue)
+	if buffered, ok := w.store.(BufferedTrafficStore); ok {
+		depth += buffered.BufferedRecords()
+	}
+	return depth
 }
 
 // Flush blocks until all queued records are stored.
@@ -199,7 +204,7 @@ func (w *AsyncTrafficStoreWrapper) worker() {
 		w.metrics.ObserveStoreDuration(time.Since(start))
 
 		batch = batch[:0]
-		w.metrics.SetQueueDepth(len(w.queue))
+		w.metrics.SetQueueDepth(w.QueueDepth())
 	}
 
 	for {
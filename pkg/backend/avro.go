@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// TrafficAvroSchema is the Avro schema of traffic records produced with
+// RecordFormatAvro, in Parsing Canonical Form. It flattens the HTTP exchange;
+// protocol-specific details (WebSocket, gRPC, rewrites) are only included
+// in the JSON format.
+const TrafficAvroSchema = `{"name":"omniproxy.TrafficRecord","type":"record","fields":[` +
+	`{"name":"proxy_id","type":"string"},` +
+	`{"name":"type","type":"string"},` +
+	`{"name":"user","type":"string"},` +
+	`{"name":"start_time_ms","type":"long"},` +
+	`{"name":"duration_ms","type":"double"},` +
+	`{"name":"ttfb_ms","type":"double"},` +
+	`{"name":"method","type":"string"},` +
+	`{"name":"url","type":"string"},` +
+	`{"name":"scheme","type":"string"},` +
+	`{"name":"host","type":"string"},` +
+	`{"name":"path","type":"string"},` +
+	`{"name":"http_version","type":"string"},` +
+	`{"name":"query","type":{"type":"map","values":"string"}},` +
+	`{"name":"request_headers","type":{"type":"map","values":"string"}},` +
+	`{"name":"request_body","type":["null","string"]},` +
+	`{"name":"request_body_size","type":"long"},` +
+	`{"name":"request_content_type","type":"string"},` +
+	`{"name":"status","type":"int"},` +
+	`{"name":"status_text","type":"string"},` +
+	`{"name":"response_headers","type":{"type":"map","values":"string"}},` +
+	`{"name":"response_body","type":["null","string"]},` +
+	`{"name":"response_body_size","type":"long"},` +
+	`{"name":"response_content_type","type":"string"},` +
+	`{"name":"response_http_version","type":"string"}]}`
+
+// avroEmpty is the CRC-64-AVRO fingerprint of the empty string.
+const avroEmpty = 0xc15d213aa4d7a795
+
+// avroTable is the CRC-64-AVRO lookup table.
+var avroTable = func() (table [256]uint64) {
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (avroEmpty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+// TrafficAvroFingerprint is the CRC-64-AVRO fingerprint of TrafficAvroSchema,
+// used in the single-object encoding header of each message.
+var TrafficAvroFingerprint = avroFingerprint(TrafficAvroSchema)
+
+// avroFingerprint returns the CRC-64-AVRO (Rabin) fingerprint of a schema.
+func avroFingerprint(schema string) uint64 {
+	fp := uint64(avroEmpty)
+	for i := 0; i < len(schema); i++ {
+		fp = (fp >> 8) ^ avroTable[byte(fp)^schema[i]]
+	}
+	return fp
+}
+
+// encodeAvro encodes rec with TrafficAvroSchema using the Avro single-object
+// encoding: the marker 0xC3 0x01, the schema fingerprint (little-endian) and
+// the binary-encoded record.
+func encodeAvro(rec *capture.Record, proxyID string) []byte {
+	b := []byte{0xc3, 0x01}
+	b = binary.LittleEndian.AppendUint64(b, TrafficAvroFingerprint)
+
+	recordType := rec.Type
+	if recordType == "" {
+		recordType = capture.RecordTypeHTTP
+	}
+
+	b = avroString(b, proxyID)
+	b = avroString(b, string(recordType))
+	b = avroString(b, rec.User)
+	b = avroLong(b, rec.StartTime.UnixMilli())
+	b = avroDouble(b, rec.DurationMs)
+	b = avroDouble(b, rec.TTFBMs)
+	b = avroString(b, rec.Request.Method)
+	b = avroString(b, rec.Request.URL)
+	b = avroString(b, rec.Request.Scheme)
+	b = avroString(b, rec.Request.Host)
+	b = avroString(b, rec.Request.Path)
+	b = avroString(b, rec.Request.HTTPVersion)
+	b = avroMap(b, rec.Request.Query.Map())
+	b = avroMap(b, rec.Request.Headers.Map())
+	b = avroBody(b, rec.Request.Body)
+	b = avroLong(b, rec.Request.BodySize)
+	b = avroString(b, rec.Request.ContentType)
+	b = avroLong(b, int64(rec.Response.Status))
+	b = avroString(b, rec.Response.StatusText)
+	b = avroMap(b, rec.Response.Headers.Map())
+	b = avroBody(b, rec.Response.Body)
+	b = avroLong(b, rec.Response.Size)
+	b = avroString(b, rec.Response.ContentType)
+	b = avroString(b, rec.Response.HTTPVersion)
+	return b
+}
+
+// avroLong appends a zig-zag varint (Avro int and long).
+func avroLong(b []byte, v int64) []byte {
+	return binary.AppendUvarint(b, uint64((v<<1)^(v>>63)))
+}
+
+func avroDouble(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func avroString(b []byte, s string) []byte {
+	b = avroLong(b, int64(len(s)))
+	return append(b, s...)
+}
+
+// avroMap appends a map as a single block, with keys sorted for
+// deterministic output.
+func avroMap(b []byte, m map[string]string) []byte {
+	if len(m) > 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b = avroLong(b, int64(len(keys)))
+		for _, k := range keys {
+			b = avroString(b, k)
+			b = avroString(b, m[k])
+		}
+	}
+	return avroLong(b, 0)
+}
+
+// avroBody appends a body as a ["null","string"] union. Bodies that are not
+// text are encoded as JSON.
+func avroBody(b []byte, body interface{}) []byte {
+	switch v := body.(type) {
+	case nil:
+		return avroLong(b, 0)
+	case string:
+		return avroString(avroLong(b, 1), v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return avroLong(b, 0)
+		}
+		return avroString(avroLong(b, 1), string(data))
+	}
+}
@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+)
+
+// CertCacheType selects a CertCache implementation.
+type CertCacheType string
+
+const (
+	// CertCacheLRU keeps the most recently used certificates (the default).
+	CertCacheLRU CertCacheType = "lru"
+	// CertCacheMemory keeps certificates for a fixed TTL.
+	CertCacheMemory CertCacheType = "memory"
+	// CertCacheRedis shares certificates between proxies through Redis.
+	CertCacheRedis CertCacheType = "redis"
+)
+
+// CertCacheConfig selects and configures the certificate cache.
+type CertCacheConfig struct {
+	// Type is the cache implementation: lru, memory or redis (default: lru).
+	Type CertCacheType `yaml:"type,omitempty"`
+
+	// Size is the maximum number of certificates an lru cache holds (default: 1000).
+	Size int `yaml:"size,omitempty"`
+
+	// TTL is how long a memory or redis cache keeps certificates
+	// (default: 1 hour for memory, 24 hours for redis).
+	TTL time.Duration `yaml:"ttl,omitempty"`
+
+	// URL is the Redis URL of a redis cache (redis://host:port/db or rediss://...).
+	URL string `yaml:"url,omitempty"`
+
+	// Metrics for observability (optional).
+	Metrics Metrics `yaml:"-"`
+}
+
+// NewCertCache creates the certificate cache described by cfg.
+func NewCertCache(cfg *CertCacheConfig) (CertCache, error) {
+	if cfg == nil {
+		cfg = &CertCacheConfig{}
+	}
+
+	switch cfg.Type {
+	case "", CertCacheLRU:
+		return NewLRUCertCache(&LRUCertCacheConfig{Capacity: cfg.Size, Metrics: cfg.Metrics}), nil
+	case CertCacheMemory:
+		return NewMemoryCertCache(&MemoryCertCacheConfig{TTL: cfg.TTL, Metrics: cfg.Metrics}), nil
+	case CertCacheRedis:
+		cache, err := NewRedisCertCache(&RedisCertCacheConfig{URL: cfg.URL, TTL: cfg.TTL, Metrics: cfg.Metrics})
+		if err != nil {
+			return nil, err
+		}
+		return cache, nil
+	default:
+		return nil, fmt.Errorf("unknown cert cache type %q (expected lru, memory or redis)", cfg.Type)
+	}
+}
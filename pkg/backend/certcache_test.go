@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+// countingMetrics counts cache lookups.
+type countingMetrics struct {
+	NoopMetrics
+	hits, misses int
+}
+
+func (m *countingMetrics) IncCacheHit()  { m.hits++ }
+func (m *countingMetrics) IncCacheMiss() { m.misses++ }
+
+func TestNewCertCache(t *testing.T) {
+	for _, typ := range []CertCacheType{"", CertCacheLRU, CertCacheMemory} {
+		metrics := &countingMetrics{}
+		cache, err := NewCertCache(&CertCacheConfig{Type: typ, Metrics: metrics})
+		if err != nil {
+			t.Fatalf("NewCertCache(%q): %v", typ, err)
+		}
+
+		if _, ok := cache.Get("example.com"); ok {
+			t.Errorf("%q: expected an empty cache", typ)
+		}
+		cache.Set("example.com", &tls.Certificate{})
+		if _, ok := cache.Get("example.com"); !ok {
+			t.Errorf("%q: expected a cached certificate", typ)
+		}
+		if metrics.hits != 1 || metrics.misses != 1 {
+			t.Errorf("%q: expected 1 hit and 1 miss, got %d and %d", typ, metrics.hits, metrics.misses)
+		}
+		_ = cache.Close()
+	}
+
+	if _, err := NewCertCache(&CertCacheConfig{Type: "disk"}); err == nil {
+		t.Error("expected error for unknown cache type")
+	}
+}
@@ -0,0 +1,1074 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+// clickHouseIdent matches database and table names that need no escaping.
+var clickHouseIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// clickHouseOrderColumns are the columns TrafficFilter.OrderBy may name.
+var clickHouseOrderColumns = map[string]bool{
+	"started_at":  true,
+	"duration_ms": true,
+	"status_code": true,
+	"host":        true,
+	"method":      true,
+	"path":        true,
+	"auth_user":   true,
+}
+
+// clickHouseSchema is the traffic table. Rows are sorted by host and time,
+// which serves per-host queries and time ranges; the bloom filter index
+// serves lookups by ID.
+const clickHouseSchema = `CREATE TABLE IF NOT EXISTS %s (
+	id UUID DEFAULT generateUUIDv4(),
+	proxy_id LowCardinality(String),
+	record_type LowCardinality(String),
+	started_at DateTime64(3, 'UTC'),
+	duration_ms Float64,
+	ttfb_ms Nullable(Float64),
+	method LowCardinality(String),
+	url String,
+	scheme LowCardinality(String),
+	host LowCardinality(String),
+	path String,
+	query String,
+	http_version LowCardinality(String),
+	stream_id UInt32,
+	request_headers Map(String, String),
+	request_body String,
+	request_body_size Int64,
+	request_is_binary Bool,
+	request_truncated Bool,
+	request_decoded_size Int64,
+	request_content_type String,
+	status_code UInt16,
+	status_text String,
+	response_headers Map(String, String),
+	response_body String,
+	response_body_size Int64,
+	response_is_binary Bool,
+	response_truncated Bool,
+	response_decoded_size Int64,
+	response_content_type String,
+	response_http_version LowCardinality(String),
+	response_events String,
+	error String,
+	connection_id String,
+	frame_sequence Int64,
+	frame_direction LowCardinality(String),
+	frame_opcode Int32,
+	close_code Int32,
+	close_reason String,
+	grpc_status Nullable(Int32),
+	grpc_message String,
+	client_ip String,
+	auth_user LowCardinality(String),
+	tags Array(String),
+	INDEX id_idx id TYPE bloom_filter GRANULARITY 4
+) ENGINE = MergeTree
+PARTITION BY toYYYYMM(started_at)
+ORDER BY (host, started_at)`
+
+// clickHouseMigrations add columns introduced after the table was first
+// created, so tables of earlier versions keep accepting inserts.
+var clickHouseMigrations = []string{
+	"ALTER TABLE %s ADD COLUMN IF NOT EXISTS request_truncated Bool AFTER request_is_binary",
+	"ALTER TABLE %s ADD COLUMN IF NOT EXISTS response_truncated Bool AFTER response_is_binary",
+	"ALTER TABLE %s ADD COLUMN IF NOT EXISTS request_decoded_size Int64 AFTER request_truncated",
+	"ALTER TABLE %s ADD COLUMN IF NOT EXISTS response_decoded_size Int64 AFTER response_truncated",
+	"ALTER TABLE %s ADD COLUMN IF NOT EXISTS response_events String AFTER response_http_version",
+}
+
+// clickHouseSettings are sent with every request so responses decode into
+// Go types: 64-bit integers as numbers and times in RFC 3339.
+var clickHouseSettings = url.Values{
+	"output_format_json_quote_64bit_integers": {"0"},
+	"date_time_input_format":                  {"best_effort"},
+	"date_time_output_format":                 {"iso"},
+}
+
+// ClickHouseTrafficStore stores traffic records in ClickHouse through its
+// HTTP interface. Suitable for production mode, where Stats and Query run
+// server-side over millions of records.
+type ClickHouseTrafficStore struct {
+	client   *http.Client
+	endpoint string
+	user     string
+	password string
+	database string
+	table    string
+	proxyID  string
+	metrics  Metrics
+	mu       sync.RWMutex
+	closed   bool
+}
+
+// ClickHouseTrafficStoreConfig configures a ClickHouseTrafficStore.
+type ClickHouseTrafficStoreConfig struct {
+	// URL is the HTTP interface URL: http(s)://[user[:password]@]host:8123
+	URL string
+
+	// Database is the database holding the table (default: "default").
+	Database string
+
+	// Table is the traffic table, created if missing (default: "traffic").
+	Table string
+
+	// ProxyID identifies this proxy in the records (default: "default").
+	ProxyID string
+
+	// Timeout bounds each request (default: 30 seconds).
+	Timeout time.Duration
+
+	// TLS configures https:// connections (optional).
+	TLS *tls.Config
+
+	// Metrics for observability (optional).
+	Metrics Metrics
+}
+
+// NewClickHouseTrafficStore connects to ClickHouse, creates the traffic
+// table if needed and returns a traffic store.
+func NewClickHouseTrafficStore(ctx context.Context, cfg *ClickHouseTrafficStoreConfig) (*ClickHouseTrafficStore, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, errors.New("clickhouse traffic store requires a URL")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clickhouse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported clickhouse URL scheme: %s (expected http or https)", u.Scheme)
+	}
+
+	database := cfg.Database
+	if database == "" {
+		database = "default"
+	}
+	table := cfg.Table
+	if table == "" {
+		table = "traffic"
+	}
+	if !clickHouseIdent.MatchString(database) || !clickHouseIdent.MatchString(table) {
+		return nil, fmt.Errorf("invalid clickhouse table name: %s.%s", database, table)
+	}
+
+	proxyID := cfg.ProxyID
+	if proxyID == "" {
+		proxyID = "default"
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = NoopMetrics{}
+	}
+
+	store := &ClickHouseTrafficStore{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: cfg.TLS, MaxIdleConnsPerHost: 8},
+		},
+		database: database,
+		table:    fmt.Sprintf("`%s`.`%s`", database, table),
+		proxyID:  proxyID,
+		metrics:  metrics,
+	}
+	if u.User != nil {
+		store.user = u.User.Username()
+		store.password, _ = u.User.Password()
+		u.User = nil
+	}
+	u.Path, u.RawQuery = "/", ""
+	store.endpoint = u.String()
+
+	if err := store.exec(ctx, fmt.Sprintf(clickHouseSchema, store.table), nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to create clickhouse table: %w", err)
+	}
+	for _, m := range clickHouseMigrations {
+		if err := store.exec(ctx, fmt.Sprintf(m, store.table), nil, nil); err != nil {
+			return nil, fmt.Errorf("failed to migrate clickhouse table: %w", err)
+		}
+	}
+
+	return store, nil
+}
+
+// clickHouseRow is a traffic table row in JSONEachRow format.
+type clickHouseRow struct {
+	ID                  string            `json:"id,omitempty"`
+	ProxyID             string            `json:"proxy_id"`
+	RecordType          string            `json:"record_type"`
+	StartedAt           string            `json:"started_at"`
+	DurationMs          float64           `json:"duration_ms"`
+	TTFBMs              *float64          `json:"ttfb_ms"`
+	Method              string            `json:"method"`
+	URL                 string            `json:"url"`
+	Scheme              string            `json:"scheme"`
+	Host                string            `json:"host"`
+	Path                string            `json:"path"`
+	Query               string            `json:"query"`
+	HTTPVersion         string            `json:"http_version"`
+	StreamID            uint32            `json:"stream_id"`
+	RequestHeaders      map[string]string `json:"request_headers"`
+	RequestBody         string            `json:"request_body"`
+	RequestBodySize     int64             `json:"request_body_size"`
+	RequestIsBinary     bool              `json:"request_is_binary"`
+	RequestTruncated    bool              `json:"request_truncated"`
+	RequestDecodedSize  int64             `json:"request_decoded_size"`
+	RequestContentType  string            `json:"request_content_type"`
+	StatusCode          int               `json:"status_code"`
+	StatusText          string            `json:"status_text"`
+	ResponseHeaders     map[string]string `json:"response_headers"`
+	ResponseBody        string            `json:"response_body"`
+	ResponseBodySize    int64             `json:"response_body_size"`
+	ResponseIsBinary    bool              `json:"response_is_binary"`
+	ResponseTruncated   bool              `json:"response_truncated"`
+	ResponseDecodedSize int64             `json:"response_decoded_size"`
+	ResponseContentType string            `json:"response_content_type"`
+	ResponseHTTPVersion string            `json:"response_http_version"`
+	ResponseEvents      string            `json:"response_events"`
+	Error               string            `json:"error"`
+	ConnectionID        string            `json:"connection_id"`
+	FrameSequence       int64             `json:"frame_sequence"`
+	FrameDirection      string            `json:"frame_direction"`
+	FrameOpcode         int               `json:"frame_opcode"`
+	CloseCode           int               `json:"close_code"`
+	CloseReason         string            `json:"close_reason"`
+	GRPCStatus          *int              `json:"grpc_status"`
+	GRPCMessage         string            `json:"grpc_message"`
+	ClientIP            string            `json:"client_ip"`
+	AuthUser            string            `json:"auth_user"`
+	Tags                []string          `json:"tags"`
+}
+
+// trafficRow converts a capture.Record into a table row.
+func (s *ClickHouseTrafficStore) trafficRow(rec *capture.Record) *clickHouseRow {
+	row := &clickHouseRow{
+		ProxyID:             s.proxyID,
+		RecordType:          string(rec.Type),
+		StartedAt:           rec.StartTime.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		DurationMs:          rec.DurationMs,
+		Method:              rec.Request.Method,
+		URL:                 rec.Request.URL,
+		Scheme:              rec.Request.Scheme,
+		Host:                rec.Request.Host,
+		Path:                rec.Request.Path,
+		HTTPVersion:         rec.Request.HTTPVersion,
+		StreamID:            rec.StreamID,
+		RequestHeaders:      rec.Request.Headers.Map(),
+		RequestBody:         bodyText(rec.Request.Body),
+		RequestBodySize:     rec.Request.BodySize,
+		RequestIsBinary:     rec.Request.IsBinary,
+		RequestTruncated:    rec.Request.Truncated,
+		RequestDecodedSize:  rec.Request.DecodedSize,
+		RequestContentType:  rec.Request.ContentType,
+		StatusCode:          rec.Response.Status,
+		StatusText:          rec.Response.StatusText,
+		ResponseHeaders:     rec.Response.Headers.Map(),
+		ResponseBody:        bodyText(rec.Response.Body),
+		ResponseBodySize:    rec.Response.Size,
+		ResponseIsBinary:    rec.Response.IsBinary,
+		ResponseTruncated:   rec.Response.Truncated,
+		ResponseDecodedSize: rec.Response.DecodedSize,
+		ResponseContentType: rec.Response.ContentType,
+		ResponseHTTPVersion: rec.Response.HTTPVersion,
+		AuthUser:            rec.User,
+		ClientIP:            rec.ClientIP,
+	}
+	if row.RecordType == "" {
+		row.RecordType = string(capture.RecordTypeHTTP)
+	}
+	if rec.Request.Query != nil {
+		queryJSON, _ := json.Marshal(rec.Request.Query)
+		row.Query = string(queryJSON)
+	}
+	if rec.Response.Events != nil {
+		eventsJSON, _ := json.Marshal(rec.Response.Events)
+		row.ResponseEvents = string(eventsJSON)
+	}
+	if rec.TTFBMs > 0 {
+		ttfb := rec.TTFBMs
+		row.TTFBMs = &ttfb
+	}
+
+	// Frame payloads go to the request or response body, as in the database store
+	if ws := rec.WebSocket; ws != nil {
+		row.ConnectionID = ws.ConnectionID
+		if ws.Sequence != 0 {
+			row.FrameSequence = ws.Sequence
+			row.FrameDirection = string(ws.Direction)
+			row.FrameOpcode = ws.Opcode
+			row.CloseCode = ws.CloseCode
+			row.CloseReason = ws.CloseReason
+			if ws.Direction == capture.DirectionClientToServer {
+				row.RequestBody, row.RequestBodySize, row.RequestIsBinary = bodyText(ws.Payload), ws.PayloadSize, ws.IsBinary
+			} else {
+				row.ResponseBody, row.ResponseBodySize, row.ResponseIsBinary = bodyText(ws.Payload), ws.PayloadSize, ws.IsBinary
+			}
+		}
+	}
+	if g := rec.GRPC; g != nil {
+		status := g.Status
+		row.GRPCStatus = &status
+		row.GRPCMessage = g.Message
+	}
+	if rec.NetworkProfile != "" {
+		row.Tags = []string{capture.NetworkProfileTag(rec.NetworkProfile)}
+	}
+	return row
+}
+
+// bodyText returns a captured body as text. Bodies that are not text are
+// encoded as JSON.
+func bodyText(body interface{}) string {
+	switch v := body.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+// Store saves a single traffic record.
+func (s *ClickHouseTrafficStore) Store(ctx context.Context, rec *capture.Record) error {
+	if rec == nil {
+		return nil
+	}
+	return s.StoreBatch(ctx, []*capture.Record{rec})
+}
+
+// StoreBatch saves traffic records with a single INSERT. ClickHouse favours
+// few large inserts, so use it behind an AsyncTrafficStoreWrapper with a
+// large batch size.
+func (s *ClickHouseTrafficStore) StoreBatch(ctx context.Context, recs []*capture.Record) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	n := 0
+	for _, rec := range recs {
+		if rec == nil {
+			continue
+		}
+		if err := enc.Encode(s.trafficRow(rec)); err != nil {
+			s.metrics.IncStoreError()
+			return fmt.Errorf("failed to encode traffic record: %w", err)
+		}
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := s.exec(ctx, "INSERT INTO "+s.table+" FORMAT JSONEachRow", nil, &buf)
+	s.metrics.ObserveStoreDuration(time.Since(start))
+
+	if err != nil {
+		s.metrics.IncStoreError()
+		return fmt.Errorf("failed to store traffic batch: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		s.metrics.IncStoreSuccess()
+	}
+	return nil
+}
+
+// Ping checks that ClickHouse is reachable and accepts the credentials.
+func (s *ClickHouseTrafficStore) Ping(ctx context.Context) error {
+	if err := s.exec(ctx, "SELECT 1", nil, nil); err != nil {
+		return fmt.Errorf("failed to ping clickhouse: %w", err)
+	}
+	return nil
+}
+
+// Close releases idle connections to ClickHouse.
+func (s *ClickHouseTrafficStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Query returns traffic records matching the filter, newest first by default.
+func (s *ClickHouseTrafficStore) Query(ctx context.Context, filter *TrafficFilter) ([]*TrafficRecord, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	q, err := newClickHouseQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	sql := "SELECT id, method, url, host, path, status_code, duration_ms, started_at, error," +
+		" record_type, connection_id, auth_user FROM " + s.table + q.where()
+
+	order, desc := "started_at", true
+	if filter != nil && filter.OrderBy != "" {
+		if !clickHouseOrderColumns[filter.OrderBy] {
+			return nil, fmt.Errorf("cannot order traffic by %q", filter.OrderBy)
+		}
+		order, desc = filter.OrderBy, filter.Desc
+	}
+	sql += " ORDER BY " + order
+	if desc {
+		sql += " DESC"
+	}
+	if filter != nil && filter.Limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", filter.Limit)
+		if filter.Offset > 0 {
+			sql += fmt.Sprintf(" OFFSET %d", filter.Offset)
+		}
+	}
+
+	rows, err := clickHouseSelect[clickHouseRow](ctx, s, sql, q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traffic: %w", err)
+	}
+
+	result := make([]*TrafficRecord, len(rows))
+	for i := range rows {
+		result[i] = rows[i].trafficRecord()
+	}
+	return result, nil
+}
+
+// GetByID returns full traffic details for a single record.
+func (s *ClickHouseTrafficStore) GetByID(ctx context.Context, id string) (*TrafficDetail, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	sql := "SELECT * FROM " + s.table + " WHERE id = toUUIDOrZero({id:String}) LIMIT 1"
+	rows, err := clickHouseSelect[clickHouseRow](ctx, s, sql, url.Values{"param_id": {id}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traffic record: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("failed to get traffic record: %s not found", id)
+	}
+
+	r := &rows[0]
+	detail := &TrafficDetail{
+		TrafficRecord:       *r.trafficRecord(),
+		Scheme:              r.Scheme,
+		Query:               r.Query,
+		RequestHeaders:      headerValues(r.RequestHeaders),
+		RequestBodySize:     r.RequestBodySize,
+		RequestIsBinary:     r.RequestIsBinary,
+		RequestTruncated:    r.RequestTruncated,
+		RequestDecodedSize:  r.RequestDecodedSize,
+		RequestContentType:  r.RequestContentType,
+		HTTPVersion:         r.HTTPVersion,
+		StreamID:            r.StreamID,
+		StatusText:          r.StatusText,
+		ResponseHeaders:     headerValues(r.ResponseHeaders),
+		ResponseBodySize:    r.ResponseBodySize,
+		ResponseIsBinary:    r.ResponseIsBinary,
+		ResponseTruncated:   r.ResponseTruncated,
+		ResponseDecodedSize: r.ResponseDecodedSize,
+		ResponseContentType: r.ResponseContentType,
+		ResponseHTTPVersion: r.ResponseHTTPVersion,
+		TTFBMs:              r.TTFBMs,
+		FrameSequence:       r.FrameSequence,
+		FrameDirection:      r.FrameDirection,
+		FrameOpcode:         r.FrameOpcode,
+		CloseCode:           r.CloseCode,
+		CloseReason:         r.CloseReason,
+		GRPCStatus:          r.GRPCStatus,
+		GRPCMessage:         r.GRPCMessage,
+		ClientIP:            r.ClientIP,
+		Tags:                r.Tags,
+	}
+	if !r.RequestIsBinary {
+		detail.RequestBody = r.RequestBody
+	}
+	if !r.ResponseIsBinary {
+		detail.ResponseBody = r.ResponseBody
+	}
+	if r.ResponseEvents != "" {
+		_ = json.Unmarshal([]byte(r.ResponseEvents), &detail.ResponseEvents)
+	}
+	return detail, nil
+}
+
+// Count returns the number of records matching the filter.
+func (s *ClickHouseTrafficStore) Count(ctx context.Context, filter *TrafficFilter) (int64, error) {
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+
+	q, err := newClickHouseQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := clickHouseSelect[struct {
+		Count int64 `json:"count"`
+	}](ctx, s, "SELECT count() AS count FROM "+s.table+q.where(), q.params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count traffic: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Count, nil
+}
+
+// clickHouseStatsRow is an aggregate row of Stats.
+type clickHouseStatsRow struct {
+	Key       json.RawMessage `json:"key"`
+	Requests  int64           `json:"requests"`
+	Errors    int64           `json:"errors"`
+	AvgMs     float64         `json:"avg_ms"`
+	Quantiles []float64       `json:"quantiles"`
+	Hosts     int64           `json:"hosts"`
+}
+
+// quantile returns the i-th of the P50, P95 and P99 durations.
+func (r *clickHouseStatsRow) quantile(i int) float64 {
+	if i < len(r.Quantiles) {
+		return r.Quantiles[i]
+	}
+	return 0
+}
+
+// Stats returns aggregate statistics for traffic matching the filter. The
+// percentiles use ClickHouse's quantile functions, so they are computed
+// server-side over all matching records.
+func (s *ClickHouseTrafficStore) Stats(ctx context.Context, filter *TrafficFilter) (*TrafficStats, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	q, err := newClickHouseQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	from := " FROM " + s.table + q.where()
+	aggregates := "count() AS requests, countIf(status_code >= 400) AS errors, avg(duration_ms) AS avg_ms," +
+		" quantiles(0.5, 0.95, 0.99)(duration_ms) AS quantiles"
+
+	totals, err := clickHouseSelect[clickHouseStatsRow](ctx, s, "SELECT "+aggregates+", uniqExact(host) AS hosts"+from, q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute traffic stats: %w", err)
+	}
+
+	stats := &TrafficStats{
+		RequestsByMethod: make(map[string]int64),
+		RequestsByStatus: make(map[int]int64),
+	}
+	if len(totals) > 0 {
+		t := &totals[0]
+		stats.TotalRequests = t.Requests
+		stats.TotalErrors = t.Errors
+		stats.AvgDurationMs = t.AvgMs
+		stats.P50DurationMs = t.quantile(0)
+		stats.P95DurationMs = t.quantile(1)
+		stats.P99DurationMs = t.quantile(2)
+		stats.UniqueHosts = t.Hosts
+	}
+
+	methods, err := clickHouseSelect[clickHouseStatsRow](ctx, s, "SELECT method AS key, count() AS requests"+from+" GROUP BY method", q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute traffic stats: %w", err)
+	}
+	for _, m := range methods {
+		var method string
+		_ = json.Unmarshal(m.Key, &method)
+		stats.RequestsByMethod[method] = m.Requests
+	}
+
+	statuses, err := clickHouseSelect[clickHouseStatsRow](ctx, s, "SELECT status_code AS key, count() AS requests"+from+" GROUP BY status_code", q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute traffic stats: %w", err)
+	}
+	for _, st := range statuses {
+		var status int
+		_ = json.Unmarshal(st.Key, &status)
+		stats.RequestsByStatus[status] = st.Requests
+	}
+
+	hosts, err := clickHouseSelect[clickHouseStatsRow](ctx, s, fmt.Sprintf("SELECT host AS key, %s%s GROUP BY host ORDER BY requests DESC LIMIT %d",
+		aggregates, from, hostStatsLimit), q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute traffic stats: %w", err)
+	}
+	for _, h := range hosts {
+		hs := HostStats{
+			Requests:      h.Requests,
+			Errors:        h.Errors,
+			AvgDurationMs: h.AvgMs,
+			P50DurationMs: h.quantile(0),
+			P95DurationMs: h.quantile(1),
+			P99DurationMs: h.quantile(2),
+		}
+		_ = json.Unmarshal(h.Key, &hs.Host)
+		stats.Hosts = append(stats.Hosts, hs)
+	}
+
+	return stats, nil
+}
+
+// trafficRecord converts a row to the list view.
+func (r *clickHouseRow) trafficRecord() *TrafficRecord {
+	startedAt, _ := time.Parse(time.RFC3339Nano, r.StartedAt)
+	return &TrafficRecord{
+		ID:           r.ID,
+		Method:       r.Method,
+		URL:          r.URL,
+		Host:         r.Host,
+		Path:         r.Path,
+		Status:       r.StatusCode,
+		Duration:     time.Duration(r.DurationMs * float64(time.Millisecond)),
+		StartTime:    startedAt,
+		Error:        r.Error,
+		RecordType:   r.RecordType,
+		ConnectionID: r.ConnectionID,
+		User:         r.AuthUser,
+	}
+}
+
+// headerValues converts stored headers to the multi-value form of TrafficDetail.
+func headerValues(h map[string]string) map[string][]string {
+	if len(h) == 0 {
+		return nil
+	}
+	headers := make(map[string][]string, len(h))
+	for k, v := range h {
+		headers[k] = []string{v}
+	}
+	return headers
+}
+
+// clickHouseQuery builds a WHERE clause whose values are sent as query
+// parameters, so they are never interpolated into the SQL.
+type clickHouseQuery struct {
+	conds  []string
+	params url.Values
+}
+
+// newClickHouseQuery translates a TrafficFilter into conditions.
+func newClickHouseQuery(filter *TrafficFilter) (*clickHouseQuery, error) {
+	q := &clickHouseQuery{params: url.Values{}}
+	if filter == nil {
+		return q, nil
+	}
+
+	if !filter.StartTime.IsZero() {
+		q.conds = append(q.conds, "started_at >= fromUnixTimestamp64Milli("+q.param("Int64", strconv.FormatInt(filter.StartTime.UnixMilli(), 10))+")")
+	}
+	if !filter.EndTime.IsZero() {
+		q.conds = append(q.conds, "started_at <= fromUnixTimestamp64Milli("+q.param("Int64", strconv.FormatInt(filter.EndTime.UnixMilli(), 10))+")")
+	}
+	q.match("host", filter.Hosts)
+	q.match("path", filter.Paths)
+	q.in("method", "String", filter.Methods)
+	q.in("record_type", "String", filter.RecordTypes)
+
+	if len(filter.StatusCodes) > 0 {
+		codes := make([]string, len(filter.StatusCodes))
+		for i, code := range filter.StatusCodes {
+			codes[i] = strconv.Itoa(code)
+		}
+		q.in("status_code", "UInt16", codes)
+	}
+	if filter.MinStatus > 0 {
+		q.conds = append(q.conds, "status_code >= "+q.param("UInt16", strconv.Itoa(filter.MinStatus)))
+	}
+	if filter.MaxStatus > 0 {
+		q.conds = append(q.conds, "status_code <= "+q.param("UInt16", strconv.Itoa(filter.MaxStatus)))
+	}
+	if filter.ConnectionID != "" {
+		q.conds = append(q.conds, "connection_id = "+q.param("String", filter.ConnectionID))
+	}
+	if filter.User != "" {
+		q.conds = append(q.conds, "auth_user = "+q.param("String", filter.User))
+	}
+
+	for _, h := range filter.Headers {
+		if h.Value == "" {
+			q.conds = append(q.conds, q.headerCond(h.Response, h.Name, query.OpExists, ""))
+		} else {
+			q.conds = append(q.conds, q.headerCond(h.Response, h.Name, query.OpContains, h.Value))
+		}
+	}
+	if filter.BodyContains != "" {
+		q.conds = append(q.conds, q.bodyContainsCond(filter.BodyContains))
+	}
+	for _, f := range filter.BodyFields {
+		segments, err := query.ParseJSONPath(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if f.Value == "" {
+			q.conds = append(q.conds, q.jsonFieldCond(f.Response, segments, query.OpExists, ""))
+		} else {
+			q.conds = append(q.conds, q.jsonFieldCond(f.Response, segments, query.OpEq, f.Value))
+		}
+	}
+
+	if filter.MinDuration > 0 {
+		q.conds = append(q.conds, "duration_ms >= "+q.param("Float64", strconv.FormatFloat(float64(filter.MinDuration.Microseconds())/1000, 'f', -1, 64)))
+	}
+	if filter.MaxDuration > 0 {
+		q.conds = append(q.conds, "duration_ms <= "+q.param("Float64", strconv.FormatFloat(float64(filter.MaxDuration.Microseconds())/1000, 'f', -1, 64)))
+	}
+	for _, tag := range filter.Tags {
+		q.conds = append(q.conds, "has(tags, "+q.param("String", tag)+")")
+	}
+	if filter.ClientIP != "" {
+		q.conds = append(q.conds, "client_ip = "+q.param("String", filter.ClientIP))
+	}
+
+	if filter.Query != nil {
+		cond, err := q.exprCond(filter.Query.Expr)
+		if err != nil {
+			return nil, err
+		}
+		q.conds = append(q.conds, cond)
+	}
+	return q, nil
+}
+
+// exprCond translates a filter expression into a condition.
+func (q *clickHouseQuery) exprCond(e query.Expr) (string, error) {
+	switch e := e.(type) {
+	case *query.And, *query.Or:
+		var left, right query.Expr
+		op := " AND "
+		if and, ok := e.(*query.And); ok {
+			left, right = and.Left, and.Right
+		} else {
+			or := e.(*query.Or)
+			left, right, op = or.Left, or.Right, " OR "
+		}
+		l, err := q.exprCond(left)
+		if err != nil {
+			return "", err
+		}
+		r, err := q.exprCond(right)
+		if err != nil {
+			return "", err
+		}
+		return "(" + l + op + r + ")", nil
+	case *query.Not:
+		x, err := q.exprCond(e.X)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + x + ")", nil
+	case *query.Compare:
+		return q.compareCond(e)
+	}
+	return "", fmt.Errorf("unsupported query expression %T", e)
+}
+
+// compareCond translates a comparison into a condition.
+func (q *clickHouseQuery) compareCond(c *query.Compare) (string, error) {
+	v := c.Value()
+
+	switch c.Op {
+	case query.OpNe, query.OpNotMatch:
+		if c.Field.Name != query.FieldStatus && c.Field.Name != query.FieldDuration {
+			pos := *c
+			pos.Op = query.OpEq
+			if c.Op == query.OpNotMatch {
+				pos.Op = query.OpMatch
+			}
+			cond, err := q.compareCond(&pos)
+			if err != nil {
+				return "", err
+			}
+			return "NOT (" + cond + ")", nil
+		}
+	}
+
+	switch c.Field.Name {
+	case query.FieldStatus, query.FieldDuration:
+		column, typ := "status_code", "UInt16"
+		if c.Field.Name == query.FieldDuration {
+			column, typ = "duration_ms", "Float64"
+		}
+		if c.Op == query.OpIn {
+			values := make([]string, len(c.Values))
+			for i, v := range c.Values {
+				values[i] = strconv.FormatFloat(v.Num, 'f', -1, 64)
+			}
+			return q.inCond(column, typ, values), nil
+		}
+		return column + " " + clickHouseOps[c.Op] + " " + q.param(typ, strconv.FormatFloat(v.Num, 'f', -1, 64)), nil
+	case query.FieldTag:
+		conds := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			conds[i] = "has(tags, " + q.param("String", v.Str) + ")"
+		}
+		if len(conds) == 1 {
+			return conds[0], nil
+		}
+		return "(" + strings.Join(conds, " OR ") + ")", nil
+	case query.FieldBody:
+		return q.bodyContainsCond(v.Str), nil
+	case query.FieldRequestHeader, query.FieldResponseHeader:
+		return q.headerCond(c.Field.Name == query.FieldResponseHeader, c.Field.Key, c.Op, v.Str), nil
+	case query.FieldRequestJSON, query.FieldResponseJSON:
+		return q.jsonFieldCond(c.Field.Name == query.FieldResponseJSON, c.Field.Path, c.Op, v.Str), nil
+	}
+
+	column, ok := queryColumns[c.Field.Name]
+	if !ok {
+		return "", fmt.Errorf("unsupported query field %s", c.Field.Name)
+	}
+	if c.Op == query.OpIn {
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = v.Str
+		}
+		return q.inCond(column, "String", values), nil
+	}
+	return q.stringCond(column, c.Op, v.Str), nil
+}
+
+// clickHouseOps maps numeric comparison operators to SQL.
+var clickHouseOps = map[query.Op]string{
+	query.OpEq: "=",
+	query.OpNe: "!=",
+	query.OpGt: ">",
+	query.OpGe: ">=",
+	query.OpLt: "<",
+	query.OpLe: "<=",
+}
+
+// stringCond compares the SQL expression expr with value: equality, a
+// case-sensitive wildcard match, or a case-insensitive substring.
+func (q *clickHouseQuery) stringCond(expr string, op query.Op, value string) string {
+	switch op {
+	case query.OpMatch:
+		return expr + " LIKE " + q.param("String", wildcardLike(value))
+	case query.OpContains:
+		return "positionCaseInsensitiveUTF8(" + expr + ", " + q.param("String", value) + ") > 0"
+	}
+	return expr + " = " + q.param("String", value)
+}
+
+// headerCond matches a header that is present (OpExists) or whose value
+// compares with value as stringCond does.
+func (q *clickHouseQuery) headerCond(response bool, name string, op query.Op, value string) string {
+	column := "request_headers"
+	if response {
+		column = "response_headers"
+	}
+	key := q.param("String", strings.ToLower(name))
+	if op == query.OpExists {
+		return "mapContains(" + column + ", " + key + ")"
+	}
+	cond := q.stringCond(column+"["+key+"]", op, value)
+	if op != query.OpContains || value == "" {
+		// Missing keys read as empty strings
+		cond = "(mapContains(" + column + ", " + key + ") AND " + cond + ")"
+	}
+	return cond
+}
+
+// bodyContainsCond matches text in the request or response body.
+func (q *clickHouseQuery) bodyContainsCond(text string) string {
+	p := q.param("String", text)
+	return "(positionCaseInsensitiveUTF8(request_body, " + p + ") > 0 OR positionCaseInsensitiveUTF8(response_body, " + p + ") > 0)"
+}
+
+// jsonFieldCond matches a JSON body field that is present (OpExists) or
+// equal to value as text.
+func (q *clickHouseQuery) jsonFieldCond(response bool, segments []query.PathSegment, op query.Op, value string) string {
+	args := "request_body"
+	if response {
+		args = "response_body"
+	}
+	for _, seg := range segments {
+		if seg.Key == "" {
+			// ClickHouse array indexes start at 1
+			args += ", " + q.param("Int64", strconv.Itoa(seg.Index+1))
+		} else {
+			args += ", " + q.param("String", seg.Key)
+		}
+	}
+	if op == query.OpExists {
+		return "JSONHas(" + args + ")"
+	}
+	cond := "trim(BOTH '\"' FROM JSONExtractRaw(" + args + ")) = " + q.param("String", value)
+	if value == "" {
+		// Missing fields read as empty strings
+		cond = "(JSONHas(" + args + ") AND " + cond + ")"
+	}
+	return cond
+}
+
+// param adds a query parameter and returns its placeholder.
+func (q *clickHouseQuery) param(typ, value string) string {
+	name := fmt.Sprintf("p%d", len(q.params))
+	q.params.Set("param_"+name, value)
+	return "{" + name + ":" + typ + "}"
+}
+
+// in adds a condition matching any of values.
+func (q *clickHouseQuery) in(column, typ string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	q.conds = append(q.conds, q.inCond(column, typ, values))
+}
+
+// inCond returns a condition matching any of values.
+func (q *clickHouseQuery) inCond(column, typ string, values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = q.param(typ, v)
+	}
+	return column + " IN (" + strings.Join(placeholders, ", ") + ")"
+}
+
+// match adds a condition matching any of patterns, where * is a wildcard.
+func (q *clickHouseQuery) match(column string, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+	var exact, conds []string
+	for _, p := range patterns {
+		if !strings.Contains(p, "*") {
+			exact = append(exact, p)
+			continue
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(p)
+		conds = append(conds, column+" LIKE "+q.param("String", strings.ReplaceAll(escaped, "*", "%")))
+	}
+	if len(exact) > 0 {
+		placeholders := make([]string, len(exact))
+		for i, v := range exact {
+			placeholders[i] = q.param("String", v)
+		}
+		conds = append(conds, column+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if len(conds) == 1 {
+		q.conds = append(q.conds, conds[0])
+	} else {
+		q.conds = append(q.conds, "("+strings.Join(conds, " OR ")+")")
+	}
+}
+
+// where returns the WHERE clause, or an empty string without conditions.
+func (q *clickHouseQuery) where() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conds, " AND ")
+}
+
+// clickHouseSelect runs a SELECT and decodes its JSONEachRow output.
+func clickHouseSelect[T any](ctx context.Context, s *ClickHouseTrafficStore, sql string, params url.Values) ([]T, error) {
+	var rows []T
+	err := s.do(ctx, sql+" FORMAT JSONEachRow", params, nil, func(body io.Reader) error {
+		dec := json.NewDecoder(body)
+		for {
+			var row T
+			if err := dec.Decode(&row); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("failed to decode clickhouse response: %w", err)
+			}
+			rows = append(rows, row)
+		}
+	})
+	return rows, err
+}
+
+// exec runs a statement that returns no rows, sending data as its input.
+func (s *ClickHouseTrafficStore) exec(ctx context.Context, sql string, params url.Values, data io.Reader) error {
+	return s.do(ctx, sql, params, data, nil)
+}
+
+// do sends a statement to the HTTP interface. The statement is sent in the
+// query string so the request body can carry INSERT data.
+func (s *ClickHouseTrafficStore) do(ctx context.Context, sql string, params url.Values, data io.Reader, read func(io.Reader) error) error {
+	values := url.Values{"query": {sql}, "database": {s.database}}
+	for k, v := range clickHouseSettings {
+		values[k] = v
+	}
+	for k, v := range params {
+		values[k] = v
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"?"+values.Encode(), data)
+	if err != nil {
+		return err
+	}
+	if s.user != "" {
+		req.Header.Set("X-ClickHouse-User", s.user)
+		req.Header.Set("X-ClickHouse-Key", s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("clickhouse returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if read == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return read(resp.Body)
+}
+
+// checkOpen returns an error once the store is closed.
+func (s *ClickHouseTrafficStore) checkOpen() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("store is closed")
+	}
+	return nil
+}
+
+// Ensure ClickHouseTrafficStore implements TrafficStore and TrafficQuerier.
+var (
+	_ TrafficStore   = (*ClickHouseTrafficStore)(nil)
+	_ TrafficQuerier = (*ClickHouseTrafficStore)(nil)
+)
@@ -0,0 +1,328 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// clickHouseServer stands in for the ClickHouse HTTP interface. It records
+// every statement and answers SELECTs with canned JSONEachRow output.
+type clickHouseServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []clickHouseRequest
+	replies  map[string]string // SQL prefix to response
+}
+
+type clickHouseRequest struct {
+	sql    string
+	params url.Values
+	user   string
+	rows   []map[string]interface{}
+}
+
+func newClickHouseServer(t *testing.T) *clickHouseServer {
+	t.Helper()
+	s := &clickHouseServer{replies: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *clickHouseServer) handle(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	req := clickHouseRequest{sql: params.Get("query"), params: params, user: r.Header.Get("X-ClickHouse-User")}
+
+	if r.Header.Get("X-ClickHouse-Key") != "secret" {
+		http.Error(w, "Code: 516. DB::Exception: default: Authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	sc := bufio.NewScanner(r.Body)
+	for sc.Scan() {
+		var row map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &row); err == nil {
+			req.rows = append(req.rows, row)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	for prefix, reply := range s.replies {
+		if strings.HasPrefix(req.sql, prefix) {
+			_, _ = io.WriteString(w, reply)
+			return
+		}
+	}
+}
+
+// last returns the most recent statement starting with prefix.
+func (s *clickHouseServer) last(t *testing.T, prefix string) clickHouseRequest {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if strings.HasPrefix(s.requests[i].sql, prefix) {
+			return s.requests[i]
+		}
+	}
+	t.Fatalf("no statement starting with %q", prefix)
+	return clickHouseRequest{}
+}
+
+func (s *clickHouseServer) reply(prefix, body string) {
+	s.mu.Lock()
+	s.replies[prefix] = body
+	s.mu.Unlock()
+}
+
+func newTestClickHouseStore(t *testing.T, s *clickHouseServer) *ClickHouseTrafficStore {
+	t.Helper()
+	u, _ := url.Parse(s.URL)
+	u.User = url.UserPassword("proxy", "secret")
+	store, err := NewClickHouseTrafficStore(context.Background(), &ClickHouseTrafficStoreConfig{
+		URL:      u.String(),
+		Database: "omniproxy",
+		ProxyID:  "edge-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestClickHouseTrafficStore(t *testing.T) {
+	server := newClickHouseServer(t)
+
+	if _, err := NewClickHouseTrafficStore(context.Background(), &ClickHouseTrafficStoreConfig{URL: server.URL}); err == nil ||
+		!strings.Contains(err.Error(), "Authentication failed") {
+		t.Errorf("expected the server error, got %v", err)
+	}
+
+	store := newTestClickHouseStore(t, server)
+
+	create := server.last(t, "CREATE TABLE")
+	if !strings.Contains(create.sql, "IF NOT EXISTS `omniproxy`.`traffic`") || !strings.Contains(create.sql, "ORDER BY (host, started_at)") {
+		t.Errorf("unexpected schema: %s", create.sql)
+	}
+	if create.user != "proxy" || create.params.Get("database") != "omniproxy" {
+		t.Errorf("unexpected user %q and database %q", create.user, create.params.Get("database"))
+	}
+
+	recs := []*capture.Record{
+		{
+			Request: capture.RequestRecord{
+				Method:  "POST",
+				URL:     "https://api.example.com/users?page=2",
+				Host:    "api.example.com",
+				Path:    "/users",
+				Headers: capture.Headers{{Name: "content-type", Value: "application/json"}},
+				Query:   capture.Query{{Name: "page", Value: "2"}},
+				Body:    map[string]interface{}{"name": "ada"},
+			},
+			Response:   capture.ResponseRecord{Status: 201, Body: "created"},
+			StartTime:  time.Date(2026, 1, 2, 3, 4, 5, 678000000, time.UTC),
+			DurationMs: 42,
+			User:       "alice",
+		},
+		nil,
+		{
+			Type:      capture.RecordTypeWebSocketFrame,
+			Request:   capture.RequestRecord{Method: "GET", Host: "ws.example.com"},
+			WebSocket: &capture.WebSocketRecord{ConnectionID: "c1", Sequence: 1, Direction: capture.DirectionClientToServer, Payload: "hi", PayloadSize: 2},
+		},
+	}
+	if err := store.StoreBatch(context.Background(), recs); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := server.last(t, "INSERT")
+	if insert.sql != "INSERT INTO `omniproxy`.`traffic` FORMAT JSONEachRow" {
+		t.Errorf("unexpected insert: %s", insert.sql)
+	}
+	if len(insert.rows) != 2 {
+		t.Fatalf("expected 2 rows in one insert, got %d", len(insert.rows))
+	}
+	row := insert.rows[0]
+	want := map[string]interface{}{
+		"proxy_id":      "edge-1",
+		"record_type":   "http",
+		"started_at":    "2026-01-02T03:04:05.678Z",
+		"host":          "api.example.com",
+		"status_code":   float64(201),
+		"request_body":  `{"name":"ada"}`,
+		"response_body": "created",
+		"query":         `[{"name":"page","value":"2"}]`,
+		"auth_user":     "alice",
+	}
+	for k, v := range want {
+		if row[k] != v {
+			t.Errorf("%s = %v, want %v", k, row[k], v)
+		}
+	}
+	if _, ok := row["id"]; ok {
+		t.Error("expected the ID to be generated by ClickHouse")
+	}
+	frame := insert.rows[1]
+	if frame["connection_id"] != "c1" || frame["request_body"] != "hi" || frame["frame_direction"] != string(capture.DirectionClientToServer) {
+		t.Errorf("unexpected frame row: %v", frame)
+	}
+}
+
+func TestClickHouseTrafficStoreQuery(t *testing.T) {
+	server := newClickHouseServer(t)
+	store := newTestClickHouseStore(t, server)
+
+	server.reply("SELECT id,", `{"id":"6f1c5b8e-2d0a-4c1e-9a57-3f0c2d9b1e11","method":"GET","url":"https://api.example.com/a","host":"api.example.com","path":"/a","status_code":500,"duration_ms":12.5,"started_at":"2026-01-02T03:04:05.000Z","error":"","record_type":"http","connection_id":"","auth_user":"alice"}
+`)
+	recs, err := store.Query(context.Background(), &TrafficFilter{
+		StartTime: time.UnixMilli(1000),
+		Hosts:     []string{"*.example.com", "localhost"},
+		Methods:   []string{"GET"},
+		MinStatus: 400,
+		User:      "alice",
+		Limit:     10,
+		Offset:    20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := server.last(t, "SELECT id,")
+	wantSQL := " FROM `omniproxy`.`traffic` WHERE started_at >= fromUnixTimestamp64Milli({p0:Int64})" +
+		" AND (host LIKE {p1:String} OR host IN ({p2:String})) AND method IN ({p3:String})" +
+		" AND status_code >= {p4:UInt16} AND auth_user = {p5:String}" +
+		" ORDER BY started_at DESC LIMIT 10 OFFSET 20 FORMAT JSONEachRow"
+	if !strings.HasSuffix(req.sql, wantSQL) {
+		t.Errorf("unexpected SQL:\n%s\nwant suffix:\n%s", req.sql, wantSQL)
+	}
+	wantParams := map[string]string{"param_p0": "1000", "param_p1": "%.example.com", "param_p2": "localhost", "param_p3": "GET", "param_p4": "400", "param_p5": "alice"}
+	for k, v := range wantParams {
+		if got := req.params.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	if r := recs[0]; r.Status != 500 || r.Duration != 12500*time.Microsecond || !r.StartTime.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected record: %+v", r)
+	}
+
+	if _, err := store.Query(context.Background(), &TrafficFilter{OrderBy: "1; DROP TABLE traffic"}); err == nil {
+		t.Error("expected error for an unknown order column")
+	}
+
+	server.reply("SELECT count()", `{"count":1234567}`+"\n")
+	n, err := store.Count(context.Background(), &TrafficFilter{StatusCodes: []int{404, 410}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1234567 {
+		t.Errorf("expected count 1234567, got %d", n)
+	}
+	if req := server.last(t, "SELECT count()"); !strings.Contains(req.sql, "WHERE status_code IN ({p0:UInt16}, {p1:UInt16})") {
+		t.Errorf("unexpected count SQL: %s", req.sql)
+	}
+
+	if _, err := store.Count(context.Background(), &TrafficFilter{
+		Headers:      []HeaderMatch{{Name: "X-Request-ID", Value: "abc"}},
+		BodyContains: "order",
+		BodyFields:   []BodyFieldMatch{{Path: "$.items[0].sku", Value: "tea", Response: true}},
+		MinDuration:  1500 * time.Microsecond,
+		Tags:         []string{"slow"},
+		ClientIP:     "10.0.0.7",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	req = server.last(t, "SELECT count()")
+	wantSQL = " WHERE positionCaseInsensitiveUTF8(request_headers[{p0:String}], {p1:String}) > 0" +
+		" AND (positionCaseInsensitiveUTF8(request_body, {p2:String}) > 0 OR positionCaseInsensitiveUTF8(response_body, {p2:String}) > 0)" +
+		" AND trim(BOTH '\"' FROM JSONExtractRaw(response_body, {p3:String}, {p4:Int64}, {p5:String})) = {p6:String}" +
+		" AND duration_ms >= {p7:Float64} AND has(tags, {p8:String}) AND client_ip = {p9:String}"
+	if !strings.Contains(req.sql, wantSQL) {
+		t.Errorf("unexpected search SQL:\n%s\nwant:\n%s", req.sql, wantSQL)
+	}
+	wantParams = map[string]string{"param_p0": "x-request-id", "param_p3": "items", "param_p4": "1", "param_p7": "1.5"}
+	for k, v := range wantParams {
+		if got := req.params.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestClickHouseTrafficStoreGetByID(t *testing.T) {
+	server := newClickHouseServer(t)
+	store := newTestClickHouseStore(t, server)
+
+	if _, err := store.GetByID(context.Background(), "missing"); err == nil {
+		t.Error("expected error for a missing record")
+	}
+
+	server.reply("SELECT *", `{"id":"6f1c5b8e-2d0a-4c1e-9a57-3f0c2d9b1e11","method":"GET","host":"api.example.com","status_code":200,"started_at":"2026-01-02T03:04:05.000Z","request_headers":{"accept":"*/*"},"response_body":"ok","ttfb_ms":3.5,"grpc_status":null,"tags":["network:3g"]}`+"\n")
+	detail, err := store.GetByID(context.Background(), "6f1c5b8e-2d0a-4c1e-9a57-3f0c2d9b1e11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := server.last(t, "SELECT *")
+	if !strings.Contains(req.sql, "WHERE id = toUUIDOrZero({id:String})") || req.params.Get("param_id") != "6f1c5b8e-2d0a-4c1e-9a57-3f0c2d9b1e11" {
+		t.Errorf("unexpected SQL %s with params %v", req.sql, req.params)
+	}
+	if detail.ID != "6f1c5b8e-2d0a-4c1e-9a57-3f0c2d9b1e11" || detail.RequestHeaders["accept"][0] != "*/*" || detail.ResponseBody != "ok" ||
+		detail.TTFBMs == nil || *detail.TTFBMs != 3.5 || detail.GRPCStatus != nil || detail.Tags[0] != "network:3g" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+}
+
+func TestClickHouseTrafficStoreStats(t *testing.T) {
+	server := newClickHouseServer(t)
+	store := newTestClickHouseStore(t, server)
+
+	server.reply("SELECT count() AS requests", `{"requests":3000000,"errors":1500,"avg_ms":20.5,"quantiles":[10,80,250.5],"hosts":2}`+"\n")
+	server.reply("SELECT method", `{"key":"GET","requests":2000000}`+"\n"+`{"key":"POST","requests":1000000}`+"\n")
+	server.reply("SELECT status_code", `{"key":200,"requests":2998500}`+"\n"+`{"key":503,"requests":1500}`+"\n")
+	server.reply("SELECT host", `{"key":"api.example.com","requests":2500000,"errors":1500,"avg_ms":22,"quantiles":[11,90,300]}`+"\n"+
+		`{"key":"cdn.example.com","requests":500000,"errors":0,"avg_ms":null,"quantiles":[null,null,null]}`+"\n")
+
+	stats, err := store.Stats(context.Background(), &TrafficFilter{Hosts: []string{"*.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totals := server.last(t, "SELECT count() AS requests")
+	if !strings.Contains(totals.sql, "quantiles(0.5, 0.95, 0.99)(duration_ms) AS quantiles") || !strings.Contains(totals.sql, "WHERE host LIKE {p0:String}") {
+		t.Errorf("unexpected stats SQL: %s", totals.sql)
+	}
+	hosts := server.last(t, "SELECT host")
+	if !strings.Contains(hosts.sql, "GROUP BY host ORDER BY requests DESC LIMIT 100") || !strings.Contains(hosts.sql, "quantiles(0.5, 0.95, 0.99)") {
+		t.Errorf("unexpected per-host SQL: %s", hosts.sql)
+	}
+
+	if stats.TotalRequests != 3000000 || stats.TotalErrors != 1500 || stats.UniqueHosts != 2 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if stats.P50DurationMs != 10 || stats.P95DurationMs != 80 || stats.P99DurationMs != 250.5 {
+		t.Errorf("unexpected percentiles: %v %v %v", stats.P50DurationMs, stats.P95DurationMs, stats.P99DurationMs)
+	}
+	if stats.RequestsByMethod["POST"] != 1000000 || stats.RequestsByStatus[503] != 1500 {
+		t.Errorf("unexpected breakdowns: %v %v", stats.RequestsByMethod, stats.RequestsByStatus)
+	}
+	if len(stats.Hosts) != 2 || stats.Hosts[0].Host != "api.example.com" || stats.Hosts[0].P99DurationMs != 300 || stats.Hosts[1].P50DurationMs != 0 {
+		t.Errorf("unexpected host stats: %+v", stats.Hosts)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	entsql "entgo.io/ent/dialect/sql"
 	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/ui/ent"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
 
 	// Database drivers
@@ -22,10 +23,14 @@ import (
 // Supports SQLite (laptop/team mode) and PostgreSQL (production mode).
 type DatabaseTrafficStore struct {
 	client  *ent.Client
+	drv     *entsql.Driver
 	proxyID int
 	metrics Metrics
 	mu      sync.RWMutex
 	closed  bool
+
+	// fullText reports whether bodies have a full-text index
+	fullText bool
 }
 
 // DatabaseTrafficStoreConfig configures the database traffic store.
@@ -62,14 +67,14 @@ func NewDatabaseTrafficStore(ctx context.Context, cfg *DatabaseTrafficStoreConfi
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
-	// Create the Ent client
-	var client *ent.Client
+	// Open the driver
+	var drv *entsql.Driver
 
 	switch dbCfg.Type {
 	case DBTypeSQLite:
-		client, err = openSQLite(dbCfg, cfg.Debug)
+		drv, err = openSQLite(dbCfg)
 	case DBTypePostgres:
-		client, err = openPostgres(dbCfg, cfg.Debug)
+		drv, err = entsql.Open(dialect.Postgres, dbCfg.DSN)
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", dbCfg.Type)
 	}
@@ -78,6 +83,13 @@ func NewDatabaseTrafficStore(ctx context.Context, cfg *DatabaseTrafficStoreConfi
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// Create the Ent client
+	opts := []ent.Option{ent.Driver(drv)}
+	if cfg.Debug {
+		opts = append(opts, ent.Debug())
+	}
+	client := ent.NewClient(opts...)
+
 	// Run migrations
 	if err := client.Schema.Create(ctx); err != nil {
 		client.Close()
@@ -86,9 +98,15 @@ func NewDatabaseTrafficStore(ctx context.Context, cfg *DatabaseTrafficStoreConfi
 
 	store := &DatabaseTrafficStore{
 		client:  client,
+		drv:     drv,
 		metrics: cfg.Metrics,
 	}
 
+	if err := store.setupSearchIndex(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+
 	if store.metrics == nil {
 		store.metrics = NoopMetrics{}
 	}
@@ -113,7 +131,7 @@ func NewDatabaseTrafficStore(ctx context.Context, cfg *DatabaseTrafficStoreConfi
 }
 
 // openSQLite opens a SQLite database connection.
-func openSQLite(cfg *DBConfig, debug bool) (*ent.Client, error) {
+func openSQLite(cfg *DBConfig) (*entsql.Driver, error) {
 	drv, err := entsql.Open(dialect.SQLite, cfg.DSN)
 	if err != nil {
 		return nil, err
@@ -130,27 +148,7 @@ func openSQLite(cfg *DBConfig, debug bool) (*ent.Client, error) {
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	opts := []ent.Option{ent.Driver(drv)}
-	if debug {
-		opts = append(opts, ent.Debug())
-	}
-
-	return ent.NewClient(opts...), nil
-}
-
-// openPostgres opens a PostgreSQL database connection.
-func openPostgres(cfg *DBConfig, debug bool) (*ent.Client, error) {
-	drv, err := entsql.Open(dialect.Postgres, cfg.DSN)
-	if err != nil {
-		return nil, err
-	}
-
-	opts := []ent.Option{ent.Driver(drv)}
-	if debug {
-		opts = append(opts, ent.Debug())
-	}
-
-	return ent.NewClient(opts...), nil
+	return drv, nil
 }
 
 // ensureDefaultProxy creates a default proxy if it doesn't exist.
@@ -222,68 +220,7 @@ func (s *DatabaseTrafficStore) Store(ctx context.Context, rec *capture.Record) e
 
 	start := time.Now()
 
-	// Convert capture.Record to database fields
-	create := s.client.Traffic.Create().
-		SetMethod(rec.Request.Method).
-		SetURL(rec.Request.URL).
-		SetScheme(rec.Request.Scheme).
-		SetHost(rec.Request.Host).
-		SetPath(rec.Request.Path).
-		SetStartedAt(rec.StartTime).
-		SetDurationMs(rec.DurationMs).
-		SetProxyID(s.proxyID)
-
-	// Request headers
-	if rec.Request.Headers != nil {
-		headers := make(map[string][]string)
-		for k, v := range rec.Request.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetRequestHeaders(headers)
-	}
-
-	// Request query
-	if rec.Request.Query != nil {
-		queryJSON, _ := json.Marshal(rec.Request.Query)
-		create.SetQuery(string(queryJSON))
-	}
-
-	// Request body
-	if rec.Request.Body != nil {
-		bodyBytes, _ := json.Marshal(rec.Request.Body)
-		create.SetRequestBody(bodyBytes)
-		create.SetRequestBodySize(rec.Request.BodySize)
-	}
-	create.SetRequestIsBinary(rec.Request.IsBinary)
-	if rec.Request.ContentType != "" {
-		create.SetContentType(rec.Request.ContentType)
-	}
-
-	// Response fields
-	create.SetStatusCode(rec.Response.Status)
-	if rec.Response.StatusText != "" {
-		create.SetStatusText(rec.Response.StatusText)
-	}
-
-	// Response headers
-	if rec.Response.Headers != nil {
-		headers := make(map[string][]string)
-		for k, v := range rec.Response.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetResponseHeaders(headers)
-	}
-
-	// Response body
-	if rec.Response.Body != nil {
-		bodyBytes, _ := json.Marshal(rec.Response.Body)
-		create.SetResponseBody(bodyBytes)
-		create.SetResponseBodySize(rec.Response.Size)
-	}
-	create.SetResponseIsBinary(rec.Response.IsBinary)
-	if rec.Response.ContentType != "" {
-		create.SetResponseContentType(rec.Response.ContentType)
-	}
+	create := s.trafficCreate(rec)
 
 	// Save
 	_, err := create.Save(ctx)
@@ -321,65 +258,7 @@ func (s *DatabaseTrafficStore) StoreBatch(ctx context.Context, recs []*capture.R
 			continue
 		}
 
-		create := s.client.Traffic.Create().
-			SetMethod(rec.Request.Method).
-			SetURL(rec.Request.URL).
-			SetScheme(rec.Request.Scheme).
-			SetHost(rec.Request.Host).
-			SetPath(rec.Request.Path).
-			SetStartedAt(rec.StartTime).
-			SetDurationMs(rec.DurationMs).
-			SetProxyID(s.proxyID).
-			SetStatusCode(rec.Response.Status).
-			SetRequestIsBinary(rec.Request.IsBinary).
-			SetResponseIsBinary(rec.Response.IsBinary)
-
-		// Request headers
-		if rec.Request.Headers != nil {
-			headers := make(map[string][]string)
-			for k, v := range rec.Request.Headers {
-				headers[k] = []string{v}
-			}
-			create.SetRequestHeaders(headers)
-		}
-
-		// Request query
-		if rec.Request.Query != nil {
-			queryJSON, _ := json.Marshal(rec.Request.Query)
-			create.SetQuery(string(queryJSON))
-		}
-
-		// Request body
-		if rec.Request.Body != nil {
-			bodyBytes, _ := json.Marshal(rec.Request.Body)
-			create.SetRequestBody(bodyBytes)
-			create.SetRequestBodySize(rec.Request.BodySize)
-		}
-		if rec.Request.ContentType != "" {
-			create.SetContentType(rec.Request.ContentType)
-		}
-
-		// Response
-		if rec.Response.StatusText != "" {
-			create.SetStatusText(rec.Response.StatusText)
-		}
-		if rec.Response.Headers != nil {
-			headers := make(map[string][]string)
-			for k, v := range rec.Response.Headers {
-				headers[k] = []string{v}
-			}
-			create.SetResponseHeaders(headers)
-		}
-		if rec.Response.Body != nil {
-			bodyBytes, _ := json.Marshal(rec.Response.Body)
-			create.SetResponseBody(bodyBytes)
-			create.SetResponseBodySize(rec.Response.Size)
-		}
-		if rec.Response.ContentType != "" {
-			create.SetResponseContentType(rec.Response.ContentType)
-		}
-
-		builders = append(builders, create)
+		builders = append(builders, s.trafficCreate(rec))
 	}
 
 	// Execute bulk create
@@ -399,6 +278,140 @@ func (s *DatabaseTrafficStore) StoreBatch(ctx context.Context, recs []*capture.R
 	return nil
 }
 
+// trafficCreate converts a capture.Record into a Traffic create builder.
+func (s *DatabaseTrafficStore) trafficCreate(rec *capture.Record) *ent.TrafficCreate {
+	create := s.client.Traffic.Create().
+		SetMethod(rec.Request.Method).
+		SetURL(rec.Request.URL).
+		SetScheme(rec.Request.Scheme).
+		SetHost(rec.Request.Host).
+		SetPath(rec.Request.Path).
+		SetStartedAt(rec.StartTime).
+		SetDurationMs(rec.DurationMs).
+		SetProxyID(s.proxyID).
+		SetStatusCode(rec.Response.Status).
+		SetRequestIsBinary(rec.Request.IsBinary).
+		SetRequestBodyTruncated(rec.Request.Truncated).
+		SetRequestDecodedSize(rec.Request.DecodedSize).
+		SetResponseIsBinary(rec.Response.IsBinary).
+		SetResponseBodyTruncated(rec.Response.Truncated).
+		SetResponseDecodedSize(rec.Response.DecodedSize)
+	if rec.Response.Events != nil {
+		create.SetResponseEvents(rec.Response.Events)
+	}
+
+	// Request headers by lowercase name (for search), plus the names as
+	// captured so their order and casing can be restored
+	if rec.Request.Headers != nil {
+		create.SetRequestHeaders(rec.Request.Headers.Multi())
+		create.SetRequestHeaderNames(rec.Request.Headers.Names())
+	}
+
+	// Request query
+	if rec.Request.Query != nil {
+		queryJSON, _ := json.Marshal(rec.Request.Query)
+		create.SetQuery(string(queryJSON))
+	}
+
+	// Request body
+	if rec.Request.Body != nil {
+		bodyBytes, _ := json.Marshal(rec.Request.Body)
+		create.SetRequestBody(bodyBytes)
+		create.SetRequestBodySize(rec.Request.BodySize)
+	}
+	if rec.Request.ContentType != "" {
+		create.SetContentType(rec.Request.ContentType)
+	}
+	if rec.Request.HTTPVersion != "" {
+		create.SetHTTPVersion(rec.Request.HTTPVersion)
+	}
+	if rec.StreamID != 0 {
+		create.SetStreamID(rec.StreamID)
+	}
+
+	// Response
+	if rec.Response.StatusText != "" {
+		create.SetStatusText(rec.Response.StatusText)
+	}
+	if rec.Response.HTTPVersion != "" {
+		create.SetResponseHTTPVersion(rec.Response.HTTPVersion)
+	}
+	if rec.Response.Headers != nil {
+		create.SetResponseHeaders(rec.Response.Headers.Multi())
+		create.SetResponseHeaderNames(rec.Response.Headers.Names())
+	}
+	if rec.Response.Body != nil {
+		bodyBytes, _ := json.Marshal(rec.Response.Body)
+		create.SetResponseBody(bodyBytes)
+		create.SetResponseBodySize(rec.Response.Size)
+	}
+	if rec.Response.ContentType != "" {
+		create.SetResponseContentType(rec.Response.ContentType)
+	}
+
+	if rec.TTFBMs > 0 {
+		create.SetTtfbMs(rec.TTFBMs)
+	}
+
+	if rec.Type != "" {
+		create.SetRecordType(string(rec.Type))
+	}
+	if ws := rec.WebSocket; ws != nil {
+		setWebSocketFields(create, ws)
+	}
+	if g := rec.GRPC; g != nil {
+		create.SetGrpcStatus(g.Status)
+		if g.Message != "" {
+			create.SetGrpcMessage(g.Message)
+		}
+	}
+	if rec.NetworkProfile != "" {
+		create.SetTags([]string{capture.NetworkProfileTag(rec.NetworkProfile)})
+	}
+	if rec.User != "" {
+		create.SetAuthUser(rec.User)
+	}
+	if rec.ClientIP != "" {
+		create.SetClientIP(rec.ClientIP)
+	}
+
+	return create
+}
+
+// setWebSocketFields maps WebSocket connection and frame details.
+// Frame payloads are stored in the request body for client frames and in the
+// response body for server frames so existing body tooling applies to both.
+func setWebSocketFields(create *ent.TrafficCreate, ws *capture.WebSocketRecord) {
+	create.SetConnectionID(ws.ConnectionID)
+	if ws.Sequence == 0 {
+		return
+	}
+
+	create.SetFrameSequence(ws.Sequence).
+		SetFrameDirection(string(ws.Direction)).
+		SetFrameOpcode(ws.Opcode)
+	if ws.CloseCode != 0 {
+		create.SetCloseCode(ws.CloseCode)
+	}
+	if ws.CloseReason != "" {
+		create.SetCloseReason(ws.CloseReason)
+	}
+
+	var payload []byte
+	if ws.Payload != nil {
+		payload, _ = json.Marshal(ws.Payload)
+	}
+	if ws.Direction == capture.DirectionClientToServer {
+		create.SetRequestBody(payload).
+			SetRequestBodySize(ws.PayloadSize).
+			SetRequestIsBinary(ws.IsBinary)
+	} else {
+		create.SetResponseBody(payload).
+			SetResponseBodySize(ws.PayloadSize).
+			SetResponseIsBinary(ws.IsBinary)
+	}
+}
+
 // Close closes the database connection.
 func (s *DatabaseTrafficStore) Close() error {
 	s.mu.Lock()
@@ -412,6 +425,16 @@ func (s *DatabaseTrafficStore) Close() error {
 	return s.client.Close()
 }
 
+// checkOpen returns an error if the store is closed.
+func (s *DatabaseTrafficStore) checkOpen() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("store is closed")
+	}
+	return nil
+}
+
 // Client returns the underlying Ent client for advanced queries.
 func (s *DatabaseTrafficStore) Client() *ent.Client {
 	return s.client
@@ -435,29 +458,11 @@ func (s *DatabaseTrafficStore) Query(ctx context.Context, filter *TrafficFilter)
 
 	// Apply filters
 	if filter != nil {
-		if !filter.StartTime.IsZero() {
-			query = query.Where(traffic.StartedAtGTE(filter.StartTime))
-		}
-		if !filter.EndTime.IsZero() {
-			query = query.Where(traffic.StartedAtLTE(filter.EndTime))
-		}
-		if len(filter.Methods) > 0 {
-			query = query.Where(traffic.MethodIn(filter.Methods...))
-		}
-		if filter.MinStatus > 0 {
-			query = query.Where(traffic.StatusCodeGTE(filter.MinStatus))
-		}
-		if filter.MaxStatus > 0 {
-			query = query.Where(traffic.StatusCodeLTE(filter.MaxStatus))
-		}
-		if len(filter.StatusCodes) > 0 {
-			query = query.Where(traffic.StatusCodeIn(filter.StatusCodes...))
-		}
-
-		// Host filtering
-		if len(filter.Hosts) > 0 {
-			query = query.Where(traffic.HostIn(filter.Hosts...))
+		preds, err := s.trafficPredicates(filter)
+		if err != nil {
+			return nil, err
 		}
+		query = query.Where(preds...)
 
 		// Pagination
 		if filter.Limit > 0 {
@@ -490,15 +495,18 @@ func (s *DatabaseTrafficStore) Query(ctx context.Context, filter *TrafficFilter)
 	result := make([]*TrafficRecord, len(records))
 	for i, r := range records {
 		result[i] = &TrafficRecord{
-			ID:        fmt.Sprintf("%d", r.ID),
-			Method:    r.Method,
-			URL:       r.URL,
-			Host:      r.Host,
-			Path:      r.Path,
-			Status:    r.StatusCode,
-			Duration:  time.Duration(r.DurationMs * float64(time.Millisecond)),
-			StartTime: r.StartedAt,
-			Error:     r.Error,
+			ID:           fmt.Sprintf("%d", r.ID),
+			Method:       r.Method,
+			URL:          r.URL,
+			Host:         r.Host,
+			Path:         r.Path,
+			Status:       r.StatusCode,
+			Duration:     time.Duration(r.DurationMs * float64(time.Millisecond)),
+			StartTime:    r.StartedAt,
+			Error:        r.Error,
+			RecordType:   r.RecordType,
+			ConnectionID: r.ConnectionID,
+			User:         r.AuthUser,
 		}
 	}
 
@@ -529,28 +537,48 @@ func (s *DatabaseTrafficStore) GetByID(ctx context.Context, id string) (*Traffic
 	// Convert to TrafficDetail
 	detail := &TrafficDetail{
 		TrafficRecord: TrafficRecord{
-			ID:        fmt.Sprintf("%d", r.ID),
-			Method:    r.Method,
-			URL:       r.URL,
-			Host:      r.Host,
-			Path:      r.Path,
-			Status:    r.StatusCode,
-			Duration:  time.Duration(r.DurationMs * float64(time.Millisecond)),
-			StartTime: r.StartedAt,
-			Error:     r.Error,
+			ID:           fmt.Sprintf("%d", r.ID),
+			Method:       r.Method,
+			URL:          r.URL,
+			Host:         r.Host,
+			Path:         r.Path,
+			Status:       r.StatusCode,
+			Duration:     time.Duration(r.DurationMs * float64(time.Millisecond)),
+			StartTime:    r.StartedAt,
+			Error:        r.Error,
+			RecordType:   r.RecordType,
+			ConnectionID: r.ConnectionID,
+			User:         r.AuthUser,
 		},
 		Scheme:              r.Scheme,
 		Query:               r.Query,
 		RequestHeaders:      r.RequestHeaders,
+		RequestHeaderNames:  r.RequestHeaderNames,
 		RequestBodySize:     r.RequestBodySize,
 		RequestIsBinary:     r.RequestIsBinary,
+		RequestTruncated:    r.RequestBodyTruncated,
+		RequestDecodedSize:  r.RequestDecodedSize,
 		RequestContentType:  r.ContentType,
+		HTTPVersion:         r.HTTPVersion,
+		StreamID:            r.StreamID,
 		StatusText:          r.StatusText,
+		ResponseHTTPVersion: r.ResponseHTTPVersion,
 		ResponseHeaders:     r.ResponseHeaders,
+		ResponseHeaderNames: r.ResponseHeaderNames,
 		ResponseBodySize:    r.ResponseBodySize,
 		ResponseIsBinary:    r.ResponseIsBinary,
+		ResponseTruncated:   r.ResponseBodyTruncated,
+		ResponseDecodedSize: r.ResponseDecodedSize,
 		ResponseContentType: r.ResponseContentType,
+		ResponseEvents:      r.ResponseEvents,
 		TTFBMs:              r.TtfbMs,
+		FrameSequence:       r.FrameSequence,
+		FrameDirection:      r.FrameDirection,
+		FrameOpcode:         r.FrameOpcode,
+		CloseCode:           r.CloseCode,
+		CloseReason:         r.CloseReason,
+		GRPCStatus:          r.GrpcStatus,
+		GRPCMessage:         r.GrpcMessage,
 		ClientIP:            r.ClientIP,
 		Tags:                r.Tags,
 	}
@@ -575,32 +603,11 @@ func (s *DatabaseTrafficStore) Count(ctx context.Context, filter *TrafficFilter)
 	}
 	s.mu.RUnlock()
 
-	query := s.client.Traffic.Query()
-
-	// Apply filters (same as Query)
-	if filter != nil {
-		if !filter.StartTime.IsZero() {
-			query = query.Where(traffic.StartedAtGTE(filter.StartTime))
-		}
-		if !filter.EndTime.IsZero() {
-			query = query.Where(traffic.StartedAtLTE(filter.EndTime))
-		}
-		if len(filter.Methods) > 0 {
-			query = query.Where(traffic.MethodIn(filter.Methods...))
-		}
-		if filter.MinStatus > 0 {
-			query = query.Where(traffic.StatusCodeGTE(filter.MinStatus))
-		}
-		if filter.MaxStatus > 0 {
-			query = query.Where(traffic.StatusCodeLTE(filter.MaxStatus))
-		}
-		if len(filter.StatusCodes) > 0 {
-			query = query.Where(traffic.StatusCodeIn(filter.StatusCodes...))
-		}
-		if len(filter.Hosts) > 0 {
-			query = query.Where(traffic.HostIn(filter.Hosts...))
-		}
+	preds, err := s.trafficPredicates(filter)
+	if err != nil {
+		return 0, err
 	}
+	query := s.client.Traffic.Query().Where(preds...)
 
 	count, err := query.Count(ctx)
 	if err != nil {
@@ -610,66 +617,71 @@ func (s *DatabaseTrafficStore) Count(ctx context.Context, filter *TrafficFilter)
 	return int64(count), nil
 }
 
-// Stats returns aggregate statistics for traffic matching the filter.
-func (s *DatabaseTrafficStore) Stats(ctx context.Context, filter *TrafficFilter) (*TrafficStats, error) {
-	s.mu.RLock()
-	if s.closed {
-		s.mu.RUnlock()
-		return nil, fmt.Errorf("store is closed")
+// trafficPredicates converts the filter criteria, other than pagination
+// and ordering, into Traffic predicates.
+func (s *DatabaseTrafficStore) trafficPredicates(filter *TrafficFilter) ([]predicate.Traffic, error) {
+	if filter == nil {
+		return nil, nil
 	}
-	s.mu.RUnlock()
 
-	// Get total count
-	total, err := s.Count(ctx, filter)
-	if err != nil {
-		return nil, err
+	var preds []predicate.Traffic
+	if !filter.StartTime.IsZero() {
+		preds = append(preds, traffic.StartedAtGTE(filter.StartTime))
 	}
-
-	// Get error count (status >= 400)
-	errorFilter := &TrafficFilter{MinStatus: 400}
-	if filter != nil {
-		errorFilter.StartTime = filter.StartTime
-		errorFilter.EndTime = filter.EndTime
-		errorFilter.Hosts = filter.Hosts
+	if !filter.EndTime.IsZero() {
+		preds = append(preds, traffic.StartedAtLTE(filter.EndTime))
 	}
-	errors, err := s.Count(ctx, errorFilter)
-	if err != nil {
-		return nil, err
+	if len(filter.Methods) > 0 {
+		preds = append(preds, traffic.MethodIn(filter.Methods...))
+	}
+	if filter.MinStatus > 0 {
+		preds = append(preds, traffic.StatusCodeGTE(filter.MinStatus))
+	}
+	if filter.MaxStatus > 0 {
+		preds = append(preds, traffic.StatusCodeLTE(filter.MaxStatus))
+	}
+	if len(filter.StatusCodes) > 0 {
+		preds = append(preds, traffic.StatusCodeIn(filter.StatusCodes...))
 	}
 
-	stats := &TrafficStats{
-		TotalRequests:    total,
-		TotalErrors:      errors,
-		RequestsByMethod: make(map[string]int64),
-		RequestsByStatus: make(map[int]int64),
+	// Host and path filtering
+	if len(filter.Hosts) > 0 {
+		preds = append(preds, traffic.HostIn(filter.Hosts...))
+	}
+	if len(filter.Paths) > 0 {
+		preds = append(preds, pathPredicate(filter.Paths))
 	}
 
-	// Get method counts using raw SQL for efficiency
-	// This is a simplified implementation - production would use proper aggregates
-	records, err := s.Query(ctx, &TrafficFilter{Limit: 10000})
-	if err != nil {
-		return stats, nil // Return partial stats on error
+	// WebSocket filtering
+	if len(filter.RecordTypes) > 0 {
+		preds = append(preds, traffic.RecordTypeIn(filter.RecordTypes...))
+	}
+	if filter.ConnectionID != "" {
+		preds = append(preds, traffic.ConnectionID(filter.ConnectionID))
 	}
 
-	var totalDuration float64
-	for _, r := range records {
-		stats.RequestsByMethod[r.Method]++
-		stats.RequestsByStatus[r.Status]++
-		totalDuration += float64(r.Duration.Milliseconds())
+	// User filtering
+	if filter.User != "" {
+		preds = append(preds, traffic.AuthUser(filter.User))
 	}
 
-	if len(records) > 0 {
-		stats.AvgDurationMs = totalDuration / float64(len(records))
+	// Header, body, duration and metadata filtering
+	search, err := s.searchPredicates(filter)
+	if err != nil {
+		return nil, err
 	}
+	preds = append(preds, search...)
 
-	// Get unique hosts
-	hostMap := make(map[string]bool)
-	for _, r := range records {
-		hostMap[r.Host] = true
+	// Filter expression
+	if filter.Query != nil {
+		p, err := s.queryPredicate(filter.Query.Expr)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
 	}
-	stats.UniqueHosts = int64(len(hostMap))
 
-	return stats, nil
+	return preds, nil
 }
 
 // Ensure DatabaseTrafficStore implements TrafficStore and TrafficQuerier.
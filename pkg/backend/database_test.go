@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -38,13 +39,13 @@ func TestDatabaseTrafficStore(t *testing.T) {
 				Host:        "example.com",
 				Path:        "/api/test",
 				Scheme:      "https",
-				Headers:     map[string]string{"content-type": "application/json"},
+				Headers:     capture.Headers{{Name: "content-type", Value: "application/json"}},
 				ContentType: "application/json",
 			},
 			Response: capture.ResponseRecord{
 				Status:     200,
 				StatusText: "200 OK",
-				Headers:    map[string]string{"content-type": "application/json"},
+				Headers:    capture.Headers{{Name: "content-type", Value: "application/json"}},
 			},
 		}
 
@@ -282,3 +283,260 @@ func TestDatabaseTrafficStoreQueryWithStatusFilter(t *testing.T) {
 		t.Errorf("expected 1 404 record, got %d", len(notFoundRecords))
 	}
 }
+
+func TestDatabaseTrafficStoreWebSocketSession(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatalf("failed to create database store: %v", err)
+	}
+	defer store.Close()
+
+	req := capture.RequestRecord{
+		Method: "GET",
+		URL:    "wss://example.com/socket",
+		Host:   "example.com",
+		Path:   "/socket",
+		Scheme: "https",
+	}
+	resp := capture.ResponseRecord{Status: 101}
+
+	recs := []*capture.Record{
+		{
+			Type:      capture.RecordTypeWebSocket,
+			StartTime: time.Now(),
+			Request:   req,
+			Response:  resp,
+			WebSocket: &capture.WebSocketRecord{ConnectionID: "conn-1"},
+		},
+		{
+			Type:      capture.RecordTypeWebSocketFrame,
+			StartTime: time.Now(),
+			Request:   req,
+			Response:  resp,
+			WebSocket: &capture.WebSocketRecord{
+				ConnectionID: "conn-1",
+				Sequence:     1,
+				Direction:    capture.DirectionClientToServer,
+				Opcode:       capture.OpcodeText,
+				Payload:      "hello",
+				PayloadSize:  5,
+			},
+		},
+		{
+			Type:      capture.RecordTypeWebSocketFrame,
+			StartTime: time.Now(),
+			Request:   req,
+			Response:  resp,
+			WebSocket: &capture.WebSocketRecord{
+				ConnectionID: "conn-1",
+				Sequence:     2,
+				Direction:    capture.DirectionServerToClient,
+				Opcode:       capture.OpcodeClose,
+				CloseCode:    1001,
+				CloseReason:  "going away",
+			},
+		},
+		{
+			StartTime: time.Now(),
+			Request:   req,
+			Response:  capture.ResponseRecord{Status: 200},
+		},
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	session, err := store.Query(ctx, &TrafficFilter{ConnectionID: "conn-1", OrderBy: "id"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(session) != 3 {
+		t.Fatalf("expected 3 records in session, got %d", len(session))
+	}
+	if session[0].RecordType != "websocket" || session[1].RecordType != "websocket_frame" {
+		t.Errorf("unexpected record types: %s, %s", session[0].RecordType, session[1].RecordType)
+	}
+
+	clientFrame, err := store.GetByID(ctx, session[1].ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if clientFrame.FrameDirection != "client" || clientFrame.RequestBody != `"hello"` || clientFrame.RequestBodySize != 5 {
+		t.Errorf("unexpected client frame: %+v", clientFrame)
+	}
+
+	closeFrame, err := store.GetByID(ctx, session[2].ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if closeFrame.CloseCode != 1001 || closeFrame.CloseReason != "going away" {
+		t.Errorf("unexpected close frame: %+v", closeFrame)
+	}
+
+	frames, err := store.Count(ctx, &TrafficFilter{RecordTypes: []string{"websocket_frame"}})
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if frames != 2 {
+		t.Errorf("expected 2 frames, got %d", frames)
+	}
+}
+
+func TestTrafficDetailRecord(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatalf("failed to create database store: %v", err)
+	}
+	defer store.Close()
+
+	rec := &capture.Record{
+		StartTime:  time.Now(),
+		DurationMs: 25,
+		Request: capture.RequestRecord{
+			Method: "POST",
+			URL:    "https://example.com/api/users?page=2&tag=b&tag=a",
+			Host:   "example.com",
+			Path:   "/api/users",
+			Scheme: "https",
+			Query:  capture.Query{{Name: "page", Value: "2"}, {Name: "tag", Value: "b"}, {Name: "tag", Value: "a"}},
+			Headers: capture.Headers{
+				{Name: "Content-Type", Value: "application/json"},
+				{Name: "Accept", Value: "text/plain"},
+				{Name: "Cookie", Value: "a=1"},
+				{Name: "accept", Value: "application/json"},
+			},
+			Body:        map[string]interface{}{"name": "Alice"},
+			ContentType: "application/json",
+			HTTPVersion: "HTTP/2.0",
+		},
+		Response: capture.ResponseRecord{
+			Status: 201,
+			Headers: capture.Headers{
+				{Name: "Content-Type", Value: "text/plain"},
+				{Name: "Set-Cookie", Value: "a=1; Path=/"},
+				{Name: "Set-Cookie", Value: "b=2; HttpOnly"},
+			},
+			Body:        "created",
+			Size:        4096,
+			Truncated:   true,
+			Events:      []capture.StreamEvent{{ID: "1", Event: "created", Data: "created", OffsetMs: 12.5}},
+			HTTPVersion: "HTTP/1.1",
+		},
+		NetworkProfile: "3g",
+		User:           "alice",
+		StreamID:       3,
+	}
+	if err := store.Store(ctx, rec); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	if rows, err := store.Query(ctx, &TrafficFilter{User: "bob"}); err != nil || len(rows) != 0 {
+		t.Errorf("expected no traffic for bob: %v (%d rows)", err, len(rows))
+	}
+	rows, err := store.Query(ctx, &TrafficFilter{User: "alice", Limit: 1})
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("Query failed: %v (%d rows)", err, len(rows))
+	}
+	detail, err := store.GetByID(ctx, rows[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+
+	got := detail.Record()
+	if got.Type != "" || got.Request.Method != "POST" || got.Request.Query.Get("page") != "2" {
+		t.Errorf("unexpected request: %+v", got.Request)
+	}
+	if !reflect.DeepEqual(got.Request.Headers, rec.Request.Headers) || !reflect.DeepEqual(got.Response.Headers, rec.Response.Headers) {
+		t.Errorf("headers changed: %v / %v", got.Request.Headers, got.Response.Headers)
+	}
+	if !reflect.DeepEqual(got.Request.Query, rec.Request.Query) {
+		t.Errorf("query changed: %v", got.Request.Query)
+	}
+	if len(got.Request.Cookies) != 1 || len(got.Response.Cookies) != 2 || !got.Response.Cookies[1].HTTPOnly {
+		t.Errorf("unexpected cookies: %+v / %+v", got.Request.Cookies, got.Response.Cookies)
+	}
+	if string(capture.BodyBytes(got.Request.Body)) != `{"name":"Alice"}` {
+		t.Errorf("unexpected request body: %v", got.Request.Body)
+	}
+	if got.Response.Status != 201 || got.Response.Body != "created" || got.Response.Headers.Get("content-type") != "text/plain" ||
+		!got.Response.Truncated || got.Response.Size != 4096 || got.Request.Truncated {
+		t.Errorf("unexpected response: %+v", got.Response)
+	}
+	if !reflect.DeepEqual(got.Response.Events, rec.Response.Events) {
+		t.Errorf("events changed: %+v", got.Response.Events)
+	}
+	if got.NetworkProfile != "3g" {
+		t.Errorf("expected network profile 3g, got %q", got.NetworkProfile)
+	}
+	if got.User != "alice" {
+		t.Errorf("expected user alice, got %q", got.User)
+	}
+	if got.Request.HTTPVersion != "HTTP/2.0" || got.Response.HTTPVersion != "HTTP/1.1" || got.StreamID != 3 {
+		t.Errorf("unexpected protocol: %s, %s, stream %d", got.Request.HTTPVersion, got.Response.HTTPVersion, got.StreamID)
+	}
+}
+
+func TestDatabaseTrafficStoreGRPC(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatalf("failed to create database store: %v", err)
+	}
+	defer store.Close()
+
+	rec := &capture.Record{
+		Type:      capture.RecordTypeGRPC,
+		StartTime: time.Now(),
+		Request: capture.RequestRecord{
+			Method:      "POST",
+			URL:         "https://api.example.com/test.v1.Greeter/SayHello",
+			Host:        "api.example.com",
+			Path:        "/test.v1.Greeter/SayHello",
+			Scheme:      "https",
+			Body:        []interface{}{map[string]interface{}{"name": "alice"}},
+			ContentType: "application/grpc",
+		},
+		Response: capture.ResponseRecord{Status: 200, Body: []interface{}{}},
+		GRPC: &capture.GRPCRecord{
+			Service:         "test.v1.Greeter",
+			Method:          "SayHello",
+			Status:          5,
+			StatusName:      "NOT_FOUND",
+			Message:         "no such user",
+			RequestMessages: 1,
+		},
+	}
+	if err := store.Store(ctx, rec); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	rows, err := store.Query(ctx, &TrafficFilter{RecordTypes: []string{"grpc"}})
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("Query failed: %v (%d rows)", err, len(rows))
+	}
+	detail, err := store.GetByID(ctx, rows[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	got := detail.Record()
+	if got.Type != capture.RecordTypeGRPC || got.GRPC == nil {
+		t.Fatalf("expected gRPC record, got %q", got.Type)
+	}
+	if *got.GRPC != *rec.GRPC {
+		t.Errorf("got %+v, want %+v", *got.GRPC, *rec.GRPC)
+	}
+}
@@ -10,12 +10,13 @@ import (
 	"github.com/grokify/omniproxy/pkg/capture"
 )
 
-// FileTrafficStore writes traffic records to a file in NDJSON format.
+// FileTrafficStore writes traffic records to a file in NDJSON, JSON or HAR format.
 // This is the simplest backend, suitable for laptop mode.
 type FileTrafficStore struct {
 	mu      sync.Mutex
 	writer  io.Writer
 	file    *os.File // nil if using stdout or provided writer
+	har     *capture.HARStreamWriter
 	format  Format
 	metrics Metrics
 }
@@ -26,6 +27,7 @@ type Format string
 const (
 	FormatNDJSON Format = "ndjson" // Newline-delimited JSON (default)
 	FormatJSON   Format = "json"   // Pretty-printed JSON
+	FormatHAR    Format = "har"    // HAR 1.2 document, written incrementally
 )
 
 // FileTrafficStoreConfig configures a FileTrafficStore.
@@ -63,6 +65,25 @@ func NewFileTrafficStore(cfg *FileTrafficStoreConfig) (*FileTrafficStore, error)
 		store.metrics = NoopMetrics{}
 	}
 
+	// HAR keeps a valid document on disk and repairs it on restart
+	if store.format == FormatHAR {
+		if cfg.Writer == nil && cfg.Path != "" {
+			har, err := capture.OpenHARFile(cfg.Path)
+			if err != nil {
+				return nil, err
+			}
+			store.har = har
+			return store, nil
+		}
+		w := cfg.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		store.writer = w
+		store.har = capture.NewHARStreamWriter(w)
+		return store, nil
+	}
+
 	// Determine writer
 	if cfg.Writer != nil {
 		store.writer = cfg.Writer
@@ -86,6 +107,15 @@ func (s *FileTrafficStore) Store(ctx context.Context, rec *capture.Record) error
 		return nil
 	}
 
+	if s.har != nil {
+		if err := s.har.WriteRecord(rec); err != nil {
+			s.metrics.IncStoreError()
+			return err
+		}
+		s.metrics.IncStoreSuccess()
+		return nil
+	}
+
 	var data []byte
 	var err error
 
@@ -128,8 +158,12 @@ func (s *FileTrafficStore) StoreBatch(ctx context.Context, recs []*capture.Recor
 	return nil
 }
 
-// Close closes the file if one was opened.
+// Close closes the file if one was opened. For HAR output it also
+// completes the document.
 func (s *FileTrafficStore) Close() error {
+	if s.har != nil {
+		return s.har.Close()
+	}
 	if s.file != nil {
 		return s.file.Close()
 	}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
 )
 
 // TrafficStore is the interface for storing captured HTTP traffic.
@@ -46,6 +47,15 @@ type TrafficQuerier interface {
 	Stats(ctx context.Context, filter *TrafficFilter) (*TrafficStats, error)
 }
 
+// BufferedTrafficStore is an optional interface for stores that hold records
+// internally until they are delivered, such as the Kafka producer. The
+// AsyncTrafficStoreWrapper includes these records in its queue depth, so
+// backpressure from a slow store shows up in the queue depth metrics.
+type BufferedTrafficStore interface {
+	// BufferedRecords returns the number of records accepted but not yet stored.
+	BufferedRecords() int
+}
+
 // TrafficFilter specifies criteria for querying traffic records.
 type TrafficFilter struct {
 	// Time range
@@ -62,6 +72,32 @@ type TrafficFilter struct {
 	MinStatus   int   // Minimum status code (e.g., 400 for errors)
 	MaxStatus   int   // Maximum status code
 
+	// WebSocket filters
+	RecordTypes  []string // Filter by record type (http, websocket, websocket_frame)
+	ConnectionID string   // Filter by WebSocket connection ID
+
+	// User filter
+	User string // Filter by authenticated proxy user
+
+	// Header filters (all must match)
+	Headers []HeaderMatch
+
+	// Body filters. BodyContains matches case-insensitive substrings on
+	// every database.
+	BodyContains string           // Text in the request or response body
+	BodyFields   []BodyFieldMatch // JSON body field predicates (all must match)
+
+	// Duration filters
+	MinDuration time.Duration
+	MaxDuration time.Duration
+
+	// Metadata filters
+	Tags     []string // Records carrying all of these tags
+	ClientIP string   // Filter by client IP address
+
+	// Query is a filter expression combined with the other criteria
+	Query *query.Query
+
 	// Pagination
 	Limit  int
 	Offset int
@@ -82,6 +118,13 @@ type TrafficRecord struct {
 	Duration  time.Duration
 	StartTime time.Time
 	Error     string
+
+	// RecordType is http, websocket or websocket_frame
+	RecordType   string
+	ConnectionID string
+
+	// User is the authenticated proxy user
+	User string
 }
 
 // TrafficDetail represents full traffic details for a single record (detail view).
@@ -89,25 +132,54 @@ type TrafficDetail struct {
 	TrafficRecord
 
 	// Request details
-	Scheme             string              `json:"scheme"`
-	Query              string              `json:"query,omitempty"`
-	RequestHeaders     map[string][]string `json:"request_headers,omitempty"`
-	RequestBody        string              `json:"request_body,omitempty"`
-	RequestBodySize    int64               `json:"request_body_size"`
-	RequestIsBinary    bool                `json:"request_is_binary"`
-	RequestContentType string              `json:"request_content_type,omitempty"`
+	Scheme         string              `json:"scheme"`
+	Query          string              `json:"query,omitempty"`
+	RequestHeaders map[string][]string `json:"request_headers,omitempty"`
+	// RequestHeaderNames are the header names as captured, one per value,
+	// in order (see capture.HeadersFromMulti)
+	RequestHeaderNames []string `json:"request_header_names,omitempty"`
+	RequestBody        string   `json:"request_body,omitempty"`
+	RequestBodySize    int64    `json:"request_body_size"`
+	RequestIsBinary    bool     `json:"request_is_binary"`
+	// RequestTruncated is true when only a prefix of the body was captured
+	RequestTruncated bool `json:"request_truncated,omitempty"`
+	// RequestDecodedSize is the body size after removing its
+	// Content-Encoding; RequestBodySize is the encoded size
+	RequestDecodedSize int64  `json:"request_decoded_size,omitempty"`
+	RequestContentType string `json:"request_content_type,omitempty"`
+	HTTPVersion        string `json:"http_version,omitempty"`
+	StreamID           uint32 `json:"stream_id,omitempty"`
 
 	// Response details
 	StatusText          string              `json:"status_text,omitempty"`
 	ResponseHeaders     map[string][]string `json:"response_headers,omitempty"`
+	ResponseHeaderNames []string            `json:"response_header_names,omitempty"`
 	ResponseBody        string              `json:"response_body,omitempty"`
 	ResponseBodySize    int64               `json:"response_body_size"`
 	ResponseIsBinary    bool                `json:"response_is_binary"`
+	ResponseTruncated   bool                `json:"response_truncated,omitempty"`
+	ResponseDecodedSize int64               `json:"response_decoded_size,omitempty"`
 	ResponseContentType string              `json:"response_content_type,omitempty"`
+	ResponseHTTPVersion string              `json:"response_http_version,omitempty"`
+	// ResponseEvents are the events of server-sent event and NDJSON
+	// stream responses
+	ResponseEvents []capture.StreamEvent `json:"response_events,omitempty"`
 
-	// Additional timing
+	// Additional timing; for event streams TTFBMs is the time to the
+	// first event
 	TTFBMs *float64 `json:"ttfb_ms,omitempty"`
 
+	// WebSocket frame details
+	FrameSequence  int64  `json:"frame_sequence,omitempty"`
+	FrameDirection string `json:"frame_direction,omitempty"`
+	FrameOpcode    int    `json:"frame_opcode,omitempty"`
+	CloseCode      int    `json:"close_code,omitempty"`
+	CloseReason    string `json:"close_reason,omitempty"`
+
+	// gRPC call status
+	GRPCStatus  *int   `json:"grpc_status,omitempty"`
+	GRPCMessage string `json:"grpc_message,omitempty"`
+
 	// Metadata
 	ClientIP string   `json:"client_ip,omitempty"`
 	Tags     []string `json:"tags,omitempty"`
@@ -124,6 +196,24 @@ type TrafficStats struct {
 	UniqueHosts      int64
 	RequestsByMethod map[string]int64
 	RequestsByStatus map[int]int64
+
+	// Hosts breaks the statistics down by host, busiest first
+	// (for stores that support it).
+	Hosts []HostStats `json:",omitempty"`
+}
+
+// hostStatsLimit bounds the hosts in TrafficStats.Hosts.
+const hostStatsLimit = 100
+
+// HostStats contains aggregate traffic statistics for a single host.
+type HostStats struct {
+	Host          string
+	Requests      int64
+	Errors        int64
+	AvgDurationMs float64
+	P50DurationMs float64
+	P95DurationMs float64
+	P99DurationMs float64
 }
 
 // CertCache is the interface for caching generated TLS certificates.
@@ -146,6 +236,15 @@ type CertCache interface {
 	Close() error
 }
 
+// CertLocker is an optional interface for CertCaches shared between proxies.
+// Holding the lock for a host lets one proxy generate its certificate while
+// the others wait and then read it from the cache.
+type CertLocker interface {
+	// Lock acquires the generation lock for host, waiting until it is free
+	// or ctx is done. The returned function releases the lock.
+	Lock(ctx context.Context, host string) (unlock func(), err error)
+}
+
 // ConfigStore is the interface for storing proxy configuration.
 // Used in team and production modes for persistent configuration.
 type ConfigStore interface {
@@ -0,0 +1,271 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// RecordFormat is the encoding of records produced to Kafka.
+type RecordFormat string
+
+const (
+	// RecordFormatJSON encodes each record as its NDJSON capture line (the default).
+	RecordFormatJSON RecordFormat = "json"
+	// RecordFormatAvro encodes records with TrafficAvroSchema.
+	RecordFormatAvro RecordFormat = "avro"
+)
+
+// PartitionKey selects the Kafka message key that records are partitioned by.
+type PartitionKey string
+
+const (
+	// PartitionByHost keeps each upstream host's traffic in order (the default).
+	PartitionByHost PartitionKey = "host"
+	// PartitionByProxy keeps each proxy's traffic in order.
+	PartitionByProxy PartitionKey = "proxy"
+)
+
+// Acks is the delivery acknowledgement required from the brokers.
+type Acks string
+
+const (
+	// AcksAll waits for all in-sync replicas (the default).
+	AcksAll Acks = "all"
+	// AcksLeader waits for the partition leader only.
+	AcksLeader Acks = "leader"
+	// AcksNone does not wait for acknowledgement.
+	AcksNone Acks = "none"
+)
+
+// KafkaTrafficStore produces traffic records to a Kafka topic.
+// Suitable for production mode, feeding downstream consumers such as ClickHouse.
+type KafkaTrafficStore struct {
+	client       *kgo.Client
+	topic        string
+	proxyID      string
+	partitionKey PartitionKey
+	format       RecordFormat
+	metrics      Metrics
+}
+
+// KafkaTrafficStoreConfig configures a KafkaTrafficStore.
+type KafkaTrafficStoreConfig struct {
+	// Brokers are the seed broker addresses (host:port).
+	Brokers []string
+
+	// Topic is the topic records are produced to (default: "omniproxy.traffic").
+	Topic string
+
+	// ProxyID identifies this proxy in the records and, with
+	// PartitionByProxy, in the message keys (default: "default").
+	ProxyID string
+
+	// PartitionKey selects the message key: host or proxy (default: host).
+	PartitionKey PartitionKey
+
+	// Format is the record encoding: json or avro (default: json).
+	Format RecordFormat
+
+	// Acks is the acknowledgement StoreBatch waits for: all, leader or none (default: all).
+	Acks Acks
+
+	// MaxBufferedRecords bounds the records awaiting delivery; producing
+	// blocks while it is reached (default: 10000).
+	MaxBufferedRecords int
+
+	// Linger is how long the producer waits to fill a batch (default: 10ms).
+	Linger time.Duration
+
+	// TLS enables TLS to the brokers when set.
+	TLS *tls.Config
+
+	// Metrics for observability (optional).
+	Metrics Metrics
+}
+
+// NewKafkaTrafficStore connects to Kafka and returns a traffic store.
+func NewKafkaTrafficStore(ctx context.Context, cfg *KafkaTrafficStoreConfig) (*KafkaTrafficStore, error) {
+	if cfg == nil || len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka traffic store requires at least one broker")
+	}
+
+	topic := cfg.Topic
+	if topic == "" {
+		topic = "omniproxy.traffic"
+	}
+
+	proxyID := cfg.ProxyID
+	if proxyID == "" {
+		proxyID = "default"
+	}
+
+	partitionKey := cfg.PartitionKey
+	switch partitionKey {
+	case "":
+		partitionKey = PartitionByHost
+	case PartitionByHost, PartitionByProxy:
+	default:
+		return nil, fmt.Errorf("unknown kafka partition key %q (expected host or proxy)", partitionKey)
+	}
+
+	format := cfg.Format
+	switch format {
+	case "":
+		format = RecordFormatJSON
+	case RecordFormatJSON, RecordFormatAvro:
+	default:
+		return nil, fmt.Errorf("unknown kafka record format %q (expected json or avro)", format)
+	}
+
+	maxBuffered := cfg.MaxBufferedRecords
+	if maxBuffered <= 0 {
+		maxBuffered = 10000
+	}
+
+	linger := cfg.Linger
+	if linger == 0 {
+		linger = 10 * time.Millisecond
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(topic),
+		kgo.ClientID("omniproxy"),
+		kgo.MaxBufferedRecords(maxBuffered),
+		kgo.ProducerLinger(linger),
+	}
+	switch cfg.Acks {
+	case "", AcksAll:
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	case AcksLeader:
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite())
+	case AcksNone:
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()), kgo.DisableIdempotentWrite())
+	default:
+		return nil, fmt.Errorf("unknown kafka acks %q (expected all, leader or none)", cfg.Acks)
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, kgo.DialTLSConfig(cfg.TLS))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = NoopMetrics{}
+	}
+
+	return &KafkaTrafficStore{
+		client:       client,
+		topic:        topic,
+		proxyID:      proxyID,
+		partitionKey: partitionKey,
+		format:       format,
+		metrics:      metrics,
+	}, nil
+}
+
+// Store produces a single traffic record and waits for its acknowledgement.
+func (s *KafkaTrafficStore) Store(ctx context.Context, rec *capture.Record) error {
+	return s.StoreBatch(ctx, []*capture.Record{rec})
+}
+
+// StoreBatch produces traffic records and waits until all of them are
+// acknowledged. Producing blocks while MaxBufferedRecords are in flight.
+func (s *KafkaTrafficStore) StoreBatch(ctx context.Context, recs []*capture.Record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	msgs := make([]*kgo.Record, 0, len(recs))
+	for _, rec := range recs {
+		msg, err := s.message(rec)
+		if err != nil {
+			s.metrics.IncStoreError()
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	err := s.client.ProduceSync(ctx, msgs...).FirstErr()
+	s.metrics.ObserveStoreDuration(time.Since(start))
+	if err != nil {
+		s.metrics.IncStoreError()
+		return fmt.Errorf("failed to produce traffic records: %w", err)
+	}
+	for range recs {
+		s.metrics.IncStoreSuccess()
+	}
+	return nil
+}
+
+// BufferedRecords returns the number of records awaiting acknowledgement.
+func (s *KafkaTrafficStore) BufferedRecords() int {
+	return int(s.client.BufferedProduceRecords())
+}
+
+// Ping checks that the brokers are reachable.
+func (s *KafkaTrafficStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx)
+}
+
+// Topic returns the topic records are produced to.
+func (s *KafkaTrafficStore) Topic() string {
+	return s.topic
+}
+
+// Close flushes buffered records and closes the client.
+func (s *KafkaTrafficStore) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := s.client.Flush(ctx)
+	s.client.Close()
+	return err
+}
+
+// message encodes rec as a Kafka message.
+func (s *KafkaTrafficStore) message(rec *capture.Record) (*kgo.Record, error) {
+	key := rec.Request.Host
+	if s.partitionKey == PartitionByProxy {
+		key = s.proxyID
+	}
+
+	msg := &kgo.Record{
+		Key: []byte(key),
+		Headers: []kgo.RecordHeader{
+			{Key: "proxy-id", Value: []byte(s.proxyID)},
+		},
+	}
+	if !rec.StartTime.IsZero() {
+		msg.Timestamp = rec.StartTime
+	}
+
+	switch s.format {
+	case RecordFormatAvro:
+		msg.Value = encodeAvro(rec, s.proxyID)
+		msg.Headers = append(msg.Headers, kgo.RecordHeader{Key: "content-type", Value: []byte("application/avro")})
+	default:
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal traffic record: %w", err)
+		}
+		msg.Value = data
+		msg.Headers = append(msg.Headers, kgo.RecordHeader{Key: "content-type", Value: []byte("application/json")})
+	}
+	return msg, nil
+}
@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// newKafkaCluster starts an in-process broker with the given topic.
+func newKafkaCluster(t *testing.T, topic string) []string {
+	t.Helper()
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(3, topic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cluster.Close)
+	return cluster.ListenAddrs()
+}
+
+// consumeKafka reads n records from the start of topic.
+func consumeKafka(t *testing.T, brokers []string, topic string, n int) []*kgo.Record {
+	t.Helper()
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var recs []*kgo.Record
+	for len(recs) < n {
+		fetches := client.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("consumed %d of %d records: %v", len(recs), n, err)
+		}
+		recs = append(recs, fetches.Records()...)
+	}
+	return recs
+}
+
+func kafkaRecord(host, path string) *capture.Record {
+	return &capture.Record{
+		Request: capture.RequestRecord{
+			Method:  "GET",
+			URL:     "https://" + host + path,
+			Host:    host,
+			Path:    path,
+			Scheme:  "https",
+			Headers: capture.Headers{{Name: "accept", Value: "application/json"}},
+		},
+		Response:   capture.ResponseRecord{Status: 200, Body: map[string]interface{}{"ok": true}},
+		StartTime:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		DurationMs: 12.5,
+	}
+}
+
+func TestKafkaTrafficStore(t *testing.T) {
+	brokers := newKafkaCluster(t, "traffic")
+
+	metrics := &countingMetrics{}
+	store, err := NewKafkaTrafficStore(context.Background(), &KafkaTrafficStoreConfig{
+		Brokers: brokers,
+		Topic:   "traffic",
+		ProxyID: "edge-1",
+		Metrics: metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	recs := []*capture.Record{
+		kafkaRecord("api.example.com", "/users"),
+		kafkaRecord("cdn.example.com", "/app.js"),
+		kafkaRecord("api.example.com", "/orders"),
+	}
+	if err := store.StoreBatch(context.Background(), recs); err != nil {
+		t.Fatal(err)
+	}
+	if store.BufferedRecords() != 0 {
+		t.Errorf("expected all records to be acknowledged, %d buffered", store.BufferedRecords())
+	}
+
+	got := consumeKafka(t, brokers, "traffic", 3)
+	partitions := map[string]int32{}
+	for _, msg := range got {
+		var rec capture.Record
+		if err := json.Unmarshal(msg.Value, &rec); err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.Key) != rec.Request.Host {
+			t.Errorf("expected key %s, got %s", rec.Request.Host, msg.Key)
+		}
+		if p, ok := partitions[rec.Request.Host]; ok && p != msg.Partition {
+			t.Errorf("records for %s were spread across partitions", rec.Request.Host)
+		}
+		partitions[rec.Request.Host] = msg.Partition
+
+		headers := map[string]string{}
+		for _, h := range msg.Headers {
+			headers[h.Key] = string(h.Value)
+		}
+		if headers["proxy-id"] != "edge-1" || headers["content-type"] != "application/json" {
+			t.Errorf("unexpected headers: %v", headers)
+		}
+		if !msg.Timestamp.Equal(rec.StartTime) {
+			t.Errorf("expected the record start time as timestamp, got %v", msg.Timestamp)
+		}
+	}
+
+	if _, err := NewKafkaTrafficStore(context.Background(), &KafkaTrafficStoreConfig{Brokers: brokers, Format: "xml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestKafkaTrafficStoreAvro(t *testing.T) {
+	brokers := newKafkaCluster(t, "traffic-avro")
+
+	store, err := NewKafkaTrafficStore(context.Background(), &KafkaTrafficStoreConfig{
+		Brokers:      brokers,
+		Topic:        "traffic-avro",
+		ProxyID:      "edge-1",
+		PartitionKey: PartitionByProxy,
+		Format:       RecordFormatAvro,
+		Acks:         AcksLeader,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if err := store.Store(context.Background(), kafkaRecord("api.example.com", "/users")); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := consumeKafka(t, brokers, "traffic-avro", 1)[0]
+	if string(msg.Key) != "edge-1" {
+		t.Errorf("expected the proxy ID as key, got %s", msg.Key)
+	}
+	b := msg.Value
+	if !bytes.HasPrefix(b, []byte{0xc3, 0x01}) || binary.LittleEndian.Uint64(b[2:10]) != TrafficAvroFingerprint {
+		t.Fatalf("missing single-object encoding header: % x", b[:10])
+	}
+
+	// Decode the leading fields
+	d := &avroDecoder{b: b[10:]}
+	fields := []string{d.string(), d.string(), d.string()}
+	if fields[0] != "edge-1" || fields[1] != "http" || fields[2] != "" {
+		t.Errorf("unexpected proxy_id, type and user: %q", fields)
+	}
+	if ms := d.long(); ms != kafkaRecord("", "").StartTime.UnixMilli() {
+		t.Errorf("unexpected start_time_ms %d", ms)
+	}
+}
+
+func TestAvroFingerprint(t *testing.T) {
+	// Reference value from the Avro specification test suite
+	if got := avroFingerprint(`"null"`); got != 0x63dd24e7cc258f8a {
+		t.Errorf("unexpected fingerprint %#x", got)
+	}
+}
+
+// avroDecoder reads Avro binary values.
+type avroDecoder struct {
+	b []byte
+}
+
+func (d *avroDecoder) long() int64 {
+	v, n := binary.Uvarint(d.b)
+	d.b = d.b[n:]
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *avroDecoder) string() string {
+	n := d.long()
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+// bufferedStore is a TrafficStore holding a fixed number of records.
+type bufferedStore struct {
+	TrafficStore
+	buffered int
+}
+
+func (s *bufferedStore) BufferedRecords() int { return s.buffered }
+func (s *bufferedStore) Close() error         { return nil }
+
+func TestAsyncQueueDepthIncludesBufferedRecords(t *testing.T) {
+	w := NewAsyncTrafficStore(&bufferedStore{buffered: 5}, nil)
+	defer w.Close()
+
+	if depth := w.QueueDepth(); depth != 5 {
+		t.Errorf("expected queue depth 5, got %d", depth)
+	}
+}
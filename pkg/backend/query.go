@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"fmt"
+
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/traffic"
+)
+
+// queryColumns maps the string fields of filter expressions to columns,
+// which share their names across the database and ClickHouse stores.
+var queryColumns = map[string]string{
+	query.FieldHost:         traffic.FieldHost,
+	query.FieldMethod:       traffic.FieldMethod,
+	query.FieldPath:         traffic.FieldPath,
+	query.FieldURL:          traffic.FieldURL,
+	query.FieldScheme:       traffic.FieldScheme,
+	query.FieldClientIP:     traffic.FieldClientIP,
+	query.FieldUser:         traffic.FieldAuthUser,
+	query.FieldType:         traffic.FieldRecordType,
+	query.FieldConnectionID: traffic.FieldConnectionID,
+}
+
+// queryPredicate compiles a filter expression into a Traffic predicate.
+func (s *DatabaseTrafficStore) queryPredicate(e query.Expr) (predicate.Traffic, error) {
+	switch e := e.(type) {
+	case *query.And:
+		left, right, err := s.queryPredicates(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return traffic.And(left, right), nil
+	case *query.Or:
+		left, right, err := s.queryPredicates(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return traffic.Or(left, right), nil
+	case *query.Not:
+		x, err := s.queryPredicate(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return traffic.Not(x), nil
+	case *query.Compare:
+		return s.comparePredicate(e)
+	}
+	return nil, fmt.Errorf("unsupported query expression %T", e)
+}
+
+// queryPredicates compiles both sides of a binary expression.
+func (s *DatabaseTrafficStore) queryPredicates(left, right query.Expr) (predicate.Traffic, predicate.Traffic, error) {
+	l, err := s.queryPredicate(left)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := s.queryPredicate(right)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+// comparePredicate compiles a comparison.
+func (s *DatabaseTrafficStore) comparePredicate(c *query.Compare) (predicate.Traffic, error) {
+	v := c.Value()
+
+	switch c.Field.Name {
+	case query.FieldStatus:
+		codes := make([]int, len(c.Values))
+		for i, v := range c.Values {
+			codes[i] = int(v.Num)
+		}
+		return numberPredicate(traffic.FieldStatusCode, c.Op, codes[0], codes), nil
+	case query.FieldDuration:
+		return numberPredicate(traffic.FieldDurationMs, c.Op, v.Num, nil), nil
+	}
+
+	// Negations also match rows without the field
+	if c.Op == query.OpNe || c.Op == query.OpNotMatch {
+		pos := *c
+		pos.Op = query.OpEq
+		if c.Op == query.OpNotMatch {
+			pos.Op = query.OpMatch
+		}
+		p, err := s.comparePredicate(&pos)
+		if err != nil {
+			return nil, err
+		}
+		if column, ok := queryColumns[c.Field.Name]; ok {
+			return traffic.Or(entsql.FieldIsNull(column), traffic.Not(p)), nil
+		}
+		if c.Field.Name == query.FieldTag {
+			return traffic.Or(traffic.TagsIsNil(), traffic.Not(p)), nil
+		}
+		return traffic.Not(p), nil
+	}
+
+	switch c.Field.Name {
+	case query.FieldTag:
+		preds := make([]predicate.Traffic, len(c.Values))
+		for i, v := range c.Values {
+			preds[i] = tagPredicate(v.Str)
+		}
+		return traffic.Or(preds...), nil
+	case query.FieldBody:
+		return s.bodyContainsPredicate(v.Str), nil
+	case query.FieldRequestHeader, query.FieldResponseHeader:
+		return headerValuePredicate(c.Field.Name == query.FieldResponseHeader, c.Field.Key, c.Op, v.Str), nil
+	case query.FieldRequestJSON, query.FieldResponseJSON:
+		return jsonFieldPredicate(c.Field.Name == query.FieldResponseJSON, c.Field.Path, c.Op, v.Str), nil
+	}
+
+	column, ok := queryColumns[c.Field.Name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query field %s", c.Field.Name)
+	}
+	switch c.Op {
+	case query.OpEq:
+		if v.Str == "" {
+			// Optional fields are stored as NULL
+			return traffic.Or(entsql.FieldIsNull(column), entsql.FieldEQ(column, "")), nil
+		}
+		return entsql.FieldEQ(column, v.Str), nil
+	case query.OpIn:
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = v.Str
+		}
+		return entsql.FieldIn(column, values...), nil
+	}
+	return func(sel *entsql.Selector) {
+		expr := sel.C(column)
+		sel.Where(entsql.P(func(b *entsql.Builder) {
+			stringCond(b, expr, c.Op, v.Str)
+		}))
+	}, nil
+}
+
+// numberPredicate compares a numeric column; values lists the operands of
+// OpIn.
+func numberPredicate[T int | float64](column string, op query.Op, v T, values []T) predicate.Traffic {
+	switch op {
+	case query.OpNe:
+		return entsql.FieldNEQ(column, v)
+	case query.OpGt:
+		return entsql.FieldGT(column, v)
+	case query.OpGe:
+		return entsql.FieldGTE(column, v)
+	case query.OpLt:
+		return entsql.FieldLT(column, v)
+	case query.OpLe:
+		return entsql.FieldLTE(column, v)
+	case query.OpIn:
+		return entsql.FieldIn(column, values...)
+	}
+	return entsql.FieldEQ(column, v)
+}
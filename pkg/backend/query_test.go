@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+func TestDatabaseTrafficStoreQueryExpr(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	recs := []*capture.Record{
+		{
+			StartTime:  now,
+			DurationMs: 750,
+			Request: capture.RequestRecord{
+				Method:  "POST",
+				URL:     "https://api.stripe.com/v1/charges",
+				Host:    "api.stripe.com",
+				Path:    "/v1/charges",
+				Scheme:  "https",
+				Headers: capture.Headers{{Name: "x-tenant", Value: "acme"}},
+				Body:    map[string]interface{}{"amount": 1200, "items": []interface{}{map[string]interface{}{"sku": "tea"}}},
+			},
+			Response: capture.ResponseRecord{
+				Status:  402,
+				Headers: capture.Headers{{Name: "request-id", Value: "req_123"}},
+				Body:    "Card declined",
+			},
+			NetworkProfile: "3g",
+			User:           "alice",
+			ClientIP:       "10.0.0.7",
+		},
+		{
+			StartTime:  now,
+			DurationMs: 40,
+			Request: capture.RequestRecord{
+				Method:  "GET",
+				URL:     "https://files.stripe.com/v1/files",
+				Host:    "files.stripe.com",
+				Path:    "/v1/files",
+				Scheme:  "https",
+				Headers: capture.Headers{{Name: "x-tenant", Value: "globex"}},
+			},
+			Response: capture.ResponseRecord{Status: 200, Body: map[string]interface{}{"paid": true}},
+			ClientIP: "10.0.0.8",
+		},
+		{
+			StartTime:  now,
+			DurationMs: 3,
+			Request: capture.RequestRecord{
+				Method: "GET",
+				URL:    "http://localhost:8080/health",
+				Host:   "localhost:8080",
+				Path:   "/health",
+				Scheme: "http",
+			},
+			Response: capture.ResponseRecord{Status: 500},
+		},
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stored queries return the records that match in memory
+	exprs := []string{
+		`host ~ "*.stripe.com" && status >= 400 && req.header["x-tenant"] == "acme" && duration > 500ms`,
+		`host ~ "*.stripe.com" && !(method == GET)`,
+		`host !~ "*.stripe.com"`,
+		`host ~ "API.*"`,
+		`path contains "FILES" || status in [500, 503]`,
+		`method in [POST, PUT]`,
+		`scheme != https`,
+		`duration < 50 && status == 200`,
+		`client_ip == 10.0.0.8 || user == alice`,
+		`user != alice`,
+		`tag == "network:3g"`,
+		`tag != "network:3g"`,
+		`req.header["x-tenant"]`,
+		`req.header["x-tenant"] != acme`,
+		`req.header["x-tenant"] ~ "glo*"`,
+		`resp.header["request-id"] contains "REQ_"`,
+		`req.json["$.amount"] == 1200`,
+		`req.json["items[0].sku"] == tea`,
+		`resp.json["paid"] == true`,
+		`resp.json["paid"]`,
+		`body contains "declined"`,
+		`type == http && connection_id == ""`,
+	}
+
+	for _, expr := range exprs {
+		q, err := query.Parse(expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+
+		var want []string
+		for _, rec := range recs {
+			if q.Match(rec) {
+				want = append(want, rec.Request.Path)
+			}
+		}
+
+		rows, err := store.Query(ctx, &TrafficFilter{Query: q})
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, row.Path)
+		}
+		slices.Sort(want)
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: expected %v, got %v", expr, want, got)
+		}
+	}
+}
+
+func TestClickHouseTrafficStoreQueryExpr(t *testing.T) {
+	server := newClickHouseServer(t)
+	store := newTestClickHouseStore(t, server)
+
+	q, err := query.Parse(`host ~ "*.stripe.com" && (status >= 500 || req.header["x-tenant"] != acme) && !(tag == slow)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Count(context.Background(), &TrafficFilter{Query: q}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := server.last(t, "SELECT count()")
+	wantSQL := " WHERE ((host LIKE {p0:String} AND (status_code >= {p1:UInt16}" +
+		" OR NOT ((mapContains(request_headers, {p2:String}) AND request_headers[{p2:String}] = {p3:String}))))" +
+		" AND NOT (has(tags, {p4:String})))"
+	if !strings.Contains(req.sql, wantSQL) {
+		t.Errorf("unexpected SQL:\n%s\nwant:\n%s", req.sql, wantSQL)
+	}
+	wantParams := map[string]string{"param_p0": "%.stripe.com", "param_p1": "500", "param_p2": "x-tenant", "param_p3": "acme", "param_p4": "slow"}
+	for k, v := range wantParams {
+		if got := req.params.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
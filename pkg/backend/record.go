@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Record converts stored traffic details back into a capture.Record, so
+// stored traffic can be replayed, mocked or exported like captured files.
+func (d *TrafficDetail) Record() *capture.Record {
+	rec := &capture.Record{
+		Type:       capture.RecordType(d.RecordType),
+		StartTime:  d.StartTime,
+		EndTime:    d.StartTime.Add(d.Duration),
+		DurationMs: durationMs(d.Duration),
+		User:       d.User,
+		StreamID:   d.StreamID,
+		Request: capture.RequestRecord{
+			Method:      d.Method,
+			URL:         d.URL,
+			Host:        d.Host,
+			Path:        d.Path,
+			Scheme:      d.Scheme,
+			Headers:     capture.HeadersFromMulti(d.RequestHeaders, d.RequestHeaderNames),
+			Body:        storedBody(d.RequestBody),
+			BodySize:    d.RequestBodySize,
+			IsBinary:    d.RequestIsBinary,
+			Truncated:   d.RequestTruncated,
+			DecodedSize: d.RequestDecodedSize,
+			ContentType: d.RequestContentType,
+			HTTPVersion: d.HTTPVersion,
+		},
+		Response: capture.ResponseRecord{
+			Status:      d.Status,
+			StatusText:  d.StatusText,
+			Headers:     capture.HeadersFromMulti(d.ResponseHeaders, d.ResponseHeaderNames),
+			Body:        storedBody(d.ResponseBody),
+			IsBinary:    d.ResponseIsBinary,
+			Truncated:   d.ResponseTruncated,
+			DecodedSize: d.ResponseDecodedSize,
+			ContentType: d.ResponseContentType,
+			Size:        d.ResponseBodySize,
+			Events:      d.ResponseEvents,
+			HTTPVersion: d.ResponseHTTPVersion,
+		},
+	}
+	if rec.Type == capture.RecordTypeHTTP {
+		rec.Type = ""
+	}
+
+	if d.TTFBMs != nil {
+		rec.TTFBMs = *d.TTFBMs
+	}
+
+	if rec.Type == capture.RecordTypeGRPC {
+		rec.GRPC = grpcRecord(d)
+	}
+
+	rec.Request.Cookies = rec.Request.Headers.Cookies()
+	rec.Response.Cookies = rec.Response.Headers.SetCookies()
+	rec.Request.ContentEncoding = rec.Request.Headers.Get("Content-Encoding")
+	rec.Response.ContentEncoding = rec.Response.Headers.Get("Content-Encoding")
+
+	// The query column holds the JSON-encoded query, or a query string
+	if d.Query != "" {
+		var query capture.Query
+		if err := json.Unmarshal([]byte(d.Query), &query); err == nil {
+			rec.Request.Query = query
+		} else {
+			rec.Request.Query = capture.ParseQuery(d.Query)
+		}
+	}
+
+	for _, tag := range d.Tags {
+		if profile, ok := capture.NetworkProfileFromTag(tag); ok {
+			rec.NetworkProfile = profile
+		}
+	}
+
+	if rec.Request.IsBinary {
+		rec.Request.Body = capture.BinaryPlaceholder
+	}
+	if rec.Response.IsBinary {
+		rec.Response.Body = capture.BinaryPlaceholder
+	}
+
+	return rec
+}
+
+// NewTrafficRecord returns the list view of a captured record, as Query
+// returns it once the record is stored. ID is empty until then.
+func NewTrafficRecord(rec *capture.Record) *TrafficRecord {
+	r := &TrafficRecord{
+		Method:     rec.Request.Method,
+		URL:        rec.Request.URL,
+		Host:       rec.Request.Host,
+		Path:       rec.Request.Path,
+		Status:     rec.Response.Status,
+		Duration:   time.Duration(rec.DurationMs * float64(time.Millisecond)),
+		StartTime:  rec.StartTime,
+		RecordType: string(rec.Type),
+		User:       rec.User,
+	}
+	if r.RecordType == "" {
+		r.RecordType = string(capture.RecordTypeHTTP)
+	}
+	if rec.WebSocket != nil {
+		r.ConnectionID = rec.WebSocket.ConnectionID
+	}
+	return r
+}
+
+// grpcRecord rebuilds the gRPC details of a call from its path and status.
+func grpcRecord(d *TrafficDetail) *capture.GRPCRecord {
+	service, method := capture.ParseGRPCPath(d.Path)
+	g := &capture.GRPCRecord{
+		Service: service,
+		Method:  method,
+		Message: d.GRPCMessage,
+		Web:     strings.HasPrefix(d.RequestContentType, "application/grpc-web"),
+	}
+	if d.GRPCStatus != nil {
+		g.Status = *d.GRPCStatus
+		g.StatusName = capture.GRPCStatusName(g.Status)
+	}
+	if msgs, ok := storedBody(d.RequestBody).([]interface{}); ok {
+		g.RequestMessages = len(msgs)
+	}
+	if msgs, ok := storedBody(d.ResponseBody).([]interface{}); ok {
+		g.ResponseMessages = len(msgs)
+	}
+	return g
+}
+
+// storedBody decodes a body column, which holds the JSON encoding of the
+// captured body (a JSON value or a string).
+func storedBody(body string) interface{} {
+	if body == "" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	return v
+}
+
+// durationMs converts a duration to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
@@ -0,0 +1,326 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// unlockScript deletes a lock only if it is still held by the caller.
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// redisLockRetry is how often a held lock is polled.
+const redisLockRetry = 25 * time.Millisecond
+
+// RedisCertCache is a certificate cache shared by proxies through Redis.
+// Suitable for production mode where several replicas serve the same clients.
+//
+// Keys are namespaced by the configured prefix:
+//
+//	<prefix>cert:<host>  PEM certificate chain and private key
+//	<prefix>lock:<host>  generation lock
+//	<prefix>ca           shared CA certificate and private key
+type RedisCertCache struct {
+	client  *redisClient
+	prefix  string
+	ttl     time.Duration
+	lockTTL time.Duration
+	timeout time.Duration
+	metrics Metrics
+}
+
+// RedisCertCacheConfig configures a RedisCertCache.
+type RedisCertCacheConfig struct {
+	// URL is the Redis URL: redis://[[user]:password@]host[:port][/db], or rediss:// for TLS.
+	URL string
+
+	// Prefix namespaces the cache keys (default: "omniproxy:").
+	Prefix string
+
+	// TTL is how long certificates are cached (default: 24 hours).
+	// Certificates never outlive their expiry.
+	TTL time.Duration
+
+	// LockTTL bounds how long a crashed proxy can hold a generation lock (default: 30 seconds).
+	LockTTL time.Duration
+
+	// Timeout bounds each Redis command (default: 5 seconds).
+	Timeout time.Duration
+
+	// Metrics for observability (optional).
+	Metrics Metrics
+}
+
+// NewRedisCertCache connects to Redis and returns a certificate cache.
+func NewRedisCertCache(cfg *RedisCertCacheConfig) (*RedisCertCache, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, errors.New("redis cert cache requires a URL")
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	client, err := newRedisClient(cfg.URL, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "omniproxy:"
+	}
+
+	ttl := cfg.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+
+	lockTTL := cfg.LockTTL
+	if lockTTL == 0 {
+		lockTTL = 30 * time.Second
+	}
+
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = NoopMetrics{}
+	}
+
+	cache := &RedisCertCache{
+		client:  client,
+		prefix:  prefix,
+		ttl:     ttl,
+		lockTTL: lockTTL,
+		timeout: timeout,
+		metrics: metrics,
+	}
+
+	if err := cache.Ping(); err != nil {
+		_ = client.close()
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// Ping checks that Redis is reachable.
+func (c *RedisCertCache) Ping() error {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if _, err := c.client.do(ctx, "PING"); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a certificate for the given hostname.
+// Unreachable servers and unreadable entries count as misses.
+func (c *RedisCertCache) Get(host string) (*tls.Certificate, bool) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	reply, err := c.client.do(ctx, "GET", c.prefix+"cert:"+host)
+	data, ok := reply.([]byte)
+	if err != nil || !ok {
+		c.metrics.IncCacheMiss()
+		return nil, false
+	}
+
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		c.metrics.IncCacheMiss()
+		return nil, false
+	}
+
+	c.metrics.IncCacheHit()
+	return &cert, true
+}
+
+// Set stores a certificate for the given hostname.
+func (c *RedisCertCache) Set(host string, cert *tls.Certificate) {
+	data, ttl, err := encodeCertificate(cert)
+	if err != nil {
+		return
+	}
+	if ttl > c.ttl {
+		ttl = c.ttl
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, _ = c.client.do(ctx, "SET", c.prefix+"cert:"+host, string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+}
+
+// Delete removes a certificate from the cache.
+func (c *RedisCertCache) Delete(host string) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, _ = c.client.do(ctx, "DEL", c.prefix+"cert:"+host)
+}
+
+// Clear removes all certificates from the cache. Locks and the shared CA
+// are kept.
+func (c *RedisCertCache) Clear() {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	cursor := "0"
+	for {
+		reply, err := c.client.do(ctx, "SCAN", cursor, "MATCH", c.prefix+"cert:*", "COUNT", "100")
+		items, ok := reply.([]interface{})
+		if err != nil || !ok || len(items) != 2 {
+			return
+		}
+		next, _ := items[0].([]byte)
+		keys, _ := items[1].([]interface{})
+
+		if len(keys) > 0 {
+			args := []string{"DEL"}
+			for _, key := range keys {
+				if b, ok := key.([]byte); ok {
+					args = append(args, string(b))
+				}
+			}
+			_, _ = c.client.do(ctx, args...)
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// Close releases the connections to Redis.
+func (c *RedisCertCache) Close() error {
+	return c.client.close()
+}
+
+// Lock acquires the generation lock for host, waiting until it is free or
+// ctx is done. Locks expire after LockTTL so a crashed proxy cannot hold one
+// forever.
+func (c *RedisCertCache) Lock(ctx context.Context, host string) (func(), error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := c.prefix + "lock:" + host
+	ttl := strconv.FormatInt(c.lockTTL.Milliseconds(), 10)
+	for {
+		reply, err := c.client.do(ctx, "SET", key, token, "NX", "PX", ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire certificate lock: %w", err)
+		}
+		if reply != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to acquire certificate lock: %w", ctx.Err())
+		case <-time.After(redisLockRetry):
+		}
+	}
+
+	unlock := func() {
+		ctx, cancel := c.context()
+		defer cancel()
+		_, _ = c.client.do(ctx, "EVAL", unlockScript, "1", key, token)
+	}
+	return unlock, nil
+}
+
+// ShareCA makes all proxies using this cache sign with the same CA, which
+// they must since they serve each other's certificates. The first proxy
+// stores its CA; the stored CA is returned to every caller and should
+// replace their own.
+func (c *RedisCertCache) ShareCA(ctx context.Context, certPEM, keyPEM []byte) (sharedCert, sharedKey []byte, err error) {
+	key := c.prefix + "ca"
+	if _, err := c.client.do(ctx, "SET", key, string(certPEM)+string(keyPEM), "NX"); err != nil {
+		return nil, nil, fmt.Errorf("failed to store shared CA: %w", err)
+	}
+
+	reply, err := c.client.do(ctx, "GET", key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load shared CA: %w", err)
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, nil, errors.New("failed to load shared CA: no CA stored")
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			sharedCert = append(sharedCert, pem.EncodeToMemory(block)...)
+		} else {
+			sharedKey = append(sharedKey, pem.EncodeToMemory(block)...)
+		}
+	}
+	if len(sharedCert) == 0 || len(sharedKey) == 0 {
+		return nil, nil, errors.New("failed to load shared CA: stored CA is incomplete")
+	}
+	return sharedCert, sharedKey, nil
+}
+
+// context returns a context bounded by the command timeout.
+func (c *RedisCertCache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// encodeCertificate encodes a certificate chain and its private key as PEM
+// and returns how long the leaf remains valid.
+func encodeCertificate(cert *tls.Certificate) ([]byte, time.Duration, error) {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return nil, 0, errors.New("empty certificate")
+	}
+
+	leaf := cert.Leaf
+	if leaf == nil {
+		var err error
+		if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, der := range cert.Certificate {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	_ = pem.Encode(&buf, &pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return buf.Bytes(), time.Until(leaf.NotAfter), nil
+}
+
+// randomToken returns a random lock owner token.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,366 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grokify/omniproxy/ui/ent"
+	"github.com/grokify/omniproxy/ui/ent/org"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/proxy"
+	"github.com/grokify/omniproxy/ui/ent/traffic"
+)
+
+// Retention actions reported to RetentionMetrics.
+const (
+	RetentionRolledUp = "rolled_up"
+	RetentionStripped = "stripped"
+	RetentionArchived = "archived"
+	RetentionDeleted  = "deleted"
+)
+
+// RetentionMetrics provides observability for the retention worker.
+type RetentionMetrics interface {
+	// AddRetention counts rows affected by a retention action.
+	AddRetention(action string, n int)
+
+	// ObserveRetentionRun records the duration and outcome of a run.
+	ObserveRetentionRun(d time.Duration, err error)
+}
+
+// NoopRetentionMetrics is a RetentionMetrics implementation that does nothing.
+type NoopRetentionMetrics struct{}
+
+func (NoopRetentionMetrics) AddRetention(string, int)                 {}
+func (NoopRetentionMetrics) ObserveRetentionRun(time.Duration, error) {}
+
+// RetentionConfig configures the retention worker.
+type RetentionConfig struct {
+	// Interval is how often the worker runs (default: 1h).
+	Interval time.Duration
+
+	// BatchSize is the number of rows updated or deleted per statement
+	// (default: 1000).
+	BatchSize int
+
+	// BodyRetention strips request and response bodies from traffic older
+	// than this, keeping the metadata until the org's retention window ends.
+	// Zero keeps bodies as long as the rows.
+	BodyRetention time.Duration
+
+	// ArchiveDir, if set, receives expired traffic as NDJSON before it is
+	// deleted, one file per org and run.
+	ArchiveDir string
+
+	// RollupDelay is how long after an hour ends before it is rolled up,
+	// leaving time for queued records to be stored (default: 5m).
+	RollupDelay time.Duration
+
+	// Metrics for observability (optional).
+	Metrics RetentionMetrics
+
+	// ErrorHandler is called with the error of a failed scheduled run
+	// (optional).
+	ErrorHandler func(error)
+}
+
+// RetentionResult reports the rows affected by a retention run.
+type RetentionResult struct {
+	RolledUp int // Rollup rows written
+	Stripped int // Traffic rows whose bodies were removed
+	Archived int // Traffic rows written to the archive
+	Deleted  int // Traffic rows deleted
+}
+
+// RetentionWorker enforces each org's traffic_retention_days on a
+// DatabaseTrafficStore. Each run rolls up complete hours of traffic into
+// hourly aggregates, archives and deletes traffic past the retention window,
+// then strips bodies past BodyRetention, in bounded batches. Traffic is never
+// deleted before it has been rolled up.
+type RetentionWorker struct {
+	store *DatabaseTrafficStore
+	cfg   RetentionConfig
+	now   func() time.Time
+
+	runMu    sync.Mutex
+	wg       sync.WaitGroup
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRetentionWorker creates a retention worker for store.
+func NewRetentionWorker(store *DatabaseTrafficStore, cfg *RetentionConfig) *RetentionWorker {
+	c := RetentionConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 1000
+	}
+	if c.RollupDelay <= 0 {
+		c.RollupDelay = 5 * time.Minute
+	}
+	if c.Metrics == nil {
+		c.Metrics = NoopRetentionMetrics{}
+	}
+
+	return &RetentionWorker{
+		store:    store,
+		cfg:      c,
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the worker immediately and then every Interval until Stop.
+func (w *RetentionWorker) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			w.runScheduled()
+			select {
+			case <-ticker.C:
+			case <-w.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the worker and waits for a run in progress to finish.
+func (w *RetentionWorker) Stop() {
+	w.stopOnce.Do(func() { close(w.stopChan) })
+	w.wg.Wait()
+}
+
+// runScheduled performs a run, canceling it if the worker is stopped.
+func (w *RetentionWorker) runScheduled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-w.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if _, err := w.RunOnce(ctx); err != nil && w.cfg.ErrorHandler != nil {
+		w.cfg.ErrorHandler(err)
+	}
+}
+
+// RunOnce performs a single retention run.
+func (w *RetentionWorker) RunOnce(ctx context.Context) (*RetentionResult, error) {
+	w.runMu.Lock()
+	defer w.runMu.Unlock()
+
+	start := time.Now()
+	result, err := w.run(ctx)
+	w.cfg.Metrics.ObserveRetentionRun(time.Since(start), err)
+	return result, err
+}
+
+// run rolls up, prunes and strips traffic.
+func (w *RetentionWorker) run(ctx context.Context) (*RetentionResult, error) {
+	if err := w.store.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	result := &RetentionResult{}
+	now := w.now().UTC()
+
+	// Roll up first; pruning stops at the hours rolled up so far
+	rolledUpTo := now.Add(-w.cfg.RollupDelay).Truncate(time.Hour)
+	n, err := w.store.rollupTraffic(ctx, rolledUpTo)
+	result.RolledUp = n
+	w.cfg.Metrics.AddRetention(RetentionRolledUp, n)
+	if err != nil {
+		return result, err
+	}
+
+	orgs, err := w.store.client.Org.Query().All(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to list orgs: %w", err)
+	}
+
+	for _, o := range orgs {
+		inOrg := traffic.HasProxyWith(proxy.HasOrgWith(org.ID(o.ID)))
+
+		var cutoff time.Time
+		if o.TrafficRetentionDays > 0 {
+			cutoff = now.AddDate(0, 0, -o.TrafficRetentionDays)
+			if cutoff.After(rolledUpTo) {
+				cutoff = rolledUpTo
+			}
+		}
+
+		if !cutoff.IsZero() {
+			archived, deleted, err := w.prune(ctx, o, inOrg, cutoff)
+			result.Archived += archived
+			result.Deleted += deleted
+			if err != nil {
+				return result, err
+			}
+		}
+
+		if w.cfg.BodyRetention > 0 {
+			bodyCutoff := now.Add(-w.cfg.BodyRetention)
+			if cutoff.Before(bodyCutoff) {
+				n, err := w.stripBodies(ctx, inOrg, bodyCutoff)
+				result.Stripped += n
+				if err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// stripBodies removes the bodies of traffic started before cutoff.
+func (w *RetentionWorker) stripBodies(ctx context.Context, inOrg predicate.Traffic, cutoff time.Time) (int, error) {
+	total := 0
+	for {
+		ids, err := w.store.client.Traffic.Query().
+			Where(
+				inOrg,
+				traffic.StartedAtLT(cutoff),
+				traffic.Or(traffic.RequestBodyNotNil(), traffic.ResponseBodyNotNil()),
+			).
+			Limit(w.cfg.BatchSize).
+			IDs(ctx)
+		if err != nil {
+			return total, fmt.Errorf("failed to find traffic bodies: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		if err := w.store.client.Traffic.Update().
+			Where(traffic.IDIn(ids...)).
+			ClearRequestBody().
+			ClearResponseBody().
+			Exec(ctx); err != nil {
+			return total, fmt.Errorf("failed to strip traffic bodies: %w", err)
+		}
+		total += len(ids)
+		w.cfg.Metrics.AddRetention(RetentionStripped, len(ids))
+
+		if len(ids) < w.cfg.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// prune archives and deletes traffic started before cutoff.
+func (w *RetentionWorker) prune(ctx context.Context, o *ent.Org, inOrg predicate.Traffic, cutoff time.Time) (archived, deleted int, err error) {
+	var archive *retentionArchive
+	defer func() {
+		if archive != nil {
+			if cerr := archive.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	}()
+
+	for {
+		ids, err := w.store.client.Traffic.Query().
+			Where(inOrg, traffic.StartedAtLT(cutoff)).
+			Order(ent.Asc(traffic.FieldID)).
+			Limit(w.cfg.BatchSize).
+			IDs(ctx)
+		if err != nil {
+			return archived, deleted, fmt.Errorf("failed to find expired traffic: %w", err)
+		}
+		if len(ids) == 0 {
+			return archived, deleted, nil
+		}
+
+		if w.cfg.ArchiveDir != "" {
+			if archive == nil {
+				archive, err = newRetentionArchive(w.cfg.ArchiveDir, o.Slug, w.now())
+				if err != nil {
+					return archived, deleted, err
+				}
+			}
+			rows, err := w.store.client.Traffic.Query().
+				Where(traffic.IDIn(ids...)).
+				Order(ent.Asc(traffic.FieldID)).
+				All(ctx)
+			if err != nil {
+				return archived, deleted, fmt.Errorf("failed to read expired traffic: %w", err)
+			}
+			if err := archive.Write(rows); err != nil {
+				return archived, deleted, err
+			}
+			archived += len(rows)
+			w.cfg.Metrics.AddRetention(RetentionArchived, len(rows))
+		}
+
+		n, err := w.store.client.Traffic.Delete().
+			Where(traffic.IDIn(ids...)).
+			Exec(ctx)
+		if err != nil {
+			return archived, deleted, fmt.Errorf("failed to delete expired traffic: %w", err)
+		}
+		deleted += n
+		w.cfg.Metrics.AddRetention(RetentionDeleted, n)
+
+		if len(ids) < w.cfg.BatchSize {
+			return archived, deleted, nil
+		}
+	}
+}
+
+// retentionArchive writes expired traffic to an NDJSON file.
+type retentionArchive struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+// newRetentionArchive creates traffic-<org>-<time>.ndjson in dir.
+func newRetentionArchive(dir, orgSlug string, now time.Time) (*retentionArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	name := fmt.Sprintf("traffic-%s-%s.ndjson", orgSlug, now.UTC().Format("20060102T150405Z"))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	return &retentionArchive{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Write appends rows to the archive and syncs it, so rows are only deleted
+// once they are on disk.
+func (a *retentionArchive) Write(rows []*ent.Traffic) error {
+	for _, row := range rows {
+		if err := a.enc.Encode(row); err != nil {
+			return fmt.Errorf("failed to archive traffic: %w", err)
+		}
+	}
+	if err := a.f.Sync(); err != nil {
+		return fmt.Errorf("failed to archive traffic: %w", err)
+	}
+	return nil
+}
+
+// Close closes the archive file.
+func (a *retentionArchive) Close() error {
+	return a.f.Close()
+}
@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+func retentionRecord(method string, status int, start time.Time, durationMs float64) *capture.Record {
+	return &capture.Record{
+		StartTime:  start,
+		DurationMs: durationMs,
+		Request: capture.RequestRecord{
+			Method:   method,
+			URL:      "https://api.example.com/users",
+			Host:     "api.example.com",
+			Path:     "/users",
+			Scheme:   "https",
+			Body:     map[string]interface{}{"name": "alice"},
+			BodySize: 16,
+		},
+		Response: capture.ResponseRecord{Status: status},
+	}
+}
+
+func TestRetentionWorker(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// The default org keeps traffic for 30 days
+	now := time.Now().UTC()
+	expired := now.AddDate(0, 0, -40).Truncate(time.Hour).Add(10 * time.Minute)
+	recs := []*capture.Record{
+		retentionRecord("GET", 200, expired, 40),
+		retentionRecord("POST", 500, expired.Add(time.Minute), 120),
+		retentionRecord("GET", 200, now.Add(-48*time.Hour), 8),
+		retentionRecord("GET", 200, now, 3),
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	archiveDir := t.TempDir()
+	worker := NewRetentionWorker(store, &RetentionConfig{
+		BatchSize:     1,
+		BodyRetention: 24 * time.Hour,
+		ArchiveDir:    archiveDir,
+	})
+
+	result, err := worker.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RetentionResult{RolledUp: 3, Stripped: 1, Archived: 2, Deleted: 2}
+	if *result != want {
+		t.Errorf("expected %+v, got %+v", want, *result)
+	}
+
+	if n, _ := store.Count(ctx, nil); n != 2 {
+		t.Errorf("expected 2 records to remain, got %d", n)
+	}
+
+	remaining, err := store.Query(ctx, &TrafficFilter{OrderBy: "started_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped, err := store.GetByID(ctx, remaining[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripped.RequestBody != "" || stripped.RequestBodySize != 16 {
+		t.Errorf("expected the body to be stripped and its size kept, got %q (%d)", stripped.RequestBody, stripped.RequestBodySize)
+	}
+	kept, err := store.GetByID(ctx, remaining[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.RequestBody == "" {
+		t.Error("expected the recent body to be kept")
+	}
+
+	files, err := filepath.Glob(filepath.Join(archiveDir, "traffic-default-*.ndjson"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one archive file, got %v (%v)", files, err)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 archived records, got %d", lines)
+	}
+
+	// Statistics still cover the deleted traffic through the rollups
+	stats, err := store.Stats(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalRequests != 4 || stats.TotalErrors != 1 {
+		t.Errorf("expected 4 requests and 1 error, got %d and %d", stats.TotalRequests, stats.TotalErrors)
+	}
+	if stats.RequestsByMethod["GET"] != 3 || stats.RequestsByStatus[500] != 1 {
+		t.Errorf("unexpected breakdown: %v %v", stats.RequestsByMethod, stats.RequestsByStatus)
+	}
+	if math.Abs(stats.AvgDurationMs-42.75) > 1e-9 {
+		t.Errorf("expected average 42.75ms, got %v", stats.AvgDurationMs)
+	}
+	if len(stats.Hosts) != 1 || stats.Hosts[0].Requests != 4 {
+		t.Errorf("unexpected host stats: %+v", stats.Hosts)
+	}
+
+	recent, err := store.Stats(ctx, &TrafficFilter{StartTime: now.Add(-72 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent.TotalRequests != 2 {
+		t.Errorf("expected 2 recent requests, got %d", recent.TotalRequests)
+	}
+
+	// A second run has nothing left to do
+	result, err = worker.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (RetentionResult{}) {
+		t.Errorf("expected an empty second run, got %+v", *result)
+	}
+}
+
+func TestDurationSummaryQuantile(t *testing.T) {
+	var d durationSummary
+	d.merge(&durationSummary{
+		requests:  4,
+		maxMs:     80,
+		histogram: []int64{0, 0, 0, 2, 0, 0, 2}, // two in [5,10), two in [50,100)
+	})
+
+	if got := d.quantile(0.5); got != 10 {
+		t.Errorf("expected p50 10, got %v", got)
+	}
+	// The longest duration bounds the top bucket
+	if got := d.quantile(1); got != 80 {
+		t.Errorf("expected p100 80, got %v", got)
+	}
+	if got := (&durationSummary{}).quantile(0.5); got != 0 {
+		t.Errorf("expected 0 without data, got %v", got)
+	}
+}
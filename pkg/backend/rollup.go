@@ -0,0 +1,442 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/ui/ent"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
+)
+
+// RollupDurationBounds are the upper bounds in milliseconds of the duration
+// histogram buckets kept in traffic rollups. A final bucket counts requests
+// slower than the last bound.
+var RollupDurationBounds = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// aggregateKey identifies the traffic summarized by an aggregate.
+type aggregateKey struct {
+	proxyID    int
+	host       string
+	method     string
+	statusCode int
+}
+
+// durationSummary accumulates request counts and durations.
+type durationSummary struct {
+	requests  int64
+	errors    int64
+	sumMs     float64
+	maxMs     float64
+	histogram []int64
+}
+
+// trafficAggregate summarizes the traffic of one proxy to one host by
+// method and status code.
+type trafficAggregate struct {
+	aggregateKey
+	durationSummary
+}
+
+// merge adds the counts and durations of o.
+func (d *durationSummary) merge(o *durationSummary) {
+	if d.histogram == nil {
+		d.histogram = make([]int64, len(RollupDurationBounds)+1)
+	}
+	d.requests += o.requests
+	d.errors += o.errors
+	d.sumMs += o.sumMs
+	if o.maxMs > d.maxMs {
+		d.maxMs = o.maxMs
+	}
+	for i, n := range o.histogram {
+		if i < len(d.histogram) {
+			d.histogram[i] += n
+		}
+	}
+}
+
+// avg returns the mean duration.
+func (d *durationSummary) avg() float64 {
+	if d.requests == 0 {
+		return 0
+	}
+	return d.sumMs / float64(d.requests)
+}
+
+// quantile estimates the q-quantile duration by interpolating within the
+// histogram bucket that holds it. The longest duration bounds the last
+// bucket.
+func (d *durationSummary) quantile(q float64) float64 {
+	var total int64
+	for _, n := range d.histogram {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var seen int64
+	for i, n := range d.histogram {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+
+		lower := 0.0
+		if i > 0 {
+			lower = RollupDurationBounds[i-1]
+		}
+		upper := d.maxMs
+		if i < len(RollupDurationBounds) && RollupDurationBounds[i] < upper {
+			upper = RollupDurationBounds[i]
+		}
+		if upper < lower {
+			return upper
+		}
+		return lower + (upper-lower)*(rank-float64(seen))/float64(n)
+	}
+	return d.maxMs
+}
+
+// durationBucketSQL returns the expression selecting the histogram bucket of
+// a duration column.
+func durationBucketSQL(column string) string {
+	var b strings.Builder
+	b.WriteString("(CASE")
+	for i, bound := range RollupDurationBounds {
+		fmt.Fprintf(&b, " WHEN %s < %g THEN %d", column, bound, i)
+	}
+	fmt.Fprintf(&b, " ELSE %d END)", len(RollupDurationBounds))
+	return b.String()
+}
+
+// aggregateTraffic groups the traffic matching preds by proxy, host, method
+// and status code. The database does the grouping, so the cost does not
+// depend on how much traffic matches.
+func (s *DatabaseTrafficStore) aggregateTraffic(ctx context.Context, preds ...predicate.Traffic) ([]*trafficAggregate, error) {
+	b := entsql.Dialect(s.drv.Dialect())
+	t := b.Table(traffic.Table)
+	duration := t.C(traffic.FieldDurationMs)
+	bucket := durationBucketSQL(duration)
+	groups := []string{
+		t.C(traffic.ProxyColumn),
+		t.C(traffic.FieldHost),
+		t.C(traffic.FieldMethod),
+		t.C(traffic.FieldStatusCode),
+		bucket,
+	}
+
+	selector := b.Select(append(groups,
+		entsql.Count("*"),
+		entsql.Sum(duration),
+		entsql.Max(duration),
+	)...).From(t).GroupBy(groups...)
+	for _, p := range preds {
+		p(selector)
+	}
+
+	query, args := selector.Query()
+	rows := &entsql.Rows{}
+	if err := s.drv.Query(ctx, query, args, rows); err != nil {
+		return nil, fmt.Errorf("failed to aggregate traffic: %w", err)
+	}
+	defer rows.Close()
+
+	byKey := make(map[aggregateKey]*trafficAggregate)
+	var result []*trafficAggregate
+	for rows.Next() {
+		var (
+			proxyID      sql.NullInt64
+			key          aggregateKey
+			index, count int64
+			sumMs, maxMs float64
+		)
+		if err := rows.Scan(&proxyID, &key.host, &key.method, &key.statusCode, &index, &count, &sumMs, &maxMs); err != nil {
+			return nil, fmt.Errorf("failed to aggregate traffic: %w", err)
+		}
+		key.proxyID = int(proxyID.Int64)
+
+		agg, ok := byKey[key]
+		if !ok {
+			agg = &trafficAggregate{aggregateKey: key}
+			agg.histogram = make([]int64, len(RollupDurationBounds)+1)
+			byKey[key] = agg
+			result = append(result, agg)
+		}
+		agg.requests += count
+		if key.statusCode >= 400 {
+			agg.errors += count
+		}
+		agg.sumMs += sumMs
+		if maxMs > agg.maxMs {
+			agg.maxMs = maxMs
+		}
+		if index >= 0 && int(index) < len(agg.histogram) {
+			agg.histogram[index] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate traffic: %w", err)
+	}
+
+	return result, nil
+}
+
+// rollupWatermark returns the end of the last rolled up hour, or the zero
+// time if nothing has been rolled up. Hours without rollups before the
+// watermark had no traffic.
+func (s *DatabaseTrafficStore) rollupWatermark(ctx context.Context) (time.Time, error) {
+	last, err := s.client.TrafficRollup.Query().
+		Order(ent.Desc(trafficrollup.FieldBucket)).
+		First(ctx)
+	if ent.IsNotFound(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query rollups: %w", err)
+	}
+	return last.Bucket.UTC().Add(time.Hour), nil
+}
+
+// rollupTraffic rolls up each hour of traffic after the watermark that ends
+// by until, skipping hours without traffic. It returns the number of rollup
+// rows written.
+func (s *DatabaseTrafficStore) rollupTraffic(ctx context.Context, until time.Time) (int, error) {
+	next, err := s.rollupWatermark(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		query := s.client.Traffic.Query().Order(ent.Asc(traffic.FieldStartedAt))
+		if !next.IsZero() {
+			query = query.Where(traffic.StartedAtGTE(next))
+		}
+		first, err := query.First(ctx)
+		if ent.IsNotFound(err) {
+			return total, nil
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to find traffic to roll up: %w", err)
+		}
+
+		hour := first.StartedAt.UTC().Truncate(time.Hour)
+		if hour.Add(time.Hour).After(until) {
+			return total, nil
+		}
+
+		n, err := s.rollupHour(ctx, hour)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		next = hour.Add(time.Hour)
+	}
+}
+
+// rollupHour replaces the rollups for the hour starting at hour.
+func (s *DatabaseTrafficStore) rollupHour(ctx context.Context, hour time.Time) (int, error) {
+	aggs, err := s.aggregateTraffic(ctx,
+		traffic.StartedAtGTE(hour),
+		traffic.StartedAtLT(hour.Add(time.Hour)),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if _, err := tx.TrafficRollup.Delete().Where(trafficrollup.Bucket(hour)).Exec(ctx); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to replace rollups: %w", err)
+	}
+
+	builders := make([]*ent.TrafficRollupCreate, 0, len(aggs))
+	for _, agg := range aggs {
+		if agg.proxyID == 0 {
+			continue
+		}
+		builders = append(builders, tx.TrafficRollup.Create().
+			SetBucket(hour).
+			SetHost(agg.host).
+			SetMethod(agg.method).
+			SetStatusCode(agg.statusCode).
+			SetRequests(agg.requests).
+			SetDurationSumMs(agg.sumMs).
+			SetDurationMaxMs(agg.maxMs).
+			SetDurationHistogram(agg.histogram).
+			SetProxyID(agg.proxyID))
+	}
+	if len(builders) > 0 {
+		if _, err := tx.TrafficRollup.CreateBulk(builders...).Save(ctx); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("failed to store rollups: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rollups: %w", err)
+	}
+	return len(builders), nil
+}
+
+// rollupRange returns the whole hours of the filter's time range that have
+// been rolled up. Rollups only keep hosts, methods and status codes, so
+// filters on anything else always read the traffic table.
+func (s *DatabaseTrafficStore) rollupRange(ctx context.Context, filter *TrafficFilter) (from, to time.Time, ok bool, err error) {
+	if filter != nil && (len(filter.Paths) > 0 || len(filter.Methods) > 0 ||
+		len(filter.StatusCodes) > 0 || filter.MinStatus > 0 || filter.MaxStatus > 0 ||
+		len(filter.RecordTypes) > 0 || filter.ConnectionID != "" || filter.User != "" ||
+		len(filter.Headers) > 0 || filter.BodyContains != "" || len(filter.BodyFields) > 0 ||
+		filter.MinDuration > 0 || filter.MaxDuration > 0 || len(filter.Tags) > 0 || filter.ClientIP != "" ||
+		filter.Query != nil) {
+		return from, to, false, nil
+	}
+
+	to, err = s.rollupWatermark(ctx)
+	if err != nil || to.IsZero() {
+		return from, to, false, err
+	}
+
+	if filter != nil {
+		if !filter.StartTime.IsZero() {
+			from = filter.StartTime.UTC().Truncate(time.Hour)
+			if from.Before(filter.StartTime) {
+				from = from.Add(time.Hour)
+			}
+		}
+		if !filter.EndTime.IsZero() {
+			if end := filter.EndTime.UTC().Truncate(time.Hour); end.Before(to) {
+				to = end
+			}
+		}
+	}
+
+	return from, to, from.Before(to), nil
+}
+
+// Stats returns aggregate statistics for traffic matching the filter.
+// Hours that have been rolled up are read from the rollup table, so the
+// statistics cover traffic that has since been pruned and long ranges stay
+// cheap. Percentiles are estimated from duration histograms.
+func (s *DatabaseTrafficStore) Stats(ctx context.Context, filter *TrafficFilter) (*TrafficStats, error) {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, fmt.Errorf("store is closed")
+	}
+	s.mu.RUnlock()
+
+	var aggs []*trafficAggregate
+	preds, err := s.trafficPredicates(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, ok, err := s.rollupRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		query := s.client.TrafficRollup.Query().
+			Where(trafficrollup.BucketGTE(from), trafficrollup.BucketLT(to))
+		if filter != nil && len(filter.Hosts) > 0 {
+			query = query.Where(trafficrollup.HostIn(filter.Hosts...))
+		}
+		rollups, err := query.All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query rollups: %w", err)
+		}
+		for _, r := range rollups {
+			agg := &trafficAggregate{
+				aggregateKey: aggregateKey{host: r.Host, method: r.Method, statusCode: r.StatusCode},
+				durationSummary: durationSummary{
+					requests:  r.Requests,
+					sumMs:     r.DurationSumMs,
+					maxMs:     r.DurationMaxMs,
+					histogram: r.DurationHistogram,
+				},
+			}
+			if r.StatusCode >= 400 {
+				agg.errors = r.Requests
+			}
+			aggs = append(aggs, agg)
+		}
+		preds = append(preds, traffic.Or(traffic.StartedAtLT(from), traffic.StartedAtGTE(to)))
+	}
+
+	raw, err := s.aggregateTraffic(ctx, preds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateStats(append(aggs, raw...)), nil
+}
+
+// aggregateStats combines aggregates into TrafficStats.
+func aggregateStats(aggs []*trafficAggregate) *TrafficStats {
+	var total durationSummary
+	byMethod := make(map[string]int64)
+	byStatus := make(map[int]int64)
+	byHost := make(map[string]*durationSummary)
+
+	for _, agg := range aggs {
+		total.merge(&agg.durationSummary)
+		byMethod[agg.method] += agg.requests
+		byStatus[agg.statusCode] += agg.requests
+
+		host, ok := byHost[agg.host]
+		if !ok {
+			host = &durationSummary{}
+			byHost[agg.host] = host
+		}
+		host.merge(&agg.durationSummary)
+	}
+
+	stats := &TrafficStats{
+		TotalRequests:    total.requests,
+		TotalErrors:      total.errors,
+		AvgDurationMs:    total.avg(),
+		P50DurationMs:    total.quantile(0.5),
+		P95DurationMs:    total.quantile(0.95),
+		P99DurationMs:    total.quantile(0.99),
+		UniqueHosts:      int64(len(byHost)),
+		RequestsByMethod: byMethod,
+		RequestsByStatus: byStatus,
+	}
+
+	for name, host := range byHost {
+		stats.Hosts = append(stats.Hosts, HostStats{
+			Host:          name,
+			Requests:      host.requests,
+			Errors:        host.errors,
+			AvgDurationMs: host.avg(),
+			P50DurationMs: host.quantile(0.5),
+			P95DurationMs: host.quantile(0.95),
+			P99DurationMs: host.quantile(0.99),
+		})
+	}
+	sort.Slice(stats.Hosts, func(i, j int) bool {
+		a, b := stats.Hosts[i], stats.Hosts[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Host < b.Host
+	})
+	if len(stats.Hosts) > hostStatsLimit {
+		stats.Hosts = stats.Hosts[:hostStatsLimit]
+	}
+
+	return stats
+}
@@ -0,0 +1,462 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/traffic"
+)
+
+// HeaderMatch matches a captured request or response header.
+type HeaderMatch struct {
+	// Name is the header name (case-insensitive).
+	Name string
+
+	// Value is text the header value must contain (case-insensitive).
+	// Empty matches any value.
+	Value string
+
+	// Response matches response headers instead of request headers.
+	Response bool
+}
+
+// BodyFieldMatch matches a field of a JSON request or response body.
+type BodyFieldMatch struct {
+	// Path locates the field, e.g. $.order.id or items[0].sku.
+	Path string
+
+	// Value is the expected field value, compared as text (strings without
+	// quotes, numbers and booleans as written). Empty matches any value.
+	Value string
+
+	// Response matches the response body instead of the request body.
+	Response bool
+}
+
+// sqlitePath formats segments as a SQLite JSON path.
+func sqlitePath(segments []query.PathSegment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range segments {
+		if s.Key == "" {
+			fmt.Fprintf(&b, "[%d]", s.Index)
+		} else {
+			b.WriteString(`."` + s.Key + `"`)
+		}
+	}
+	return b.String()
+}
+
+// likeEscaper escapes LIKE wildcards with a backslash.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likeContains returns a LIKE pattern matching values that contain s.
+func likeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// wildcardLike converts a wildcard pattern (* and ?) to a LIKE pattern.
+func wildcardLike(pattern string) string {
+	return strings.NewReplacer("*", "%", "?", "_").Replace(likeEscaper.Replace(pattern))
+}
+
+// wildcardGlob converts a wildcard pattern to a SQLite GLOB pattern, which
+// shares * and ? but treats [ as a character class.
+func wildcardGlob(pattern string) string {
+	return strings.ReplaceAll(pattern, "[", "[[]")
+}
+
+// stringCond writes a condition comparing the SQL expression expr with value:
+// equality, a case-sensitive wildcard match, or a case-insensitive substring.
+func stringCond(b *entsql.Builder, expr string, op query.Op, value string) {
+	postgres := b.Dialect() == dialect.Postgres
+	switch op {
+	case query.OpMatch:
+		if postgres {
+			b.WriteString(expr + " LIKE ").Arg(wildcardLike(value)).WriteString(` ESCAPE '\'`)
+		} else {
+			b.WriteString(expr + " GLOB ").Arg(wildcardGlob(value))
+		}
+	case query.OpContains:
+		like := "LIKE"
+		if postgres {
+			like = "ILIKE"
+		}
+		b.WriteString(expr + " " + like + " ").Arg(likeContains(value)).WriteString(` ESCAPE '\'`)
+	default:
+		b.WriteString(expr + " = ").Arg(value)
+	}
+}
+
+// bodySearchTerms returns the text to search bodies for. Bodies are stored
+// as JSON, so the JSON-escaped form of the text is searched as well.
+func bodySearchTerms(text string) []string {
+	terms := []string{text}
+	if b, err := json.Marshal(text); err == nil {
+		if escaped := string(b[1 : len(b)-1]); escaped != text {
+			terms = append(terms, escaped)
+		}
+	}
+	return terms
+}
+
+// searchPredicates converts the header, body, duration and metadata criteria
+// of the filter into Traffic predicates.
+func (s *DatabaseTrafficStore) searchPredicates(filter *TrafficFilter) ([]predicate.Traffic, error) {
+	var preds []predicate.Traffic
+
+	for _, h := range filter.Headers {
+		preds = append(preds, headerPredicate(h))
+	}
+	if filter.BodyContains != "" {
+		preds = append(preds, s.bodyContainsPredicate(filter.BodyContains))
+	}
+	for _, f := range filter.BodyFields {
+		p, err := bodyFieldPredicate(f)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+
+	if filter.MinDuration > 0 {
+		preds = append(preds, traffic.DurationMsGTE(float64(filter.MinDuration.Microseconds())/1000))
+	}
+	if filter.MaxDuration > 0 {
+		preds = append(preds, traffic.DurationMsLTE(float64(filter.MaxDuration.Microseconds())/1000))
+	}
+	for _, tag := range filter.Tags {
+		preds = append(preds, tagPredicate(tag))
+	}
+	if filter.ClientIP != "" {
+		preds = append(preds, traffic.ClientIP(filter.ClientIP))
+	}
+
+	return preds, nil
+}
+
+// headerPredicate matches a header in the JSON header columns, which map
+// lowercase names to lists of values.
+func headerPredicate(h HeaderMatch) predicate.Traffic {
+	if h.Value == "" {
+		return headerValuePredicate(h.Response, h.Name, query.OpExists, "")
+	}
+	return headerValuePredicate(h.Response, h.Name, query.OpContains, h.Value)
+}
+
+// headerValuePredicate matches records with a header present (OpExists) or
+// having a value that compares with value as stringCond does.
+func headerValuePredicate(response bool, name string, op query.Op, value string) predicate.Traffic {
+	column := traffic.FieldRequestHeaders
+	if response {
+		column = traffic.FieldResponseHeaders
+	}
+	name = strings.ToLower(name)
+
+	return func(s *entsql.Selector) {
+		c := s.C(column)
+		s.Where(entsql.P(func(b *entsql.Builder) {
+			if b.Dialect() == dialect.Postgres {
+				if op == query.OpExists {
+					b.WriteString("(" + c + " -> ").Arg(name).WriteString(") IS NOT NULL")
+					return
+				}
+				b.WriteString("EXISTS (SELECT 1 FROM jsonb_array_elements_text(" + c + " -> ").Arg(name).
+					WriteString(") AS h(value) WHERE ")
+				stringCond(b, "h.value", op, value)
+				b.WriteString(")")
+				return
+			}
+
+			path := sqlitePath([]query.PathSegment{{Key: name}})
+			if op == query.OpExists {
+				b.WriteString("json_type(" + c + ", ").Arg(path).WriteString(") IS NOT NULL")
+				return
+			}
+			b.WriteString("EXISTS (SELECT 1 FROM json_each(" + c + ", ").Arg(path).WriteString(") WHERE ")
+			stringCond(b, "value", op, value)
+			b.WriteString(")")
+		}))
+	}
+}
+
+// bodyContainsPredicate matches text in the request or response body as a
+// case-insensitive substring. With a search index, SQLite looks up text of
+// three or more characters in the FTS5 trigram index and PostgreSQL matches
+// the indexed body text with ILIKE through a pg_trgm index; otherwise bodies
+// are scanned with LIKE.
+func (s *DatabaseTrafficStore) bodyContainsPredicate(text string) predicate.Traffic {
+	useIndex := s.fullText
+	if s.drv.Dialect() == dialect.SQLite && utf8.RuneCountInString(text) < 3 {
+		useIndex = false
+	}
+	terms := bodySearchTerms(text)
+
+	return func(sel *entsql.Selector) {
+		id := sel.C(traffic.FieldID)
+		reqBody := sel.C(traffic.FieldRequestBody)
+		respBody := sel.C(traffic.FieldResponseBody)
+
+		sel.Where(entsql.P(func(b *entsql.Builder) {
+			postgres := b.Dialect() == dialect.Postgres
+			switch {
+			case useIndex && postgres:
+				b.WriteString("(")
+				for i, t := range terms {
+					if i > 0 {
+						b.WriteString(" OR ")
+					}
+					b.WriteString(sel.C(searchTextColumn) + " ILIKE ").Arg(likeContains(t)).WriteString(` ESCAPE '\'`)
+				}
+				b.WriteString(")")
+			case useIndex:
+				phrases := make([]string, len(terms))
+				for i, t := range terms {
+					phrases[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+				}
+				b.WriteString(id + " IN (SELECT rowid FROM " + sqliteSearchTable + " WHERE " + sqliteSearchTable + " MATCH ").
+					Arg(strings.Join(phrases, " OR ")).WriteString(")")
+			default:
+				cast, like := "CAST(%s AS TEXT)", "LIKE"
+				if postgres {
+					cast, like = "convert_from(%s, 'UTF8')", "ILIKE"
+				}
+				b.WriteString("(")
+				for i, t := range terms {
+					for j, column := range []string{reqBody, respBody} {
+						if i > 0 || j > 0 {
+							b.WriteString(" OR ")
+						}
+						b.WriteString(fmt.Sprintf(cast, column) + " " + like + " ").Arg(likeContains(t)).WriteString(` ESCAPE '\'`)
+					}
+				}
+				b.WriteString(")")
+			}
+		}))
+	}
+}
+
+// bodyFieldPredicate matches a field of a JSON body.
+func bodyFieldPredicate(f BodyFieldMatch) (predicate.Traffic, error) {
+	segments, err := query.ParseJSONPath(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	if f.Value == "" {
+		return jsonFieldPredicate(f.Response, segments, query.OpExists, ""), nil
+	}
+	return jsonFieldPredicate(f.Response, segments, query.OpEq, f.Value), nil
+}
+
+// jsonFieldPredicate matches records whose JSON body has a field at segments
+// (OpExists) or equal to value (OpEq), compared as text: strings without
+// quotes, numbers and booleans as written.
+func jsonFieldPredicate(response bool, segments []query.PathSegment, op query.Op, value string) predicate.Traffic {
+	column := traffic.FieldRequestBody
+	if response {
+		column = traffic.FieldResponseBody
+	}
+
+	return func(s *entsql.Selector) {
+		c := s.C(column)
+		s.Where(entsql.P(func(b *entsql.Builder) {
+			if b.Dialect() == dialect.Postgres {
+				jsonOp := "#>>"
+				if op == query.OpExists {
+					jsonOp = "#>"
+				}
+				b.WriteString("(convert_from(" + c + ", 'UTF8')::jsonb " + jsonOp + " ARRAY[")
+				for i, seg := range segments {
+					if i > 0 {
+						b.Comma()
+					}
+					if seg.Key == "" {
+						b.Arg(strconv.Itoa(seg.Index))
+					} else {
+						b.Arg(seg.Key)
+					}
+				}
+				b.WriteString("]::text[])")
+				if op == query.OpExists {
+					b.WriteString(" IS NOT NULL")
+				} else {
+					b.WriteString(" = ").Arg(value)
+				}
+				return
+			}
+
+			// Booleans are compared as true/false rather than 1/0
+			doc := "CAST(" + c + " AS TEXT)"
+			path := sqlitePath(segments)
+			b.WriteString("(CASE WHEN json_valid(" + doc + ") THEN ")
+			if op == query.OpExists {
+				b.WriteString("json_type(" + doc + ", ").Arg(path).WriteString(") END) IS NOT NULL")
+				return
+			}
+			b.WriteString("CASE json_type(" + doc + ", ").Arg(path).
+				WriteString(") WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(json_extract(" + doc + ", ").Arg(path).
+				WriteString(") AS TEXT) END END) = ").Arg(value)
+		}))
+	}
+}
+
+// tagPredicate matches records carrying tag.
+func tagPredicate(tag string) predicate.Traffic {
+	return func(s *entsql.Selector) {
+		c := s.C(traffic.FieldTags)
+		s.Where(entsql.P(func(b *entsql.Builder) {
+			if b.Dialect() == dialect.Postgres {
+				tagJSON, _ := json.Marshal([]string{tag})
+				b.WriteString(c + " @> ").Arg(string(tagJSON)).WriteString("::jsonb")
+				return
+			}
+			b.WriteString("EXISTS (SELECT 1 FROM json_each(" + c + ") WHERE value = ").Arg(tag).WriteString(")")
+		}))
+	}
+}
+
+// pathPredicate matches request paths equal to any of patterns, where *
+// and ? are wildcards as in the capture filter.
+func pathPredicate(patterns []string) predicate.Traffic {
+	return func(s *entsql.Selector) {
+		c := s.C(traffic.FieldPath)
+		s.Where(entsql.P(func(b *entsql.Builder) {
+			b.WriteString("(")
+			for i, p := range patterns {
+				if i > 0 {
+					b.WriteString(" OR ")
+				}
+				op := query.OpEq
+				if strings.ContainsAny(p, "*?") {
+					op = query.OpMatch
+				}
+				stringCond(b, c, op, p)
+			}
+			b.WriteString(")")
+		}))
+	}
+}
+
+const (
+	// sqliteSearchTable is the FTS5 index over traffic bodies.
+	sqliteSearchTable = "traffic_search"
+
+	// searchTextColumn is the PostgreSQL column holding the decoded text of
+	// traffic bodies, indexed with pg_trgm.
+	searchTextColumn = "search_text"
+)
+
+// sqliteSearchSchema creates the FTS5 body index and the triggers keeping it
+// in step with the traffic table. The trigram tokenizer supports substring
+// matches.
+var sqliteSearchSchema = []string{
+	`CREATE VIRTUAL TABLE traffic_search USING fts5(request_body, response_body, tokenize = 'trigram')`,
+	`CREATE TRIGGER IF NOT EXISTS traffic_search_insert AFTER INSERT ON traffics BEGIN
+		INSERT INTO traffic_search(rowid, request_body, response_body)
+		VALUES (new.id, CAST(new.request_body AS TEXT), CAST(new.response_body AS TEXT));
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS traffic_search_delete AFTER DELETE ON traffics BEGIN
+		DELETE FROM traffic_search WHERE rowid = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS traffic_search_update AFTER UPDATE OF request_body, response_body ON traffics BEGIN
+		DELETE FROM traffic_search WHERE rowid = old.id;
+		INSERT INTO traffic_search(rowid, request_body, response_body)
+		VALUES (new.id, CAST(new.request_body AS TEXT), CAST(new.response_body AS TEXT));
+	END`,
+	`INSERT INTO traffic_search(rowid, request_body, response_body)
+		SELECT id, CAST(request_body AS TEXT), CAST(response_body AS TEXT) FROM traffics`,
+}
+
+// postgresSearchExtension provides the trigram operator classes. Without
+// it, body searches fall back to scanning with ILIKE.
+const postgresSearchExtension = `CREATE EXTENSION IF NOT EXISTS pg_trgm`
+
+// postgresSearchSchema creates the body text column, maintained by a trigger,
+// and its trigram index, which serves ILIKE substring matches. It replaces
+// the earlier tsvector index, which only matched whole words.
+var postgresSearchSchema = []string{
+	`DROP TRIGGER IF EXISTS traffic_search_vector ON traffics`,
+	`DROP FUNCTION IF EXISTS traffic_search_vector()`,
+	`ALTER TABLE traffics DROP COLUMN IF EXISTS search_vector`,
+	`ALTER TABLE traffics ADD COLUMN search_text text`,
+	`CREATE OR REPLACE FUNCTION traffic_search_text() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_text :=
+			coalesce(convert_from(NEW.request_body, 'UTF8'), '') || E'\n' ||
+			coalesce(convert_from(NEW.response_body, 'UTF8'), '');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`CREATE TRIGGER traffic_search_text BEFORE INSERT OR UPDATE OF request_body, response_body ON traffics
+		FOR EACH ROW EXECUTE FUNCTION traffic_search_text()`,
+	`UPDATE traffics SET request_body = request_body WHERE request_body IS NOT NULL OR response_body IS NOT NULL`,
+	`CREATE INDEX traffic_search_text_idx ON traffics USING GIN (search_text gin_trgm_ops)`,
+}
+
+// setupSearchIndex creates the full-text index over bodies on first use. On
+// SQLite builds without FTS5 (the sqlite_fts5 build tag), body searches fall
+// back to scanning with LIKE.
+func (s *DatabaseTrafficStore) setupSearchIndex(ctx context.Context) error {
+	var (
+		exists string
+		schema []string
+	)
+	switch s.drv.Dialect() {
+	case dialect.SQLite:
+		exists = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'traffic_search'`
+		schema = sqliteSearchSchema
+	case dialect.Postgres:
+		exists = `SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'traffics' AND column_name = 'search_text'`
+		schema = postgresSearchSchema
+	default:
+		return nil
+	}
+
+	rows := &entsql.Rows{}
+	if err := s.drv.Query(ctx, exists, []any{}, rows); err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+	n, err := entsql.ScanInt(rows)
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+	if n > 0 {
+		s.fullText = true
+		return nil
+	}
+	// Creating the extension needs the CREATE privilege on the database
+	if s.drv.Dialect() == dialect.Postgres {
+		if err := s.drv.Exec(ctx, postgresSearchExtension, []any{}, nil); err != nil {
+			return nil
+		}
+	}
+
+	tx, err := s.drv.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	for _, stmt := range schema {
+		if err := tx.Exec(ctx, stmt, []any{}, nil); err != nil {
+			_ = tx.Rollback()
+			if s.drv.Dialect() == dialect.SQLite && (strings.Contains(err.Error(), "no such module") || strings.Contains(err.Error(), "no such tokenizer")) {
+				return nil
+			}
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+
+	s.fullText = true
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build sqlite_fts5
+
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// TestDatabaseTrafficStoreSearchFTS5 runs body searches through the FTS5
+// trigram index, which only builds with the sqlite_fts5 tag:
+//
+//	go test -tags sqlite_fts5 ./pkg/backend
+func TestDatabaseTrafficStoreSearchFTS5(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if !store.fullText {
+		t.Fatal("expected the FTS5 search index")
+	}
+
+	now := time.Now()
+	recs := []*capture.Record{
+		{
+			StartTime: now,
+			Request: capture.RequestRecord{
+				Method: "POST",
+				URL:    "https://shop.example.com/orders",
+				Host:   "shop.example.com",
+				Path:   "/orders",
+				Body:   map[string]interface{}{"order": map[string]interface{}{"id": "ORD-12345"}},
+			},
+			Response: capture.ResponseRecord{Status: 201, Body: "<b>created</b>"},
+		},
+		{
+			StartTime: now,
+			Request:   capture.RequestRecord{Method: "GET", URL: "https://shop.example.com/health", Host: "shop.example.com", Path: "/health"},
+			Response:  capture.ResponseRecord{Status: 200, Body: map[string]interface{}{"status": "ok"}},
+		},
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Matches are case-insensitive substrings, as with the LIKE scan
+	tests := []struct {
+		text string
+		want string // path of the single match, empty for none
+	}{
+		{"ord-12345", "/orders"},
+		{"RD-123", "/orders"},
+		{"<b>created", "/orders"},
+		{"ok", "/health"},
+		{"ORD-99999", ""},
+	}
+	for _, tt := range tests {
+		got, err := store.Query(ctx, &TrafficFilter{BodyContains: tt.text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tt.want == "" && len(got) != 0:
+			t.Errorf("%q: expected no match, got %s", tt.text, got[0].Path)
+		case tt.want != "" && (len(got) != 1 || got[0].Path != tt.want):
+			t.Errorf("%q: expected %s, got %d records", tt.text, tt.want, len(got))
+		}
+	}
+}
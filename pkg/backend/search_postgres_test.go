@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/ui/ent/traffic"
+)
+
+// TestDatabaseTrafficStoreSearchPostgres runs body searches through the
+// pg_trgm index. It needs a PostgreSQL database and is skipped otherwise:
+//
+//	OMNIPROXY_TEST_POSTGRES_URL=postgres://localhost/omniproxy_test?sslmode=disable go test ./pkg/backend
+//
+// Records are stored under a unique host, so the database may be shared.
+func TestDatabaseTrafficStoreSearchPostgres(t *testing.T) {
+	dbURL := os.Getenv("OMNIPROXY_TEST_POSTGRES_URL")
+	if dbURL == "" {
+		t.Skip("OMNIPROXY_TEST_POSTGRES_URL not set")
+	}
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: dbURL,
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if !store.fullText {
+		t.Fatal("expected the pg_trgm search index")
+	}
+
+	host := fmt.Sprintf("search-%d.example.com", time.Now().UnixNano())
+	now := time.Now()
+	recs := []*capture.Record{
+		{
+			StartTime: now,
+			Request: capture.RequestRecord{
+				Method: "POST",
+				URL:    "https://" + host + "/orders",
+				Host:   host,
+				Path:   "/orders",
+				Body:   map[string]interface{}{"order": map[string]interface{}{"id": "ord_12345"}},
+			},
+			Response: capture.ResponseRecord{Status: 201, Body: "<b>created</b>"},
+		},
+		{
+			StartTime: now,
+			Request:   capture.RequestRecord{Method: "GET", URL: "https://" + host + "/health", Host: host, Path: "/health"},
+			Response:  capture.ResponseRecord{Status: 200, Body: map[string]interface{}{"status": "ok"}},
+		},
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_, _ = store.Client().Traffic.Delete().Where(traffic.Host(host)).Exec(ctx)
+	}()
+
+	// Matches are case-insensitive substrings, including part of a token
+	tests := []struct {
+		text string
+		want string // path of the single match, empty for none
+	}{
+		{"ord_12", "/orders"},
+		{"RD_1234", "/orders"},
+		{"<b>created", "/orders"},
+		{"ok", "/health"},
+		{"ord%", ""},
+		{"ord_99999", ""},
+	}
+	for _, tt := range tests {
+		got, err := store.Query(ctx, &TrafficFilter{Hosts: []string{host}, BodyContains: tt.text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tt.want == "" && len(got) != 0:
+			t.Errorf("%q: expected no match, got %s", tt.text, got[0].Path)
+		case tt.want != "" && (len(got) != 1 || got[0].Path != tt.want):
+			t.Errorf("%q: expected %s, got %d records", tt.text, tt.want, len(got))
+		}
+	}
+}
+
+func TestBodyContainsPredicatePostgres(t *testing.T) {
+	store := &DatabaseTrafficStore{drv: entsql.OpenDB(dialect.Postgres, nil), fullText: true}
+	sel := entsql.Dialect(dialect.Postgres).Select("*").From(entsql.Table(traffic.Table))
+	store.bodyContainsPredicate(`ord_"12`)(sel)
+
+	query, args := sel.Query()
+	want := `SELECT * FROM "traffics" WHERE ("traffics"."search_text" ILIKE $1 ESCAPE '\' OR "traffics"."search_text" ILIKE $2 ESCAPE '\')`
+	if query != want {
+		t.Errorf("query = %s\nwant %s", query, want)
+	}
+	if len(args) != 2 || args[0] != `%ord\_"12%` || args[1] != `%ord\_\\"12%` {
+		t.Errorf("unexpected args: %q", args)
+	}
+}
@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+func TestDatabaseTrafficStoreSearch(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewDatabaseTrafficStore(ctx, &DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	recs := []*capture.Record{
+		{
+			StartTime:  now,
+			DurationMs: 450,
+			Request: capture.RequestRecord{
+				Method:  "POST",
+				URL:     "https://shop.example.com/orders",
+				Host:    "shop.example.com",
+				Path:    "/orders",
+				Headers: capture.Headers{{Name: "x-request-id", Value: "req-ABC-1"}, {Name: "content-type", Value: "application/json"}},
+				Body: map[string]interface{}{
+					"order": map[string]interface{}{"id": "ORD-12345"},
+					"items": []interface{}{map[string]interface{}{"sku": "tea", "qty": 2}},
+					"paid":  true,
+				},
+			},
+			Response: capture.ResponseRecord{
+				Status:  201,
+				Headers: capture.Headers{{Name: "location", Value: "/orders/ORD-12345"}},
+				Body:    "<b>created</b>",
+			},
+			NetworkProfile: "3g",
+			ClientIP:       "10.0.0.7",
+		},
+		{
+			StartTime:  now,
+			DurationMs: 20,
+			Request: capture.RequestRecord{
+				Method:  "GET",
+				URL:     "https://shop.example.com/health",
+				Host:    "shop.example.com",
+				Path:    "/health",
+				Headers: capture.Headers{{Name: "x-request-id", Value: "req-XYZ-2"}},
+			},
+			Response: capture.ResponseRecord{
+				Status: 200,
+				Body:   map[string]interface{}{"status": "ok", "paid": false},
+			},
+			ClientIP: "10.0.0.8",
+		},
+	}
+	if err := store.StoreBatch(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter TrafficFilter
+		want   string // path of the single match, empty for none
+	}{
+		{"path wildcard", TrafficFilter{Paths: []string{"/ord*"}}, "/orders"},
+		{"path exact or wildcard", TrafficFilter{Paths: []string{"/orders/*", "/healt?"}}, "/health"},
+		{"path no match", TrafficFilter{Paths: []string{"/api/*", "/Orders"}}, ""},
+		{"header present", TrafficFilter{Headers: []HeaderMatch{{Name: "Content-Type"}}}, "/orders"},
+		{"header value", TrafficFilter{Headers: []HeaderMatch{{Name: "X-Request-ID", Value: "xyz"}}}, "/health"},
+		{"response header", TrafficFilter{Headers: []HeaderMatch{{Name: "location", Value: "ORD-12345", Response: true}}}, "/orders"},
+		{"header missing", TrafficFilter{Headers: []HeaderMatch{{Name: "authorization"}}}, ""},
+		{"body text", TrafficFilter{BodyContains: "ord-12345"}, "/orders"},
+		{"short body text", TrafficFilter{BodyContains: "ok"}, "/health"},
+		{"escaped body text", TrafficFilter{BodyContains: "<b>created"}, "/orders"},
+		{"body text missing", TrafficFilter{BodyContains: "ORD-99999"}, ""},
+		{"body field", TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "$.order.id", Value: "ORD-12345"}}}, "/orders"},
+		{"body array field", TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "items[0].qty", Value: "2"}}}, "/orders"},
+		{"body boolean field", TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "paid", Value: "true"}}}, "/orders"},
+		{"response body field", TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "paid", Value: "false", Response: true}}}, "/health"},
+		{"body field present", TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "status", Response: true}}}, "/health"},
+		{"min duration", TrafficFilter{MinDuration: 100 * time.Millisecond}, "/orders"},
+		{"max duration", TrafficFilter{MaxDuration: 100 * time.Millisecond}, "/health"},
+		{"tag", TrafficFilter{Tags: []string{capture.NetworkProfileTag("3g")}}, "/orders"},
+		{"client IP", TrafficFilter{ClientIP: "10.0.0.8"}, "/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			got, err := store.Query(ctx, &filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			count, err := store.Count(ctx, &filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(count) != len(got) {
+				t.Errorf("Count returned %d, Query %d records", count, len(got))
+			}
+
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("expected no match, got %s", got[0].Path)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Path != tt.want {
+				t.Errorf("expected %s, got %d records", tt.want, len(got))
+			}
+		})
+	}
+
+	if _, err := store.Query(ctx, &TrafficFilter{BodyFields: []BodyFieldMatch{{Path: "items[x]"}}}); err == nil {
+		t.Error("expected error for an invalid JSON path")
+	}
+}
+
+func TestSQLitePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "$.order.id", want: `$."order"."id"`},
+		{path: "order.id", want: `$."order"."id"`},
+		{path: "items[0].sku", want: `$."items"[0]."sku"`},
+		{path: "$[2]", want: `$[2]`},
+		{path: "$", wantErr: true},
+		{path: "items[", wantErr: true},
+		{path: "items[-1]", wantErr: true},
+		{path: `a"b`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		segments, err := query.ParseJSONPath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if got := sqlitePath(segments); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.want, got)
+		}
+	}
+}
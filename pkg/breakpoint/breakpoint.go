@@ -0,0 +1,587 @@
+// Package breakpoint pauses proxied requests and responses so they can be
+// inspected and edited before they continue.
+//
+// Breakpoint rules select traffic by host, path and method with the same
+// wildcard semantics as capture.Filter. When a request or response matches,
+// the proxy goroutine handling it parks until a client lists the pending item
+// and resumes it (optionally with edits) or drops it. Items that are not
+// handled within the timeout continue unchanged.
+package breakpoint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Breakpoint phases.
+const (
+	// PhaseRequest pauses a request before it is forwarded upstream
+	PhaseRequest = "request"
+	// PhaseResponse pauses a response before it is returned to the client
+	PhaseResponse = "response"
+)
+
+// EncodingBase64 marks a body that is base64 encoded because it is not valid UTF-8.
+const EncodingBase64 = "base64"
+
+// HeaderBreakpoint is set on responses synthesized for dropped items.
+const HeaderBreakpoint = "X-OmniProxy-Breakpoint"
+
+// Errors returned by the manager.
+var (
+	// ErrNotFound is returned when a rule or pending item does not exist
+	ErrNotFound = errors.New("breakpoint not found")
+	// ErrInvalidEdit is returned when an edit cannot be applied
+	ErrInvalidEdit = errors.New("invalid breakpoint edit")
+)
+
+// Rule selects the traffic to pause. Hosts and paths support wildcards; an
+// empty list matches everything. A rule with neither Request nor Response set
+// pauses requests.
+type Rule struct {
+	// ID identifies the rule (assigned when empty)
+	ID string `json:"id"`
+	// Hosts is a list of hosts to match (supports wildcards)
+	Hosts []string `json:"hosts,omitempty"`
+	// Paths is a list of path patterns to match (supports wildcards)
+	Paths []string `json:"paths,omitempty"`
+	// Methods is a list of HTTP methods to match
+	Methods []string `json:"methods,omitempty"`
+	// Request pauses matching requests before they are forwarded
+	Request bool `json:"request"`
+	// Response pauses responses to matching requests before they are returned
+	Response bool `json:"response"`
+}
+
+// Pending is a paused request or response.
+type Pending struct {
+	// ID identifies the pending item
+	ID string `json:"id"`
+	// RuleID is the rule that paused the item
+	RuleID string `json:"rule_id"`
+	// Phase is request or response
+	Phase string `json:"phase"`
+	// Method is the request method
+	Method string `json:"method"`
+	// URL is the request URL
+	URL string `json:"url"`
+	// Status is the response status code (response phase only)
+	Status int `json:"status,omitempty"`
+	// Headers are the request or response headers
+	Headers http.Header `json:"headers"`
+	// Body is the request or response body
+	Body string `json:"body,omitempty"`
+	// BodyEncoding is base64 when Body is not valid UTF-8
+	BodyEncoding string `json:"body_encoding,omitempty"`
+	// BodyOmitted is set when the body exceeds the size limit and cannot be edited
+	BodyOmitted bool `json:"body_omitted,omitempty"`
+	// CreatedAt is when the item was paused
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is when the item continues automatically
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Edit modifies a pending item before it is resumed. Zero fields are left
+// unchanged.
+type Edit struct {
+	// Method replaces the request method (request phase only)
+	Method string `json:"method,omitempty"`
+	// URL replaces the absolute request URL (request phase only)
+	URL string `json:"url,omitempty"`
+	// Status replaces the response status code (response phase only)
+	Status int `json:"status,omitempty"`
+	// Headers replaces all headers
+	Headers http.Header `json:"headers,omitempty"`
+	// Body replaces the body
+	Body *string `json:"body,omitempty"`
+	// BodyEncoding is base64 when Body is base64 encoded
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// Config holds breakpoint configuration.
+type Config struct {
+	// Timeout is how long an item stays paused before it continues unchanged
+	Timeout time.Duration
+	// MaxBodySize is the largest body exposed for editing
+	MaxBodySize int64
+}
+
+// DefaultConfig returns default breakpoint configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:     60 * time.Second,
+		MaxBodySize: 1024 * 1024, // 1MB
+	}
+}
+
+// compiledRule is a rule with its filter compiled.
+type compiledRule struct {
+	Rule
+	filter *capture.Filter
+}
+
+// decision is the outcome delivered to a paused goroutine.
+type decision struct {
+	drop bool
+	edit *Edit
+	body []byte
+}
+
+// pendingItem is a paused item and the channel its goroutine waits on.
+type pendingItem struct {
+	*Pending
+	decided chan decision
+}
+
+// Manager holds breakpoint rules and paused items.
+type Manager struct {
+	config *Config
+
+	mu          sync.Mutex
+	rules       []*compiledRule
+	pending     map[string]*pendingItem
+	nextRule    int
+	nextPending int
+}
+
+// New creates a breakpoint manager.
+func New(cfg *Config) *Manager {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultConfig().MaxBodySize
+	}
+	return &Manager{
+		config:  cfg,
+		pending: make(map[string]*pendingItem),
+	}
+}
+
+// AddRule adds a breakpoint rule and returns it with its ID assigned.
+func (m *Manager) AddRule(rule Rule) (Rule, error) {
+	if !rule.Request && !rule.Response {
+		rule.Request = true
+	}
+
+	filter := capture.NewFilter()
+	filter.IncludeHosts = rule.Hosts
+	filter.IncludePaths = rule.Paths
+	filter.IncludeMethods = rule.Methods
+	if err := filter.Compile(); err != nil {
+		return rule, fmt.Errorf("invalid breakpoint rule: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rule.ID == "" {
+		m.nextRule++
+		rule.ID = "bp-" + strconv.Itoa(m.nextRule)
+	}
+	for _, r := range m.rules {
+		if r.ID == rule.ID {
+			return rule, fmt.Errorf("breakpoint rule %q already exists", rule.ID)
+		}
+	}
+	m.rules = append(m.rules, &compiledRule{Rule: rule, filter: filter})
+	return rule, nil
+}
+
+// RemoveRule removes a breakpoint rule. Items it already paused stay pending.
+func (m *Manager) RemoveRule(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, r := range m.rules {
+		if r.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Rules returns the breakpoint rules in the order they were added.
+func (m *Manager) Rules() []Rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rules := make([]Rule, len(m.rules))
+	for i, r := range m.rules {
+		rules[i] = r.Rule
+	}
+	return rules
+}
+
+// List returns the pending items, oldest first.
+func (m *Manager) List() []*Pending {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*Pending, 0, len(m.pending))
+	for _, item := range m.pending {
+		list = append(list, item.Pending)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Get returns a pending item.
+func (m *Manager) Get(id string) (*Pending, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.pending[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return item.Pending, nil
+}
+
+// Resume continues a pending item, applying edit if it is not nil.
+func (m *Manager) Resume(id string, edit *Edit) error {
+	d := decision{edit: edit}
+	if edit != nil {
+		m.mu.Lock()
+		item, ok := m.pending[id]
+		m.mu.Unlock()
+		if !ok {
+			return ErrNotFound
+		}
+		body, err := validateEdit(item.Pending, edit)
+		if err != nil {
+			return err
+		}
+		d.body = body
+	}
+	return m.decide(id, d)
+}
+
+// Drop discards a pending item. The client receives a 502 response.
+func (m *Manager) Drop(id string) error {
+	return m.decide(id, decision{drop: true})
+}
+
+// decide removes a pending item and hands the decision to its goroutine.
+func (m *Manager) decide(id string, d decision) error {
+	m.mu.Lock()
+	item, ok := m.pending[id]
+	if ok {
+		delete(m.pending, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrNotFound
+	}
+	item.decided <- d // buffered; the waiter is the only receiver
+	return nil
+}
+
+// match returns the first rule matching req for the phase.
+func (m *Manager) match(req *http.Request, phase string) *compiledRule {
+	host := req.URL.Hostname()
+	if host == "" {
+		host = stripPort(req.Host)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range m.rules {
+		if phase == PhaseRequest && !r.Request || phase == PhaseResponse && !r.Response {
+			continue
+		}
+		if r.filter.MatchRequest(host, req.URL.Path, req.Method) {
+			return r
+		}
+	}
+	return nil
+}
+
+// PauseRequest parks the calling goroutine if req matches a request
+// breakpoint. It returns the (possibly edited) request, or a response to send
+// instead when the request is dropped.
+func (m *Manager) PauseRequest(req *http.Request) (*http.Request, *http.Response) {
+	rule := m.match(req, PhaseRequest)
+	if rule == nil {
+		return req, nil
+	}
+
+	body, rest, err := m.peekBody(req.Body)
+	if err != nil {
+		return req, nil
+	}
+	req.Body = rest
+
+	p := &Pending{
+		RuleID:  rule.ID,
+		Phase:   PhaseRequest,
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header.Clone(),
+	}
+	setPendingBody(p, body)
+
+	d, ok := m.wait(req, p)
+	if !ok {
+		return req, nil
+	}
+	if d.drop {
+		return req, droppedResponse(req)
+	}
+	if d.edit != nil {
+		applyRequestEdit(req, d.edit, d.body)
+	}
+	return req, nil
+}
+
+// PauseResponse parks the calling goroutine if the request that produced
+// resp matches a response breakpoint. It returns the (possibly edited)
+// response.
+func (m *Manager) PauseResponse(req *http.Request, resp *http.Response) *http.Response {
+	if req == nil || resp == nil {
+		return resp
+	}
+	// Upgraded connections have no body to edit, and dropped items stay dropped
+	if resp.StatusCode == http.StatusSwitchingProtocols || resp.Header.Get(HeaderBreakpoint) != "" {
+		return resp
+	}
+	rule := m.match(req, PhaseResponse)
+	if rule == nil {
+		return resp
+	}
+
+	body, rest, err := m.peekBody(resp.Body)
+	if err != nil {
+		return resp
+	}
+	resp.Body = rest
+
+	p := &Pending{
+		RuleID:  rule.ID,
+		Phase:   PhaseResponse,
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Status:  resp.StatusCode,
+		Headers: resp.Header.Clone(),
+	}
+	setPendingBody(p, body)
+
+	d, ok := m.wait(req, p)
+	if !ok {
+		return resp
+	}
+	if d.drop {
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		return droppedResponse(req)
+	}
+	if d.edit != nil {
+		applyResponseEdit(resp, d.edit, d.body)
+	}
+	return resp
+}
+
+// wait registers p and blocks until it is decided, times out or the client
+// goes away. It reports false when the item continues unchanged.
+func (m *Manager) wait(req *http.Request, p *Pending) (decision, bool) {
+	item := &pendingItem{Pending: p, decided: make(chan decision, 1)}
+
+	m.mu.Lock()
+	m.nextPending++
+	p.ID = strconv.Itoa(m.nextPending)
+	p.CreatedAt = time.Now()
+	p.ExpiresAt = p.CreatedAt.Add(m.config.Timeout)
+	m.pending[p.ID] = item
+	m.mu.Unlock()
+
+	timer := time.NewTimer(m.config.Timeout)
+	defer timer.Stop()
+
+	select {
+	case d := <-item.decided:
+		return d, true
+	case <-timer.C:
+	case <-req.Context().Done():
+	}
+
+	m.mu.Lock()
+	_, stillPending := m.pending[p.ID]
+	delete(m.pending, p.ID)
+	m.mu.Unlock()
+
+	// A decision may have raced with the timeout
+	if !stillPending {
+		return <-item.decided, true
+	}
+	return decision{}, false
+}
+
+// peekBody reads up to MaxBodySize bytes of body. It returns the bytes read
+// (nil if the body is too large to expose) and a reader that yields the full
+// original body.
+func (m *Manager) peekBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return []byte{}, body, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, m.config.MaxBodySize+1))
+	if err != nil {
+		body.Close()
+		return nil, nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(data)) > m.config.MaxBodySize {
+		return nil, readCloser{io.MultiReader(bytes.NewReader(data), body), body}, nil
+	}
+	body.Close()
+	return data, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// readCloser combines a reader with the closer of the underlying body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func setPendingBody(p *Pending, body []byte) {
+	switch {
+	case body == nil:
+		p.BodyOmitted = true
+	case utf8.Valid(body):
+		p.Body = string(body)
+	default:
+		p.Body = base64.StdEncoding.EncodeToString(body)
+		p.BodyEncoding = EncodingBase64
+	}
+}
+
+// validateEdit checks that edit applies to p and returns the decoded body.
+func validateEdit(p *Pending, edit *Edit) ([]byte, error) {
+	if p.Phase == PhaseRequest && edit.Status != 0 {
+		return nil, fmt.Errorf("%w: status can only be set on responses", ErrInvalidEdit)
+	}
+	if p.Phase == PhaseResponse && (edit.Method != "" || edit.URL != "") {
+		return nil, fmt.Errorf("%w: method and url can only be set on requests", ErrInvalidEdit)
+	}
+	if edit.Status != 0 && (edit.Status < 100 || edit.Status > 999) {
+		return nil, fmt.Errorf("%w: bad status %d", ErrInvalidEdit, edit.Status)
+	}
+	if edit.URL != "" {
+		u, err := url.Parse(edit.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%w: url must be absolute: %s", ErrInvalidEdit, edit.URL)
+		}
+	}
+	if edit.Body == nil {
+		return nil, nil
+	}
+	switch edit.BodyEncoding {
+	case "":
+		return []byte(*edit.Body), nil
+	case EncodingBase64:
+		body, err := base64.StdEncoding.DecodeString(*edit.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad base64 body: %v", ErrInvalidEdit, err)
+		}
+		return body, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown body encoding %q", ErrInvalidEdit, edit.BodyEncoding)
+	}
+}
+
+func applyRequestEdit(req *http.Request, edit *Edit, body []byte) {
+	if edit.Method != "" {
+		req.Method = strings.ToUpper(edit.Method)
+	}
+	if edit.URL != "" {
+		if u, err := url.Parse(edit.URL); err == nil {
+			req.URL = u
+			req.Host = u.Host
+		}
+	}
+	if edit.Headers != nil {
+		req.Header = edit.Headers.Clone()
+	}
+	if edit.Body != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		req.TransferEncoding = nil
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+}
+
+func applyResponseEdit(resp *http.Response, edit *Edit, body []byte) {
+	if edit.Status != 0 {
+		resp.StatusCode = edit.Status
+		resp.Status = strconv.Itoa(edit.Status) + " " + http.StatusText(edit.Status)
+	}
+	if edit.Headers != nil {
+		resp.Header = edit.Headers.Clone()
+	}
+	if edit.Body != nil {
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		if resp.Header == nil {
+			resp.Header = make(http.Header)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		resp.TransferEncoding = nil
+	}
+}
+
+// droppedResponse builds the response sent for a dropped item.
+func droppedResponse(req *http.Request) *http.Response {
+	body := "Dropped at breakpoint\n"
+	resp := &http.Response{
+		StatusCode:    http.StatusBadGateway,
+		Status:        strconv.Itoa(http.StatusBadGateway) + " " + http.StatusText(http.StatusBadGateway),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Header.Set(HeaderBreakpoint, "dropped")
+	return resp
+}
+
+// stripPort removes a port from host, if present.
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,207 @@
+package breakpoint
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitPending polls until n items are pending.
+func waitPending(t *testing.T, m *Manager, n int) []*Pending {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if list := m.List(); len(list) == n {
+			return list
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d pending items, got %d", n, len(m.List()))
+	return nil
+}
+
+func TestRules(t *testing.T) {
+	m := New(nil)
+
+	rule, err := m.AddRule(Rule{Hosts: []string{"*.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.ID != "bp-1" || !rule.Request || rule.Response {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	if _, err := m.AddRule(Rule{ID: "bp-1"}); err == nil {
+		t.Error("expected duplicate ID error")
+	}
+
+	if len(m.Rules()) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(m.Rules()))
+	}
+	if err := m.RemoveRule("bp-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RemoveRule("bp-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Without rules nothing is paused
+	req := httptest.NewRequest("GET", "http://api.example.com/", nil)
+	if got, resp := m.PauseRequest(req); got != req || resp != nil {
+		t.Error("expected request to pass through")
+	}
+}
+
+func TestPauseRequestEdit(t *testing.T) {
+	m := New(nil)
+	if _, err := m.AddRule(Rule{Methods: []string{"POST"}, Paths: []string{"/api/*"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "http://api.example.com/api/users", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("X-Original", "1")
+
+	type result struct {
+		req  *http.Request
+		resp *http.Response
+	}
+	done := make(chan result)
+	go func() {
+		r, resp := m.PauseRequest(req)
+		done <- result{r, resp}
+	}()
+
+	p := waitPending(t, m, 1)[0]
+	if p.Phase != PhaseRequest || p.Method != "POST" || p.Body != `{"name":"a"}` || p.Headers.Get("X-Original") != "1" {
+		t.Errorf("unexpected pending item: %+v", p)
+	}
+
+	// Edits are validated against the phase
+	if err := m.Resume(p.ID, &Edit{Status: 500}); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected invalid edit, got %v", err)
+	}
+	if err := m.Resume(p.ID, &Edit{URL: "/relative"}); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected invalid edit, got %v", err)
+	}
+
+	body := `{"name":"edited"}`
+	err := m.Resume(p.ID, &Edit{
+		Method:  "put",
+		URL:     "http://localhost:3000/api/users/1",
+		Headers: http.Header{"X-Edited": {"1"}},
+		Body:    &body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := <-done
+	if res.resp != nil {
+		t.Fatal("expected request to continue")
+	}
+	r := res.req
+	if r.Method != "PUT" || r.URL.String() != "http://localhost:3000/api/users/1" || r.Host != "localhost:3000" {
+		t.Errorf("unexpected request line: %s %s (host %s)", r.Method, r.URL, r.Host)
+	}
+	if r.Header.Get("X-Edited") != "1" || r.Header.Get("X-Original") != "" {
+		t.Errorf("unexpected headers: %v", r.Header)
+	}
+	data, _ := io.ReadAll(r.Body)
+	if string(data) != body || r.ContentLength != int64(len(body)) {
+		t.Errorf("unexpected body: %s (%d)", data, r.ContentLength)
+	}
+	if len(m.List()) != 0 {
+		t.Error("expected no pending items after resume")
+	}
+	if err := m.Resume(p.ID, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPauseRequestDrop(t *testing.T) {
+	m := New(nil)
+	if _, err := m.AddRule(Rule{}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan *http.Response)
+	go func() {
+		_, resp := m.PauseRequest(httptest.NewRequest("GET", "http://example.com/", nil))
+		done <- resp
+	}()
+
+	p := waitPending(t, m, 1)[0]
+	if err := m.Drop(p.ID); err != nil {
+		t.Fatal(err)
+	}
+	resp := <-done
+	if resp == nil || resp.StatusCode != http.StatusBadGateway || resp.Header.Get(HeaderBreakpoint) != "dropped" {
+		t.Errorf("expected dropped response, got %+v", resp)
+	}
+}
+
+func TestPauseResponse(t *testing.T) {
+	m := New(nil)
+	if _, err := m.AddRule(Rule{Hosts: []string{"example.com"}, Response: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "http://example.com/data", nil)
+
+	// Response-only rules do not pause requests
+	if _, resp := m.PauseRequest(req); resp != nil || len(m.List()) != 0 {
+		t.Fatal("request should not be paused")
+	}
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/octet-stream"}},
+		Body:       io.NopCloser(strings.NewReader("\xff\xfe")),
+	}
+	done := make(chan *http.Response)
+	go func() {
+		done <- m.PauseResponse(req, resp)
+	}()
+
+	p := waitPending(t, m, 1)[0]
+	if p.Phase != PhaseResponse || p.Status != 200 || p.BodyEncoding != EncodingBase64 || p.Body != "//4=" {
+		t.Errorf("unexpected pending item: %+v", p)
+	}
+	if err := m.Resume(p.ID, &Edit{Method: "POST"}); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected invalid edit, got %v", err)
+	}
+
+	body := "AQI="
+	if err := m.Resume(p.ID, &Edit{Status: 503, Body: &body, BodyEncoding: EncodingBase64}); err != nil {
+		t.Fatal(err)
+	}
+	got := <-done
+	data, _ := io.ReadAll(got.Body)
+	if got.StatusCode != 503 || string(data) != "\x01\x02" || got.Header.Get("Content-Type") != "application/octet-stream" {
+		t.Errorf("unexpected response: %d %q %v", got.StatusCode, data, got.Header)
+	}
+}
+
+func TestPauseTimeout(t *testing.T) {
+	m := New(&Config{Timeout: 20 * time.Millisecond, MaxBodySize: 4})
+	if _, err := m.AddRule(Rule{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Large bodies are omitted but still forwarded intact
+	req := httptest.NewRequest("POST", "http://example.com/", strings.NewReader("0123456789"))
+	got, resp := m.PauseRequest(req)
+	if resp != nil {
+		t.Fatal("expected request to continue after timeout")
+	}
+	data, _ := io.ReadAll(got.Body)
+	if string(data) != "0123456789" {
+		t.Errorf("body not preserved: %q", data)
+	}
+	if len(m.List()) != 0 {
+		t.Error("expected timed out item to be removed")
+	}
+}
@@ -11,9 +11,13 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"golang.org/x/net/publicsuffix"
 )
 
 // CA represents a certificate authority for MITM proxying.
@@ -207,6 +211,45 @@ func (ca *CA) TLSCertificate() (tls.Certificate, error) {
 
 // GenerateCert generates a certificate for the given domain, signed by this CA.
 func (ca *CA) GenerateCert(domain string) (certPEM, keyPEM []byte, err error) {
+	certDER, privateKey, err := ca.newLeaf([]string{domain})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+// IssueLeaf generates a certificate for names, which may be DNS names,
+// wildcards or IP addresses. The first name is used as the CommonName.
+func (ca *CA) IssueLeaf(names ...string) (*tls.Certificate, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no names to issue a certificate for")
+	}
+	certDER, privateKey, err := ca.newLeaf(names)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{certDER},
+		PrivateKey:  privateKey,
+		Leaf:        leaf,
+	}, nil
+}
+
+// newLeaf creates a server certificate for names signed by the CA.
+func (ca *CA) newLeaf(names []string) ([]byte, *ecdsa.PrivateKey, error) {
 	// Generate new private key for this certificate
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -221,29 +264,53 @@ func (ca *CA) GenerateCert(domain string) (certPEM, keyPEM []byte, err error) {
 	template := &x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
-			CommonName: domain,
+			CommonName: names[0],
 		},
 		NotBefore:   time.Now().Add(-1 * time.Hour), // 1 hour before to handle clock skew
 		NotAfter:    time.Now().AddDate(1, 0, 0),    // 1 year validity
-		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
 		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		DNSNames:    []string{domain},
+	}
+	// Leaves must not outlive the CA that signs them
+	if template.NotAfter.After(ca.Certificate.NotAfter) {
+		template.NotAfter = ca.Certificate.NotAfter
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
 	}
 
 	certDER, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, &privateKey.PublicKey, ca.PrivateKey)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
 	}
+	return certDER, privateKey, nil
+}
 
-	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
-
-	keyDER, err := x509.MarshalECPrivateKey(privateKey)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+// LeafNames returns the cache key and names of the certificate that serves
+// host. Hosts below a registrable domain share a wildcard certificate, so
+// api.example.com and www.example.com are both served by *.example.com.
+// IP addresses, registrable domains themselves and hosts directly below a
+// public suffix get a certificate of their own.
+func LeafNames(host string) (key string, names []string) {
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	if net.ParseIP(host) != nil {
+		return host, []string{host}
 	}
-	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
 
-	return certPEM, keyPEM, nil
+	label, parent, ok := strings.Cut(host, ".")
+	if !ok || label == "*" || !strings.Contains(parent, ".") {
+		return host, []string{host}
+	}
+	// A wildcard for a public suffix such as *.co.uk would not be trusted
+	if _, err := publicsuffix.EffectiveTLDPlusOne(parent); err != nil {
+		return host, []string{host}
+	}
+	wildcard := "*." + parent
+	return wildcard, []string{wildcard}
 }
 
 // DefaultCADir returns the default directory for storing CA files.
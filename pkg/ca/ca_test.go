@@ -1,6 +1,7 @@
 package ca
 
 import (
+	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
 	"os"
@@ -157,6 +158,69 @@ func TestGenerateCert(t *testing.T) {
 	}
 }
 
+func TestIssueLeaf(t *testing.T) {
+	ca, err := New(nil)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+
+	tests := []struct {
+		host     string
+		key      string
+		verifies []string
+	}{
+		{"api.example.com", "*.example.com", []string{"api.example.com", "www.example.com"}},
+		{"a.b.example.com", "*.b.example.com", []string{"a.b.example.com"}},
+		{"Example.com.", "example.com", []string{"example.com"}},
+		{"shop.co.uk", "shop.co.uk", []string{"shop.co.uk"}},
+		{"user.github.io", "user.github.io", []string{"user.github.io"}},
+		{"localhost", "localhost", []string{"localhost"}},
+		{"127.0.0.1", "127.0.0.1", []string{"127.0.0.1"}},
+		{"[::1]", "::1", []string{"::1"}},
+	}
+
+	for _, tt := range tests {
+		key, names := LeafNames(tt.host)
+		if key != tt.key {
+			t.Errorf("LeafNames(%q): expected key %s, got %s", tt.host, tt.key, key)
+			continue
+		}
+
+		tlsCert, err := ca.IssueLeaf(names...)
+		if err != nil {
+			t.Fatalf("failed to issue leaf for %s: %v", tt.host, err)
+		}
+		if _, ok := tlsCert.Leaf.PublicKey.(*ecdsa.PublicKey); !ok {
+			t.Errorf("expected an ECDSA key for %s", tt.host)
+		}
+		for _, name := range tt.verifies {
+			opts := x509.VerifyOptions{
+				Roots:     roots,
+				DNSName:   name,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}
+			if _, err := tlsCert.Leaf.Verify(opts); err != nil {
+				t.Errorf("certificate for %s does not verify for %s: %v", tt.host, name, err)
+			}
+		}
+	}
+
+	// The wildcard does not cover the registrable domain or deeper names
+	_, names := LeafNames("api.example.com")
+	tlsCert, err := ca.IssueLeaf(names...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"example.com", "a.b.example.com"} {
+		if err := tlsCert.Leaf.VerifyHostname(name); err == nil {
+			t.Errorf("expected %s not to match %v", name, tlsCert.Leaf.DNSNames)
+		}
+	}
+}
+
 func TestTLSCertificate(t *testing.T) {
 	ca, err := New(nil)
 	if err != nil {
@@ -0,0 +1,240 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/grokify/omniproxy/pkg/contentdetect"
+)
+
+// bodyCapture is the capture state of bodies that are teed while they are
+// relayed instead of being read up front.
+type bodyCapture struct {
+	request  *bodyStream
+	response *bodyStream
+	// pending is true while the response body is still being relayed
+	pending bool
+}
+
+// CaptureBody defers finishing rec until the response body has been relayed.
+// The body passes through to the client as it arrives, so streaming
+// responses (server-sent events, long polls, large downloads) are neither
+// held back nor buffered; up to MaxBodySize of it is kept for the record.
+// Call it before FinishCapture, which then leaves the record open until the
+// body ends or is closed. onError, if non-nil, receives errors from writing
+// the record then. gRPC calls are captured as by CaptureGRPC, and upgraded
+// connections are left to CaptureWebSocket.
+func (c *Capturer) CaptureBody(rec *Record, resp *http.Response, onError func(error)) {
+	if rec.grpc != nil {
+		c.CaptureGRPC(rec, resp, onError)
+		return
+	}
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody || resp.StatusCode == http.StatusSwitchingProtocols {
+		return
+	}
+
+	rec.Response = c.responseRecord(resp)
+	if rec.body == nil {
+		rec.body = &bodyCapture{}
+	}
+	rec.body.pending = true
+
+	body := &teeBody{ReadCloser: resp.Body}
+	if c.config.IncludeBody {
+		rec.body.response = &bodyStream{
+			limit:  c.config.MaxBodySize,
+			events: newEventParser(resp.Header.Get("Content-Type"), resp.Header.Get("Content-Encoding"), rec.StartTime),
+		}
+		body.stream = rec.body.response
+	}
+	body.done = func() {
+		c.completeRecord(rec)
+		if err := c.finishRecord(rec); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	resp.Body = body
+}
+
+// pending reports whether rec is left open until its response body has
+// been relayed.
+func (rec *Record) pending() bool {
+	return rec.grpc != nil && rec.grpc.pending || rec.body != nil && rec.body.pending
+}
+
+// completeBodies records the bodies teed while rec was relayed.
+func (c *Capturer) completeBodies(rec *Record) {
+	if rec.body == nil {
+		return
+	}
+	if s := rec.body.request; s != nil {
+		r := &rec.Request
+		data, size, truncated := s.snapshot()
+		r.BodySize = size
+		if len(data) > 0 {
+			b := c.recordBody(data, r.ContentType, r.ContentEncoding, truncated)
+			r.Body, r.IsBinary, r.Truncated, r.DecodedSize = b.body, b.isBinary, b.truncated, b.decodedSize
+		} else {
+			r.Truncated = truncated
+		}
+	}
+	if s := rec.body.response; s != nil {
+		r := &rec.Response
+		if r.Events = s.streamEvents(); len(r.Events) > 0 {
+			// Streams count time to the first event rather than first byte
+			rec.TTFBMs = r.Events[0].OffsetMs
+		}
+		data, size, truncated := s.snapshot()
+		r.Size = size
+		if len(data) > 0 {
+			b := c.recordBody(data, r.ContentType, r.ContentEncoding, truncated)
+			r.Body, r.IsBinary, r.Truncated, r.DecodedSize = b.body, b.isBinary, b.truncated, b.decodedSize
+		} else {
+			r.Truncated = truncated
+		}
+	}
+}
+
+// recordedBody is what a record keeps of a body.
+type recordedBody struct {
+	body      interface{}
+	isBinary  bool
+	truncated bool
+	// decodedSize is the size after removing the content encoding, when known
+	decodedSize int64
+}
+
+// recordBody returns the recorded form of the captured bytes of a body:
+// decoded from its content encoding, then a placeholder for skipped binary
+// content or the parsed body. Truncated bodies are kept as text, since a
+// prefix that happens to parse would misrepresent the body. The encoded
+// bytes are what the client receives; only the record is decoded.
+func (c *Capturer) recordBody(data []byte, contentType, encoding string, truncated bool) recordedBody {
+	b := recordedBody{truncated: truncated}
+	if encoding != "" {
+		if decoded, size, ok := decodeContent(data, encoding, c.config.MaxBodySize, c.maxDecodedSize()); ok {
+			data = decoded
+			if size >= 0 && !truncated {
+				b.decodedSize = size
+			}
+			b.truncated = truncated || size < 0 || size > c.config.MaxBodySize
+		}
+	}
+
+	switch {
+	case c.config.SkipBinary && contentdetect.IsBinary(contentType, data):
+		b.body, b.isBinary = BinaryPlaceholder, true
+	case b.truncated:
+		b.body = string(data)
+	default:
+		b.body = c.parseBody(data, contentType)
+	}
+	return b
+}
+
+// maxDecodedSize returns the limit on bytes decoded from an encoded body.
+func (c *Capturer) maxDecodedSize() int64 {
+	if c.config.MaxDecodedSize > 0 {
+		return c.config.MaxDecodedSize
+	}
+	return c.config.MaxBodySize
+}
+
+// readPrefix reads up to MaxBodySize bytes of body for records captured up
+// front. It returns them, whether the body continues past them, and a body
+// that replays them before the rest.
+func (c *Capturer) readPrefix(body io.ReadCloser) ([]byte, bool, io.ReadCloser) {
+	data, _ := io.ReadAll(io.LimitReader(body, c.config.MaxBodySize+1))
+	restored := &prefixedBody{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+	if int64(len(data)) > c.config.MaxBodySize {
+		return data[:c.config.MaxBodySize], true, restored
+	}
+	return data, false, restored
+}
+
+// prefixedBody reads bytes already taken from a body before the rest of it.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyStream collects the bytes of a body as they are relayed, up to limit.
+// Event streams are also split into events as they arrive.
+type bodyStream struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	size      int64
+	limit     int64
+	truncated bool
+	events    *eventParser
+}
+
+func (s *bodyStream) write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size += int64(len(p))
+	if room := s.limit - int64(s.buf.Len()); room < int64(len(p)) {
+		s.truncated = true
+		p = p[:max(room, 0)]
+	}
+	s.buf.Write(p)
+	if s.events != nil {
+		s.events.write(p)
+	}
+}
+
+// streamEvents ends the event stream and returns its events. Events past
+// the limit are dropped with the bytes that carried them.
+func (s *bodyStream) streamEvents() []StreamEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events == nil {
+		return nil
+	}
+	if !s.truncated {
+		s.events.finish()
+	}
+	return append([]StreamEvent(nil), s.events.events...)
+}
+
+// snapshot returns the collected bytes, the total size seen and whether
+// bytes were dropped.
+func (s *bodyStream) snapshot() ([]byte, int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return bytes.Clone(s.buf.Bytes()), s.size, s.truncated
+}
+
+// teeBody tees a request or response body into a stream and calls done
+// once when the body ends or is closed.
+type teeBody struct {
+	io.ReadCloser
+	stream *bodyStream
+	done   func()
+	once   sync.Once
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.stream != nil {
+		b.stream.write(p[:n])
+	}
+	if err != nil {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *teeBody) finish() {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+}
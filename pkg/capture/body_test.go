@@ -0,0 +1,141 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStartCaptureChunkedRequest(t *testing.T) {
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 8})
+
+	req, _ := http.NewRequest("POST", "http://example.com/upload", io.NopCloser(strings.NewReader("chunked upload body")))
+	req.ContentLength = -1
+	rec := c.StartCapture(req)
+
+	// The body reaches upstream unchanged
+	sent, err := io.ReadAll(req.Body)
+	if err != nil || string(sent) != "chunked upload body" {
+		t.Fatalf("unexpected body sent upstream: %q, %v", sent, err)
+	}
+	if err := c.FinishCapture(rec, &http.Response{StatusCode: 200}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := rec.Request
+	if r.Body != "chunked " || r.BodySize != 19 || !r.Truncated {
+		t.Errorf("unexpected request capture: body %q, size %d, truncated %v", r.Body, r.BodySize, r.Truncated)
+	}
+}
+
+func TestCaptureBodyStreams(t *testing.T) {
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 12})
+	var finished []*Record
+	c.AddHandler(func(rec *Record) { finished = append(finished, rec) })
+
+	req, _ := http.NewRequest("GET", "http://example.com/events", nil)
+	rec := c.StartCapture(req)
+
+	pr, pw := io.Pipe()
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"text/event-stream"}},
+		Body:       pr,
+	}
+	c.CaptureBody(rec, resp, func(err error) { t.Errorf("capture error: %v", err) })
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(finished) != 0 {
+		t.Fatal("record finished before the body was relayed")
+	}
+
+	// Each event reaches the client as soon as it is sent
+	buf := make([]byte, 64)
+	for _, event := range []string{"data: one\n\n", "data: two\n\n"} {
+		go func() { _, _ = pw.Write([]byte(event)) }()
+		n, err := resp.Body.Read(buf)
+		if err != nil || string(buf[:n]) != event {
+			t.Fatalf("read %q, %v; want %q", buf[:n], err, event)
+		}
+	}
+	if len(finished) != 0 {
+		t.Fatal("record finished while the body was streaming")
+	}
+
+	_ = pw.Close()
+	if _, err := resp.Body.Read(buf); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(finished) != 1 {
+		t.Fatalf("expected the record to finish once, got %d", len(finished))
+	}
+
+	r := finished[0].Response
+	if r.Status != 200 || r.Body != "data: one\n\nd" || r.Size != 22 || !r.Truncated {
+		t.Errorf("unexpected response capture: body %q, size %d, truncated %v", r.Body, r.Size, r.Truncated)
+	}
+	if finished[0].EndTime.IsZero() {
+		t.Error("expected an end time")
+	}
+}
+
+func TestCaptureBodyClosedEarly(t *testing.T) {
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 1024})
+	req, _ := http.NewRequest("GET", "http://example.com/download", nil)
+	rec := c.StartCapture(req)
+
+	resp := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"partial":true}`))}
+	c.CaptureBody(rec, resp, nil)
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// A client that goes away closes the body without reading it all
+	buf := make([]byte, 4)
+	_, _ = resp.Body.Read(buf)
+	_ = resp.Body.Close()
+
+	recs := c.Records()
+	if len(recs) != 1 || recs[0].Response.Body != `{"pa` || recs[0].Response.Size != 4 {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+}
+
+func TestCaptureResponseTruncated(t *testing.T) {
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 4})
+	body := `{"items":[1,2,3]}`
+	resp := &http.Response{
+		StatusCode:    200,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(strings.NewReader(body)),
+	}
+
+	rr := c.CaptureResponse(resp)
+	if rr.Body != `{"it` || rr.Size != int64(len(body)) || !rr.Truncated {
+		t.Errorf("unexpected capture: body %q, size %d, truncated %v", rr.Body, rr.Size, rr.Truncated)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != body {
+		t.Errorf("body not restored: %q", data)
+	}
+
+	// Truncation survives HAR output
+	buf := &bytes.Buffer{}
+	w := NewHARStreamWriter(buf)
+	if err := w.WriteRecord(&Record{Request: RequestRecord{Method: "GET", URL: "http://example.com/"}, Response: rr}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := ReadRecords(buf)
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("ReadRecords: %v", err)
+	}
+	if got := recs[0].Response; got.Body != `{"it` || !got.Truncated || got.Size != rr.Size {
+		t.Errorf("unexpected response from HAR: %+v", got)
+	}
+}
@@ -2,19 +2,24 @@
 package capture
 
 import (
-	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/grokify/omniproxy/pkg/contentdetect"
+	"github.com/grokify/omniproxy/pkg/protodecode"
+	"github.com/grokify/omniproxy/pkg/redact"
 )
 
 // Record represents a captured HTTP transaction.
 type Record struct {
+	// Type identifies the kind of record (empty means RecordTypeHTTP)
+	Type RecordType `json:"type,omitempty"`
 	// Request information
 	Request RequestRecord `json:"request"`
 	// Response information
@@ -23,32 +28,114 @@ type Record struct {
 	StartTime  time.Time `json:"startTime"`
 	EndTime    time.Time `json:"endTime,omitempty"`
 	DurationMs float64   `json:"durationMs,omitempty"`
+	// TTFBMs is the time from StartTime to the first upstream response
+	// byte, or to the first event of event streams
+	TTFBMs float64 `json:"ttfbMs,omitempty"`
+	// Timings breaks the upstream exchange into HAR timing phases
+	Timings *Timings `json:"timings,omitempty"`
+	// WebSocket holds connection and frame details for WebSocket records
+	WebSocket *WebSocketRecord `json:"websocket,omitempty"`
+	// GRPC holds the method and status of gRPC calls
+	GRPC *GRPCRecord `json:"grpc,omitempty"`
+	// Rewrite describes rewrite rules applied by the proxy
+	Rewrite *RewriteRecord `json:"rewrite,omitempty"`
+	// NetworkProfile is the simulated network profile the exchange ran under
+	NetworkProfile string `json:"networkProfile,omitempty"`
+	// User is the authenticated proxy user that sent the request
+	User string `json:"user,omitempty"`
+	// ClientIP is the address of the client that sent the request
+	ClientIP string `json:"clientIp,omitempty"`
+	// StreamID is the HTTP/2 stream that carried the request from the client
+	StreamID uint32 `json:"streamId,omitempty"`
+
+	trace *timingTrace
+	grpc  *grpcCall
+	body  *bodyCapture
 }
 
 // RequestRecord represents a captured HTTP request.
 type RequestRecord struct {
-	Method      string            `json:"method"`
-	URL         string            `json:"url"`
-	Host        string            `json:"host"`
-	Path        string            `json:"path"`
-	Scheme      string            `json:"scheme"`
-	Headers     map[string]string `json:"headers,omitempty"`
-	Query       map[string]string `json:"query,omitempty"`
-	Body        interface{}       `json:"body,omitempty"`
-	BodySize    int64             `json:"bodySize,omitempty"`
-	IsBinary    bool              `json:"isBinary,omitempty"`
-	ContentType string            `json:"contentType,omitempty"`
+	Method  string  `json:"method"`
+	URL     string  `json:"url"`
+	Host    string  `json:"host"`
+	Path    string  `json:"path"`
+	Scheme  string  `json:"scheme"`
+	Headers Headers `json:"headers,omitempty"`
+	// Query holds the query parameters in the order they appear in the URL
+	Query Query `json:"query,omitempty"`
+	// Cookies are parsed from the Cookie headers
+	Cookies  []Cookie    `json:"cookies,omitempty"`
+	Body     interface{} `json:"body,omitempty"`
+	BodySize int64       `json:"bodySize,omitempty"`
+	IsBinary bool        `json:"isBinary,omitempty"`
+	// Truncated is true when Body holds only the first MaxBodySize bytes;
+	// BodySize is still the full size
+	Truncated   bool   `json:"truncated,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// ContentEncoding is the Content-Encoding of the body. Body is recorded
+	// decoded; BodySize is the encoded size and DecodedSize the decoded one
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	DecodedSize     int64  `json:"decodedSize,omitempty"`
+	HeadersSize     int64  `json:"headersSize,omitempty"`
+	// HTTPVersion is the protocol the client used (e.g. HTTP/1.1, HTTP/2.0)
+	HTTPVersion string `json:"httpVersion,omitempty"`
 }
 
 // ResponseRecord represents a captured HTTP response.
 type ResponseRecord struct {
-	Status      int               `json:"status"`
-	StatusText  string            `json:"statusText,omitempty"`
-	Headers     map[string]string `json:"headers,omitempty"`
-	Body        interface{}       `json:"body,omitempty"`
-	IsBinary    bool              `json:"isBinary,omitempty"`
-	ContentType string            `json:"contentType,omitempty"`
-	Size        int64             `json:"size,omitempty"`
+	Status     int     `json:"status"`
+	StatusText string  `json:"statusText,omitempty"`
+	Headers    Headers `json:"headers,omitempty"`
+	// Cookies are parsed from the Set-Cookie headers
+	Cookies  []Cookie    `json:"cookies,omitempty"`
+	Body     interface{} `json:"body,omitempty"`
+	IsBinary bool        `json:"isBinary,omitempty"`
+	// Truncated is true when Body holds only the first MaxBodySize bytes;
+	// Size is still the full size
+	Truncated   bool   `json:"truncated,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// ContentEncoding is the Content-Encoding of the body. Body is recorded
+	// decoded; Size is the encoded size and DecodedSize the decoded one
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	DecodedSize     int64  `json:"decodedSize,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+	HeadersSize     int64  `json:"headersSize,omitempty"`
+	RedirectURL     string `json:"redirectURL,omitempty"`
+	// Events are the events of server-sent event and NDJSON streams, in
+	// the order they arrived; Body still holds the raw stream
+	Events []StreamEvent `json:"events,omitempty"`
+	// HTTPVersion is the protocol the response was received over
+	HTTPVersion string `json:"httpVersion,omitempty"`
+}
+
+// RewriteRecord describes the rewrite rules applied to a transaction. The
+// Request and Response of the record hold what was actually exchanged.
+type RewriteRecord struct {
+	// Rules are the names of the applied rules, in order
+	Rules []string `json:"rules"`
+	// Local is true when a rule answered the request without contacting upstream
+	Local bool `json:"local,omitempty"`
+	// OriginalRequest is the request as received, before rewriting (optional)
+	OriginalRequest *RequestRecord `json:"originalRequest,omitempty"`
+	// OriginalResponse is the response as received, before rewriting (optional)
+	OriginalResponse *ResponseRecord `json:"originalResponse,omitempty"`
+}
+
+// networkTagPrefix prefixes the network profile when it is stored as a tag.
+const networkTagPrefix = "network:"
+
+// NetworkProfileTag returns the tag that records a network profile in stores
+// that keep tags rather than a dedicated field.
+func NetworkProfileTag(profile string) string {
+	return networkTagPrefix + profile
+}
+
+// NetworkProfileFromTag returns the network profile recorded by tag.
+func NetworkProfileFromTag(tag string) (string, bool) {
+	if !strings.HasPrefix(tag, networkTagPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(tag, networkTagPrefix), true
 }
 
 // Capturer captures HTTP transactions.
@@ -59,6 +146,7 @@ type Capturer struct {
 	format   Format
 	config   *Config
 	handlers []Handler
+	har      *HARStreamWriter
 }
 
 // Format specifies the output format for captured traffic.
@@ -75,6 +163,9 @@ const (
 	FormatIR Format = "ir"
 )
 
+// BinaryPlaceholder is the body recorded in place of skipped binary content.
+const BinaryPlaceholder = "[binary content]"
+
 // Handler is called for each captured record.
 type Handler func(*Record)
 
@@ -84,18 +175,29 @@ type Config struct {
 	Output io.Writer
 	// Format is the output format (default: ndjson)
 	Format Format
+	// HAR receives HAR output instead of Output, such as a file opened with
+	// OpenHARFile (optional; closed by Close)
+	HAR *HARStreamWriter
 	// IncludeHeaders controls whether to include headers
 	IncludeHeaders bool
-	// FilterHeaders is a list of headers to exclude (case-insensitive)
-	FilterHeaders []string
+	// Redactor removes secrets from records before handlers and outputs
+	// see them (optional)
+	Redactor *redact.Redactor
 	// IncludeBody controls whether to include request/response bodies
 	IncludeBody bool
 	// MaxBodySize is the maximum body size to capture (default: 1MB)
 	MaxBodySize int64
+	// MaxDecodedSize limits the bytes decompressed from a gzip, br, zstd or
+	// deflate encoded body, guarding against decompression bombs (default:
+	// 10MB; MaxBodySize when zero)
+	MaxDecodedSize int64
 	// SkipBinary skips capturing binary content (images, videos, etc.)
 	SkipBinary bool
 	// Filter is the request/response filter (optional)
 	Filter *Filter
+	// Protos decodes gRPC messages to JSON (optional; without it messages
+	// are recorded as raw protobuf wire-format dumps)
+	Protos *protodecode.Decoder
 }
 
 // DefaultConfig returns default capturer configuration.
@@ -104,17 +206,11 @@ func DefaultConfig() *Config {
 		Output:         os.Stdout,
 		Format:         FormatNDJSON,
 		IncludeHeaders: true,
-		FilterHeaders: []string{
-			"authorization",
-			"cookie",
-			"set-cookie",
-			"x-api-key",
-			"x-auth-token",
-			"proxy-authorization",
-		},
-		IncludeBody: true,
-		MaxBodySize: 1024 * 1024, // 1MB
-		SkipBinary:  true,        // Skip binary content by default
+		Redactor:       redact.Default(),
+		IncludeBody:    true,
+		MaxBodySize:    1024 * 1024, // 1MB
+		MaxDecodedSize: DefaultMaxDecodedSize,
+		SkipBinary:     true, // Skip binary content by default
 	}
 }
 
@@ -123,12 +219,29 @@ func NewCapturer(cfg *Config) *Capturer {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
-	return &Capturer{
+	c := &Capturer{
 		records: make([]Record, 0),
 		output:  cfg.Output,
 		format:  cfg.Format,
 		config:  cfg,
 	}
+	if c.format == FormatHAR {
+		if cfg.HAR != nil {
+			c.har = cfg.HAR
+		} else if c.output != nil {
+			c.har = NewHARStreamWriter(c.output)
+		}
+	}
+	return c
+}
+
+// Close finalizes the output. For HAR output it writes the closing brackets
+// of the document; other formats need no finalization.
+func (c *Capturer) Close() error {
+	if c.har != nil {
+		return c.har.Close()
+	}
+	return nil
 }
 
 // AddHandler adds a handler to be called for each captured record.
@@ -138,120 +251,215 @@ func (c *Capturer) AddHandler(h Handler) {
 	c.handlers = append(c.handlers, h)
 }
 
-// StartCapture begins capturing a request.
+// StartCapture begins capturing a request. Bodies of known length up to
+// MaxBodySize are read up front; chunked and larger bodies are teed as they
+// are sent upstream and recorded when the record is finished.
 func (c *Capturer) StartCapture(req *http.Request) *Record {
 	rec := &Record{
 		StartTime: time.Now(),
-		Request: RequestRecord{
-			Method: req.Method,
-			URL:    req.URL.String(),
-			Host:   req.Host,
-			Path:   req.URL.Path,
-			Scheme: req.URL.Scheme,
-		},
+		Request:   c.requestRecord(req),
+		ClientIP:  clientIP(req.RemoteAddr),
+	}
+
+	// gRPC messages are recorded as they stream (see CaptureGRPC)
+	if IsGRPCContentType(req.Header.Get("Content-Type")) {
+		c.startGRPC(rec, req)
+		return rec
+	}
+
+	// Capture request body
+	if c.config.IncludeBody && req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength > 0 && req.ContentLength <= c.config.MaxBodySize {
+			c.readRequestBody(&rec.Request, req)
+		} else {
+			rec.body = &bodyCapture{request: &bodyStream{limit: c.config.MaxBodySize}}
+			req.Body = &teeBody{ReadCloser: req.Body, stream: rec.body.request}
+		}
+	}
+
+	return rec
+}
+
+// CaptureRequest records the request line, headers and body of req without
+// starting a record, such as to keep a request as it was before rewriting.
+// Up to MaxBodySize of the body is read up front; req.Body is replaced so
+// the whole body stays readable.
+func (c *Capturer) CaptureRequest(req *http.Request) RequestRecord {
+	rr := c.requestRecord(req)
+	if c.config.IncludeBody && req.Body != nil && req.Body != http.NoBody && !IsGRPCContentType(rr.ContentType) {
+		c.readRequestBody(&rr, req)
+	}
+	return rr
+}
+
+// requestRecord records everything about req but its body.
+func (c *Capturer) requestRecord(req *http.Request) RequestRecord {
+	rr := RequestRecord{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Host:        req.Host,
+		Path:        req.URL.Path,
+		Scheme:      req.URL.Scheme,
+		HeadersSize: requestHeadersSize(req),
+		HTTPVersion: req.Proto,
 	}
 
 	// Determine scheme
-	if rec.Request.Scheme == "" {
+	if rr.Scheme == "" {
 		if req.TLS != nil {
-			rec.Request.Scheme = "https"
+			rr.Scheme = "https"
 		} else {
-			rec.Request.Scheme = "http"
+			rr.Scheme = "http"
 		}
 	}
 
 	// Capture headers
 	if c.config.IncludeHeaders && len(req.Header) > 0 {
-		rec.Request.Headers = c.filterHeaders(req.Header)
+		rr.Headers = HeadersFromHTTP(req.Header)
+		rr.Cookies = rr.Headers.Cookies()
 		if ct := req.Header.Get("Content-Type"); ct != "" {
-			rec.Request.ContentType = ct
+			rr.ContentType = ct
 		}
 	}
 
 	// Capture query parameters
-	if len(req.URL.Query()) > 0 {
-		rec.Request.Query = make(map[string]string)
-		for k, v := range req.URL.Query() {
-			if len(v) > 0 {
-				rec.Request.Query[k] = v[0]
-			}
-		}
+	rr.Query = ParseQuery(req.URL.RawQuery)
+
+	// Bodies are recorded decoded, even without headers
+	rr.ContentEncoding = req.Header.Get("Content-Encoding")
+
+	return rr
+}
+
+// readRequestBody reads up to MaxBodySize of the body of req into rr.
+func (c *Capturer) readRequestBody(rr *RequestRecord, req *http.Request) {
+	body, truncated, restored := c.readPrefix(req.Body)
+	req.Body = restored
+	if len(body) == 0 {
+		return
 	}
+	rr.BodySize = int64(len(body))
+	if truncated {
+		rr.BodySize = max(req.ContentLength, rr.BodySize)
+	}
+	b := c.recordBody(body, rr.ContentType, rr.ContentEncoding, truncated)
+	rr.Body, rr.IsBinary, rr.Truncated, rr.DecodedSize = b.body, b.isBinary, b.truncated, b.decodedSize
+}
 
-	// Capture request body
-	if c.config.IncludeBody && req.Body != nil && req.ContentLength > 0 && req.ContentLength <= c.config.MaxBodySize {
-		body, err := io.ReadAll(io.LimitReader(req.Body, c.config.MaxBodySize))
-		if err == nil && len(body) > 0 {
-			req.Body = io.NopCloser(bytes.NewReader(body))
-			rec.Request.BodySize = int64(len(body))
-
-			// Check if binary content
-			if c.config.SkipBinary && contentdetect.IsBinary(rec.Request.ContentType, body) {
-				rec.Request.IsBinary = true
-				rec.Request.Body = "[binary content]"
-			} else {
-				rec.Request.Body = c.parseBody(body, rec.Request.ContentType)
-			}
+// clientIP returns the host part of a request's remote address.
+func clientIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// FinishCapture completes capturing a response. Unless CaptureBody deferred
+// the record, up to MaxBodySize of the response body is read first.
+func (c *Capturer) FinishCapture(rec *Record, resp *http.Response) error {
+	// Records whose response body is still being relayed are finished when
+	// it ends (see CaptureBody and CaptureGRPC)
+	if rec.pending() {
+		return nil
+	}
+
+	if resp != nil {
+		rec.Response = c.CaptureResponse(resp)
+		if rec.grpc != nil {
+			c.completeGRPC(rec, resp)
 		}
 	}
 
-	return rec
+	c.completeRecord(rec)
+	return c.finishRecord(rec)
 }
 
-// FinishCapture completes capturing a response.
-func (c *Capturer) FinishCapture(rec *Record, resp *http.Response) error {
+// completeRecord sets the end time and timings of rec and records the
+// bodies teed while it was relayed.
+func (c *Capturer) completeRecord(rec *Record) {
 	rec.EndTime = time.Now()
 	rec.DurationMs = float64(rec.EndTime.Sub(rec.StartTime).Microseconds()) / 1000.0
 
-	if resp != nil {
-		rec.Response = ResponseRecord{
-			Status:     resp.StatusCode,
-			StatusText: resp.Status,
-		}
+	// Upstream timing phases (receive ends once the body has been read)
+	if rec.trace != nil {
+		rec.Timings = rec.trace.finish(rec.EndTime)
+		rec.TTFBMs = rec.trace.ttfb(rec.StartTime)
+	}
+
+	c.completeBodies(rec)
+}
 
-		// Capture response headers
-		if c.config.IncludeHeaders && len(resp.Header) > 0 {
-			rec.Response.Headers = c.filterHeaders(resp.Header)
-			if ct := resp.Header.Get("Content-Type"); ct != "" {
-				rec.Response.ContentType = ct
+// CaptureResponse records the status, headers and body of resp without
+// finishing a record. Up to MaxBodySize of the body is read up front;
+// resp.Body is replaced so the whole body stays readable.
+func (c *Capturer) CaptureResponse(resp *http.Response) ResponseRecord {
+	rr := c.responseRecord(resp)
+
+	// Capture response body (an upgraded connection has no body to read,
+	// and gRPC bodies are captured as they stream)
+	if c.config.IncludeBody && resp.Body != nil && resp.Body != http.NoBody && resp.StatusCode != http.StatusSwitchingProtocols &&
+		!IsGRPCContentType(resp.Header.Get("Content-Type")) {
+		body, truncated, restored := c.readPrefix(resp.Body)
+		resp.Body = restored
+		if len(body) > 0 {
+			rr.Size = int64(len(body))
+			if truncated {
+				rr.Size = max(resp.ContentLength, rr.Size)
 			}
+			b := c.recordBody(body, rr.ContentType, rr.ContentEncoding, truncated)
+			rr.Body, rr.IsBinary, rr.Truncated, rr.DecodedSize = b.body, b.isBinary, b.truncated, b.decodedSize
 		}
+	}
 
-		// Capture response body
-		if c.config.IncludeBody && resp.Body != nil && resp.ContentLength <= c.config.MaxBodySize {
-			body, err := io.ReadAll(io.LimitReader(resp.Body, c.config.MaxBodySize))
-			if err == nil && len(body) > 0 {
-				resp.Body = io.NopCloser(bytes.NewReader(body))
-				rec.Response.Size = int64(len(body))
-
-				// Check if binary content
-				if c.config.SkipBinary && contentdetect.IsBinary(rec.Response.ContentType, body) {
-					rec.Response.IsBinary = true
-					rec.Response.Body = "[binary content]"
-				} else {
-					rec.Response.Body = c.parseBody(body, rec.Response.ContentType)
-				}
-			}
+	return rr
+}
+
+// responseRecord records everything about resp but its body.
+func (c *Capturer) responseRecord(resp *http.Response) ResponseRecord {
+	rr := ResponseRecord{
+		Status:      resp.StatusCode,
+		StatusText:  resp.Status,
+		HeadersSize: responseHeadersSize(resp),
+		RedirectURL: redirectURL(resp),
+		HTTPVersion: resp.Proto,
+	}
+
+	// Capture response headers
+	if c.config.IncludeHeaders && len(resp.Header) > 0 {
+		rr.Headers = HeadersFromHTTP(resp.Header)
+		rr.Cookies = rr.Headers.SetCookies()
+		if ct := resp.Header.Get("Content-Type"); ct != "" {
+			rr.ContentType = ct
 		}
 	}
 
-	return c.finishRecord(rec)
+	// Bodies are recorded decoded, even without headers
+	rr.ContentEncoding = resp.Header.Get("Content-Encoding")
+
+	return rr
 }
 
 // FinishCaptureWithStatus completes capturing with just status code and size (for reverse proxy).
 func (c *Capturer) FinishCaptureWithStatus(rec *Record, statusCode int, bytesWritten int64) error {
-	rec.EndTime = time.Now()
-	rec.DurationMs = float64(rec.EndTime.Sub(rec.StartTime).Microseconds()) / 1000.0
 	rec.Response = ResponseRecord{
 		Status: statusCode,
 		Size:   bytesWritten,
 	}
+	c.completeRecord(rec)
 
 	return c.finishRecord(rec)
 }
 
 // finishRecord stores and writes the record.
 func (c *Capturer) finishRecord(rec *Record) error {
+	// Drop records the filter excludes
+	if c.config.Filter != nil && !c.config.Filter.Match(rec) {
+		return nil
+	}
+
+	// Redact before anything else sees the record
+	c.redact(rec)
+
 	// Store record
 	c.mu.Lock()
 	c.records = append(c.records, *rec)
@@ -267,27 +475,13 @@ func (c *Capturer) finishRecord(rec *Record) error {
 	return c.writeRecord(rec)
 }
 
-// filterHeaders filters sensitive headers.
-func (c *Capturer) filterHeaders(headers http.Header) map[string]string {
-	result := make(map[string]string)
-	for k, v := range headers {
-		skip := false
-		kLower := normalizeHeader(k)
-		for _, filter := range c.config.FilterHeaders {
-			if normalizeHeader(filter) == kLower {
-				skip = true
-				break
-			}
-		}
-		if !skip && len(v) > 0 {
-			result[kLower] = v[0]
-		}
-	}
-	return result
-}
-
 // parseBody attempts to parse body as JSON, otherwise returns as string.
 func (c *Capturer) parseBody(body []byte, contentType string) interface{} {
+	return decodeBody(body, contentType)
+}
+
+// decodeBody parses body as JSON when possible, otherwise returns it as a string.
+func decodeBody(body []byte, contentType string) interface{} {
 	// Try JSON parsing
 	if isJSONContentType(contentType) || len(body) > 0 && (body[0] == '{' || body[0] == '[') {
 		var v interface{}
@@ -300,6 +494,9 @@ func (c *Capturer) parseBody(body []byte, contentType string) interface{} {
 
 // writeRecord writes a record to the output.
 func (c *Capturer) writeRecord(rec *Record) error {
+	if c.har != nil {
+		return c.har.WriteRecord(rec)
+	}
 	if c.output == nil {
 		return nil
 	}
@@ -347,16 +544,57 @@ func (c *Capturer) Clear() {
 	c.records = make([]Record, 0)
 }
 
-func normalizeHeader(s string) string {
-	result := make([]byte, len(s))
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c >= 'A' && c <= 'Z' {
-			c += 'a' - 'A'
+// requestHeadersSize returns the size of the request line and header block
+// as they would appear on the wire in HTTP/1.1.
+func requestHeadersSize(req *http.Request) int64 {
+	uri := req.URL.RequestURI()
+	proto := req.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	size := len(req.Method) + 1 + len(uri) + 1 + len(proto) + 2
+	if req.Host != "" && req.Header.Get("Host") == "" {
+		size += len("Host: ") + len(req.Host) + 2
+	}
+	return int64(size + headerBlockSize(req.Header))
+}
+
+// responseHeadersSize returns the size of the status line and header block.
+func responseHeadersSize(resp *http.Response) int64 {
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	status := resp.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return int64(len(proto) + 1 + len(status) + 2 + headerBlockSize(resp.Header))
+}
+
+// headerBlockSize sums "Name: value\r\n" lines plus the terminating CRLF.
+func headerBlockSize(h http.Header) int {
+	size := 2
+	for k, vs := range h {
+		for _, v := range vs {
+			size += len(k) + 2 + len(v) + 2
+		}
+	}
+	return size
+}
+
+// redirectURL resolves the Location header of a redirect response.
+func redirectURL(resp *http.Response) string {
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return ""
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		if u, err := resp.Request.URL.Parse(loc); err == nil {
+			return u.String()
 		}
-		result[i] = c
 	}
-	return string(result)
+	return loc
 }
 
 func isJSONContentType(ct string) bool {
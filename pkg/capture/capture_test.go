@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+
+	"github.com/grokify/omniproxy/pkg/redact"
 )
 
 func TestNewCapturer(t *testing.T) {
@@ -69,7 +71,7 @@ func TestStartCapture(t *testing.T) {
 		t.Errorf("expected https, got %s", rec.Request.Scheme)
 	}
 
-	if rec.Request.Query["limit"] != "10" {
+	if rec.Request.Query.Get("limit") != "10" {
 		t.Errorf("expected limit=10, got %v", rec.Request.Query)
 	}
 }
@@ -82,7 +84,7 @@ func TestFinishCapture(t *testing.T) {
 		IncludeHeaders: true,
 		IncludeBody:    true,
 		MaxBodySize:    1024,
-		FilterHeaders:  []string{"authorization"},
+		Redactor:       redact.Default(),
 	}
 	c := NewCapturer(cfg)
 
@@ -114,16 +116,57 @@ func TestFinishCapture(t *testing.T) {
 	}
 }
 
-func TestHeaderFiltering(t *testing.T) {
+func TestFinishCaptureFilter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	filter := NewFilter()
+	filter.ExcludeHosts = []string{"*.internal"}
+	filter.Predicate = func(rec *Record) bool { return rec.Response.Status >= 400 }
+	if err := filter.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCapturer(&Config{Output: buf, Format: FormatNDJSON, Filter: filter})
+
+	for _, tt := range []struct {
+		url    string
+		status int
+	}{
+		{"https://api.example.com/ok", 200},
+		{"https://db.internal/fail", 500},
+		{"https://api.example.com/fail", 500},
+	} {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		rec := c.StartCapture(req)
+		if err := c.FinishCapture(rec, &http.Response{StatusCode: tt.status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := c.Records()
+	if len(records) != 1 || records[0].Request.Path != "/fail" || records[0].Request.Host != "api.example.com" {
+		t.Fatalf("expected only the failed api.example.com request, got %+v", records)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("expected 1 record written, got %d", n)
+	}
+}
+
+func TestHeaderRedaction(t *testing.T) {
+	redactor, err := redact.New(&redact.Config{Headers: []string{"authorization", "cookie", "x-api-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
 	cfg := &Config{
 		Output:         &bytes.Buffer{},
 		Format:         FormatNDJSON,
 		IncludeHeaders: true,
 		IncludeBody:    false,
-		FilterHeaders:  []string{"authorization", "cookie", "x-api-key"},
+		Redactor:       redactor,
 	}
 	c := NewCapturer(cfg)
 
+	var handled *Record
+	c.AddHandler(func(rec *Record) { handled = rec })
+
 	req, _ := http.NewRequest("GET", "https://api.example.com/test", nil)
 	req.Header.Set("Authorization", "Bearer secret")
 	req.Header.Set("Cookie", "session=abc")
@@ -132,27 +175,93 @@ func TestHeaderFiltering(t *testing.T) {
 	req.Header.Set("User-Agent", "test")
 
 	rec := c.StartCapture(req)
+	if err := c.FinishCapture(rec, &http.Response{StatusCode: 200}); err != nil {
+		t.Fatalf("FinishCapture failed: %v", err)
+	}
+	if handled == nil {
+		t.Fatal("expected the handler to be called")
+	}
 
-	// Sensitive headers should be filtered
-	if _, ok := rec.Request.Headers["authorization"]; ok {
-		t.Error("authorization header should be filtered")
+	// Sensitive headers should be redacted
+	for _, name := range []string{"authorization", "x-api-key"} {
+		if v := handled.Request.Headers.Get(name); v != redact.Placeholder {
+			t.Errorf("%s header should be redacted, got %q", name, v)
+		}
 	}
-	if _, ok := rec.Request.Headers["cookie"]; ok {
-		t.Error("cookie header should be filtered")
+
+	// Cookies keep their names
+	if v := handled.Request.Headers.Get("cookie"); v != "session=[REDACTED]" {
+		t.Errorf("cookie header should be redacted, got %q", v)
 	}
-	if _, ok := rec.Request.Headers["x-api-key"]; ok {
-		t.Error("x-api-key header should be filtered")
+	if len(handled.Request.Cookies) != 1 || handled.Request.Cookies[0] != (Cookie{Name: "session", Value: redact.Placeholder}) {
+		t.Errorf("unexpected cookies: %+v", handled.Request.Cookies)
 	}
 
 	// Non-sensitive headers should be present
-	if rec.Request.Headers["accept"] != "application/json" {
+	if handled.Request.Headers.Get("accept") != "application/json" {
 		t.Error("accept header should be present")
 	}
-	if rec.Request.Headers["user-agent"] != "test" {
+	if handled.Request.Headers.Get("user-agent") != "test" {
 		t.Error("user-agent header should be present")
 	}
 }
 
+func TestBodyAndQueryRedaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewCapturer(&Config{
+		Output:         buf,
+		Format:         FormatNDJSON,
+		IncludeHeaders: true,
+		IncludeBody:    true,
+		MaxBodySize:    1024,
+		Redactor:       redact.Default(),
+	})
+
+	body := `{"user":"ann","password":"hunter2","card":"4111 1111 1111 1111"}`
+	req, _ := http.NewRequest("POST", "https://api.example.com/login?api_key=k3y&page=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := c.StartCapture(req)
+
+	// The forwarded request keeps its body
+	if sent, _ := io.ReadAll(req.Body); string(sent) != body {
+		t.Errorf("request body changed: %s", sent)
+	}
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+		Body:       io.NopCloser(strings.NewReader("access_token=t0k&expires=3600")),
+	}
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatalf("FinishCapture failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "k3y", "4111", "t0k"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("output leaks %q: %s", secret, out)
+		}
+	}
+
+	var got Record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Request.Query.Get("api_key") != redact.Placeholder || got.Request.Query.Get("page") != "1" {
+		t.Errorf("unexpected query: %v", got.Request.Query)
+	}
+	if got.Request.URL != "https://api.example.com/login?api_key=%5BREDACTED%5D&page=1" {
+		t.Errorf("unexpected URL: %s", got.Request.URL)
+	}
+	reqBody, _ := got.Request.Body.(map[string]interface{})
+	if reqBody["user"] != "ann" || reqBody["password"] != redact.Placeholder || reqBody["card"] != redact.Placeholder {
+		t.Errorf("unexpected request body: %v", got.Request.Body)
+	}
+	if got.Response.Body != "access_token=%5BREDACTED%5D&expires=3600" {
+		t.Errorf("unexpected response body: %v", got.Response.Body)
+	}
+}
+
 func TestRecords(t *testing.T) {
 	c := NewCapturer(nil)
 
@@ -307,14 +416,14 @@ func TestQueryParams(t *testing.T) {
 
 	rec := c.StartCapture(req)
 
-	if rec.Request.Query["q"] != "test" {
-		t.Errorf("expected q=test, got %v", rec.Request.Query["q"])
+	if rec.Request.Query.Get("q") != "test" {
+		t.Errorf("expected q=test, got %v", rec.Request.Query.Get("q"))
 	}
-	if rec.Request.Query["limit"] != "10" {
-		t.Errorf("expected limit=10, got %v", rec.Request.Query["limit"])
+	if rec.Request.Query.Get("limit") != "10" {
+		t.Errorf("expected limit=10, got %v", rec.Request.Query.Get("limit"))
 	}
-	if rec.Request.Query["offset"] != "0" {
-		t.Errorf("expected offset=0, got %v", rec.Request.Query["offset"])
+	if rec.Request.Query.Get("offset") != "0" {
+		t.Errorf("expected offset=0, got %v", rec.Request.Query.Get("offset"))
 	}
 }
 
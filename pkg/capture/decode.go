@@ -0,0 +1,88 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultMaxDecodedSize is the default limit on the bytes decompressed from
+// an encoded body.
+const DefaultMaxDecodedSize = 10 * 1024 * 1024 // 10MB
+
+// errUnsupportedEncoding is returned for content codings that cannot be
+// decoded.
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// decodeContent removes the content codings listed in encoding, a
+// Content-Encoding value whose codings were applied in order. It returns up
+// to keep decoded bytes and the full decoded size, or -1 when the size is
+// unknown because data ended early or more than limit bytes were decoded,
+// which guards against decompression bombs. ok is false when a coding is
+// not supported or data does not decode at all.
+func decodeContent(data []byte, encoding string, keep, limit int64) (decoded []byte, size int64, ok bool) {
+	var r io.Reader = bytes.NewReader(data)
+	codings := strings.Split(encoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+		d, err := newDecoder(coding, r)
+		if err != nil {
+			return nil, 0, false
+		}
+		defer d.Close()
+		r = d
+	}
+
+	keep = min(keep, limit)
+	lr := &io.LimitedReader{R: r, N: limit + 1}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, lr, keep)
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(io.Discard, lr)
+		n += rest
+	}
+	switch {
+	case err != nil && err != io.EOF && n == 0:
+		return nil, 0, false
+	case err != nil && err != io.EOF, lr.N <= 0:
+		return buf.Bytes(), -1, true
+	}
+	return buf.Bytes(), n, true
+}
+
+// newDecoder returns a reader that decodes r with a content coding.
+func newDecoder(coding string, r io.Reader) (io.ReadCloser, error) {
+	switch coding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// deflate is meant to be zlib-wrapped, but some servers send raw
+		// deflate data
+		br := bufio.NewReader(r)
+		if h, err := br.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return nil, errUnsupportedEncoding
+}
@@ -0,0 +1,172 @@
+package capture
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encode compresses data with a content coding.
+func encode(t *testing.T, coding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		var err error
+		if w, err = zstd.NewWriter(&buf); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unknown coding %s", coding)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeContent(t *testing.T) {
+	plain := []byte(`{"message":"hello, compressed world"}`)
+	tests := []struct {
+		name     string
+		coding   string
+		encoding string
+	}{
+		{"gzip", "gzip", "gzip"},
+		{"x-gzip", "gzip", "x-gzip"},
+		{"zlib deflate", "deflate", "deflate"},
+		{"raw deflate", "raw-deflate", "deflate"},
+		{"brotli", "br", "br"},
+		{"zstd", "zstd", "ZSTD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, size, ok := decodeContent(encode(t, tt.coding, plain), tt.encoding, 1024, 1024)
+			if !ok || !bytes.Equal(got, plain) || size != int64(len(plain)) {
+				t.Errorf("decodeContent = %q, %d, %v", got, size, ok)
+			}
+		})
+	}
+
+	// Codings are removed in the reverse of the order they were applied
+	data := encode(t, "br", encode(t, "gzip", plain))
+	if got, _, ok := decodeContent(data, "gzip, br", 1024, 1024); !ok || !bytes.Equal(got, plain) {
+		t.Errorf("unexpected layered decoding: %q, %v", got, ok)
+	}
+	if got, _, ok := decodeContent(plain, "identity", 1024, 1024); !ok || !bytes.Equal(got, plain) {
+		t.Errorf("unexpected identity decoding: %q, %v", got, ok)
+	}
+
+	for _, encoding := range []string{"compress", "gzip"} {
+		if _, _, ok := decodeContent([]byte("not compressed"), encoding, 1024, 1024); ok {
+			t.Errorf("%s: expected a failure", encoding)
+		}
+	}
+}
+
+func TestDecodeContentLimits(t *testing.T) {
+	plain := bytes.Repeat([]byte("a"), 4096)
+	data := encode(t, "gzip", plain)
+
+	// Only keep bytes are returned, but the whole body is measured
+	got, size, ok := decodeContent(data, "gzip", 100, 8192)
+	if !ok || len(got) != 100 || size != 4096 {
+		t.Errorf("decodeContent = %d bytes, size %d, %v", len(got), size, ok)
+	}
+
+	// Decoding stops at the limit, leaving the size unknown
+	got, size, ok = decodeContent(data, "gzip", 100, 1000)
+	if !ok || len(got) != 100 || size != -1 {
+		t.Errorf("decodeContent over the limit = %d bytes, size %d, %v", len(got), size, ok)
+	}
+
+	// A cut-off body decodes as far as it goes
+	var b strings.Builder
+	for i := 0; b.Len() < 65536; i++ {
+		fmt.Fprintf(&b, "%d,", i*i)
+	}
+	data = encode(t, "gzip", []byte(b.String()))
+	got, size, ok = decodeContent(data[:len(data)/2], "gzip", 1<<20, 1<<20)
+	if !ok || len(got) == 0 || size != -1 {
+		t.Errorf("decodeContent of a prefix = %d bytes, size %d, %v", len(got), size, ok)
+	}
+}
+
+func TestCaptureEncodedResponse(t *testing.T) {
+	c := NewCapturer(&Config{IncludeHeaders: true, IncludeBody: true, MaxBodySize: 1024, SkipBinary: true})
+	plain := `{"items":["a","b","c"]}`
+	encoded := encode(t, "br", []byte(plain))
+
+	req, _ := http.NewRequest("GET", "http://example.com/items", nil)
+	rec := c.StartCapture(req)
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"br"}},
+		Body:       io.NopCloser(bytes.NewReader(encoded)),
+	}
+	c.CaptureBody(rec, resp, nil)
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// The client still receives the encoded bytes
+	if sent, _ := io.ReadAll(resp.Body); !bytes.Equal(sent, encoded) {
+		t.Errorf("client received %q", sent)
+	}
+	_ = resp.Body.Close()
+
+	r := rec.Response
+	if r.IsBinary || string(BodyBytes(r.Body)) != plain {
+		t.Errorf("unexpected body: %v (binary %v)", r.Body, r.IsBinary)
+	}
+	if r.ContentEncoding != "br" || r.Size != int64(len(encoded)) || r.DecodedSize != int64(len(plain)) || r.Truncated {
+		t.Errorf("unexpected sizes: %+v", r)
+	}
+
+	// HAR keeps both sizes
+	entry := RecordToHAREntry(rec)
+	if entry.Response.Content.Size != int64(len(plain)) || entry.Response.BodySize != len(encoded) {
+		t.Errorf("unexpected HAR sizes: %+v", entry.Response)
+	}
+	if got := HAREntryToRecord(&entry).Response; got.Size != r.Size || got.DecodedSize != r.DecodedSize {
+		t.Errorf("sizes changed through HAR: %d, %d", got.Size, got.DecodedSize)
+	}
+}
+
+func TestCaptureEncodedBomb(t *testing.T) {
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 4096, MaxDecodedSize: 2048})
+	encoded := encode(t, "gzip", []byte(strings.Repeat("0", 1<<20)))
+	if len(encoded) > 4096 {
+		t.Fatalf("encoded body too large for the test: %d bytes", len(encoded))
+	}
+
+	rr := c.CaptureResponse(&http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Encoding": {"gzip"}},
+		Body:       io.NopCloser(bytes.NewReader(encoded)),
+	})
+	if !rr.Truncated || rr.DecodedSize != 0 || rr.Size != int64(len(encoded)) || len(rr.Body.(string)) != 2048 {
+		t.Errorf("unexpected capture of a bomb: truncated %v, decoded size %d, body %d bytes", rr.Truncated, rr.DecodedSize, len(rr.Body.(string)))
+	}
+}
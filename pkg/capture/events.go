@@ -0,0 +1,143 @@
+package capture
+
+import (
+	"mime"
+	"strings"
+	"time"
+)
+
+// StreamEvent is an event of a server-sent events (text/event-stream) or
+// NDJSON streaming response.
+type StreamEvent struct {
+	// ID is the id field of a server-sent event
+	ID string `json:"id,omitempty"`
+	// Event is the event type of a server-sent event
+	Event string `json:"event,omitempty"`
+	// Data is the data of a server-sent event (its data lines joined by
+	// newlines) or one line of an NDJSON stream
+	Data string `json:"data"`
+	// OffsetMs is the time from the start of the request until the event
+	// was complete
+	OffsetMs float64 `json:"offsetMs"`
+}
+
+// eventFormat is the framing of an event stream.
+type eventFormat int
+
+const (
+	eventFormatSSE eventFormat = iota + 1
+	eventFormatNDJSON
+)
+
+// eventStreamFormat returns the framing of a response with contentType, or
+// 0 when it is not an event stream.
+func eventStreamFormat(contentType string) eventFormat {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0
+	}
+	switch mediaType {
+	case "text/event-stream":
+		return eventFormatSSE
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return eventFormatNDJSON
+	}
+	return 0
+}
+
+// eventParser splits a stream into events as its bytes arrive, timing each
+// event from start.
+type eventParser struct {
+	format eventFormat
+	start  time.Time
+	events []StreamEvent
+
+	line []byte
+	// cr is true after a CR, so a following LF does not end another line
+	cr bool
+	// event is the server-sent event being read
+	event   StreamEvent
+	hasData bool
+}
+
+// newEventParser returns a parser for responses with contentType, or nil
+// when they are not event streams. Encoded streams are not parsed, since
+// they can only be decoded once they end.
+func newEventParser(contentType, contentEncoding string, start time.Time) *eventParser {
+	format := eventStreamFormat(contentType)
+	if format == 0 || contentEncoding != "" && !strings.EqualFold(contentEncoding, "identity") {
+		return nil
+	}
+	return &eventParser{format: format, start: start}
+}
+
+// write parses the next bytes of the stream.
+func (p *eventParser) write(b []byte) {
+	now := time.Now()
+	for _, c := range b {
+		switch {
+		case c == '\n' && p.cr:
+			p.cr = false
+		case c == '\n' || c == '\r':
+			p.cr = c == '\r'
+			p.endLine(now)
+		default:
+			p.cr = false
+			p.line = append(p.line, c)
+		}
+	}
+}
+
+// finish ends the stream. A final NDJSON line needs no newline, but an
+// unterminated server-sent event is discarded, as browsers do.
+func (p *eventParser) finish() {
+	if p.format == eventFormatNDJSON && len(p.line) > 0 {
+		p.endLine(time.Now())
+	}
+	p.line = nil
+}
+
+func (p *eventParser) endLine(now time.Time) {
+	line := string(p.line)
+	p.line = p.line[:0]
+
+	if p.format == eventFormatNDJSON {
+		if strings.TrimSpace(line) != "" {
+			p.add(StreamEvent{Data: line}, now)
+		}
+		return
+	}
+
+	// A blank line dispatches the event; events without data are dropped
+	if line == "" {
+		if p.hasData {
+			p.add(p.event, now)
+		}
+		p.event, p.hasData = StreamEvent{}, false
+		return
+	}
+	if strings.HasPrefix(line, ":") {
+		return
+	}
+	name, value, _ := strings.Cut(line, ":")
+	value = strings.TrimPrefix(value, " ")
+	switch name {
+	case "data":
+		if p.hasData {
+			p.event.Data += "\n"
+		}
+		p.event.Data += value
+		p.hasData = true
+	case "event":
+		p.event.Event = value
+	case "id":
+		if !strings.ContainsRune(value, 0) {
+			p.event.ID = value
+		}
+	}
+}
+
+func (p *eventParser) add(e StreamEvent, now time.Time) {
+	e.OffsetMs = float64(now.Sub(p.start).Microseconds()) / 1000.0
+	p.events = append(p.events, e)
+}
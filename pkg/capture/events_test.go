@@ -0,0 +1,121 @@
+package capture
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/redact"
+)
+
+// parseEvents feeds chunks to a parser and returns its events, ignoring
+// their timing.
+func parseEvents(contentType string, chunks ...string) []StreamEvent {
+	p := newEventParser(contentType, "", time.Now())
+	for _, chunk := range chunks {
+		p.write([]byte(chunk))
+	}
+	p.finish()
+	for i := range p.events {
+		p.events[i].OffsetMs = 0
+	}
+	return p.events
+}
+
+func TestEventParserSSE(t *testing.T) {
+	got := parseEvents("text/event-stream; charset=utf-8",
+		": keep-alive\n\n",
+		"id: 1\nevent: delta\ndata: {\"text\":\"Hel",
+		"lo\"}\n\n",
+		"data: first line\r",
+		"\ndata:second line\r\n\r\n",
+		"event: empty\n\n",
+		"data: unterminated",
+	)
+	want := []StreamEvent{
+		{ID: "1", Event: "delta", Data: `{"text":"Hello"}`},
+		{Data: "first line\nsecond line"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestEventParserNDJSON(t *testing.T) {
+	got := parseEvents("application/x-ndjson", `{"n":1}`+"\n\n"+`{"n"`, `:2}`+"\n"+`{"n":3}`)
+	want := []StreamEvent{{Data: `{"n":1}`}, {Data: `{"n":2}`}, {Data: `{"n":3}`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	for _, ct := range []string{"application/json", "text/plain", ""} {
+		if newEventParser(ct, "", time.Now()) != nil {
+			t.Errorf("%q parsed as an event stream", ct)
+		}
+	}
+	if newEventParser("text/event-stream", "gzip", time.Now()) != nil {
+		t.Error("encoded stream parsed as an event stream")
+	}
+}
+
+func TestCaptureBodyEvents(t *testing.T) {
+	r, err := redact.New(&redact.Config{JSON: []string{"$.token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 1024, Redactor: r})
+	req, _ := http.NewRequest("POST", "http://example.com/v1/chat", nil)
+	rec := c.StartCapture(req)
+
+	pr, pw := io.Pipe()
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"text/event-stream"}},
+		Body:       pr,
+	}
+	c.CaptureBody(rec, resp, nil)
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_, _ = pw.Write([]byte("event: start\ndata: {\"token\":\"secret\"}\n\n"))
+		time.Sleep(20 * time.Millisecond)
+		_, _ = pw.Write([]byte("id: 2\ndata: [DONE]\n\n"))
+		_ = pw.Close()
+	}()
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	recs := c.Records()
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	got := recs[0]
+	events := got.Response.Events
+	if len(events) != 2 || events[0].Event != "start" || events[1].ID != "2" || events[1].Data != "[DONE]" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[0].Data != `{"token":"`+redact.Placeholder+`"}` {
+		t.Errorf("event data not redacted: %s", events[0].Data)
+	}
+	if events[1].OffsetMs-events[0].OffsetMs < 20 {
+		t.Errorf("events not timed as they arrived: %+v", events)
+	}
+	if got.TTFBMs != events[0].OffsetMs {
+		t.Errorf("TTFBMs = %v, want the first event at %v", got.TTFBMs, events[0].OffsetMs)
+	}
+
+	// Events survive HAR output
+	entry := RecordToHAREntry(&got)
+	if len(entry.Response.Events) != 2 || entry.Response.Events[1].Time != events[1].OffsetMs {
+		t.Errorf("unexpected HAR events: %+v", entry.Response.Events)
+	}
+	if back := HAREntryToRecord(&entry); !reflect.DeepEqual(back.Response.Events, events) {
+		t.Errorf("events changed through HAR: %+v", back.Response.Events)
+	}
+}
@@ -27,6 +27,8 @@ type Filter struct {
 	MinStatusCode int
 	// MaxStatusCode is the maximum status code to include
 	MaxStatusCode int
+	// Predicate, if set, must also match complete records (see package query)
+	Predicate func(rec *Record) bool
 
 	// Compiled patterns (internal)
 	includeHostPatterns []*regexp.Regexp
@@ -131,6 +133,10 @@ func (f *Filter) Match(rec *Record) bool {
 		return false
 	}
 
+	if f.Predicate != nil && !f.Predicate(rec) {
+		return false
+	}
+
 	return true
 }
 
@@ -219,7 +225,7 @@ func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
 	result := make([]*regexp.Regexp, 0, len(patterns))
 
 	for _, pattern := range patterns {
-		re, err := wildcardToRegexp(pattern)
+		re, err := CompileWildcard(pattern)
 		if err != nil {
 			return nil, err
 		}
@@ -229,9 +235,9 @@ func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
 	return result, nil
 }
 
-// wildcardToRegexp converts a wildcard pattern to a regexp.
+// CompileWildcard converts a wildcard pattern to a regexp.
 // Supports * (match any characters) and ? (match single character).
-func wildcardToRegexp(pattern string) (*regexp.Regexp, error) {
+func CompileWildcard(pattern string) (*regexp.Regexp, error) {
 	// Escape special regex characters except * and ?
 	var result strings.Builder
 	result.WriteString("^")
@@ -325,7 +325,7 @@ func TestWildcardToRegexp(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.pattern+"_"+tt.input, func(t *testing.T) {
-			re, err := wildcardToRegexp(tt.pattern)
+			re, err := CompileWildcard(tt.pattern)
 			if err != nil {
 				t.Fatalf("failed to compile pattern: %v", err)
 			}
@@ -0,0 +1,287 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// gRPC message frame flags.
+const (
+	grpcFlagCompressed = 0x01
+	// grpcFlagTrailer marks the frame that carries trailers in gRPC-Web
+	grpcFlagTrailer = 0x80
+)
+
+// grpcFrameHeaderLen is the length of the flags byte and message length.
+const grpcFrameHeaderLen = 5
+
+// CompressedPlaceholder is recorded for messages compressed with an
+// encoding the capturer cannot decompress.
+const CompressedPlaceholder = "[compressed message]"
+
+// GRPCRecord holds the details of a gRPC call.
+type GRPCRecord struct {
+	// Service is the full service name (e.g. helloworld.Greeter)
+	Service string `json:"service"`
+	// Method is the method name (e.g. SayHello)
+	Method string `json:"method"`
+	// Status is the grpc-status code (0 is OK)
+	Status int `json:"status"`
+	// StatusName is the name of Status (e.g. NOT_FOUND)
+	StatusName string `json:"statusName,omitempty"`
+	// Message is the grpc-message error description
+	Message string `json:"message,omitempty"`
+	// Encoding is the grpc-encoding used to compress messages
+	Encoding string `json:"encoding,omitempty"`
+	// Web is true for gRPC-Web calls
+	Web bool `json:"web,omitempty"`
+	// RequestMessages is the number of messages sent by the client
+	RequestMessages int `json:"requestMessages"`
+	// ResponseMessages is the number of messages sent by the server
+	ResponseMessages int `json:"responseMessages"`
+	// Truncated is true if messages exceeded MaxBodySize and were dropped
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// FullMethod returns the call's method in /package.Service/Method form.
+func (g *GRPCRecord) FullMethod() string {
+	return "/" + g.Service + "/" + g.Method
+}
+
+// grpcStatusNames are the names of the gRPC status codes.
+var grpcStatusNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// GRPCStatusName returns the name of a gRPC status code.
+func GRPCStatusName(code int) string {
+	if code < 0 || code >= len(grpcStatusNames) {
+		return ""
+	}
+	return grpcStatusNames[code]
+}
+
+// IsGRPCContentType reports whether contentType is a gRPC or gRPC-Web type.
+func IsGRPCContentType(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "application/grpc")
+}
+
+// grpcCall is the capture state of an in-flight gRPC call.
+type grpcCall struct {
+	request  *bodyStream
+	response *bodyStream
+	// text is true for base64-encoded gRPC-Web (application/grpc-web-text)
+	text bool
+	// pending is true while the response body is still being relayed
+	pending bool
+}
+
+// startGRPC marks rec as a gRPC call and tees the request body so messages
+// are recorded as they are sent instead of being buffered up front, which
+// would stall streaming calls.
+func (c *Capturer) startGRPC(rec *Record, req *http.Request) {
+	contentType := strings.ToLower(req.Header.Get("Content-Type"))
+	service, method := ParseGRPCPath(req.URL.Path)
+
+	rec.Type = RecordTypeGRPC
+	rec.GRPC = &GRPCRecord{
+		Service:  service,
+		Method:   method,
+		Encoding: req.Header.Get("Grpc-Encoding"),
+		Web:      strings.HasPrefix(contentType, "application/grpc-web"),
+	}
+	rec.grpc = &grpcCall{text: strings.HasPrefix(contentType, "application/grpc-web-text")}
+
+	if c.config.IncludeBody && req.Body != nil && req.Body != http.NoBody {
+		rec.grpc.request = &bodyStream{limit: c.config.MaxBodySize}
+		req.Body = &teeBody{ReadCloser: req.Body, stream: rec.grpc.request}
+	}
+}
+
+// CaptureGRPC defers finishing a gRPC record until the response body has
+// been relayed, so that streamed messages and trailers are recorded. Call it
+// before FinishCapture, which then leaves the record open. onError, if
+// non-nil, receives errors from writing the record once the call ends.
+func (c *Capturer) CaptureGRPC(rec *Record, resp *http.Response, onError func(error)) {
+	if rec.grpc == nil || resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	rec.Response = c.CaptureResponse(resp)
+	rec.grpc.pending = true
+
+	body := &teeBody{ReadCloser: resp.Body}
+	if c.config.IncludeBody {
+		rec.grpc.response = &bodyStream{limit: c.config.MaxBodySize}
+		body.stream = rec.grpc.response
+	}
+	body.done = func() {
+		c.completeRecord(rec)
+		c.completeGRPC(rec, resp)
+		if err := c.finishRecord(rec); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	resp.Body = body
+}
+
+// completeGRPC records the messages and status of a finished call.
+func (c *Capturer) completeGRPC(rec *Record, resp *http.Response) {
+	call, g := rec.grpc, rec.GRPC
+
+	var trailer http.Header
+	if call.request != nil {
+		msgs, _, size, truncated := c.grpcMessages(call.request, g, false, g.Encoding, call.text)
+		g.RequestMessages = len(msgs)
+		g.Truncated = g.Truncated || truncated
+		rec.Request.Body = msgs
+		rec.Request.BodySize = size
+	}
+	if call.response != nil {
+		encoding := resp.Header.Get("Grpc-Encoding")
+		if encoding == "" {
+			encoding = g.Encoding
+		}
+		msgs, webTrailer, size, truncated := c.grpcMessages(call.response, g, true, encoding, call.text)
+		g.ResponseMessages = len(msgs)
+		g.Truncated = g.Truncated || truncated
+		rec.Response.Body = msgs
+		rec.Response.Size = size
+		trailer = webTrailer
+	}
+
+	// Status is in the trailers, in the headers of trailers-only responses,
+	// or in the trailer frame of gRPC-Web responses
+	for _, h := range []http.Header{resp.Trailer, resp.Header, trailer} {
+		if v := h.Get("Grpc-Status"); v != "" {
+			if code, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				g.Status = code
+				g.StatusName = GRPCStatusName(code)
+				g.Message = decodeGRPCMessage(h.Get("Grpc-Message"))
+			}
+			break
+		}
+	}
+}
+
+// grpcMessages decodes the messages of a stream whose compressed messages
+// use encoding. It returns the decoded
+// messages, the gRPC-Web trailers (if any), the bytes seen and whether
+// messages were dropped.
+func (c *Capturer) grpcMessages(s *bodyStream, g *GRPCRecord, response bool, encoding string, text bool) ([]interface{}, http.Header, int64, bool) {
+	data, size, truncated := s.snapshot()
+	if text {
+		data = decodeGRPCWebText(data)
+	}
+
+	msgs := []interface{}{}
+	var trailer http.Header
+	for len(data) >= grpcFrameHeaderLen {
+		flags := data[0]
+		length := int(binary.BigEndian.Uint32(data[1:grpcFrameHeaderLen]))
+		if length > len(data)-grpcFrameHeaderLen {
+			truncated = true
+			break
+		}
+		payload := data[grpcFrameHeaderLen : grpcFrameHeaderLen+length]
+		data = data[grpcFrameHeaderLen+length:]
+
+		if flags&grpcFlagTrailer != 0 {
+			trailer = parseGRPCWebTrailer(payload)
+			continue
+		}
+		if flags&grpcFlagCompressed != 0 {
+			var ok bool
+			if payload, ok = c.decompressGRPC(payload, encoding); !ok {
+				msgs = append(msgs, CompressedPlaceholder)
+				continue
+			}
+		}
+		msgs = append(msgs, c.config.Protos.Decode(g.FullMethod(), response, payload))
+	}
+	if len(data) > 0 {
+		truncated = true
+	}
+	return msgs, trailer, size, truncated
+}
+
+// ParseGRPCPath splits a /package.Service/Method path into service and
+// method. Prefixes in front of the service, as used by some gRPC-Web
+// gateways, are ignored.
+func ParseGRPCPath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// decompressGRPC decompresses a message compressed with encoding (gzip,
+// deflate or zstd), within the limit on decoded bytes.
+func (c *Capturer) decompressGRPC(payload []byte, encoding string) ([]byte, bool) {
+	if encoding == "" || encoding == "identity" {
+		return nil, false
+	}
+	limit := c.maxDecodedSize()
+	out, size, ok := decodeContent(payload, encoding, limit, limit)
+	if !ok || size < 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// decodeGRPCWebText decodes a grpc-web-text body, which may concatenate
+// separately padded base64 chunks.
+func decodeGRPCWebText(data []byte) []byte {
+	data = bytes.Join(bytes.Fields(data), nil)
+	out := make([]byte, 0, base64.StdEncoding.DecodedLen(len(data)))
+	buf := make([]byte, 3)
+	for len(data) >= 4 {
+		n, err := base64.StdEncoding.Decode(buf, data[:4])
+		if err != nil {
+			break
+		}
+		out = append(out, buf[:n]...)
+		data = data[4:]
+	}
+	return out
+}
+
+// parseGRPCWebTrailer parses the header block of a gRPC-Web trailer frame.
+func parseGRPCWebTrailer(payload []byte) http.Header {
+	r := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(payload), strings.NewReader("\r\n\r\n"))))
+	h, err := r.ReadMIMEHeader()
+	if err != nil && len(h) == 0 {
+		return nil
+	}
+	return http.Header(h)
+}
+
+// decodeGRPCMessage decodes the percent-encoding of grpc-message.
+func decodeGRPCMessage(msg string) string {
+	if !strings.Contains(msg, "%") {
+		return msg
+	}
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			if v, err := strconv.ParseUint(msg[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String()
+}
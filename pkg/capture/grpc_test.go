@@ -0,0 +1,153 @@
+package capture
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// grpcFrame frames a message with the gRPC length prefix.
+func grpcFrame(flags byte, msg []byte) []byte {
+	b := make([]byte, grpcFrameHeaderLen, grpcFrameHeaderLen+len(msg))
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// nameMessage encodes a message with field 1 set to name.
+func nameMessage(name string) []byte {
+	return append([]byte{0x0a, byte(len(name))}, name...)
+}
+
+func newGRPCCapturer(buf *bytes.Buffer) *Capturer {
+	return NewCapturer(&Config{Output: buf, Format: FormatNDJSON, IncludeHeaders: true, IncludeBody: true, MaxBodySize: 1024})
+}
+
+func TestCaptureGRPC(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := newGRPCCapturer(buf)
+
+	reqBody := append(grpcFrame(0, nameMessage("alice")), grpcFrame(0, nameMessage("bob"))...)
+	req := httptest.NewRequest(http.MethodPost, "https://api.example.com/test.v1.Greeter/SayHello", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	rec := c.StartCapture(req)
+	if rec.Type != RecordTypeGRPC || rec.GRPC.Service != "test.v1.Greeter" || rec.GRPC.Method != "SayHello" {
+		t.Fatalf("unexpected gRPC record: %s %+v", rec.Type, rec.GRPC)
+	}
+
+	// The request body is relayed unchanged while it is recorded
+	sent, _ := io.ReadAll(req.Body)
+	if !bytes.Equal(sent, reqBody) {
+		t.Error("request body was modified")
+	}
+
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	_, _ = zw.Write(nameMessage("hello alice"))
+	_ = zw.Close()
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/2.0",
+		Header:     http.Header{"Content-Type": {"application/grpc"}, "Grpc-Encoding": {"gzip"}},
+		Body:       io.NopCloser(bytes.NewReader(append(grpcFrame(0, nameMessage("hello bob")), grpcFrame(grpcFlagCompressed, zipped.Bytes())...))),
+		Trailer:    http.Header{"Grpc-Status": {"5"}, "Grpc-Message": {"not%20found"}},
+	}
+	var captureErr error
+	c.CaptureGRPC(rec, resp, func(err error) { captureErr = err })
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("record written before the response body was relayed")
+	}
+
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if captureErr != nil {
+		t.Fatal(captureErr)
+	}
+
+	recs, err := ReadNDJSON(buf)
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d (%v)", len(recs), err)
+	}
+	got := recs[0]
+	g := got.GRPC
+	if g.Status != 5 || g.StatusName != "NOT_FOUND" || g.Message != "not found" || g.RequestMessages != 2 || g.ResponseMessages != 2 {
+		t.Errorf("unexpected gRPC details: %+v", g)
+	}
+	reqJSON, _ := json.Marshal(got.Request.Body)
+	if want := `[[{"field":1,"type":"bytes","value":"alice"}],[{"field":1,"type":"bytes","value":"bob"}]]`; string(reqJSON) != want {
+		t.Errorf("unexpected request messages: %s", reqJSON)
+	}
+	respJSON, _ := json.Marshal(got.Response.Body)
+	if !strings.Contains(string(respJSON), `"hello alice"`) {
+		t.Errorf("expected decompressed response message, got %s", respJSON)
+	}
+	if got.Request.BodySize != int64(len(reqBody)) {
+		t.Errorf("unexpected request size %d", got.Request.BodySize)
+	}
+}
+
+func TestCaptureGRPCWeb(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := newGRPCCapturer(buf)
+
+	reqBody := base64.StdEncoding.EncodeToString(grpcFrame(0, nameMessage("alice")))
+	req := httptest.NewRequest(http.MethodPost, "https://api.example.com/rpc/test.v1.Greeter/SayHello", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/grpc-web-text")
+	rec := c.StartCapture(req)
+	_, _ = io.ReadAll(req.Body)
+
+	// Each frame is encoded separately, as streaming servers do
+	respBody := base64.StdEncoding.EncodeToString(grpcFrame(0, nameMessage("hi"))) +
+		base64.StdEncoding.EncodeToString(grpcFrame(grpcFlagTrailer, []byte("grpc-status: 3\r\ngrpc-message: bad name\r\n")))
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/grpc-web-text"}},
+		Body:       io.NopCloser(strings.NewReader(respBody)),
+	}
+	c.CaptureGRPC(rec, resp, nil)
+	_ = c.FinishCapture(rec, resp)
+	_, _ = io.ReadAll(resp.Body)
+
+	recs, err := ReadNDJSON(buf)
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d (%v)", len(recs), err)
+	}
+	g := recs[0].GRPC
+	if !g.Web || g.Service != "test.v1.Greeter" {
+		t.Errorf("unexpected gRPC-Web details: %+v", g)
+	}
+	if g.Status != 3 || g.Message != "bad name" || g.RequestMessages != 1 || g.ResponseMessages != 1 {
+		t.Errorf("unexpected gRPC-Web status: %+v", g)
+	}
+
+	// Trailers-only responses carry the status in their headers
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodPost, "https://api.example.com/test.v1.Greeter/SayHello", bytes.NewReader(grpcFrame(0, nil)))
+	req.Header.Set("Content-Type", "application/grpc")
+	rec = c.StartCapture(req)
+	resp = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/grpc"}, "Grpc-Status": {"12"}},
+		Body:       http.NoBody,
+	}
+	c.CaptureGRPC(rec, resp, nil)
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	recs, _ = ReadNDJSON(buf)
+	if len(recs) != 1 || recs[0].GRPC.StatusName != "UNIMPLEMENTED" {
+		t.Errorf("unexpected trailers-only record: %+v", recs)
+	}
+}
@@ -59,6 +59,18 @@ type HARResponse struct {
 	RedirectURL string      `json:"redirectURL"`
 	HeadersSize int         `json:"headersSize"`
 	BodySize    int         `json:"bodySize"`
+	// Events are the events of an event stream response, a custom field
+	// as HAR 1.2 allows for names starting with an underscore
+	Events []HAREvent `json:"_events,omitempty"`
+}
+
+// HAREvent represents a server-sent event or NDJSON line of a response.
+type HAREvent struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+	// Time is the time in milliseconds from the start of the request
+	Time float64 `json:"time"`
 }
 
 // HARHeader represents an HTTP header.
@@ -76,6 +88,8 @@ type HARCookie struct {
 	Expires  string `json:"expires,omitempty"`
 	HTTPOnly bool   `json:"httpOnly,omitempty"`
 	Secure   bool   `json:"secure,omitempty"`
+	// SameSite is a browser extension to HAR 1.2
+	SameSite string `json:"sameSite,omitempty"`
 }
 
 // HARQueryPair represents a query string parameter.
@@ -89,6 +103,7 @@ type HARPostData struct {
 	MimeType string         `json:"mimeType"`
 	Text     string         `json:"text,omitempty"`
 	Params   []HARPostParam `json:"params,omitempty"`
+	Comment  string         `json:"comment,omitempty"`
 }
 
 // HARPostParam represents a POST parameter.
@@ -101,12 +116,20 @@ type HARPostParam struct {
 
 // HARContent represents response content.
 type HARContent struct {
-	Size     int64  `json:"size"`
-	MimeType string `json:"mimeType"`
-	Text     string `json:"text,omitempty"`
-	Encoding string `json:"encoding,omitempty"`
+	Size int64 `json:"size"`
+	// Compression is the bytes saved by the Content-Encoding (Size less
+	// the response bodySize)
+	Compression int64  `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Comment     string `json:"comment,omitempty"`
 }
 
+// harTruncatedComment marks post data and content whose text holds only
+// the start of the body; the sizes are those of the whole body.
+const harTruncatedComment = "truncated"
+
 // HARCache represents cache information.
 type HARCache struct{}
 
@@ -168,43 +191,41 @@ func (w *HARWriter) Write() error {
 
 // recordToHAREntry converts a Record to a HAREntry.
 func (w *HARWriter) recordToHAREntry(rec *Record) HAREntry {
+	return RecordToHAREntry(rec)
+}
+
+// RecordToHAREntry converts a captured Record to a HAR 1.2 entry.
+func RecordToHAREntry(rec *Record) HAREntry {
 	entry := HAREntry{
 		StartedDateTime: rec.StartTime.Format(time.RFC3339Nano),
 		Time:            rec.DurationMs,
 		Request: HARRequest{
 			Method:      rec.Request.Method,
 			URL:         rec.Request.URL,
-			HTTPVersion: "HTTP/1.1",
-			Cookies:     []HARCookie{},
+			HTTPVersion: harHTTPVersion(rec.Request.HTTPVersion, ""),
+			Cookies:     cookiesToHAR(rec.Request.Cookies, rec.Request.Headers.Cookies),
 			Headers:     headersToHAR(rec.Request.Headers),
 			QueryString: queryToHAR(rec.Request.Query),
-			HeadersSize: -1,
+			HeadersSize: harSize(rec.Request.HeadersSize),
 			BodySize:    0,
 		},
 		Response: HARResponse{
 			Status:      rec.Response.Status,
 			StatusText:  rec.Response.StatusText,
-			HTTPVersion: "HTTP/1.1",
-			Cookies:     []HARCookie{},
+			HTTPVersion: harHTTPVersion(rec.Response.HTTPVersion, rec.Request.HTTPVersion),
+			Cookies:     cookiesToHAR(rec.Response.Cookies, rec.Response.Headers.SetCookies),
 			Headers:     headersToHAR(rec.Response.Headers),
 			Content: HARContent{
 				Size:     rec.Response.Size,
 				MimeType: rec.Response.ContentType,
 			},
-			RedirectURL: "",
-			HeadersSize: -1,
+			RedirectURL: rec.Response.RedirectURL,
+			HeadersSize: harSize(rec.Response.HeadersSize),
 			BodySize:    int(rec.Response.Size),
+			Events:      eventsToHAR(rec.Response.Events),
 		},
-		Cache: HARCache{},
-		Timings: HARTimings{
-			Blocked: -1,
-			DNS:     -1,
-			Connect: -1,
-			Send:    0,
-			Wait:    rec.DurationMs,
-			Receive: 0,
-			SSL:     -1,
-		},
+		Cache:   HARCache{},
+		Timings: timingsToHAR(rec),
 	}
 
 	// Add request body
@@ -215,38 +236,123 @@ func (w *HARWriter) recordToHAREntry(rec *Record) HAREntry {
 			Text:     bodyText,
 		}
 		entry.Request.BodySize = len(bodyText)
+		if rec.Request.Truncated {
+			entry.Request.PostData.Comment = harTruncatedComment
+		}
+		if rec.Request.Truncated || rec.Request.DecodedSize > 0 {
+			entry.Request.BodySize = int(rec.Request.BodySize)
+		}
+	}
+
+	// Content is decoded; bodySize stays the encoded size
+	if d := rec.Response.DecodedSize; d > 0 {
+		entry.Response.Content.Size = d
+		entry.Response.Content.Compression = d - rec.Response.Size
 	}
 
 	// Add response body
 	if rec.Response.Body != nil {
 		entry.Response.Content.Text = bodyToString(rec.Response.Body)
+		if rec.Response.Truncated {
+			entry.Response.Content.Comment = harTruncatedComment
+		}
 	}
 
 	return entry
 }
 
-// headersToHAR converts a header map to HAR headers.
-func headersToHAR(headers map[string]string) []HARHeader {
-	if headers == nil {
-		return []HARHeader{}
+// eventsToHAR converts stream events to HAR events.
+func eventsToHAR(events []StreamEvent) []HAREvent {
+	if events == nil {
+		return nil
+	}
+	result := make([]HAREvent, len(events))
+	for i, e := range events {
+		result[i] = HAREvent{ID: e.ID, Event: e.Event, Data: e.Data, Time: e.OffsetMs}
 	}
+	return result
+}
 
-	result := make([]HARHeader, 0, len(headers))
-	for name, value := range headers {
-		result = append(result, HARHeader{Name: name, Value: value})
+// timingsToHAR returns captured timing phases, or the whole duration as
+// wait time when no upstream trace is available.
+func timingsToHAR(rec *Record) HARTimings {
+	if t := rec.Timings; t != nil {
+		return HARTimings{
+			Blocked: t.Blocked,
+			DNS:     t.DNS,
+			Connect: t.Connect,
+			Send:    t.Send,
+			Wait:    t.Wait,
+			Receive: t.Receive,
+			SSL:     t.SSL,
+		}
+	}
+	return HARTimings{
+		Blocked: -1,
+		DNS:     -1,
+		Connect: -1,
+		Send:    0,
+		Wait:    rec.DurationMs,
+		Receive: 0,
+		SSL:     -1,
+	}
+}
+
+// harSize returns size, or -1 (unknown) when it was not captured.
+func harSize(size int64) int {
+	if size <= 0 {
+		return -1
+	}
+	return int(size)
+}
+
+// harHTTPVersion returns the first known protocol version, defaulting to
+// HTTP/1.1 for records captured before versions were recorded.
+func harHTTPVersion(versions ...string) string {
+	for _, v := range versions {
+		if v != "" {
+			return v
+		}
+	}
+	return "HTTP/1.1"
+}
+
+// cookiesToHAR converts parsed cookies to HAR cookies, parsing them from
+// the headers with parse for records written before cookies were recorded.
+func cookiesToHAR(cookies []Cookie, parse func() []Cookie) []HARCookie {
+	if cookies == nil {
+		cookies = parse()
+	}
+	result := make([]HARCookie, 0, len(cookies))
+	for _, c := range cookies {
+		result = append(result, HARCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite,
+		})
 	}
 	return result
 }
 
-// queryToHAR converts a query map to HAR query pairs.
-func queryToHAR(query map[string]string) []HARQueryPair {
-	if query == nil {
-		return []HARQueryPair{}
+// headersToHAR converts headers to HAR headers.
+func headersToHAR(headers Headers) []HARHeader {
+	result := make([]HARHeader, 0, len(headers))
+	for _, h := range headers {
+		result = append(result, HARHeader{Name: h.Name, Value: h.Value})
 	}
+	return result
+}
 
+// queryToHAR converts query parameters to HAR query pairs.
+func queryToHAR(query Query) []HARQueryPair {
 	result := make([]HARQueryPair, 0, len(query))
-	for name, value := range query {
-		result = append(result, HARQueryPair{Name: name, Value: value})
+	for _, p := range query {
+		result = append(result, HARQueryPair{Name: p.Name, Value: p.Value})
 	}
 	return result
 }
@@ -0,0 +1,194 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// harStreamHeader opens a HAR document; entries follow one per line.
+const harStreamHeader = `{"log":{"version":"1.2","creator":{"name":"OmniProxy","version":"0.1.0"},"entries":[` + "\n"
+
+// harStreamFooter closes the entries array, the log object and the document.
+const harStreamFooter = "]}}\n"
+
+// HARStreamWriter writes a HAR 1.2 document incrementally, one entry per
+// line. When backed by a file opened with OpenHARFile, the document is
+// closed after every entry so that the file is always valid HAR, and a file
+// left behind by a crash is repaired when it is reopened.
+type HARStreamWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	file    *os.File // non-nil when the footer is kept on disk after each entry
+	entries int
+	started bool
+	closed  bool
+}
+
+// NewHARStreamWriter creates a HAR writer for a non-seekable output such as
+// stdout. The document is completed by Close.
+func NewHARStreamWriter(w io.Writer) *HARStreamWriter {
+	return &HARStreamWriter{w: w}
+}
+
+// OpenHARFile opens path for streaming HAR output, creating it if needed.
+// Entries from a previous run are kept: a clean file is reopened in place and
+// a truncated one is cut back to its last complete entry and closed again.
+func OpenHARFile(path string) (*HARStreamWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644) //nolint:gosec // path is provided by the operator
+	if err != nil {
+		return nil, err
+	}
+
+	w := &HARStreamWriter{w: f, file: f}
+	if err := w.resume(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to resume HAR file %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// WriteRecord appends rec as a HAR entry. WebSocket frame records are
+// skipped because HAR has no standard representation for them.
+func (w *HARStreamWriter) WriteRecord(rec *Record) error {
+	if rec == nil || rec.Type == RecordTypeWebSocketFrame {
+		return nil
+	}
+
+	data, err := json.Marshal(RecordToHAREntry(rec))
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errors.New("HAR writer is closed")
+	}
+
+	var buf bytes.Buffer
+	if !w.started {
+		buf.WriteString(harStreamHeader)
+		w.started = true
+	}
+	if w.entries > 0 {
+		buf.WriteByte(',')
+	}
+	buf.Write(data)
+	buf.WriteByte('\n')
+
+	if w.file == nil {
+		_, err := w.w.Write(buf.Bytes())
+		if err == nil {
+			w.entries++
+		}
+		return err
+	}
+
+	// Keep the document closed on disk, then step back over the footer
+	buf.WriteString(harStreamFooter)
+	if _, err := w.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(-int64(len(harStreamFooter)), io.SeekCurrent); err != nil {
+		return err
+	}
+	w.entries++
+	return nil
+}
+
+// Close completes the HAR document and closes the underlying file, if any.
+func (w *HARStreamWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.file != nil {
+		return w.file.Close()
+	}
+
+	var buf bytes.Buffer
+	if !w.started {
+		buf.WriteString(harStreamHeader)
+	}
+	buf.WriteString(harStreamFooter)
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
+
+// Entries returns the number of entries in the document, including entries
+// kept from a previous run.
+func (w *HARStreamWriter) Entries() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.entries
+}
+
+// resume positions the file just before its footer, repairing it if needed.
+func (w *HARStreamWriter) resume() error {
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() == 0 {
+		if _, err := w.file.WriteString(harStreamHeader + harStreamFooter); err != nil {
+			return err
+		}
+		w.started = true
+		_, err := w.file.Seek(-int64(len(harStreamFooter)), io.SeekEnd)
+		return err
+	}
+
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(w.file)
+
+	header, err := r.ReadBytes('\n')
+	if err != nil || !bytes.HasPrefix(header, []byte(`{"log":`)) || !bytes.HasSuffix(header, []byte("[\n")) {
+		return errors.New("existing file is not a streaming HAR document")
+	}
+
+	// Keep every complete entry line; stop at the footer or the first
+	// partial line left by a crash.
+	good := int64(len(header))
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 || err != nil && !errors.Is(err, io.EOF) {
+			break
+		}
+		if line[len(line)-1] != '\n' {
+			break
+		}
+		entry := bytes.TrimPrefix(bytes.TrimSuffix(line, []byte("\n")), []byte(","))
+		if !bytes.HasPrefix(entry, []byte("{")) || !json.Valid(entry) {
+			break
+		}
+		w.entries++
+		good += int64(len(line))
+	}
+
+	if err := w.file.Truncate(good); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(good, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.file.WriteString(harStreamFooter); err != nil {
+		return err
+	}
+	w.started = true
+	_, err = w.file.Seek(good, io.SeekStart)
+	return err
+}
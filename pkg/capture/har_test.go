@@ -0,0 +1,275 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHARTestRecord(path string) *Record {
+	return &Record{
+		StartTime:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		DurationMs: 12.5,
+		Request: RequestRecord{
+			Method:      "GET",
+			URL:         "https://api.example.com" + path,
+			Host:        "api.example.com",
+			Path:        path,
+			Scheme:      "https",
+			Headers:     Headers{{Name: "cookie", Value: "session=abc; theme=dark"}},
+			HeadersSize: 120,
+		},
+		Response: ResponseRecord{
+			Status:      302,
+			StatusText:  "302 Found",
+			Headers:     Headers{{Name: "set-cookie", Value: "id=42; Path=/; HttpOnly; Secure"}},
+			HeadersSize: 80,
+			RedirectURL: "https://api.example.com/login",
+		},
+	}
+}
+
+func readHAR(t *testing.T, data []byte) HAR {
+	t.Helper()
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatalf("invalid HAR document: %v\n%s", err, data)
+	}
+	return har
+}
+
+func TestRecordToHAREntry(t *testing.T) {
+	rec := newHARTestRecord("/users")
+	rec.Timings = &Timings{Blocked: 1, DNS: 2, Connect: 5, SSL: 3, Send: 0.5, Wait: 10, Receive: 4}
+
+	entry := RecordToHAREntry(rec)
+
+	if len(entry.Request.Cookies) != 2 || entry.Request.Cookies[1].Name != "theme" {
+		t.Errorf("unexpected request cookies: %+v", entry.Request.Cookies)
+	}
+	if len(entry.Response.Cookies) != 1 || !entry.Response.Cookies[0].HTTPOnly || entry.Response.Cookies[0].Path != "/" {
+		t.Errorf("unexpected response cookies: %+v", entry.Response.Cookies)
+	}
+	if entry.Request.HeadersSize != 120 || entry.Response.HeadersSize != 80 {
+		t.Errorf("unexpected headers sizes: %d, %d", entry.Request.HeadersSize, entry.Response.HeadersSize)
+	}
+	if entry.Response.RedirectURL != "https://api.example.com/login" {
+		t.Errorf("unexpected redirectURL: %s", entry.Response.RedirectURL)
+	}
+	if entry.Timings.DNS != 2 || entry.Timings.SSL != 3 || entry.Timings.Wait != 10 {
+		t.Errorf("unexpected timings: %+v", entry.Timings)
+	}
+
+	// Without a trace the whole duration is reported as wait time
+	entry = RecordToHAREntry(&Record{DurationMs: 7})
+	if entry.Timings.Wait != 7 || entry.Timings.DNS != -1 || entry.Request.HeadersSize != -1 {
+		t.Errorf("unexpected fallback timings: %+v", entry.Timings)
+	}
+	if entry.Request.HTTPVersion != "HTTP/1.1" || entry.Response.HTTPVersion != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1 fallback, got %s, %s", entry.Request.HTTPVersion, entry.Response.HTTPVersion)
+	}
+
+	// Recorded protocol versions are reported; responses fall back to the request's
+	rec.Request.HTTPVersion = "HTTP/2.0"
+	entry = RecordToHAREntry(rec)
+	if entry.Request.HTTPVersion != "HTTP/2.0" || entry.Response.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("expected HTTP/2.0, got %s, %s", entry.Request.HTTPVersion, entry.Response.HTTPVersion)
+	}
+	rec.Response.HTTPVersion = "HTTP/1.1"
+	if entry = RecordToHAREntry(rec); entry.Response.HTTPVersion != "HTTP/1.1" {
+		t.Errorf("expected response HTTP/1.1, got %s", entry.Response.HTTPVersion)
+	}
+}
+
+func TestHARStreamWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewHARStreamWriter(buf)
+
+	for _, p := range []string{"/a", "/b"} {
+		if err := w.WriteRecord(newHARTestRecord(p)); err != nil {
+			t.Fatalf("WriteRecord failed: %v", err)
+		}
+	}
+	// Frames are not HAR entries
+	if err := w.WriteRecord(&Record{Type: RecordTypeWebSocketFrame}); err != nil {
+		t.Fatalf("WriteRecord failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	har := readHAR(t, buf.Bytes())
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 2 {
+		t.Fatalf("unexpected HAR: version=%s entries=%d", har.Log.Version, len(har.Log.Entries))
+	}
+	if har.Log.Entries[1].Request.URL != "https://api.example.com/b" {
+		t.Errorf("unexpected entry order: %s", har.Log.Entries[1].Request.URL)
+	}
+
+	// An empty capture is still a valid document
+	empty := &bytes.Buffer{}
+	if err := NewHARStreamWriter(empty).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(readHAR(t, empty.Bytes()).Log.Entries) != 0 {
+		t.Error("expected no entries")
+	}
+}
+
+func TestHARCapturerFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewCapturer(&Config{Output: buf, Format: FormatHAR, MaxBodySize: 1024})
+
+	req, _ := http.NewRequest("GET", "https://api.example.com/test", nil)
+	rec := c.StartCapture(req)
+	if err := c.FinishCapture(rec, &http.Response{StatusCode: 200}); err != nil {
+		t.Fatalf("FinishCapture failed: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(readHAR(t, buf.Bytes()).Log.Entries) != 1 {
+		t.Error("expected one HAR entry")
+	}
+}
+
+func TestOpenHARFileResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.har")
+
+	w, err := OpenHARFile(path)
+	if err != nil {
+		t.Fatalf("OpenHARFile failed: %v", err)
+	}
+	if err := w.WriteRecord(newHARTestRecord("/first")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is valid HAR while the writer is still open
+	data, _ := os.ReadFile(path)
+	if len(readHAR(t, data).Log.Entries) != 1 {
+		t.Error("expected file to be valid with one entry before close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening appends to the existing document
+	w, err = OpenHARFile(path)
+	if err != nil {
+		t.Fatalf("OpenHARFile (resume) failed: %v", err)
+	}
+	if w.Entries() != 1 {
+		t.Errorf("expected 1 existing entry, got %d", w.Entries())
+	}
+	if err := w.WriteRecord(newHARTestRecord("/second")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(path)
+	if entries := readHAR(t, data).Log.Entries; len(entries) != 2 || entries[1].Request.URL != "https://api.example.com/second" {
+		t.Errorf("unexpected entries after resume: %d", len(entries))
+	}
+}
+
+func TestOpenHARFileRepairsCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.har")
+
+	w, err := OpenHARFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(newHARTestRecord("/kept")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of writing the next entry
+	data, _ := os.ReadFile(path)
+	data = bytes.TrimSuffix(data, []byte(harStreamFooter))
+	data = append(data, []byte(`,{"startedDateTime":"2026-01-02T03:04`)...)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = OpenHARFile(path)
+	if err != nil {
+		t.Fatalf("OpenHARFile (repair) failed: %v", err)
+	}
+	if err := w.WriteRecord(newHARTestRecord("/after")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(path)
+	entries := readHAR(t, data).Log.Entries
+	if len(entries) != 2 || !strings.HasSuffix(entries[0].Request.URL, "/kept") || !strings.HasSuffix(entries[1].Request.URL, "/after") {
+		t.Errorf("unexpected entries after repair: %+v", entries)
+	}
+}
+
+func TestOpenHARFileRejectsForeignFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.har")
+	if err := os.WriteFile(path, []byte("hello\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenHARFile(path); err == nil {
+		t.Error("expected error for a file that is not a streaming HAR document")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "hello\n" {
+		t.Error("foreign file must not be modified")
+	}
+}
+
+func TestTraceRequestTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/next", http.StatusFound)
+	}))
+	defer server.Close()
+
+	c := NewCapturer(&Config{IncludeBody: true, MaxBodySize: 1024})
+	req, _ := http.NewRequest("GET", server.URL+"/start", nil)
+	rec := c.StartCapture(req)
+	req = c.TraceRequest(rec, req)
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if rec.Timings == nil {
+		t.Fatal("expected timings to be captured")
+	}
+	if rec.Timings.Connect < 0 || rec.Timings.SSL != -1 {
+		t.Errorf("expected a new plain-text connection, got %+v", rec.Timings)
+	}
+	if rec.TTFBMs <= 0 {
+		t.Errorf("expected positive TTFB, got %f", rec.TTFBMs)
+	}
+	if rec.Response.RedirectURL != server.URL+"/next" {
+		t.Errorf("unexpected redirect URL: %s", rec.Response.RedirectURL)
+	}
+	if rec.Request.HeadersSize <= 0 || rec.Response.HeadersSize <= 0 {
+		t.Errorf("expected header sizes, got %d and %d", rec.Request.HeadersSize, rec.Response.HeadersSize)
+	}
+}
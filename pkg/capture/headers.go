@@ -0,0 +1,375 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Header is a captured header field.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Headers is an ordered list of header fields. A header sent more than once,
+// such as Set-Cookie, has one field per value. Names keep the casing they
+// were captured with; lookups ignore case.
+type Headers []Header
+
+// HeadersFromHTTP converts h to Headers. net/http does not keep the order
+// headers arrive in, so fields are sorted by name, with the values of each
+// name in the order they were received.
+func HeadersFromHTTP(h http.Header) Headers {
+	if len(h) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make(Headers, 0, len(h))
+	for _, name := range names {
+		for _, v := range h[name] {
+			headers = append(headers, Header{Name: name, Value: v})
+		}
+	}
+	return headers
+}
+
+// Get returns the first value of the named header, or "" when it is absent.
+func (h Headers) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values returns every value of the named header, in order.
+func (h Headers) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// Has reports whether the named header is present.
+func (h Headers) Has(name string) bool {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTP converts h to an http.Header.
+func (h Headers) HTTP() http.Header {
+	header := make(http.Header, len(h))
+	for _, f := range h {
+		header[f.Name] = append(header[f.Name], f.Value)
+	}
+	return header
+}
+
+// Map returns the first value of each header by lowercase name, for stores
+// and views that keep a single value per header.
+func (h Headers) Map() map[string]string {
+	if h == nil {
+		return nil
+	}
+	m := make(map[string]string, len(h))
+	for _, f := range h {
+		name := strings.ToLower(f.Name)
+		if _, ok := m[name]; !ok {
+			m[name] = f.Value
+		}
+	}
+	return m
+}
+
+// Multi returns the values of each header by lowercase name.
+func (h Headers) Multi() map[string][]string {
+	if h == nil {
+		return nil
+	}
+	m := make(map[string][]string, len(h))
+	for _, f := range h {
+		name := strings.ToLower(f.Name)
+		m[name] = append(m[name], f.Value)
+	}
+	return m
+}
+
+// Names returns the name of each field, in order. Together with Multi it
+// describes h completely (see HeadersFromMulti).
+func (h Headers) Names() []string {
+	if h == nil {
+		return nil
+	}
+	names := make([]string, len(h))
+	for i, f := range h {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// HeadersFromMulti rebuilds Headers from the values of each lowercase name
+// and, when known, the name of each field in order (see Headers.Names).
+// Without names, fields are sorted by name. Values not covered by names are
+// appended.
+func HeadersFromMulti(values map[string][]string, names []string) Headers {
+	if values == nil {
+		return nil
+	}
+	remaining := make(map[string][]string, len(values))
+	for name, vs := range values {
+		name = strings.ToLower(name)
+		remaining[name] = append(remaining[name], vs...)
+	}
+
+	headers := make(Headers, 0, len(names))
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if vs := remaining[key]; len(vs) > 0 {
+			headers = append(headers, Header{Name: name, Value: vs[0]})
+			remaining[key] = vs[1:]
+		}
+	}
+
+	rest := make([]string, 0, len(remaining))
+	for name, vs := range remaining {
+		if len(vs) > 0 {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		for _, v := range remaining[name] {
+			headers = append(headers, Header{Name: name, Value: v})
+		}
+	}
+	return headers
+}
+
+// Cookies parses the Cookie headers. Malformed cookies are skipped.
+func (h Headers) Cookies() []Cookie {
+	req := http.Request{Header: http.Header{"Cookie": h.Values("Cookie")}}
+	var cookies []Cookie
+	for _, c := range req.Cookies() {
+		cookies = append(cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
+
+// SetCookies parses the Set-Cookie headers. Malformed cookies are skipped.
+func (h Headers) SetCookies() []Cookie {
+	resp := http.Response{Header: http.Header{"Set-Cookie": h.Values("Set-Cookie")}}
+	var cookies []Cookie
+	for _, c := range resp.Cookies() {
+		cookies = append(cookies, cookieFromHTTP(c))
+	}
+	return cookies
+}
+
+// UnmarshalJSON decodes a list of fields, or the object of lowercase names
+// to single values written by earlier versions.
+func (h *Headers) UnmarshalJSON(data []byte) error {
+	var fields []Header
+	if err := unmarshalPairs(data, &fields, func(name, value string) {
+		fields = append(fields, Header{Name: name, Value: value})
+	}); err != nil {
+		return fmt.Errorf("failed to decode headers: %w", err)
+	}
+	*h = fields
+	return nil
+}
+
+// QueryParam is a captured query string parameter.
+type QueryParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Query is an ordered list of query parameters. A parameter given more than
+// once has one entry per value. Names are case-sensitive.
+type Query []QueryParam
+
+// ParseQuery parses a raw query string, keeping the order and repeats of its
+// parameters. Parameters that fail to decode are kept as they are.
+func ParseQuery(rawQuery string) Query {
+	var query Query
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		query = append(query, QueryParam{Name: name, Value: value})
+	}
+	return query
+}
+
+// Get returns the first value of the named parameter, or "" when it is absent.
+func (q Query) Get(name string) string {
+	for _, p := range q {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Values returns every value of the named parameter, in order.
+func (q Query) Values(name string) []string {
+	var values []string
+	for _, p := range q {
+		if p.Name == name {
+			values = append(values, p.Value)
+		}
+	}
+	return values
+}
+
+// URLValues converts q to url.Values.
+func (q Query) URLValues() url.Values {
+	values := make(url.Values, len(q))
+	for _, p := range q {
+		values[p.Name] = append(values[p.Name], p.Value)
+	}
+	return values
+}
+
+// Encode returns q as a URL-encoded query string, in order.
+func (q Query) Encode() string {
+	var b strings.Builder
+	for i, p := range q {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(p.Name))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(p.Value))
+	}
+	return b.String()
+}
+
+// Map returns the first value of each parameter, for stores and views that
+// keep a single value per parameter.
+func (q Query) Map() map[string]string {
+	if q == nil {
+		return nil
+	}
+	m := make(map[string]string, len(q))
+	for _, p := range q {
+		if _, ok := m[p.Name]; !ok {
+			m[p.Name] = p.Value
+		}
+	}
+	return m
+}
+
+// UnmarshalJSON decodes a list of parameters, or the object of names to
+// single values written by earlier versions.
+func (q *Query) UnmarshalJSON(data []byte) error {
+	var params []QueryParam
+	if err := unmarshalPairs(data, &params, func(name, value string) {
+		params = append(params, QueryParam{Name: name, Value: value})
+	}); err != nil {
+		return fmt.Errorf("failed to decode query: %w", err)
+	}
+	*q = params
+	return nil
+}
+
+// unmarshalPairs decodes a JSON list into list, or calls add for each
+// value of a JSON object of names to strings or string lists (sorted by
+// name, since objects are unordered).
+func unmarshalPairs(data []byte, list interface{}, add func(name, value string)) error {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		return nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		return json.Unmarshal(data, list)
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var values []string
+		if err := json.Unmarshal(obj[name], &values); err != nil {
+			var value string
+			if err := json.Unmarshal(obj[name], &value); err != nil {
+				return err
+			}
+			values = []string{value}
+		}
+		for _, v := range values {
+			add(name, v)
+		}
+	}
+	return nil
+}
+
+// Cookie is a cookie parsed from a Cookie or Set-Cookie header. Request
+// cookies have only a name and value.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+// cookieFromHTTP converts a parsed Set-Cookie header.
+func cookieFromHTTP(c *http.Cookie) Cookie {
+	cookie := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		MaxAge:   c.MaxAge,
+		HTTPOnly: c.HttpOnly,
+		Secure:   c.Secure,
+	}
+	if !c.Expires.IsZero() {
+		cookie.Expires = c.Expires.UTC().Format(time.RFC3339)
+	}
+	switch c.SameSite {
+	case http.SameSiteLaxMode:
+		cookie.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		cookie.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		cookie.SameSite = "None"
+	}
+	return cookie
+}
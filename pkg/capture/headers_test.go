@@ -0,0 +1,167 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeadersFromHTTP(t *testing.T) {
+	h := HeadersFromHTTP(http.Header{
+		"X-B":        {"2"},
+		"Set-Cookie": {"a=1", "b=2"},
+		"x-lower":    {"v"},
+	})
+	want := Headers{
+		{Name: "Set-Cookie", Value: "a=1"},
+		{Name: "Set-Cookie", Value: "b=2"},
+		{Name: "X-B", Value: "2"},
+		{Name: "x-lower", Value: "v"},
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+
+	if h.Get("set-cookie") != "a=1" || !reflect.DeepEqual(h.Values("SET-COOKIE"), []string{"a=1", "b=2"}) || !h.Has("X-Lower") {
+		t.Errorf("unexpected lookups on %v", h)
+	}
+	if h.Get("missing") != "" || h.Has("missing") {
+		t.Error("expected missing headers to be absent")
+	}
+	if h.Map()["set-cookie"] != "a=1" || len(h.Multi()["set-cookie"]) != 2 {
+		t.Errorf("unexpected maps: %v, %v", h.Map(), h.Multi())
+	}
+
+	// Names and Multi restore the fields in order
+	if got := HeadersFromMulti(h.Multi(), h.Names()); !reflect.DeepEqual(got, h) {
+		t.Errorf("HeadersFromMulti = %v, want %v", got, h)
+	}
+	// Without names, fields are sorted by lowercase name
+	got := HeadersFromMulti(map[string][]string{"x-b": {"2"}, "accept": {"a", "b"}}, nil)
+	if !reflect.DeepEqual(got, Headers{{Name: "accept", Value: "a"}, {Name: "accept", Value: "b"}, {Name: "x-b", Value: "2"}}) {
+		t.Errorf("unexpected headers without names: %v", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery("b=2&a=1&b=3&flag&Name=x%20y&bad=%zz")
+	want := Query{
+		{Name: "b", Value: "2"},
+		{Name: "a", Value: "1"},
+		{Name: "b", Value: "3"},
+		{Name: "flag", Value: ""},
+		{Name: "Name", Value: "x y"},
+		{Name: "bad", Value: "%zz"},
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Fatalf("unexpected query: %v", q)
+	}
+	if q.Get("b") != "2" || len(q.Values("b")) != 2 || q.Get("name") != "" {
+		t.Errorf("unexpected lookups on %v", q)
+	}
+	if q.Encode() != "b=2&a=1&b=3&flag=&Name=x+y&bad=%25zz" {
+		t.Errorf("unexpected encoding: %s", q.Encode())
+	}
+	if ParseQuery("") != nil {
+		t.Error("expected no parameters for an empty query")
+	}
+}
+
+func TestHeadersLegacyJSON(t *testing.T) {
+	var rec Record
+	data := `{"request":{"headers":{"x-b":"2","accept":"*/*"},"query":{"page":"1"}},"response":{"headers":{"vary":["a","b"]}}}`
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rec.Request.Headers, Headers{{Name: "accept", Value: "*/*"}, {Name: "x-b", Value: "2"}}) {
+		t.Errorf("unexpected request headers: %v", rec.Request.Headers)
+	}
+	if !reflect.DeepEqual(rec.Request.Query, Query{{Name: "page", Value: "1"}}) {
+		t.Errorf("unexpected query: %v", rec.Request.Query)
+	}
+	if !reflect.DeepEqual(rec.Response.Headers.Values("Vary"), []string{"a", "b"}) {
+		t.Errorf("unexpected response headers: %v", rec.Response.Headers)
+	}
+}
+
+func TestCookies(t *testing.T) {
+	h := Headers{
+		{Name: "Cookie", Value: "a=1; b=2"},
+		{Name: "Cookie", Value: "c=3"},
+		{Name: "Set-Cookie", Value: "id=42; Path=/; Domain=example.com; Max-Age=60; HttpOnly; Secure; SameSite=Lax"},
+		{Name: "Set-Cookie", Value: "old=; Expires=Thu, 01 Jan 1970 00:00:00 GMT"},
+	}
+	if got := h.Cookies(); !reflect.DeepEqual(got, []Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}, {Name: "c", Value: "3"}}) {
+		t.Errorf("unexpected cookies: %+v", got)
+	}
+	want := []Cookie{
+		{Name: "id", Value: "42", Path: "/", Domain: "example.com", MaxAge: 60, HTTPOnly: true, Secure: true, SameSite: "Lax"},
+		{Name: "old", Expires: "1970-01-01T00:00:00Z"},
+	}
+	if got := h.SetCookies(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected set-cookies: %+v", got)
+	}
+}
+
+// roundTripRecord captures a request and response that repeat headers,
+// query parameters and cookies.
+func roundTripRecord(t *testing.T, format Format) (*Record, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	c := NewCapturer(&Config{Output: buf, Format: format, IncludeHeaders: true})
+
+	req, _ := http.NewRequest("GET", "https://api.example.com/items?tag=b&tag=a&page=1", nil)
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Set("Cookie", "session=abc; theme=dark")
+	rec := c.StartCapture(req)
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header: http.Header{
+			"Set-Cookie": {"a=1; Path=/; Max-Age=60", "b=2; HttpOnly; SameSite=Strict"},
+			"Vary":       {"Accept"},
+		},
+		Body: io.NopCloser(strings.NewReader("")),
+	}
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return rec, buf
+}
+
+func TestHeadersRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatNDJSON, FormatHAR, FormatIR} {
+		t.Run(string(format), func(t *testing.T) {
+			rec, buf := roundTripRecord(t, format)
+			if len(rec.Request.Headers.Values("Accept")) != 2 || len(rec.Response.Cookies) != 2 {
+				t.Fatalf("unexpected capture: %+v", rec)
+			}
+			if q := rec.Request.Query; len(q) != 3 || q[0] != (QueryParam{Name: "tag", Value: "b"}) {
+				t.Fatalf("unexpected query: %v", q)
+			}
+
+			recs, err := ReadRecords(buf)
+			if err != nil || len(recs) != 1 {
+				t.Fatalf("ReadRecords: %v (%d records)", err, len(recs))
+			}
+			got := recs[0]
+			if !reflect.DeepEqual(got.Request.Headers, rec.Request.Headers) || !reflect.DeepEqual(got.Response.Headers, rec.Response.Headers) {
+				t.Errorf("headers changed:\n got %v / %v\nwant %v / %v", got.Request.Headers, got.Response.Headers, rec.Request.Headers, rec.Response.Headers)
+			}
+			if !reflect.DeepEqual(got.Request.Query, rec.Request.Query) {
+				t.Errorf("query changed: %v, want %v", got.Request.Query, rec.Request.Query)
+			}
+			if !reflect.DeepEqual(got.Request.Cookies, rec.Request.Cookies) || !reflect.DeepEqual(got.Response.Cookies, rec.Response.Cookies) {
+				t.Errorf("cookies changed: %+v / %+v", got.Request.Cookies, got.Response.Cookies)
+			}
+		})
+	}
+}
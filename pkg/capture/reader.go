@@ -0,0 +1,223 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ReadRecords reads captured traffic from r, detecting whether it holds a
+// HAR document or NDJSON/JSON records.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	br := bufio.NewReader(r)
+	if isHAR(br) {
+		return ReadHAR(br)
+	}
+	return ReadNDJSON(br)
+}
+
+// ReadRecordsFile reads captured traffic from a HAR, NDJSON or JSON file.
+func ReadRecordsFile(path string) ([]*Record, error) {
+	f, err := os.Open(path) //nolint:gosec // path is provided by the operator
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	recs, err := ReadRecords(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return recs, nil
+}
+
+// ReadNDJSON reads records written in the NDJSON, JSON or IR formats.
+func ReadNDJSON(r io.Reader) ([]*Record, error) {
+	dec := json.NewDecoder(r)
+	var recs []*Record
+	for {
+		rec := &Record{}
+		if err := dec.Decode(rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return recs, nil
+			}
+			return recs, fmt.Errorf("record %d: %w", len(recs)+1, err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// ReadHAR reads a HAR document and converts its entries to records.
+func ReadHAR(r io.Reader) ([]*Record, error) {
+	var har HAR
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, err
+	}
+	recs := make([]*Record, 0, len(har.Log.Entries))
+	for i := range har.Log.Entries {
+		recs = append(recs, HAREntryToRecord(&har.Log.Entries[i]))
+	}
+	return recs, nil
+}
+
+// HAREntryToRecord converts a HAR entry back into a captured Record.
+func HAREntryToRecord(entry *HAREntry) *Record {
+	rec := &Record{
+		DurationMs: entry.Time,
+		Request: RequestRecord{
+			Method:  entry.Request.Method,
+			URL:     entry.Request.URL,
+			Headers: headersFromHAR(entry.Request.Headers),
+		},
+		Response: ResponseRecord{
+			Status:      entry.Response.Status,
+			StatusText:  entry.Response.StatusText,
+			Headers:     headersFromHAR(entry.Response.Headers),
+			ContentType: entry.Response.Content.MimeType,
+			Size:        entry.Response.Content.Size,
+			RedirectURL: entry.Response.RedirectURL,
+			Events:      eventsFromHAR(entry.Response.Events),
+		},
+	}
+
+	// Bodies were recorded decoded; content size less compression is the
+	// encoded size
+	rec.Request.ContentEncoding = rec.Request.Headers.Get("Content-Encoding")
+	rec.Response.ContentEncoding = rec.Response.Headers.Get("Content-Encoding")
+	if c := entry.Response.Content; c.Compression != 0 {
+		rec.Response.DecodedSize = c.Size
+		rec.Response.Size = c.Size - c.Compression
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime); err == nil {
+		rec.StartTime = t
+		rec.EndTime = t.Add(time.Duration(entry.Time * float64(time.Millisecond)))
+	}
+
+	if u, err := url.Parse(entry.Request.URL); err == nil {
+		rec.Request.Host = u.Host
+		rec.Request.Path = u.Path
+		rec.Request.Scheme = u.Scheme
+	}
+
+	for _, q := range entry.Request.QueryString {
+		rec.Request.Query = append(rec.Request.Query, QueryParam{Name: q.Name, Value: q.Value})
+	}
+
+	// Cookies are parsed from the headers when present, since HAR cookies
+	// leave out attributes such as Max-Age
+	rec.Request.Cookies = rec.Request.Headers.Cookies()
+	if rec.Request.Cookies == nil {
+		rec.Request.Cookies = cookiesFromHAR(entry.Request.Cookies)
+	}
+	rec.Response.Cookies = rec.Response.Headers.SetCookies()
+	if rec.Response.Cookies == nil {
+		rec.Response.Cookies = cookiesFromHAR(entry.Response.Cookies)
+	}
+
+	if pd := entry.Request.PostData; pd != nil && pd.Text != "" {
+		rec.Request.ContentType = pd.MimeType
+		rec.Request.Body = decodeBody([]byte(pd.Text), pd.MimeType)
+		rec.Request.BodySize = int64(len(pd.Text))
+		switch {
+		case pd.Comment == harTruncatedComment:
+			rec.Request.Body = pd.Text
+			rec.Request.BodySize = max(int64(entry.Request.BodySize), rec.Request.BodySize)
+			rec.Request.Truncated = true
+		case rec.Request.ContentEncoding != "" && entry.Request.BodySize > 0:
+			rec.Request.DecodedSize = rec.Request.BodySize
+			rec.Request.BodySize = int64(entry.Request.BodySize)
+		}
+	}
+	if ct := rec.Request.Headers.Get("Content-Type"); ct != "" && rec.Request.ContentType == "" {
+		rec.Request.ContentType = ct
+	}
+
+	if c := entry.Response.Content; c.Text != "" {
+		if c.Encoding == "base64" {
+			if data, err := base64.StdEncoding.DecodeString(c.Text); err == nil {
+				rec.Response.Body = string(data)
+			}
+		} else {
+			rec.Response.Body = decodeBody([]byte(c.Text), c.MimeType)
+		}
+		if c.Comment == harTruncatedComment {
+			rec.Response.Truncated = true
+			if c.Encoding != "base64" {
+				rec.Response.Body = c.Text
+			}
+		}
+	}
+
+	return rec
+}
+
+// BodyBytes returns the wire form of a captured body: strings are used as-is
+// and parsed JSON is re-encoded. It returns nil for empty bodies.
+func BodyBytes(body interface{}) []byte {
+	if body == nil {
+		return nil
+	}
+	return []byte(bodyToString(body))
+}
+
+// eventsFromHAR converts HAR events to stream events.
+func eventsFromHAR(events []HAREvent) []StreamEvent {
+	if events == nil {
+		return nil
+	}
+	result := make([]StreamEvent, len(events))
+	for i, e := range events {
+		result[i] = StreamEvent{ID: e.ID, Event: e.Event, Data: e.Data, OffsetMs: e.Time}
+	}
+	return result
+}
+
+// headersFromHAR converts HAR headers, keeping their order and casing.
+func headersFromHAR(headers []HARHeader) Headers {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make(Headers, len(headers))
+	for i, h := range headers {
+		result[i] = Header{Name: h.Name, Value: h.Value}
+	}
+	return result
+}
+
+// cookiesFromHAR converts HAR cookies.
+func cookiesFromHAR(cookies []HARCookie) []Cookie {
+	if len(cookies) == 0 {
+		return nil
+	}
+	result := make([]Cookie, len(cookies))
+	for i, c := range cookies {
+		result[i] = Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite,
+		}
+	}
+	return result
+}
+
+// isHAR peeks at the start of the stream for a HAR "log" object.
+func isHAR(br *bufio.Reader) bool {
+	head, _ := br.Peek(512)
+	head = bytes.TrimLeft(head, " \t\r\n")
+	head = bytes.TrimPrefix(head, []byte("{"))
+	head = bytes.TrimLeft(head, " \t\r\n")
+	return bytes.HasPrefix(head, []byte(`"log"`))
+}
@@ -0,0 +1,78 @@
+package capture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadRecordsNDJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewCapturer(&Config{Output: buf, Format: FormatNDJSON})
+	for _, p := range []string{"/a", "/b"} {
+		rec := newHARTestRecord(p)
+		rec.Request.Body = map[string]interface{}{"n": float64(1)}
+		if err := c.writeRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs, err := ReadRecords(buf)
+	if err != nil {
+		t.Fatalf("ReadRecords failed: %v", err)
+	}
+	if len(recs) != 2 || recs[1].Request.Path != "/b" {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+	if string(BodyBytes(recs[0].Request.Body)) != `{"n":1}` {
+		t.Errorf("unexpected body bytes: %s", BodyBytes(recs[0].Request.Body))
+	}
+
+	if _, err := ReadRecords(strings.NewReader("{\"request\":{}}\nnot json\n")); err == nil {
+		t.Error("expected error for malformed NDJSON")
+	}
+}
+
+func TestReadRecordsHAR(t *testing.T) {
+	rec := newHARTestRecord("/users")
+	rec.Request.URL += "?page=2"
+	rec.Request.Query = Query{{Name: "page", Value: "2"}}
+	rec.Request.ContentType = "application/json"
+	rec.Request.Body = map[string]interface{}{"name": "Alice"}
+	rec.Response.ContentType = "application/json"
+	rec.Response.Body = []interface{}{"a", "b"}
+
+	buf := &bytes.Buffer{}
+	w := NewHARStreamWriter(buf)
+	if err := w.WriteRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := ReadRecords(buf)
+	if err != nil {
+		t.Fatalf("ReadRecords failed: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+
+	got := recs[0]
+	if got.Request.Method != "GET" || got.Request.Host != "api.example.com" || got.Request.Path != "/users" {
+		t.Errorf("unexpected request: %+v", got.Request)
+	}
+	if got.Request.Query.Get("page") != "2" || got.Request.Headers.Get("cookie") == "" {
+		t.Errorf("unexpected query or headers: %+v", got.Request)
+	}
+	if string(BodyBytes(got.Request.Body)) != `{"name":"Alice"}` {
+		t.Errorf("unexpected request body: %v", got.Request.Body)
+	}
+	if got.Response.Status != 302 || string(BodyBytes(got.Response.Body)) != `["a","b"]` {
+		t.Errorf("unexpected response: %+v", got.Response)
+	}
+	if !got.StartTime.Equal(rec.StartTime) {
+		t.Errorf("unexpected start time: %v", got.StartTime)
+	}
+}
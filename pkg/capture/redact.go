@@ -0,0 +1,114 @@
+package capture
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/grokify/omniproxy/pkg/redact"
+)
+
+// redact applies the configured redaction rules to every part of rec that
+// can carry secrets. Maps and bodies are replaced rather than modified,
+// since WebSocket frame records share them with their upgrade request.
+func (c *Capturer) redact(rec *Record) {
+	r := c.config.Redactor
+	if r == nil {
+		return
+	}
+
+	redactRequest(r, &rec.Request)
+	redactResponse(r, &rec.Response)
+
+	if rec.Rewrite != nil {
+		rw := *rec.Rewrite
+		if rw.OriginalRequest != nil {
+			req := *rw.OriginalRequest
+			redactRequest(r, &req)
+			rw.OriginalRequest = &req
+		}
+		if rw.OriginalResponse != nil {
+			resp := *rw.OriginalResponse
+			redactResponse(r, &resp)
+			rw.OriginalResponse = &resp
+		}
+		rec.Rewrite = &rw
+	}
+
+	if rec.WebSocket != nil {
+		ws := *rec.WebSocket
+		ws.Payload = r.Body(ws.Payload, "")
+		ws.CloseReason = r.Text(ws.CloseReason)
+		rec.WebSocket = &ws
+	}
+
+	if rec.GRPC != nil {
+		g := *rec.GRPC
+		g.Message = r.Text(g.Message)
+		rec.GRPC = &g
+	}
+}
+
+// Cookies are parsed again from the redacted headers, which keep cookie
+// names and mask their values.
+func redactRequest(r *redact.Redactor, req *RequestRecord) {
+	req.URL = r.URL(req.URL)
+	req.Path = r.Text(req.Path)
+	req.Headers = redactHeaders(r, req.Headers)
+	if req.Cookies != nil {
+		req.Cookies = req.Headers.Cookies()
+	}
+	if req.Query != nil {
+		query := make(Query, len(req.Query))
+		for i, p := range req.Query {
+			query[i] = QueryParam{Name: p.Name, Value: r.QueryParam(p.Name, p.Value)}
+		}
+		req.Query = query
+	}
+	req.Body = r.Body(req.Body, req.ContentType)
+}
+
+func redactResponse(r *redact.Redactor, resp *ResponseRecord) {
+	resp.Headers = redactHeaders(r, resp.Headers)
+	if resp.Cookies != nil {
+		resp.Cookies = resp.Headers.SetCookies()
+	}
+	resp.Body = r.Body(resp.Body, resp.ContentType)
+	if resp.Events != nil {
+		events := make([]StreamEvent, len(resp.Events))
+		for i, e := range resp.Events {
+			e.Data = redactEventData(r, e.Data)
+			events[i] = e
+		}
+		resp.Events = events
+	}
+	resp.RedirectURL = r.URL(resp.RedirectURL)
+}
+
+// redactEventData redacts JSON event data by JSON path as well as pattern.
+// The data is encoded again only when redaction changed it.
+func redactEventData(r *redact.Redactor, data string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return r.Text(data)
+	}
+	redacted := r.Body(v, "application/json")
+	if reflect.DeepEqual(redacted, v) {
+		return data
+	}
+	encoded, err := json.Marshal(redacted)
+	if err != nil {
+		return r.Text(data)
+	}
+	return string(encoded)
+}
+
+func redactHeaders(r *redact.Redactor, headers Headers) Headers {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(Headers, len(headers))
+	for i, h := range headers {
+		redacted[i] = Header{Name: h.Name, Value: r.Header(h.Name, h.Value)}
+	}
+	return redacted
+}
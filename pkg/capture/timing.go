@@ -0,0 +1,144 @@
+package capture
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings holds the phases of the upstream exchange in milliseconds, using
+// HAR semantics: -1 means a phase did not apply (e.g. DNS and connect on a
+// reused connection) and Connect includes SSL.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// timingTrace collects httptrace events for a single request.
+type timingTrace struct {
+	mu           sync.Mutex
+	getConn      time.Time
+	gotConn      time.Time
+	reused       bool
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+// TraceRequest returns a copy of req instrumented with an httptrace.ClientTrace
+// that records connection and transfer phases into rec. FinishCapture turns
+// them into rec.Timings and rec.TTFBMs.
+func (c *Capturer) TraceRequest(rec *Record, req *http.Request) *http.Request {
+	t := &timingTrace{}
+	rec.trace = t
+
+	mark := func(field *time.Time) {
+		t.mu.Lock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+		t.mu.Unlock()
+	}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) { mark(&t.getConn) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+			mark(&t.gotConn)
+		},
+		DNSStart:     func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { mark(&t.dnsDone) },
+		ConnectStart: func(string, string) { mark(&t.connectStart) },
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.connectDone = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { mark(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { mark(&t.firstByte) },
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// finish computes the HAR timing phases, ending the receive phase at end.
+// It returns nil if the request never reached the network.
+func (t *timingTrace) finish(end time.Time) *Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.getConn.IsZero() || t.gotConn.IsZero() || t.firstByte.IsZero() {
+		return nil
+	}
+
+	tm := &Timings{DNS: -1, Connect: -1, SSL: -1}
+	if !t.reused {
+		tm.DNS = phaseMs(t.dnsStart, t.dnsDone)
+		connectEnd := t.connectDone
+		if !t.tlsDone.IsZero() {
+			connectEnd = t.tlsDone
+		}
+		tm.Connect = phaseMs(t.connectStart, connectEnd)
+		tm.SSL = phaseMs(t.tlsStart, t.tlsDone)
+	}
+
+	// Blocked is time spent waiting for a connection, excluding setup phases
+	blocked := phaseMs(t.getConn, t.gotConn)
+	for _, v := range []float64{tm.DNS, tm.Connect} {
+		if v > 0 {
+			blocked -= v
+		}
+	}
+	tm.Blocked = nonNegative(blocked)
+
+	sent := t.wroteRequest
+	if sent.IsZero() || sent.After(t.firstByte) {
+		sent = t.gotConn
+	}
+	tm.Send = nonNegative(phaseMs(t.gotConn, sent))
+	tm.Wait = nonNegative(phaseMs(sent, t.firstByte))
+	tm.Receive = nonNegative(phaseMs(t.firstByte, end))
+
+	return tm
+}
+
+// ttfb returns milliseconds from start to the first response byte, or 0.
+func (t *timingTrace) ttfb(start time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstByte.IsZero() {
+		return 0
+	}
+	return nonNegative(phaseMs(start, t.firstByte))
+}
+
+// phaseMs returns the milliseconds between a and b, or -1 if either is unset.
+func phaseMs(a, b time.Time) float64 {
+	if a.IsZero() || b.IsZero() {
+		return -1
+	}
+	return float64(b.Sub(a).Microseconds()) / 1000.0
+}
+
+func nonNegative(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,435 @@
+package capture
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grokify/omniproxy/pkg/contentdetect"
+)
+
+// RecordType identifies what a Record describes.
+type RecordType string
+
+const (
+	// RecordTypeHTTP is a plain HTTP request/response pair (the default when empty)
+	RecordTypeHTTP RecordType = "http"
+	// RecordTypeWebSocket is the HTTP upgrade that opened a WebSocket connection
+	RecordTypeWebSocket RecordType = "websocket"
+	// RecordTypeWebSocketFrame is a single frame sent over a WebSocket connection
+	RecordTypeWebSocketFrame RecordType = "websocket_frame"
+	// RecordTypeGRPC is a gRPC call, with its messages in the request and response bodies
+	RecordTypeGRPC RecordType = "grpc"
+)
+
+// WebSocketDirection is the direction a WebSocket frame travelled.
+type WebSocketDirection string
+
+const (
+	// DirectionClientToServer marks frames sent by the client
+	DirectionClientToServer WebSocketDirection = "client"
+	// DirectionServerToClient marks frames sent by the server
+	DirectionServerToClient WebSocketDirection = "server"
+)
+
+// WebSocket opcodes as defined by RFC 6455.
+const (
+	OpcodeContinuation = 0x0
+	OpcodeText         = 0x1
+	OpcodeBinary       = 0x2
+	OpcodeClose        = 0x8
+	OpcodePing         = 0x9
+	OpcodePong         = 0xA
+)
+
+// maxControlPayload is the largest payload RFC 6455 allows in a control frame.
+const maxControlPayload = 125
+
+// WebSocketRecord holds WebSocket connection and frame details.
+// Upgrade records only carry ConnectionID; frame records carry the rest.
+type WebSocketRecord struct {
+	// ConnectionID groups the upgrade record with its frames
+	ConnectionID string `json:"connectionId"`
+	// Sequence is the frame's position within the connection (starting at 1)
+	Sequence int64 `json:"sequence,omitempty"`
+	// Direction is which peer sent the frame
+	Direction WebSocketDirection `json:"direction,omitempty"`
+	// Opcode is the raw frame opcode
+	Opcode int `json:"opcode,omitempty"`
+	// OpcodeName is the human-readable opcode (text, binary, close, ...)
+	OpcodeName string `json:"opcodeName,omitempty"`
+	// Final is true if the FIN bit was set
+	Final bool `json:"final,omitempty"`
+	// Compressed is true if the frame used permessage-deflate (RSV1)
+	Compressed bool `json:"compressed,omitempty"`
+	// Payload is the (unmasked) frame payload
+	Payload interface{} `json:"payload,omitempty"`
+	// PayloadSize is the full payload length, even when truncated
+	PayloadSize int64 `json:"payloadSize,omitempty"`
+	// IsBinary is true if the payload was skipped as binary
+	IsBinary bool `json:"isBinary,omitempty"`
+	// Truncated is true if the payload exceeded MaxBodySize
+	Truncated bool `json:"truncated,omitempty"`
+	// CloseCode is the status code carried by a close frame
+	CloseCode int `json:"closeCode,omitempty"`
+	// CloseReason is the reason carried by a close frame
+	CloseReason string `json:"closeReason,omitempty"`
+	// ProtocolError is true if the frame broke RFC 6455, such as a control
+	// frame over 125 bytes or a reserved opcode
+	ProtocolError bool `json:"protocolError,omitempty"`
+}
+
+// OpcodeName returns the human-readable name of a WebSocket opcode.
+func OpcodeName(opcode int) string {
+	switch opcode {
+	case OpcodeContinuation:
+		return "continuation"
+	case OpcodeText:
+		return "text"
+	case OpcodeBinary:
+		return "binary"
+	case OpcodeClose:
+		return "close"
+	case OpcodePing:
+		return "ping"
+	case OpcodePong:
+		return "pong"
+	default:
+		return "unknown"
+	}
+}
+
+// IsWebSocketUpgrade reports whether resp completes a WebSocket handshake.
+func IsWebSocketUpgrade(resp *http.Response) bool {
+	if resp == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		return false
+	}
+	for _, v := range resp.Header.Values("Upgrade") {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), "websocket") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CaptureWebSocket marks rec as a WebSocket upgrade and wraps the upgraded
+// connection in resp.Body so that every frame relayed in either direction
+// is emitted as a RecordTypeWebSocketFrame record. Call it before
+// FinishCapture. onError, if non-nil, receives errors from writing frame
+// records; relaying is never interrupted by capture failures.
+func (c *Capturer) CaptureWebSocket(rec *Record, resp *http.Response, onError func(error)) {
+	if !IsWebSocketUpgrade(resp) || resp.Body == nil {
+		return
+	}
+	rw, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return
+	}
+
+	rec.Type = RecordTypeWebSocket
+	rec.WebSocket = &WebSocketRecord{ConnectionID: newConnectionID()}
+
+	conn := &wsConn{
+		rwc:      rw,
+		capturer: c,
+		onError:  onError,
+		connID:   rec.WebSocket.ConnectionID,
+		request: RequestRecord{
+			Method: rec.Request.Method,
+			URL:    rec.Request.URL,
+			Host:   rec.Request.Host,
+			Path:   rec.Request.Path,
+			Scheme: rec.Request.Scheme,
+		},
+		status:     resp.StatusCode,
+		statusText: resp.Status,
+		user:       rec.User,
+	}
+	conn.fromServer = newWSFrameParser(DirectionServerToClient, c.wsPayloadLimit(), conn.emit)
+	conn.fromClient = newWSFrameParser(DirectionClientToServer, c.wsPayloadLimit(), conn.emit)
+	resp.Body = conn
+}
+
+// wsPayloadLimit returns how many payload bytes of each frame to keep.
+func (c *Capturer) wsPayloadLimit() int64 {
+	if !c.config.IncludeBody {
+		return 0
+	}
+	return c.config.MaxBodySize
+}
+
+// wsConn tees an upgraded connection into a pair of frame parsers.
+// Reads carry server-to-client bytes and writes carry client-to-server bytes.
+type wsConn struct {
+	rwc        io.ReadWriteCloser
+	capturer   *Capturer
+	onError    func(error)
+	connID     string
+	request    RequestRecord
+	status     int
+	statusText string
+	user       string
+	seq        atomic.Int64
+
+	fromServer *wsFrameParser
+	fromClient *wsFrameParser
+	readMu     sync.Mutex
+	writeMu    sync.Mutex
+}
+
+func (w *wsConn) Read(p []byte) (int, error) {
+	n, err := w.rwc.Read(p)
+	if n > 0 {
+		w.readMu.Lock()
+		w.fromServer.write(p[:n])
+		w.readMu.Unlock()
+	}
+	return n, err
+}
+
+func (w *wsConn) Write(p []byte) (int, error) {
+	n, err := w.rwc.Write(p)
+	if n > 0 {
+		w.writeMu.Lock()
+		w.fromClient.write(p[:n])
+		w.writeMu.Unlock()
+	}
+	return n, err
+}
+
+func (w *wsConn) Close() error {
+	return w.rwc.Close()
+}
+
+// emit converts a parsed frame into a Record and hands it to the capturer.
+func (w *wsConn) emit(f *wsFrame) {
+	now := time.Now()
+	ws := &WebSocketRecord{
+		ConnectionID:  w.connID,
+		Sequence:      w.seq.Add(1),
+		Direction:     f.direction,
+		Opcode:        f.opcode,
+		OpcodeName:    OpcodeName(f.opcode),
+		Final:         f.fin,
+		Compressed:    f.compressed,
+		PayloadSize:   f.size,
+		Truncated:     f.size > int64(len(f.payload)),
+		ProtocolError: f.protocolError,
+	}
+
+	if f.opcode == OpcodeClose && len(f.control) >= 2 {
+		ws.CloseCode = int(binary.BigEndian.Uint16(f.control[:2]))
+		ws.CloseReason = string(f.control[2:])
+	}
+
+	// Close payloads are fully described by CloseCode and CloseReason
+	if len(f.payload) > 0 && f.opcode != OpcodeClose {
+		cfg := w.capturer.config
+		isText := f.opcode != OpcodeBinary && !f.compressed && utf8.Valid(f.payload)
+		switch {
+		case isText:
+			ws.Payload = w.capturer.parseBody(f.payload, "")
+		case cfg.SkipBinary && (f.compressed || contentdetect.IsBinary("", f.payload)):
+			ws.IsBinary = true
+			ws.Payload = BinaryPlaceholder
+		default:
+			ws.Payload = f.payload
+		}
+	}
+
+	rec := &Record{
+		Type:      RecordTypeWebSocketFrame,
+		Request:   w.request,
+		Response:  ResponseRecord{Status: w.status, StatusText: w.statusText},
+		StartTime: now,
+		EndTime:   now,
+		WebSocket: ws,
+		User:      w.user,
+	}
+	if err := w.capturer.finishRecord(rec); err != nil && w.onError != nil {
+		w.onError(err)
+	}
+}
+
+// wsFrame is a parsed WebSocket frame.
+type wsFrame struct {
+	direction  WebSocketDirection
+	fin        bool
+	compressed bool
+	opcode     int
+	size       int64
+	payload    []byte // captured payload, at most the parser limit
+	control    []byte // full payload of valid control frames (<= 125 bytes)
+
+	protocolError bool
+}
+
+// isControlOpcode reports whether opcode is a close, ping or pong.
+func isControlOpcode(opcode int) bool {
+	return opcode == OpcodeClose || opcode == OpcodePing || opcode == OpcodePong
+}
+
+// isReservedOpcode reports whether opcode is not defined by RFC 6455.
+func isReservedOpcode(opcode int) bool {
+	return opcode > OpcodeBinary && !isControlOpcode(opcode)
+}
+
+// wsFrameParser incrementally parses a stream of WebSocket frames.
+// It never buffers more than the frame header and the captured payload prefix.
+type wsFrameParser struct {
+	direction WebSocketDirection
+	limit     int64
+	emit      func(*wsFrame)
+
+	hdr       []byte
+	frame     *wsFrame
+	masked    bool
+	mask      [4]byte
+	remaining uint64
+	offset    uint64
+}
+
+func newWSFrameParser(dir WebSocketDirection, limit int64, emit func(*wsFrame)) *wsFrameParser {
+	return &wsFrameParser{direction: dir, limit: limit, emit: emit, hdr: make([]byte, 0, 14)}
+}
+
+// wsHeaderLen returns the full header length implied by the bytes seen so far.
+func wsHeaderLen(hdr []byte) int {
+	if len(hdr) < 2 {
+		return 2
+	}
+	n := 2
+	switch hdr[1] & 0x7F {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+	if hdr[1]&0x80 != 0 {
+		n += 4
+	}
+	return n
+}
+
+// write feeds raw connection bytes into the parser.
+func (p *wsFrameParser) write(b []byte) {
+	for len(b) > 0 {
+		if p.frame == nil {
+			need := wsHeaderLen(p.hdr) - len(p.hdr)
+			if need > len(b) {
+				need = len(b)
+			}
+			p.hdr = append(p.hdr, b[:need]...)
+			b = b[need:]
+			if len(p.hdr) < wsHeaderLen(p.hdr) {
+				continue
+			}
+			p.startFrame()
+			if p.remaining == 0 {
+				p.finishFrame()
+			}
+			continue
+		}
+
+		n := uint64(len(b))
+		if n > p.remaining {
+			n = p.remaining
+		}
+		p.consume(b[:n])
+		b = b[n:]
+		if p.remaining == 0 {
+			p.finishFrame()
+		}
+	}
+}
+
+func (p *wsFrameParser) startFrame() {
+	h := p.hdr
+	f := &wsFrame{
+		direction:  p.direction,
+		fin:        h[0]&0x80 != 0,
+		compressed: h[0]&0x40 != 0,
+		opcode:     int(h[0] & 0x0F),
+	}
+
+	var size uint64
+	pos := 2
+	switch h[1] & 0x7F {
+	case 126:
+		size = uint64(binary.BigEndian.Uint16(h[2:4]))
+		pos = 4
+	case 127:
+		size = binary.BigEndian.Uint64(h[2:10])
+		pos = 10
+	default:
+		size = uint64(h[1] & 0x7F)
+	}
+
+	p.masked = h[1]&0x80 != 0
+	if p.masked {
+		copy(p.mask[:], h[pos:pos+4])
+	}
+
+	f.size = int64(size) //nolint:gosec // frame sizes beyond int64 are not meaningful
+	// Oversized control frames are relayed but never buffered in full
+	f.protocolError = isReservedOpcode(f.opcode) ||
+		(isControlOpcode(f.opcode) && size > maxControlPayload)
+	p.frame = f
+	p.remaining = size
+	p.offset = 0
+}
+
+// consume unmasks and keeps payload bytes up to the capture limit.
+func (p *wsFrameParser) consume(b []byte) {
+	f := p.frame
+	isControl := isControlOpcode(f.opcode) && !f.protocolError
+	keep := 0
+	if room := p.limit - int64(len(f.payload)); room > 0 {
+		keep = len(b)
+		if int64(keep) > room {
+			keep = int(room)
+		}
+	}
+	if isControl || keep > 0 {
+		chunk := make([]byte, len(b))
+		copy(chunk, b)
+		if p.masked {
+			for i := range chunk {
+				chunk[i] ^= p.mask[(p.offset+uint64(i))%4]
+			}
+		}
+		if isControl {
+			f.control = append(f.control, chunk...)
+		}
+		f.payload = append(f.payload, chunk[:keep]...)
+	}
+	p.offset += uint64(len(b))
+	p.remaining -= uint64(len(b))
+}
+
+func (p *wsFrameParser) finishFrame() {
+	f := p.frame
+	p.frame = nil
+	p.hdr = p.hdr[:0]
+	if p.emit != nil {
+		p.emit(f)
+	}
+}
+
+// newConnectionID returns a random identifier for a WebSocket connection.
+func newConnectionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
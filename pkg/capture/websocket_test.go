@@ -0,0 +1,288 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// wsFrameBytes encodes a single WebSocket frame, masking it if mask is non-nil.
+func wsFrameBytes(opcode byte, fin bool, payload []byte, mask []byte) []byte {
+	var buf bytes.Buffer
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	buf.WriteByte(b0)
+
+	var maskBit byte
+	if mask != nil {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		buf.WriteByte(maskBit | byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		buf.WriteByte(maskBit | 126)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)))
+	default:
+		buf.WriteByte(maskBit | 127)
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(payload)))
+	}
+
+	if mask == nil {
+		buf.Write(payload)
+		return buf.Bytes()
+	}
+	buf.Write(mask)
+	for i, c := range payload {
+		buf.WriteByte(c ^ mask[i%4])
+	}
+	return buf.Bytes()
+}
+
+// fakeUpgradedConn stands in for the ReadWriteCloser body of a 101 response.
+type fakeUpgradedConn struct {
+	fromServer io.Reader
+	toServer   bytes.Buffer
+}
+
+func (f *fakeUpgradedConn) Read(p []byte) (int, error)  { return f.fromServer.Read(p) }
+func (f *fakeUpgradedConn) Write(p []byte) (int, error) { return f.toServer.Write(p) }
+func (f *fakeUpgradedConn) Close() error                { return nil }
+
+func newUpgradeResponse(conn io.ReadWriteCloser) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusSwitchingProtocols,
+		Status:     "101 Switching Protocols",
+		Header: http.Header{
+			"Connection": []string{"Upgrade"},
+			"Upgrade":    []string{"websocket"},
+		},
+		Body: conn,
+	}
+}
+
+func collectRecords(c *Capturer) (*[]*Record, *sync.Mutex) {
+	var mu sync.Mutex
+	recs := []*Record{}
+	c.AddHandler(func(r *Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		recs = append(recs, r)
+	})
+	return &recs, &mu
+}
+
+func TestIsWebSocketUpgrade(t *testing.T) {
+	if !IsWebSocketUpgrade(newUpgradeResponse(nil)) {
+		t.Error("expected 101 with Upgrade: websocket to be detected")
+	}
+	if IsWebSocketUpgrade(&http.Response{StatusCode: 200, Header: http.Header{"Upgrade": []string{"websocket"}}}) {
+		t.Error("expected 200 response not to be an upgrade")
+	}
+	if IsWebSocketUpgrade(&http.Response{StatusCode: 101, Header: http.Header{"Upgrade": []string{"h2c"}}}) {
+		t.Error("expected h2c upgrade not to be a websocket upgrade")
+	}
+	if IsWebSocketUpgrade(nil) {
+		t.Error("expected nil response not to be an upgrade")
+	}
+}
+
+func TestCaptureWebSocketFrames(t *testing.T) {
+	cfg := &Config{
+		Output:      &bytes.Buffer{},
+		Format:      FormatNDJSON,
+		IncludeBody: true,
+		MaxBodySize: 1024,
+		SkipBinary:  true,
+	}
+	c := NewCapturer(cfg)
+	recs, mu := collectRecords(c)
+
+	req, _ := http.NewRequest("GET", "http://example.com/socket", nil)
+	rec := c.StartCapture(req)
+
+	// Server sends a text frame followed by a close frame
+	closePayload := append([]byte{0x03, 0xE8}, []byte("bye")...)
+	serverStream := append(wsFrameBytes(OpcodeText, true, []byte(`{"hello":"world"}`), nil),
+		wsFrameBytes(OpcodeClose, true, closePayload, nil)...)
+	conn := &fakeUpgradedConn{fromServer: bytes.NewReader(serverStream)}
+	resp := newUpgradeResponse(conn)
+
+	c.CaptureWebSocket(rec, resp, func(err error) { t.Errorf("unexpected capture error: %v", err) })
+	if err := c.FinishCapture(rec, resp); err != nil {
+		t.Fatalf("FinishCapture failed: %v", err)
+	}
+
+	if rec.Type != RecordTypeWebSocket {
+		t.Errorf("expected upgrade record type %q, got %q", RecordTypeWebSocket, rec.Type)
+	}
+	if rec.WebSocket == nil || rec.WebSocket.ConnectionID == "" {
+		t.Fatal("expected upgrade record to carry a connection ID")
+	}
+
+	// Client sends a masked text frame, split across two writes
+	clientFrame := wsFrameBytes(OpcodeText, true, []byte("ping?"), []byte{1, 2, 3, 4})
+	if _, err := resp.Body.(io.Writer).Write(clientFrame[:3]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resp.Body.(io.Writer).Write(clientFrame[3:]); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(conn.toServer.Bytes(), clientFrame) {
+		t.Error("client bytes should be relayed unchanged")
+	}
+
+	// Relay server bytes in small chunks
+	relayed, err := io.ReadAll(io.LimitReader(resp.Body, int64(len(serverStream))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(relayed, serverStream) {
+		t.Error("server bytes should be relayed unchanged")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*recs) != 4 {
+		t.Fatalf("expected 4 records (upgrade + 3 frames), got %d", len(*recs))
+	}
+
+	client := (*recs)[1].WebSocket
+	if client.Direction != DirectionClientToServer || client.Payload != "ping?" {
+		t.Errorf("unexpected client frame: %+v", client)
+	}
+	if client.ConnectionID != rec.WebSocket.ConnectionID {
+		t.Error("frame should share the upgrade's connection ID")
+	}
+
+	text := (*recs)[2]
+	if text.Type != RecordTypeWebSocketFrame || text.Response.Status != 101 {
+		t.Errorf("unexpected frame record: type=%q status=%d", text.Type, text.Response.Status)
+	}
+	payload, ok := text.WebSocket.Payload.(map[string]interface{})
+	if !ok || payload["hello"] != "world" {
+		t.Errorf("expected JSON text payload, got %#v", text.WebSocket.Payload)
+	}
+	if text.Request.URL != "http://example.com/socket" {
+		t.Errorf("frame should carry the connection URL, got %s", text.Request.URL)
+	}
+
+	closing := (*recs)[3].WebSocket
+	if closing.OpcodeName != "close" || closing.CloseCode != 1000 || closing.CloseReason != "bye" {
+		t.Errorf("unexpected close frame: %+v", closing)
+	}
+}
+
+func TestWebSocketFrameLimits(t *testing.T) {
+	cfg := &Config{
+		Output:      &bytes.Buffer{},
+		Format:      FormatNDJSON,
+		IncludeBody: true,
+		MaxBodySize: 16,
+		SkipBinary:  true,
+	}
+	c := NewCapturer(cfg)
+	recs, mu := collectRecords(c)
+
+	large := bytes.Repeat([]byte("a"), 300)
+	png := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00}
+	stream := append(wsFrameBytes(OpcodeText, true, large, nil), wsFrameBytes(OpcodeBinary, true, png, nil)...)
+	conn := &fakeUpgradedConn{fromServer: bytes.NewReader(stream)}
+
+	req, _ := http.NewRequest("GET", "https://example.com/socket", nil)
+	rec := c.StartCapture(req)
+	resp := newUpgradeResponse(conn)
+	c.CaptureWebSocket(rec, resp, nil)
+
+	// Read one byte at a time to exercise header reassembly
+	buf := make([]byte, 1)
+	for {
+		if _, err := resp.Body.Read(buf); err != nil {
+			break
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*recs) != 2 {
+		t.Fatalf("expected 2 frame records, got %d", len(*recs))
+	}
+
+	text := (*recs)[0].WebSocket
+	if !text.Truncated || text.PayloadSize != 300 || text.Payload != string(large[:16]) {
+		t.Errorf("expected truncated 16-byte payload of 300, got %+v", text)
+	}
+
+	bin := (*recs)[1].WebSocket
+	if !bin.IsBinary || bin.Payload != "[binary content]" || bin.PayloadSize != int64(len(png)) {
+		t.Errorf("expected skipped binary payload, got %+v", bin)
+	}
+}
+
+func TestWebSocketControlFrameLimits(t *testing.T) {
+	cfg := &Config{
+		Output:      &bytes.Buffer{},
+		Format:      FormatNDJSON,
+		IncludeBody: true,
+		MaxBodySize: 16,
+	}
+	c := NewCapturer(cfg)
+	recs, mu := collectRecords(c)
+
+	ping := bytes.Repeat([]byte("p"), 200)
+	reserved := bytes.Repeat([]byte("r"), 200)
+	closePayload := append([]byte{0x03, 0xE8}, bytes.Repeat([]byte("x"), 200)...)
+	stream := append(wsFrameBytes(OpcodePing, true, ping, nil), wsFrameBytes(0xB, true, reserved, nil)...)
+	stream = append(stream, wsFrameBytes(OpcodeClose, true, closePayload, nil)...)
+	conn := &fakeUpgradedConn{fromServer: bytes.NewReader(stream)}
+
+	req, _ := http.NewRequest("GET", "https://example.com/socket", nil)
+	rec := c.StartCapture(req)
+	resp := newUpgradeResponse(conn)
+	c.CaptureWebSocket(rec, resp, nil)
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*recs) != 3 {
+		t.Fatalf("expected 3 frame records, got %d", len(*recs))
+	}
+	for i, want := range []string{"ping", "unknown", "close"} {
+		ws := (*recs)[i].WebSocket
+		if ws.OpcodeName != want || !ws.ProtocolError || ws.PayloadSize < 200 {
+			t.Errorf("frame %d: expected %s protocol error, got %+v", i, want, ws)
+		}
+	}
+	if closing := (*recs)[2].WebSocket; closing.CloseCode != 0 || closing.CloseReason != "" {
+		t.Errorf("oversized close frame was parsed: %+v", closing)
+	}
+}
+
+func TestWebSocketOversizedControlNotBuffered(t *testing.T) {
+	var frames []*wsFrame
+	p := newWSFrameParser(DirectionServerToClient, 16, func(f *wsFrame) { frames = append(frames, f) })
+
+	// A ping declaring a 1 TiB payload must not be accumulated
+	hdr := []byte{0x80 | OpcodePing, 127, 0, 0, 0x01, 0, 0, 0, 0, 0}
+	p.write(hdr)
+	chunk := bytes.Repeat([]byte("p"), 64*1024)
+	for i := 0; i < 16; i++ {
+		p.write(chunk)
+	}
+	if p.frame == nil || !p.frame.protocolError {
+		t.Fatalf("expected an in-progress protocol error frame, got %+v", p.frame)
+	}
+	if len(p.frame.control) != 0 || len(p.frame.payload) != 16 {
+		t.Errorf("oversized ping buffered %d control and %d payload bytes", len(p.frame.control), len(p.frame.payload))
+	}
+	if len(frames) != 0 {
+		t.Errorf("frame emitted before its payload ended: %d", len(frames))
+	}
+}
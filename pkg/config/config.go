@@ -7,6 +7,13 @@ import (
 	"path/filepath"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/netsim"
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+	"github.com/grokify/omniproxy/pkg/redact"
+	"github.com/grokify/omniproxy/pkg/rewrite"
 )
 
 // Config represents the OmniProxy configuration file.
@@ -28,6 +35,17 @@ type Config struct {
 
 	// Upstream proxy configuration
 	Upstream string `yaml:"upstream,omitempty"`
+	// VerifyUpstream verifies the TLS certificates of origin servers
+	VerifyUpstream bool `yaml:"verifyUpstream,omitempty"`
+
+	// Rules are request/response rewrite rules, applied in order
+	Rules []rewrite.Rule `yaml:"rules,omitempty"`
+
+	// Network simulation profiles
+	Network netsim.Config `yaml:"network,omitempty"`
+
+	// Auth requires proxy clients to authenticate
+	Auth proxyauth.Config `yaml:"auth,omitempty"`
 }
 
 // ServerConfig holds server-related configuration.
@@ -50,6 +68,8 @@ type MITMConfig struct {
 	KeyPath string `yaml:"keyPath,omitempty"`
 	// SkipHosts is a list of hosts to skip MITM for
 	SkipHosts []string `yaml:"skipHosts,omitempty"`
+	// CertCache configures the cache for intercepted-host certificates
+	CertCache backend.CertCacheConfig `yaml:"certCache,omitempty"`
 }
 
 // CaptureConfig holds capture-related configuration.
@@ -64,8 +84,18 @@ type CaptureConfig struct {
 	IncludeBody bool `yaml:"includeBody"`
 	// MaxBodySize is the maximum body size to capture
 	MaxBodySize int64 `yaml:"maxBodySize"`
-	// FilterHeaders is a list of headers to exclude
+	// MaxDecodedSize limits the bytes decompressed from an encoded body
+	MaxDecodedSize int64 `yaml:"maxDecodedSize"`
+	// Redact holds the rules for redacting secrets from captured traffic
+	Redact redact.Config `yaml:"redact"`
+	// FilterHeaders lists additional headers to redact.
+	//
+	// Deprecated: use Redact.Headers.
 	FilterHeaders []string `yaml:"filterHeaders,omitempty"`
+	// RecordOriginal also records the request and response as they were before rewrite rules
+	RecordOriginal bool `yaml:"recordOriginal,omitempty"`
+	// ProtoDescriptors are FileDescriptorSet files used to decode gRPC messages
+	ProtoDescriptors []string `yaml:"protoDescriptors,omitempty"`
 }
 
 // FilterConfig holds request/response filtering configuration.
@@ -82,6 +112,8 @@ type FilterConfig struct {
 	IncludeMethods []string `yaml:"includeMethods,omitempty"`
 	// ExcludeMethods is a list of methods to exclude
 	ExcludeMethods []string `yaml:"excludeMethods,omitempty"`
+	// Query is a filter expression records must also match (see package query)
+	Query string `yaml:"query,omitempty"`
 }
 
 // ReverseConfig holds reverse proxy configuration.
@@ -139,14 +171,8 @@ func DefaultConfig() *Config {
 			IncludeHeaders: true,
 			IncludeBody:    true,
 			MaxBodySize:    1024 * 1024, // 1MB
-			FilterHeaders: []string{
-				"authorization",
-				"cookie",
-				"set-cookie",
-				"x-api-key",
-				"x-auth-token",
-				"proxy-authorization",
-			},
+			MaxDecodedSize: capture.DefaultMaxDecodedSize,
+			Redact:         *redact.DefaultConfig(),
 		},
 		Filter: FilterConfig{},
 	}
@@ -215,6 +241,39 @@ func ExampleConfig() string {
 	cfg.Filter.IncludeHosts = []string{"api.example.com", "*.example.org"}
 	cfg.Filter.ExcludePaths = []string{"*.js", "*.css", "*.png", "*.jpg"}
 	cfg.MITM.SkipHosts = []string{"*.pinned-app.com"}
+	cfg.Rules = []rewrite.Rule{
+		{
+			Name:  "staging-api",
+			Match: rewrite.Match{Hosts: []string{"api.example.com"}, Paths: []string{"/v1/*"}},
+			Request: &rewrite.RequestActions{
+				Actions: rewrite.Actions{
+					SetHeaders:    map[string]string{"X-Debug": "1"},
+					RemoveHeaders: []string{"If-None-Match"},
+				},
+				Host: "staging.example.com",
+			},
+			Response: &rewrite.ResponseActions{
+				Actions: rewrite.Actions{
+					JSON: []rewrite.JSONPatch{{Path: "$.features.beta", Value: true}},
+				},
+			},
+		},
+		{
+			Name:    "block-tracking",
+			Match:   rewrite.Match{Hosts: []string{"*.tracking.example.com"}},
+			Respond: &rewrite.LocalResponse{Status: 204},
+		},
+	}
+
+	cfg.Network = netsim.Config{
+		Profiles: []netsim.Profile{
+			{Name: "flaky-api", LatencyMs: 300, JitterMs: 200, ResetRate: 0.02, ErrorRate: 0.1, ErrorStatus: 502},
+		},
+		Hosts: []netsim.HostProfile{
+			{Host: "api.example.com", Profile: "flaky-api"},
+			{Host: "*.example.org", Profile: "3g"},
+		},
+	}
 
 	data, _ := yaml.Marshal(cfg)
 	return string(data)
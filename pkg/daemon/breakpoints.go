@@ -0,0 +1,277 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/breakpoint"
+)
+
+// BreakpointsResponse is the response format for the /breakpoints endpoint.
+type BreakpointsResponse struct {
+	Rules []breakpoint.Rule `json:"rules"`
+}
+
+// PendingResponse is the response format for the /breakpoints/pending endpoint.
+type PendingResponse struct {
+	Pending []*breakpoint.Pending `json:"pending"`
+}
+
+// handleBreakpoints lists (GET) and adds (POST) breakpoint rules.
+func (d *Daemon) handleBreakpoints(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if d.breakpoints == nil {
+		jsonError(w, "breakpoints not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, BreakpointsResponse{Rules: d.breakpoints.Rules()})
+	case http.MethodPost:
+		var rule breakpoint.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			jsonError(w, "invalid breakpoint rule: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if rule.ID == "pending" {
+			jsonError(w, `breakpoint rule ID "pending" is reserved`, http.StatusBadRequest)
+			return
+		}
+		rule, err := d.breakpoints.AddRule(rule)
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, rule)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleBreakpointItem serves:
+//
+//	DELETE /breakpoints/{rule-id}
+//	GET    /breakpoints/pending
+//	GET    /breakpoints/pending/{id}
+//	POST   /breakpoints/pending/{id}/resume (optional breakpoint.Edit body)
+//	POST   /breakpoints/pending/{id}/drop
+func (d *Daemon) handleBreakpointItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if d.breakpoints == nil {
+		jsonError(w, "breakpoints not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/breakpoints/"), "/")
+	if parts[0] != "pending" {
+		if len(parts) != 1 || parts[0] == "" {
+			jsonError(w, "not found", http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := d.breakpoints.RemoveRule(parts[0]); err != nil {
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]string{"status": "removed"})
+		return
+	}
+
+	switch {
+	case len(parts) == 1 || len(parts) == 2 && parts[1] == "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, PendingResponse{Pending: d.breakpoints.List()})
+
+	case len(parts) == 2:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p, err := d.breakpoints.Get(parts[1])
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, p)
+
+	case len(parts) == 3 && (parts[2] == "resume" || parts[2] == "drop"):
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var err error
+		status := "resumed"
+		if parts[2] == "drop" {
+			status = "dropped"
+			err = d.breakpoints.Drop(parts[1])
+		} else {
+			var edit *breakpoint.Edit
+			if edit, err = decodeEdit(r.Body); err != nil {
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			err = d.breakpoints.Resume(parts[1], edit)
+		}
+		switch {
+		case errors.Is(err, breakpoint.ErrNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			jsonError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeJSON(w, map[string]string{"status": status})
+		}
+
+	default:
+		jsonError(w, "not found", http.StatusNotFound)
+	}
+}
+
+// decodeEdit decodes an optional edit from a request body.
+func decodeEdit(body io.Reader) (*breakpoint.Edit, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edit: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var edit breakpoint.Edit
+	if err := json.Unmarshal(data, &edit); err != nil {
+		return nil, fmt.Errorf("invalid breakpoint edit: %w", err)
+	}
+	return &edit, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func jsonError(w http.ResponseWriter, msg string, code int) {
+	data, _ := json.Marshal(map[string]string{"error": msg})
+	http.Error(w, string(data), code)
+}
+
+// ListBreakpoints retrieves the breakpoint rules.
+func (c *Client) ListBreakpoints() ([]breakpoint.Rule, error) {
+	var res BreakpointsResponse
+	if err := c.doJSON(http.MethodGet, "/breakpoints", nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list breakpoints: %w", err)
+	}
+	return res.Rules, nil
+}
+
+// AddBreakpoint adds a breakpoint rule and returns it with its ID assigned.
+func (c *Client) AddBreakpoint(rule breakpoint.Rule) (*breakpoint.Rule, error) {
+	var res breakpoint.Rule
+	if err := c.doJSON(http.MethodPost, "/breakpoints", rule, &res); err != nil {
+		return nil, fmt.Errorf("failed to add breakpoint: %w", err)
+	}
+	return &res, nil
+}
+
+// RemoveBreakpoint removes a breakpoint rule.
+func (c *Client) RemoveBreakpoint(id string) error {
+	if err := c.doJSON(http.MethodDelete, "/breakpoints/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to remove breakpoint: %w", err)
+	}
+	return nil
+}
+
+// ListPending retrieves the paused requests and responses.
+func (c *Client) ListPending() ([]*breakpoint.Pending, error) {
+	var res PendingResponse
+	if err := c.doJSON(http.MethodGet, "/breakpoints/pending", nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list pending breakpoints: %w", err)
+	}
+	return res.Pending, nil
+}
+
+// GetPending retrieves a paused request or response.
+func (c *Client) GetPending(id string) (*breakpoint.Pending, error) {
+	var res breakpoint.Pending
+	if err := c.doJSON(http.MethodGet, "/breakpoints/pending/"+url.PathEscape(id), nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to get pending breakpoint: %w", err)
+	}
+	return &res, nil
+}
+
+// Resume continues a paused request or response, applying edit if it is not nil.
+func (c *Client) Resume(id string, edit *breakpoint.Edit) error {
+	var in interface{}
+	if edit != nil {
+		in = edit
+	}
+	if err := c.doJSON(http.MethodPost, "/breakpoints/pending/"+url.PathEscape(id)+"/resume", in, nil); err != nil {
+		return fmt.Errorf("failed to resume: %w", err)
+	}
+	return nil
+}
+
+// Drop discards a paused request or response.
+func (c *Client) Drop(id string) error {
+	if err := c.doJSON(http.MethodPost, "/breakpoints/pending/"+url.PathEscape(id)+"/drop", nil, nil); err != nil {
+		return fmt.Errorf("failed to drop: %w", err)
+	}
+	return nil
+}
+
+// doJSON sends in as JSON (if not nil) and decodes the response into out (if not nil).
+func (c *Client) doJSON(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, "http://unix"+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return errors.New(apiErr.Error)
+		}
+		return errors.New(strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
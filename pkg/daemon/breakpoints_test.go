@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/breakpoint"
+)
+
+func TestDaemonBreakpoints(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "omniproxyd-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &Config{
+		PIDFile:    filepath.Join(tmpDir, "test.pid"),
+		SocketPath: filepath.Join(tmpDir, "test.sock"),
+	}
+
+	m := breakpoint.New(nil)
+	d := New(cfg)
+	d.SetBreakpoints(m)
+
+	ctx := context.Background()
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	defer func() { _ = d.Stop(ctx) }()
+
+	client := NewClient(cfg.SocketPath)
+
+	rule, err := client.AddBreakpoint(breakpoint.Rule{Hosts: []string{"api.example.com"}})
+	if err != nil {
+		t.Fatalf("failed to add breakpoint: %v", err)
+	}
+	if _, err := client.AddBreakpoint(breakpoint.Rule{ID: "pending"}); err == nil {
+		t.Error("expected reserved ID to be rejected")
+	}
+	rules, err := client.ListBreakpoints()
+	if err != nil || len(rules) != 1 || rules[0].ID != rule.ID {
+		t.Fatalf("unexpected rules: %v %v", rules, err)
+	}
+
+	// Pause two requests: resume one with edits and drop the other
+	type result struct {
+		req  *http.Request
+		resp *http.Response
+	}
+	pause := func(target string) chan result {
+		ch := make(chan result, 1)
+		go func() {
+			req, resp := m.PauseRequest(httptest.NewRequest("GET", target, nil))
+			ch <- result{req, resp}
+		}()
+		return ch
+	}
+	first := pause("http://api.example.com/a")
+	var pending []*breakpoint.Pending
+	for i := 0; i < 200 && len(pending) < 1; i++ {
+		time.Sleep(5 * time.Millisecond)
+		pending, _ = client.ListPending()
+	}
+	second := pause("http://api.example.com/b")
+	for i := 0; i < 200 && len(pending) < 2; i++ {
+		time.Sleep(5 * time.Millisecond)
+		pending, _ = client.ListPending()
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending items, got %d", len(pending))
+	}
+
+	p, err := client.GetPending(pending[0].ID)
+	if err != nil || p.URL != "http://api.example.com/a" {
+		t.Fatalf("unexpected pending item: %+v %v", p, err)
+	}
+
+	if err := client.Resume(p.ID, &breakpoint.Edit{Status: 200}); err == nil {
+		t.Error("expected invalid edit to be rejected")
+	}
+	if err := client.Resume(p.ID, &breakpoint.Edit{Method: "DELETE"}); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if res := <-first; res.resp != nil || res.req.Method != "DELETE" {
+		t.Errorf("expected edited request to continue, got %s", res.req.Method)
+	}
+
+	if err := client.Drop(pending[1].ID); err != nil {
+		t.Fatalf("failed to drop: %v", err)
+	}
+	if res := <-second; res.resp == nil || res.resp.StatusCode != http.StatusBadGateway {
+		t.Error("expected dropped request to get a 502")
+	}
+
+	if err := client.Drop(pending[1].ID); err == nil {
+		t.Error("expected error for unknown pending item")
+	}
+	if err := client.RemoveBreakpoint(rule.ID); err != nil {
+		t.Fatalf("failed to remove breakpoint: %v", err)
+	}
+	if err := client.RemoveBreakpoint(rule.ID); err == nil {
+		t.Error("expected error removing unknown breakpoint")
+	}
+}
@@ -13,10 +13,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/breakpoint"
+	"github.com/grokify/omniproxy/pkg/netsim"
+	"github.com/grokify/omniproxy/pkg/query"
 )
 
 // Default paths for daemon files.
@@ -80,6 +84,15 @@ type Daemon struct {
 
 	// Optional traffic querier for /traffic endpoint
 	trafficQuerier backend.TrafficQuerier
+
+	// Optional breakpoint manager for /breakpoints endpoints
+	breakpoints *breakpoint.Manager
+
+	// Optional network simulator for /network endpoint
+	network *netsim.Simulator
+
+	// Subscribers of the /traffic/stream endpoint
+	stream *trafficBroker
 }
 
 // New creates a new daemon instance.
@@ -90,6 +103,7 @@ func New(cfg *Config) *Daemon {
 	return &Daemon{
 		config: cfg,
 		stopCh: make(chan struct{}),
+		stream: newTrafficBroker(),
 	}
 }
 
@@ -105,6 +119,16 @@ func (d *Daemon) SetTrafficQuerier(tq backend.TrafficQuerier) {
 	d.trafficQuerier = tq
 }
 
+// SetBreakpoints sets the breakpoint manager for the /breakpoints endpoints.
+func (d *Daemon) SetBreakpoints(m *breakpoint.Manager) {
+	d.breakpoints = m
+}
+
+// SetNetwork sets the network simulator for the /network endpoint.
+func (d *Daemon) SetNetwork(s *netsim.Simulator) {
+	d.network = s
+}
+
 // Start starts the daemon control server.
 func (d *Daemon) Start(ctx context.Context) error {
 	d.mu.Lock()
@@ -151,11 +175,17 @@ func (d *Daemon) Start(ctx context.Context) error {
 	mux.HandleFunc("/stats", d.handleStats)
 	mux.HandleFunc("/traffic", d.handleTraffic)
 	mux.HandleFunc("/traffic/", d.handleTrafficDetail)
+	mux.HandleFunc("/traffic/stream", d.handleTrafficStream)
+	mux.HandleFunc("/breakpoints", d.handleBreakpoints)
+	mux.HandleFunc("/breakpoints/", d.handleBreakpointItem)
+	mux.HandleFunc("/network", d.handleNetwork)
 
 	d.server = &http.Server{
 		Handler:           mux,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	// Streams never go idle, so end them when shutting down
+	d.server.RegisterOnShutdown(d.stream.close)
 
 	// Start the proxy
 	if d.onStart != nil {
@@ -303,9 +333,51 @@ func (d *Daemon) handleReload(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// StatsResponse is the response format for the /stats endpoint.
+type StatsResponse struct {
+	*Status
+
+	// Traffic holds aggregate statistics when a traffic querier is set
+	Traffic *backend.TrafficStats `json:"traffic,omitempty"`
+}
+
 func (d *Daemon) handleStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(d.Status()); err != nil {
+
+	response := StatsResponse{Status: d.Status()}
+
+	if d.trafficQuerier != nil {
+		q := r.URL.Query()
+		filter := &backend.TrafficFilter{}
+		if host := q.Get("host"); host != "" {
+			filter.Hosts = []string{host}
+		}
+		if since := q.Get("since"); since != "" {
+			dur, err := time.ParseDuration(since)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error":"invalid since: %s"}`, err.Error()), http.StatusBadRequest)
+				return
+			}
+			filter.StartTime = time.Now().Add(-dur)
+		}
+		if expr := q.Get("q"); expr != "" {
+			parsed, err := query.Parse(expr)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+				return
+			}
+			filter.Query = parsed
+		}
+
+		stats, err := d.trafficQuerier.Stats(r.Context(), filter)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"failed to compute stats: %s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		response.Traffic = stats
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -327,51 +399,10 @@ func (d *Daemon) handleTraffic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters
-	q := r.URL.Query()
-
-	filter := &backend.TrafficFilter{
-		Limit:  100, // Default limit
-		Offset: 0,
-		Desc:   true, // Newest first by default
-	}
-
-	// Limit
-	if limitStr := q.Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 1000 {
-			filter.Limit = limit
-		}
-	}
-
-	// Offset
-	if offsetStr := q.Get("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			filter.Offset = offset
-		}
-	}
-
-	// Host filter
-	if host := q.Get("host"); host != "" {
-		filter.Hosts = []string{host}
-	}
-
-	// Method filter
-	if method := q.Get("method"); method != "" {
-		filter.Methods = []string{method}
-	}
-
-	// Status code filter
-	if statusStr := q.Get("status"); statusStr != "" {
-		if status, err := strconv.Atoi(statusStr); err == nil {
-			filter.StatusCodes = []int{status}
-		}
-	}
-
-	// Min status (e.g., 400 for errors only)
-	if minStatusStr := q.Get("min_status"); minStatusStr != "" {
-		if minStatus, err := strconv.Atoi(minStatusStr); err == nil {
-			filter.MinStatus = minStatus
-		}
+	filter, err := parseTrafficFilter(r.URL.Query())
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Query traffic records
@@ -383,13 +414,9 @@ func (d *Daemon) handleTraffic(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get total count (without limit/offset)
-	countFilter := &backend.TrafficFilter{
-		Hosts:       filter.Hosts,
-		Methods:     filter.Methods,
-		StatusCodes: filter.StatusCodes,
-		MinStatus:   filter.MinStatus,
-	}
-	total, _ := d.trafficQuerier.Count(ctx, countFilter)
+	countFilter := *filter
+	countFilter.Limit, countFilter.Offset = 0, 0
+	total, _ := d.trafficQuerier.Count(ctx, &countFilter)
 
 	response := TrafficResponse{
 		Records: records,
@@ -412,15 +439,14 @@ func (d *Daemon) handleTrafficDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract ID from path: /traffic/{id}
-	path := r.URL.Path
-	if len(path) <= len("/traffic/") {
+	// Extract ID from path: /traffic/{id} or /traffic/{id}/export
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/traffic/"), "/")
+	if id == "" {
 		http.Error(w, `{"error":"missing traffic ID"}`, http.StatusBadRequest)
 		return
 	}
-	id := path[len("/traffic/"):]
-	if id == "" {
-		http.Error(w, `{"error":"missing traffic ID"}`, http.StatusBadRequest)
+	if action != "" && action != "export" {
+		jsonError(w, "not found", http.StatusNotFound)
 		return
 	}
 
@@ -432,6 +458,11 @@ func (d *Daemon) handleTrafficDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if action == "export" {
+		d.writeTrafficSnippet(w, r, detail)
+		return
+	}
+
 	if err := json.NewEncoder(w).Encode(detail); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -2,12 +2,18 @@ package daemon
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/grokify/mogo/log/slogutil"
+	"github.com/grokify/omniproxy/pkg/backend"
 )
 
 func TestDaemonConfig(t *testing.T) {
@@ -325,3 +331,108 @@ func TestDaemonControlAPIMethodNotAllowed(t *testing.T) {
 		t.Error("daemon should be running")
 	}
 }
+
+// statsQuerier is a TrafficQuerier that only answers Stats.
+type statsQuerier struct {
+	backend.TrafficQuerier
+	filter *backend.TrafficFilter
+}
+
+func (q *statsQuerier) Stats(_ context.Context, filter *backend.TrafficFilter) (*backend.TrafficStats, error) {
+	q.filter = filter
+	return &backend.TrafficStats{
+		TotalRequests: 2000000,
+		P99DurationMs: 250,
+		Hosts:         []backend.HostStats{{Host: "api.example.com", Requests: 2000000}},
+	}, nil
+}
+
+func TestDaemonStats(t *testing.T) {
+	d := New(&Config{Version: "test-1.0.0"})
+
+	// Without a querier, /stats reports the daemon status only
+	rec := httptest.NewRecorder()
+	d.handleStats(rec, httptest.NewRequest("GET", "/stats", nil))
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["version"] != "test-1.0.0" || resp["traffic"] != nil {
+		t.Errorf("unexpected stats: %v", resp)
+	}
+
+	q := &statsQuerier{}
+	d.SetTrafficQuerier(q)
+	rec = httptest.NewRecorder()
+	d.handleStats(rec, httptest.NewRequest("GET", "/stats?host=api.example.com&since=1h", nil))
+
+	var stats StatsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Status == nil || stats.Version != "test-1.0.0" {
+		t.Errorf("expected the daemon status, got %s", rec.Body)
+	}
+	if stats.Traffic == nil || stats.Traffic.P99DurationMs != 250 || stats.Traffic.Hosts[0].Host != "api.example.com" {
+		t.Errorf("unexpected traffic stats: %s", rec.Body)
+	}
+	if q.filter.Hosts[0] != "api.example.com" || time.Since(q.filter.StartTime) < time.Hour-time.Minute {
+		t.Errorf("unexpected filter: %+v", q.filter)
+	}
+
+	rec = httptest.NewRecorder()
+	d.handleStats(rec, httptest.NewRequest("GET", "/stats?since=yesterday", nil))
+	if rec.Code != 400 {
+		t.Errorf("expected 400 for an invalid since, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	d.handleStats(rec, httptest.NewRequest("GET", "/stats?q="+url.QueryEscape("status >= 500"), nil))
+	if rec.Code != 200 || q.filter.Query == nil {
+		t.Errorf("expected the expression in the filter, got %d: %+v", rec.Code, q.filter)
+	}
+}
+
+// recordingQuerier is a TrafficQuerier that records the filters of Query
+// and Count.
+type recordingQuerier struct {
+	backend.TrafficQuerier
+	query, count *backend.TrafficFilter
+}
+
+func (q *recordingQuerier) Query(_ context.Context, filter *backend.TrafficFilter) ([]*backend.TrafficRecord, error) {
+	q.query = filter
+	return []*backend.TrafficRecord{{ID: "1", Host: "api.example.com"}}, nil
+}
+
+func (q *recordingQuerier) Count(_ context.Context, filter *backend.TrafficFilter) (int64, error) {
+	q.count = filter
+	return 1, nil
+}
+
+func TestDaemonTrafficQuery(t *testing.T) {
+	d := New(&Config{Version: "test-1.0.0"})
+	q := &recordingQuerier{}
+	d.SetTrafficQuerier(q)
+
+	expr := `host ~ "*.example.com" && status >= 500`
+	rec := httptest.NewRecorder()
+	d.handleTraffic(rec, httptest.NewRequest("GET", "/traffic?method=GET&q="+url.QueryEscape(expr), nil))
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if q.query.Query == nil || q.query.Query.String() != expr || q.query.Methods[0] != "GET" {
+		t.Errorf("unexpected filter: %+v", q.query)
+	}
+
+	// The total counts the same expression
+	if q.count.Query != q.query.Query {
+		t.Errorf("unexpected count filter: %+v", q.count)
+	}
+
+	rec = httptest.NewRecorder()
+	d.handleTraffic(rec, httptest.NewRequest("GET", "/traffic?q="+url.QueryEscape("status >= high"), nil))
+	if rec.Code != 400 || !strings.Contains(rec.Body.String(), "expected a number") {
+		t.Errorf("expected 400 for an invalid expression, got %d: %s", rec.Code, rec.Body)
+	}
+}
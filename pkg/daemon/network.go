@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grokify/omniproxy/pkg/netsim"
+)
+
+// NetworkResponse is the response format for the /network endpoint.
+type NetworkResponse struct {
+	// Config is the active network simulation settings
+	Config *netsim.Config `json:"config"`
+	// Profiles are all available profiles, built-in and custom
+	Profiles []netsim.Profile `json:"profiles"`
+}
+
+// handleNetwork shows (GET) and replaces (PUT or POST) the network
+// simulation settings.
+func (d *Daemon) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if d.network == nil {
+		jsonError(w, "network simulation not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var cfg netsim.Config
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			jsonError(w, "invalid network config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := d.network.Apply(&cfg); err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, NetworkResponse{
+		Config:   d.network.Config(),
+		Profiles: d.network.Profiles(),
+	})
+}
+
+// GetNetwork retrieves the network simulation settings and available profiles.
+func (c *Client) GetNetwork() (*NetworkResponse, error) {
+	var res NetworkResponse
+	if err := c.doJSON(http.MethodGet, "/network", nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to get network settings: %w", err)
+	}
+	return &res, nil
+}
+
+// SetNetwork replaces the network simulation settings.
+func (c *Client) SetNetwork(cfg *netsim.Config) (*NetworkResponse, error) {
+	var res NetworkResponse
+	if err := c.doJSON(http.MethodPut, "/network", cfg, &res); err != nil {
+		return nil, fmt.Errorf("failed to set network settings: %w", err)
+	}
+	return &res, nil
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grokify/omniproxy/pkg/netsim"
+)
+
+func TestDaemonNetwork(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "omniproxyd-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &Config{
+		PIDFile:    filepath.Join(tmpDir, "test.pid"),
+		SocketPath: filepath.Join(tmpDir, "test.sock"),
+	}
+
+	sim, err := netsim.New(&netsim.Config{Default: "3g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(cfg)
+	d.SetNetwork(sim)
+
+	ctx := context.Background()
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	defer func() { _ = d.Stop(ctx) }()
+
+	client := NewClient(cfg.SocketPath)
+
+	res, err := client.GetNetwork()
+	if err != nil {
+		t.Fatalf("failed to get network settings: %v", err)
+	}
+	if res.Config.Default != "3g" || len(res.Profiles) != len(netsim.BuiltinProfiles()) {
+		t.Errorf("unexpected network settings: %+v", res)
+	}
+
+	res, err = client.SetNetwork(&netsim.Config{
+		Profiles: []netsim.Profile{{Name: "lossy", ResetRate: 0.2}},
+		Hosts:    []netsim.HostProfile{{Host: "api.example.com", Profile: "lossy"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to set network settings: %v", err)
+	}
+	if res.Config.Default != "" || len(res.Profiles) != len(netsim.BuiltinProfiles())+1 {
+		t.Errorf("unexpected network settings: %+v", res)
+	}
+	if p := sim.ProfileFor("api.example.com"); p == nil || p.Name != "lossy" {
+		t.Errorf("expected lossy profile for api.example.com, got %+v", p)
+	}
+
+	// Invalid settings are rejected and leave the active ones in place
+	if _, err := client.SetNetwork(&netsim.Config{Default: "5g"}); err == nil {
+		t.Error("expected unknown profile to be rejected")
+	}
+	if p := sim.ProfileFor("api.example.com"); p == nil || p.Name != "lossy" {
+		t.Errorf("expected settings to be unchanged, got %+v", p)
+	}
+}
@@ -0,0 +1,428 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+const (
+	// streamBufferSize is the number of records buffered for each stream
+	// client. Records published while a client's buffer is full are dropped
+	// for that client.
+	streamBufferSize = 256
+
+	// streamHeartbeat is how often idle streams are pinged, so that both
+	// ends notice dropped connections.
+	streamHeartbeat = 15 * time.Second
+
+	// streamWriteTimeout bounds a single event write to a stream client.
+	streamWriteTimeout = 10 * time.Second
+
+	// streamCatchUpPage is the number of stored records read at a time when
+	// a client resumes from a cursor.
+	streamCatchUpPage = 500
+)
+
+// TrafficEvent is a traffic record pushed by the /traffic/stream endpoint.
+type TrafficEvent struct {
+	// Cursor identifies the record's position; pass it back as ?cursor= or
+	// Last-Event-ID to resume after it
+	Cursor string `json:"cursor"`
+
+	// Record summarizes the record. ID is only set for records read back
+	// from the traffic store, since live records are stored asynchronously
+	Record *backend.TrafficRecord `json:"record"`
+
+	// Detail is the full record (with ?detail=true)
+	Detail *capture.Record `json:"detail,omitempty"`
+
+	// Dropped counts records skipped before this one because the client
+	// fell behind
+	Dropped int64 `json:"dropped,omitempty"`
+}
+
+// TrafficStreamOptions selects the records of a traffic stream.
+type TrafficStreamOptions struct {
+	// Query is a filter expression records must match
+	Query string
+	// Detail includes full records in events
+	Detail bool
+	// Cursor resumes after the event with this cursor, reading missed
+	// records from the traffic store
+	Cursor string
+}
+
+// streamCursor returns the cursor of a record that started at t.
+func streamCursor(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// parseStreamCursor parses a cursor returned by streamCursor.
+func parseStreamCursor(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return time.Unix(0, n), nil
+}
+
+// trafficBroker fans captured records out to stream clients.
+type trafficBroker struct {
+	mu     sync.Mutex
+	subs   map[*streamSubscriber]struct{}
+	closed bool
+}
+
+// streamSubscriber is a stream client's filter and record buffer.
+type streamSubscriber struct {
+	query   *query.Query
+	records chan *capture.Record
+	done    chan struct{} // closed when the daemon shuts down
+	dropped atomic.Int64
+}
+
+func newTrafficBroker() *trafficBroker {
+	return &trafficBroker{subs: make(map[*streamSubscriber]struct{})}
+}
+
+// subscribe registers a stream client for records matching q (all if nil).
+func (b *trafficBroker) subscribe(q *query.Query) *streamSubscriber {
+	s := &streamSubscriber{
+		query:   q,
+		records: make(chan *capture.Record, streamBufferSize),
+		done:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.done)
+	} else {
+		b.subs[s] = struct{}{}
+	}
+	return s
+}
+
+// unsubscribe removes a stream client.
+func (b *trafficBroker) unsubscribe(s *streamSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, s)
+}
+
+// publish offers rec to every matching client without blocking.
+func (b *trafficBroker) publish(rec *capture.Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if s.query != nil && !s.query.Match(rec) {
+			continue
+		}
+		select {
+		case s.records <- rec:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// close ends all streams.
+func (b *trafficBroker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subs {
+		close(s.done)
+		delete(b.subs, s)
+	}
+}
+
+// Publish pushes a captured record to /traffic/stream clients. It never
+// blocks, so it can be registered with Capturer.AddHandler.
+func (d *Daemon) Publish(rec *capture.Record) {
+	d.stream.publish(rec)
+}
+
+// handleTrafficStream streams new records as Server-Sent Events, or as
+// WebSocket text messages when the client asks for an upgrade.
+func (d *Daemon) handleTrafficStream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var expr *query.Query
+	if s := q.Get("q"); s != "" {
+		var err error
+		if expr, err = query.Parse(s); err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	detail, _ := strconv.ParseBool(q.Get("detail"))
+
+	cursor := q.Get("cursor")
+	if cursor == "" {
+		cursor = r.Header.Get("Last-Event-ID")
+	}
+	var since time.Time
+	if cursor != "" {
+		var err error
+		if since, err = parseStreamCursor(cursor); err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Subscribe before catching up, so no record falls between the two
+	sub := d.stream.subscribe(expr)
+	defer d.stream.unsubscribe(sub)
+
+	s := &trafficStream{d: d, sub: sub, query: expr, detail: detail, since: since}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := conn.CloseRead(r.Context())
+		s.send = func(ev *TrafficEvent) error {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+			defer cancel()
+			return conn.Write(ctx, websocket.MessageText, data)
+		}
+		s.ping = func() error {
+			ctx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+			defer cancel()
+			return conn.Ping(ctx)
+		}
+		if err := s.run(ctx); err == nil {
+			_ = conn.Close(websocket.StatusGoingAway, "daemon stopping")
+		}
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		jsonError(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	rc := http.NewResponseController(w)
+	write := func(format string, args ...interface{}) error {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	s.send = func(ev *TrafficEvent) error {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		return write("id: %s\ndata: %s\n\n", ev.Cursor, data)
+	}
+	s.ping = func() error {
+		return write(": ping\n\n")
+	}
+	_ = s.run(r.Context())
+}
+
+// trafficStream sends the events of one stream client.
+type trafficStream struct {
+	d      *Daemon
+	sub    *streamSubscriber
+	query  *query.Query
+	detail bool
+	since  time.Time // resume cursor, zero for live records only
+	send   func(*TrafficEvent) error
+	ping   func() error
+}
+
+// run catches up from the cursor, then sends live records until the client
+// disconnects (returning its error) or the daemon stops (returning nil).
+func (s *trafficStream) run(ctx context.Context) error {
+	// Live records already sent while catching up are skipped
+	caughtUp, err := s.catchUp(ctx)
+	if err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.sub.done:
+			return nil
+		case <-heartbeat.C:
+			if err := s.ping(); err != nil {
+				return err
+			}
+		case rec := <-s.sub.records:
+			if !caughtUp.IsZero() && !rec.StartTime.After(caughtUp) {
+				continue
+			}
+			ev := &TrafficEvent{
+				Cursor:  streamCursor(rec.StartTime),
+				Record:  backend.NewTrafficRecord(rec),
+				Dropped: s.sub.dropped.Swap(0),
+			}
+			if s.detail {
+				ev.Detail = rec
+			}
+			if err := s.send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// catchUp sends stored records that started after the cursor, oldest first,
+// and returns the start time of the last one. Records still queued for an
+// asynchronous store arrive live instead.
+func (s *trafficStream) catchUp(ctx context.Context) (time.Time, error) {
+	if s.since.IsZero() || s.d.trafficQuerier == nil {
+		return time.Time{}, nil
+	}
+
+	filter := &backend.TrafficFilter{
+		StartTime: s.since.Add(time.Nanosecond),
+		Query:     s.query,
+		OrderBy:   "started_at",
+		Limit:     streamCatchUpPage,
+	}
+	var last time.Time
+	for {
+		records, err := s.d.trafficQuerier.Query(ctx, filter)
+		if err != nil {
+			return last, fmt.Errorf("failed to query traffic: %w", err)
+		}
+		for _, r := range records {
+			ev := &TrafficEvent{Cursor: streamCursor(r.StartTime), Record: r}
+			if s.detail {
+				detail, err := s.d.trafficQuerier.GetByID(ctx, r.ID)
+				if err != nil {
+					return last, fmt.Errorf("failed to get traffic: %w", err)
+				}
+				ev.Detail = detail.Record()
+			}
+			if err := s.send(ev); err != nil {
+				return last, err
+			}
+			last = r.StartTime
+		}
+		if len(records) < filter.Limit {
+			return last, nil
+		}
+		filter.Offset += len(records)
+	}
+}
+
+// StreamTraffic streams new traffic records from the daemon as Server-Sent
+// Events, calling fn for each until ctx is canceled, fn returns an error, or
+// the daemon closes the stream.
+func (c *Client) StreamTraffic(ctx context.Context, opts *TrafficStreamOptions, fn func(*TrafficEvent) error) error {
+	params := url.Values{}
+	if opts != nil {
+		if opts.Query != "" {
+			params.Set("q", opts.Query)
+		}
+		if opts.Detail {
+			params.Set("detail", "true")
+		}
+		if opts.Cursor != "" {
+			params.Set("cursor", opts.Cursor)
+		}
+	}
+	path := "http://unix/traffic/stream"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Streams outlive the client's request timeout
+	httpClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("failed to stream traffic: %s", apiErr.Error)
+		}
+		return fmt.Errorf("failed to stream traffic: %s", strings.TrimSpace(string(data)))
+	}
+
+	// Events are "data:" lines ended by a blank line; ids and comments are
+	// not needed since the cursor is part of the event
+	br := bufio.NewReader(resp.Body)
+	var data strings.Builder
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read traffic stream: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var ev TrafficEvent
+		if err := json.Unmarshal([]byte(data.String()), &ev); err != nil {
+			return fmt.Errorf("failed to decode traffic event: %w", err)
+		}
+		data.Reset()
+		if err := fn(&ev); err != nil {
+			return err
+		}
+	}
+}
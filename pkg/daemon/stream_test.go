@@ -0,0 +1,236 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+func streamRecord(start time.Time, status int) *capture.Record {
+	return &capture.Record{
+		StartTime: start,
+		Request: capture.RequestRecord{
+			Method: "GET",
+			URL:    "https://api.example.com/v1/items",
+			Host:   "api.example.com",
+			Path:   "/v1/items",
+			Scheme: "https",
+		},
+		Response: capture.ResponseRecord{Status: status, Body: "ok"},
+	}
+}
+
+func startStreamDaemon(t *testing.T, tq backend.TrafficQuerier) (*Daemon, *Config) {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "omniproxyd-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	cfg := &Config{
+		PIDFile:    filepath.Join(tmpDir, "test.pid"),
+		SocketPath: filepath.Join(tmpDir, "test.sock"),
+	}
+	d := New(cfg)
+	if tq != nil {
+		d.SetTrafficQuerier(tq)
+	}
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	t.Cleanup(func() { _ = d.Stop(context.Background()) })
+	return d, cfg
+}
+
+// publishUntil publishes rec until done is closed, since a stream only
+// receives records published after it subscribes.
+func publishUntil(d *Daemon, rec *capture.Record, done <-chan struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		d.Publish(rec)
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func TestTrafficBrokerDrops(t *testing.T) {
+	b := newTrafficBroker()
+	q, err := query.Parse(`status >= 500`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := b.subscribe(q)
+
+	rec := streamRecord(time.Now(), 502)
+	for i := 0; i < streamBufferSize+3; i++ {
+		b.publish(rec)
+		b.publish(streamRecord(time.Now(), 200)) // filtered out
+	}
+	if len(sub.records) != streamBufferSize {
+		t.Errorf("expected a full buffer, got %d records", len(sub.records))
+	}
+	if n := sub.dropped.Load(); n != 3 {
+		t.Errorf("expected 3 dropped records, got %d", n)
+	}
+
+	b.close()
+	select {
+	case <-sub.done:
+	default:
+		t.Error("expected close to end subscriptions")
+	}
+	if late := b.subscribe(nil); late == nil {
+		t.Fatal("expected a subscriber")
+	} else {
+		select {
+		case <-late.done:
+		default:
+			t.Error("expected subscriptions after close to be done")
+		}
+	}
+}
+
+func TestDaemonTrafficStream(t *testing.T) {
+	d, cfg := startStreamDaemon(t, nil)
+	client := NewClient(cfg.SocketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan struct{})
+	go publishUntil(d, streamRecord(start, 200), done)
+	go publishUntil(d, streamRecord(start.Add(time.Second), 503), done)
+	defer close(done)
+
+	var got *TrafficEvent
+	errFound := errors.New("found")
+	err := client.StreamTraffic(ctx, &TrafficStreamOptions{Query: `status >= 500`, Detail: true}, func(ev *TrafficEvent) error {
+		got = ev
+		return errFound
+	})
+	if !errors.Is(err, errFound) {
+		t.Fatalf("failed to stream traffic: %v", err)
+	}
+	if got.Record == nil || got.Record.Status != 503 || got.Record.Host != "api.example.com" {
+		t.Errorf("expected the 503 record, got %+v", got.Record)
+	}
+	if got.Detail == nil || got.Detail.Response.Body != "ok" {
+		t.Errorf("expected record detail, got %+v", got.Detail)
+	}
+	if got.Cursor != streamCursor(start.Add(time.Second)) {
+		t.Errorf("unexpected cursor %q", got.Cursor)
+	}
+
+	// Invalid expressions are rejected before streaming
+	if err := client.StreamTraffic(ctx, &TrafficStreamOptions{Query: `status >=`}, func(*TrafficEvent) error {
+		return nil
+	}); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
+
+func TestDaemonTrafficStreamResume(t *testing.T) {
+	ctx := context.Background()
+	store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for i, status := range []int{200, 502, 503} {
+		if err := store.Store(ctx, streamRecord(start.Add(time.Duration(i)*time.Second), status)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, cfg := startStreamDaemon(t, store)
+	client := NewClient(cfg.SocketPath)
+
+	streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Resuming after the first record replays the rest in capture order
+	var events []*TrafficEvent
+	errDone := errors.New("done")
+	err = client.StreamTraffic(streamCtx, &TrafficStreamOptions{Cursor: streamCursor(start), Detail: true}, func(ev *TrafficEvent) error {
+		events = append(events, ev)
+		if len(events) == 2 {
+			return errDone
+		}
+		return nil
+	})
+	if !errors.Is(err, errDone) {
+		t.Fatalf("failed to stream traffic: %v", err)
+	}
+	if events[0].Record.Status != 502 || events[1].Record.Status != 503 {
+		t.Errorf("expected the records after the cursor, got %+v, %+v", events[0].Record, events[1].Record)
+	}
+	if events[0].Record.ID == "" || events[0].Detail == nil || events[0].Detail.Response.Body != "ok" {
+		t.Errorf("expected stored record detail, got %+v", events[0])
+	}
+	if events[1].Cursor != streamCursor(start.Add(2*time.Second)) {
+		t.Errorf("unexpected cursor %q", events[1].Cursor)
+	}
+}
+
+func TestDaemonTrafficStreamWebSocket(t *testing.T) {
+	d, cfg := startStreamDaemon(t, nil)
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", cfg.SocketPath)
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws://unix/traffic/stream?q=method+%3D%3D+GET", &websocket.DialOptions{HTTPClient: httpClient})
+	if err != nil {
+		t.Fatalf("failed to dial stream: %v", err)
+	}
+	defer conn.CloseNow()
+
+	done := make(chan struct{})
+	defer close(done)
+	go publishUntil(d, streamRecord(time.Now(), 201), done)
+
+	typ, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if typ != websocket.MessageText {
+		t.Errorf("expected a text message, got %v", typ)
+	}
+	var ev TrafficEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Record == nil || ev.Record.Status != 201 || ev.Detail != nil {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+}
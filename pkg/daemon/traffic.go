@@ -0,0 +1,313 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/export"
+	"github.com/grokify/omniproxy/pkg/query"
+)
+
+// parseTrafficFilter parses the query parameters of the /traffic endpoint.
+// Unparseable limits and status codes are ignored; other invalid values
+// are errors.
+func parseTrafficFilter(q url.Values) (*backend.TrafficFilter, error) {
+	filter := &backend.TrafficFilter{
+		Limit:  100, // Default limit
+		Offset: 0,
+		Desc:   true, // Newest first by default
+	}
+
+	// Limit
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 1000 {
+			filter.Limit = limit
+		}
+	}
+
+	// Offset
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	// Sort column, descending with a - prefix (default -started_at)
+	if sort := q.Get("sort"); sort != "" {
+		filter.OrderBy = strings.TrimPrefix(sort, "-")
+		filter.Desc = strings.HasPrefix(sort, "-")
+	}
+
+	// Time range (RFC 3339, or a duration ago such as 1h)
+	var err error
+	if filter.StartTime, err = parseTimeParam("since", q.Get("since")); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = parseTimeParam("until", q.Get("until")); err != nil {
+		return nil, err
+	}
+
+	// Request filters
+	filter.Hosts = q["host"]
+	filter.Paths = q["path"]
+	filter.Methods = q["method"]
+
+	// Status code filters
+	for _, statusStr := range q["status"] {
+		if status, err := strconv.Atoi(statusStr); err == nil {
+			filter.StatusCodes = append(filter.StatusCodes, status)
+		}
+	}
+
+	// Min status (e.g., 400 for errors only)
+	if minStatusStr := q.Get("min_status"); minStatusStr != "" {
+		if minStatus, err := strconv.Atoi(minStatusStr); err == nil {
+			filter.MinStatus = minStatus
+		}
+	}
+	if maxStatusStr := q.Get("max_status"); maxStatusStr != "" {
+		if maxStatus, err := strconv.Atoi(maxStatusStr); err == nil {
+			filter.MaxStatus = maxStatus
+		}
+	}
+
+	// Record type filter (http, websocket, websocket_frame)
+	filter.RecordTypes = q["type"]
+
+	// WebSocket connection filter (upgrade plus all its frames)
+	filter.ConnectionID = q.Get("connection_id")
+
+	// Authenticated proxy user filter
+	filter.User = q.Get("user")
+
+	// Header filters (name or name:value, value matched as a substring)
+	for _, h := range q["header"] {
+		filter.Headers = append(filter.Headers, parseHeaderMatch(h, false))
+	}
+	for _, h := range q["response_header"] {
+		filter.Headers = append(filter.Headers, parseHeaderMatch(h, true))
+	}
+
+	// Body filters (text search and path=value JSON field predicates)
+	filter.BodyContains = q.Get("body")
+	for _, f := range q["body_field"] {
+		filter.BodyFields = append(filter.BodyFields, parseBodyFieldMatch(f, false))
+	}
+	for _, f := range q["response_body_field"] {
+		filter.BodyFields = append(filter.BodyFields, parseBodyFieldMatch(f, true))
+	}
+
+	// Duration filters (e.g. 250ms, 2s)
+	if v := q.Get("min_duration"); v != "" {
+		if filter.MinDuration, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid min_duration: %w", err)
+		}
+	}
+	if v := q.Get("max_duration"); v != "" {
+		if filter.MaxDuration, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid max_duration: %w", err)
+		}
+	}
+
+	// Metadata filters
+	filter.Tags = q["tag"]
+	filter.ClientIP = q.Get("client_ip")
+
+	// Filter expression
+	if expr := q.Get("q"); expr != "" {
+		if filter.Query, err = query.Parse(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// trafficFilterParams encodes a filter as /traffic query parameters.
+func trafficFilterParams(filter *backend.TrafficFilter) url.Values {
+	q := url.Values{}
+	if filter == nil {
+		return q
+	}
+
+	if filter.Limit > 0 {
+		q.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		q.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	if filter.OrderBy != "" {
+		sort := filter.OrderBy
+		if filter.Desc {
+			sort = "-" + sort
+		}
+		q.Set("sort", sort)
+	}
+	if !filter.StartTime.IsZero() {
+		q.Set("since", filter.StartTime.Format(time.RFC3339Nano))
+	}
+	if !filter.EndTime.IsZero() {
+		q.Set("until", filter.EndTime.Format(time.RFC3339Nano))
+	}
+
+	q["host"] = filter.Hosts
+	q["path"] = filter.Paths
+	q["method"] = filter.Methods
+	for _, status := range filter.StatusCodes {
+		q.Add("status", strconv.Itoa(status))
+	}
+	if filter.MinStatus > 0 {
+		q.Set("min_status", strconv.Itoa(filter.MinStatus))
+	}
+	if filter.MaxStatus > 0 {
+		q.Set("max_status", strconv.Itoa(filter.MaxStatus))
+	}
+	q["type"] = filter.RecordTypes
+	if filter.ConnectionID != "" {
+		q.Set("connection_id", filter.ConnectionID)
+	}
+	if filter.User != "" {
+		q.Set("user", filter.User)
+	}
+
+	for _, h := range filter.Headers {
+		v := h.Name
+		if h.Value != "" {
+			v += ":" + h.Value
+		}
+		if h.Response {
+			q.Add("response_header", v)
+		} else {
+			q.Add("header", v)
+		}
+	}
+	if filter.BodyContains != "" {
+		q.Set("body", filter.BodyContains)
+	}
+	for _, f := range filter.BodyFields {
+		v := f.Path
+		if f.Value != "" {
+			v += "=" + f.Value
+		}
+		if f.Response {
+			q.Add("response_body_field", v)
+		} else {
+			q.Add("body_field", v)
+		}
+	}
+
+	if filter.MinDuration > 0 {
+		q.Set("min_duration", filter.MinDuration.String())
+	}
+	if filter.MaxDuration > 0 {
+		q.Set("max_duration", filter.MaxDuration.String())
+	}
+	q["tag"] = filter.Tags
+	if filter.ClientIP != "" {
+		q.Set("client_ip", filter.ClientIP)
+	}
+	if filter.Query != nil {
+		q.Set("q", filter.Query.String())
+	}
+
+	// Drop unset repeated parameters
+	for k, v := range q {
+		if len(v) == 0 {
+			delete(q, k)
+		}
+	}
+	return q
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a duration relative to now.
+func parseTimeParam(name, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: expected RFC 3339 time or duration, got %q", name, s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// parseHeaderMatch parses a name or name:value header filter.
+func parseHeaderMatch(s string, response bool) backend.HeaderMatch {
+	name, value, _ := strings.Cut(s, ":")
+	return backend.HeaderMatch{
+		Name:     strings.TrimSpace(name),
+		Value:    strings.TrimSpace(value),
+		Response: response,
+	}
+}
+
+// parseBodyFieldMatch parses a path or path=value JSON body field filter.
+func parseBodyFieldMatch(s string, response bool) backend.BodyFieldMatch {
+	path, value, _ := strings.Cut(s, "=")
+	return backend.BodyFieldMatch{
+		Path:     path,
+		Value:    value,
+		Response: response,
+	}
+}
+
+// writeTrafficSnippet writes code that repeats the request of detail, in
+// the language given by ?as= (default curl). With ?placeholders=true,
+// header values redacted at capture time are replaced with placeholders.
+func (d *Daemon) writeTrafficSnippet(w http.ResponseWriter, r *http.Request, detail *backend.TrafficDetail) {
+	q := r.URL.Query()
+
+	format := export.FormatCurl
+	if as := q.Get("as"); as != "" {
+		var err error
+		if format, err = export.ParseFormat(as); err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	opts := &export.Options{BodyFile: q.Get("body_file")}
+	opts.Placeholders, _ = strconv.ParseBool(q.Get("placeholders"))
+
+	snippet, err := export.GenerateDetail(detail, format, opts)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if snippet.BodyFile != "" {
+		w.Header().Set("X-Body-File", snippet.BodyFile)
+	}
+	_, _ = io.WriteString(w, strings.TrimSuffix(snippet.Code, "\n")+"\n")
+}
+
+// QueryTraffic retrieves stored traffic records matching filter, with the
+// total number of matches.
+func (c *Client) QueryTraffic(filter *backend.TrafficFilter) (*TrafficResponse, error) {
+	var res TrafficResponse
+	path := "/traffic"
+	if params := trafficFilterParams(filter); len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	if err := c.doJSON(http.MethodGet, path, nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to query traffic: %w", err)
+	}
+	return &res, nil
+}
+
+// GetTraffic retrieves the full details of a stored traffic record.
+func (c *Client) GetTraffic(id string) (*backend.TrafficDetail, error) {
+	var res backend.TrafficDetail
+	if err := c.doJSON(http.MethodGet, "/traffic/"+url.PathEscape(id), nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to get traffic: %w", err)
+	}
+	return &res, nil
+}
@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/query"
+	"github.com/grokify/omniproxy/pkg/redact"
+)
+
+func TestTrafficFilterParams(t *testing.T) {
+	q, err := query.Parse(`status >= 500 || duration > 1s`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	filter := &backend.TrafficFilter{
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+		Hosts:        []string{"api.example.com", "*.example.org"},
+		Paths:        []string{"/v1/*"},
+		Methods:      []string{"GET", "POST"},
+		StatusCodes:  []int{200, 201},
+		MinStatus:    200,
+		MaxStatus:    299,
+		RecordTypes:  []string{"http"},
+		ConnectionID: "conn-1",
+		User:         "alice",
+		Headers:      []backend.HeaderMatch{{Name: "x-tenant", Value: "acme"}, {Name: "request-id", Response: true}},
+		BodyContains: "ORD-1",
+		BodyFields:   []backend.BodyFieldMatch{{Path: "$.order.id", Value: "7"}, {Path: "$.ok", Response: true}},
+		MinDuration:  250 * time.Millisecond,
+		MaxDuration:  2 * time.Second,
+		Tags:         []string{"network:3g"},
+		ClientIP:     "10.0.0.7",
+		Query:        q,
+		Limit:        50,
+		Offset:       100,
+		OrderBy:      "started_at",
+	}
+
+	got, err := parseTrafficFilter(trafficFilterParams(filter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Query == nil || got.Query.String() != q.String() {
+		t.Errorf("expected query %q, got %v", q, got.Query)
+	}
+	got.Query = q
+	if !reflect.DeepEqual(got, filter) {
+		t.Errorf("filter did not round-trip:\n got %+v\nwant %+v", got, filter)
+	}
+
+	// An empty filter takes the endpoint defaults
+	got, err = parseTrafficFilter(trafficFilterParams(&backend.TrafficFilter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Limit != 100 || !got.Desc || got.OrderBy != "" {
+		t.Errorf("unexpected defaults: %+v", got)
+	}
+}
+
+func TestDaemonTrafficClient(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "omniproxyd-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	ctx := context.Background()
+	store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{
+		DatabaseURL: "sqlite::memory:",
+		ProxyName:   "test-proxy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for i, status := range []int{200, 502, 503} {
+		err := store.Store(ctx, &capture.Record{
+			StartTime: start.Add(time.Duration(i) * time.Second),
+			Request: capture.RequestRecord{
+				Method: "GET",
+				URL:    "https://api.example.com/v1/items",
+				Host:   "api.example.com",
+				Path:   "/v1/items",
+				Scheme: "https",
+				Headers: capture.Headers{
+					{Name: "accept", Value: "application/json"},
+					{Name: "authorization", Value: redact.Placeholder},
+				},
+			},
+			Response: capture.ResponseRecord{Status: status, Body: "ok"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &Config{
+		PIDFile:    filepath.Join(tmpDir, "test.pid"),
+		SocketPath: filepath.Join(tmpDir, "test.sock"),
+	}
+	d := New(cfg)
+	d.SetTrafficQuerier(store)
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	defer func() { _ = d.Stop(ctx) }()
+
+	client := NewClient(cfg.SocketPath)
+
+	q, err := query.Parse(`status >= 500`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.QueryTraffic(&backend.TrafficFilter{
+		StartTime: start,
+		Query:     q,
+		OrderBy:   "started_at",
+	})
+	if err != nil {
+		t.Fatalf("failed to query traffic: %v", err)
+	}
+	if res.Total != 2 || len(res.Records) != 2 || res.Records[0].Status != 502 || res.Records[1].Status != 503 {
+		t.Fatalf("expected the 5xx records in capture order, got %+v", res)
+	}
+
+	detail, err := client.GetTraffic(res.Records[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get traffic: %v", err)
+	}
+	if detail.ID != res.Records[0].ID || detail.Record().Response.Body != "ok" || !detail.StartTime.Equal(start.Add(time.Second)) {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+
+	if _, err := client.GetTraffic("missing"); err == nil {
+		t.Error("expected an error for a missing record")
+	}
+
+	resp, err := client.httpClient.Get("http://unix/traffic/" + res.Records[0].ID + "/export?as=httpie&placeholders=true")
+	if err != nil {
+		t.Fatalf("failed to export traffic: %v", err)
+	}
+	defer resp.Body.Close()
+	code, _ := io.ReadAll(resp.Body)
+	want := "http GET https://api.example.com/v1/items \\\n  accept:application/json \\\n  'authorization:<authorization>'\n"
+	if resp.StatusCode != http.StatusOK || string(code) != want {
+		t.Errorf("unexpected export (%d):\n%s\nwant:\n%s", resp.StatusCode, code, want)
+	}
+
+	resp, err = client.httpClient.Get("http://unix/traffic/" + res.Records[0].ID + "/export?as=ruby")
+	if err != nil {
+		t.Fatalf("failed to export traffic: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown snippet format, got %d", resp.StatusCode)
+	}
+}
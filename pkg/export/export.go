@@ -0,0 +1,237 @@
+// Package export generates code snippets that repeat captured requests, for
+// sharing reproductions outside of omniproxy.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/redact"
+)
+
+// Format is the language of a snippet.
+type Format string
+
+const (
+	// FormatCurl is a curl command line
+	FormatCurl Format = "curl"
+	// FormatHTTPie is an HTTPie command line
+	FormatHTTPie Format = "httpie"
+	// FormatGo is a Go program using net/http
+	FormatGo Format = "go"
+	// FormatPython is a Python script using requests
+	FormatPython Format = "python"
+	// FormatFetch is a JavaScript module using fetch
+	FormatFetch Format = "fetch"
+)
+
+// Formats lists the supported snippet formats.
+var Formats = []Format{FormatCurl, FormatHTTPie, FormatGo, FormatPython, FormatFetch}
+
+// ParseFormat returns the snippet format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown snippet format %q (expected %s)", s, strings.Join(names, ", "))
+}
+
+// DefaultBodyFile is the file binary request bodies are read from.
+const DefaultBodyFile = "body.bin"
+
+// Options configures snippet generation.
+type Options struct {
+	// Placeholders replaces header values redacted at capture time with
+	// placeholders to fill in, such as <authorization>
+	Placeholders bool
+	// BodyFile is the file binary request bodies are read from
+	// (default: body.bin)
+	BodyFile string
+}
+
+// Snippet is code that repeats a captured request.
+type Snippet struct {
+	// Format is the language of Code
+	Format Format `json:"format"`
+	// Code is the snippet source
+	Code string `json:"code"`
+	// BodyFile is the file Code reads the request body from, set when the
+	// body is binary
+	BodyFile string `json:"bodyFile,omitempty"`
+	// Body is the content to save as BodyFile; nil when the body was not
+	// captured and must be supplied by hand
+	Body []byte `json:"body,omitempty"`
+}
+
+// Placeholder returns the value that replaces a redacted header.
+func Placeholder(name string) string {
+	return "<" + strings.ToLower(name) + ">"
+}
+
+// Generate returns a snippet in format that repeats the request of rec.
+func Generate(rec *capture.Record, format Format, opts *Options) (*Snippet, error) {
+	return generate(newRequest(rec), format, opts)
+}
+
+// GenerateDetail returns a snippet in format that repeats a stored request.
+func GenerateDetail(d *backend.TrafficDetail, format Format, opts *Options) (*Snippet, error) {
+	return Generate(d.Record(), format, opts)
+}
+
+func generate(req *request, format Format, opts *Options) (*Snippet, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Placeholders {
+		req.addPlaceholders()
+	}
+
+	snippet := &Snippet{Format: format}
+	if req.binary {
+		snippet.BodyFile = opts.BodyFile
+		if snippet.BodyFile == "" {
+			snippet.BodyFile = DefaultBodyFile
+		}
+		snippet.Body = req.body
+	}
+
+	switch format {
+	case FormatCurl:
+		snippet.Code = curlSnippet(req, snippet.BodyFile)
+	case FormatHTTPie:
+		snippet.Code = httpieSnippet(req, snippet.BodyFile)
+	case FormatGo:
+		snippet.Code = goSnippet(req, snippet.BodyFile)
+	case FormatPython:
+		snippet.Code = pythonSnippet(req, snippet.BodyFile)
+	case FormatFetch:
+		snippet.Code = fetchSnippet(req, snippet.BodyFile)
+	default:
+		return nil, fmt.Errorf("unknown snippet format %q", format)
+	}
+	return snippet, nil
+}
+
+// header is a request header line.
+type header struct {
+	name  string
+	value string
+}
+
+// request is the part of a record that snippets repeat.
+type request struct {
+	method  string
+	url     string
+	headers []header // in captured order
+	// body is the request body, nil when there is none or it was not captured
+	body []byte
+	// binary is true when the body must be read from a file
+	binary bool
+}
+
+// skipHeaders are set by HTTP clients from the URL and body.
+var skipHeaders = map[string]bool{
+	"host":           true,
+	"content-length": true,
+}
+
+// newRequest extracts the request of rec.
+func newRequest(rec *capture.Record) *request {
+	req := &request{
+		method: rec.Request.Method,
+		url:    rec.Request.URL,
+	}
+	if req.method == "" {
+		req.method = "GET"
+	}
+
+	for _, h := range rec.Request.Headers {
+		name := strings.ToLower(h.Name)
+		// Bodies are recorded decoded, so they are sent without their encoding
+		if name == "content-encoding" && rec.Request.ContentEncoding != "" {
+			continue
+		}
+		if !skipHeaders[name] {
+			req.headers = append(req.headers, header{name: h.Name, value: h.Value})
+		}
+	}
+
+	switch body := rec.Request.Body.(type) {
+	case nil:
+	case string:
+		if rec.Request.IsBinary || body == capture.BinaryPlaceholder {
+			req.binary = true
+		} else if body != "" {
+			req.body = []byte(body)
+			req.binary = !isText(body)
+		}
+	default:
+		if data, err := json.Marshal(body); err == nil {
+			req.body = data
+		}
+	}
+	if rec.Request.IsBinary {
+		req.binary = true
+	}
+	return req
+}
+
+// addPlaceholders replaces redacted header values with placeholders.
+func (r *request) addPlaceholders() {
+	for i, h := range r.headers {
+		if redact.IsRedacted(h.value) {
+			r.headers[i].value = Placeholder(h.name)
+		}
+	}
+}
+
+// hasBody reports whether the snippet sends a body.
+func (r *request) hasBody() bool {
+	return r.binary || len(r.body) > 0
+}
+
+// joinedHeaders returns the headers with repeated values joined, for
+// languages whose header maps take one value per name.
+func (r *request) joinedHeaders() []header {
+	var joined []header
+	index := make(map[string]int)
+	for _, h := range r.headers {
+		lower := strings.ToLower(h.name)
+		i, ok := index[lower]
+		if !ok {
+			index[lower] = len(joined)
+			joined = append(joined, h)
+			continue
+		}
+		sep := ", "
+		if lower == "cookie" {
+			sep = "; "
+		}
+		joined[i].value += sep + h.value
+	}
+	return joined
+}
+
+// isText reports whether s can be embedded in source code as text.
+func isText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
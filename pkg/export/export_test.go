@@ -0,0 +1,222 @@
+package export
+
+import (
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/redact"
+)
+
+func postRecord() *capture.Record {
+	return &capture.Record{
+		Request: capture.RequestRecord{
+			Method: "POST",
+			URL:    "https://api.example.com/v1/orders?note=it's",
+			Host:   "api.example.com",
+			Headers: capture.Headers{
+				{Name: "host", Value: "api.example.com"},
+				{Name: "content-length", Value: "27"},
+				{Name: "content-type", Value: "application/json"},
+				{Name: "x-empty", Value: ""},
+			},
+			Body: map[string]interface{}{"item": "o'neil", "qty": float64(2)},
+		},
+	}
+}
+
+func TestGenerateCurl(t *testing.T) {
+	s, err := Generate(postRecord(), FormatCurl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `curl 'https://api.example.com/v1/orders?note=it'\''s' \
+  -H 'content-type: application/json' \
+  -H 'x-empty;' \
+  --data-raw '{"item":"o'\''neil","qty":2}'`
+	if s.Code != want {
+		t.Errorf("unexpected curl snippet:\n%s\nwant:\n%s", s.Code, want)
+	}
+	if s.BodyFile != "" || s.Body != nil {
+		t.Errorf("expected an inline body, got %+v", s)
+	}
+
+	// GET and HEAD need no method; other methods without a body do
+	rec := &capture.Record{Request: capture.RequestRecord{Method: "DELETE", URL: "https://example.com/a"}}
+	if s, _ := Generate(rec, FormatCurl, nil); s.Code != "curl https://example.com/a \\\n  -X DELETE" {
+		t.Errorf("unexpected DELETE snippet: %s", s.Code)
+	}
+	rec.Request.Method = "HEAD"
+	if s, _ := Generate(rec, FormatCurl, nil); s.Code != "curl https://example.com/a \\\n  --head" {
+		t.Errorf("unexpected HEAD snippet: %s", s.Code)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell")
+	}
+	for _, s := range []string{"", "plain", "it's", `a "b" $HOME`, "line\nbreak", `back\slash`, "*?[~]", "a b;c|d&e"} {
+		out, err := exec.Command(sh, "-c", "printf %s "+shellQuote(s)).Output()
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if string(out) != s {
+			t.Errorf("shellQuote(%q) = %s, shell read %q", s, shellQuote(s), out)
+		}
+	}
+}
+
+func TestGenerateBinaryBody(t *testing.T) {
+	rec := &capture.Record{
+		Request: capture.RequestRecord{
+			Method: "PUT",
+			URL:    "https://example.com/upload",
+			Body:   "\x89PNG\r\n\x1a\n\x00",
+		},
+	}
+	s, err := Generate(rec, FormatCurl, &Options{BodyFile: "image.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.BodyFile != "image.png" || string(s.Body) != "\x89PNG\r\n\x1a\n\x00" {
+		t.Errorf("expected a body file, got %+v", s)
+	}
+	if !strings.Contains(s.Code, "--data-binary @image.png") || !strings.Contains(s.Code, "-X PUT") {
+		t.Errorf("unexpected snippet: %s", s.Code)
+	}
+
+	// Bodies skipped at capture time still need a file, supplied by hand
+	rec.Request.Body = capture.BinaryPlaceholder
+	rec.Request.IsBinary = true
+	s, err = Generate(rec, FormatHTTPie, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.BodyFile != DefaultBodyFile || s.Body != nil || !strings.HasSuffix(s.Code, "< body.bin") {
+		t.Errorf("unexpected snippet: %+v", s)
+	}
+}
+
+func TestGeneratePlaceholders(t *testing.T) {
+	rec := postRecord()
+	rec.Request.Headers = append(rec.Request.Headers,
+		capture.Header{Name: "authorization", Value: redact.Placeholder},
+		capture.Header{Name: "x-api-key", Value: "[REDACTED:0a1b2c3d4e5f]"},
+		capture.Header{Name: "x-token", Value: "Bearer " + redact.Placeholder},
+	)
+
+	s, err := Generate(rec, FormatHTTPie, &Options{Placeholders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.Code, "'authorization:<authorization>'") || !strings.Contains(s.Code, "'x-api-key:<x-api-key>'") {
+		t.Errorf("expected placeholders for redacted headers: %s", s.Code)
+	}
+	if !strings.Contains(s.Code, "'x-token:Bearer [REDACTED]'") {
+		t.Errorf("expected partly redacted headers to be kept: %s", s.Code)
+	}
+
+	s, err = Generate(rec, FormatHTTPie, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.Code, "'authorization:[REDACTED]'") {
+		t.Errorf("expected redacted values without placeholders: %s", s.Code)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	rec := postRecord()
+	rec.Request.Headers = append(rec.Request.Headers, capture.Header{Name: "authorization", Value: `Bearer "x"`})
+	for _, body := range []interface{}{rec.Request.Body, nil, "line one\nline `two`", "\x00\x01"} {
+		rec.Request.Body = body
+		s, err := Generate(rec, FormatGo, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", s.Code, parser.AllErrors); err != nil {
+			t.Errorf("invalid Go for body %q: %v\n%s", body, err, s.Code)
+		}
+	}
+}
+
+func TestGenerateDetail(t *testing.T) {
+	d := &backend.TrafficDetail{
+		TrafficRecord: backend.TrafficRecord{Method: "GET", URL: "https://example.com/"},
+		RequestHeaders: map[string][]string{
+			"cookie": {"a=1", "b=2"},
+			"accept": {"text/html", "application/json"},
+		},
+	}
+
+	s, err := GenerateDetail(d, FormatPython, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `import requests
+
+url = "https://example.com/"
+headers = {
+    "accept": "text/html, application/json",
+    "cookie": "a=1; b=2",
+}
+
+response = requests.request("GET", url, headers=headers)
+print(response.status_code)
+print(response.text)
+`
+	if s.Code != want {
+		t.Errorf("unexpected python snippet:\n%s\nwant:\n%s", s.Code, want)
+	}
+
+	s, err = GenerateDetail(d, FormatCurl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.Code, "-H 'cookie: a=1' \\\n  -H 'cookie: b=2'") {
+		t.Errorf("expected repeated headers: %s", s.Code)
+	}
+}
+
+func TestGenerateFetch(t *testing.T) {
+	s, err := Generate(postRecord(), FormatFetch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `const response = await fetch("https://api.example.com/v1/orders?note=it's", {
+  method: "POST",
+  headers: {
+    "content-type": "application/json",
+    "x-empty": "",
+  },
+  body: "{\"item\":\"o'neil\",\"qty\":2}",
+});
+
+console.log(response.status);
+console.log(await response.text());
+`
+	if s.Code != want {
+		t.Errorf("unexpected fetch snippet:\n%s\nwant:\n%s", s.Code, want)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		got, err := ParseFormat(string(f))
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+		if _, err := Generate(postRecord(), f, nil); err != nil {
+			t.Errorf("%s: %v", f, err)
+		}
+	}
+	if _, err := ParseFormat("ruby"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// curlSnippet returns a curl command line, one option per line.
+func curlSnippet(req *request, bodyFile string) string {
+	args := []string{"curl " + shellQuote(req.url)}
+
+	switch {
+	case req.method == "HEAD":
+		args = append(args, "--head")
+	case req.hasBody() && req.method != "POST", !req.hasBody() && req.method != "GET":
+		// curl sends GET, or POST with a body
+		args = append(args, "-X "+shellQuote(req.method))
+	}
+
+	for _, h := range req.headers {
+		line := h.name + ": " + h.value
+		if h.value == "" {
+			// "name:" would remove the header
+			line = h.name + ";"
+		}
+		args = append(args, "-H "+shellQuote(line))
+	}
+
+	switch {
+	case req.binary:
+		args = append(args, "--data-binary "+shellQuote("@"+bodyFile))
+	case len(req.body) > 0:
+		args = append(args, "--data-raw "+shellQuote(string(req.body)))
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// httpieSnippet returns an HTTPie command line, one item per line.
+func httpieSnippet(req *request, bodyFile string) string {
+	first := "http"
+	if !req.binary && len(req.body) > 0 {
+		first += " --raw " + shellQuote(string(req.body))
+	}
+	args := []string{first + " " + shellQuote(req.method) + " " + shellQuote(req.url)}
+
+	for _, h := range req.headers {
+		item := h.name + ":" + h.value
+		if h.value == "" {
+			// "name:" would remove the header
+			item = h.name + ";"
+		}
+		args = append(args, shellQuote(item))
+	}
+
+	if req.binary {
+		args = append(args, "< "+shellQuote(bodyFile))
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// goSnippet returns a Go program using net/http.
+func goSnippet(req *request, bodyFile string) string {
+	var b strings.Builder
+
+	imports := []string{"fmt", "io", "log", "net/http"}
+	switch {
+	case req.binary:
+		imports = append(imports, "bytes", "os")
+	case len(req.body) > 0:
+		imports = append(imports, "strings")
+	}
+
+	b.WriteString("package main\n\nimport (\n")
+	for _, name := range imports {
+		fmt.Fprintf(&b, "\t%q\n", name)
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	reqBody := "nil"
+	switch {
+	case req.binary:
+		fmt.Fprintf(&b, "\tdata, err := os.ReadFile(%s)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tbody := bytes.NewReader(data)\n\n", strconv.Quote(bodyFile))
+		reqBody = "body"
+	case len(req.body) > 0:
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n\n", goString(string(req.body)))
+		reqBody = "body"
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n",
+		strconv.Quote(req.method), strconv.Quote(req.url), reqBody)
+	for _, h := range req.headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+
+	b.WriteString(`
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.Status)
+	fmt.Println(string(respBody))
+}
+`)
+
+	// Sort the imports
+	code := b.String()
+	if formatted, err := format.Source([]byte(code)); err == nil {
+		code = string(formatted)
+	}
+	return code
+}
+
+// goString quotes s as a Go string literal, using a raw string for readable
+// multi-line text.
+func goString(s string) string {
+	if strings.Contains(s, "\n") && !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// pythonSnippet returns a Python script using requests.
+func pythonSnippet(req *request, bodyFile string) string {
+	var b strings.Builder
+
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", jsonString(req.url))
+
+	args := []string{jsonString(req.method), "url"}
+	if len(req.headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range req.joinedHeaders() {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonString(h.name), jsonString(h.value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+
+	switch {
+	case req.binary:
+		fmt.Fprintf(&b, "with open(%s, \"rb\") as f:\n    data = f.read()\n", jsonString(bodyFile))
+		args = append(args, "data=data")
+	case len(req.body) > 0:
+		// Encode as UTF-8; requests sends str bodies as Latin-1
+		fmt.Fprintf(&b, "data = %s.encode()\n", jsonString(string(req.body)))
+		args = append(args, "data=data")
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s)\n", strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return b.String()
+}
+
+// fetchSnippet returns a JavaScript module using fetch. Binary bodies are
+// read with Node's fs module.
+func fetchSnippet(req *request, bodyFile string) string {
+	var b strings.Builder
+
+	if req.binary {
+		b.WriteString("import { readFile } from \"node:fs/promises\";\n\n")
+	}
+
+	var init []string
+	if req.method != "GET" {
+		init = append(init, "  method: "+jsonString(req.method)+",\n")
+	}
+	if len(req.headers) > 0 {
+		var h strings.Builder
+		h.WriteString("  headers: {\n")
+		for _, hdr := range req.joinedHeaders() {
+			fmt.Fprintf(&h, "    %s: %s,\n", jsonString(hdr.name), jsonString(hdr.value))
+		}
+		h.WriteString("  },\n")
+		init = append(init, h.String())
+	}
+	switch {
+	case req.binary:
+		init = append(init, "  body: await readFile("+jsonString(bodyFile)+"),\n")
+	case len(req.body) > 0:
+		init = append(init, "  body: "+jsonString(string(req.body))+",\n")
+	}
+
+	if len(init) == 0 {
+		fmt.Fprintf(&b, "const response = await fetch(%s);\n", jsonString(req.url))
+	} else {
+		fmt.Fprintf(&b, "const response = await fetch(%s, {\n%s});\n", jsonString(req.url), strings.Join(init, ""))
+	}
+	b.WriteString("\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String()
+}
+
+// jsonString quotes s as a JSON string, which is also a valid Python and
+// JavaScript string literal.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// shellQuote quotes s for a POSIX shell, leaving words that need no quoting
+// as they are.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !isShellSafe(r) }) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isShellSafe reports whether r has no special meaning to a POSIX shell.
+func isShellSafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("_-./:@%+=,", r)
+}
@@ -0,0 +1,390 @@
+// Package mock serves recorded responses for incoming requests.
+//
+// A Server is loaded with captured records (from NDJSON, HAR or the
+// database) and answers each request with the status, headers and body of
+// the best matching recording. It can run as a standalone http.Handler or be
+// plugged into the forward proxy, where matched requests never reach the
+// network.
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// QueryMatch controls how query strings are compared.
+type QueryMatch string
+
+const (
+	// QueryExact requires the same parameters and values
+	QueryExact QueryMatch = "exact"
+	// QuerySubset requires every recorded parameter to be present with the same values
+	QuerySubset QueryMatch = "subset"
+	// QueryIgnore ignores the query string
+	QueryIgnore QueryMatch = "ignore"
+)
+
+// HeaderMock is set on every response served from a recording.
+const HeaderMock = "X-OmniProxy-Mock"
+
+// maxMatchBodySize limits how much of a request body is read for matching.
+const maxMatchBodySize = 10 << 20
+
+// Config holds mock server configuration.
+type Config struct {
+	// MatchHost requires the request host to match the recorded host.
+	// Typically enabled in proxy mode and disabled for a standalone server.
+	MatchHost bool
+	// Query controls query string matching (default: exact)
+	Query QueryMatch
+	// IgnoreQueryParams are query parameters excluded from matching (e.g., cache busters)
+	IgnoreQueryParams []string
+	// MatchBody requires the request body to match; JSON bodies are compared structurally
+	MatchBody bool
+	// NotFoundStatus is the status returned when nothing matches (default: 404)
+	NotFoundStatus int
+	// Passthrough forwards unmatched requests upstream in proxy mode instead
+	// of answering with NotFoundStatus
+	Passthrough bool
+}
+
+// DefaultConfig returns default mock configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Query:          QueryExact,
+		NotFoundStatus: http.StatusNotFound,
+	}
+}
+
+// entry is a recording prepared for matching.
+type entry struct {
+	rec   *capture.Record
+	host  string
+	path  string
+	query url.Values
+	body  []byte
+	json  interface{}
+}
+
+// Server answers requests from recorded traffic.
+type Server struct {
+	config  *Config
+	ignore  map[string]bool
+	mu      sync.Mutex
+	entries map[string][]*entry // keyed by method and path
+	count   int
+	hits    map[string]int // sequence position per candidate set
+}
+
+// New creates a mock server and loads recs into it.
+func New(cfg *Config, recs []*capture.Record) (*Server, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Query == "" {
+		cfg.Query = QueryExact
+	}
+	switch cfg.Query {
+	case QueryExact, QuerySubset, QueryIgnore:
+	default:
+		return nil, fmt.Errorf("invalid query match mode: %s", cfg.Query)
+	}
+	if cfg.NotFoundStatus == 0 {
+		cfg.NotFoundStatus = http.StatusNotFound
+	}
+
+	s := &Server{
+		config:  cfg,
+		ignore:  make(map[string]bool),
+		entries: make(map[string][]*entry),
+		hits:    make(map[string]int),
+	}
+	for _, p := range cfg.IgnoreQueryParams {
+		s.ignore[p] = true
+	}
+	s.Load(recs)
+	return s, nil
+}
+
+// Load adds recordings to the server. Records without a response, and
+// WebSocket records, are skipped. Recordings for the same request are served
+// in the order they were loaded.
+func (s *Server) Load(recs []*capture.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range recs {
+		e := newEntry(rec)
+		if e == nil {
+			continue
+		}
+		key := matchKey(rec.Request.Method, e.path)
+		s.entries[key] = append(s.entries[key], e)
+		s.count++
+	}
+}
+
+// Len returns the number of loaded recordings.
+func (s *Server) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// newEntry prepares rec for matching, returning nil if it cannot be served.
+func newEntry(rec *capture.Record) *entry {
+	if rec == nil || rec.Response.Status == 0 || rec.Request.Method == "" ||
+		(rec.Type != "" && rec.Type != capture.RecordTypeHTTP) {
+		return nil
+	}
+
+	e := &entry{
+		rec:  rec,
+		host: normalizeHost(rec.Request.Host, rec.Request.Scheme),
+		path: rec.Request.Path,
+	}
+
+	if u, err := url.Parse(rec.Request.URL); err == nil {
+		if e.path == "" {
+			e.path = u.Path
+		}
+		if e.host == "" {
+			e.host = normalizeHost(u.Host, u.Scheme)
+		}
+		e.query = u.Query()
+	}
+	if len(e.query) == 0 && len(rec.Request.Query) > 0 {
+		e.query = rec.Request.Query.URLValues()
+	}
+	if e.path == "" {
+		e.path = "/"
+	}
+
+	e.body = capture.BodyBytes(rec.Request.Body)
+	e.json = decodeJSON(e.body)
+	return e
+}
+
+// Match returns the recording for req, or nil if nothing matches. The
+// request body is read and restored when body matching is enabled.
+func (s *Server) Match(req *http.Request) *capture.Record {
+	var body []byte
+	if s.config.MatchBody && req.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(req.Body, maxMatchBodySize))
+		if err != nil {
+			return nil
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		body = data
+	}
+	bodyJSON := decodeJSON(body)
+
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	host := req.URL.Host
+	if host == "" {
+		host = req.Host
+	}
+	host = normalizeHost(host, req.URL.Scheme)
+	query := req.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := matchKey(req.Method, path)
+	var matches []int
+	for i, e := range s.entries[key] {
+		if s.config.MatchHost && e.host != host {
+			continue
+		}
+		if !s.matchQuery(e.query, query) {
+			continue
+		}
+		if s.config.MatchBody && !matchBody(e, body, bodyJSON) {
+			continue
+		}
+		matches = append(matches, i)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// Repeated requests step through the matching recordings, then keep
+	// returning the last one.
+	seq := fmt.Sprintf("%s#%v", key, matches)
+	n := s.hits[seq]
+	s.hits[seq] = n + 1
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return s.entries[key][matches[n]].rec
+}
+
+// Reset rewinds all recording sequences.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits = make(map[string]int)
+}
+
+// Respond returns the recorded response for req, or nil if nothing matches.
+func (s *Server) Respond(req *http.Request) *http.Response {
+	rec := s.Match(req)
+	if rec == nil {
+		return nil
+	}
+	return NewResponse(req, rec)
+}
+
+// NotFound returns the response for a request that matches no recording.
+func (s *Server) NotFound(req *http.Request) *http.Response {
+	body := fmt.Sprintf("omniproxy mock: no recording matches %s %s\n", req.Method, req.URL.String())
+	resp := newResponse(req, s.config.NotFoundStatus, []byte(body))
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Header.Set(HeaderMock, "miss")
+	return resp
+}
+
+// Passthrough reports whether unmatched proxy requests go upstream.
+func (s *Server) Passthrough() bool {
+	return s.config.Passthrough
+}
+
+// ServeHTTP answers r from the recordings.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := s.Respond(r)
+	if resp == nil {
+		resp = s.NotFound(r)
+	}
+	defer resp.Body.Close()
+
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// skipResponseHeaders are recorded headers that do not describe the served body.
+var skipResponseHeaders = map[string]bool{
+	"content-length":    true,
+	"content-encoding":  true,
+	"transfer-encoding": true,
+	"connection":        true,
+	"keep-alive":        true,
+}
+
+// NewResponse builds the HTTP response recorded in rec.
+func NewResponse(req *http.Request, rec *capture.Record) *http.Response {
+	var body []byte
+	if !rec.Response.IsBinary && rec.Response.Body != capture.BinaryPlaceholder {
+		body = capture.BodyBytes(rec.Response.Body)
+	}
+
+	resp := newResponse(req, rec.Response.Status, body)
+	for _, h := range rec.Response.Headers {
+		if skipResponseHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		resp.Header.Add(h.Name, h.Value)
+	}
+	if rec.Response.ContentType != "" && resp.Header.Get("Content-Type") == "" {
+		resp.Header.Set("Content-Type", rec.Response.ContentType)
+	}
+	resp.Header.Set(HeaderMock, "hit")
+	return resp
+}
+
+// newResponse creates a response with a fixed body.
+func newResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// matchQuery compares a recorded query with the request query.
+func (s *Server) matchQuery(recorded, actual url.Values) bool {
+	if s.config.Query == QueryIgnore {
+		return true
+	}
+	for k, v := range recorded {
+		if s.ignore[k] {
+			continue
+		}
+		if !reflect.DeepEqual(v, actual[k]) {
+			return false
+		}
+	}
+	if s.config.Query == QuerySubset {
+		return true
+	}
+	for k := range actual {
+		if _, ok := recorded[k]; !ok && !s.ignore[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchBody compares a recorded request body with the request body.
+func matchBody(e *entry, body []byte, bodyJSON interface{}) bool {
+	if e.json != nil && bodyJSON != nil {
+		return reflect.DeepEqual(e.json, bodyJSON)
+	}
+	return bytes.Equal(bytes.TrimSpace(e.body), bytes.TrimSpace(body))
+}
+
+// decodeJSON decodes body as JSON, returning nil if it is not JSON.
+func decodeJSON(body []byte) interface{} {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || (body[0] != '{' && body[0] != '[') {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// matchKey indexes recordings by method and path.
+func matchKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// normalizeHost lowercases host and strips the default port for scheme.
+func normalizeHost(host, scheme string) string {
+	host = strings.ToLower(host)
+	h, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	if port == "80" && scheme != "https" || port == "443" && scheme != "http" {
+		return h
+	}
+	return host
+}
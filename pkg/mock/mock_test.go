@@ -0,0 +1,152 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+func newTestRecord(method, rawURL string, status int, body interface{}) *capture.Record {
+	req := httptest.NewRequest(method, rawURL, nil)
+	return &capture.Record{
+		Request: capture.RequestRecord{
+			Method: method,
+			URL:    rawURL,
+			Host:   req.Host,
+			Path:   req.URL.Path,
+			Scheme: req.URL.Scheme,
+		},
+		Response: capture.ResponseRecord{
+			Status: status,
+			Headers: capture.Headers{
+				{Name: "content-type", Value: "application/json"},
+				{Name: "content-length", Value: "999"},
+			},
+			Body: body,
+		},
+	}
+}
+
+func serve(t *testing.T, h http.Handler, method, target, body string) (*http.Response, string) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, r))
+	resp := w.Result()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func TestServerMatching(t *testing.T) {
+	recs := []*capture.Record{
+		newTestRecord("GET", "https://api.example.com/users?page=1", 200, []interface{}{"alice"}),
+		newTestRecord("GET", "https://api.example.com/users?page=2", 200, []interface{}{"bob"}),
+		newTestRecord("DELETE", "https://api.example.com/users/1", 204, nil),
+		{Type: capture.RecordTypeWebSocketFrame},
+	}
+
+	s, err := New(nil, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 3 {
+		t.Fatalf("expected 3 recordings, got %d", s.Len())
+	}
+
+	resp, body := serve(t, s, "GET", "http://localhost:8081/users?page=2", "")
+	if resp.StatusCode != 200 || body != `["bob"]` {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get(HeaderMock) != "hit" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", resp.Header)
+	}
+	if resp.Header.Get("Content-Length") == "999" {
+		t.Error("recorded content-length must not be served")
+	}
+
+	// Exact query matching rejects unknown parameters
+	resp, _ = serve(t, s, "GET", "http://localhost:8081/users?page=2&sort=name", "")
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get(HeaderMock) != "miss" {
+		t.Errorf("expected miss, got %d", resp.StatusCode)
+	}
+
+	resp, _ = serve(t, s, "DELETE", "http://localhost:8081/users/1", "")
+	if resp.StatusCode != 204 {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerQueryModes(t *testing.T) {
+	recs := []*capture.Record{
+		newTestRecord("GET", "https://api.example.com/search?q=go&_=123", 200, "subset"),
+	}
+
+	s, _ := New(&Config{Query: QuerySubset, IgnoreQueryParams: []string{"_"}}, recs)
+	if resp, _ := serve(t, s, "GET", "/search?q=go&_=456&extra=1", ""); resp.StatusCode != 200 {
+		t.Errorf("subset: expected match, got %d", resp.StatusCode)
+	}
+	if resp, _ := serve(t, s, "GET", "/search?q=rust", ""); resp.StatusCode != 404 {
+		t.Errorf("subset: expected miss, got %d", resp.StatusCode)
+	}
+
+	s, _ = New(&Config{Query: QueryIgnore, NotFoundStatus: http.StatusBadGateway}, recs)
+	if resp, _ := serve(t, s, "GET", "/search", ""); resp.StatusCode != 200 {
+		t.Errorf("ignore: expected match, got %d", resp.StatusCode)
+	}
+	if resp, _ := serve(t, s, "GET", "/other", ""); resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected configured not-found status, got %d", resp.StatusCode)
+	}
+
+	if _, err := New(&Config{Query: "fuzzy"}, nil); err == nil {
+		t.Error("expected error for invalid query mode")
+	}
+}
+
+func TestServerMatchHostAndBody(t *testing.T) {
+	a := newTestRecord("POST", "https://a.example.com/login", 200, "a")
+	a.Request.Body = map[string]interface{}{"user": "alice", "remember": true}
+	b := newTestRecord("POST", "https://b.example.com/login", 401, "b")
+
+	s, _ := New(&Config{MatchHost: true, MatchBody: true}, []*capture.Record{a, b})
+
+	// JSON bodies match regardless of key order and whitespace
+	resp, body := serve(t, s, "POST", "https://a.example.com:443/login", `{ "remember": true, "user": "alice" }`)
+	if resp.StatusCode != 200 || body != "a" {
+		t.Errorf("expected host a match, got %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := serve(t, s, "POST", "https://a.example.com/login", `{"user":"bob"}`); resp.StatusCode != 404 {
+		t.Errorf("expected body mismatch, got %d", resp.StatusCode)
+	}
+	if resp, _ := serve(t, s, "POST", "https://b.example.com/login", ""); resp.StatusCode != 401 {
+		t.Errorf("expected host b match, got %d", resp.StatusCode)
+	}
+	if resp, _ := serve(t, s, "POST", "https://c.example.com/login", ""); resp.StatusCode != 404 {
+		t.Errorf("expected host mismatch, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerSequence(t *testing.T) {
+	recs := []*capture.Record{
+		newTestRecord("GET", "https://api.example.com/job", 202, "pending"),
+		newTestRecord("GET", "https://api.example.com/job", 200, "done"),
+	}
+	s, _ := New(nil, recs)
+
+	for i, want := range []string{"pending", "done", "done"} {
+		if _, body := serve(t, s, "GET", "/job", ""); body != want {
+			t.Errorf("request %d: expected %s, got %s", i, want, body)
+		}
+	}
+
+	s.Reset()
+	if _, body := serve(t, s, "GET", "/job", ""); body != "pending" {
+		t.Errorf("expected sequence to restart after reset, got %s", body)
+	}
+}
@@ -0,0 +1,249 @@
+// Package netsim simulates network conditions for proxied traffic.
+//
+// A profile combines latency, jitter, upload and download bandwidth caps,
+// random connection resets and injected 5xx responses. A simulator applies a
+// default profile to all traffic and per-host profiles on top of it. Profiles
+// can be switched at runtime with Apply.
+package netsim
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// ProfileNone disables simulation when used as a default or host profile.
+const ProfileNone = "none"
+
+// ErrConnectionReset is returned for simulated connection resets.
+var ErrConnectionReset = errors.New("simulated connection reset")
+
+// Profile describes simulated network conditions. Zero values disable the
+// corresponding effect.
+type Profile struct {
+	// Name identifies the profile
+	Name string `yaml:"name" json:"name"`
+	// LatencyMs is the delay added to each round trip
+	LatencyMs int `yaml:"latencyMs,omitempty" json:"latency_ms,omitempty"`
+	// JitterMs is the maximum random variation added to or removed from the latency
+	JitterMs int `yaml:"jitterMs,omitempty" json:"jitter_ms,omitempty"`
+	// DownloadKbps caps the download bandwidth in kilobits per second
+	DownloadKbps int `yaml:"downloadKbps,omitempty" json:"download_kbps,omitempty"`
+	// UploadKbps caps the upload bandwidth in kilobits per second
+	UploadKbps int `yaml:"uploadKbps,omitempty" json:"upload_kbps,omitempty"`
+	// ResetRate is the probability (0-1) that a request or connection is reset
+	ResetRate float64 `yaml:"resetRate,omitempty" json:"reset_rate,omitempty"`
+	// ErrorRate is the probability (0-1) that a request gets an injected error response
+	ErrorRate float64 `yaml:"errorRate,omitempty" json:"error_rate,omitempty"`
+	// ErrorStatus is the status code of injected errors (default: 503)
+	ErrorStatus int `yaml:"errorStatus,omitempty" json:"error_status,omitempty"`
+}
+
+// HostProfile applies a profile to matching hosts.
+type HostProfile struct {
+	// Host is the host to match (supports wildcards)
+	Host string `yaml:"host" json:"host"`
+	// Profile is the name of the profile to apply
+	Profile string `yaml:"profile" json:"profile"`
+}
+
+// Config holds network simulation settings.
+type Config struct {
+	// Profiles are custom profiles, in addition to the built-in ones
+	Profiles []Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// Default is the profile applied to hosts without a host profile
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Hosts are per-host profiles, matched in order
+	Hosts []HostProfile `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	// Seed seeds the random source for reproducible runs (0: time-based)
+	Seed int64 `yaml:"seed,omitempty" json:"seed,omitempty"`
+}
+
+// BuiltinProfiles returns the built-in profiles.
+func BuiltinProfiles() []Profile {
+	return []Profile{
+		{Name: "slow-3g", LatencyMs: 2000, JitterMs: 200, DownloadKbps: 400, UploadKbps: 400},
+		{Name: "3g", LatencyMs: 560, JitterMs: 100, DownloadKbps: 1600, UploadKbps: 750},
+		{Name: "4g", LatencyMs: 70, JitterMs: 20, DownloadKbps: 12000, UploadKbps: 5000},
+		{Name: "flaky-wifi", LatencyMs: 40, JitterMs: 150, DownloadKbps: 5000, UploadKbps: 2000, ResetRate: 0.05, ErrorRate: 0.05},
+	}
+}
+
+// hostRule is a host profile with its pattern compiled.
+type hostRule struct {
+	filter  *capture.Filter
+	profile *Profile
+}
+
+// Simulator applies network profiles to proxied traffic.
+type Simulator struct {
+	mu       sync.RWMutex
+	config   *Config
+	profiles map[string]*Profile
+	def      *Profile
+	hosts    []hostRule
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// New creates a simulator.
+func New(cfg *Config) (*Simulator, error) {
+	s := &Simulator{}
+	if err := s.Apply(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Apply replaces the simulator settings. Traffic already in flight keeps the
+// profile it started with.
+func (s *Simulator) Apply(cfg *Config) error {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	profiles := make(map[string]*Profile)
+	for _, p := range BuiltinProfiles() {
+		p := p
+		profiles[p.Name] = &p
+	}
+	for _, p := range cfg.Profiles {
+		p := p
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		profiles[p.Name] = &p
+	}
+
+	lookup := func(name string) (*Profile, error) {
+		if name == "" || name == ProfileNone {
+			return nil, nil
+		}
+		p, ok := profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown network profile %q", name)
+		}
+		return p, nil
+	}
+
+	def, err := lookup(cfg.Default)
+	if err != nil {
+		return err
+	}
+	hosts := make([]hostRule, 0, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		p, err := lookup(h.Profile)
+		if err != nil {
+			return err
+		}
+		filter := capture.NewFilter()
+		filter.IncludeHosts = []string{h.Host}
+		if err := filter.Compile(); err != nil {
+			return fmt.Errorf("invalid network host %q: %w", h.Host, err)
+		}
+		hosts = append(hosts, hostRule{filter: filter, profile: p})
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	copied := *cfg
+	s.mu.Lock()
+	s.config = &copied
+	s.profiles = profiles
+	s.def = def
+	s.hosts = hosts
+	s.mu.Unlock()
+
+	s.rngMu.Lock()
+	s.rng = rand.New(rand.NewSource(seed)) //nolint:gosec // Simulation does not need a secure source
+	s.rngMu.Unlock()
+	return nil
+}
+
+// Config returns the current settings.
+func (s *Simulator) Config() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	copied := *s.config
+	return &copied
+}
+
+// Profiles returns all available profiles, sorted by name.
+func (s *Simulator) Profiles() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// ProfileFor returns the profile for host, or nil if traffic to host is not
+// simulated. host may include a port.
+func (s *Simulator) ProfileFor(host string) *Profile {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, h := range s.hosts {
+		if h.filter.MatchRequest(host, "", "") {
+			return h.profile
+		}
+	}
+	return s.def
+}
+
+// Validate checks that a profile's values are in range.
+func (p *Profile) Validate() error {
+	switch {
+	case p.Name == "" || p.Name == ProfileNone:
+		return fmt.Errorf("invalid network profile name %q", p.Name)
+	case p.LatencyMs < 0 || p.JitterMs < 0 || p.DownloadKbps < 0 || p.UploadKbps < 0:
+		return fmt.Errorf("network profile %q: values must not be negative", p.Name)
+	case p.ResetRate < 0 || p.ResetRate > 1 || p.ErrorRate < 0 || p.ErrorRate > 1:
+		return fmt.Errorf("network profile %q: rates must be between 0 and 1", p.Name)
+	case p.ErrorStatus != 0 && (p.ErrorStatus < 400 || p.ErrorStatus > 599):
+		return fmt.Errorf("network profile %q: error status must be 4xx or 5xx", p.Name)
+	}
+	return nil
+}
+
+// chance reports true with probability rate.
+func (s *Simulator) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	return s.rng.Float64() < rate
+}
+
+// delay returns the profile latency with jitter applied.
+func (s *Simulator) delay(p *Profile) time.Duration {
+	ms := p.LatencyMs
+	if p.JitterMs > 0 {
+		s.rngMu.Lock()
+		ms += s.rng.Intn(2*p.JitterMs+1) - p.JitterMs
+		s.rngMu.Unlock()
+	}
+	if ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
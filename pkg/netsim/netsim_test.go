@@ -0,0 +1,192 @@
+package netsim
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"builtin", &Config{Default: "3g"}, false},
+		{"none", &Config{Default: ProfileNone}, false},
+		{"custom", &Config{Profiles: []Profile{{Name: "lossy", ResetRate: 0.5}}, Hosts: []HostProfile{{Host: "*.example.com", Profile: "lossy"}}}, false},
+		{"unknown default", &Config{Default: "5g"}, true},
+		{"unknown host profile", &Config{Hosts: []HostProfile{{Host: "example.com", Profile: "5g"}}}, true},
+		{"negative latency", &Config{Profiles: []Profile{{Name: "bad", LatencyMs: -1}}}, true},
+		{"rate out of range", &Config{Profiles: []Profile{{Name: "bad", ErrorRate: 1.5}}}, true},
+		{"invalid status", &Config{Profiles: []Profile{{Name: "bad", ErrorStatus: 200}}}, true},
+		{"reserved name", &Config{Profiles: []Profile{{Name: ProfileNone}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileFor(t *testing.T) {
+	s, err := New(&Config{
+		Default: "4g",
+		Hosts: []HostProfile{
+			{Host: "local.example.com", Profile: ProfileNone},
+			{Host: "*.example.com", Profile: "3g"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com:443", "3g"},
+		{"api.example.com", "3g"},
+		{"local.example.com", ""},
+		{"other.org", "4g"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if p := s.ProfileFor(tt.host); p != nil {
+			got = p.Name
+		}
+		if got != tt.want {
+			t.Errorf("ProfileFor(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	// Switching at runtime replaces the assignments
+	if err := s.Apply(&Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.ProfileFor("api.example.com"); p != nil {
+		t.Errorf("expected no profile after reset, got %q", p.Name)
+	}
+	if err := s.Apply(&Config{Default: "5g"}); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 5000))
+	}))
+	defer upstream.Close()
+
+	s, err := New(&Config{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("latency and download cap", func(t *testing.T) {
+		// 5000 bytes at 200 kbps (25000 bytes/s) take about 200ms
+		p := &Profile{Name: "slow", LatencyMs: 50, DownloadKbps: 200}
+		start := time.Now()
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		resp, err := s.RoundTrip(p, req, http.DefaultTransport)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if len(body) != 5000 {
+			t.Errorf("expected 5000 bytes, got %d", len(body))
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("expected shaped round trip to take at least 200ms, took %v", elapsed)
+		}
+	})
+
+	t.Run("error injection", func(t *testing.T) {
+		p := &Profile{Name: "broken", ErrorRate: 1, ErrorStatus: http.StatusBadGateway}
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		resp, err := s.RoundTrip(p, req, http.DefaultTransport)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway || resp.Header.Get(HeaderNetwork) != "injected-error" {
+			t.Errorf("expected injected 502, got %d %v", resp.StatusCode, resp.Header)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		p := &Profile{Name: "reset", ResetRate: 1}
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		if _, err := s.RoundTrip(p, req, http.DefaultTransport); !errors.Is(err, ErrConnectionReset) {
+			t.Errorf("expected connection reset, got %v", err)
+		}
+	})
+}
+
+func TestConn(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("upload cap", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+		go func() { _, _ = io.Copy(io.Discard, server) }()
+
+		// 2000 bytes at 80 kbps (10000 bytes/s) take about 200ms
+		conn := s.Conn(&Profile{Name: "slow", UploadKbps: 80}, client)
+		defer conn.Close()
+		start := time.Now()
+		if _, err := conn.Write([]byte(strings.Repeat("x", 2000))); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected capped write to take at least 150ms, took %v", elapsed)
+		}
+	})
+
+	t.Run("idle connection", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+		go func() { _, _ = io.Copy(io.Discard, server) }()
+
+		conn := s.Conn(&Profile{Name: "slow", UploadKbps: 80}, client)
+		defer conn.Close()
+		if _, err := conn.Write([]byte(strings.Repeat("x", 1000))); err != nil {
+			t.Fatal(err)
+		}
+
+		// Idling does not build up credit: after 500ms, 3000 bytes still
+		// take about 300ms less one 50ms burst
+		time.Sleep(500 * time.Millisecond)
+		start := time.Now()
+		if _, err := conn.Write([]byte(strings.Repeat("x", 3000))); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("expected capped write after idling to take at least 200ms, took %v", elapsed)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+
+		conn := s.Conn(&Profile{Name: "reset", ResetRate: 1}, client)
+		if _, err := conn.Read(make([]byte, 16)); !errors.Is(err, ErrConnectionReset) {
+			t.Errorf("expected connection reset, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package netsim
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderNetwork is set on responses injected by the simulator.
+const HeaderNetwork = "X-OmniProxy-Network"
+
+// RoundTrip sends req through next under profile p: it waits for the
+// simulated latency, may reset the request or inject an error response, and
+// caps the upload and download bandwidth of the bodies.
+func (s *Simulator) RoundTrip(p *Profile, req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if err := sleep(req.Context(), s.delay(p)); err != nil {
+		return nil, err
+	}
+	if s.chance(p.ResetRate) {
+		return nil, ErrConnectionReset
+	}
+	if s.chance(p.ErrorRate) {
+		return errorResponse(req, p), nil
+	}
+
+	if p.UploadKbps > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &throttledBody{Reader: newThrottledReader(req.Context(), req.Body, p.UploadKbps), Closer: req.Body}
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		// The body of an upgrade is the connection itself, which goproxy
+		// and WebSocket capture write to as well as read from
+		if rwc, ok := resp.Body.(io.ReadWriteCloser); ok && (p.DownloadKbps > 0 || p.UploadKbps > 0) {
+			resp.Body = newThrottledConn(rwc, p)
+		}
+		return resp, nil
+	}
+	if p.DownloadKbps > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &throttledBody{Reader: newThrottledReader(req.Context(), resp.Body, p.DownloadKbps), Closer: resp.Body}
+	}
+	return resp, nil
+}
+
+// WrapDial returns a dial function for tunnelled connections that applies the
+// profile of the target host. Connections to hosts without a profile are not
+// shaped.
+func (s *Simulator) WrapDial(dial func(network, addr string) (net.Conn, error)) func(network, addr string) (net.Conn, error) {
+	return func(network, addr string) (net.Conn, error) {
+		p := s.ProfileFor(addr)
+		if p == nil {
+			return dial(network, addr)
+		}
+		if d := s.delay(p); d > 0 {
+			time.Sleep(d)
+		}
+		conn, err := dial(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return s.Conn(p, conn), nil
+	}
+}
+
+// Conn wraps conn so that it is shaped by profile p. Each response turn (a
+// read following a write) waits for the simulated latency, reads and writes
+// are capped to the profile bandwidth, and the connection may be reset on
+// its first read.
+func (s *Simulator) Conn(p *Profile, conn net.Conn) net.Conn {
+	c := &shapedConn{Conn: conn, sim: s, profile: p, reset: s.chance(p.ResetRate)}
+	if p.DownloadKbps > 0 {
+		c.down = newLimiter(p.DownloadKbps)
+	}
+	if p.UploadKbps > 0 {
+		c.up = newLimiter(p.UploadKbps)
+	}
+	return c
+}
+
+// shapedConn applies a profile to a tunnelled connection.
+type shapedConn struct {
+	net.Conn
+	sim     *Simulator
+	profile *Profile
+	down    *limiter
+	up      *limiter
+	reset   bool
+
+	mu    sync.Mutex
+	wrote bool
+}
+
+func (c *shapedConn) Read(b []byte) (int, error) {
+	if c.reset {
+		if tcp, ok := c.Conn.(*net.TCPConn); ok {
+			_ = tcp.SetLinger(0) // send RST instead of FIN
+		}
+		c.Conn.Close()
+		return 0, ErrConnectionReset
+	}
+
+	c.mu.Lock()
+	turn := c.wrote
+	c.wrote = false
+	c.mu.Unlock()
+	if turn {
+		if d := c.sim.delay(c.profile); d > 0 {
+			time.Sleep(d)
+		}
+	}
+
+	return readLimited(c.Conn, c.down, b)
+}
+
+func (c *shapedConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.wrote = true
+	c.mu.Unlock()
+
+	return writeLimited(c.Conn, c.up, b)
+}
+
+// CloseWrite half-closes the connection when supported, so tunnels can
+// propagate EOF.
+func (c *shapedConn) CloseWrite() error {
+	if hc, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return hc.CloseWrite()
+	}
+	return c.Conn.Close()
+}
+
+// limiter paces a byte stream to a fixed rate. It is a token bucket
+// holding at most one chunk of credit, so an idle stream cannot save up
+// time and then send a burst at full speed.
+type limiter struct {
+	bytesPerSec float64
+	chunk       int
+
+	mu sync.Mutex
+	// next is when the bytes sent so far are paid for
+	next time.Time
+}
+
+func newLimiter(kbps int) *limiter {
+	bps := float64(kbps) * 1000 / 8
+	// Pace in chunks of about 50ms of transfer
+	chunk := int(bps / 20)
+	if chunk < 1 {
+		chunk = 1
+	}
+	return &limiter{bytesPerSec: bps, chunk: chunk}
+}
+
+// wait blocks until n more bytes fit within the rate.
+func (l *limiter) wait(ctx context.Context, n int) {
+	if n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	burst := time.Duration(float64(l.chunk) / l.bytesPerSec * float64(time.Second))
+	if earliest := time.Now().Add(-burst); l.next.Before(earliest) {
+		l.next = earliest
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / l.bytesPerSec * float64(time.Second)))
+	due := l.next
+	l.mu.Unlock()
+
+	_ = sleep(ctx, time.Until(due))
+}
+
+// throttledReader reads from r at a limited rate.
+type throttledReader struct {
+	ctx context.Context
+	r   io.Reader
+	l   *limiter
+}
+
+func newThrottledReader(ctx context.Context, r io.Reader, kbps int) *throttledReader {
+	return &throttledReader{ctx: ctx, r: r, l: newLimiter(kbps)}
+}
+
+func (t *throttledReader) Read(b []byte) (int, error) {
+	if len(b) > t.l.chunk {
+		b = b[:t.l.chunk]
+	}
+	n, err := t.r.Read(b)
+	t.l.wait(t.ctx, n)
+	return n, err
+}
+
+// throttledBody combines a throttled reader with the original body's Close.
+type throttledBody struct {
+	io.Reader
+	io.Closer
+}
+
+// throttledConn caps both directions of an upgraded connection: reads to
+// the download rate and writes to the upload rate.
+type throttledConn struct {
+	io.ReadWriteCloser
+	down *limiter
+	up   *limiter
+}
+
+func newThrottledConn(rwc io.ReadWriteCloser, p *Profile) *throttledConn {
+	c := &throttledConn{ReadWriteCloser: rwc}
+	if p.DownloadKbps > 0 {
+		c.down = newLimiter(p.DownloadKbps)
+	}
+	if p.UploadKbps > 0 {
+		c.up = newLimiter(p.UploadKbps)
+	}
+	return c
+}
+
+func (c *throttledConn) Read(b []byte) (int, error) {
+	return readLimited(c.ReadWriteCloser, c.down, b)
+}
+
+func (c *throttledConn) Write(b []byte) (int, error) {
+	return writeLimited(c.ReadWriteCloser, c.up, b)
+}
+
+// readLimited reads from r at the rate of l, or directly when l is nil.
+func readLimited(r io.Reader, l *limiter, b []byte) (int, error) {
+	if l == nil {
+		return r.Read(b)
+	}
+	if len(b) > l.chunk {
+		b = b[:l.chunk]
+	}
+	n, err := r.Read(b)
+	l.wait(context.Background(), n)
+	return n, err
+}
+
+// writeLimited writes b to w in chunks paced by l, or directly when l is nil.
+func writeLimited(w io.Writer, l *limiter, b []byte) (int, error) {
+	if l == nil {
+		return w.Write(b)
+	}
+	written := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > l.chunk {
+			chunk = chunk[:l.chunk]
+		}
+		l.wait(context.Background(), len(chunk))
+		n, err := w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// errorResponse builds an injected error response.
+func errorResponse(req *http.Request, p *Profile) *http.Response {
+	status := p.ErrorStatus
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	body := "Simulated network error (" + p.Name + ")\n"
+	resp := &http.Response{
+		StatusCode:    status,
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Header.Set(HeaderNetwork, "injected-error")
+	return resp
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
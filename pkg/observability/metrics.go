@@ -30,11 +30,19 @@ type Metrics struct {
 	CertsCacheHits metric.Int64Counter
 	CertsCacheMiss metric.Int64Counter
 
+	// TLS metrics
+	CertGenerationDuration metric.Float64Histogram
+	TLSHandshakeDuration   metric.Float64Histogram
+
 	// Traffic store metrics
 	TrafficStored      metric.Int64Counter
 	TrafficStoreErrors metric.Int64Counter
 	TrafficQueueDepth  metric.Int64ObservableGauge
 
+	// Retention metrics
+	RetentionRecords     metric.Int64Counter
+	RetentionRunDuration metric.Float64Histogram
+
 	// Connection metrics
 	ActiveConnections metric.Int64UpDownCounter
 
@@ -121,6 +129,27 @@ func NewMetrics(meterProvider metric.MeterProvider) (*Metrics, error) {
 		return nil, err
 	}
 
+	// TLS metrics
+	m.CertGenerationDuration, err = meter.Float64Histogram(
+		"omniproxy.certs.generation.duration",
+		metric.WithDescription("Certificate generation duration in milliseconds"),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(0.5, 1, 2.5, 5, 10, 25, 50, 100),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.TLSHandshakeDuration, err = meter.Float64Histogram(
+		"omniproxy.tls.handshake.duration",
+		metric.WithDescription("Client TLS handshake duration in milliseconds for intercepted connections"),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Traffic store metrics
 	m.TrafficStored, err = meter.Int64Counter(
 		"omniproxy.traffic.stored",
@@ -140,6 +169,25 @@ func NewMetrics(meterProvider metric.MeterProvider) (*Metrics, error) {
 		return nil, err
 	}
 
+	// Retention metrics
+	m.RetentionRecords, err = meter.Int64Counter(
+		"omniproxy.retention.records",
+		metric.WithDescription("Total number of traffic records affected by retention, by action"),
+		metric.WithUnit("{record}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.RetentionRunDuration, err = meter.Float64Histogram(
+		"omniproxy.retention.run.duration",
+		metric.WithDescription("Duration of traffic retention runs"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Connection metrics
 	m.ActiveConnections, err = meter.Int64UpDownCounter(
 		"omniproxy.connections.active",
@@ -223,6 +271,19 @@ func (m *Metrics) RecordCertCacheMiss(ctx context.Context) {
 	m.CertsCacheMiss.Add(ctx, 1)
 }
 
+// RecordCertGenerationDuration records how long generating a certificate took.
+func (m *Metrics) RecordCertGenerationDuration(ctx context.Context, duration time.Duration) {
+	m.CertGenerationDuration.Record(ctx, float64(duration.Microseconds())/1000)
+}
+
+// RecordTLSHandshake records a client TLS handshake on an intercepted
+// connection and whether its certificate came from the cache.
+func (m *Metrics) RecordTLSHandshake(ctx context.Context, duration time.Duration, cached bool) {
+	m.TLSHandshakeDuration.Record(ctx, float64(duration.Microseconds())/1000, metric.WithAttributes(
+		attribute.Bool("cert_cached", cached),
+	))
+}
+
 // RecordTrafficStored records a successful traffic store.
 func (m *Metrics) RecordTrafficStored(ctx context.Context) {
 	m.TrafficStored.Add(ctx, 1)
@@ -233,6 +294,25 @@ func (m *Metrics) RecordTrafficStoreError(ctx context.Context) {
 	m.TrafficStoreErrors.Add(ctx, 1)
 }
 
+// RecordRetention records traffic records rolled up, stripped, archived or
+// deleted by the retention worker.
+func (m *Metrics) RecordRetention(ctx context.Context, action string, n int) {
+	m.RetentionRecords.Add(ctx, int64(n), metric.WithAttributes(
+		attribute.String("action", action),
+	))
+}
+
+// RecordRetentionRun records the duration and outcome of a retention run.
+func (m *Metrics) RecordRetentionRun(ctx context.Context, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.RetentionRunDuration.Record(ctx, float64(duration.Microseconds())/1000, metric.WithAttributes(
+		attribute.String("result", result),
+	))
+}
+
 // ConnectionOpened should be called when a connection is opened.
 func (m *Metrics) ConnectionOpened(ctx context.Context) {
 	m.ActiveConnections.Add(ctx, 1)
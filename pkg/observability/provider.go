@@ -131,3 +131,15 @@ func (b *BackendMetrics) IncCacheMiss() {
 // SetQueueDepth sets the current queue depth gauge.
 // Note: Queue depth is handled via callback in the main metrics.
 func (b *BackendMetrics) SetQueueDepth(n int) {}
+
+// AddRetention counts records affected by a retention action.
+func (b *BackendMetrics) AddRetention(action string, n int) {
+	if n > 0 {
+		b.m.RecordRetention(b.ctx, action, n)
+	}
+}
+
+// ObserveRetentionRun records the duration and outcome of a retention run.
+func (b *BackendMetrics) ObserveRetentionRun(d time.Duration, err error) {
+	b.m.RecordRetentionRun(b.ctx, d, err)
+}
@@ -0,0 +1,205 @@
+// Package protodecode decodes protobuf messages for traffic capture.
+//
+// With descriptors loaded from FileDescriptorSet files (protoc
+// --descriptor_set_out --include_imports, buf build -o, or grpcurl
+// -protoset-out for services that support reflection), gRPC messages are
+// decoded to their JSON mapping. Without descriptors, or for unknown
+// methods, messages are dumped field by field from the wire format.
+package protodecode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// Well-known types resolve even when a descriptor set omits them
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Decoder decodes gRPC messages using loaded descriptors. A nil Decoder
+// decodes every message as a raw wire-format dump.
+type Decoder struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+}
+
+// Load reads FileDescriptorSet files and returns a decoder for the services
+// they describe.
+func Load(paths ...string) (*Decoder, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+		}
+		s, err := ParseDescriptorSet(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse descriptor set %s: %w", path, err)
+		}
+		set.File = append(set.File, s.File...)
+	}
+	return New(set)
+}
+
+// ParseDescriptorSet parses a FileDescriptorSet in binary or JSON encoding.
+func ParseDescriptorSet(data []byte) (*descriptorpb.FileDescriptorSet, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := protojson.Unmarshal(trimmed, set); err != nil {
+			return nil, err
+		}
+		return set, nil
+	}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// New returns a decoder for the files in set. Files may appear in any order
+// and more than once; imports missing from set are resolved from the
+// well-known types.
+func New(set *descriptorpb.FileDescriptorSet) (*Decoder, error) {
+	files := new(protoregistry.Files)
+	resolver := &fallbackResolver{files: files}
+
+	pending := set.GetFile()
+	for len(pending) > 0 {
+		var next []*descriptorpb.FileDescriptorProto
+		var lastErr error
+		for _, fdp := range pending {
+			if _, err := files.FindFileByPath(fdp.GetName()); err == nil {
+				continue
+			}
+			fd, err := protodesc.NewFile(fdp, resolver)
+			if err != nil {
+				// Imports may be listed later in the set
+				next = append(next, fdp)
+				lastErr = err
+				continue
+			}
+			if err := files.RegisterFile(fd); err != nil {
+				return nil, fmt.Errorf("failed to register %s: %w", fdp.GetName(), err)
+			}
+		}
+		if len(next) == len(pending) {
+			return nil, fmt.Errorf("failed to resolve descriptors: %w", lastErr)
+		}
+		pending = next
+	}
+
+	return &Decoder{files: files, types: dynamicpb.NewTypes(files)}, nil
+}
+
+// Services returns the full names of the services the decoder knows.
+func (d *Decoder) Services() []string {
+	if d == nil {
+		return nil
+	}
+	var names []string
+	d.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			names = append(names, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	return names
+}
+
+// Decode decodes a message of fullMethod (/package.Service/Method), the
+// request message unless response is set. Messages of methods without
+// descriptors, or that do not match their descriptor, are returned as a
+// raw dump (see Raw).
+func (d *Decoder) Decode(fullMethod string, response bool, data []byte) interface{} {
+	if md := d.messageType(fullMethod, response); md != nil {
+		if v, err := d.decodeJSON(md, data); err == nil {
+			return v
+		}
+	}
+	fields, err := Raw(data)
+	if err != nil {
+		// Not protobuf: the JSON encoding of []byte is base64
+		return data
+	}
+	return fields
+}
+
+// messageType returns the input or output type of fullMethod.
+func (d *Decoder) messageType(fullMethod string, response bool) protoreflect.MessageDescriptor {
+	if d == nil {
+		return nil
+	}
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil
+	}
+	desc, err := d.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil
+	}
+	if response {
+		return md.Output()
+	}
+	return md.Input()
+}
+
+// decodeJSON unmarshals data as md and converts it to its JSON mapping.
+func (d *Decoder) decodeJSON(md protoreflect.MessageDescriptor, data []byte) (interface{}, error) {
+	msg := dynamicpb.NewMessage(md)
+	if err := (proto.UnmarshalOptions{Resolver: d.types}).Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	b, err := protojson.MarshalOptions{Resolver: d.types, UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// fallbackResolver resolves descriptors from loaded files, then from the
+// types linked into the binary.
+type fallbackResolver struct {
+	files *protoregistry.Files
+}
+
+func (r *fallbackResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.files.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r *fallbackResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if desc, err := r.files.FindDescriptorByName(name); err == nil {
+		return desc, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
@@ -0,0 +1,167 @@
+package protodecode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// greeterSet describes test.v1.Greeter/SayHello. The Timestamp import is
+// deliberately left out of the set.
+func greeterSet() *descriptorpb.FileDescriptorSet {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			JsonName: proto.String(name),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:       proto.String("test/v1/greeter.proto"),
+		Package:    proto.String("test.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("HelloRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")},
+			},
+			{
+				Name: proto.String("HelloReply"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					field("sent_at", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".test.v1.HelloRequest"),
+				OutputType: proto.String(".test.v1.HelloReply"),
+			}},
+		}},
+	}}}
+}
+
+// helloReply encodes HelloReply{message, count, sent_at: 1970-01-01T00:00:10Z}.
+func helloReply(message string, count int) []byte {
+	var ts []byte
+	ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, 10)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, message)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(count))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, ts)
+	return b
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	binary, err := proto.Marshal(greeterSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := protojson.Marshal(greeterSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"greeter.protoset": binary, "greeter.json": text} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		d, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		if got := d.Services(); !reflect.DeepEqual(got, []string{"test.v1.Greeter"}) {
+			t.Errorf("unexpected services from %s: %v", name, got)
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.protoset")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	unresolved := greeterSet()
+	unresolved.File[0].Dependency = []string{"test/v1/missing.proto"}
+	if _, err := New(unresolved); err == nil {
+		t.Error("expected error for unresolved import")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	d, err := New(greeterSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := json.Marshal(d.Decode("/test.v1.Greeter/SayHello", true, helloReply("hi", 2)))
+	if want := `{"count":2,"message":"hi","sent_at":"1970-01-01T00:00:10Z"}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// Unknown methods and a nil decoder fall back to a raw dump
+	for _, dec := range []*Decoder{d, nil} {
+		got, _ = json.Marshal(dec.Decode("/test.v1.Other/Call", false, helloReply("hi", 2)))
+		want := `[{"field":1,"type":"bytes","value":"hi"},{"field":2,"type":"varint","value":2},` +
+			`{"field":3,"type":"bytes","value":[{"field":1,"type":"varint","value":10}]}]`
+		if string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	// Data that is not protobuf is kept as bytes
+	got, _ = json.Marshal(d.Decode("/test.v1.Greeter/SayHello", false, []byte{0xff}))
+	if string(got) != `"/w=="` {
+		t.Errorf("expected base64 bytes, got %s", got)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 7)
+	b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 8)
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0x00, 0xff})
+	b = protowire.AppendTag(b, 4, protowire.StartGroupType)
+	b = protowire.AppendTag(b, 5, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	b = protowire.AppendTag(b, 4, protowire.EndGroupType)
+
+	fields, err := Raw(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(fields)
+	want := `[{"field":1,"type":"fixed32","value":7},{"field":2,"type":"fixed64","value":8},` +
+		`{"field":3,"type":"bytes","value":"AP8="},{"field":4,"type":"group","value":[{"field":5,"type":"varint","value":1}]}]`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err := Raw([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Error("expected error for truncated field")
+	}
+}
@@ -0,0 +1,120 @@
+package protodecode
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxRawDepth limits how deeply length-delimited fields are tried as
+// nested messages.
+const maxRawDepth = 16
+
+// Field is a field decoded from the protobuf wire format without a schema.
+type Field struct {
+	// Number is the field number
+	Number int32 `json:"field"`
+	// Type is the wire type: varint, fixed32, fixed64, bytes or group
+	Type string `json:"type"`
+	// Value is a number for scalar types. Length-delimited values are a
+	// nested []Field when they parse as a message, a string when they are
+	// printable text and base64-encoded bytes otherwise.
+	Value interface{} `json:"value"`
+}
+
+// ErrMalformed is returned by Raw for data that is not protobuf.
+var ErrMalformed = errors.New("malformed protobuf wire format")
+
+// Raw dumps a message from the protobuf wire format. Without a schema the
+// field names and exact scalar types are unknown, so varints are reported
+// unsigned and fixed-size values as raw integers.
+func Raw(data []byte) ([]Field, error) {
+	return raw(data, 0)
+}
+
+func raw(b []byte, depth int) ([]Field, error) {
+	fields := []Field{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, ErrMalformed
+		}
+		b = b[n:]
+
+		f := Field{Number: int32(num)}
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, ErrMalformed
+			}
+			f.Type, f.Value = "varint", v
+			b = b[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			if n < 0 {
+				return nil, ErrMalformed
+			}
+			f.Type, f.Value = "fixed32", v
+			b = b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return nil, ErrMalformed
+			}
+			f.Type, f.Value = "fixed64", v
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, ErrMalformed
+			}
+			f.Type, f.Value = "bytes", rawBytes(v, depth)
+			b = b[n:]
+		case protowire.StartGroupType:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, ErrMalformed
+			}
+			inner, err := raw(b[:n-protowire.SizeTag(num)], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			f.Type, f.Value = "group", inner
+			b = b[n:]
+		default:
+			return nil, ErrMalformed
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// rawBytes interprets a length-delimited value as text, a nested message or
+// opaque bytes, in that order.
+func rawBytes(b []byte, depth int) interface{} {
+	if isPrintable(b) {
+		return string(b)
+	}
+	if depth < maxRawDepth && len(b) > 0 {
+		if nested, err := raw(b, depth+1); err == nil {
+			return nested
+		}
+	}
+	return b
+}
+
+// isPrintable reports whether b is UTF-8 text without control characters.
+func isPrintable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/ca"
+	"github.com/grokify/omniproxy/pkg/observability"
+	"golang.org/x/sync/singleflight"
+)
+
+// certLockTimeout bounds the wait for a certificate being generated by
+// another proxy. The certificate is generated locally after it.
+const certLockTimeout = 5 * time.Second
+
+// certIssuer mints the leaf certificates presented to intercepted clients.
+// Leaves are cached by ca.LeafNames key, and concurrent handshakes for the
+// same key wait for a single generation. Caches shared between proxies
+// that implement backend.CertLocker extend this to all of them.
+type certIssuer struct {
+	ca      *ca.CA
+	cache   backend.CertCache
+	metrics *observability.Metrics
+	group   singleflight.Group
+}
+
+// certificate returns the leaf for host and whether it came from the cache.
+func (i *certIssuer) certificate(host string) (*tls.Certificate, bool, error) {
+	key, names := ca.LeafNames(host)
+	if cert, ok := i.cache.Get(key); ok {
+		if cert.Leaf == nil || time.Now().Before(cert.Leaf.NotAfter) {
+			return cert, true, nil
+		}
+		i.cache.Delete(key)
+	}
+
+	v, err, _ := i.group.Do(key, func() (interface{}, error) {
+		if locker, ok := i.cache.(backend.CertLocker); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), certLockTimeout)
+			defer cancel()
+			if unlock, err := locker.Lock(ctx, key); err == nil {
+				defer unlock()
+				// Another proxy may have generated it while we waited
+				if cert, ok := i.cache.Get(key); ok {
+					return issued{cert: cert, cached: true}, nil
+				}
+			}
+		}
+
+		start := time.Now()
+		cert, err := i.ca.IssueLeaf(names...)
+		if err != nil {
+			return nil, err
+		}
+		i.cache.Set(key, cert)
+
+		if i.metrics != nil {
+			ctx := context.Background()
+			i.metrics.RecordCertGenerated(ctx, key)
+			i.metrics.RecordCertGenerationDuration(ctx, time.Since(start))
+		}
+		return issued{cert: cert}, nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to issue certificate for %s: %w", host, err)
+	}
+	result := v.(issued)
+	return result.cert, result.cached, nil
+}
+
+// issued is the result of a certificate lookup shared by waiting handshakes.
+type issued struct {
+	cert   *tls.Certificate
+	cached bool
+}
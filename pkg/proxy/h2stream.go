@@ -0,0 +1,221 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2ClientPreface starts every HTTP/2 connection.
+const http2ClientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// HTTP/2 frame types and flags used to follow header blocks.
+const (
+	frameHeaderLen    = 9
+	frameHeaders      = 0x1
+	frameContinuation = 0x9
+
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+)
+
+// streamSniffer follows the client side of an HTTP/2 connection to learn
+// which stream carried each request. net/http does not expose stream IDs to
+// handlers, so the sniffer decodes request header blocks as they are read
+// and queues stream IDs by method and path for the handler to claim.
+//
+// Decoding happens before the server sees the bytes, so a stream ID is
+// always queued before its handler runs. Connections that do not start
+// with the HTTP/2 preface are ignored.
+type streamSniffer struct {
+	mu       sync.Mutex
+	disabled bool
+	preface  int
+
+	header  []byte
+	skip    int
+	inFrame bool
+	frame   frameInfo
+	payload []byte
+
+	block       []byte
+	blockStream uint32
+
+	decoder    *hpack.Decoder
+	lastStream uint32
+	pending    map[string][]uint32
+}
+
+// frameInfo is a decoded frame header.
+type frameInfo struct {
+	typ    byte
+	flags  byte
+	stream uint32
+	length int
+}
+
+func newStreamSniffer() *streamSniffer {
+	return &streamSniffer{
+		decoder: hpack.NewDecoder(4096, nil),
+		pending: make(map[string][]uint32),
+	}
+}
+
+// streamID claims the oldest unclaimed stream that carried a request with
+// method and path, or returns 0 if none is known.
+func (s *streamSniffer) streamID(method, path string) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + " " + path
+	queue := s.pending[key]
+	if len(queue) == 0 {
+		return 0
+	}
+	id := queue[0]
+	if len(queue) == 1 {
+		delete(s.pending, key)
+	} else {
+		s.pending[key] = queue[1:]
+	}
+	return id
+}
+
+// observe consumes bytes read from the client.
+func (s *streamSniffer) observe(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled {
+		return
+	}
+
+	if s.preface < len(http2ClientPreface) {
+		n := min(len(b), len(http2ClientPreface)-s.preface)
+		if string(b[:n]) != http2ClientPreface[s.preface:s.preface+n] {
+			s.disable()
+			return
+		}
+		s.preface += n
+		b = b[n:]
+	}
+
+	for len(b) > 0 && !s.disabled {
+		switch {
+		case s.skip > 0:
+			n := min(s.skip, len(b))
+			s.skip -= n
+			b = b[n:]
+
+		case s.inFrame:
+			n := min(s.frame.length-len(s.payload), len(b))
+			s.payload = append(s.payload, b[:n]...)
+			b = b[n:]
+			if len(s.payload) == s.frame.length {
+				s.inFrame = false
+				s.finishFrame()
+			}
+
+		default:
+			n := min(frameHeaderLen-len(s.header), len(b))
+			s.header = append(s.header, b[:n]...)
+			b = b[n:]
+			if len(s.header) < frameHeaderLen {
+				continue
+			}
+			s.startFrame()
+		}
+	}
+}
+
+// startFrame handles a complete frame header.
+func (s *streamSniffer) startFrame() {
+	h := s.header
+	s.header = s.header[:0]
+
+	f := frameInfo{
+		length: int(h[0])<<16 | int(h[1])<<8 | int(h[2]),
+		typ:    h[3],
+		flags:  h[4],
+		stream: binary.BigEndian.Uint32(h[5:9]) & 0x7fffffff,
+	}
+	if f.typ != frameHeaders && f.typ != frameContinuation {
+		s.skip = f.length
+		return
+	}
+
+	s.frame = f
+	s.payload = s.payload[:0]
+	if f.length == 0 {
+		s.finishFrame()
+		return
+	}
+	s.inFrame = true
+}
+
+// finishFrame handles a complete HEADERS or CONTINUATION frame.
+func (s *streamSniffer) finishFrame() {
+	f := s.frame
+	fragment := s.payload
+
+	if f.typ == frameHeaders {
+		if f.flags&flagPadded != 0 {
+			if len(fragment) < 1 || int(fragment[0]) > len(fragment)-1 {
+				s.disable()
+				return
+			}
+			fragment = fragment[1 : len(fragment)-int(fragment[0])]
+		}
+		if f.flags&flagPriority != 0 {
+			if len(fragment) < 5 {
+				s.disable()
+				return
+			}
+			fragment = fragment[5:]
+		}
+		s.block = append(s.block[:0], fragment...)
+		s.blockStream = f.stream
+	} else {
+		s.block = append(s.block, fragment...)
+	}
+
+	if f.flags&flagEndHeaders != 0 {
+		s.decodeBlock()
+	}
+}
+
+// decodeBlock decodes a complete header block. Every block is decoded to
+// keep the HPACK dynamic table in sync; only the first block of a stream
+// (the request headers, not trailers) is queued.
+func (s *streamSniffer) decodeBlock() {
+	fields, err := s.decoder.DecodeFull(s.block)
+	if err != nil {
+		s.disable()
+		return
+	}
+	if s.blockStream <= s.lastStream {
+		return
+	}
+	s.lastStream = s.blockStream
+
+	var method, path string
+	for _, f := range fields {
+		switch f.Name {
+		case ":method":
+			method = f.Value
+		case ":path":
+			path = f.Value
+		}
+	}
+	key := method + " " + path
+	s.pending[key] = append(s.pending[key], s.blockStream)
+}
+
+// disable stops sniffing, e.g. for HTTP/1.x connections or on malformed input.
+func (s *streamSniffer) disable() {
+	s.disabled = true
+	s.header, s.payload, s.block = nil, nil, nil
+	s.pending = make(map[string][]uint32)
+}
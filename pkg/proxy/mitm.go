@@ -0,0 +1,242 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elazarl/goproxy"
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// tlsRecordTypeHandshake is the first byte of a TLS ClientHello.
+const tlsRecordTypeHandshake = 0x16
+
+// mitmHandshakeTimeout bounds the client TLS handshake of a tunnel.
+const mitmHandshakeTimeout = 10 * time.Second
+
+// interceptedKey is the request context key for requests intercepted in a
+// CONNECT tunnel.
+type interceptedKey struct{}
+
+// intercepted describes a request read from an intercepted CONNECT tunnel.
+type intercepted struct {
+	// user is the user that authenticated the CONNECT request
+	user string
+	// streamID is the HTTP/2 stream that carried the request (0 for HTTP/1.x)
+	streamID uint32
+}
+
+// interceptedFrom returns the tunnel details of req, or nil if req was not
+// read from an intercepted tunnel.
+func interceptedFrom(req *http.Request) *intercepted {
+	if req == nil {
+		return nil
+	}
+	in, _ := req.Context().Value(interceptedKey{}).(*intercepted)
+	return in
+}
+
+// mitmConn is a decrypted client connection of an intercepted tunnel.
+type mitmConn struct {
+	net.Conn
+	// host is the CONNECT target
+	host string
+	// scheme is https for TLS tunnels and http otherwise
+	scheme string
+	// user is the user that authenticated the CONNECT request
+	user string
+	// streams maps HTTP/2 requests to their stream IDs
+	streams *streamSniffer
+}
+
+func (c *mitmConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.streams.observe(b[:n])
+	}
+	return n, err
+}
+
+// mitmConnKey is the connection context key for the mitmConn serving a request.
+type mitmConnKey struct{}
+
+// mitmAction intercepts a CONNECT tunnel. The client-facing side negotiates
+// HTTP/2 or HTTP/1.1 with ALPN; requests are then handled like any other
+// proxied request.
+func (p *Proxy) mitmAction() *goproxy.ConnectAction {
+	return &goproxy.ConnectAction{Action: goproxy.ConnectHijack, Hijack: p.hijackMITM}
+}
+
+// hijackMITM takes over the client connection of a CONNECT request.
+func (p *Proxy) hijackMITM(req *http.Request, client net.Conn, ctx *goproxy.ProxyCtx) {
+	if _, err := client.Write([]byte("HTTP/1.0 200 Connection established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		return
+	}
+
+	conn := &mitmConn{host: req.URL.Host, scheme: "http", streams: newStreamSniffer()}
+	if tunnel, ok := ctx.UserData.(*connectState); ok {
+		conn.user = tunnel.user
+	}
+
+	// Tunnels may carry plain HTTP as well as TLS
+	reader := bufio.NewReader(client)
+	if peek, _ := reader.Peek(1); len(peek) > 0 && peek[0] == tlsRecordTypeHandshake {
+		tlsConn, err := p.handshakeMITM(&bufferedConn{Conn: client, r: reader}, conn.host)
+		if err != nil {
+			ctx.Warnf("TLS handshake with client for %s failed: %v", conn.host, err)
+			_ = client.Close()
+			return
+		}
+		conn.Conn = tlsConn
+		conn.scheme = "https"
+	} else {
+		conn.Conn = &bufferedConn{Conn: client, r: reader}
+	}
+
+	// Serve returns once the listener is drained; the connection is
+	// served until the client closes it
+	_ = p.mitmServer().Serve(&connListener{conn: conn})
+}
+
+// handshakeMITM completes the client TLS handshake of a tunnel to host. The
+// certificate follows the SNI name, falling back to the CONNECT host for
+// clients that send none.
+func (p *Proxy) handshakeMITM(client net.Conn, host string) (*tls.Conn, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	var cached bool
+	tlsConn := tls.Server(client, &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = host
+			}
+			cert, hit, err := p.certs.certificate(name)
+			cached = hit
+			return cert, err
+		},
+	})
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), mitmHandshakeTimeout)
+	defer cancel()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	if p.config.Metrics != nil {
+		p.config.Metrics.RecordTLSHandshake(context.Background(), time.Since(start), cached)
+	}
+	return tlsConn, nil
+}
+
+// mitmServer returns the server for decrypted tunnel connections. It speaks
+// HTTP/1.1 and, for clients that negotiated h2, HTTP/2.
+func (p *Proxy) mitmServer() *http.Server {
+	p.mitmOnce.Do(func() {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+
+		p.mitm = &http.Server{
+			Handler:           http.HandlerFunc(p.serveIntercepted),
+			Protocols:         protocols,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       90 * time.Second,
+			ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+				return context.WithValue(ctx, mitmConnKey{}, c)
+			},
+		}
+	})
+	return p.mitm
+}
+
+// serveIntercepted passes a request read from a tunnel to the proxy handlers.
+func (p *Proxy) serveIntercepted(w http.ResponseWriter, r *http.Request) {
+	conn, ok := r.Context().Value(mitmConnKey{}).(*mitmConn)
+	if !ok {
+		http.Error(w, "not an intercepted connection", http.StatusInternalServerError)
+		return
+	}
+
+	in := &intercepted{user: conn.user}
+	if r.ProtoMajor == 2 {
+		in.streamID = conn.streams.streamID(r.Method, r.RequestURI)
+	}
+	r = r.WithContext(context.WithValue(r.Context(), interceptedKey{}, in))
+
+	r.URL.Scheme = conn.scheme
+	r.URL.Host = r.Host
+	if r.URL.Host == "" {
+		r.URL.Host = conn.host
+	}
+
+	// Streamed gRPC messages must reach the client as they arrive
+	if capture.IsGRPCContentType(r.Header.Get("Content-Type")) {
+		w = &flushWriter{ResponseWriter: w}
+	}
+	p.server.ServeHTTP(w, r)
+}
+
+// flushWriter flushes after every write.
+type flushWriter struct {
+	http.ResponseWriter
+}
+
+func (w *flushWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err == nil {
+		err = http.NewResponseController(w.ResponseWriter).Flush()
+	}
+	return n, err
+}
+
+func (w *flushWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *flushWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bufferedConn reads through a bufio.Reader that may hold peeked bytes.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// connListener is a listener that yields a single connection.
+type connListener struct {
+	conn net.Conn
+	once sync.Once
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() { conn = l.conn })
+	if conn == nil {
+		return nil, net.ErrClosed
+	}
+	return conn, nil
+}
+
+func (l *connListener) Close() error {
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
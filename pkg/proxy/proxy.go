@@ -3,15 +3,26 @@ package proxy
 
 import (
 	"crypto/tls"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/elazarl/goproxy"
 	"github.com/grokify/mogo/log/slogutil"
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/breakpoint"
 	"github.com/grokify/omniproxy/pkg/ca"
 	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/mock"
+	"github.com/grokify/omniproxy/pkg/netsim"
+	"github.com/grokify/omniproxy/pkg/observability"
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+	"github.com/grokify/omniproxy/pkg/rewrite"
 )
 
 // Proxy represents an HTTP/HTTPS proxy server.
@@ -20,6 +31,12 @@ type Proxy struct {
 	ca       *ca.CA
 	capturer *capture.Capturer
 	config   *Config
+
+	// certs issues certificates for intercepted hosts
+	certs *certIssuer
+	// mitm serves decrypted tunnel connections
+	mitm     *http.Server
+	mitmOnce sync.Once
 }
 
 // Config holds proxy configuration options.
@@ -38,6 +55,64 @@ type Config struct {
 	SkipHosts []string
 	// Upstream is the upstream proxy URL (e.g., http://proxy:8080)
 	Upstream string
+	// VerifyUpstream verifies the TLS certificates of origin servers, with
+	// or without an upstream proxy. It is off by default, like goproxy, so
+	// hosts with self-signed certificates can be intercepted.
+	VerifyUpstream bool
+	// Mock answers matching requests from recorded traffic (optional)
+	Mock *mock.Server
+	// Rewrite applies request/response rewrite rules (optional)
+	Rewrite *rewrite.Engine
+	// RecordOriginal captures the pre-rewrite request and response alongside the rewritten ones
+	RecordOriginal bool
+	// Breakpoints pauses matching requests and responses for editing (optional)
+	Breakpoints *breakpoint.Manager
+	// Network simulates network conditions for upstream traffic (optional)
+	Network *netsim.Simulator
+	// Auth requires clients to authenticate with Proxy-Authorization (optional)
+	Auth *proxyauth.Authenticator
+	// CertCache caches certificates issued for intercepted hosts (default: LRU of 1000)
+	CertCache backend.CertCache
+	// Metrics records certificate and TLS handshake metrics (optional)
+	Metrics *observability.Metrics
+}
+
+// requestState is the per-request state shared by the proxy handlers.
+type requestState struct {
+	// rec is the capture record (nil when not capturing)
+	rec *capture.Record
+	// rewrite holds the rewrite rules matched by the request
+	rewrite *rewrite.Result
+	// original is the request before rewriting (when RecordOriginal is set)
+	original *capture.RequestRecord
+	// network is the name of the network profile applied to the request
+	network string
+	// user is the authenticated proxy user
+	user string
+	// streamID is the HTTP/2 stream of an intercepted request
+	streamID uint32
+}
+
+// connectState is the state of an authenticated CONNECT tunnel. Requests
+// intercepted in the tunnel inherit its user.
+type connectState struct {
+	// user is the user that authenticated the CONNECT request
+	user string
+}
+
+// stateFor returns the request state stored in ctx, creating it if needed.
+// Requests intercepted in a tunnel start with the tunnel's user and stream.
+func stateFor(ctx *goproxy.ProxyCtx) *requestState {
+	if st, ok := ctx.UserData.(*requestState); ok {
+		return st
+	}
+	st := &requestState{}
+	if in := interceptedFrom(ctx.Req); in != nil {
+		st.user = in.user
+		st.streamID = in.streamID
+	}
+	ctx.UserData = st
+	return st
 }
 
 // DefaultConfig returns default proxy configuration.
@@ -58,6 +133,10 @@ func New(cfg *Config) (*Proxy, error) {
 
 	server := goproxy.NewProxyHttpServer()
 	server.Verbose = cfg.Verbose
+	// Speak HTTP/2 upstream when the origin supports it, keeping the rest
+	// of goproxy's transport
+	server.Tr.TLSClientConfig = upstreamTLSConfig(cfg)
+	server.Tr.ForceAttemptHTTP2 = true
 
 	p := &Proxy{
 		server:   server,
@@ -73,6 +152,11 @@ func New(cfg *Config) (*Proxy, error) {
 		}
 	}
 
+	// Setup authentication before any other handler sees the request
+	if cfg.Auth != nil {
+		p.setupAuth()
+	}
+
 	// Setup MITM if enabled
 	if cfg.EnableMITM && cfg.CA != nil {
 		if err := p.setupMITM(); err != nil {
@@ -80,11 +164,36 @@ func New(cfg *Config) (*Proxy, error) {
 		}
 	}
 
+	// Setup rewrite rules before capture so records reflect what was sent
+	if cfg.Rewrite != nil && cfg.Rewrite.Len() > 0 {
+		p.setupRewrite()
+	}
+
+	// Setup breakpoints after rewriting and before capture so records reflect edits
+	if cfg.Breakpoints != nil {
+		p.setupBreakpoints()
+	}
+
+	// Setup network simulation once the request's final host is known
+	if cfg.Network != nil {
+		p.setupNetwork()
+	}
+
 	// Setup request/response capture
 	if cfg.Capturer != nil {
 		p.setupCapture()
 	}
 
+	// Answer locally synthesized responses after capture has started
+	if cfg.Rewrite != nil && cfg.Rewrite.Len() > 0 {
+		p.setupLocalResponses()
+	}
+
+	// Setup mock responses after capture so mocked exchanges are recorded too
+	if cfg.Mock != nil {
+		p.setupMock()
+	}
+
 	return p, nil
 }
 
@@ -97,11 +206,9 @@ func (p *Proxy) setupUpstream(upstreamURL string) error {
 
 	// Set the proxy's transport to use the upstream proxy
 	p.server.Tr = &http.Transport{
-		Proxy: http.ProxyURL(upstream),
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, //nolint:gosec // Intentional for MITM proxy to intercept HTTPS
-			MinVersion:         tls.VersionTLS12,
-		},
+		Proxy:             http.ProxyURL(upstream),
+		TLSClientConfig:   upstreamTLSConfig(p.config),
+		ForceAttemptHTTP2: true,
 	}
 
 	// For CONNECT requests, use the upstream proxy
@@ -110,6 +217,15 @@ func (p *Proxy) setupUpstream(upstreamURL string) error {
 	return nil
 }
 
+// upstreamTLSConfig returns the TLS configuration for connections to
+// origin servers.
+func upstreamTLSConfig(cfg *Config) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: !cfg.VerifyUpstream, //nolint:gosec // Intentional for MITM proxy to intercept HTTPS
+		MinVersion:         tls.VersionTLS12,
+	}
+}
+
 // setupMITM configures HTTPS interception.
 func (p *Proxy) setupMITM() error {
 	tlsCert, err := p.ca.TLSCertificate()
@@ -117,6 +233,12 @@ func (p *Proxy) setupMITM() error {
 		return err
 	}
 
+	cache := p.config.CertCache
+	if cache == nil {
+		cache = backend.NewLRUCertCache(nil)
+	}
+	p.certs = &certIssuer{ca: p.ca, cache: cache, metrics: p.config.Metrics}
+
 	// Set up goproxy's MITM config
 	goproxy.GoproxyCa = tlsCert
 	goproxy.OkConnect = &goproxy.ConnectAction{Action: goproxy.ConnectAccept, TLSConfig: goproxy.TLSConfigFromCA(&tlsCert)}
@@ -140,7 +262,7 @@ func (p *Proxy) setupMITM() error {
 					return goproxy.OkConnect, host
 				}
 			}
-			return goproxy.MitmConnect, host
+			return p.mitmAction(), host
 		}))
 
 	// Set custom TLS config
@@ -150,12 +272,86 @@ func (p *Proxy) setupMITM() error {
 	return nil
 }
 
+// setupAuth rejects requests and CONNECT tunnels without valid proxy
+// credentials. Requests intercepted in an authenticated tunnel inherit the
+// tunnel's user.
+func (p *Proxy) setupAuth() {
+	auth := p.config.Auth
+
+	p.server.OnRequest().HandleConnectFunc(func(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
+		id, err := auth.Authenticate(ctx.Req)
+		if err != nil {
+			logAuthError(ctx.Req, err)
+			return &goproxy.ConnectAction{
+				Action: goproxy.ConnectProxyAuthHijack,
+				Hijack: func(req *http.Request, client net.Conn, ctx *goproxy.ProxyCtx) {
+					// goproxy has written the status line
+					h := make(http.Header)
+					auth.SetChallenge(h)
+					h.Set("Content-Length", "0")
+					h.Set("Connection", "close")
+					_ = h.Write(client)
+					_, _ = client.Write([]byte("\r\n"))
+					_ = client.Close()
+				},
+			}, host
+		}
+		ctx.UserData = &connectState{user: id.User}
+		return nil, host
+	})
+
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		if interceptedFrom(req) != nil {
+			// Authenticated with the CONNECT request
+			stateFor(ctx)
+			return req, nil
+		}
+		id, err := auth.Authenticate(req)
+		if err != nil {
+			logAuthError(req, err)
+			return req, auth.Challenge(req)
+		}
+		// Credentials are for the proxy only: don't forward or capture them
+		req.Header.Del(proxyauth.HeaderProxyAuthorization)
+		stateFor(ctx).user = id.User
+		return req, nil
+	})
+}
+
+// logAuthError logs rejected credentials. Missing credentials are expected
+// on a client's first request and are not logged.
+func logAuthError(req *http.Request, err error) {
+	if errors.Is(err, proxyauth.ErrMissingCredentials) {
+		return
+	}
+	logger := slogutil.LoggerFromContext(req.Context(), slogutil.Null())
+	logger.Warn("proxy authentication failed", "remote", req.RemoteAddr, "error", err)
+}
+
 // setupCapture configures request/response capture.
 func (p *Proxy) setupCapture() {
 	// Capture requests
 	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
 		if p.capturer != nil {
-			ctx.UserData = p.capturer.StartCapture(req)
+			// Disable permessage-deflate so captured WebSocket frames stay readable
+			if isWebSocketRequest(req) {
+				req.Header.Del("Sec-WebSocket-Extensions")
+			}
+			rec := p.capturer.StartCapture(req)
+			req = p.capturer.TraceRequest(rec, req)
+
+			st := stateFor(ctx)
+			st.rec = rec
+			rec.NetworkProfile = st.network
+			rec.User = st.user
+			rec.StreamID = st.streamID
+			if st.rewrite != nil {
+				rec.Rewrite = &capture.RewriteRecord{
+					Rules:           st.rewrite.Rules,
+					Local:           st.rewrite.Response != nil,
+					OriginalRequest: st.original,
+				}
+			}
 		}
 		return req, nil
 	})
@@ -163,9 +359,20 @@ func (p *Proxy) setupCapture() {
 	// Capture responses
 	p.server.OnResponse().DoFunc(func(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
 		if p.capturer != nil && ctx.UserData != nil {
-			if rec, ok := ctx.UserData.(*capture.Record); ok {
+			if st, ok := ctx.UserData.(*requestState); ok && st.rec != nil {
+				rec := st.rec
+				logger := slogutil.LoggerFromContext(ctx.Req.Context(), slogutil.Null())
+				if capture.IsWebSocketUpgrade(resp) {
+					p.capturer.CaptureWebSocket(rec, resp, func(err error) {
+						logger.Error("failed to capture websocket frame", "error", err)
+					})
+				}
+				// Bodies are teed as they stream to the client, and the
+				// record is finished once the body ends
+				p.capturer.CaptureBody(rec, resp, func(err error) {
+					logger.Error("failed to finish capture", "error", err)
+				})
 				if err := p.capturer.FinishCapture(rec, resp); err != nil {
-					logger := slogutil.LoggerFromContext(resp.Request.Context(), slogutil.Null())
 					logger.Error("failed to finish capture", "error", err)
 				}
 			}
@@ -174,6 +381,112 @@ func (p *Proxy) setupCapture() {
 	})
 }
 
+// setupRewrite applies rewrite rules to requests and responses.
+func (p *Proxy) setupRewrite() {
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		var original *capture.RequestRecord
+		if p.config.RecordOriginal && p.capturer != nil {
+			rr := p.capturer.CaptureRequest(req)
+			original = &rr
+		}
+
+		res, err := p.config.Rewrite.RewriteRequest(req)
+		if err != nil {
+			logger := slogutil.LoggerFromContext(req.Context(), slogutil.Null())
+			logger.Error("failed to rewrite request", "error", err)
+		}
+		if res != nil {
+			st := stateFor(ctx)
+			st.rewrite = res
+			st.original = original
+		}
+		return req, nil
+	})
+
+	p.server.OnResponse().DoFunc(func(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+		st, ok := ctx.UserData.(*requestState)
+		if !ok || st.rewrite == nil || resp == nil || !st.rewrite.HasResponseActions() {
+			return resp
+		}
+
+		if p.config.RecordOriginal && p.capturer != nil && st.rec != nil && st.rec.Rewrite != nil {
+			original := p.capturer.CaptureResponse(resp)
+			st.rec.Rewrite.OriginalResponse = &original
+		}
+
+		if err := st.rewrite.RewriteResponse(resp); err != nil {
+			logger := slogutil.LoggerFromContext(ctx.Req.Context(), slogutil.Null())
+			logger.Error("failed to rewrite response", "error", err)
+		}
+		return resp
+	})
+}
+
+// setupBreakpoints pauses matching requests and responses until they are
+// resumed, dropped or time out.
+func (p *Proxy) setupBreakpoints() {
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		return p.config.Breakpoints.PauseRequest(req)
+	})
+
+	p.server.OnResponse().DoFunc(func(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+		return p.config.Breakpoints.PauseResponse(ctx.Req, resp)
+	})
+}
+
+// setupNetwork applies network profiles to upstream round trips and to
+// tunnelled CONNECT streams.
+func (p *Proxy) setupNetwork() {
+	sim := p.config.Network
+
+	dial := p.server.ConnectDial
+	if dial == nil {
+		dial = func(network, addr string) (net.Conn, error) {
+			return net.Dial(network, addr)
+		}
+	}
+	p.server.ConnectDial = sim.WrapDial(dial)
+
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		host := req.URL.Host
+		if host == "" {
+			host = req.Host
+		}
+		profile := sim.ProfileFor(host)
+		if profile == nil {
+			return req, nil
+		}
+		stateFor(ctx).network = profile.Name
+		ctx.RoundTripper = goproxy.RoundTripperFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Response, error) {
+			return sim.RoundTrip(profile, req, ctx.Proxy.Tr)
+		})
+		return req, nil
+	})
+}
+
+// setupLocalResponses returns responses synthesized by rewrite rules.
+func (p *Proxy) setupLocalResponses() {
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		if st, ok := ctx.UserData.(*requestState); ok && st.rewrite != nil && st.rewrite.Response != nil {
+			return req, st.rewrite.Response
+		}
+		return req, nil
+	})
+}
+
+// setupMock answers requests from recorded traffic instead of the network.
+func (p *Proxy) setupMock() {
+	p.server.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		if resp := p.config.Mock.Respond(req); resp != nil {
+			return req, resp
+		}
+		if p.config.Mock.Passthrough() {
+			return req, nil
+		}
+		return req, p.config.Mock.NotFound(req)
+	})
+}
+
 // Server returns the underlying goproxy server.
 func (p *Proxy) Server() *goproxy.ProxyHttpServer {
 	return p.server
@@ -193,6 +506,11 @@ func (p *Proxy) ListenAndServe(addr string) error {
 	return server.ListenAndServe()
 }
 
+// isWebSocketRequest reports whether req asks to upgrade to WebSocket.
+func isWebSocketRequest(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
 // matchWildcard performs simple wildcard matching (e.g., *.example.com).
 func matchWildcard(pattern, host string) bool {
 	if len(pattern) == 0 {
@@ -0,0 +1,788 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/pkg/breakpoint"
+	"github.com/grokify/omniproxy/pkg/ca"
+	"github.com/grokify/omniproxy/pkg/capture"
+	"github.com/grokify/omniproxy/pkg/netsim"
+	"github.com/grokify/omniproxy/pkg/proxyauth"
+	"github.com/grokify/omniproxy/pkg/rewrite"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// newTestClient starts p and returns a client that uses it as its proxy.
+func newTestClient(t *testing.T, p *Proxy) *http.Client {
+	t.Helper()
+	server := httptest.NewServer(p.Server())
+	t.Cleanup(server.Close)
+	proxyURL, _ := url.Parse(server.URL)
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+}
+
+func readRecords(t *testing.T, buf *bytes.Buffer) []*capture.Record {
+	t.Helper()
+	recs, err := capture.ReadNDJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recs
+}
+
+func TestProxyRewrite(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"env":"`+r.Header.Get("X-Env")+`","beta":false}`)
+	}))
+	defer upstream.Close()
+
+	engine, err := rewrite.New([]rewrite.Rule{
+		{
+			Name:    "env",
+			Match:   rewrite.Match{Paths: []string{"/api/*"}},
+			Request: &rewrite.RequestActions{Actions: rewrite.Actions{SetHeaders: map[string]string{"X-Env": "staging"}}},
+			Response: &rewrite.ResponseActions{Actions: rewrite.Actions{
+				JSON: []rewrite.JSONPatch{{Path: "$.beta", Value: true}},
+			}},
+		},
+		{
+			Name:    "blocked",
+			Match:   rewrite.Match{Paths: []string{"/track"}},
+			Respond: &rewrite.LocalResponse{Status: http.StatusNoContent},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	capturer := capture.NewCapturer(&capture.Config{
+		Output:         buf,
+		Format:         capture.FormatNDJSON,
+		IncludeHeaders: true,
+		IncludeBody:    true,
+		MaxBodySize:    1024,
+	})
+
+	p, err := New(&Config{Capturer: capturer, Rewrite: engine, RecordOriginal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, p)
+
+	resp, err := client.Get(upstream.URL + "/api/flags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"beta":true,"env":"staging"}` {
+		t.Errorf("unexpected rewritten body: %s", body)
+	}
+
+	resp, err = client.Get(upstream.URL + "/track")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected local 204, got %d", resp.StatusCode)
+	}
+
+	recs := readRecords(t, buf)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+
+	// The record holds what was sent and returned; the original is kept aside
+	rec := recs[0]
+	if rec.Rewrite == nil || rec.Rewrite.Rules[0] != "env" {
+		t.Fatalf("expected rewrite info, got %+v", rec.Rewrite)
+	}
+	if rec.Request.Headers.Get("x-env") != "staging" || rec.Rewrite.OriginalRequest.Headers.Get("x-env") != "" {
+		t.Errorf("unexpected request headers: %v / %v", rec.Request.Headers, rec.Rewrite.OriginalRequest.Headers)
+	}
+	got, _ := json.Marshal(rec.Response.Body)
+	orig, _ := json.Marshal(rec.Rewrite.OriginalResponse.Body)
+	if !strings.Contains(string(got), `"beta":true`) || !strings.Contains(string(orig), `"beta":false`) {
+		t.Errorf("unexpected response bodies: %s / %s", got, orig)
+	}
+
+	if local := recs[1].Rewrite; local == nil || !local.Local || recs[1].Response.Status != http.StatusNoContent {
+		t.Errorf("expected local response record, got %+v", recs[1])
+	}
+}
+
+func TestProxyBreakpoints(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.Header.Get("X-User"))
+	}))
+	defer upstream.Close()
+
+	m := breakpoint.New(nil)
+	if _, err := m.AddRule(breakpoint.Rule{Paths: []string{"/greet"}, Request: true, Response: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	capturer := capture.NewCapturer(&capture.Config{
+		Output:         buf,
+		Format:         capture.FormatNDJSON,
+		IncludeHeaders: true,
+		IncludeBody:    true,
+		MaxBodySize:    1024,
+	})
+	p, err := New(&Config{Capturer: capturer, Breakpoints: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, p)
+
+	// Resume each paused phase as soon as it shows up
+	go func() {
+		for _, edit := range []*breakpoint.Edit{
+			{Headers: http.Header{"X-User": {"alice"}}},
+			{Status: http.StatusTeapot},
+		} {
+			for {
+				if list := m.List(); len(list) > 0 {
+					if err := m.Resume(list[0].ID, edit); err != nil {
+						t.Error(err)
+					}
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+	}()
+
+	resp, err := client.Get(upstream.URL + "/greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot || string(body) != "hello alice" {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+	}
+
+	// The record reflects the edited exchange
+	recs := readRecords(t, buf)
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	if recs[0].Request.Headers.Get("x-user") != "alice" || recs[0].Response.Status != http.StatusTeapot {
+		t.Errorf("unexpected record: %+v", recs[0])
+	}
+}
+
+func TestProxyNetwork(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	sim, err := netsim.New(&netsim.Config{
+		Profiles: []netsim.Profile{{Name: "broken", ErrorRate: 1}},
+		Default:  "broken",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	capturer := capture.NewCapturer(&capture.Config{Output: buf, Format: capture.FormatNDJSON})
+
+	p, err := New(&Config{Capturer: capturer, Network: sim})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, p)
+
+	resp, err := client.Get(upstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected injected 503, got %d", resp.StatusCode)
+	}
+
+	// Switching the profile at runtime applies to the next request
+	if err := sim.Apply(&netsim.Config{Default: netsim.ProfileNone}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Get(upstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected upstream 200, got %d", resp.StatusCode)
+	}
+
+	recs := readRecords(t, buf)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	if recs[0].NetworkProfile != "broken" || recs[1].NetworkProfile != "" {
+		t.Errorf("unexpected network profiles: %q, %q", recs[0].NetworkProfile, recs[1].NetworkProfile)
+	}
+}
+
+func TestProxyNetworkWebSocket(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		for {
+			typ, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			if err := conn.Write(r.Context(), typ, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	sim, err := netsim.New(&netsim.Config{
+		Profiles: []netsim.Profile{{Name: "slow", DownloadKbps: 80, UploadKbps: 80}},
+		Default:  "slow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	capturer := capture.NewCapturer(&capture.Config{IncludeBody: true})
+	var mu sync.Mutex
+	var frames []*capture.Record
+	capturer.AddHandler(func(rec *capture.Record) {
+		if rec.Type == capture.RecordTypeWebSocketFrame {
+			mu.Lock()
+			frames = append(frames, rec)
+			mu.Unlock()
+		}
+	})
+
+	p, err := New(&Config{Capturer: capturer, Network: sim})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, p)
+
+	// The upgraded connection is shaped, but still relays both directions
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, strings.Replace(upstream.URL, "http", "ws", 1), &websocket.DialOptions{HTTPClient: client})
+	if err != nil {
+		t.Fatalf("Dial through shaped proxy failed: %v", err)
+	}
+	defer conn.CloseNow()
+
+	// 2000 bytes at 80 kbps (10000 bytes/s) take about 200ms each way
+	msg := strings.Repeat("x", 2000)
+	start := time.Now()
+	if err := conn.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	_, echo, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(echo) != msg {
+		t.Errorf("unexpected echo of %d bytes", len(echo))
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("expected shaped echo to take at least 250ms, took %v", elapsed)
+	}
+	_ = conn.Close(websocket.StatusNormalClosure, "")
+
+	// Frames in both directions are captured
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(frames)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(frames) < 2 || frames[0].WebSocket.Direction != capture.DirectionClientToServer || frames[1].WebSocket.Direction != capture.DirectionServerToClient {
+		t.Fatalf("expected frames in both directions, got %d", len(frames))
+	}
+}
+
+func TestProxyAuth(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "proxy-auth="+r.Header.Get("Proxy-Authorization"))
+	}))
+	defer upstream.Close()
+	tlsUpstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer tlsUpstream.Close()
+
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := proxyauth.New(&proxyauth.Config{Tokens: []proxyauth.Token{{Name: "alice", Token: "alice-token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	capturer := capture.NewCapturer(&capture.Config{Output: buf, Format: capture.FormatNDJSON, IncludeBody: true, MaxBodySize: 1024})
+
+	p, err := New(&Config{Capturer: capturer, Auth: authenticator, EnableMITM: true, CA: authority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Server().Tr = tlsUpstream.Client().Transport.(*http.Transport)
+	server := httptest.NewServer(p.Server())
+	defer server.Close()
+
+	clientFor := func(userinfo *url.Userinfo) *http.Client {
+		proxyURL, _ := url.Parse(server.URL)
+		proxyURL.User = userinfo
+		return &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Test against the MITM certificate
+		}}
+	}
+
+	// Without credentials: 407 with a challenge, for requests and tunnels
+	resp, err := clientFor(nil).Get(upstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusProxyAuthRequired || resp.Header.Get("Proxy-Authenticate") == "" {
+		t.Errorf("expected 407 challenge, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if _, err := clientFor(url.UserPassword("alice", "wrong")).Get(tlsUpstream.URL + "/"); err == nil || !strings.Contains(err.Error(), "Proxy Authentication Required") {
+		t.Errorf("expected CONNECT to be rejected, got %v", err)
+	}
+
+	// With credentials: the user is recorded and credentials are not forwarded
+	client := clientFor(url.UserPassword("alice", "alice-token"))
+	resp, err = client.Get(upstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "proxy-auth=" {
+		t.Errorf("expected credentials to be stripped, got %q", body)
+	}
+	resp, err = client.Get(tlsUpstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure" {
+		t.Errorf("unexpected MITM body: %q", body)
+	}
+
+	recs := readRecords(t, buf)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	for _, rec := range recs {
+		if rec.User != "alice" {
+			t.Errorf("expected user alice for %s, got %q", rec.Request.URL, rec.User)
+		}
+		if rec.Request.Headers.Has("proxy-authorization") {
+			t.Errorf("captured proxy credentials for %s", rec.Request.URL)
+		}
+	}
+}
+
+func TestProxyHTTP2(t *testing.T) {
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto+" "+r.URL.Path)
+	}))
+	upstream.EnableHTTP2 = true
+	upstream.StartTLS()
+	defer upstream.Close()
+
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	capturer := capture.NewCapturer(&capture.Config{Output: buf, Format: capture.FormatNDJSON, IncludeBody: true, MaxBodySize: 1024})
+
+	p, err := New(&Config{Capturer: capturer, EnableMITM: true, CA: authority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Server().Tr = upstream.Client().Transport.(*http.Transport)
+	server := httptest.NewServer(p.Server())
+	defer server.Close()
+
+	proxyURL, _ := url.Parse(server.URL)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:             http.ProxyURL(proxyURL),
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Test against the MITM certificate
+		ForceAttemptHTTP2: true,
+	}}
+
+	// Both requests share one HTTP/2 connection, on streams 1 and 3
+	for _, path := range []string{"/first", "/second"} {
+		resp, err := client.Get(upstream.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.ProtoMajor != 2 {
+			t.Errorf("expected HTTP/2 from the proxy, got %s", resp.Proto)
+		}
+		if string(body) != "HTTP/2.0 "+path {
+			t.Errorf("expected HTTP/2 upstream, got %q", body)
+		}
+	}
+
+	recs := readRecords(t, buf)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	for i, rec := range recs {
+		if rec.Request.HTTPVersion != "HTTP/2.0" || rec.Response.HTTPVersion != "HTTP/2.0" {
+			t.Errorf("unexpected versions for %s: %s, %s", rec.Request.Path, rec.Request.HTTPVersion, rec.Response.HTTPVersion)
+		}
+		if want := uint32(2*i + 1); rec.StreamID != want {
+			t.Errorf("expected stream %d for %s, got %d", want, rec.Request.Path, rec.StreamID)
+		}
+		if entry := capture.RecordToHAREntry(rec); entry.Request.HTTPVersion != "HTTP/2.0" {
+			t.Errorf("unexpected HAR version: %s", entry.Request.HTTPVersion)
+		}
+	}
+}
+
+func TestProxyVerifyUpstream(t *testing.T) {
+	tlsUpstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer tlsUpstream.Close()
+
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain proxy to chain through, which tunnels CONNECT requests
+	chained, err := New(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainedServer := httptest.NewServer(chained.Server())
+	defer chainedServer.Close()
+
+	// The self-signed origin is accepted unless verification is enabled,
+	// with or without an upstream proxy
+	for _, upstream := range []string{"", chainedServer.URL} {
+		for _, verify := range []bool{false, true} {
+			p, err := New(&Config{EnableMITM: true, CA: authority, Upstream: upstream, VerifyUpstream: verify})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server := httptest.NewServer(p.Server())
+			proxyURL, _ := url.Parse(server.URL)
+			client := &http.Client{Transport: &http.Transport{
+				Proxy:           http.ProxyURL(proxyURL),
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Test against the MITM certificate
+			}}
+
+			resp, err := client.Get(tlsUpstream.URL + "/")
+			ok := err == nil && resp.StatusCode == http.StatusOK
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if ok == verify {
+				t.Errorf("upstream %q, verify %v: unexpected result %v", upstream, verify, err)
+			}
+			server.Close()
+		}
+	}
+}
+
+func TestStreamSniffer(t *testing.T) {
+	var conn bytes.Buffer
+	conn.WriteString(http2ClientPreface)
+	framer := http2.NewFramer(&conn, nil)
+	var block bytes.Buffer
+	encoder := hpack.NewEncoder(&block)
+
+	headers := func(method, path string) []byte {
+		block.Reset()
+		for _, f := range []hpack.HeaderField{
+			{Name: ":method", Value: method},
+			{Name: ":path", Value: path},
+			{Name: ":scheme", Value: "https"},
+			{Name: ":authority", Value: "example.com"},
+			{Name: "x-request", Value: path},
+		} {
+			_ = encoder.WriteField(f)
+		}
+		return append([]byte(nil), block.Bytes()...)
+	}
+
+	_ = framer.WriteSettings()
+	_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: headers("GET", "/a"), EndHeaders: true, EndStream: true})
+	// A padded, prioritized header block split across a CONTINUATION frame
+	b := headers("POST", "/b")
+	_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 3, BlockFragment: b[:3], PadLength: 4, Priority: http2.PriorityParam{Weight: 16}})
+	_ = framer.WriteContinuation(3, true, b[3:])
+	_ = framer.WriteData(3, false, []byte("payload"))
+	// Trailers are not new requests
+	block.Reset()
+	_ = encoder.WriteField(hpack.HeaderField{Name: "x-trailer", Value: "1"})
+	_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 3, BlockFragment: block.Bytes(), EndHeaders: true, EndStream: true})
+	// Repeated requests reuse the dynamic table
+	_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 5, BlockFragment: headers("GET", "/a"), EndHeaders: true, EndStream: true})
+
+	// Feed the connection in small reads
+	s := newStreamSniffer()
+	data := conn.Bytes()
+	for len(data) > 0 {
+		n := min(5, len(data))
+		s.observe(data[:n])
+		data = data[n:]
+	}
+
+	tests := []struct {
+		method, path string
+		want         uint32
+	}{
+		{"POST", "/b", 3},
+		{"GET", "/a", 1},
+		{"GET", "/a", 5},
+		{"GET", "/a", 0},
+		{"GET", "/missing", 0},
+	}
+	for _, tt := range tests {
+		if got := s.streamID(tt.method, tt.path); got != tt.want {
+			t.Errorf("streamID(%s %s) = %d, want %d", tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	// HTTP/1.x connections are ignored
+	s = newStreamSniffer()
+	s.observe([]byte("GET /a HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	if !s.disabled || s.streamID("GET", "/a") != 0 {
+		t.Error("expected HTTP/1.1 connection to be ignored")
+	}
+}
+
+func TestProxyGRPC(t *testing.T) {
+	frame := func(msg string) []byte {
+		b := []byte{0, 0, 0, 0, byte(len(msg) + 2), 0x0a, byte(len(msg))}
+		return append(b, msg...)
+	}
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		// Server streaming: two messages, then the status in trailers
+		for _, msg := range []string{"hello", "again"} {
+			_, _ = w.Write(frame(msg))
+			w.(http.Flusher).Flush()
+		}
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	}))
+	upstream.EnableHTTP2 = true
+	upstream.StartTLS()
+	defer upstream.Close()
+
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capturer := capture.NewCapturer(&capture.Config{Format: capture.FormatNDJSON, IncludeBody: true, MaxBodySize: 1024})
+	p, err := New(&Config{Capturer: capturer, EnableMITM: true, CA: authority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Server().Tr = upstream.Client().Transport.(*http.Transport)
+	server := httptest.NewServer(p.Server())
+	defer server.Close()
+
+	proxyURL, _ := url.Parse(server.URL)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:             http.ProxyURL(proxyURL),
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Test against the MITM certificate
+		ForceAttemptHTTP2: true,
+	}}
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL+"/test.v1.Greeter/SayHello", io.NopCloser(bytes.NewReader(frame("alice"))))
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 2*len(frame("hello")) || resp.Trailer.Get("Grpc-Status") != "0" {
+		t.Errorf("unexpected response: %d bytes, trailers %v", len(body), resp.Trailer)
+	}
+
+	// The record is finished once the streamed body has been relayed
+	var recs []capture.Record
+	for deadline := time.Now().Add(2 * time.Second); len(recs) == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		recs = capturer.Records()
+	}
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	rec := recs[0]
+	if rec.Type != capture.RecordTypeGRPC || rec.GRPC.FullMethod() != "/test.v1.Greeter/SayHello" || rec.GRPC.StatusName != "OK" {
+		t.Errorf("unexpected gRPC record: %s %+v", rec.Type, rec.GRPC)
+	}
+	if rec.GRPC.RequestMessages != 1 || rec.GRPC.ResponseMessages != 2 {
+		t.Errorf("unexpected message counts: %+v", rec.GRPC)
+	}
+}
+
+// countingCache counts certificates stored in a cache.
+type countingCache struct {
+	backend.CertCache
+	sets atomic.Int32
+}
+
+func (c *countingCache) Set(host string, cert *tls.Certificate) {
+	c.sets.Add(1)
+	c.CertCache.Set(host, cert)
+}
+
+func TestProxyCertCache(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &countingCache{CertCache: backend.NewLRUCertCache(nil)}
+	p, err := New(&Config{EnableMITM: true, CA: authority, CertCache: cache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Server().Tr = upstream.Client().Transport.(*http.Transport)
+	server := httptest.NewServer(p.Server())
+	defer server.Close()
+
+	proxyURL, _ := url.Parse(server.URL)
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+	get := func(serverName string) error {
+		client := &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: serverName, MinVersion: tls.VersionTLS12},
+		}}
+		resp, err := client.Get(upstream.URL)
+		if err != nil {
+			return err
+		}
+		_, _ = io.ReadAll(resp.Body)
+		return resp.Body.Close()
+	}
+
+	// Concurrent handshakes without SNI share one certificate for the IP
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- get("")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := cache.sets.Load(); n != 1 {
+		t.Errorf("expected 1 certificate for concurrent handshakes, got %d", n)
+	}
+
+	// Hosts of one domain share a wildcard certificate
+	for _, name := range []string{"api.example.com", "www.example.com"} {
+		if err := get(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if n := cache.sets.Load(); n != 2 {
+		t.Errorf("expected 2 certificates in total, got %d", n)
+	}
+	if _, ok := cache.Get("*.example.com"); !ok {
+		t.Error("expected a cached wildcard certificate")
+	}
+}
+
+// lockingCache is a cache shared by several proxies.
+type lockingCache struct {
+	countingCache
+	mu sync.Mutex
+}
+
+func (c *lockingCache) Lock(ctx context.Context, host string) (func(), error) {
+	c.mu.Lock()
+	return c.mu.Unlock, nil
+}
+
+func TestCertIssuerSharedCache(t *testing.T) {
+	authority, err := ca.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &lockingCache{countingCache: countingCache{CertCache: backend.NewLRUCertCache(nil)}}
+	replicas := []*certIssuer{{ca: authority, cache: cache}, {ca: authority, cache: cache}}
+
+	// Only one replica generates the certificate; the other reads it
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := replicas[i%2].certificate("api.example.com"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := cache.sets.Load(); n != 1 {
+		t.Errorf("expected 1 certificate across replicas, got %d", n)
+	}
+}
@@ -0,0 +1,108 @@
+package proxyauth
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // Required to verify {SHA} htpasswd entries
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Htpasswd holds Basic credentials from an htpasswd file.
+//
+// Supported hashes are bcrypt (htpasswd -B) and SHA1 (htpasswd -s). Plain
+// text passwords must be marked with a {PLAIN} prefix, since unmarked ones
+// cannot be told apart from crypt hashes. Any other entry, including MD5
+// (apr1) and crypt, is rejected when loading.
+type Htpasswd struct {
+	users map[string]string
+}
+
+// LoadHtpasswd reads an htpasswd file.
+func LoadHtpasswd(path string) (*Htpasswd, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer f.Close()
+
+	h, err := ParseHtpasswd(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse htpasswd file %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// ParseHtpasswd parses user:hash lines. Blank lines and lines starting with
+// # are ignored.
+func ParseHtpasswd(r io.Reader) (*Htpasswd, error) {
+	h := &Htpasswd{users: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("line %d: expected user:hash", line)
+		}
+		switch {
+		case isBcrypt(hash), strings.HasPrefix(hash, sha1Prefix), strings.HasPrefix(hash, plainPrefix):
+		case strings.HasPrefix(hash, "$apr1$"), strings.HasPrefix(hash, "$1$"):
+			return nil, fmt.Errorf("line %d: unsupported MD5 hash for %q (use htpasswd -B)", line, user)
+		default:
+			return nil, fmt.Errorf("line %d: unsupported hash for %q (use htpasswd -B, or prefix plain text passwords with %s)", line, user, plainPrefix)
+		}
+		h.users[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Len returns the number of users.
+func (h *Htpasswd) Len() int {
+	return len(h.users)
+}
+
+// Verify reports whether password matches the entry for user.
+func (h *Htpasswd) Verify(user, password string) bool {
+	hash, ok := h.users[user]
+	if !ok {
+		return false
+	}
+
+	switch {
+	case isBcrypt(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, sha1Prefix):
+		sum := sha1.Sum([]byte(password)) //nolint:gosec // Required to verify {SHA} htpasswd entries
+		expected := base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(hash[len(sha1Prefix):]), []byte(expected)) == 1
+	case strings.HasPrefix(hash, plainPrefix):
+		return subtle.ConstantTimeCompare([]byte(hash[len(plainPrefix):]), []byte(password)) == 1
+	default:
+		return false
+	}
+}
+
+// Prefixes of SHA1 and plain text htpasswd entries.
+const (
+	sha1Prefix  = "{SHA}"
+	plainPrefix = "{PLAIN}"
+)
+
+// isBcrypt reports whether hash is a bcrypt hash.
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,352 @@
+// Package proxyauth authenticates clients of the forward proxy.
+//
+// Clients send credentials in the Proxy-Authorization header, either as Basic
+// credentials or as a Bearer token. Credentials are checked against an
+// htpasswd file, static tokens and, optionally, the users and sessions of the
+// UI user store. Requests without valid credentials receive a 407 challenge.
+package proxyauth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/omniproxy/ui/auth"
+	"github.com/grokify/omniproxy/ui/ent"
+)
+
+// HeaderProxyAuthorization carries the client's proxy credentials.
+const HeaderProxyAuthorization = "Proxy-Authorization"
+
+// Authentication methods reported in Identity.Method.
+const (
+	MethodHtpasswd = "htpasswd"
+	MethodToken    = "token"
+	MethodUser     = "user"
+	MethodSession  = "session"
+)
+
+var (
+	// ErrMissingCredentials is returned when a request has no proxy credentials.
+	ErrMissingCredentials = errors.New("proxy credentials required")
+	// ErrInvalidCredentials is returned when proxy credentials are not accepted.
+	ErrInvalidCredentials = errors.New("invalid proxy credentials")
+)
+
+// Identity is an authenticated proxy client.
+type Identity struct {
+	// User is the user name, token name or user email
+	User string `json:"user"`
+	// Method is how the client authenticated
+	Method string `json:"method"`
+}
+
+// Token is a static bearer token.
+type Token struct {
+	// Name identifies the token holder in captured traffic
+	Name string `yaml:"name" json:"name"`
+	// Token is the secret the client sends
+	Token string `yaml:"token" json:"token"`
+}
+
+// UserService authenticates users and sessions of the UI user store.
+// *auth.Service from ui/auth implements it.
+type UserService interface {
+	Authenticate(ctx context.Context, email, password string) (*ent.User, error)
+	ValidateSession(ctx context.Context, token string) (*ent.User, *ent.Session, error)
+}
+
+// revocationNotifier is implemented by user services that report revoked
+// sessions and disabled users, such as *auth.Service.
+type revocationNotifier interface {
+	OnRevoke(fn func(email string))
+}
+
+// Config holds proxy authentication settings.
+type Config struct {
+	// Realm is sent in 407 challenges (default: "OmniProxy")
+	Realm string `yaml:"realm,omitempty"`
+	// HtpasswdFile is an htpasswd file with Basic credentials (bcrypt, SHA1 or {PLAIN})
+	HtpasswdFile string `yaml:"htpasswdFile,omitempty"`
+	// Tokens are static bearer tokens
+	Tokens []Token `yaml:"tokens,omitempty"`
+	// CacheTTL is how long accepted credentials are remembered (default: 1
+	// minute). Sessions revoked and users disabled through the Users service
+	// are dropped from the cache at once; changes made elsewhere, such as by
+	// another process sharing the database, take effect within CacheTTL.
+	CacheTTL time.Duration `yaml:"cacheTTL,omitempty"`
+	// Users authenticates UI users and sessions (optional)
+	Users UserService `yaml:"-"`
+}
+
+// DefaultConfig returns default proxy authentication settings.
+func DefaultConfig() *Config {
+	return &Config{
+		Realm:    "OmniProxy",
+		CacheTTL: time.Minute,
+	}
+}
+
+// Enabled reports whether any credential source is configured.
+func (c *Config) Enabled() bool {
+	return c != nil && (c.HtpasswdFile != "" || len(c.Tokens) > 0 || c.Users != nil)
+}
+
+// maxCacheEntries bounds the credential cache. The oldest entries are
+// evicted first.
+const maxCacheEntries = 10000
+
+// cacheEntry is an accepted credential.
+type cacheEntry struct {
+	key      [sha256.Size]byte
+	identity *Identity
+	expires  time.Time
+}
+
+// Authenticator checks proxy credentials.
+type Authenticator struct {
+	realm    string
+	htpasswd *Htpasswd
+	tokens   []Token
+	users    UserService
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]*list.Element
+	// order holds cache entries from oldest to newest
+	order *list.List
+}
+
+// New creates an authenticator.
+func New(cfg *Config) (*Authenticator, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if !cfg.Enabled() {
+		return nil, fmt.Errorf("no proxy credential source configured")
+	}
+
+	a := &Authenticator{
+		realm:    cfg.Realm,
+		users:    cfg.Users,
+		cacheTTL: cfg.CacheTTL,
+		cache:    make(map[[sha256.Size]byte]*list.Element),
+		order:    list.New(),
+	}
+	if a.realm == "" {
+		a.realm = DefaultConfig().Realm
+	}
+	if a.cacheTTL == 0 {
+		a.cacheTTL = DefaultConfig().CacheTTL
+	}
+
+	if cfg.HtpasswdFile != "" {
+		h, err := LoadHtpasswd(cfg.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		a.htpasswd = h
+	}
+	for _, t := range cfg.Tokens {
+		if t.Name == "" || t.Token == "" {
+			return nil, fmt.Errorf("proxy token requires a name and a token")
+		}
+		a.tokens = append(a.tokens, t)
+	}
+	if n, ok := cfg.Users.(revocationNotifier); ok {
+		n.OnRevoke(a.Revoke)
+	}
+
+	return a, nil
+}
+
+// Authenticate checks the Proxy-Authorization header of req.
+func (a *Authenticator) Authenticate(req *http.Request) (*Identity, error) {
+	header := req.Header.Get(HeaderProxyAuthorization)
+	if header == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	key := sha256.Sum256([]byte(header))
+	if id := a.cached(key); id != nil {
+		return id, nil
+	}
+
+	scheme, value, _ := strings.Cut(header, " ")
+	value = strings.TrimSpace(value)
+
+	var id *Identity
+	var err error
+	switch strings.ToLower(scheme) {
+	case "basic":
+		decoded, decodeErr := base64.StdEncoding.DecodeString(value)
+		if decodeErr != nil {
+			return nil, ErrInvalidCredentials
+		}
+		user, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, ErrInvalidCredentials
+		}
+		id, err = a.basic(req.Context(), user, password)
+	case "bearer":
+		id, err = a.bearer(req.Context(), value)
+	default:
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	a.store(key, id)
+	return id, nil
+}
+
+// basic checks Basic credentials. Clients that cannot send Bearer tokens
+// to a proxy may send a token as the password with any user name.
+func (a *Authenticator) basic(ctx context.Context, user, password string) (*Identity, error) {
+	if a.htpasswd != nil && a.htpasswd.Verify(user, password) {
+		return &Identity{User: user, Method: MethodHtpasswd}, nil
+	}
+	if name, ok := a.token(password); ok {
+		return &Identity{User: name, Method: MethodToken}, nil
+	}
+	if a.users != nil {
+		u, err := a.users.Authenticate(ctx, user, password)
+		if err == nil {
+			return &Identity{User: u.Email, Method: MethodUser}, nil
+		}
+		if !isRejection(err) {
+			return nil, fmt.Errorf("failed to authenticate user: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// bearer checks a static token or a UI session token.
+func (a *Authenticator) bearer(ctx context.Context, token string) (*Identity, error) {
+	if name, ok := a.token(token); ok {
+		return &Identity{User: name, Method: MethodToken}, nil
+	}
+	if a.users != nil {
+		u, _, err := a.users.ValidateSession(ctx, token)
+		if err == nil {
+			return &Identity{User: u.Email, Method: MethodSession}, nil
+		}
+		if !isRejection(err) {
+			return nil, fmt.Errorf("failed to validate session: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// token returns the name of the static token matching secret. Every token
+// is compared in constant time, so timing does not reveal how much of a
+// token was guessed.
+func (a *Authenticator) token(secret string) (string, bool) {
+	name, found := "", false
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(secret)) == 1 {
+			name, found = t.Name, true
+		}
+	}
+	return name, found
+}
+
+// isRejection reports whether a user store error means the credentials were
+// rejected, as opposed to the store failing.
+func isRejection(err error) bool {
+	return errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrUserInactive) ||
+		errors.Is(err, auth.ErrSessionInvalid) || errors.Is(err, auth.ErrSessionExpired)
+}
+
+// cached returns a remembered identity for a credential.
+func (a *Authenticator) cached(key [sha256.Size]byte) *Identity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	elem, ok := a.cache[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		a.evict(elem)
+		return nil
+	}
+	return entry.identity
+}
+
+// store remembers an accepted credential, evicting the oldest entries when
+// the cache is full.
+func (a *Authenticator) store(key [sha256.Size]byte, id *Identity) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if elem, ok := a.cache[key]; ok {
+		a.evict(elem)
+	}
+	for a.order.Len() >= maxCacheEntries {
+		a.evict(a.order.Front())
+	}
+	entry := &cacheEntry{key: key, identity: id, expires: time.Now().Add(a.cacheTTL)}
+	a.cache[key] = a.order.PushBack(entry)
+}
+
+// evict removes a cache entry. The caller holds a.mu.
+func (a *Authenticator) evict(elem *list.Element) {
+	delete(a.cache, elem.Value.(*cacheEntry).key)
+	a.order.Remove(elem)
+}
+
+// Revoke drops the cached credentials of a UI user, identified by email,
+// so the user's next request is checked against the user store again.
+// Authenticators register it with user services that report revocations.
+func (a *Authenticator) Revoke(email string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for elem := a.order.Front(); elem != nil; {
+		next := elem.Next()
+		id := elem.Value.(*cacheEntry).identity
+		if id.User == email && (id.Method == MethodUser || id.Method == MethodSession) {
+			a.evict(elem)
+		}
+		elem = next
+	}
+}
+
+// Challenge returns a 407 response asking the client for credentials.
+func (a *Authenticator) Challenge(req *http.Request) *http.Response {
+	body := "Proxy Authentication Required\n"
+	resp := &http.Response{
+		StatusCode:    http.StatusProxyAuthRequired,
+		Status:        "407 Proxy Authentication Required",
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	a.SetChallenge(resp.Header)
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return resp
+}
+
+// SetChallenge adds the Proxy-Authenticate challenges to h.
+func (a *Authenticator) SetChallenge(h http.Header) {
+	h.Add("Proxy-Authenticate", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", a.realm))
+	if len(a.tokens) > 0 || a.users != nil {
+		h.Add("Proxy-Authenticate", fmt.Sprintf("Bearer realm=%q", a.realm))
+	}
+}
@@ -0,0 +1,285 @@
+package proxyauth
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // Builds {SHA} htpasswd entries
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/backend"
+	"github.com/grokify/omniproxy/ui/auth"
+	"github.com/grokify/omniproxy/ui/ent"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUsers is an in-memory UserService.
+type fakeUsers struct {
+	passwords map[string]string
+	sessions  map[string]string
+	err       error
+	calls     int
+}
+
+func (f *fakeUsers) Authenticate(ctx context.Context, email, password string) (*ent.User, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if p, ok := f.passwords[email]; ok && p == password {
+		return &ent.User{Email: email}, nil
+	}
+	return nil, auth.ErrInvalidCredentials
+}
+
+func (f *fakeUsers) ValidateSession(ctx context.Context, token string) (*ent.User, *ent.Session, error) {
+	f.calls++
+	if email, ok := f.sessions[token]; ok {
+		return &ent.User{Email: email}, &ent.Session{}, nil
+	}
+	return nil, nil, auth.ErrSessionInvalid
+}
+
+func basicHeader(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func writeHtpasswd(t *testing.T) string {
+	t.Helper()
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("alice-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha1.Sum([]byte("bob-secret")) //nolint:gosec // Builds {SHA} htpasswd entries
+	content := strings.Join([]string{
+		"# team proxy users",
+		"alice:" + string(bcryptHash),
+		"bob:{SHA}" + base64.StdEncoding.EncodeToString(sum[:]),
+		"",
+		"carol:{PLAIN}carol-secret",
+	}, "\n")
+
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseHtpasswd(t *testing.T) {
+	h, err := LoadHtpasswd(writeHtpasswd(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 3 {
+		t.Errorf("expected 3 users, got %d", h.Len())
+	}
+
+	tests := []struct {
+		user, password string
+		want           bool
+	}{
+		{"alice", "alice-secret", true},
+		{"alice", "wrong", false},
+		{"bob", "bob-secret", true},
+		{"bob", "wrong", false},
+		{"carol", "carol-secret", true},
+		{"dave", "carol-secret", false},
+	}
+	for _, tt := range tests {
+		if got := h.Verify(tt.user, tt.password); got != tt.want {
+			t.Errorf("Verify(%q, %q) = %v, want %v", tt.user, tt.password, got, tt.want)
+		}
+	}
+
+	// Unmarked plain text could be a DES crypt hash (htpasswd -d)
+	for _, bad := range []string{"nohash", "dave:$apr1$abc$def", "erin:$6$salt$hash", "frank:rOR8wfl0Msz0E", "grace:grace-secret"} {
+		if _, err := ParseHtpasswd(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	users := &fakeUsers{
+		passwords: map[string]string{"dana@example.com": "dana-secret"},
+		sessions:  map[string]string{"session-token": "erin@example.com"},
+	}
+	a, err := New(&Config{
+		HtpasswdFile: writeHtpasswd(t),
+		Tokens:       []Token{{Name: "ci", Token: "ci-token"}},
+		Users:        users,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		want    Identity
+		wantErr error
+	}{
+		{"htpasswd", basicHeader("alice", "alice-secret"), Identity{User: "alice", Method: MethodHtpasswd}, nil},
+		{"bearer token", "Bearer ci-token", Identity{User: "ci", Method: MethodToken}, nil},
+		{"token as password", basicHeader("anything", "ci-token"), Identity{User: "ci", Method: MethodToken}, nil},
+		{"database user", basicHeader("dana@example.com", "dana-secret"), Identity{User: "dana@example.com", Method: MethodUser}, nil},
+		{"session", "Bearer session-token", Identity{User: "erin@example.com", Method: MethodSession}, nil},
+		{"missing", "", Identity{}, ErrMissingCredentials},
+		{"wrong password", basicHeader("alice", "wrong"), Identity{}, ErrInvalidCredentials},
+		{"unknown token", "Bearer nope", Identity{}, ErrInvalidCredentials},
+		{"malformed basic", "Basic !!!", Identity{}, ErrInvalidCredentials},
+		{"unsupported scheme", "Digest abc", Identity{}, ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderProxyAuthorization, tt.header)
+			}
+			id, err := a.Authenticate(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *id != tt.want {
+				t.Errorf("got %+v, want %+v", *id, tt.want)
+			}
+		})
+	}
+
+	// Accepted credentials are cached
+	calls := users.calls
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set(HeaderProxyAuthorization, "Bearer session-token")
+	if _, err := a.Authenticate(req); err != nil {
+		t.Fatal(err)
+	}
+	if users.calls != calls {
+		t.Errorf("expected cached session, got %d new user store calls", users.calls-calls)
+	}
+
+	// Store failures are errors, not rejections
+	users.err = errors.New("database is down")
+	req.Header.Set(HeaderProxyAuthorization, basicHeader("dana@example.com", "other"))
+	if _, err := a.Authenticate(req); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected store error, got %v", err)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	if _, err := New(&Config{}); err == nil {
+		t.Error("expected error without credential sources")
+	}
+
+	a, err := New(&Config{Realm: "Team", Tokens: []Token{{Name: "ci", Token: "ci-token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := a.Challenge(httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		t.Errorf("expected 407, got %d", resp.StatusCode)
+	}
+	challenges := resp.Header.Values("Proxy-Authenticate")
+	if len(challenges) != 2 || !strings.HasPrefix(challenges[0], `Basic realm="Team"`) || challenges[1] != `Bearer realm="Team"` {
+		t.Errorf("unexpected challenges: %q", challenges)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	store, err := backend.NewDatabaseTrafficStore(ctx, &backend.DatabaseTrafficStoreConfig{DatabaseURL: "sqlite::memory:", ProxyName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	client := store.Client()
+	org, err := client.Org.Query().First(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := auth.NewService(client, &auth.Config{SessionDuration: time.Hour, BcryptCost: bcrypt.MinCost})
+	hash, err := svc.HashPassword("dana-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dana, err := client.User.Create().SetEmail("dana@example.com").SetPasswordHash(hash).SetOrg(org).Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess, err := svc.CreateSession(ctx, dana.ID, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(&Config{Users: svc, CacheTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticate := func(header string) error {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Header.Set(HeaderProxyAuthorization, header)
+		_, err := a.Authenticate(req)
+		return err
+	}
+	bearer := "Bearer " + sess.Token
+	basic := basicHeader("dana@example.com", "dana-secret")
+	for _, h := range []string{bearer, basic} {
+		if err := authenticate(h); err != nil {
+			t.Fatalf("%s: %v", h, err)
+		}
+	}
+
+	// A logged out session is rejected despite being cached
+	if err := svc.Logout(ctx, sess.Token); err != nil {
+		t.Fatal(err)
+	}
+	if err := authenticate(bearer); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected revoked session to be rejected, got %v", err)
+	}
+
+	// So is a disabled user
+	if err := authenticate(basic); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetUserActive(ctx, dana.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := authenticate(basic); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected disabled user to be rejected, got %v", err)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	a, err := New(&Config{Tokens: []Token{{Name: "ci", Token: "ci-token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := &Identity{User: "ci", Method: MethodToken}
+	for i := 0; i <= maxCacheEntries; i++ {
+		a.store(sha256.Sum256([]byte(strconv.Itoa(i))), id)
+	}
+	if len(a.cache) != maxCacheEntries || a.order.Len() != maxCacheEntries {
+		t.Fatalf("cache holds %d entries, want %d", len(a.cache), maxCacheEntries)
+	}
+	if a.cached(sha256.Sum256([]byte("0"))) != nil {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	if a.cached(sha256.Sum256([]byte("1"))) == nil || a.cached(sha256.Sum256([]byte(strconv.Itoa(maxCacheEntries)))) == nil {
+		t.Error("expected newer entries to be kept")
+	}
+}
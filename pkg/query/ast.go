@@ -0,0 +1,154 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Expr is a node of a parsed expression: *And, *Or, *Not or *Compare.
+type Expr interface {
+	// String formats the expression in canonical syntax.
+	String() string
+
+	match(rec *capture.Record) bool
+}
+
+// And matches records that match both sides.
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches records that match either side.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches records that do not match X.
+type Not struct {
+	X Expr
+}
+
+// Compare compares a record field with one value, with a list of values for
+// OpIn, or tests that a keyed field is present for OpExists.
+type Compare struct {
+	Field  Field
+	Op     Op
+	Values []Value
+
+	patterns []*regexp.Regexp // compiled wildcard values
+}
+
+// Value returns the first value of the comparison.
+func (c *Compare) Value() Value {
+	if len(c.Values) == 0 {
+		return Value{}
+	}
+	return c.Values[0]
+}
+
+// Field names, in canonical form.
+const (
+	FieldHost           = "host"
+	FieldMethod         = "method"
+	FieldPath           = "path"
+	FieldURL            = "url"
+	FieldScheme         = "scheme"
+	FieldStatus         = "status"
+	FieldDuration       = "duration"
+	FieldClientIP       = "client_ip"
+	FieldUser           = "user"
+	FieldType           = "type"
+	FieldConnectionID   = "connection_id"
+	FieldTag            = "tag"
+	FieldBody           = "body"
+	FieldRequestHeader  = "req.header"
+	FieldResponseHeader = "resp.header"
+	FieldRequestJSON    = "req.json"
+	FieldResponseJSON   = "resp.json"
+)
+
+// Field is a record field. Header fields are keyed by header name and JSON
+// fields by a JSON path into the body.
+type Field struct {
+	Name string
+	Key  string
+
+	// Path is the parsed Key of JSON fields.
+	Path []PathSegment
+}
+
+// String formats the field, e.g. req.header["x-tenant"].
+func (f Field) String() string {
+	if f.Key == "" {
+		return f.Name
+	}
+	return f.Name + "[" + strconv.Quote(f.Key) + "]"
+}
+
+// Op is a comparison operator.
+type Op string
+
+// Comparison operators. OpMatch matches wildcard patterns, where * matches
+// any characters and ? a single character. OpContains matches substrings
+// case-insensitively.
+const (
+	OpEq       Op = "=="
+	OpNe       Op = "!="
+	OpMatch    Op = "~"
+	OpNotMatch Op = "!~"
+	OpGt       Op = ">"
+	OpGe       Op = ">="
+	OpLt       Op = "<"
+	OpLe       Op = "<="
+	OpIn       Op = "in"
+	OpContains Op = "contains"
+	OpExists   Op = "exists"
+)
+
+// ValueKind is the type of a literal.
+type ValueKind int
+
+// Literal types.
+const (
+	KindString ValueKind = iota
+	KindNumber
+	KindDuration
+)
+
+// Value is a literal in an expression.
+type Value struct {
+	Kind ValueKind
+	// Str is the content of strings, or the literal as written
+	Str string
+	// Num is the value of numbers, in milliseconds for durations
+	Num float64
+}
+
+// String formats the value as a literal.
+func (v Value) String() string {
+	if v.Kind == KindString {
+		return strconv.Quote(v.Str)
+	}
+	return v.Str
+}
+
+func (e *And) String() string { return "(" + e.Left.String() + " && " + e.Right.String() + ")" }
+func (e *Or) String() string  { return "(" + e.Left.String() + " || " + e.Right.String() + ")" }
+func (e *Not) String() string { return "!" + e.X.String() }
+
+func (c *Compare) String() string {
+	switch c.Op {
+	case OpExists:
+		return c.Field.String()
+	case OpIn:
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = v.String()
+		}
+		return c.Field.String() + " in [" + strings.Join(values, ", ") + "]"
+	}
+	return c.Field.String() + " " + string(c.Op) + " " + c.Value().String()
+}
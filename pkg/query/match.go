@@ -0,0 +1,218 @@
+package query
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+func (e *And) match(rec *capture.Record) bool { return e.Left.match(rec) && e.Right.match(rec) }
+func (e *Or) match(rec *capture.Record) bool  { return e.Left.match(rec) || e.Right.match(rec) }
+func (e *Not) match(rec *capture.Record) bool { return !e.X.match(rec) }
+
+// compile compiles the wildcard patterns of ~ and !~ comparisons.
+func (c *Compare) compile() error {
+	c.patterns = make([]*regexp.Regexp, len(c.Values))
+	for i, v := range c.Values {
+		re, err := capture.CompileWildcard(v.Str)
+		if err != nil {
+			return err
+		}
+		c.patterns[i] = re
+	}
+	return nil
+}
+
+func (c *Compare) match(rec *capture.Record) bool {
+	switch c.Field.Name {
+	case FieldStatus:
+		return c.matchNumber(float64(rec.Response.Status))
+	case FieldDuration:
+		return c.matchNumber(rec.DurationMs)
+	case FieldTag:
+		tags := recordTags(rec)
+		has := func(v Value) bool { return slices.Contains(tags, v.Str) }
+		if c.Op == OpNe {
+			return !has(c.Value())
+		}
+		return slices.ContainsFunc(c.Values, has)
+	case FieldBody:
+		return bodyContains(rec.Request.Body, c.Value().Str) || bodyContains(rec.Response.Body, c.Value().Str)
+	case FieldRequestHeader, FieldResponseHeader:
+		headers := rec.Request.Headers
+		if c.Field.Name == FieldResponseHeader {
+			headers = rec.Response.Headers
+		}
+		return c.matchValues(headers.Values(c.Field.Key))
+	case FieldRequestJSON, FieldResponseJSON:
+		body := rec.Request.Body
+		if c.Field.Name == FieldResponseJSON {
+			body = rec.Response.Body
+		}
+		v, ok := lookupPath(body, c.Field.Path)
+		if ok && v == nil {
+			// A JSON null is present but equals no value
+			return c.Op == OpExists || c.Op == OpNe
+		}
+		return c.matchString(jsonText(v), ok)
+	}
+	return c.matchString(recordString(rec, c.Field.Name), true)
+}
+
+// matchValues compares a field that may have several values, such as a
+// repeated header: it matches when any value does, and negations match when
+// no value matches their positive form.
+func (c *Compare) matchValues(values []string) bool {
+	if len(values) == 0 {
+		return c.matchString("", false)
+	}
+	negated := c.Op == OpNe || c.Op == OpNotMatch
+	for _, v := range values {
+		if c.matchString(v, true) != negated {
+			return !negated
+		}
+	}
+	return negated
+}
+
+// matchString compares a string field; present reports whether a keyed
+// field exists.
+func (c *Compare) matchString(s string, present bool) bool {
+	switch c.Op {
+	case OpExists:
+		return present
+	case OpNe:
+		return !present || s != c.Value().Str
+	case OpNotMatch:
+		return !present || !c.matchPattern(s)
+	}
+	if !present {
+		return false
+	}
+
+	switch c.Op {
+	case OpEq:
+		return s == c.Value().Str
+	case OpMatch:
+		return c.matchPattern(s)
+	case OpContains:
+		return strings.Contains(strings.ToLower(s), strings.ToLower(c.Value().Str))
+	case OpIn:
+		return slices.ContainsFunc(c.Values, func(v Value) bool { return v.Str == s })
+	}
+	return false
+}
+
+// matchPattern reports whether s matches the wildcard pattern.
+func (c *Compare) matchPattern(s string) bool {
+	if c.patterns != nil {
+		return slices.ContainsFunc(c.patterns, func(re *regexp.Regexp) bool { return re.MatchString(s) })
+	}
+
+	// Comparisons built without Parse
+	return slices.ContainsFunc(c.Values, func(v Value) bool {
+		re, err := capture.CompileWildcard(v.Str)
+		return err == nil && re.MatchString(s)
+	})
+}
+
+// matchNumber compares a numeric field.
+func (c *Compare) matchNumber(n float64) bool {
+	v := c.Value().Num
+	switch c.Op {
+	case OpEq:
+		return n == v
+	case OpNe:
+		return n != v
+	case OpGt:
+		return n > v
+	case OpGe:
+		return n >= v
+	case OpLt:
+		return n < v
+	case OpLe:
+		return n <= v
+	case OpIn:
+		return slices.ContainsFunc(c.Values, func(v Value) bool { return v.Num == n })
+	}
+	return false
+}
+
+// recordString returns a string field of rec.
+func recordString(rec *capture.Record, name string) string {
+	switch name {
+	case FieldHost:
+		return rec.Request.Host
+	case FieldMethod:
+		return strings.ToUpper(rec.Request.Method)
+	case FieldPath:
+		return rec.Request.Path
+	case FieldURL:
+		return rec.Request.URL
+	case FieldScheme:
+		return rec.Request.Scheme
+	case FieldClientIP:
+		return rec.ClientIP
+	case FieldUser:
+		return rec.User
+	case FieldType:
+		if rec.Type == "" {
+			return string(capture.RecordTypeHTTP)
+		}
+		return string(rec.Type)
+	case FieldConnectionID:
+		if rec.WebSocket != nil {
+			return rec.WebSocket.ConnectionID
+		}
+	}
+	return ""
+}
+
+// recordTags returns the tags a record is stored with.
+func recordTags(rec *capture.Record) []string {
+	if rec.NetworkProfile == "" {
+		return nil
+	}
+	return []string{capture.NetworkProfileTag(rec.NetworkProfile)}
+}
+
+// bodyContains reports whether a decoded body contains text, ignoring case.
+// JSON bodies are searched in their encoded form.
+func bodyContains(body interface{}, text string) bool {
+	if body == nil {
+		return false
+	}
+	s, ok := body.(string)
+	if !ok {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return false
+		}
+		s = string(data)
+	}
+	s = strings.ToLower(s)
+	if strings.Contains(s, strings.ToLower(text)) {
+		return true
+	}
+
+	// Strings inside JSON bodies are escaped
+	escaped, _ := json.Marshal(text)
+	return !ok && strings.Contains(s, strings.ToLower(string(escaped[1:len(escaped)-1])))
+}
+
+// jsonText formats a JSON value the way stores compare it: strings without
+// quotes, other values in their JSON encoding.
+func jsonText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+func testRecord() *capture.Record {
+	return &capture.Record{
+		DurationMs: 750,
+		Request: capture.RequestRecord{
+			Method:  "POST",
+			URL:     "https://api.stripe.com/v1/charges",
+			Host:    "api.stripe.com",
+			Path:    "/v1/charges",
+			Scheme:  "https",
+			Headers: capture.Headers{{Name: "x-tenant", Value: "acme"}, {Name: "content-type", Value: "application/json"}},
+			Body: map[string]interface{}{
+				"amount": float64(1200),
+				"items":  []interface{}{map[string]interface{}{"sku": "tea"}},
+				"note":   `say "hi"`,
+				"paid":   true,
+				"refund": nil,
+			},
+		},
+		Response: capture.ResponseRecord{
+			Status:  402,
+			Headers: capture.Headers{{Name: "request-id", Value: "req_123"}},
+			Body:    "Card declined",
+		},
+		NetworkProfile: "3g",
+		User:           "alice",
+		ClientIP:       "10.0.0.7",
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rec := testRecord()
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`host ~ "*.stripe.com" && status >= 400 && req.header["x-tenant"] == "acme" && duration > 500ms`, true},
+		{`host ~ "*.example.com"`, false},
+		{`host !~ "*.example.com"`, true},
+		{`host ~ "api.stripe.co?"`, true},
+		{`method == post`, true},
+		{`method in [GET, PUT]`, false},
+		{`path contains "CHARGES"`, true},
+		{`url == "https://api.stripe.com/v1/charges"`, true},
+		{`scheme != https`, false},
+		{`status in [400, 402]`, true},
+		{`status < 400 || duration >= 1s`, false},
+		{`duration == 750`, true},
+		{`client_ip == 10.0.0.7 && user == alice && type == http`, true},
+		{`connection_id != ""`, false},
+		{`tag == "network:3g"`, true},
+		{`tag != "network:3g"`, false},
+		{`req.header["X-Tenant"]`, true},
+		{`req.header["authorization"]`, false},
+		{`req.header["authorization"] != "x"`, true},
+		{`resp.header["request-id"] contains "REQ"`, true},
+		{`resp.header["request-id"] ~ "req_*"`, true},
+		{`req.json["$.amount"] == 1200`, true},
+		{`req.json["items[0].sku"] == "tea"`, true},
+		{`req.json["paid"] == true`, true},
+		{`req.json["refund"]`, true},
+		{`req.json["refund"] == null`, false},
+		{`req.json["missing"]`, false},
+		{`resp.json["amount"]`, false},
+		{`body contains "declined"`, true},
+		{`body contains "tea"`, true},
+		{`body contains "say \"hi\""`, true},
+		{`body contains "approved"`, false},
+		{`not (status == 402)`, false},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := q.Match(rec); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	q, err := Parse(`status >= 500`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := q.Filter()
+
+	rec := testRecord()
+	if f.Match(rec) {
+		t.Error("expected a 402 response not to match")
+	}
+	rec.Response.Status = 503
+	if !f.Match(rec) {
+		t.Error("expected a 503 response to match")
+	}
+}
@@ -0,0 +1,443 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind identifies a lexical token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+// token is a lexical token and its offset in the input.
+type token struct {
+	kind tokenKind
+	text string // identifier, symbol, or literal as written
+	str  string // string contents
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.str)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// symbols lists operators and punctuation, longest first.
+var symbols = []string{"&&", "||", "==", "!=", "!~", ">=", "<=", "=", "~", ">", "<", "!", "(", ")", "[", "]", ","}
+
+// lexer splits an expression into tokens.
+type lexer struct {
+	src string
+	pos int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid query at offset %d: %s", pos, fmt.Sprintf(format, args...))
+}
+
+// next returns the next token.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '"':
+		return l.quoted(start)
+	case c == '\'':
+		return l.singleQuoted(start)
+	case c >= '0' && c <= '9':
+		l.scanWord()
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case c == '_' || c == '$' || isLetter(c):
+		l.scanWord()
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+
+	for _, sym := range symbols {
+		if strings.HasPrefix(l.src[l.pos:], sym) {
+			l.pos += len(sym)
+			return token{kind: tokSymbol, text: sym, pos: start}, nil
+		}
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+// scanWord advances over an identifier or a bare literal such as 500ms or
+// 10.0.0.7.
+func (l *lexer) scanWord() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '_' || c == '.' || c == '-' || c == '$' || c == ':' || isLetter(c) || (c >= '0' && c <= '9') {
+			l.pos++
+			continue
+		}
+		if r, size := utf8.DecodeRuneInString(l.src[l.pos:]); r == 'µ' {
+			l.pos += size
+			continue
+		}
+		return
+	}
+}
+
+// quoted scans a double-quoted string with Go escapes.
+func (l *lexer) quoted(start int) (token, error) {
+	for i := start + 1; i < len(l.src); i++ {
+		switch l.src[i] {
+		case '\\':
+			i++
+		case '"':
+			text := l.src[start : i+1]
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return token{}, l.errorf(start, "invalid string %s", text)
+			}
+			l.pos = i + 1
+			return token{kind: tokString, text: text, str: s, pos: start}, nil
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+// singleQuoted scans a single-quoted string, where only \' and \\ are
+// escapes.
+func (l *lexer) singleQuoted(start int) (token, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(l.src); i++ {
+		switch c := l.src[i]; c {
+		case '\\':
+			if i+1 < len(l.src) && (l.src[i+1] == '\'' || l.src[i+1] == '\\') {
+				i++
+				b.WriteByte(l.src[i])
+			} else {
+				b.WriteByte(c)
+			}
+		case '\'':
+			l.pos = i + 1
+			return token{kind: tokString, text: l.src[start:l.pos], str: b.String(), pos: start}, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parser builds an expression tree by recursive descent.
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.lex.errorf(p.tok.pos, format, args...)
+}
+
+// is reports whether the current token is the symbol or keyword s.
+func (p *parser) is(s ...string) bool {
+	switch p.tok.kind {
+	case tokSymbol:
+		return slices.Contains(s, p.tok.text)
+	case tokIdent:
+		return slices.Contains(s, strings.ToLower(p.tok.text))
+	}
+	return false
+}
+
+// expect consumes the symbol s.
+func (p *parser) expect(s string) error {
+	if !p.is(s) {
+		return p.errorf("expected %q, found %s", s, p.tok)
+	}
+	return p.next()
+}
+
+// parseOr parses a disjunction.
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("||", "or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses a conjunction.
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("&&", "and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression or a comparison.
+func (p *parser) parseUnary() (Expr, error) {
+	switch {
+	case p.is("!", "not"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case p.is("("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return p.parseCompare()
+}
+
+// parseCompare parses a field followed by an operator and value, or a keyed
+// field on its own.
+func (p *parser) parseCompare() (Expr, error) {
+	if p.tok.kind != tokIdent {
+		return nil, p.errorf("expected a field, found %s", p.tok)
+	}
+	spec, ok := fields[strings.ToLower(p.tok.text)]
+	if !ok {
+		return nil, p.errorf("unknown field %q", p.tok.text)
+	}
+	fieldTok := p.tok
+	field := Field{Name: spec.name}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	keyed := spec.kind == kindHeader || spec.kind == kindJSON
+	if p.is("[") {
+		if !keyed {
+			return nil, p.errorf("field %s takes no key", field.Name)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokString && p.tok.kind != tokIdent {
+			return nil, p.errorf("expected a key, found %s", p.tok)
+		}
+		field.Key = p.tok.str
+		if p.tok.kind == tokIdent {
+			field.Key = p.tok.text
+		}
+		keyPos := p.tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+
+		if spec.kind == kindHeader {
+			field.Key = strings.ToLower(field.Key)
+		} else {
+			path, err := ParseJSONPath(field.Key)
+			if err != nil {
+				return nil, p.lex.errorf(keyPos, "%v", err)
+			}
+			field.Path = path
+		}
+	} else if keyed {
+		return nil, p.lex.errorf(fieldTok.pos, "field %s needs a key, e.g. %s[\"name\"]", field.Name, field.Name)
+	}
+
+	cmp := &Compare{Field: field}
+	opPos := p.tok.pos
+	switch {
+	case p.is("==", "="):
+		cmp.Op = OpEq
+	case p.is("!=", "~", "!~", ">", ">=", "<", "<=", "in", "contains"):
+		cmp.Op = Op(strings.ToLower(p.tok.text))
+	case keyed && (p.tok.kind == tokEOF || p.is(")", "&&", "||", "and", "or")):
+		cmp.Op = OpExists
+	default:
+		return nil, p.errorf("expected an operator after %s, found %s", field, p.tok)
+	}
+	if !slices.Contains(kindOps[spec.kind], cmp.Op) {
+		if cmp.Op == OpExists {
+			return nil, p.errorf("expected an operator after %s, found %s", field, p.tok)
+		}
+		return nil, p.lex.errorf(opPos, "operator %s is not supported for %s", cmp.Op, field.Name)
+	}
+	if cmp.Op == OpExists {
+		return cmp, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if cmp.Op == OpIn {
+		values, err := p.parseList(spec.kind)
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = values
+	} else {
+		v, err := p.parseValue(spec.kind)
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = []Value{v}
+	}
+
+	if field.Name == FieldMethod {
+		for i := range cmp.Values {
+			cmp.Values[i].Str = strings.ToUpper(cmp.Values[i].Str)
+		}
+	}
+	if cmp.Op == OpMatch || cmp.Op == OpNotMatch {
+		if err := cmp.compile(); err != nil {
+			return nil, p.lex.errorf(opPos, "invalid pattern: %v", err)
+		}
+	}
+	return cmp, nil
+}
+
+// parseList parses a bracketed or parenthesized list of values.
+func (p *parser) parseList(kind fieldKind) ([]Value, error) {
+	closing := "]"
+	switch {
+	case p.is("("):
+		closing = ")"
+	case !p.is("["):
+		return nil, p.errorf("expected a list, found %s", p.tok)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var values []Value
+	for {
+		v, err := p.parseValue(kind)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if !p.is(",") {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(closing); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseValue parses a literal for a field of kind.
+func (p *parser) parseValue(kind fieldKind) (Value, error) {
+	tok := p.tok
+	var v Value
+	switch tok.kind {
+	case tokString:
+		v = Value{Kind: KindString, Str: tok.str}
+	case tokIdent:
+		v = Value{Kind: KindString, Str: tok.text}
+	case tokNumber:
+		if n, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			v = Value{Kind: KindNumber, Str: tok.text, Num: n}
+		} else if d, err := time.ParseDuration(tok.text); err == nil {
+			v = Value{Kind: KindDuration, Str: tok.text, Num: float64(d.Microseconds()) / 1000}
+		} else {
+			// Bare words such as IP addresses
+			v = Value{Kind: KindString, Str: tok.text}
+		}
+	default:
+		return Value{}, p.errorf("expected a value, found %s", tok)
+	}
+
+	switch kind {
+	case kindNumber:
+		if v.Kind != KindNumber {
+			return Value{}, p.errorf("expected a number, found %s", tok)
+		}
+	case kindDuration:
+		// Plain numbers are milliseconds
+		if v.Kind == KindString {
+			return Value{}, p.errorf("expected a duration such as 500ms, found %s", tok)
+		}
+	default:
+		if v.Kind != KindString {
+			v.Kind = KindString
+		}
+	}
+
+	if err := p.next(); err != nil {
+		return Value{}, err
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is one step of a JSON path: an object key, or an array index
+// when Key is empty.
+type PathSegment struct {
+	Key   string
+	Index int
+}
+
+// ParseJSONPath parses a dotted JSON path with optional array indexes, such
+// as $.order.id or items[0].sku. The leading $ is optional.
+func ParseJSONPath(path string) ([]PathSegment, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+
+	var segments []PathSegment
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: unclosed index", path)
+			}
+			index, err := strconv.Atoi(p[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: bad index %q", path, p[1:end])
+			}
+			segments = append(segments, PathSegment{Index: index})
+			p = p[end+1:]
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			key := p[:end]
+			if strings.ContainsAny(key, `"]`) {
+				return nil, fmt.Errorf("invalid JSON path %q: bad key %q", path, key)
+			}
+			segments = append(segments, PathSegment{Key: key})
+			p = p[end:]
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid JSON path %q: no fields", path)
+	}
+	return segments, nil
+}
+
+// lookupPath returns the value at segments in a decoded JSON document.
+func lookupPath(doc interface{}, segments []PathSegment) (interface{}, bool) {
+	node := doc
+	for _, seg := range segments {
+		if seg.Key != "" {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if node, ok = obj[seg.Key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		arr, ok := node.([]interface{})
+		if !ok || seg.Index >= len(arr) {
+			return nil, false
+		}
+		node = arr[seg.Index]
+	}
+	return node, true
+}
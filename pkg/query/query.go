@@ -0,0 +1,131 @@
+// Package query implements a small expression language for filtering
+// traffic, shared by live capture and stored traffic queries.
+//
+// An expression compares record fields with literals and combines the
+// comparisons with &&, || and !:
+//
+//	host ~ "*.stripe.com" && status >= 400 && req.header["x-tenant"] == "acme" && duration > 500ms
+//
+// Fields are host, method, path, url, scheme, status, duration, client_ip,
+// user, type, connection_id, tag, body, req.header["name"],
+// resp.header["name"], req.json["path"] and resp.json["path"], where the
+// JSON fields take a path into the body such as "$.order.id". A header or
+// JSON field on its own tests that it is present.
+//
+// Operators are == (or =), !=, ~ and !~ (wildcard patterns, where * matches
+// any characters and ? a single character), >, >=, <, <=, in [...] and
+// contains (a case-insensitive substring). The keywords and, or and not may
+// be used in place of &&, || and !.
+//
+// A parsed Query matches capture records directly (Match) and is compiled by
+// the traffic stores in package backend to database predicates.
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Query is a parsed filter expression.
+type Query struct {
+	// Expr is the root of the expression.
+	Expr Expr
+
+	src string
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (*Query, error) {
+	p := &parser{lex: newLexer(s)}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, fmt.Errorf("invalid query: empty expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Query{Expr: expr, src: strings.TrimSpace(s)}, nil
+}
+
+// String returns the expression as it was parsed.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Match reports whether rec matches the expression.
+func (q *Query) Match(rec *capture.Record) bool {
+	return q.Expr.match(rec)
+}
+
+// Filter returns a capture filter that keeps only the records matching the
+// expression.
+func (q *Query) Filter() *capture.Filter {
+	f := capture.NewFilter()
+	f.Predicate = q.Match
+	return f
+}
+
+// fieldKind groups fields by the values and operators they accept.
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindDuration
+	kindTag
+	kindText
+	kindHeader
+	kindJSON
+)
+
+// fields maps field names, including aliases, to their canonical name and
+// kind.
+var fields = map[string]struct {
+	name string
+	kind fieldKind
+}{
+	"host":            {FieldHost, kindString},
+	"method":          {FieldMethod, kindString},
+	"path":            {FieldPath, kindString},
+	"url":             {FieldURL, kindString},
+	"scheme":          {FieldScheme, kindString},
+	"status":          {FieldStatus, kindNumber},
+	"status_code":     {FieldStatus, kindNumber},
+	"duration":        {FieldDuration, kindDuration},
+	"client_ip":       {FieldClientIP, kindString},
+	"ip":              {FieldClientIP, kindString},
+	"user":            {FieldUser, kindString},
+	"type":            {FieldType, kindString},
+	"connection_id":   {FieldConnectionID, kindString},
+	"tag":             {FieldTag, kindTag},
+	"tags":            {FieldTag, kindTag},
+	"body":            {FieldBody, kindText},
+	"req.header":      {FieldRequestHeader, kindHeader},
+	"request.header":  {FieldRequestHeader, kindHeader},
+	"resp.header":     {FieldResponseHeader, kindHeader},
+	"response.header": {FieldResponseHeader, kindHeader},
+	"req.json":        {FieldRequestJSON, kindJSON},
+	"request.json":    {FieldRequestJSON, kindJSON},
+	"resp.json":       {FieldResponseJSON, kindJSON},
+	"response.json":   {FieldResponseJSON, kindJSON},
+}
+
+// kindOps lists the operators each kind of field accepts.
+var kindOps = map[fieldKind][]Op{
+	kindString:   {OpEq, OpNe, OpMatch, OpNotMatch, OpContains, OpIn},
+	kindNumber:   {OpEq, OpNe, OpGt, OpGe, OpLt, OpLe, OpIn},
+	kindDuration: {OpEq, OpNe, OpGt, OpGe, OpLt, OpLe},
+	kindTag:      {OpEq, OpNe, OpIn},
+	kindText:     {OpContains},
+	kindHeader:   {OpExists, OpEq, OpNe, OpMatch, OpNotMatch, OpContains},
+	kindJSON:     {OpExists, OpEq, OpNe},
+}
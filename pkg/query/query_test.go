@@ -0,0 +1,134 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			input: `host ~ "*.stripe.com" && status >= 400 && req.header["X-Tenant"] == "acme" && duration > 500ms`,
+			want:  `(((host ~ "*.stripe.com" && status >= 400) && req.header["x-tenant"] == "acme") && duration > 500ms)`,
+		},
+		{
+			input: `method = get or not (path contains '/v1/' and status in [200, 201])`,
+			want:  `(method == "GET" || !(path contains "/v1/" && status in [200, 201]))`,
+		},
+		{
+			input: `resp.json["$.items[0].sku"] && client_ip == 10.0.0.7`,
+			want:  `(resp.json["$.items[0].sku"] && client_ip == "10.0.0.7")`,
+		},
+		{
+			input: `tag in (network:3g, "network:edge") || duration <= 250`,
+			want:  `(tag in ["network:3g", "network:edge"] || duration <= 250)`,
+		},
+		{
+			input: `host == a || host == b && host == c`,
+			want:  `(host == "a" || (host == "b" && host == "c"))`,
+		},
+		{
+			input: `!!body contains "ORD-1"`,
+			want:  `!!body contains "ORD-1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got := q.Expr.String(); got != tt.want {
+			t.Errorf("Parse(%q)\n got %s\nwant %s", tt.input, got, tt.want)
+		}
+		if q.String() != tt.input {
+			t.Errorf("String() = %q, want the input", q.String())
+		}
+
+		// The canonical form parses to the same expression
+		again, err := Parse(tt.want)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.want, err)
+		} else if got := again.Expr.String(); got != tt.want {
+			t.Errorf("reparsed %q as %s", tt.want, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "empty expression"},
+		{"hostname == x", `unknown field "hostname"`},
+		{"host", "expected an operator"},
+		{"status > fast", "expected a number"},
+		{"duration > 5xs", "expected a duration"},
+		{"status ~ 4*", "operator ~ is not supported for status"},
+		{"body == x", "operator == is not supported for body"},
+		{`req.header == "x"`, "needs a key"},
+		{`host["x"] == "y"`, "takes no key"},
+		{`req.json["items[x]"]`, "bad index"},
+		{`host == "unterminated`, "unterminated string"},
+		{"(host == a", `expected ")"`},
+		{"host == a b", `unexpected "b"`},
+		{"host == a &", "unexpected character"},
+		{"status in 200", "expected a list"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil {
+			t.Errorf("Parse(%q): expected error", tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q): error %q does not contain %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []PathSegment
+		wantErr bool
+	}{
+		{path: "$.order.id", want: []PathSegment{{Key: "order"}, {Key: "id"}}},
+		{path: "items[2].sku", want: []PathSegment{{Key: "items"}, {Index: 2}, {Key: "sku"}}},
+		{path: "$[0]", want: []PathSegment{{Index: 0}}},
+		{path: "$", wantErr: true},
+		{path: "items[", wantErr: true},
+		{path: "items[-1]", wantErr: true},
+		{path: `a"b`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseJSONPath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want, got)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package redact
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a JSONPath.
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+	// recursive matches at any depth below the current node ($..key)
+	recursive bool
+}
+
+// jsonPath is a parsed JSONPath.
+type jsonPath struct {
+	segments []segment
+}
+
+// parseJSONPath parses a JSONPath subset: $ for the root, .key or ['key']
+// for object members, [n] for array elements, * or [*] for every member or
+// element, and ..key for members at any depth.
+func parseJSONPath(path string) (*jsonPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid json path %q: must start with $", path)
+	}
+	jp := &jsonPath{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			recursive := strings.HasPrefix(rest, ".")
+			if recursive {
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("invalid json path %q: empty key", path)
+			}
+			jp.segments = append(jp.segments, segment{key: key, wildcard: key == "*", recursive: recursive})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed [", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				jp.segments = append(jp.segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				jp.segments = append(jp.segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid json path %q: bad index %q", path, inner)
+				}
+				jp.segments = append(jp.segments, segment{index: n, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid json path %q: unexpected %q", path, rest[0])
+		}
+	}
+	if len(jp.segments) == 0 {
+		return nil, fmt.Errorf("invalid json path %q: cannot redact the root", path)
+	}
+	return jp, nil
+}
+
+// redact replaces the values at the path in doc with mask(value), and
+// returns the result. Object keys match case-insensitively.
+func (jp *jsonPath) redact(doc interface{}, mask func(interface{}) interface{}) interface{} {
+	return redactSegments(doc, jp.segments, mask)
+}
+
+func redactSegments(node interface{}, segs []segment, mask func(interface{}) interface{}) interface{} {
+	if len(segs) == 0 {
+		return mask(node)
+	}
+	seg := segs[0]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, child := range n {
+			if !seg.isIndex && (seg.wildcard || strings.EqualFold(k, seg.key)) {
+				child = redactSegments(child, segs[1:], mask)
+				n[k] = child
+			}
+			if seg.recursive {
+				n[k] = redactSegments(child, segs, mask)
+			}
+		}
+	case []interface{}:
+		for i, child := range n {
+			if seg.wildcard && !seg.recursive || seg.isIndex && seg.index == i {
+				child = redactSegments(child, segs[1:], mask)
+				n[i] = child
+			}
+			if seg.recursive {
+				n[i] = redactSegments(child, segs, mask)
+			}
+		}
+	}
+	return node
+}
@@ -0,0 +1,113 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in pattern names.
+const (
+	// PatternJWT matches JSON Web Tokens
+	PatternJWT = "jwt"
+	// PatternAWSAccessKey matches AWS access key IDs
+	PatternAWSAccessKey = "aws-access-key"
+	// PatternCreditCard matches payment card numbers that pass the Luhn check
+	PatternCreditCard = "credit-card"
+)
+
+// pattern is a compiled redaction pattern.
+type pattern struct {
+	re *regexp.Regexp
+	// valid filters matches, when set
+	valid func(string) bool
+}
+
+// builtinPatterns are the patterns available by name.
+var builtinPatterns = map[string]*pattern{
+	// Header and payload are base64url-encoded JSON objects, so both start
+	// with eyJ ({")
+	PatternJWT:          {re: regexp.MustCompile(`eyJ[A-Za-z0-9_-]{4,}\.eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`)},
+	PatternAWSAccessKey: {re: regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`)},
+	PatternCreditCard: {
+		re:    regexp.MustCompile(`\b[2-6](?:[ -]?[0-9]){12,18}\b`),
+		valid: isCardNumber,
+	},
+}
+
+// compilePattern returns the built-in pattern named s, or compiles s as a
+// regular expression.
+func compilePattern(s string) (*pattern, error) {
+	if p, ok := builtinPatterns[s]; ok {
+		return p, nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction pattern %q: %w", s, err)
+	}
+	return &pattern{re: re}, nil
+}
+
+// replace returns s with each match (or each match's first group) masked.
+func (p *pattern) replace(s string, mask func(string) string) string {
+	matches := p.re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 {
+			// Redact only the first group, when it matched
+			if m[2] < 0 {
+				continue
+			}
+			start, end = m[2], m[3]
+		}
+		value := s[start:end]
+		if value == "" || p.valid != nil && !p.valid(value) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(mask(value))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// isCardNumber reports whether s, ignoring spaces and dashes, is 13 to 19
+// digits that pass the Luhn check.
+func isCardNumber(s string) bool {
+	var digits []int
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = append(digits, int(c-'0'))
+		case c == ' ' || c == '-':
+		default:
+			return false
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,363 @@
+// Package redact removes secrets from captured traffic.
+//
+// Rules select values by header name, query parameter, form field or JSON
+// path, or match them anywhere with regular expressions (including built-in
+// patterns for JWTs, AWS access keys and credit card numbers). Each redacted
+// value is replaced with [REDACTED], or with [REDACTED:<hash>] when a hash
+// key is configured: the hash is an HMAC of the value, so equal values can be
+// correlated across records without being revealed.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Placeholder replaces redacted values when no hash key is configured.
+const Placeholder = "[REDACTED]"
+
+// hashPrefix starts the keyed-hash form of redacted values.
+const hashPrefix = "[REDACTED:"
+
+// hashLen is the number of HMAC bytes kept in redacted values.
+const hashLen = 6
+
+// Config holds redaction rules. Names are matched case-insensitively and
+// support * wildcards.
+type Config struct {
+	// Headers are request and response headers whose values are redacted
+	Headers []string `yaml:"headers,omitempty"`
+	// Query are query parameters whose values are redacted
+	Query []string `yaml:"query,omitempty"`
+	// JSON are JSONPaths of values to redact in JSON bodies and messages,
+	// e.g. $.user.password, $.cards[*].number or $..token (at any depth)
+	JSON []string `yaml:"json,omitempty"`
+	// Form are fields whose values are redacted in URL-encoded form bodies
+	Form []string `yaml:"form,omitempty"`
+	// Patterns are regular expressions redacted wherever they match in
+	// header values, URLs and bodies, or the names of built-in patterns
+	// (jwt, aws-access-key, credit-card). When a pattern has groups, only
+	// the first group is redacted.
+	Patterns []string `yaml:"patterns,omitempty"`
+	// HashKey, when set, replaces values with a keyed hash instead of a
+	// plain placeholder
+	HashKey string `yaml:"hashKey,omitempty"`
+}
+
+// defaultNames are the secret-bearing names redacted by default in query
+// strings, forms and JSON bodies.
+var defaultNames = []string{
+	"password",
+	"secret",
+	"client_secret",
+	"token",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"api_key",
+	"apikey",
+}
+
+// DefaultConfig returns the default redaction rules.
+func DefaultConfig() *Config {
+	cfg := &Config{
+		Headers: []string{
+			"authorization",
+			"cookie",
+			"set-cookie",
+			"x-api-key",
+			"x-auth-token",
+			"proxy-authorization",
+		},
+		Query:    append([]string(nil), defaultNames...),
+		Form:     append([]string(nil), defaultNames...),
+		Patterns: []string{PatternJWT, PatternAWSAccessKey, PatternCreditCard},
+	}
+	for _, name := range defaultNames {
+		cfg.JSON = append(cfg.JSON, "$.."+name)
+	}
+	return cfg
+}
+
+// Redactor applies redaction rules. A nil Redactor redacts nothing.
+type Redactor struct {
+	headers  []string
+	query    []string
+	form     []string
+	json     []*jsonPath
+	patterns []*pattern
+	key      []byte
+}
+
+// New compiles redaction rules.
+func New(cfg *Config) (*Redactor, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	r := &Redactor{
+		headers: lowerNames(cfg.Headers),
+		query:   lowerNames(cfg.Query),
+		form:    lowerNames(cfg.Form),
+	}
+	if cfg.HashKey != "" {
+		r.key = []byte(cfg.HashKey)
+	}
+
+	for _, p := range cfg.JSON {
+		jp, err := parseJSONPath(p)
+		if err != nil {
+			return nil, err
+		}
+		r.json = append(r.json, jp)
+	}
+	for _, p := range cfg.Patterns {
+		compiled, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, compiled)
+	}
+	return r, nil
+}
+
+// Default returns a Redactor with the default rules.
+func Default() *Redactor {
+	r, err := New(DefaultConfig())
+	if err != nil {
+		panic(fmt.Sprintf("redact: invalid default rules: %v", err))
+	}
+	return r
+}
+
+func lowerNames(names []string) []string {
+	lower := make([]string, len(names))
+	for i, n := range names {
+		lower[i] = strings.ToLower(strings.TrimSpace(n))
+	}
+	return lower
+}
+
+// matchName reports whether name matches one of patterns (lowercased).
+func matchName(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		if p == name {
+			return true
+		}
+		if strings.Contains(p, "*") {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsRedacted reports whether s is a redacted value.
+func IsRedacted(s string) bool {
+	return s == Placeholder || strings.HasPrefix(s, hashPrefix) && strings.HasSuffix(s, "]")
+}
+
+// Mask returns the redacted form of value: the placeholder, or its keyed
+// hash. Values that are already redacted are returned as they are.
+func (r *Redactor) Mask(value string) string {
+	if IsRedacted(value) {
+		return value
+	}
+	if r.key == nil {
+		return Placeholder
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil)[:hashLen]) + "]"
+}
+
+// Header returns the value of header name with redaction applied. Cookie
+// and Set-Cookie headers keep their cookie names and attributes: only the
+// cookie values are masked.
+func (r *Redactor) Header(name, value string) string {
+	if r == nil {
+		return value
+	}
+	if !matchName(r.headers, name) {
+		return r.Text(value)
+	}
+	switch strings.ToLower(name) {
+	case "cookie":
+		return r.cookieValues(value, false)
+	case "set-cookie":
+		return r.cookieValues(value, true)
+	}
+	return r.Mask(value)
+}
+
+// cookieValues masks the values of the name=value pairs in a Cookie header,
+// or only the first pair of a Set-Cookie header (the rest are attributes).
+func (r *Redactor) cookieValues(line string, setCookie bool) string {
+	parts := strings.Split(line, ";")
+	for i, part := range parts {
+		if setCookie && i > 0 {
+			break
+		}
+		name, value, ok := strings.Cut(part, "=")
+		switch {
+		case !ok && strings.TrimSpace(part) != "":
+			trimmed := strings.TrimLeft(part, " ")
+			parts[i] = part[:len(part)-len(trimmed)] + r.Mask(trimmed)
+		case ok && value != "":
+			parts[i] = name + "=" + r.Mask(value)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// QueryParam returns the decoded value of query parameter name with
+// redaction applied.
+func (r *Redactor) QueryParam(name, value string) string {
+	if r == nil {
+		return value
+	}
+	if matchName(r.query, name) {
+		return r.Mask(value)
+	}
+	return r.Text(value)
+}
+
+// URL returns rawURL with redacted query parameters and patterns.
+func (r *Redactor) URL(rawURL string) string {
+	if r == nil {
+		return rawURL
+	}
+	base, rest, hasQuery := strings.Cut(rawURL, "?")
+	if hasQuery {
+		query, fragment, hasFragment := strings.Cut(rest, "#")
+		rawURL = base + "?" + r.encodedPairs(query, r.query)
+		if hasFragment {
+			rawURL += "#" + fragment
+		}
+	}
+	return r.Text(rawURL)
+}
+
+// encodedPairs redacts the values of URL-encoded name=value pairs whose
+// names match names, keeping the rest of the encoding as it is.
+func (r *Redactor) encodedPairs(s string, names []string) string {
+	pairs := strings.Split(s, "&")
+	for i, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if !matchName(names, name) {
+			continue
+		}
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			decoded = value
+		}
+		pairs[i] = key + "=" + url.QueryEscape(r.Mask(decoded))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// Body returns a captured body with redaction applied. JSON bodies (decoded
+// to maps and slices) are redacted by JSON path, URL-encoded form bodies by
+// field, and text everywhere by pattern. Decoded bodies are copied rather
+// than modified.
+func (r *Redactor) Body(body interface{}, contentType string) interface{} {
+	if r == nil || body == nil {
+		return body
+	}
+	switch b := body.(type) {
+	case string:
+		if isFormContentType(contentType) {
+			b = r.encodedPairs(b, r.form)
+		}
+		return r.Text(b)
+	case map[string]interface{}, []interface{}:
+		body = copyJSON(b)
+	default:
+		// Raw bytes and other scalars
+		return body
+	}
+	for _, jp := range r.json {
+		body = jp.redact(body, r.maskJSON)
+	}
+	return r.textValues(body)
+}
+
+// maskJSON masks a JSON value of any type, hashing objects and numbers by
+// their encoding.
+func (r *Redactor) maskJSON(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		return r.Mask(s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Placeholder
+	}
+	return r.Mask(string(data))
+}
+
+// textValues applies patterns to every string in a decoded JSON document.
+func (r *Redactor) textValues(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.Text(v)
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = r.textValues(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.textValues(child)
+		}
+	}
+	return v
+}
+
+// copyJSON deep-copies the objects and arrays of a decoded JSON document.
+func copyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			m[k] = copyJSON(child)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, child := range v {
+			a[i] = copyJSON(child)
+		}
+		return a
+	}
+	return v
+}
+
+// Text returns s with every pattern match redacted.
+func (r *Redactor) Text(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.replace(s, r.Mask)
+	}
+	return s
+}
+
+// isFormContentType reports whether ct is a URL-encoded form.
+func isFormContentType(ct string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(ct)), "application/x-www-form-urlencoded")
+}
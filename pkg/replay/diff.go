@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Diff field names.
+const (
+	FieldStatus = "status"
+	FieldHeader = "header"
+	FieldBody   = "body"
+)
+
+// maxBodyDiffs caps the number of body differences reported per response.
+const maxBodyDiffs = 50
+
+// Diff describes one difference between the recorded and replayed response.
+type Diff struct {
+	// Field is status, header or body
+	Field string `json:"field"`
+	// Path is the header name or JSON path (e.g., $.items[0].name)
+	Path string `json:"path,omitempty"`
+	// Expected is the recorded value (nil when missing)
+	Expected interface{} `json:"expected"`
+	// Actual is the replayed value (nil when missing)
+	Actual interface{} `json:"actual"`
+}
+
+// String formats the difference for text reports.
+func (d Diff) String() string {
+	label := d.Field
+	if d.Path != "" {
+		label += " " + d.Path
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", label, formatValue(d.Expected), formatValue(d.Actual))
+}
+
+// formatValue renders a diff value compactly.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<missing>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return truncate(string(data))
+	}
+}
+
+// decodeJSON decodes a JSON body, returning nil for an empty body.
+func decodeJSON(body []byte) (interface{}, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// indexPattern matches array indexes in a JSON path.
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// ignoredPath reports whether path matches one of the ignore patterns.
+// A [*] in a pattern matches any array index.
+func ignoredPath(path string, ignore []string) bool {
+	if len(ignore) == 0 {
+		return false
+	}
+	wildcard := indexPattern.ReplaceAllString(path, "[*]")
+	for _, p := range ignore {
+		if p == path || p == wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// diffJSON compares two decoded JSON values recursively. Object keys are
+// compared regardless of order; arrays are compared element by element.
+func diffJSON(path string, expected, actual interface{}, ignore []string) []Diff {
+	var diffs []Diff
+	walkJSON(path, expected, actual, ignore, &diffs)
+	return diffs
+}
+
+func walkJSON(path string, expected, actual interface{}, ignore []string, diffs *[]Diff) {
+	if len(*diffs) >= maxBodyDiffs || ignoredPath(path, ignore) {
+		return
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(exp)+len(act))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		for k := range act {
+			if _, ok := exp[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkJSON(path+"."+k, exp[k], act[k], ignore, diffs)
+		}
+		return
+
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		n := max(len(exp), len(act))
+		for i := range n {
+			var e, a interface{}
+			if i < len(exp) {
+				e = exp[i]
+			}
+			if i < len(act) {
+				a = act[i]
+			}
+			walkJSON(fmt.Sprintf("%s[%d]", path, i), e, a, ignore, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*diffs = append(*diffs, Diff{Field: FieldBody, Path: path, Expected: expected, Actual: actual})
+	}
+}
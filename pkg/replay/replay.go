@@ -0,0 +1,470 @@
+// Package replay re-sends captured HTTP traffic and compares the new
+// responses against the recorded ones.
+//
+// Records can come from NDJSON or HAR captures (see capture.ReadRecordsFile)
+// or from a database TrafficQuerier. Each replayed response is diffed against
+// the recorded status, headers and body, producing a pass/fail Report that can
+// be used as a regression harness against another environment.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Config holds replay configuration.
+type Config struct {
+	// BaseURL overrides the scheme, host and path prefix of every request
+	// (e.g., https://staging.example.com). Empty replays against the original URL.
+	BaseURL string
+	// Concurrency is the number of requests in flight (default: 1)
+	Concurrency int
+	// Rate limits requests per second (0 = unlimited)
+	Rate float64
+	// OriginalTiming preserves the recorded gaps between request start times
+	OriginalTiming bool
+	// Headers are set on every request, e.g. credentials filtered out at capture time
+	Headers http.Header
+	// CompareHeaders enables response header comparison
+	CompareHeaders bool
+	// CompareBody enables response body comparison
+	CompareBody bool
+	// IgnoreHeaders are response headers excluded from comparison, in addition to DefaultIgnoreHeaders
+	IgnoreHeaders []string
+	// IgnoreBodyPaths are JSON paths excluded from body comparison (e.g., $.updatedAt, $.items[*].id)
+	IgnoreBodyPaths []string
+	// Timeout is the per-request timeout (default: 30s)
+	Timeout time.Duration
+	// Insecure skips TLS certificate verification (e.g., self-signed staging)
+	Insecure bool
+	// Client overrides the HTTP client (optional)
+	Client *http.Client
+}
+
+// DefaultIgnoreHeaders are response headers that legitimately differ between
+// runs and are never compared.
+var DefaultIgnoreHeaders = []string{
+	"date",
+	"age",
+	"expires",
+	"last-modified",
+	"etag",
+	"set-cookie",
+	"content-length",
+	"connection",
+	"keep-alive",
+	"transfer-encoding",
+	"server-timing",
+	"x-request-id",
+	"x-amzn-requestid",
+	"x-amzn-trace-id",
+	"cf-ray",
+	"report-to",
+	"nel",
+	"alt-svc",
+	"via",
+	"x-cache",
+}
+
+// hopHeaders are request headers that are not replayed as-is.
+var hopHeaders = map[string]bool{
+	"connection":          true,
+	"proxy-connection":    true,
+	"keep-alive":          true,
+	"transfer-encoding":   true,
+	"te":                  true,
+	"upgrade":             true,
+	"content-length":      true,
+	"accept-encoding":     true,
+	"proxy-authorization": true,
+}
+
+// decodedEncoding reports whether name is the Content-Encoding of a request
+// body that was recorded decoded, and so is replayed without it.
+func decodedEncoding(rec *capture.Record, name string) bool {
+	return rec.Request.ContentEncoding != "" && strings.EqualFold(name, "Content-Encoding")
+}
+
+// DefaultConfig returns default replay configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Concurrency:    1,
+		CompareHeaders: true,
+		CompareBody:    true,
+		Timeout:        30 * time.Second,
+	}
+}
+
+// Replayer re-sends captured requests and diffs the responses.
+type Replayer struct {
+	config        *Config
+	client        *http.Client
+	baseURL       *url.URL
+	ignoreHeaders map[string]bool
+	ignorePaths   []string
+}
+
+// New creates a new Replayer.
+func New(cfg *Config) (*Replayer, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	r := &Replayer{
+		config:        cfg,
+		ignoreHeaders: make(map[string]bool),
+		ignorePaths:   cfg.IgnoreBodyPaths,
+	}
+
+	if cfg.BaseURL != "" {
+		u, err := url.Parse(cfg.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid base URL: %s", cfg.BaseURL)
+		}
+		r.baseURL = u
+	}
+
+	for _, h := range DefaultIgnoreHeaders {
+		r.ignoreHeaders[h] = true
+	}
+	for _, h := range cfg.IgnoreHeaders {
+		r.ignoreHeaders[strings.ToLower(h)] = true
+	}
+
+	r.client = cfg.Client
+	if r.client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.Insecure {
+			transport.TLSClientConfig = &tls.Config{
+				InsecureSkipVerify: true, //nolint:gosec // Opt-in for replaying against self-signed environments
+				MinVersion:         tls.VersionTLS12,
+			}
+		}
+		r.client = &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			// Compare recorded redirects rather than following them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	return r, nil
+}
+
+// Replayable reports whether rec is an HTTP exchange that can be re-sent.
+// WebSocket upgrades and frames are skipped.
+func Replayable(rec *capture.Record) bool {
+	return rec != nil && rec.Request.Method != "" && rec.Request.URL != "" &&
+		(rec.Type == "" || rec.Type == capture.RecordTypeHTTP)
+}
+
+// Run replays recs and returns the report. Records that are not Replayable
+// are skipped. Run stops scheduling new requests when ctx is cancelled.
+func (r *Replayer) Run(ctx context.Context, recs []*capture.Record) *Report {
+	started := time.Now()
+
+	replayable := make([]*capture.Record, 0, len(recs))
+	for _, rec := range recs {
+		if Replayable(rec) {
+			replayable = append(replayable, rec)
+		}
+	}
+	if r.config.OriginalTiming {
+		sort.SliceStable(replayable, func(i, j int) bool {
+			return replayable[i].StartTime.Before(replayable[j].StartTime)
+		})
+	}
+
+	results := make([]*Result, len(replayable))
+	jobs := make(chan int)
+
+	workers := r.config.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.replayOne(ctx, i, replayable[i])
+			}
+		}()
+	}
+
+	r.schedule(ctx, replayable, jobs)
+	close(jobs)
+	wg.Wait()
+
+	report := &Report{Results: make([]*Result, 0, len(results))}
+	for _, res := range results {
+		if res == nil {
+			continue // not scheduled before cancellation
+		}
+		report.add(res)
+	}
+	report.DurationMs = float64(time.Since(started).Microseconds()) / 1000.0
+	return report
+}
+
+// schedule feeds record indexes to the workers, honouring rate and timing.
+func (r *Replayer) schedule(ctx context.Context, recs []*capture.Record, jobs chan<- int) {
+	start := time.Now()
+	var interval time.Duration
+	if r.config.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / r.config.Rate)
+	}
+	next := start
+
+	for i, rec := range recs {
+		at := next
+		if r.config.OriginalTiming && !recs[0].StartTime.IsZero() {
+			if offset := start.Add(rec.StartTime.Sub(recs[0].StartTime)); offset.After(at) {
+				at = offset
+			}
+		}
+		if !sleepUntil(ctx, at) {
+			return
+		}
+		next = time.Now().Add(interval)
+
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sleepUntil waits until t, returning false if ctx is cancelled first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// replayOne sends a single request and compares the response.
+func (r *Replayer) replayOne(ctx context.Context, index int, rec *capture.Record) *Result {
+	res := &Result{
+		Index:          index,
+		Method:         rec.Request.Method,
+		URL:            rec.Request.URL,
+		ExpectedStatus: rec.Response.Status,
+	}
+
+	req, err := r.BuildRequest(ctx, rec)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.URL = req.URL.String()
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		res.DurationMs = float64(time.Since(start).Microseconds()) / 1000.0
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	res.DurationMs = float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		res.Error = fmt.Sprintf("failed to read response body: %v", err)
+		return res
+	}
+
+	res.Status = resp.StatusCode
+	res.Diffs = r.Compare(rec, resp, body)
+	res.Passed = len(res.Diffs) == 0
+	return res
+}
+
+// BuildRequest converts a captured record into an outgoing request, applying
+// the base URL override and configured headers.
+func (r *Replayer) BuildRequest(ctx context.Context, rec *capture.Record) (*http.Request, error) {
+	u, err := url.Parse(rec.Request.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorded URL: %w", err)
+	}
+	if r.baseURL != nil {
+		u.Scheme = r.baseURL.Scheme
+		u.Host = r.baseURL.Host
+		if prefix := strings.TrimSuffix(r.baseURL.Path, "/"); prefix != "" {
+			u.Path = path.Join(prefix, u.Path)
+			u.RawPath = ""
+		}
+	}
+
+	if rec.Request.IsBinary || rec.Request.Body == capture.BinaryPlaceholder {
+		return nil, errors.New("binary request body was not captured")
+	}
+	if rec.Request.Truncated {
+		return nil, errors.New("request body was truncated when captured")
+	}
+
+	var body io.Reader
+	if data := capture.BodyBytes(rec.Request.Body); len(data) > 0 {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, rec.Request.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range rec.Request.Headers {
+		if hopHeaders[strings.ToLower(h.Name)] || decodedEncoding(rec, h.Name) {
+			continue
+		}
+		req.Header.Add(h.Name, h.Value)
+	}
+	if rec.Request.ContentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", rec.Request.ContentType)
+	}
+	for k, vs := range r.config.Headers {
+		req.Header.Del(k)
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	return req, nil
+}
+
+// Compare diffs a live response against the recorded one.
+func (r *Replayer) Compare(rec *capture.Record, resp *http.Response, body []byte) []Diff {
+	var diffs []Diff
+
+	if rec.Response.Status != resp.StatusCode {
+		diffs = append(diffs, Diff{
+			Field:    FieldStatus,
+			Expected: rec.Response.Status,
+			Actual:   resp.StatusCode,
+		})
+	}
+
+	if r.config.CompareHeaders {
+		diffs = append(diffs, r.compareHeaders(rec.Response.Headers, resp.Header)...)
+	}
+
+	if r.config.CompareBody {
+		diffs = append(diffs, r.compareBody(rec, body)...)
+	}
+
+	return diffs
+}
+
+// compareHeaders checks every recorded, non-ignored header against the
+// response. Repeated headers are compared as lists of values.
+func (r *Replayer) compareHeaders(expected capture.Headers, actual http.Header) []Diff {
+	names := make([]string, 0, len(expected))
+	seen := make(map[string]bool, len(expected))
+	for _, h := range expected {
+		key := strings.ToLower(h.Name)
+		if !r.ignoreHeaders[key] && !seen[key] {
+			seen[key] = true
+			names = append(names, h.Name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+
+	var diffs []Diff
+	for _, name := range names {
+		want := expected.Values(name)
+		values := actual.Values(name)
+		switch {
+		case len(values) == 0:
+			diffs = append(diffs, Diff{Field: FieldHeader, Path: name, Expected: headerDiffValue(want), Actual: nil})
+		case len(want) == 1 && values[0] != want[0]:
+			diffs = append(diffs, Diff{Field: FieldHeader, Path: name, Expected: want[0], Actual: values[0]})
+		case len(want) > 1 && !slices.Equal(values, want):
+			diffs = append(diffs, Diff{Field: FieldHeader, Path: name, Expected: want, Actual: values})
+		}
+	}
+	return diffs
+}
+
+// headerDiffValue reports a single header value as a string.
+func headerDiffValue(values []string) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
+
+// compareBody compares bodies structurally when the recorded body is JSON.
+func (r *Replayer) compareBody(rec *capture.Record, body []byte) []Diff {
+	expected := rec.Response.Body
+	if rec.Response.IsBinary || expected == capture.BinaryPlaceholder {
+		return nil
+	}
+	if expected == nil {
+		if len(body) == 0 {
+			return nil
+		}
+		return []Diff{{Field: FieldBody, Path: "$", Expected: nil, Actual: truncate(string(body))}}
+	}
+
+	// Only the start of truncated bodies is known
+	if rec.Response.Truncated {
+		if prefix := capture.BodyBytes(expected); !bytes.HasPrefix(body, prefix) {
+			return []Diff{{Field: FieldBody, Path: "$", Expected: truncate(string(prefix)), Actual: truncate(string(body))}}
+		}
+		return nil
+	}
+
+	if s, ok := expected.(string); ok {
+		if s != string(body) {
+			return []Diff{{Field: FieldBody, Path: "$", Expected: truncate(s), Actual: truncate(string(body))}}
+		}
+		return nil
+	}
+
+	actual, err := decodeJSON(body)
+	if err != nil {
+		return []Diff{{Field: FieldBody, Path: "$", Expected: "JSON", Actual: truncate(string(body))}}
+	}
+	return diffJSON("$", expected, actual, r.ignorePaths)
+}
+
+// truncate shortens long values in diff output.
+func truncate(s string) string {
+	const limit = 200
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit] + "..."
+}
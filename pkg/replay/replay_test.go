@@ -0,0 +1,221 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+func newTestRecord(method, url string, status int, body interface{}) *capture.Record {
+	return &capture.Record{
+		Request: capture.RequestRecord{
+			Method:  method,
+			URL:     url,
+			Headers: capture.Headers{{Name: "x-api-key", Value: "secret"}, {Name: "accept-encoding", Value: "gzip"}},
+		},
+		Response: capture.ResponseRecord{
+			Status:  status,
+			Headers: capture.Headers{{Name: "content-type", Value: "application/json"}, {Name: "date", Value: "Mon, 01 Jan 2024 00:00:00 GMT"}},
+			Body:    body,
+		},
+	}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/1":
+			_, _ = io.WriteString(w, `{"id":1,"name":"Alice","tags":["a","b"],"updatedAt":"now"}`)
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"body":  string(body),
+				"key":   r.Header.Get("X-Api-Key"),
+				"token": r.Header.Get("Authorization"),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"not found"}`)
+		}
+	}))
+}
+
+func TestReplayPassAndFail(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	recs := []*capture.Record{
+		newTestRecord("GET", "https://api.example.com/users/1", 200, map[string]interface{}{
+			"id": float64(1), "name": "Alice", "tags": []interface{}{"a", "b"}, "updatedAt": "then",
+		}),
+		newTestRecord("GET", "https://api.example.com/users/1", 200, map[string]interface{}{
+			"id": float64(1), "name": "Bob", "tags": []interface{}{"a"}, "updatedAt": "then",
+		}),
+		newTestRecord("GET", "https://api.example.com/missing", 200, nil),
+		{Type: capture.RecordTypeWebSocketFrame},
+	}
+
+	r, err := New(&Config{
+		BaseURL:         server.URL,
+		Concurrency:     2,
+		CompareHeaders:  true,
+		CompareBody:     true,
+		IgnoreBodyPaths: []string{"$.updatedAt"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := r.Run(context.Background(), recs)
+	if report.Total != 3 || report.Passed != 1 || report.Failed != 2 || report.OK() {
+		t.Fatalf("unexpected report totals: %+v", report)
+	}
+
+	if !report.Results[0].Passed {
+		t.Errorf("expected first request to pass, got diffs %v", report.Results[0].Diffs)
+	}
+
+	paths := map[string]bool{}
+	for _, d := range report.Results[1].Diffs {
+		paths[d.Path] = true
+	}
+	if !paths["$.name"] || !paths["$.tags[1]"] || len(paths) != 2 {
+		t.Errorf("unexpected body diffs: %v", report.Results[1].Diffs)
+	}
+
+	if d := report.Results[2].Diffs; len(d) == 0 || d[0].Field != FieldStatus || d[0].Actual != 404 {
+		t.Errorf("expected status diff, got %v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `body $.name: expected "Bob", got "Alice"`) {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	rec := newTestRecord("POST", "https://api.example.com/echo", 200, nil)
+	rec.Request.Body = map[string]interface{}{"hello": "world"}
+
+	r, err := New(&Config{
+		BaseURL: server.URL,
+		Headers: http.Header{"Authorization": {"Bearer replay"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := r.BuildRequest(context.Background(), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Accept-Encoding") != "" {
+		t.Error("accept-encoding should not be replayed")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var echo map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&echo); err != nil {
+		t.Fatal(err)
+	}
+	if echo["body"] != `{"hello":"world"}` || echo["key"] != "secret" || echo["token"] != "Bearer replay" {
+		t.Errorf("unexpected echoed request: %v", echo)
+	}
+
+	rec.Request.Body = capture.BinaryPlaceholder
+	if _, err := r.BuildRequest(context.Background(), rec); err == nil {
+		t.Error("expected error for a binary body that was not captured")
+	}
+}
+
+func TestCompareHeaders(t *testing.T) {
+	r, _ := New(&Config{CompareHeaders: true, IgnoreHeaders: []string{"X-Trace"}})
+
+	rec := newTestRecord("GET", "https://api.example.com/", 200, nil)
+	rec.Response.Headers = append(rec.Response.Headers,
+		capture.Header{Name: "x-trace", Value: "abc"},
+		capture.Header{Name: "cache-control", Value: "no-store"},
+	)
+
+	resp := &http.Response{StatusCode: 200, Header: http.Header{
+		"Content-Type": {"text/plain"},
+		"Date":         {"Tue, 02 Jan 2024 00:00:00 GMT"},
+	}}
+
+	diffs := r.Compare(rec, resp, nil)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 header diffs, got %v", diffs)
+	}
+	if diffs[0].Path != "cache-control" || diffs[0].Actual != nil {
+		t.Errorf("expected missing cache-control, got %v", diffs[0])
+	}
+	if diffs[1].Path != "content-type" || diffs[1].Actual != "text/plain" {
+		t.Errorf("expected content-type diff, got %v", diffs[1])
+	}
+}
+
+func TestDiffJSONIgnoreWildcard(t *testing.T) {
+	expected := map[string]interface{}{"items": []interface{}{
+		map[string]interface{}{"id": "1", "v": float64(1)},
+		map[string]interface{}{"id": "2", "v": float64(2)},
+	}}
+	actual := map[string]interface{}{"items": []interface{}{
+		map[string]interface{}{"id": "9", "v": float64(1)},
+		map[string]interface{}{"id": "8", "v": float64(3)},
+	}}
+
+	diffs := diffJSON("$", expected, actual, []string{"$.items[*].id"})
+	if len(diffs) != 1 || diffs[0].Path != "$.items[1].v" {
+		t.Errorf("unexpected diffs: %v", diffs)
+	}
+}
+
+func TestReplayRate(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer server.Close()
+
+	var recs []*capture.Record
+	for range 3 {
+		recs = append(recs, &capture.Record{
+			Request:  capture.RequestRecord{Method: "GET", URL: server.URL + "/"},
+			Response: capture.ResponseRecord{Status: 200},
+		})
+	}
+
+	r, _ := New(&Config{Concurrency: 3, Rate: 20})
+	start := time.Now()
+	report := r.Run(context.Background(), recs)
+
+	if !report.OK() || count.Load() != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	// Three requests at 20/s need at least two 50ms intervals
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("rate limit not applied: %v", elapsed)
+	}
+}
@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Result is the outcome of replaying one request.
+type Result struct {
+	// Index is the position of the request in the replay order
+	Index int `json:"index"`
+	// Method is the HTTP method
+	Method string `json:"method"`
+	// URL is the URL the request was sent to
+	URL string `json:"url"`
+	// ExpectedStatus is the recorded status code
+	ExpectedStatus int `json:"expectedStatus"`
+	// Status is the replayed status code (0 on error)
+	Status int `json:"status,omitempty"`
+	// DurationMs is the replay round-trip time
+	DurationMs float64 `json:"durationMs"`
+	// Passed is true when the response matched the recording
+	Passed bool `json:"passed"`
+	// Error is set when the request could not be replayed
+	Error string `json:"error,omitempty"`
+	// Diffs lists the differences from the recorded response
+	Diffs []Diff `json:"diffs,omitempty"`
+}
+
+// Report summarizes a replay run.
+type Report struct {
+	// Total is the number of requests replayed
+	Total int `json:"total"`
+	// Passed is the number of matching responses
+	Passed int `json:"passed"`
+	// Failed is the number of responses that differed
+	Failed int `json:"failed"`
+	// Errors is the number of requests that could not be replayed
+	Errors int `json:"errors"`
+	// DurationMs is the wall-clock duration of the run
+	DurationMs float64 `json:"durationMs"`
+	// Results holds per-request results in replay order
+	Results []*Result `json:"results"`
+}
+
+// add records a result in the report totals.
+func (r *Report) add(res *Result) {
+	r.Results = append(r.Results, res)
+	r.Total++
+	switch {
+	case res.Error != "":
+		r.Errors++
+	case res.Passed:
+		r.Passed++
+	default:
+		r.Failed++
+	}
+}
+
+// OK reports whether every request was replayed and matched.
+func (r *Report) OK() bool {
+	return r.Failed == 0 && r.Errors == 0
+}
+
+// WriteText writes a human-readable report.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, res := range r.Results {
+		var err error
+		switch {
+		case res.Error != "":
+			_, err = fmt.Fprintf(w, "ERROR %s %s: %s\n", res.Method, res.URL, res.Error)
+		case res.Passed:
+			_, err = fmt.Fprintf(w, "PASS  %s %s %d (%.0fms)\n", res.Method, res.URL, res.Status, res.DurationMs)
+		default:
+			_, err = fmt.Fprintf(w, "FAIL  %s %s %d -> %d (%.0fms)\n", res.Method, res.URL, res.ExpectedStatus, res.Status, res.DurationMs)
+			for _, d := range res.Diffs {
+				if err != nil {
+					break
+				}
+				_, err = fmt.Fprintf(w, "      %s\n", d)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d requests: %d passed, %d failed, %d errors (%.0fms)\n",
+		r.Total, r.Passed, r.Failed, r.Errors, r.DurationMs)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
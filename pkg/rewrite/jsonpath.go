@@ -0,0 +1,250 @@
+package rewrite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON patch operations.
+const (
+	// OpSet sets the value at the path, creating object keys as needed
+	OpSet = "set"
+	// OpRemove removes the value at the path
+	OpRemove = "remove"
+)
+
+// JSONPatch modifies a JSON body at a JSONPath location.
+//
+// Paths use a simple JSONPath subset: $ for the root, .key or ['key'] for
+// object members, [n] for array elements and [*] for every element, e.g.
+// $.items[*].price or $.user['display-name'].
+type JSONPatch struct {
+	// Op is set (default) or remove
+	Op string `yaml:"op,omitempty"`
+	// Path is the JSONPath of the value to modify
+	Path string `yaml:"path"`
+	// Value is the new value for set
+	Value interface{} `yaml:"value,omitempty"`
+}
+
+// segment is one step of a JSONPath.
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonPath is a parsed JSONPath.
+type jsonPath struct {
+	segments []segment
+}
+
+// compiledPatch is a JSON patch with its path parsed and value normalized.
+type compiledPatch struct {
+	path   *jsonPath
+	remove bool
+	value  interface{}
+}
+
+// parseJSONPath parses the supported JSONPath subset.
+func parseJSONPath(path string) (*jsonPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid json path %q: must start with $", path)
+	}
+	jp := &jsonPath{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("invalid json path %q: empty key", path)
+			}
+			if key == "*" {
+				jp.segments = append(jp.segments, segment{wildcard: true})
+			} else {
+				jp.segments = append(jp.segments, segment{key: key})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed [", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				jp.segments = append(jp.segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				jp.segments = append(jp.segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid json path %q: bad index %q", path, inner)
+				}
+				jp.segments = append(jp.segments, segment{index: n, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid json path %q: unexpected %q", path, rest[0])
+		}
+	}
+	if len(jp.segments) == 0 {
+		return nil, fmt.Errorf("invalid json path %q: cannot patch the root", path)
+	}
+	return jp, nil
+}
+
+// compilePatches validates patches and parses their paths.
+func compilePatches(patches []JSONPatch) ([]*compiledPatch, error) {
+	compiled := make([]*compiledPatch, len(patches))
+	for i, p := range patches {
+		switch p.Op {
+		case "", OpSet, OpRemove:
+		default:
+			return nil, fmt.Errorf("invalid json patch op %q", p.Op)
+		}
+		jp, err := parseJSONPath(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = &compiledPatch{path: jp, remove: p.Op == OpRemove, value: normalizeValue(p.Value)}
+	}
+	return compiled, nil
+}
+
+// applyPatches applies patches to a JSON document. It reports false if the
+// body is not JSON.
+func applyPatches(body []byte, patches []*compiledPatch) ([]byte, bool, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false, nil
+	}
+
+	for _, p := range patches {
+		doc = p.path.apply(doc, p.remove, p.value)
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode patched body: %w", err)
+	}
+	return out, true, nil
+}
+
+// apply sets or removes the value at the path in doc and returns the result.
+func (jp *jsonPath) apply(doc interface{}, remove bool, value interface{}) interface{} {
+	return applySegments(doc, jp.segments, remove, value)
+}
+
+func applySegments(node interface{}, segs []segment, remove bool, value interface{}) interface{} {
+	seg := segs[0]
+	last := len(segs) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if seg.isIndex {
+			return node
+		}
+		if seg.wildcard {
+			for k, child := range n {
+				if last {
+					if remove {
+						delete(n, k)
+					} else {
+						n[k] = copyValue(value)
+					}
+				} else {
+					n[k] = applySegments(child, segs[1:], remove, value)
+				}
+			}
+			return n
+		}
+		child, ok := n[seg.key]
+		switch {
+		case last && remove:
+			delete(n, seg.key)
+		case last:
+			n[seg.key] = copyValue(value)
+		case ok:
+			n[seg.key] = applySegments(child, segs[1:], remove, value)
+		case !remove:
+			// Create intermediate objects for set
+			n[seg.key] = applySegments(map[string]interface{}{}, segs[1:], remove, value)
+		}
+		return n
+
+	case []interface{}:
+		if !seg.isIndex && !seg.wildcard {
+			return node
+		}
+		if seg.wildcard {
+			if last && remove {
+				return []interface{}{}
+			}
+			for i := range n {
+				if last {
+					n[i] = copyValue(value)
+				} else {
+					n[i] = applySegments(n[i], segs[1:], remove, value)
+				}
+			}
+			return n
+		}
+		if seg.index >= len(n) {
+			return n
+		}
+		if last && remove {
+			return append(n[:seg.index], n[seg.index+1:]...)
+		}
+		if last {
+			n[seg.index] = copyValue(value)
+		} else {
+			n[seg.index] = applySegments(n[seg.index], segs[1:], remove, value)
+		}
+		return n
+	}
+
+	return node
+}
+
+// normalizeValue returns a copy of a YAML-decoded value that encodes as JSON.
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = normalizeValue(val)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, val := range v {
+			a[i] = normalizeValue(val)
+		}
+		return a
+	}
+	return v
+}
+
+// copyValue deep-copies a patch value so that documents never share it.
+func copyValue(v interface{}) interface{} {
+	return normalizeValue(v)
+}
@@ -0,0 +1,440 @@
+// Package rewrite applies declarative rules that modify proxied requests and
+// responses.
+//
+// Rules are matched with the same host, path and method wildcard semantics as
+// capture.Filter. A matching rule can set or remove headers, rewrite the URL
+// or redirect the host, replace body content with regular expressions or
+// JSONPath patches, and answer the request locally without contacting the
+// upstream server.
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/omniproxy/pkg/capture"
+)
+
+// Rule is a declarative rewrite rule.
+type Rule struct {
+	// Name identifies the rule in captured records and logs
+	Name string `yaml:"name"`
+	// Disabled skips the rule without removing it
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Match selects the requests the rule applies to
+	Match Match `yaml:"match,omitempty"`
+	// Request modifies the outgoing request
+	Request *RequestActions `yaml:"request,omitempty"`
+	// Response modifies the response returned to the client
+	Response *ResponseActions `yaml:"response,omitempty"`
+	// Respond answers the request locally instead of forwarding it
+	Respond *LocalResponse `yaml:"respond,omitempty"`
+}
+
+// Match selects requests by host, path and method. Hosts and paths support
+// wildcards; an empty list matches everything.
+type Match struct {
+	// Hosts is a list of hosts to match (supports wildcards)
+	Hosts []string `yaml:"hosts,omitempty"`
+	// Paths is a list of path patterns to match (supports wildcards)
+	Paths []string `yaml:"paths,omitempty"`
+	// Methods is a list of HTTP methods to match
+	Methods []string `yaml:"methods,omitempty"`
+}
+
+// Actions are the header and body modifications shared by requests and responses.
+type Actions struct {
+	// SetHeaders sets (replaces) headers
+	SetHeaders map[string]string `yaml:"setHeaders,omitempty"`
+	// RemoveHeaders removes headers
+	RemoveHeaders []string `yaml:"removeHeaders,omitempty"`
+	// Body applies regular expression replacements to the body
+	Body []Replace `yaml:"body,omitempty"`
+	// JSON applies JSONPath patches to a JSON body
+	JSON []JSONPatch `yaml:"json,omitempty"`
+}
+
+// RequestActions modify an outgoing request.
+type RequestActions struct {
+	Actions `yaml:",inline"`
+	// URL rewrites the full request URL with a regular expression
+	URL *Replace `yaml:"url,omitempty"`
+	// Host redirects the request to another host[:port]
+	Host string `yaml:"host,omitempty"`
+}
+
+// ResponseActions modify a response before it is returned to the client.
+type ResponseActions struct {
+	Actions `yaml:",inline"`
+	// Status overrides the status code
+	Status int `yaml:"status,omitempty"`
+}
+
+// Replace is a regular expression replacement. Replacement supports $1-style
+// group references.
+type Replace struct {
+	// Pattern is the regular expression to match
+	Pattern string `yaml:"pattern"`
+	// Replacement is the replacement text
+	Replacement string `yaml:"replacement"`
+}
+
+// LocalResponse is a response synthesized by the proxy.
+type LocalResponse struct {
+	// Status is the status code (default: 200)
+	Status int `yaml:"status,omitempty"`
+	// Headers are the response headers
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Body is the response body
+	Body string `yaml:"body,omitempty"`
+}
+
+// compiledRule is a rule with its patterns compiled.
+type compiledRule struct {
+	*Rule
+	filter   *capture.Filter
+	url      *regexp.Regexp
+	reqBody  []*regexp.Regexp
+	reqJSON  []*compiledPatch
+	respBody []*regexp.Regexp
+	respJSON []*compiledPatch
+}
+
+// MaxBodySize is the largest body that body replacements and JSON patches
+// are applied to. Larger bodies pass through unchanged.
+const MaxBodySize int64 = 1024 * 1024 // 1MB
+
+// Engine applies rewrite rules in order.
+type Engine struct {
+	rules       []*compiledRule
+	maxBodySize int64
+}
+
+// New compiles rules into an engine. Disabled rules are skipped.
+func New(rules []Rule) (*Engine, error) {
+	e := &Engine{maxBodySize: MaxBodySize}
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Disabled {
+			continue
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		cr, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		e.rules = append(e.rules, cr)
+	}
+	return e, nil
+}
+
+// Len returns the number of active rules.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+func compileRule(rule *Rule) (*compiledRule, error) {
+	cr := &compiledRule{Rule: rule}
+
+	cr.filter = capture.NewFilter()
+	cr.filter.IncludeHosts = rule.Match.Hosts
+	cr.filter.IncludePaths = rule.Match.Paths
+	cr.filter.IncludeMethods = rule.Match.Methods
+	if err := cr.filter.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid match: %w", err)
+	}
+
+	var err error
+	if rule.Request != nil {
+		if rule.Request.URL != nil {
+			if cr.url, err = regexp.Compile(rule.Request.URL.Pattern); err != nil {
+				return nil, fmt.Errorf("invalid url pattern: %w", err)
+			}
+		}
+		if cr.reqBody, err = compileReplaces(rule.Request.Body); err != nil {
+			return nil, err
+		}
+		if cr.reqJSON, err = compilePatches(rule.Request.JSON); err != nil {
+			return nil, err
+		}
+	}
+	if rule.Response != nil {
+		if cr.respBody, err = compileReplaces(rule.Response.Body); err != nil {
+			return nil, err
+		}
+		if cr.respJSON, err = compilePatches(rule.Response.JSON); err != nil {
+			return nil, err
+		}
+	}
+	return cr, nil
+}
+
+func compileReplaces(replaces []Replace) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(replaces))
+	for i, r := range replaces {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body pattern %q: %w", r.Pattern, err)
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+// Result carries the rules matched by a request into the response phase.
+type Result struct {
+	// Rules are the names of the matched rules, in order
+	Rules []string
+	// Response is the locally synthesized response, if a rule answered the request
+	Response *http.Response
+
+	matched     []*compiledRule
+	maxBodySize int64
+}
+
+// RewriteRequest applies matching rules to req in place. Rules are matched
+// against the request as received, before any rule modifies it. It returns
+// nil if no rule matched.
+func (e *Engine) RewriteRequest(req *http.Request) (*Result, error) {
+	host := req.URL.Hostname()
+	if host == "" {
+		host = stripPort(req.Host)
+	}
+	path := req.URL.Path
+
+	var res *Result
+	for _, rule := range e.rules {
+		if !rule.filter.MatchRequest(host, path, req.Method) {
+			continue
+		}
+		if res == nil {
+			res = &Result{maxBodySize: e.maxBodySize}
+		}
+		res.Rules = append(res.Rules, rule.Name)
+		res.matched = append(res.matched, rule)
+
+		if res.Response != nil {
+			continue // already answered; only response actions still apply
+		}
+		if rule.Request != nil {
+			if err := rule.rewriteRequest(req, e.maxBodySize); err != nil {
+				return res, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+		}
+		if rule.Respond != nil {
+			res.Response = rule.Respond.response(req)
+		}
+	}
+	return res, nil
+}
+
+// HasResponseActions reports whether any matched rule modifies the response.
+func (r *Result) HasResponseActions() bool {
+	for _, rule := range r.matched {
+		if rule.Response != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RewriteResponse applies the response actions of the matched rules to resp
+// in place.
+func (r *Result) RewriteResponse(resp *http.Response) error {
+	if resp == nil {
+		return nil
+	}
+	for _, rule := range r.matched {
+		if rule.Response == nil {
+			continue
+		}
+		if err := rule.rewriteResponse(resp, r.maxBodySize); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *compiledRule) rewriteRequest(req *http.Request, limit int64) error {
+	a := r.Request
+
+	if r.url != nil {
+		rewritten := r.url.ReplaceAllString(req.URL.String(), a.URL.Replacement)
+		u, err := url.Parse(rewritten)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("url rewrite produced an invalid URL: %s", rewritten)
+		}
+		req.URL = u
+		req.Host = u.Host
+	}
+	if a.Host != "" {
+		req.URL.Host = a.Host
+		req.Host = a.Host
+	}
+
+	applyHeaders(req.Header, &a.Actions)
+
+	if len(r.reqBody) == 0 && len(r.reqJSON) == 0 {
+		return nil
+	}
+	body, rest, err := peekBody(req.Body, limit)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		req.Body = rest
+		return nil
+	}
+	body, err = rewriteBody(body, a.Body, r.reqBody, r.reqJSON)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+func (r *compiledRule) rewriteResponse(resp *http.Response, limit int64) error {
+	a := r.Response
+
+	if a.Status > 0 {
+		resp.StatusCode = a.Status
+		resp.Status = strconv.Itoa(a.Status) + " " + http.StatusText(a.Status)
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	applyHeaders(resp.Header, &a.Actions)
+
+	if len(r.respBody) == 0 && len(r.respJSON) == 0 {
+		return nil
+	}
+	// Compressed bodies cannot be rewritten as text
+	if ce := resp.Header.Get("Content-Encoding"); ce != "" && !strings.EqualFold(ce, "identity") {
+		return nil
+	}
+	// Streams are relayed as they arrive, and bodies of unknown or excessive
+	// length are not held in memory
+	if isEventStream(resp.Header.Get("Content-Type")) || resp.ContentLength < 0 || resp.ContentLength > limit {
+		return nil
+	}
+	body, rest, err := peekBody(resp.Body, limit)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		resp.Body = rest
+		return nil
+	}
+	body, err = rewriteBody(body, a.Body, r.respBody, r.respJSON)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.TransferEncoding = nil
+	return nil
+}
+
+// rewriteBody applies regex replacements, then JSON patches. JSON patches
+// are skipped when the body is not JSON.
+func rewriteBody(body []byte, replaces []Replace, res []*regexp.Regexp, patches []*compiledPatch) ([]byte, error) {
+	for i, re := range res {
+		body = re.ReplaceAll(body, []byte(replaces[i].Replacement))
+	}
+	if len(patches) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	patched, ok, err := applyPatches(body, patches)
+	if err != nil || !ok {
+		return body, err
+	}
+	return patched, nil
+}
+
+// applyHeaders removes, then sets headers.
+func applyHeaders(h http.Header, a *Actions) {
+	for _, name := range a.RemoveHeaders {
+		h.Del(name)
+	}
+	for name, value := range a.SetHeaders {
+		h.Set(name, value)
+	}
+}
+
+// peekBody reads up to limit bytes of body. If the body fits, it returns
+// its bytes and closes body. Otherwise it returns a nil slice and a reader
+// that replays the bytes read followed by the rest of body.
+func peekBody(body io.ReadCloser, limit int64) ([]byte, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return []byte{}, body, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		body.Close()
+		return nil, nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, readCloser{io.MultiReader(bytes.NewReader(data), body), body}, nil
+	}
+	body.Close()
+	return data, nil, nil
+}
+
+// readCloser combines a reader with the closer of the underlying body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isEventStream reports whether contentType is a server-sent events stream.
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// response builds the local response for req.
+func (l *LocalResponse) response(req *http.Request) *http.Response {
+	status := l.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &http.Response{
+		StatusCode:    status,
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(l.Body)),
+		ContentLength: int64(len(l.Body)),
+		Request:       req,
+	}
+	for k, v := range l.Headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+// stripPort removes a port from host, if present.
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,273 @@
+package rewrite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRewriteRequest(t *testing.T) {
+	e, err := New([]Rule{
+		{
+			Name:  "headers",
+			Match: Match{Hosts: []string{"*.example.com"}, Methods: []string{"POST"}},
+			Request: &RequestActions{
+				Actions: Actions{
+					SetHeaders:    map[string]string{"X-Env": "staging"},
+					RemoveHeaders: []string{"Cookie"},
+					Body:          []Replace{{Pattern: `"user":"(\w+)"`, Replacement: `"user":"$1-test"`}},
+				},
+				Host: "staging.example.com:8443",
+			},
+		},
+		{
+			Name:    "unmatched",
+			Match:   Match{Paths: []string{"/other/*"}},
+			Request: &RequestActions{Actions: Actions{SetHeaders: map[string]string{"X-Other": "1"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "https://api.example.com/v1/login", strings.NewReader(`{"user":"alice"}`))
+	req.Header.Set("Cookie", "session=1")
+
+	res, err := e.RewriteRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res == nil || len(res.Rules) != 1 || res.Rules[0] != "headers" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if req.Host != "staging.example.com:8443" || req.URL.Host != "staging.example.com:8443" {
+		t.Errorf("host not redirected: %s %s", req.Host, req.URL.Host)
+	}
+	if req.Header.Get("X-Env") != "staging" || req.Header.Get("Cookie") != "" || req.Header.Get("X-Other") != "" {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"user":"alice-test"}` || req.ContentLength != int64(len(body)) {
+		t.Errorf("unexpected body: %s (%d)", body, req.ContentLength)
+	}
+
+	// Non-matching requests are untouched
+	req = httptest.NewRequest("GET", "https://api.example.com/v1/login", nil)
+	if res, _ := e.RewriteRequest(req); res != nil {
+		t.Errorf("expected no match, got %+v", res)
+	}
+}
+
+func TestRewriteURL(t *testing.T) {
+	e, err := New([]Rule{{
+		Request: &RequestActions{URL: &Replace{
+			Pattern:     `^https://api\.example\.com/v1/(.*)$`,
+			Replacement: "http://localhost:3000/v2/$1",
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "https://api.example.com/v1/users?page=2", nil)
+	res, err := e.RewriteRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rules[0] != "rule-1" {
+		t.Errorf("expected generated rule name, got %s", res.Rules[0])
+	}
+	if req.URL.String() != "http://localhost:3000/v2/users?page=2" || req.Host != "localhost:3000" {
+		t.Errorf("unexpected URL: %s (host %s)", req.URL, req.Host)
+	}
+}
+
+func TestRewriteResponseAndLocal(t *testing.T) {
+	e, err := New([]Rule{
+		{
+			Name:  "patch",
+			Match: Match{Paths: []string{"/api/*"}},
+			Response: &ResponseActions{
+				Status: http.StatusAccepted,
+				Actions: Actions{
+					SetHeaders: map[string]string{"X-Patched": "true"},
+					JSON: []JSONPatch{
+						{Path: "$.user.role", Value: "admin"},
+						{Path: "$.items[*].price", Value: 0},
+						{Op: OpRemove, Path: "$.secret"},
+						{Path: "$.flags.beta", Value: map[string]interface{}{"on": true}},
+					},
+				},
+			},
+		},
+		{
+			Name:    "local",
+			Match:   Match{Paths: []string{"/api/local"}},
+			Respond: &LocalResponse{Status: 418, Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"local":true}`},
+		},
+		{
+			Name:    "skipped",
+			Match:   Match{Paths: []string{"/api/local"}},
+			Request: &RequestActions{Actions: Actions{SetHeaders: map[string]string{"X-Skipped": "1"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "http://example.com/api/user", nil)
+	res, _ := e.RewriteRequest(req)
+	if res == nil || res.Response != nil || !res.HasResponseActions() {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"user":{"name":"a"},"items":[{"price":5},{"price":7}],"secret":"x"}`)),
+	}
+	if err := res.RewriteResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	want := `{"flags":{"beta":{"on":true}},"items":[{"price":0},{"price":0}],"user":{"name":"a","role":"admin"}}`
+	if string(body) != want {
+		t.Errorf("unexpected body:\n got %s\nwant %s", body, want)
+	}
+	if resp.StatusCode != 202 || resp.Header.Get("X-Patched") != "true" {
+		t.Errorf("unexpected status or headers: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// A local response stops request rewriting; response actions still apply
+	req = httptest.NewRequest("GET", "http://example.com/api/local", nil)
+	res, _ = e.RewriteRequest(req)
+	if res.Response == nil || res.Response.StatusCode != 418 || req.Header.Get("X-Skipped") != "" {
+		t.Fatalf("expected local response without later request actions: %+v", res)
+	}
+	if len(res.Rules) != 3 {
+		t.Errorf("expected all matching rules to be listed, got %v", res.Rules)
+	}
+}
+
+func TestRulesFromYAML(t *testing.T) {
+	data := `
+- name: mock-flags
+  match:
+    hosts: [api.example.com]
+  response:
+    setHeaders:
+      X-Mocked: "yes"
+    json:
+      - path: $.limits
+        value: {max: 10, tiers: [a, b]}
+      - op: remove
+        path: $['internal-id']
+- name: broken
+  disabled: true
+  request:
+    url:
+      pattern: "("
+`
+	var rules []Rule
+	if err := yaml.Unmarshal([]byte(data), &rules); err != nil {
+		t.Fatal(err)
+	}
+	e, err := New(rules)
+	if err != nil {
+		t.Fatalf("disabled rules must not be compiled: %v", err)
+	}
+	if e.Len() != 1 {
+		t.Fatalf("expected 1 active rule, got %d", e.Len())
+	}
+
+	req := httptest.NewRequest("GET", "https://api.example.com/", nil)
+	res, _ := e.RewriteRequest(req)
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"internal-id":1}`))}
+	if err := res.RewriteResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"limits":{"max":10,"tiers":["a","b"]}}` || resp.Header.Get("X-Mocked") != "yes" {
+		t.Errorf("unexpected response: %s %v", body, resp.Header)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	tests := []Rule{
+		{Request: &RequestActions{URL: &Replace{Pattern: "("}}},
+		{Response: &ResponseActions{Actions: Actions{Body: []Replace{{Pattern: "["}}}}},
+		{Response: &ResponseActions{Actions: Actions{JSON: []JSONPatch{{Path: "items"}}}}},
+		{Response: &ResponseActions{Actions: Actions{JSON: []JSONPatch{{Path: "$.a[x]"}}}}},
+		{Response: &ResponseActions{Actions: Actions{JSON: []JSONPatch{{Op: "move", Path: "$.a"}}}}},
+	}
+	for i, rule := range tests {
+		if _, err := New([]Rule{rule}); err == nil {
+			t.Errorf("test %d: expected error", i)
+		}
+	}
+}
+
+func TestRewriteBodyLimits(t *testing.T) {
+	e, err := New([]Rule{{
+		Name:     "replace",
+		Request:  &RequestActions{Actions: Actions{Body: []Replace{{Pattern: "a", Replacement: "b"}}}},
+		Response: &ResponseActions{Actions: Actions{Body: []Replace{{Pattern: "a", Replacement: "b"}}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.maxBodySize = 8
+
+	// Bodies over the limit pass through unchanged
+	req := httptest.NewRequest("POST", "http://example.com/upload", strings.NewReader("aaaaaaaaaaaa"))
+	res, err := e.RewriteRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != "aaaaaaaaaaaa" {
+		t.Errorf("oversized request body rewritten: %s", body)
+	}
+	for _, n := range []int64{12, -1} {
+		resp := &http.Response{StatusCode: 200, Header: http.Header{}, ContentLength: n, Body: io.NopCloser(strings.NewReader("aaaaaaaaaaaa"))}
+		if err := res.RewriteResponse(resp); err != nil {
+			t.Fatal(err)
+		}
+		if body, _ := io.ReadAll(resp.Body); string(body) != "aaaaaaaaaaaa" {
+			t.Errorf("response with length %d rewritten: %s", n, body)
+		}
+	}
+
+	// An event stream is returned without waiting for it to end
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/event-stream"}}, ContentLength: -1, Body: pr}
+	done := make(chan error, 1)
+	go func() { done <- res.RewriteResponse(resp) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("rewriting blocked on an event stream")
+	}
+	go func() { _, _ = pw.Write([]byte("data: a\n\n")) }()
+	buf := make([]byte, 9)
+	if _, err := io.ReadFull(resp.Body, buf); err != nil || string(buf) != "data: a\n\n" {
+		t.Errorf("event stream changed: %q %v", buf, err)
+	}
+
+	// Small bodies are still rewritten
+	resp = &http.Response{StatusCode: 200, Header: http.Header{}, ContentLength: 4, Body: io.NopCloser(strings.NewReader("aaaa"))}
+	if err := res.RewriteResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "bbbb" {
+		t.Errorf("small body not rewritten: %s", body)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/grokify/omniproxy/ui/ent"
@@ -35,6 +36,9 @@ type Service struct {
 	client          *ent.Client
 	sessionDuration time.Duration
 	bcryptCost      int
+
+	mu       sync.Mutex
+	onRevoke []func(email string)
 }
 
 // Config holds auth service configuration.
@@ -122,6 +126,30 @@ func (s *Service) Login(ctx context.Context, orgID int, email, password, ipAddre
 	return sess, nil
 }
 
+// Authenticate verifies an email and password without creating a session.
+// It is used for credentials that are sent with every request, such as
+// proxy authentication.
+func (s *Service) Authenticate(ctx context.Context, email, password string) (*ent.User, error) {
+	users, err := s.client.User.Query().
+		Where(user.Email(email)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Emails are unique per org, so check each account with this email
+	for _, u := range users {
+		if u.PasswordHash == "" || !s.VerifyPassword(u.PasswordHash, password) {
+			continue
+		}
+		if !u.Active {
+			return nil, ErrUserInactive
+		}
+		return u, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
 // CreateSession creates a new session for a user.
 func (s *Service) CreateSession(ctx context.Context, userID int, ipAddress, userAgent string) (*ent.Session, error) {
 	// Generate session token
@@ -196,21 +224,78 @@ func (s *Service) ValidateSession(ctx context.Context, token string) (*ent.User,
 	return u, sess, nil
 }
 
+// OnRevoke registers fn to be called with a user's email after one of the
+// user's sessions is invalidated or the user is disabled, so callers that
+// cache credentials can drop them.
+func (s *Service) OnRevoke(fn func(email string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRevoke = append(s.onRevoke, fn)
+}
+
+// revoked calls the OnRevoke functions for the user with email.
+func (s *Service) revoked(email string) {
+	s.mu.Lock()
+	fns := append([]func(string){}, s.onRevoke...)
+	s.mu.Unlock()
+	for _, fn := range fns {
+		fn(email)
+	}
+}
+
 // Logout invalidates a session.
 func (s *Service) Logout(ctx context.Context, token string) error {
 	hashedToken := hashToken(token)
-	_, err := s.client.Session.Delete().
+	u, err := s.client.User.Query().
+		Where(user.HasSessionsWith(session.Token(hashedToken))).
+		Only(ctx)
+	if err != nil && !ent.IsNotFound(err) {
+		return err
+	}
+	if _, err := s.client.Session.Delete().
 		Where(session.Token(hashedToken)).
-		Exec(ctx)
-	return err
+		Exec(ctx); err != nil {
+		return err
+	}
+	if u != nil {
+		s.revoked(u.Email)
+	}
+	return nil
 }
 
 // LogoutAll invalidates all sessions for a user.
 func (s *Service) LogoutAll(ctx context.Context, userID int) error {
-	_, err := s.client.Session.Delete().
+	u, err := s.client.User.Get(ctx, userID)
+	if err != nil && !ent.IsNotFound(err) {
+		return err
+	}
+	if _, err := s.client.Session.Delete().
 		Where(session.HasUserWith(user.ID(userID))).
-		Exec(ctx)
-	return err
+		Exec(ctx); err != nil {
+		return err
+	}
+	if u != nil {
+		s.revoked(u.Email)
+	}
+	return nil
+}
+
+// SetUserActive enables or disables a user. Disabled users cannot log in,
+// and their existing sessions are rejected.
+func (s *Service) SetUserActive(ctx context.Context, userID int, active bool) error {
+	u, err := s.client.User.UpdateOneID(userID).
+		SetActive(active).
+		Save(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if !active {
+		s.revoked(u.Email)
+	}
+	return nil
 }
 
 // CleanExpiredSessions removes all expired sessions.
@@ -19,6 +19,7 @@ import (
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/session"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 	"github.com/grokify/omniproxy/ui/ent/user"
 )
 
@@ -35,6 +36,8 @@ type Client struct {
 	Session *SessionClient
 	// Traffic is the client for interacting with the Traffic builders.
 	Traffic *TrafficClient
+	// TrafficRollup is the client for interacting with the TrafficRollup builders.
+	TrafficRollup *TrafficRollupClient
 	// User is the client for interacting with the User builders.
 	User *UserClient
 }
@@ -52,6 +55,7 @@ func (c *Client) init() {
 	c.Proxy = NewProxyClient(c.config)
 	c.Session = NewSessionClient(c.config)
 	c.Traffic = NewTrafficClient(c.config)
+	c.TrafficRollup = NewTrafficRollupClient(c.config)
 	c.User = NewUserClient(c.config)
 }
 
@@ -143,13 +147,14 @@ func (c *Client) Tx(ctx context.Context) (*Tx, error) {
 	cfg := c.config
 	cfg.driver = tx
 	return &Tx{
-		ctx:     ctx,
-		config:  cfg,
-		Org:     NewOrgClient(cfg),
-		Proxy:   NewProxyClient(cfg),
-		Session: NewSessionClient(cfg),
-		Traffic: NewTrafficClient(cfg),
-		User:    NewUserClient(cfg),
+		ctx:           ctx,
+		config:        cfg,
+		Org:           NewOrgClient(cfg),
+		Proxy:         NewProxyClient(cfg),
+		Session:       NewSessionClient(cfg),
+		Traffic:       NewTrafficClient(cfg),
+		TrafficRollup: NewTrafficRollupClient(cfg),
+		User:          NewUserClient(cfg),
 	}, nil
 }
 
@@ -167,13 +172,14 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	cfg := c.config
 	cfg.driver = &txDriver{tx: tx, drv: c.driver}
 	return &Tx{
-		ctx:     ctx,
-		config:  cfg,
-		Org:     NewOrgClient(cfg),
-		Proxy:   NewProxyClient(cfg),
-		Session: NewSessionClient(cfg),
-		Traffic: NewTrafficClient(cfg),
-		User:    NewUserClient(cfg),
+		ctx:           ctx,
+		config:        cfg,
+		Org:           NewOrgClient(cfg),
+		Proxy:         NewProxyClient(cfg),
+		Session:       NewSessionClient(cfg),
+		Traffic:       NewTrafficClient(cfg),
+		TrafficRollup: NewTrafficRollupClient(cfg),
+		User:          NewUserClient(cfg),
 	}, nil
 }
 
@@ -202,21 +208,21 @@ func (c *Client) Close() error {
 // Use adds the mutation hooks to all the entity clients.
 // In order to add hooks to a specific client, call: `client.Node.Use(...)`.
 func (c *Client) Use(hooks ...Hook) {
-	c.Org.Use(hooks...)
-	c.Proxy.Use(hooks...)
-	c.Session.Use(hooks...)
-	c.Traffic.Use(hooks...)
-	c.User.Use(hooks...)
+	for _, n := range []interface{ Use(...Hook) }{
+		c.Org, c.Proxy, c.Session, c.Traffic, c.TrafficRollup, c.User,
+	} {
+		n.Use(hooks...)
+	}
 }
 
 // Intercept adds the query interceptors to all the entity clients.
 // In order to add interceptors to a specific client, call: `client.Node.Intercept(...)`.
 func (c *Client) Intercept(interceptors ...Interceptor) {
-	c.Org.Intercept(interceptors...)
-	c.Proxy.Intercept(interceptors...)
-	c.Session.Intercept(interceptors...)
-	c.Traffic.Intercept(interceptors...)
-	c.User.Intercept(interceptors...)
+	for _, n := range []interface{ Intercept(...Interceptor) }{
+		c.Org, c.Proxy, c.Session, c.Traffic, c.TrafficRollup, c.User,
+	} {
+		n.Intercept(interceptors...)
+	}
 }
 
 // Mutate implements the ent.Mutator interface.
@@ -230,6 +236,8 @@ func (c *Client) Mutate(ctx context.Context, m Mutation) (Value, error) {
 		return c.Session.mutate(ctx, m)
 	case *TrafficMutation:
 		return c.Traffic.mutate(ctx, m)
+	case *TrafficRollupMutation:
+		return c.TrafficRollup.mutate(ctx, m)
 	case *UserMutation:
 		return c.User.mutate(ctx, m)
 	default:
@@ -542,6 +550,22 @@ func (c *ProxyClient) QueryTraffic(_m *Proxy) *TrafficQuery {
 	return query
 }
 
+// QueryRollups queries the rollups edge of a Proxy.
+func (c *ProxyClient) QueryRollups(_m *Proxy) *TrafficRollupQuery {
+	query := (&TrafficRollupClient{config: c.config}).Query()
+	query.path = func(context.Context) (fromV *sql.Selector, _ error) {
+		id := _m.ID
+		step := sqlgraph.NewStep(
+			sqlgraph.From(proxy.Table, proxy.FieldID, id),
+			sqlgraph.To(trafficrollup.Table, trafficrollup.FieldID),
+			sqlgraph.Edge(sqlgraph.O2M, false, proxy.RollupsTable, proxy.RollupsColumn),
+		)
+		fromV = sqlgraph.Neighbors(_m.driver.Dialect(), step)
+		return fromV, nil
+	}
+	return query
+}
+
 // Hooks returns the client hooks.
 func (c *ProxyClient) Hooks() []Hook {
 	return c.hooks.Proxy
@@ -865,6 +889,155 @@ func (c *TrafficClient) mutate(ctx context.Context, m *TrafficMutation) (Value,
 	}
 }
 
+// TrafficRollupClient is a client for the TrafficRollup schema.
+type TrafficRollupClient struct {
+	config
+}
+
+// NewTrafficRollupClient returns a client for the TrafficRollup from the given config.
+func NewTrafficRollupClient(c config) *TrafficRollupClient {
+	return &TrafficRollupClient{config: c}
+}
+
+// Use adds a list of mutation hooks to the hooks stack.
+// A call to `Use(f, g, h)` equals to `trafficrollup.Hooks(f(g(h())))`.
+func (c *TrafficRollupClient) Use(hooks ...Hook) {
+	c.hooks.TrafficRollup = append(c.hooks.TrafficRollup, hooks...)
+}
+
+// Intercept adds a list of query interceptors to the interceptors stack.
+// A call to `Intercept(f, g, h)` equals to `trafficrollup.Intercept(f(g(h())))`.
+func (c *TrafficRollupClient) Intercept(interceptors ...Interceptor) {
+	c.inters.TrafficRollup = append(c.inters.TrafficRollup, interceptors...)
+}
+
+// Create returns a builder for creating a TrafficRollup entity.
+func (c *TrafficRollupClient) Create() *TrafficRollupCreate {
+	mutation := newTrafficRollupMutation(c.config, OpCreate)
+	return &TrafficRollupCreate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// CreateBulk returns a builder for creating a bulk of TrafficRollup entities.
+func (c *TrafficRollupClient) CreateBulk(builders ...*TrafficRollupCreate) *TrafficRollupCreateBulk {
+	return &TrafficRollupCreateBulk{config: c.config, builders: builders}
+}
+
+// MapCreateBulk creates a bulk creation builder from the given slice. For each item in the slice, the function creates
+// a builder and applies setFunc on it.
+func (c *TrafficRollupClient) MapCreateBulk(slice any, setFunc func(*TrafficRollupCreate, int)) *TrafficRollupCreateBulk {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice {
+		return &TrafficRollupCreateBulk{err: fmt.Errorf("calling to TrafficRollupClient.MapCreateBulk with wrong type %T, need slice", slice)}
+	}
+	builders := make([]*TrafficRollupCreate, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		builders[i] = c.Create()
+		setFunc(builders[i], i)
+	}
+	return &TrafficRollupCreateBulk{config: c.config, builders: builders}
+}
+
+// Update returns an update builder for TrafficRollup.
+func (c *TrafficRollupClient) Update() *TrafficRollupUpdate {
+	mutation := newTrafficRollupMutation(c.config, OpUpdate)
+	return &TrafficRollupUpdate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOne returns an update builder for the given entity.
+func (c *TrafficRollupClient) UpdateOne(_m *TrafficRollup) *TrafficRollupUpdateOne {
+	mutation := newTrafficRollupMutation(c.config, OpUpdateOne, withTrafficRollup(_m))
+	return &TrafficRollupUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOneID returns an update builder for the given id.
+func (c *TrafficRollupClient) UpdateOneID(id int) *TrafficRollupUpdateOne {
+	mutation := newTrafficRollupMutation(c.config, OpUpdateOne, withTrafficRollupID(id))
+	return &TrafficRollupUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// Delete returns a delete builder for TrafficRollup.
+func (c *TrafficRollupClient) Delete() *TrafficRollupDelete {
+	mutation := newTrafficRollupMutation(c.config, OpDelete)
+	return &TrafficRollupDelete{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// DeleteOne returns a builder for deleting the given entity.
+func (c *TrafficRollupClient) DeleteOne(_m *TrafficRollup) *TrafficRollupDeleteOne {
+	return c.DeleteOneID(_m.ID)
+}
+
+// DeleteOneID returns a builder for deleting the given entity by its id.
+func (c *TrafficRollupClient) DeleteOneID(id int) *TrafficRollupDeleteOne {
+	builder := c.Delete().Where(trafficrollup.ID(id))
+	builder.mutation.id = &id
+	builder.mutation.op = OpDeleteOne
+	return &TrafficRollupDeleteOne{builder}
+}
+
+// Query returns a query builder for TrafficRollup.
+func (c *TrafficRollupClient) Query() *TrafficRollupQuery {
+	return &TrafficRollupQuery{
+		config: c.config,
+		ctx:    &QueryContext{Type: TypeTrafficRollup},
+		inters: c.Interceptors(),
+	}
+}
+
+// Get returns a TrafficRollup entity by its id.
+func (c *TrafficRollupClient) Get(ctx context.Context, id int) (*TrafficRollup, error) {
+	return c.Query().Where(trafficrollup.ID(id)).Only(ctx)
+}
+
+// GetX is like Get, but panics if an error occurs.
+func (c *TrafficRollupClient) GetX(ctx context.Context, id int) *TrafficRollup {
+	obj, err := c.Get(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// QueryProxy queries the proxy edge of a TrafficRollup.
+func (c *TrafficRollupClient) QueryProxy(_m *TrafficRollup) *ProxyQuery {
+	query := (&ProxyClient{config: c.config}).Query()
+	query.path = func(context.Context) (fromV *sql.Selector, _ error) {
+		id := _m.ID
+		step := sqlgraph.NewStep(
+			sqlgraph.From(trafficrollup.Table, trafficrollup.FieldID, id),
+			sqlgraph.To(proxy.Table, proxy.FieldID),
+			sqlgraph.Edge(sqlgraph.M2O, true, trafficrollup.ProxyTable, trafficrollup.ProxyColumn),
+		)
+		fromV = sqlgraph.Neighbors(_m.driver.Dialect(), step)
+		return fromV, nil
+	}
+	return query
+}
+
+// Hooks returns the client hooks.
+func (c *TrafficRollupClient) Hooks() []Hook {
+	return c.hooks.TrafficRollup
+}
+
+// Interceptors returns the client interceptors.
+func (c *TrafficRollupClient) Interceptors() []Interceptor {
+	return c.inters.TrafficRollup
+}
+
+func (c *TrafficRollupClient) mutate(ctx context.Context, m *TrafficRollupMutation) (Value, error) {
+	switch m.Op() {
+	case OpCreate:
+		return (&TrafficRollupCreate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdate:
+		return (&TrafficRollupUpdate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdateOne:
+		return (&TrafficRollupUpdateOne{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpDelete, OpDeleteOne:
+		return (&TrafficRollupDelete{config: c.config, hooks: c.Hooks(), mutation: m}).Exec(ctx)
+	default:
+		return nil, fmt.Errorf("ent: unknown TrafficRollup mutation op: %q", m.Op())
+	}
+}
+
 // UserClient is a client for the User schema.
 type UserClient struct {
 	config
@@ -1033,9 +1206,9 @@ func (c *UserClient) mutate(ctx context.Context, m *UserMutation) (Value, error)
 // hooks and interceptors per client, for fast access.
 type (
 	hooks struct {
-		Org, Proxy, Session, Traffic, User []ent.Hook
+		Org, Proxy, Session, Traffic, TrafficRollup, User []ent.Hook
 	}
 	inters struct {
-		Org, Proxy, Session, Traffic, User []ent.Interceptor
+		Org, Proxy, Session, Traffic, TrafficRollup, User []ent.Interceptor
 	}
 )
@@ -16,6 +16,7 @@ import (
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/session"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 	"github.com/grokify/omniproxy/ui/ent/user"
 )
 
@@ -77,11 +78,12 @@ var (
 func checkColumn(t, c string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
-			org.Table:     org.ValidColumn,
-			proxy.Table:   proxy.ValidColumn,
-			session.Table: session.ValidColumn,
-			traffic.Table: traffic.ValidColumn,
-			user.Table:    user.ValidColumn,
+			org.Table:           org.ValidColumn,
+			proxy.Table:         proxy.ValidColumn,
+			session.Table:       session.ValidColumn,
+			traffic.Table:       traffic.ValidColumn,
+			trafficrollup.Table: trafficrollup.ValidColumn,
+			user.Table:          user.ValidColumn,
 		})
 	})
 	return columnCheck(t, c)
@@ -57,6 +57,18 @@ func (f TrafficFunc) Mutate(ctx context.Context, m ent.Mutation) (ent.Value, err
 	return nil, fmt.Errorf("unexpected mutation type %T. expect *ent.TrafficMutation", m)
 }
 
+// The TrafficRollupFunc type is an adapter to allow the use of ordinary
+// function as TrafficRollup mutator.
+type TrafficRollupFunc func(context.Context, *ent.TrafficRollupMutation) (ent.Value, error)
+
+// Mutate calls f(ctx, m).
+func (f TrafficRollupFunc) Mutate(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+	if mv, ok := m.(*ent.TrafficRollupMutation); ok {
+		return f(ctx, mv)
+	}
+	return nil, fmt.Errorf("unexpected mutation type %T. expect *ent.TrafficRollupMutation", m)
+}
+
 // The UserFunc type is an adapter to allow the use of ordinary
 // function as User mutator.
 type UserFunc func(context.Context, *ent.UserMutation) (ent.Value, error)
@@ -138,21 +138,41 @@ var (
 		{Name: "path", Type: field.TypeString},
 		{Name: "query", Type: field.TypeString, Nullable: true},
 		{Name: "request_headers", Type: field.TypeJSON, Nullable: true},
+		{Name: "request_header_names", Type: field.TypeJSON, Nullable: true},
 		{Name: "request_body", Type: field.TypeBytes, Nullable: true},
 		{Name: "request_body_size", Type: field.TypeInt64, Default: 0},
 		{Name: "request_is_binary", Type: field.TypeBool, Default: false},
+		{Name: "request_body_truncated", Type: field.TypeBool, Default: false},
+		{Name: "request_decoded_size", Type: field.TypeInt64, Default: 0},
 		{Name: "content_type", Type: field.TypeString, Nullable: true},
+		{Name: "http_version", Type: field.TypeString, Nullable: true},
+		{Name: "stream_id", Type: field.TypeUint32, Nullable: true},
 		{Name: "status_code", Type: field.TypeInt},
 		{Name: "status_text", Type: field.TypeString, Nullable: true},
+		{Name: "response_http_version", Type: field.TypeString, Nullable: true},
 		{Name: "response_headers", Type: field.TypeJSON, Nullable: true},
+		{Name: "response_header_names", Type: field.TypeJSON, Nullable: true},
 		{Name: "response_body", Type: field.TypeBytes, Nullable: true},
 		{Name: "response_body_size", Type: field.TypeInt64, Default: 0},
 		{Name: "response_is_binary", Type: field.TypeBool, Default: false},
+		{Name: "response_body_truncated", Type: field.TypeBool, Default: false},
+		{Name: "response_decoded_size", Type: field.TypeInt64, Default: 0},
+		{Name: "response_events", Type: field.TypeJSON, Nullable: true},
 		{Name: "response_content_type", Type: field.TypeString, Nullable: true},
 		{Name: "started_at", Type: field.TypeTime},
 		{Name: "duration_ms", Type: field.TypeFloat64},
 		{Name: "ttfb_ms", Type: field.TypeFloat64, Nullable: true},
+		{Name: "record_type", Type: field.TypeString, Default: "http"},
+		{Name: "connection_id", Type: field.TypeString, Nullable: true},
+		{Name: "frame_sequence", Type: field.TypeInt64, Nullable: true},
+		{Name: "frame_direction", Type: field.TypeString, Nullable: true},
+		{Name: "frame_opcode", Type: field.TypeInt, Nullable: true},
+		{Name: "close_code", Type: field.TypeInt, Nullable: true},
+		{Name: "close_reason", Type: field.TypeString, Nullable: true},
+		{Name: "grpc_status", Type: field.TypeInt, Nullable: true},
+		{Name: "grpc_message", Type: field.TypeString, Nullable: true},
 		{Name: "client_ip", Type: field.TypeString, Nullable: true},
+		{Name: "auth_user", Type: field.TypeString, Nullable: true},
 		{Name: "error", Type: field.TypeString, Nullable: true},
 		{Name: "tags", Type: field.TypeJSON, Nullable: true},
 		{Name: "created_at", Type: field.TypeTime},
@@ -166,7 +186,7 @@ var (
 		ForeignKeys: []*schema.ForeignKey{
 			{
 				Symbol:     "traffics_proxies_traffic",
-				Columns:    []*schema.Column{TrafficsColumns[26]},
+				Columns:    []*schema.Column{TrafficsColumns[46]},
 				RefColumns: []*schema.Column{ProxiesColumns[0]},
 				OnDelete:   schema.NoAction,
 			},
@@ -190,12 +210,12 @@ var (
 			{
 				Name:    "traffic_status_code",
 				Unique:  false,
-				Columns: []*schema.Column{TrafficsColumns[12]},
+				Columns: []*schema.Column{TrafficsColumns[17]},
 			},
 			{
 				Name:    "traffic_started_at",
 				Unique:  false,
-				Columns: []*schema.Column{TrafficsColumns[19]},
+				Columns: []*schema.Column{TrafficsColumns[29]},
 			},
 			{
 				Name:    "traffic_host_path",
@@ -207,6 +227,60 @@ var (
 				Unique:  false,
 				Columns: []*schema.Column{TrafficsColumns[1], TrafficsColumns[4], TrafficsColumns[5]},
 			},
+			{
+				Name:    "traffic_connection_id",
+				Unique:  false,
+				Columns: []*schema.Column{TrafficsColumns[33]},
+			},
+			{
+				Name:    "traffic_auth_user",
+				Unique:  false,
+				Columns: []*schema.Column{TrafficsColumns[42]},
+			},
+		},
+	}
+	// TrafficRollupsColumns holds the columns for the "traffic_rollups" table.
+	TrafficRollupsColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Increment: true},
+		{Name: "bucket", Type: field.TypeTime},
+		{Name: "host", Type: field.TypeString},
+		{Name: "method", Type: field.TypeString},
+		{Name: "status_code", Type: field.TypeInt},
+		{Name: "requests", Type: field.TypeInt64},
+		{Name: "duration_sum_ms", Type: field.TypeFloat64},
+		{Name: "duration_max_ms", Type: field.TypeFloat64},
+		{Name: "duration_histogram", Type: field.TypeJSON},
+		{Name: "proxy_rollups", Type: field.TypeInt},
+	}
+	// TrafficRollupsTable holds the schema information for the "traffic_rollups" table.
+	TrafficRollupsTable = &schema.Table{
+		Name:       "traffic_rollups",
+		Columns:    TrafficRollupsColumns,
+		PrimaryKey: []*schema.Column{TrafficRollupsColumns[0]},
+		ForeignKeys: []*schema.ForeignKey{
+			{
+				Symbol:     "traffic_rollups_proxies_rollups",
+				Columns:    []*schema.Column{TrafficRollupsColumns[9]},
+				RefColumns: []*schema.Column{ProxiesColumns[0]},
+				OnDelete:   schema.NoAction,
+			},
+		},
+		Indexes: []*schema.Index{
+			{
+				Name:    "trafficrollup_bucket",
+				Unique:  false,
+				Columns: []*schema.Column{TrafficRollupsColumns[1]},
+			},
+			{
+				Name:    "trafficrollup_host_bucket",
+				Unique:  false,
+				Columns: []*schema.Column{TrafficRollupsColumns[2], TrafficRollupsColumns[1]},
+			},
+			{
+				Name:    "trafficrollup_bucket_host_method_status_code_proxy_rollups",
+				Unique:  true,
+				Columns: []*schema.Column{TrafficRollupsColumns[1], TrafficRollupsColumns[2], TrafficRollupsColumns[3], TrafficRollupsColumns[4], TrafficRollupsColumns[9]},
+			},
 		},
 	}
 	// UsersColumns holds the columns for the "users" table.
@@ -262,6 +336,7 @@ var (
 		ProxiesTable,
 		SessionsTable,
 		TrafficsTable,
+		TrafficRollupsTable,
 		UsersTable,
 	}
 )
@@ -270,5 +345,6 @@ func init() {
 	ProxiesTable.ForeignKeys[0].RefTable = OrgsTable
 	SessionsTable.ForeignKeys[0].RefTable = UsersTable
 	TrafficsTable.ForeignKeys[0].RefTable = ProxiesTable
+	TrafficRollupsTable.ForeignKeys[0].RefTable = ProxiesTable
 	UsersTable.ForeignKeys[0].RefTable = OrgsTable
 }
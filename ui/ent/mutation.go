@@ -11,11 +11,13 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/ui/ent/org"
 	"github.com/grokify/omniproxy/ui/ent/predicate"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/session"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 	"github.com/grokify/omniproxy/ui/ent/user"
 )
 
@@ -28,11 +30,12 @@ const (
 	OpUpdateOne = ent.OpUpdateOne
 
 	// Node types.
-	TypeOrg     = "Org"
-	TypeProxy   = "Proxy"
-	TypeSession = "Session"
-	TypeTraffic = "Traffic"
-	TypeUser    = "User"
+	TypeOrg           = "Org"
+	TypeProxy         = "Proxy"
+	TypeSession       = "Session"
+	TypeTraffic       = "Traffic"
+	TypeTrafficRollup = "TrafficRollup"
+	TypeUser          = "User"
 )
 
 // OrgMutation represents an operation that mutates the Org nodes in the graph.
@@ -1019,6 +1022,9 @@ type ProxyMutation struct {
 	traffic             map[int]struct{}
 	removedtraffic      map[int]struct{}
 	clearedtraffic      bool
+	rollups             map[int]struct{}
+	removedrollups      map[int]struct{}
+	clearedrollups      bool
 	done                bool
 	oldValue            func(context.Context) (*Proxy, error)
 	predicates          []predicate.Proxy
@@ -2018,6 +2024,60 @@ func (m *ProxyMutation) ResetTraffic() {
 	m.removedtraffic = nil
 }
 
+// AddRollupIDs adds the "rollups" edge to the TrafficRollup entity by ids.
+func (m *ProxyMutation) AddRollupIDs(ids ...int) {
+	if m.rollups == nil {
+		m.rollups = make(map[int]struct{})
+	}
+	for i := range ids {
+		m.rollups[ids[i]] = struct{}{}
+	}
+}
+
+// ClearRollups clears the "rollups" edge to the TrafficRollup entity.
+func (m *ProxyMutation) ClearRollups() {
+	m.clearedrollups = true
+}
+
+// RollupsCleared reports if the "rollups" edge to the TrafficRollup entity was cleared.
+func (m *ProxyMutation) RollupsCleared() bool {
+	return m.clearedrollups
+}
+
+// RemoveRollupIDs removes the "rollups" edge to the TrafficRollup entity by IDs.
+func (m *ProxyMutation) RemoveRollupIDs(ids ...int) {
+	if m.removedrollups == nil {
+		m.removedrollups = make(map[int]struct{})
+	}
+	for i := range ids {
+		delete(m.rollups, ids[i])
+		m.removedrollups[ids[i]] = struct{}{}
+	}
+}
+
+// RemovedRollups returns the removed IDs of the "rollups" edge to the TrafficRollup entity.
+func (m *ProxyMutation) RemovedRollupsIDs() (ids []int) {
+	for id := range m.removedrollups {
+		ids = append(ids, id)
+	}
+	return
+}
+
+// RollupsIDs returns the "rollups" edge IDs in the mutation.
+func (m *ProxyMutation) RollupsIDs() (ids []int) {
+	for id := range m.rollups {
+		ids = append(ids, id)
+	}
+	return
+}
+
+// ResetRollups resets all changes to the "rollups" edge.
+func (m *ProxyMutation) ResetRollups() {
+	m.rollups = nil
+	m.clearedrollups = false
+	m.removedrollups = nil
+}
+
 // Where appends a list predicates to the ProxyMutation builder.
 func (m *ProxyMutation) Where(ps ...predicate.Proxy) {
 	m.predicates = append(m.predicates, ps...)
@@ -2483,13 +2543,16 @@ func (m *ProxyMutation) ResetField(name string) error {
 
 // AddedEdges returns all edge names that were set/added in this mutation.
 func (m *ProxyMutation) AddedEdges() []string {
-	edges := make([]string, 0, 2)
+	edges := make([]string, 0, 3)
 	if m.org != nil {
 		edges = append(edges, proxy.EdgeOrg)
 	}
 	if m.traffic != nil {
 		edges = append(edges, proxy.EdgeTraffic)
 	}
+	if m.rollups != nil {
+		edges = append(edges, proxy.EdgeRollups)
+	}
 	return edges
 }
 
@@ -2507,16 +2570,25 @@ func (m *ProxyMutation) AddedIDs(name string) []ent.Value {
 			ids = append(ids, id)
 		}
 		return ids
+	case proxy.EdgeRollups:
+		ids := make([]ent.Value, 0, len(m.rollups))
+		for id := range m.rollups {
+			ids = append(ids, id)
+		}
+		return ids
 	}
 	return nil
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
 func (m *ProxyMutation) RemovedEdges() []string {
-	edges := make([]string, 0, 2)
+	edges := make([]string, 0, 3)
 	if m.removedtraffic != nil {
 		edges = append(edges, proxy.EdgeTraffic)
 	}
+	if m.removedrollups != nil {
+		edges = append(edges, proxy.EdgeRollups)
+	}
 	return edges
 }
 
@@ -2530,19 +2602,28 @@ func (m *ProxyMutation) RemovedIDs(name string) []ent.Value {
 			ids = append(ids, id)
 		}
 		return ids
+	case proxy.EdgeRollups:
+		ids := make([]ent.Value, 0, len(m.removedrollups))
+		for id := range m.removedrollups {
+			ids = append(ids, id)
+		}
+		return ids
 	}
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
 func (m *ProxyMutation) ClearedEdges() []string {
-	edges := make([]string, 0, 2)
+	edges := make([]string, 0, 3)
 	if m.clearedorg {
 		edges = append(edges, proxy.EdgeOrg)
 	}
 	if m.clearedtraffic {
 		edges = append(edges, proxy.EdgeTraffic)
 	}
+	if m.clearedrollups {
+		edges = append(edges, proxy.EdgeRollups)
+	}
 	return edges
 }
 
@@ -2554,6 +2635,8 @@ func (m *ProxyMutation) EdgeCleared(name string) bool {
 		return m.clearedorg
 	case proxy.EdgeTraffic:
 		return m.clearedtraffic
+	case proxy.EdgeRollups:
+		return m.clearedrollups
 	}
 	return false
 }
@@ -2579,6 +2662,9 @@ func (m *ProxyMutation) ResetEdge(name string) error {
 	case proxy.EdgeTraffic:
 		m.ResetTraffic()
 		return nil
+	case proxy.EdgeRollups:
+		m.ResetRollups()
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy edge %s", name)
 }
@@ -3290,46 +3376,76 @@ func (m *SessionMutation) ResetEdge(name string) error {
 // TrafficMutation represents an operation that mutates the Traffic nodes in the graph.
 type TrafficMutation struct {
 	config
-	op                    Op
-	typ                   string
-	id                    *int
-	method                *string
-	url                   *string
-	scheme                *string
-	host                  *string
-	_path                 *string
-	query                 *string
-	request_headers       *map[string][]string
-	request_body          *[]byte
-	request_body_size     *int64
-	addrequest_body_size  *int64
-	request_is_binary     *bool
-	content_type          *string
-	status_code           *int
-	addstatus_code        *int
-	status_text           *string
-	response_headers      *map[string][]string
-	response_body         *[]byte
-	response_body_size    *int64
-	addresponse_body_size *int64
-	response_is_binary    *bool
-	response_content_type *string
-	started_at            *time.Time
-	duration_ms           *float64
-	addduration_ms        *float64
-	ttfb_ms               *float64
-	addttfb_ms            *float64
-	client_ip             *string
-	error                 *string
-	tags                  *[]string
-	appendtags            []string
-	created_at            *time.Time
-	clearedFields         map[string]struct{}
-	proxy                 *int
-	clearedproxy          bool
-	done                  bool
-	oldValue              func(context.Context) (*Traffic, error)
-	predicates            []predicate.Traffic
+	op                          Op
+	typ                         string
+	id                          *int
+	method                      *string
+	url                         *string
+	scheme                      *string
+	host                        *string
+	_path                       *string
+	query                       *string
+	request_headers             *map[string][]string
+	request_header_names        *[]string
+	appendrequest_header_names  []string
+	request_body                *[]byte
+	request_body_size           *int64
+	addrequest_body_size        *int64
+	request_is_binary           *bool
+	request_body_truncated      *bool
+	request_decoded_size        *int64
+	addrequest_decoded_size     *int64
+	content_type                *string
+	http_version                *string
+	stream_id                   *uint32
+	addstream_id                *int32
+	status_code                 *int
+	addstatus_code              *int
+	status_text                 *string
+	response_http_version       *string
+	response_headers            *map[string][]string
+	response_header_names       *[]string
+	appendresponse_header_names []string
+	response_body               *[]byte
+	response_body_size          *int64
+	addresponse_body_size       *int64
+	response_is_binary          *bool
+	response_body_truncated     *bool
+	response_decoded_size       *int64
+	addresponse_decoded_size    *int64
+	response_events             *[]capture.StreamEvent
+	appendresponse_events       []capture.StreamEvent
+	response_content_type       *string
+	started_at                  *time.Time
+	duration_ms                 *float64
+	addduration_ms              *float64
+	ttfb_ms                     *float64
+	addttfb_ms                  *float64
+	record_type                 *string
+	connection_id               *string
+	frame_sequence              *int64
+	addframe_sequence           *int64
+	frame_direction             *string
+	frame_opcode                *int
+	addframe_opcode             *int
+	close_code                  *int
+	addclose_code               *int
+	close_reason                *string
+	grpc_status                 *int
+	addgrpc_status              *int
+	grpc_message                *string
+	client_ip                   *string
+	auth_user                   *string
+	error                       *string
+	tags                        *[]string
+	appendtags                  []string
+	created_at                  *time.Time
+	clearedFields               map[string]struct{}
+	proxy                       *int
+	clearedproxy                bool
+	done                        bool
+	oldValue                    func(context.Context) (*Traffic, error)
+	predicates                  []predicate.Traffic
 }
 
 var _ ent.Mutation = (*TrafficMutation)(nil)
@@ -3708,6 +3824,71 @@ func (m *TrafficMutation) ResetRequestHeaders() {
 	delete(m.clearedFields, traffic.FieldRequestHeaders)
 }
 
+// SetRequestHeaderNames sets the "request_header_names" field.
+func (m *TrafficMutation) SetRequestHeaderNames(s []string) {
+	m.request_header_names = &s
+	m.appendrequest_header_names = nil
+}
+
+// RequestHeaderNames returns the value of the "request_header_names" field in the mutation.
+func (m *TrafficMutation) RequestHeaderNames() (r []string, exists bool) {
+	v := m.request_header_names
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestHeaderNames returns the old "request_header_names" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldRequestHeaderNames(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestHeaderNames is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestHeaderNames requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestHeaderNames: %w", err)
+	}
+	return oldValue.RequestHeaderNames, nil
+}
+
+// AppendRequestHeaderNames adds s to the "request_header_names" field.
+func (m *TrafficMutation) AppendRequestHeaderNames(s []string) {
+	m.appendrequest_header_names = append(m.appendrequest_header_names, s...)
+}
+
+// AppendedRequestHeaderNames returns the list of values that were appended to the "request_header_names" field in this mutation.
+func (m *TrafficMutation) AppendedRequestHeaderNames() ([]string, bool) {
+	if len(m.appendrequest_header_names) == 0 {
+		return nil, false
+	}
+	return m.appendrequest_header_names, true
+}
+
+// ClearRequestHeaderNames clears the value of the "request_header_names" field.
+func (m *TrafficMutation) ClearRequestHeaderNames() {
+	m.request_header_names = nil
+	m.appendrequest_header_names = nil
+	m.clearedFields[traffic.FieldRequestHeaderNames] = struct{}{}
+}
+
+// RequestHeaderNamesCleared returns if the "request_header_names" field was cleared in this mutation.
+func (m *TrafficMutation) RequestHeaderNamesCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldRequestHeaderNames]
+	return ok
+}
+
+// ResetRequestHeaderNames resets all changes to the "request_header_names" field.
+func (m *TrafficMutation) ResetRequestHeaderNames() {
+	m.request_header_names = nil
+	m.appendrequest_header_names = nil
+	delete(m.clearedFields, traffic.FieldRequestHeaderNames)
+}
+
 // SetRequestBody sets the "request_body" field.
 func (m *TrafficMutation) SetRequestBody(b []byte) {
 	m.request_body = &b
@@ -3849,6 +4030,98 @@ func (m *TrafficMutation) ResetRequestIsBinary() {
 	m.request_is_binary = nil
 }
 
+// SetRequestBodyTruncated sets the "request_body_truncated" field.
+func (m *TrafficMutation) SetRequestBodyTruncated(b bool) {
+	m.request_body_truncated = &b
+}
+
+// RequestBodyTruncated returns the value of the "request_body_truncated" field in the mutation.
+func (m *TrafficMutation) RequestBodyTruncated() (r bool, exists bool) {
+	v := m.request_body_truncated
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestBodyTruncated returns the old "request_body_truncated" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldRequestBodyTruncated(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestBodyTruncated is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestBodyTruncated requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestBodyTruncated: %w", err)
+	}
+	return oldValue.RequestBodyTruncated, nil
+}
+
+// ResetRequestBodyTruncated resets all changes to the "request_body_truncated" field.
+func (m *TrafficMutation) ResetRequestBodyTruncated() {
+	m.request_body_truncated = nil
+}
+
+// SetRequestDecodedSize sets the "request_decoded_size" field.
+func (m *TrafficMutation) SetRequestDecodedSize(i int64) {
+	m.request_decoded_size = &i
+	m.addrequest_decoded_size = nil
+}
+
+// RequestDecodedSize returns the value of the "request_decoded_size" field in the mutation.
+func (m *TrafficMutation) RequestDecodedSize() (r int64, exists bool) {
+	v := m.request_decoded_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestDecodedSize returns the old "request_decoded_size" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldRequestDecodedSize(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestDecodedSize is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestDecodedSize requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestDecodedSize: %w", err)
+	}
+	return oldValue.RequestDecodedSize, nil
+}
+
+// AddRequestDecodedSize adds i to the "request_decoded_size" field.
+func (m *TrafficMutation) AddRequestDecodedSize(i int64) {
+	if m.addrequest_decoded_size != nil {
+		*m.addrequest_decoded_size += i
+	} else {
+		m.addrequest_decoded_size = &i
+	}
+}
+
+// AddedRequestDecodedSize returns the value that was added to the "request_decoded_size" field in this mutation.
+func (m *TrafficMutation) AddedRequestDecodedSize() (r int64, exists bool) {
+	v := m.addrequest_decoded_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRequestDecodedSize resets all changes to the "request_decoded_size" field.
+func (m *TrafficMutation) ResetRequestDecodedSize() {
+	m.request_decoded_size = nil
+	m.addrequest_decoded_size = nil
+}
+
 // SetContentType sets the "content_type" field.
 func (m *TrafficMutation) SetContentType(s string) {
 	m.content_type = &s
@@ -3898,6 +4171,125 @@ func (m *TrafficMutation) ResetContentType() {
 	delete(m.clearedFields, traffic.FieldContentType)
 }
 
+// SetHTTPVersion sets the "http_version" field.
+func (m *TrafficMutation) SetHTTPVersion(s string) {
+	m.http_version = &s
+}
+
+// HTTPVersion returns the value of the "http_version" field in the mutation.
+func (m *TrafficMutation) HTTPVersion() (r string, exists bool) {
+	v := m.http_version
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHTTPVersion returns the old "http_version" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldHTTPVersion(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHTTPVersion is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHTTPVersion requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHTTPVersion: %w", err)
+	}
+	return oldValue.HTTPVersion, nil
+}
+
+// ClearHTTPVersion clears the value of the "http_version" field.
+func (m *TrafficMutation) ClearHTTPVersion() {
+	m.http_version = nil
+	m.clearedFields[traffic.FieldHTTPVersion] = struct{}{}
+}
+
+// HTTPVersionCleared returns if the "http_version" field was cleared in this mutation.
+func (m *TrafficMutation) HTTPVersionCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldHTTPVersion]
+	return ok
+}
+
+// ResetHTTPVersion resets all changes to the "http_version" field.
+func (m *TrafficMutation) ResetHTTPVersion() {
+	m.http_version = nil
+	delete(m.clearedFields, traffic.FieldHTTPVersion)
+}
+
+// SetStreamID sets the "stream_id" field.
+func (m *TrafficMutation) SetStreamID(u uint32) {
+	m.stream_id = &u
+	m.addstream_id = nil
+}
+
+// StreamID returns the value of the "stream_id" field in the mutation.
+func (m *TrafficMutation) StreamID() (r uint32, exists bool) {
+	v := m.stream_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStreamID returns the old "stream_id" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldStreamID(ctx context.Context) (v uint32, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStreamID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStreamID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStreamID: %w", err)
+	}
+	return oldValue.StreamID, nil
+}
+
+// AddStreamID adds u to the "stream_id" field.
+func (m *TrafficMutation) AddStreamID(u int32) {
+	if m.addstream_id != nil {
+		*m.addstream_id += u
+	} else {
+		m.addstream_id = &u
+	}
+}
+
+// AddedStreamID returns the value that was added to the "stream_id" field in this mutation.
+func (m *TrafficMutation) AddedStreamID() (r int32, exists bool) {
+	v := m.addstream_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearStreamID clears the value of the "stream_id" field.
+func (m *TrafficMutation) ClearStreamID() {
+	m.stream_id = nil
+	m.addstream_id = nil
+	m.clearedFields[traffic.FieldStreamID] = struct{}{}
+}
+
+// StreamIDCleared returns if the "stream_id" field was cleared in this mutation.
+func (m *TrafficMutation) StreamIDCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldStreamID]
+	return ok
+}
+
+// ResetStreamID resets all changes to the "stream_id" field.
+func (m *TrafficMutation) ResetStreamID() {
+	m.stream_id = nil
+	m.addstream_id = nil
+	delete(m.clearedFields, traffic.FieldStreamID)
+}
+
 // SetStatusCode sets the "status_code" field.
 func (m *TrafficMutation) SetStatusCode(i int) {
 	m.status_code = &i
@@ -4003,6 +4395,55 @@ func (m *TrafficMutation) ResetStatusText() {
 	delete(m.clearedFields, traffic.FieldStatusText)
 }
 
+// SetResponseHTTPVersion sets the "response_http_version" field.
+func (m *TrafficMutation) SetResponseHTTPVersion(s string) {
+	m.response_http_version = &s
+}
+
+// ResponseHTTPVersion returns the value of the "response_http_version" field in the mutation.
+func (m *TrafficMutation) ResponseHTTPVersion() (r string, exists bool) {
+	v := m.response_http_version
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseHTTPVersion returns the old "response_http_version" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldResponseHTTPVersion(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseHTTPVersion is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseHTTPVersion requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseHTTPVersion: %w", err)
+	}
+	return oldValue.ResponseHTTPVersion, nil
+}
+
+// ClearResponseHTTPVersion clears the value of the "response_http_version" field.
+func (m *TrafficMutation) ClearResponseHTTPVersion() {
+	m.response_http_version = nil
+	m.clearedFields[traffic.FieldResponseHTTPVersion] = struct{}{}
+}
+
+// ResponseHTTPVersionCleared returns if the "response_http_version" field was cleared in this mutation.
+func (m *TrafficMutation) ResponseHTTPVersionCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldResponseHTTPVersion]
+	return ok
+}
+
+// ResetResponseHTTPVersion resets all changes to the "response_http_version" field.
+func (m *TrafficMutation) ResetResponseHTTPVersion() {
+	m.response_http_version = nil
+	delete(m.clearedFields, traffic.FieldResponseHTTPVersion)
+}
+
 // SetResponseHeaders sets the "response_headers" field.
 func (m *TrafficMutation) SetResponseHeaders(value map[string][]string) {
 	m.response_headers = &value
@@ -4052,6 +4493,71 @@ func (m *TrafficMutation) ResetResponseHeaders() {
 	delete(m.clearedFields, traffic.FieldResponseHeaders)
 }
 
+// SetResponseHeaderNames sets the "response_header_names" field.
+func (m *TrafficMutation) SetResponseHeaderNames(s []string) {
+	m.response_header_names = &s
+	m.appendresponse_header_names = nil
+}
+
+// ResponseHeaderNames returns the value of the "response_header_names" field in the mutation.
+func (m *TrafficMutation) ResponseHeaderNames() (r []string, exists bool) {
+	v := m.response_header_names
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseHeaderNames returns the old "response_header_names" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldResponseHeaderNames(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseHeaderNames is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseHeaderNames requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseHeaderNames: %w", err)
+	}
+	return oldValue.ResponseHeaderNames, nil
+}
+
+// AppendResponseHeaderNames adds s to the "response_header_names" field.
+func (m *TrafficMutation) AppendResponseHeaderNames(s []string) {
+	m.appendresponse_header_names = append(m.appendresponse_header_names, s...)
+}
+
+// AppendedResponseHeaderNames returns the list of values that were appended to the "response_header_names" field in this mutation.
+func (m *TrafficMutation) AppendedResponseHeaderNames() ([]string, bool) {
+	if len(m.appendresponse_header_names) == 0 {
+		return nil, false
+	}
+	return m.appendresponse_header_names, true
+}
+
+// ClearResponseHeaderNames clears the value of the "response_header_names" field.
+func (m *TrafficMutation) ClearResponseHeaderNames() {
+	m.response_header_names = nil
+	m.appendresponse_header_names = nil
+	m.clearedFields[traffic.FieldResponseHeaderNames] = struct{}{}
+}
+
+// ResponseHeaderNamesCleared returns if the "response_header_names" field was cleared in this mutation.
+func (m *TrafficMutation) ResponseHeaderNamesCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldResponseHeaderNames]
+	return ok
+}
+
+// ResetResponseHeaderNames resets all changes to the "response_header_names" field.
+func (m *TrafficMutation) ResetResponseHeaderNames() {
+	m.response_header_names = nil
+	m.appendresponse_header_names = nil
+	delete(m.clearedFields, traffic.FieldResponseHeaderNames)
+}
+
 // SetResponseBody sets the "response_body" field.
 func (m *TrafficMutation) SetResponseBody(b []byte) {
 	m.response_body = &b
@@ -4193,38 +4699,195 @@ func (m *TrafficMutation) ResetResponseIsBinary() {
 	m.response_is_binary = nil
 }
 
-// SetResponseContentType sets the "response_content_type" field.
-func (m *TrafficMutation) SetResponseContentType(s string) {
-	m.response_content_type = &s
+// SetResponseBodyTruncated sets the "response_body_truncated" field.
+func (m *TrafficMutation) SetResponseBodyTruncated(b bool) {
+	m.response_body_truncated = &b
 }
 
-// ResponseContentType returns the value of the "response_content_type" field in the mutation.
-func (m *TrafficMutation) ResponseContentType() (r string, exists bool) {
-	v := m.response_content_type
+// ResponseBodyTruncated returns the value of the "response_body_truncated" field in the mutation.
+func (m *TrafficMutation) ResponseBodyTruncated() (r bool, exists bool) {
+	v := m.response_body_truncated
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldResponseContentType returns the old "response_content_type" field's value of the Traffic entity.
+// OldResponseBodyTruncated returns the old "response_body_truncated" field's value of the Traffic entity.
 // If the Traffic object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *TrafficMutation) OldResponseContentType(ctx context.Context) (v string, err error) {
+func (m *TrafficMutation) OldResponseBodyTruncated(ctx context.Context) (v bool, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldResponseContentType is only allowed on UpdateOne operations")
+		return v, errors.New("OldResponseBodyTruncated is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldResponseContentType requires an ID field in the mutation")
+		return v, errors.New("OldResponseBodyTruncated requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldResponseContentType: %w", err)
+		return v, fmt.Errorf("querying old value for OldResponseBodyTruncated: %w", err)
 	}
-	return oldValue.ResponseContentType, nil
+	return oldValue.ResponseBodyTruncated, nil
 }
 
-// ClearResponseContentType clears the value of the "response_content_type" field.
+// ResetResponseBodyTruncated resets all changes to the "response_body_truncated" field.
+func (m *TrafficMutation) ResetResponseBodyTruncated() {
+	m.response_body_truncated = nil
+}
+
+// SetResponseDecodedSize sets the "response_decoded_size" field.
+func (m *TrafficMutation) SetResponseDecodedSize(i int64) {
+	m.response_decoded_size = &i
+	m.addresponse_decoded_size = nil
+}
+
+// ResponseDecodedSize returns the value of the "response_decoded_size" field in the mutation.
+func (m *TrafficMutation) ResponseDecodedSize() (r int64, exists bool) {
+	v := m.response_decoded_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseDecodedSize returns the old "response_decoded_size" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldResponseDecodedSize(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseDecodedSize is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseDecodedSize requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseDecodedSize: %w", err)
+	}
+	return oldValue.ResponseDecodedSize, nil
+}
+
+// AddResponseDecodedSize adds i to the "response_decoded_size" field.
+func (m *TrafficMutation) AddResponseDecodedSize(i int64) {
+	if m.addresponse_decoded_size != nil {
+		*m.addresponse_decoded_size += i
+	} else {
+		m.addresponse_decoded_size = &i
+	}
+}
+
+// AddedResponseDecodedSize returns the value that was added to the "response_decoded_size" field in this mutation.
+func (m *TrafficMutation) AddedResponseDecodedSize() (r int64, exists bool) {
+	v := m.addresponse_decoded_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseDecodedSize resets all changes to the "response_decoded_size" field.
+func (m *TrafficMutation) ResetResponseDecodedSize() {
+	m.response_decoded_size = nil
+	m.addresponse_decoded_size = nil
+}
+
+// SetResponseEvents sets the "response_events" field.
+func (m *TrafficMutation) SetResponseEvents(ce []capture.StreamEvent) {
+	m.response_events = &ce
+	m.appendresponse_events = nil
+}
+
+// ResponseEvents returns the value of the "response_events" field in the mutation.
+func (m *TrafficMutation) ResponseEvents() (r []capture.StreamEvent, exists bool) {
+	v := m.response_events
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseEvents returns the old "response_events" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldResponseEvents(ctx context.Context) (v []capture.StreamEvent, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseEvents is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseEvents requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseEvents: %w", err)
+	}
+	return oldValue.ResponseEvents, nil
+}
+
+// AppendResponseEvents adds ce to the "response_events" field.
+func (m *TrafficMutation) AppendResponseEvents(ce []capture.StreamEvent) {
+	m.appendresponse_events = append(m.appendresponse_events, ce...)
+}
+
+// AppendedResponseEvents returns the list of values that were appended to the "response_events" field in this mutation.
+func (m *TrafficMutation) AppendedResponseEvents() ([]capture.StreamEvent, bool) {
+	if len(m.appendresponse_events) == 0 {
+		return nil, false
+	}
+	return m.appendresponse_events, true
+}
+
+// ClearResponseEvents clears the value of the "response_events" field.
+func (m *TrafficMutation) ClearResponseEvents() {
+	m.response_events = nil
+	m.appendresponse_events = nil
+	m.clearedFields[traffic.FieldResponseEvents] = struct{}{}
+}
+
+// ResponseEventsCleared returns if the "response_events" field was cleared in this mutation.
+func (m *TrafficMutation) ResponseEventsCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldResponseEvents]
+	return ok
+}
+
+// ResetResponseEvents resets all changes to the "response_events" field.
+func (m *TrafficMutation) ResetResponseEvents() {
+	m.response_events = nil
+	m.appendresponse_events = nil
+	delete(m.clearedFields, traffic.FieldResponseEvents)
+}
+
+// SetResponseContentType sets the "response_content_type" field.
+func (m *TrafficMutation) SetResponseContentType(s string) {
+	m.response_content_type = &s
+}
+
+// ResponseContentType returns the value of the "response_content_type" field in the mutation.
+func (m *TrafficMutation) ResponseContentType() (r string, exists bool) {
+	v := m.response_content_type
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseContentType returns the old "response_content_type" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldResponseContentType(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseContentType is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseContentType requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseContentType: %w", err)
+	}
+	return oldValue.ResponseContentType, nil
+}
+
+// ClearResponseContentType clears the value of the "response_content_type" field.
 func (m *TrafficMutation) ClearResponseContentType() {
 	m.response_content_type = nil
 	m.clearedFields[traffic.FieldResponseContentType] = struct{}{}
@@ -4404,677 +5067,2809 @@ func (m *TrafficMutation) ResetTtfbMs() {
 	delete(m.clearedFields, traffic.FieldTtfbMs)
 }
 
-// SetClientIP sets the "client_ip" field.
-func (m *TrafficMutation) SetClientIP(s string) {
-	m.client_ip = &s
+// SetRecordType sets the "record_type" field.
+func (m *TrafficMutation) SetRecordType(s string) {
+	m.record_type = &s
 }
 
-// ClientIP returns the value of the "client_ip" field in the mutation.
-func (m *TrafficMutation) ClientIP() (r string, exists bool) {
-	v := m.client_ip
+// RecordType returns the value of the "record_type" field in the mutation.
+func (m *TrafficMutation) RecordType() (r string, exists bool) {
+	v := m.record_type
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClientIP returns the old "client_ip" field's value of the Traffic entity.
+// OldRecordType returns the old "record_type" field's value of the Traffic entity.
 // If the Traffic object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *TrafficMutation) OldClientIP(ctx context.Context) (v string, err error) {
+func (m *TrafficMutation) OldRecordType(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClientIP is only allowed on UpdateOne operations")
+		return v, errors.New("OldRecordType is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClientIP requires an ID field in the mutation")
+		return v, errors.New("OldRecordType requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClientIP: %w", err)
+		return v, fmt.Errorf("querying old value for OldRecordType: %w", err)
 	}
-	return oldValue.ClientIP, nil
-}
-
-// ClearClientIP clears the value of the "client_ip" field.
-func (m *TrafficMutation) ClearClientIP() {
-	m.client_ip = nil
-	m.clearedFields[traffic.FieldClientIP] = struct{}{}
-}
-
-// ClientIPCleared returns if the "client_ip" field was cleared in this mutation.
-func (m *TrafficMutation) ClientIPCleared() bool {
-	_, ok := m.clearedFields[traffic.FieldClientIP]
-	return ok
+	return oldValue.RecordType, nil
 }
 
-// ResetClientIP resets all changes to the "client_ip" field.
-func (m *TrafficMutation) ResetClientIP() {
-	m.client_ip = nil
-	delete(m.clearedFields, traffic.FieldClientIP)
+// ResetRecordType resets all changes to the "record_type" field.
+func (m *TrafficMutation) ResetRecordType() {
+	m.record_type = nil
 }
 
-// SetError sets the "error" field.
-func (m *TrafficMutation) SetError(s string) {
-	m.error = &s
+// SetConnectionID sets the "connection_id" field.
+func (m *TrafficMutation) SetConnectionID(s string) {
+	m.connection_id = &s
 }
 
-// Error returns the value of the "error" field in the mutation.
-func (m *TrafficMutation) Error() (r string, exists bool) {
-	v := m.error
+// ConnectionID returns the value of the "connection_id" field in the mutation.
+func (m *TrafficMutation) ConnectionID() (r string, exists bool) {
+	v := m.connection_id
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldError returns the old "error" field's value of the Traffic entity.
+// OldConnectionID returns the old "connection_id" field's value of the Traffic entity.
 // If the Traffic object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *TrafficMutation) OldError(ctx context.Context) (v string, err error) {
+func (m *TrafficMutation) OldConnectionID(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldError is only allowed on UpdateOne operations")
+		return v, errors.New("OldConnectionID is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldError requires an ID field in the mutation")
+		return v, errors.New("OldConnectionID requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldError: %w", err)
+		return v, fmt.Errorf("querying old value for OldConnectionID: %w", err)
 	}
-	return oldValue.Error, nil
+	return oldValue.ConnectionID, nil
 }
 
-// ClearError clears the value of the "error" field.
-func (m *TrafficMutation) ClearError() {
-	m.error = nil
-	m.clearedFields[traffic.FieldError] = struct{}{}
+// ClearConnectionID clears the value of the "connection_id" field.
+func (m *TrafficMutation) ClearConnectionID() {
+	m.connection_id = nil
+	m.clearedFields[traffic.FieldConnectionID] = struct{}{}
 }
 
-// ErrorCleared returns if the "error" field was cleared in this mutation.
-func (m *TrafficMutation) ErrorCleared() bool {
-	_, ok := m.clearedFields[traffic.FieldError]
+// ConnectionIDCleared returns if the "connection_id" field was cleared in this mutation.
+func (m *TrafficMutation) ConnectionIDCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldConnectionID]
 	return ok
 }
 
-// ResetError resets all changes to the "error" field.
-func (m *TrafficMutation) ResetError() {
-	m.error = nil
-	delete(m.clearedFields, traffic.FieldError)
+// ResetConnectionID resets all changes to the "connection_id" field.
+func (m *TrafficMutation) ResetConnectionID() {
+	m.connection_id = nil
+	delete(m.clearedFields, traffic.FieldConnectionID)
 }
 
-// SetTags sets the "tags" field.
-func (m *TrafficMutation) SetTags(s []string) {
-	m.tags = &s
-	m.appendtags = nil
+// SetFrameSequence sets the "frame_sequence" field.
+func (m *TrafficMutation) SetFrameSequence(i int64) {
+	m.frame_sequence = &i
+	m.addframe_sequence = nil
 }
 
-// Tags returns the value of the "tags" field in the mutation.
-func (m *TrafficMutation) Tags() (r []string, exists bool) {
-	v := m.tags
+// FrameSequence returns the value of the "frame_sequence" field in the mutation.
+func (m *TrafficMutation) FrameSequence() (r int64, exists bool) {
+	v := m.frame_sequence
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldTags returns the old "tags" field's value of the Traffic entity.
+// OldFrameSequence returns the old "frame_sequence" field's value of the Traffic entity.
 // If the Traffic object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *TrafficMutation) OldTags(ctx context.Context) (v []string, err error) {
+func (m *TrafficMutation) OldFrameSequence(ctx context.Context) (v int64, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldTags is only allowed on UpdateOne operations")
+		return v, errors.New("OldFrameSequence is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldTags requires an ID field in the mutation")
+		return v, errors.New("OldFrameSequence requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldTags: %w", err)
+		return v, fmt.Errorf("querying old value for OldFrameSequence: %w", err)
 	}
-	return oldValue.Tags, nil
+	return oldValue.FrameSequence, nil
 }
 
-// AppendTags adds s to the "tags" field.
-func (m *TrafficMutation) AppendTags(s []string) {
-	m.appendtags = append(m.appendtags, s...)
+// AddFrameSequence adds i to the "frame_sequence" field.
+func (m *TrafficMutation) AddFrameSequence(i int64) {
+	if m.addframe_sequence != nil {
+		*m.addframe_sequence += i
+	} else {
+		m.addframe_sequence = &i
+	}
 }
 
-// AppendedTags returns the list of values that were appended to the "tags" field in this mutation.
-func (m *TrafficMutation) AppendedTags() ([]string, bool) {
-	if len(m.appendtags) == 0 {
-		return nil, false
+// AddedFrameSequence returns the value that was added to the "frame_sequence" field in this mutation.
+func (m *TrafficMutation) AddedFrameSequence() (r int64, exists bool) {
+	v := m.addframe_sequence
+	if v == nil {
+		return
 	}
-	return m.appendtags, true
+	return *v, true
 }
 
-// ClearTags clears the value of the "tags" field.
-func (m *TrafficMutation) ClearTags() {
-	m.tags = nil
-	m.appendtags = nil
-	m.clearedFields[traffic.FieldTags] = struct{}{}
+// ClearFrameSequence clears the value of the "frame_sequence" field.
+func (m *TrafficMutation) ClearFrameSequence() {
+	m.frame_sequence = nil
+	m.addframe_sequence = nil
+	m.clearedFields[traffic.FieldFrameSequence] = struct{}{}
 }
 
-// TagsCleared returns if the "tags" field was cleared in this mutation.
-func (m *TrafficMutation) TagsCleared() bool {
-	_, ok := m.clearedFields[traffic.FieldTags]
+// FrameSequenceCleared returns if the "frame_sequence" field was cleared in this mutation.
+func (m *TrafficMutation) FrameSequenceCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldFrameSequence]
 	return ok
 }
 
-// ResetTags resets all changes to the "tags" field.
-func (m *TrafficMutation) ResetTags() {
-	m.tags = nil
-	m.appendtags = nil
-	delete(m.clearedFields, traffic.FieldTags)
+// ResetFrameSequence resets all changes to the "frame_sequence" field.
+func (m *TrafficMutation) ResetFrameSequence() {
+	m.frame_sequence = nil
+	m.addframe_sequence = nil
+	delete(m.clearedFields, traffic.FieldFrameSequence)
 }
 
-// SetCreatedAt sets the "created_at" field.
-func (m *TrafficMutation) SetCreatedAt(t time.Time) {
-	m.created_at = &t
+// SetFrameDirection sets the "frame_direction" field.
+func (m *TrafficMutation) SetFrameDirection(s string) {
+	m.frame_direction = &s
 }
 
-// CreatedAt returns the value of the "created_at" field in the mutation.
-func (m *TrafficMutation) CreatedAt() (r time.Time, exists bool) {
-	v := m.created_at
+// FrameDirection returns the value of the "frame_direction" field in the mutation.
+func (m *TrafficMutation) FrameDirection() (r string, exists bool) {
+	v := m.frame_direction
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldCreatedAt returns the old "created_at" field's value of the Traffic entity.
+// OldFrameDirection returns the old "frame_direction" field's value of the Traffic entity.
 // If the Traffic object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *TrafficMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+func (m *TrafficMutation) OldFrameDirection(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+		return v, errors.New("OldFrameDirection is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+		return v, errors.New("OldFrameDirection requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+		return v, fmt.Errorf("querying old value for OldFrameDirection: %w", err)
 	}
-	return oldValue.CreatedAt, nil
+	return oldValue.FrameDirection, nil
 }
 
-// ResetCreatedAt resets all changes to the "created_at" field.
-func (m *TrafficMutation) ResetCreatedAt() {
-	m.created_at = nil
+// ClearFrameDirection clears the value of the "frame_direction" field.
+func (m *TrafficMutation) ClearFrameDirection() {
+	m.frame_direction = nil
+	m.clearedFields[traffic.FieldFrameDirection] = struct{}{}
 }
 
-// SetProxyID sets the "proxy" edge to the Proxy entity by id.
-func (m *TrafficMutation) SetProxyID(id int) {
-	m.proxy = &id
+// FrameDirectionCleared returns if the "frame_direction" field was cleared in this mutation.
+func (m *TrafficMutation) FrameDirectionCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldFrameDirection]
+	return ok
 }
 
-// ClearProxy clears the "proxy" edge to the Proxy entity.
-func (m *TrafficMutation) ClearProxy() {
-	m.clearedproxy = true
+// ResetFrameDirection resets all changes to the "frame_direction" field.
+func (m *TrafficMutation) ResetFrameDirection() {
+	m.frame_direction = nil
+	delete(m.clearedFields, traffic.FieldFrameDirection)
 }
 
-// ProxyCleared reports if the "proxy" edge to the Proxy entity was cleared.
-func (m *TrafficMutation) ProxyCleared() bool {
-	return m.clearedproxy
+// SetFrameOpcode sets the "frame_opcode" field.
+func (m *TrafficMutation) SetFrameOpcode(i int) {
+	m.frame_opcode = &i
+	m.addframe_opcode = nil
 }
 
-// ProxyID returns the "proxy" edge ID in the mutation.
-func (m *TrafficMutation) ProxyID() (id int, exists bool) {
-	if m.proxy != nil {
-		return *m.proxy, true
+// FrameOpcode returns the value of the "frame_opcode" field in the mutation.
+func (m *TrafficMutation) FrameOpcode() (r int, exists bool) {
+	v := m.frame_opcode
+	if v == nil {
+		return
 	}
-	return
+	return *v, true
 }
 
-// ProxyIDs returns the "proxy" edge IDs in the mutation.
-// Note that IDs always returns len(IDs) <= 1 for unique edges, and you should use
-// ProxyID instead. It exists only for internal usage by the builders.
-func (m *TrafficMutation) ProxyIDs() (ids []int) {
-	if id := m.proxy; id != nil {
-		ids = append(ids, *id)
+// OldFrameOpcode returns the old "frame_opcode" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldFrameOpcode(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldFrameOpcode is only allowed on UpdateOne operations")
 	}
-	return
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldFrameOpcode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldFrameOpcode: %w", err)
+	}
+	return oldValue.FrameOpcode, nil
 }
 
-// ResetProxy resets all changes to the "proxy" edge.
-func (m *TrafficMutation) ResetProxy() {
-	m.proxy = nil
-	m.clearedproxy = false
+// AddFrameOpcode adds i to the "frame_opcode" field.
+func (m *TrafficMutation) AddFrameOpcode(i int) {
+	if m.addframe_opcode != nil {
+		*m.addframe_opcode += i
+	} else {
+		m.addframe_opcode = &i
+	}
 }
 
-// Where appends a list predicates to the TrafficMutation builder.
-func (m *TrafficMutation) Where(ps ...predicate.Traffic) {
-	m.predicates = append(m.predicates, ps...)
+// AddedFrameOpcode returns the value that was added to the "frame_opcode" field in this mutation.
+func (m *TrafficMutation) AddedFrameOpcode() (r int, exists bool) {
+	v := m.addframe_opcode
+	if v == nil {
+		return
+	}
+	return *v, true
 }
 
-// WhereP appends storage-level predicates to the TrafficMutation builder. Using this method,
-// users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *TrafficMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.Traffic, len(ps))
-	for i := range ps {
-		p[i] = ps[i]
-	}
-	m.Where(p...)
+// ClearFrameOpcode clears the value of the "frame_opcode" field.
+func (m *TrafficMutation) ClearFrameOpcode() {
+	m.frame_opcode = nil
+	m.addframe_opcode = nil
+	m.clearedFields[traffic.FieldFrameOpcode] = struct{}{}
 }
 
-// Op returns the operation name.
-func (m *TrafficMutation) Op() Op {
-	return m.op
+// FrameOpcodeCleared returns if the "frame_opcode" field was cleared in this mutation.
+func (m *TrafficMutation) FrameOpcodeCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldFrameOpcode]
+	return ok
 }
 
-// SetOp allows setting the mutation operation.
-func (m *TrafficMutation) SetOp(op Op) {
-	m.op = op
+// ResetFrameOpcode resets all changes to the "frame_opcode" field.
+func (m *TrafficMutation) ResetFrameOpcode() {
+	m.frame_opcode = nil
+	m.addframe_opcode = nil
+	delete(m.clearedFields, traffic.FieldFrameOpcode)
 }
 
-// Type returns the node type of this mutation (Traffic).
-func (m *TrafficMutation) Type() string {
-	return m.typ
+// SetCloseCode sets the "close_code" field.
+func (m *TrafficMutation) SetCloseCode(i int) {
+	m.close_code = &i
+	m.addclose_code = nil
 }
 
-// Fields returns all fields that were changed during this mutation. Note that in
-// order to get all numeric fields that were incremented/decremented, call
-// AddedFields().
-func (m *TrafficMutation) Fields() []string {
-	fields := make([]string, 0, 25)
-	if m.method != nil {
-		fields = append(fields, traffic.FieldMethod)
-	}
-	if m.url != nil {
-		fields = append(fields, traffic.FieldURL)
+// CloseCode returns the value of the "close_code" field in the mutation.
+func (m *TrafficMutation) CloseCode() (r int, exists bool) {
+	v := m.close_code
+	if v == nil {
+		return
 	}
-	if m.scheme != nil {
-		fields = append(fields, traffic.FieldScheme)
+	return *v, true
+}
+
+// OldCloseCode returns the old "close_code" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldCloseCode(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCloseCode is only allowed on UpdateOne operations")
 	}
-	if m.host != nil {
-		fields = append(fields, traffic.FieldHost)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCloseCode requires an ID field in the mutation")
 	}
-	if m._path != nil {
-		fields = append(fields, traffic.FieldPath)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCloseCode: %w", err)
 	}
-	if m.query != nil {
-		fields = append(fields, traffic.FieldQuery)
+	return oldValue.CloseCode, nil
+}
+
+// AddCloseCode adds i to the "close_code" field.
+func (m *TrafficMutation) AddCloseCode(i int) {
+	if m.addclose_code != nil {
+		*m.addclose_code += i
+	} else {
+		m.addclose_code = &i
 	}
-	if m.request_headers != nil {
-		fields = append(fields, traffic.FieldRequestHeaders)
+}
+
+// AddedCloseCode returns the value that was added to the "close_code" field in this mutation.
+func (m *TrafficMutation) AddedCloseCode() (r int, exists bool) {
+	v := m.addclose_code
+	if v == nil {
+		return
 	}
-	if m.request_body != nil {
-		fields = append(fields, traffic.FieldRequestBody)
+	return *v, true
+}
+
+// ClearCloseCode clears the value of the "close_code" field.
+func (m *TrafficMutation) ClearCloseCode() {
+	m.close_code = nil
+	m.addclose_code = nil
+	m.clearedFields[traffic.FieldCloseCode] = struct{}{}
+}
+
+// CloseCodeCleared returns if the "close_code" field was cleared in this mutation.
+func (m *TrafficMutation) CloseCodeCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldCloseCode]
+	return ok
+}
+
+// ResetCloseCode resets all changes to the "close_code" field.
+func (m *TrafficMutation) ResetCloseCode() {
+	m.close_code = nil
+	m.addclose_code = nil
+	delete(m.clearedFields, traffic.FieldCloseCode)
+}
+
+// SetCloseReason sets the "close_reason" field.
+func (m *TrafficMutation) SetCloseReason(s string) {
+	m.close_reason = &s
+}
+
+// CloseReason returns the value of the "close_reason" field in the mutation.
+func (m *TrafficMutation) CloseReason() (r string, exists bool) {
+	v := m.close_reason
+	if v == nil {
+		return
 	}
-	if m.request_body_size != nil {
-		fields = append(fields, traffic.FieldRequestBodySize)
+	return *v, true
+}
+
+// OldCloseReason returns the old "close_reason" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldCloseReason(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCloseReason is only allowed on UpdateOne operations")
 	}
-	if m.request_is_binary != nil {
-		fields = append(fields, traffic.FieldRequestIsBinary)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCloseReason requires an ID field in the mutation")
 	}
-	if m.content_type != nil {
-		fields = append(fields, traffic.FieldContentType)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCloseReason: %w", err)
 	}
-	if m.status_code != nil {
-		fields = append(fields, traffic.FieldStatusCode)
+	return oldValue.CloseReason, nil
+}
+
+// ClearCloseReason clears the value of the "close_reason" field.
+func (m *TrafficMutation) ClearCloseReason() {
+	m.close_reason = nil
+	m.clearedFields[traffic.FieldCloseReason] = struct{}{}
+}
+
+// CloseReasonCleared returns if the "close_reason" field was cleared in this mutation.
+func (m *TrafficMutation) CloseReasonCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldCloseReason]
+	return ok
+}
+
+// ResetCloseReason resets all changes to the "close_reason" field.
+func (m *TrafficMutation) ResetCloseReason() {
+	m.close_reason = nil
+	delete(m.clearedFields, traffic.FieldCloseReason)
+}
+
+// SetGrpcStatus sets the "grpc_status" field.
+func (m *TrafficMutation) SetGrpcStatus(i int) {
+	m.grpc_status = &i
+	m.addgrpc_status = nil
+}
+
+// GrpcStatus returns the value of the "grpc_status" field in the mutation.
+func (m *TrafficMutation) GrpcStatus() (r int, exists bool) {
+	v := m.grpc_status
+	if v == nil {
+		return
 	}
-	if m.status_text != nil {
-		fields = append(fields, traffic.FieldStatusText)
+	return *v, true
+}
+
+// OldGrpcStatus returns the old "grpc_status" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldGrpcStatus(ctx context.Context) (v *int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldGrpcStatus is only allowed on UpdateOne operations")
 	}
-	if m.response_headers != nil {
-		fields = append(fields, traffic.FieldResponseHeaders)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldGrpcStatus requires an ID field in the mutation")
 	}
-	if m.response_body != nil {
-		fields = append(fields, traffic.FieldResponseBody)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldGrpcStatus: %w", err)
 	}
-	if m.response_body_size != nil {
-		fields = append(fields, traffic.FieldResponseBodySize)
+	return oldValue.GrpcStatus, nil
+}
+
+// AddGrpcStatus adds i to the "grpc_status" field.
+func (m *TrafficMutation) AddGrpcStatus(i int) {
+	if m.addgrpc_status != nil {
+		*m.addgrpc_status += i
+	} else {
+		m.addgrpc_status = &i
 	}
-	if m.response_is_binary != nil {
-		fields = append(fields, traffic.FieldResponseIsBinary)
+}
+
+// AddedGrpcStatus returns the value that was added to the "grpc_status" field in this mutation.
+func (m *TrafficMutation) AddedGrpcStatus() (r int, exists bool) {
+	v := m.addgrpc_status
+	if v == nil {
+		return
 	}
-	if m.response_content_type != nil {
-		fields = append(fields, traffic.FieldResponseContentType)
+	return *v, true
+}
+
+// ClearGrpcStatus clears the value of the "grpc_status" field.
+func (m *TrafficMutation) ClearGrpcStatus() {
+	m.grpc_status = nil
+	m.addgrpc_status = nil
+	m.clearedFields[traffic.FieldGrpcStatus] = struct{}{}
+}
+
+// GrpcStatusCleared returns if the "grpc_status" field was cleared in this mutation.
+func (m *TrafficMutation) GrpcStatusCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldGrpcStatus]
+	return ok
+}
+
+// ResetGrpcStatus resets all changes to the "grpc_status" field.
+func (m *TrafficMutation) ResetGrpcStatus() {
+	m.grpc_status = nil
+	m.addgrpc_status = nil
+	delete(m.clearedFields, traffic.FieldGrpcStatus)
+}
+
+// SetGrpcMessage sets the "grpc_message" field.
+func (m *TrafficMutation) SetGrpcMessage(s string) {
+	m.grpc_message = &s
+}
+
+// GrpcMessage returns the value of the "grpc_message" field in the mutation.
+func (m *TrafficMutation) GrpcMessage() (r string, exists bool) {
+	v := m.grpc_message
+	if v == nil {
+		return
 	}
-	if m.started_at != nil {
-		fields = append(fields, traffic.FieldStartedAt)
+	return *v, true
+}
+
+// OldGrpcMessage returns the old "grpc_message" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldGrpcMessage(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldGrpcMessage is only allowed on UpdateOne operations")
 	}
-	if m.duration_ms != nil {
-		fields = append(fields, traffic.FieldDurationMs)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldGrpcMessage requires an ID field in the mutation")
 	}
-	if m.ttfb_ms != nil {
-		fields = append(fields, traffic.FieldTtfbMs)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldGrpcMessage: %w", err)
 	}
-	if m.client_ip != nil {
-		fields = append(fields, traffic.FieldClientIP)
+	return oldValue.GrpcMessage, nil
+}
+
+// ClearGrpcMessage clears the value of the "grpc_message" field.
+func (m *TrafficMutation) ClearGrpcMessage() {
+	m.grpc_message = nil
+	m.clearedFields[traffic.FieldGrpcMessage] = struct{}{}
+}
+
+// GrpcMessageCleared returns if the "grpc_message" field was cleared in this mutation.
+func (m *TrafficMutation) GrpcMessageCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldGrpcMessage]
+	return ok
+}
+
+// ResetGrpcMessage resets all changes to the "grpc_message" field.
+func (m *TrafficMutation) ResetGrpcMessage() {
+	m.grpc_message = nil
+	delete(m.clearedFields, traffic.FieldGrpcMessage)
+}
+
+// SetClientIP sets the "client_ip" field.
+func (m *TrafficMutation) SetClientIP(s string) {
+	m.client_ip = &s
+}
+
+// ClientIP returns the value of the "client_ip" field in the mutation.
+func (m *TrafficMutation) ClientIP() (r string, exists bool) {
+	v := m.client_ip
+	if v == nil {
+		return
 	}
-	if m.error != nil {
-		fields = append(fields, traffic.FieldError)
+	return *v, true
+}
+
+// OldClientIP returns the old "client_ip" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldClientIP(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClientIP is only allowed on UpdateOne operations")
 	}
-	if m.tags != nil {
-		fields = append(fields, traffic.FieldTags)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClientIP requires an ID field in the mutation")
 	}
-	if m.created_at != nil {
-		fields = append(fields, traffic.FieldCreatedAt)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClientIP: %w", err)
 	}
-	return fields
+	return oldValue.ClientIP, nil
 }
 
-// Field returns the value of a field with the given name. The second boolean
-// return value indicates that this field was not set, or was not defined in the
-// schema.
-func (m *TrafficMutation) Field(name string) (ent.Value, bool) {
-	switch name {
-	case traffic.FieldMethod:
-		return m.Method()
-	case traffic.FieldURL:
-		return m.URL()
-	case traffic.FieldScheme:
-		return m.Scheme()
-	case traffic.FieldHost:
-		return m.Host()
-	case traffic.FieldPath:
-		return m.Path()
-	case traffic.FieldQuery:
-		return m.Query()
-	case traffic.FieldRequestHeaders:
-		return m.RequestHeaders()
-	case traffic.FieldRequestBody:
-		return m.RequestBody()
-	case traffic.FieldRequestBodySize:
-		return m.RequestBodySize()
-	case traffic.FieldRequestIsBinary:
-		return m.RequestIsBinary()
-	case traffic.FieldContentType:
-		return m.ContentType()
-	case traffic.FieldStatusCode:
-		return m.StatusCode()
-	case traffic.FieldStatusText:
-		return m.StatusText()
-	case traffic.FieldResponseHeaders:
-		return m.ResponseHeaders()
-	case traffic.FieldResponseBody:
-		return m.ResponseBody()
-	case traffic.FieldResponseBodySize:
-		return m.ResponseBodySize()
-	case traffic.FieldResponseIsBinary:
-		return m.ResponseIsBinary()
-	case traffic.FieldResponseContentType:
-		return m.ResponseContentType()
-	case traffic.FieldStartedAt:
-		return m.StartedAt()
-	case traffic.FieldDurationMs:
-		return m.DurationMs()
-	case traffic.FieldTtfbMs:
-		return m.TtfbMs()
-	case traffic.FieldClientIP:
-		return m.ClientIP()
-	case traffic.FieldError:
-		return m.Error()
-	case traffic.FieldTags:
-		return m.Tags()
-	case traffic.FieldCreatedAt:
-		return m.CreatedAt()
-	}
-	return nil, false
+// ClearClientIP clears the value of the "client_ip" field.
+func (m *TrafficMutation) ClearClientIP() {
+	m.client_ip = nil
+	m.clearedFields[traffic.FieldClientIP] = struct{}{}
 }
 
-// OldField returns the old value of the field from the database. An error is
-// returned if the mutation operation is not UpdateOne, or the query to the
+// ClientIPCleared returns if the "client_ip" field was cleared in this mutation.
+func (m *TrafficMutation) ClientIPCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldClientIP]
+	return ok
+}
+
+// ResetClientIP resets all changes to the "client_ip" field.
+func (m *TrafficMutation) ResetClientIP() {
+	m.client_ip = nil
+	delete(m.clearedFields, traffic.FieldClientIP)
+}
+
+// SetAuthUser sets the "auth_user" field.
+func (m *TrafficMutation) SetAuthUser(s string) {
+	m.auth_user = &s
+}
+
+// AuthUser returns the value of the "auth_user" field in the mutation.
+func (m *TrafficMutation) AuthUser() (r string, exists bool) {
+	v := m.auth_user
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAuthUser returns the old "auth_user" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldAuthUser(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAuthUser is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAuthUser requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAuthUser: %w", err)
+	}
+	return oldValue.AuthUser, nil
+}
+
+// ClearAuthUser clears the value of the "auth_user" field.
+func (m *TrafficMutation) ClearAuthUser() {
+	m.auth_user = nil
+	m.clearedFields[traffic.FieldAuthUser] = struct{}{}
+}
+
+// AuthUserCleared returns if the "auth_user" field was cleared in this mutation.
+func (m *TrafficMutation) AuthUserCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldAuthUser]
+	return ok
+}
+
+// ResetAuthUser resets all changes to the "auth_user" field.
+func (m *TrafficMutation) ResetAuthUser() {
+	m.auth_user = nil
+	delete(m.clearedFields, traffic.FieldAuthUser)
+}
+
+// SetError sets the "error" field.
+func (m *TrafficMutation) SetError(s string) {
+	m.error = &s
+}
+
+// Error returns the value of the "error" field in the mutation.
+func (m *TrafficMutation) Error() (r string, exists bool) {
+	v := m.error
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldError returns the old "error" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldError(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldError is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldError requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldError: %w", err)
+	}
+	return oldValue.Error, nil
+}
+
+// ClearError clears the value of the "error" field.
+func (m *TrafficMutation) ClearError() {
+	m.error = nil
+	m.clearedFields[traffic.FieldError] = struct{}{}
+}
+
+// ErrorCleared returns if the "error" field was cleared in this mutation.
+func (m *TrafficMutation) ErrorCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldError]
+	return ok
+}
+
+// ResetError resets all changes to the "error" field.
+func (m *TrafficMutation) ResetError() {
+	m.error = nil
+	delete(m.clearedFields, traffic.FieldError)
+}
+
+// SetTags sets the "tags" field.
+func (m *TrafficMutation) SetTags(s []string) {
+	m.tags = &s
+	m.appendtags = nil
+}
+
+// Tags returns the value of the "tags" field in the mutation.
+func (m *TrafficMutation) Tags() (r []string, exists bool) {
+	v := m.tags
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTags returns the old "tags" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldTags(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTags is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTags requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTags: %w", err)
+	}
+	return oldValue.Tags, nil
+}
+
+// AppendTags adds s to the "tags" field.
+func (m *TrafficMutation) AppendTags(s []string) {
+	m.appendtags = append(m.appendtags, s...)
+}
+
+// AppendedTags returns the list of values that were appended to the "tags" field in this mutation.
+func (m *TrafficMutation) AppendedTags() ([]string, bool) {
+	if len(m.appendtags) == 0 {
+		return nil, false
+	}
+	return m.appendtags, true
+}
+
+// ClearTags clears the value of the "tags" field.
+func (m *TrafficMutation) ClearTags() {
+	m.tags = nil
+	m.appendtags = nil
+	m.clearedFields[traffic.FieldTags] = struct{}{}
+}
+
+// TagsCleared returns if the "tags" field was cleared in this mutation.
+func (m *TrafficMutation) TagsCleared() bool {
+	_, ok := m.clearedFields[traffic.FieldTags]
+	return ok
+}
+
+// ResetTags resets all changes to the "tags" field.
+func (m *TrafficMutation) ResetTags() {
+	m.tags = nil
+	m.appendtags = nil
+	delete(m.clearedFields, traffic.FieldTags)
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *TrafficMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *TrafficMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the Traffic entity.
+// If the Traffic object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *TrafficMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// SetProxyID sets the "proxy" edge to the Proxy entity by id.
+func (m *TrafficMutation) SetProxyID(id int) {
+	m.proxy = &id
+}
+
+// ClearProxy clears the "proxy" edge to the Proxy entity.
+func (m *TrafficMutation) ClearProxy() {
+	m.clearedproxy = true
+}
+
+// ProxyCleared reports if the "proxy" edge to the Proxy entity was cleared.
+func (m *TrafficMutation) ProxyCleared() bool {
+	return m.clearedproxy
+}
+
+// ProxyID returns the "proxy" edge ID in the mutation.
+func (m *TrafficMutation) ProxyID() (id int, exists bool) {
+	if m.proxy != nil {
+		return *m.proxy, true
+	}
+	return
+}
+
+// ProxyIDs returns the "proxy" edge IDs in the mutation.
+// Note that IDs always returns len(IDs) <= 1 for unique edges, and you should use
+// ProxyID instead. It exists only for internal usage by the builders.
+func (m *TrafficMutation) ProxyIDs() (ids []int) {
+	if id := m.proxy; id != nil {
+		ids = append(ids, *id)
+	}
+	return
+}
+
+// ResetProxy resets all changes to the "proxy" edge.
+func (m *TrafficMutation) ResetProxy() {
+	m.proxy = nil
+	m.clearedproxy = false
+}
+
+// Where appends a list predicates to the TrafficMutation builder.
+func (m *TrafficMutation) Where(ps ...predicate.Traffic) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the TrafficMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *TrafficMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Traffic, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *TrafficMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *TrafficMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Traffic).
+func (m *TrafficMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *TrafficMutation) Fields() []string {
+	fields := make([]string, 0, 45)
+	if m.method != nil {
+		fields = append(fields, traffic.FieldMethod)
+	}
+	if m.url != nil {
+		fields = append(fields, traffic.FieldURL)
+	}
+	if m.scheme != nil {
+		fields = append(fields, traffic.FieldScheme)
+	}
+	if m.host != nil {
+		fields = append(fields, traffic.FieldHost)
+	}
+	if m._path != nil {
+		fields = append(fields, traffic.FieldPath)
+	}
+	if m.query != nil {
+		fields = append(fields, traffic.FieldQuery)
+	}
+	if m.request_headers != nil {
+		fields = append(fields, traffic.FieldRequestHeaders)
+	}
+	if m.request_header_names != nil {
+		fields = append(fields, traffic.FieldRequestHeaderNames)
+	}
+	if m.request_body != nil {
+		fields = append(fields, traffic.FieldRequestBody)
+	}
+	if m.request_body_size != nil {
+		fields = append(fields, traffic.FieldRequestBodySize)
+	}
+	if m.request_is_binary != nil {
+		fields = append(fields, traffic.FieldRequestIsBinary)
+	}
+	if m.request_body_truncated != nil {
+		fields = append(fields, traffic.FieldRequestBodyTruncated)
+	}
+	if m.request_decoded_size != nil {
+		fields = append(fields, traffic.FieldRequestDecodedSize)
+	}
+	if m.content_type != nil {
+		fields = append(fields, traffic.FieldContentType)
+	}
+	if m.http_version != nil {
+		fields = append(fields, traffic.FieldHTTPVersion)
+	}
+	if m.stream_id != nil {
+		fields = append(fields, traffic.FieldStreamID)
+	}
+	if m.status_code != nil {
+		fields = append(fields, traffic.FieldStatusCode)
+	}
+	if m.status_text != nil {
+		fields = append(fields, traffic.FieldStatusText)
+	}
+	if m.response_http_version != nil {
+		fields = append(fields, traffic.FieldResponseHTTPVersion)
+	}
+	if m.response_headers != nil {
+		fields = append(fields, traffic.FieldResponseHeaders)
+	}
+	if m.response_header_names != nil {
+		fields = append(fields, traffic.FieldResponseHeaderNames)
+	}
+	if m.response_body != nil {
+		fields = append(fields, traffic.FieldResponseBody)
+	}
+	if m.response_body_size != nil {
+		fields = append(fields, traffic.FieldResponseBodySize)
+	}
+	if m.response_is_binary != nil {
+		fields = append(fields, traffic.FieldResponseIsBinary)
+	}
+	if m.response_body_truncated != nil {
+		fields = append(fields, traffic.FieldResponseBodyTruncated)
+	}
+	if m.response_decoded_size != nil {
+		fields = append(fields, traffic.FieldResponseDecodedSize)
+	}
+	if m.response_events != nil {
+		fields = append(fields, traffic.FieldResponseEvents)
+	}
+	if m.response_content_type != nil {
+		fields = append(fields, traffic.FieldResponseContentType)
+	}
+	if m.started_at != nil {
+		fields = append(fields, traffic.FieldStartedAt)
+	}
+	if m.duration_ms != nil {
+		fields = append(fields, traffic.FieldDurationMs)
+	}
+	if m.ttfb_ms != nil {
+		fields = append(fields, traffic.FieldTtfbMs)
+	}
+	if m.record_type != nil {
+		fields = append(fields, traffic.FieldRecordType)
+	}
+	if m.connection_id != nil {
+		fields = append(fields, traffic.FieldConnectionID)
+	}
+	if m.frame_sequence != nil {
+		fields = append(fields, traffic.FieldFrameSequence)
+	}
+	if m.frame_direction != nil {
+		fields = append(fields, traffic.FieldFrameDirection)
+	}
+	if m.frame_opcode != nil {
+		fields = append(fields, traffic.FieldFrameOpcode)
+	}
+	if m.close_code != nil {
+		fields = append(fields, traffic.FieldCloseCode)
+	}
+	if m.close_reason != nil {
+		fields = append(fields, traffic.FieldCloseReason)
+	}
+	if m.grpc_status != nil {
+		fields = append(fields, traffic.FieldGrpcStatus)
+	}
+	if m.grpc_message != nil {
+		fields = append(fields, traffic.FieldGrpcMessage)
+	}
+	if m.client_ip != nil {
+		fields = append(fields, traffic.FieldClientIP)
+	}
+	if m.auth_user != nil {
+		fields = append(fields, traffic.FieldAuthUser)
+	}
+	if m.error != nil {
+		fields = append(fields, traffic.FieldError)
+	}
+	if m.tags != nil {
+		fields = append(fields, traffic.FieldTags)
+	}
+	if m.created_at != nil {
+		fields = append(fields, traffic.FieldCreatedAt)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *TrafficMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case traffic.FieldMethod:
+		return m.Method()
+	case traffic.FieldURL:
+		return m.URL()
+	case traffic.FieldScheme:
+		return m.Scheme()
+	case traffic.FieldHost:
+		return m.Host()
+	case traffic.FieldPath:
+		return m.Path()
+	case traffic.FieldQuery:
+		return m.Query()
+	case traffic.FieldRequestHeaders:
+		return m.RequestHeaders()
+	case traffic.FieldRequestHeaderNames:
+		return m.RequestHeaderNames()
+	case traffic.FieldRequestBody:
+		return m.RequestBody()
+	case traffic.FieldRequestBodySize:
+		return m.RequestBodySize()
+	case traffic.FieldRequestIsBinary:
+		return m.RequestIsBinary()
+	case traffic.FieldRequestBodyTruncated:
+		return m.RequestBodyTruncated()
+	case traffic.FieldRequestDecodedSize:
+		return m.RequestDecodedSize()
+	case traffic.FieldContentType:
+		return m.ContentType()
+	case traffic.FieldHTTPVersion:
+		return m.HTTPVersion()
+	case traffic.FieldStreamID:
+		return m.StreamID()
+	case traffic.FieldStatusCode:
+		return m.StatusCode()
+	case traffic.FieldStatusText:
+		return m.StatusText()
+	case traffic.FieldResponseHTTPVersion:
+		return m.ResponseHTTPVersion()
+	case traffic.FieldResponseHeaders:
+		return m.ResponseHeaders()
+	case traffic.FieldResponseHeaderNames:
+		return m.ResponseHeaderNames()
+	case traffic.FieldResponseBody:
+		return m.ResponseBody()
+	case traffic.FieldResponseBodySize:
+		return m.ResponseBodySize()
+	case traffic.FieldResponseIsBinary:
+		return m.ResponseIsBinary()
+	case traffic.FieldResponseBodyTruncated:
+		return m.ResponseBodyTruncated()
+	case traffic.FieldResponseDecodedSize:
+		return m.ResponseDecodedSize()
+	case traffic.FieldResponseEvents:
+		return m.ResponseEvents()
+	case traffic.FieldResponseContentType:
+		return m.ResponseContentType()
+	case traffic.FieldStartedAt:
+		return m.StartedAt()
+	case traffic.FieldDurationMs:
+		return m.DurationMs()
+	case traffic.FieldTtfbMs:
+		return m.TtfbMs()
+	case traffic.FieldRecordType:
+		return m.RecordType()
+	case traffic.FieldConnectionID:
+		return m.ConnectionID()
+	case traffic.FieldFrameSequence:
+		return m.FrameSequence()
+	case traffic.FieldFrameDirection:
+		return m.FrameDirection()
+	case traffic.FieldFrameOpcode:
+		return m.FrameOpcode()
+	case traffic.FieldCloseCode:
+		return m.CloseCode()
+	case traffic.FieldCloseReason:
+		return m.CloseReason()
+	case traffic.FieldGrpcStatus:
+		return m.GrpcStatus()
+	case traffic.FieldGrpcMessage:
+		return m.GrpcMessage()
+	case traffic.FieldClientIP:
+		return m.ClientIP()
+	case traffic.FieldAuthUser:
+		return m.AuthUser()
+	case traffic.FieldError:
+		return m.Error()
+	case traffic.FieldTags:
+		return m.Tags()
+	case traffic.FieldCreatedAt:
+		return m.CreatedAt()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
 // database failed.
 func (m *TrafficMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
 	switch name {
 	case traffic.FieldMethod:
-		return m.OldMethod(ctx)
+		return m.OldMethod(ctx)
+	case traffic.FieldURL:
+		return m.OldURL(ctx)
+	case traffic.FieldScheme:
+		return m.OldScheme(ctx)
+	case traffic.FieldHost:
+		return m.OldHost(ctx)
+	case traffic.FieldPath:
+		return m.OldPath(ctx)
+	case traffic.FieldQuery:
+		return m.OldQuery(ctx)
+	case traffic.FieldRequestHeaders:
+		return m.OldRequestHeaders(ctx)
+	case traffic.FieldRequestHeaderNames:
+		return m.OldRequestHeaderNames(ctx)
+	case traffic.FieldRequestBody:
+		return m.OldRequestBody(ctx)
+	case traffic.FieldRequestBodySize:
+		return m.OldRequestBodySize(ctx)
+	case traffic.FieldRequestIsBinary:
+		return m.OldRequestIsBinary(ctx)
+	case traffic.FieldRequestBodyTruncated:
+		return m.OldRequestBodyTruncated(ctx)
+	case traffic.FieldRequestDecodedSize:
+		return m.OldRequestDecodedSize(ctx)
+	case traffic.FieldContentType:
+		return m.OldContentType(ctx)
+	case traffic.FieldHTTPVersion:
+		return m.OldHTTPVersion(ctx)
+	case traffic.FieldStreamID:
+		return m.OldStreamID(ctx)
+	case traffic.FieldStatusCode:
+		return m.OldStatusCode(ctx)
+	case traffic.FieldStatusText:
+		return m.OldStatusText(ctx)
+	case traffic.FieldResponseHTTPVersion:
+		return m.OldResponseHTTPVersion(ctx)
+	case traffic.FieldResponseHeaders:
+		return m.OldResponseHeaders(ctx)
+	case traffic.FieldResponseHeaderNames:
+		return m.OldResponseHeaderNames(ctx)
+	case traffic.FieldResponseBody:
+		return m.OldResponseBody(ctx)
+	case traffic.FieldResponseBodySize:
+		return m.OldResponseBodySize(ctx)
+	case traffic.FieldResponseIsBinary:
+		return m.OldResponseIsBinary(ctx)
+	case traffic.FieldResponseBodyTruncated:
+		return m.OldResponseBodyTruncated(ctx)
+	case traffic.FieldResponseDecodedSize:
+		return m.OldResponseDecodedSize(ctx)
+	case traffic.FieldResponseEvents:
+		return m.OldResponseEvents(ctx)
+	case traffic.FieldResponseContentType:
+		return m.OldResponseContentType(ctx)
+	case traffic.FieldStartedAt:
+		return m.OldStartedAt(ctx)
+	case traffic.FieldDurationMs:
+		return m.OldDurationMs(ctx)
+	case traffic.FieldTtfbMs:
+		return m.OldTtfbMs(ctx)
+	case traffic.FieldRecordType:
+		return m.OldRecordType(ctx)
+	case traffic.FieldConnectionID:
+		return m.OldConnectionID(ctx)
+	case traffic.FieldFrameSequence:
+		return m.OldFrameSequence(ctx)
+	case traffic.FieldFrameDirection:
+		return m.OldFrameDirection(ctx)
+	case traffic.FieldFrameOpcode:
+		return m.OldFrameOpcode(ctx)
+	case traffic.FieldCloseCode:
+		return m.OldCloseCode(ctx)
+	case traffic.FieldCloseReason:
+		return m.OldCloseReason(ctx)
+	case traffic.FieldGrpcStatus:
+		return m.OldGrpcStatus(ctx)
+	case traffic.FieldGrpcMessage:
+		return m.OldGrpcMessage(ctx)
+	case traffic.FieldClientIP:
+		return m.OldClientIP(ctx)
+	case traffic.FieldAuthUser:
+		return m.OldAuthUser(ctx)
+	case traffic.FieldError:
+		return m.OldError(ctx)
+	case traffic.FieldTags:
+		return m.OldTags(ctx)
+	case traffic.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	}
+	return nil, fmt.Errorf("unknown Traffic field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *TrafficMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case traffic.FieldMethod:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMethod(v)
+		return nil
+	case traffic.FieldURL:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetURL(v)
+		return nil
+	case traffic.FieldScheme:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetScheme(v)
+		return nil
+	case traffic.FieldHost:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHost(v)
+		return nil
+	case traffic.FieldPath:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPath(v)
+		return nil
+	case traffic.FieldQuery:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetQuery(v)
+		return nil
+	case traffic.FieldRequestHeaders:
+		v, ok := value.(map[string][]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestHeaders(v)
+		return nil
+	case traffic.FieldRequestHeaderNames:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestHeaderNames(v)
+		return nil
+	case traffic.FieldRequestBody:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestBody(v)
+		return nil
+	case traffic.FieldRequestBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestBodySize(v)
+		return nil
+	case traffic.FieldRequestIsBinary:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestIsBinary(v)
+		return nil
+	case traffic.FieldRequestBodyTruncated:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestBodyTruncated(v)
+		return nil
+	case traffic.FieldRequestDecodedSize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestDecodedSize(v)
+		return nil
+	case traffic.FieldContentType:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContentType(v)
+		return nil
+	case traffic.FieldHTTPVersion:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHTTPVersion(v)
+		return nil
+	case traffic.FieldStreamID:
+		v, ok := value.(uint32)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStreamID(v)
+		return nil
+	case traffic.FieldStatusCode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStatusCode(v)
+		return nil
+	case traffic.FieldStatusText:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStatusText(v)
+		return nil
+	case traffic.FieldResponseHTTPVersion:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseHTTPVersion(v)
+		return nil
+	case traffic.FieldResponseHeaders:
+		v, ok := value.(map[string][]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseHeaders(v)
+		return nil
+	case traffic.FieldResponseHeaderNames:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseHeaderNames(v)
+		return nil
+	case traffic.FieldResponseBody:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseBody(v)
+		return nil
+	case traffic.FieldResponseBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseBodySize(v)
+		return nil
+	case traffic.FieldResponseIsBinary:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseIsBinary(v)
+		return nil
+	case traffic.FieldResponseBodyTruncated:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseBodyTruncated(v)
+		return nil
+	case traffic.FieldResponseDecodedSize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseDecodedSize(v)
+		return nil
+	case traffic.FieldResponseEvents:
+		v, ok := value.([]capture.StreamEvent)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseEvents(v)
+		return nil
+	case traffic.FieldResponseContentType:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseContentType(v)
+		return nil
+	case traffic.FieldStartedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStartedAt(v)
+		return nil
+	case traffic.FieldDurationMs:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDurationMs(v)
+		return nil
+	case traffic.FieldTtfbMs:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTtfbMs(v)
+		return nil
+	case traffic.FieldRecordType:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRecordType(v)
+		return nil
+	case traffic.FieldConnectionID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConnectionID(v)
+		return nil
+	case traffic.FieldFrameSequence:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetFrameSequence(v)
+		return nil
+	case traffic.FieldFrameDirection:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetFrameDirection(v)
+		return nil
+	case traffic.FieldFrameOpcode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetFrameOpcode(v)
+		return nil
+	case traffic.FieldCloseCode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCloseCode(v)
+		return nil
+	case traffic.FieldCloseReason:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCloseReason(v)
+		return nil
+	case traffic.FieldGrpcStatus:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetGrpcStatus(v)
+		return nil
+	case traffic.FieldGrpcMessage:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetGrpcMessage(v)
+		return nil
+	case traffic.FieldClientIP:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClientIP(v)
+		return nil
+	case traffic.FieldAuthUser:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAuthUser(v)
+		return nil
+	case traffic.FieldError:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetError(v)
+		return nil
+	case traffic.FieldTags:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTags(v)
+		return nil
+	case traffic.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *TrafficMutation) AddedFields() []string {
+	var fields []string
+	if m.addrequest_body_size != nil {
+		fields = append(fields, traffic.FieldRequestBodySize)
+	}
+	if m.addrequest_decoded_size != nil {
+		fields = append(fields, traffic.FieldRequestDecodedSize)
+	}
+	if m.addstream_id != nil {
+		fields = append(fields, traffic.FieldStreamID)
+	}
+	if m.addstatus_code != nil {
+		fields = append(fields, traffic.FieldStatusCode)
+	}
+	if m.addresponse_body_size != nil {
+		fields = append(fields, traffic.FieldResponseBodySize)
+	}
+	if m.addresponse_decoded_size != nil {
+		fields = append(fields, traffic.FieldResponseDecodedSize)
+	}
+	if m.addduration_ms != nil {
+		fields = append(fields, traffic.FieldDurationMs)
+	}
+	if m.addttfb_ms != nil {
+		fields = append(fields, traffic.FieldTtfbMs)
+	}
+	if m.addframe_sequence != nil {
+		fields = append(fields, traffic.FieldFrameSequence)
+	}
+	if m.addframe_opcode != nil {
+		fields = append(fields, traffic.FieldFrameOpcode)
+	}
+	if m.addclose_code != nil {
+		fields = append(fields, traffic.FieldCloseCode)
+	}
+	if m.addgrpc_status != nil {
+		fields = append(fields, traffic.FieldGrpcStatus)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *TrafficMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case traffic.FieldRequestBodySize:
+		return m.AddedRequestBodySize()
+	case traffic.FieldRequestDecodedSize:
+		return m.AddedRequestDecodedSize()
+	case traffic.FieldStreamID:
+		return m.AddedStreamID()
+	case traffic.FieldStatusCode:
+		return m.AddedStatusCode()
+	case traffic.FieldResponseBodySize:
+		return m.AddedResponseBodySize()
+	case traffic.FieldResponseDecodedSize:
+		return m.AddedResponseDecodedSize()
+	case traffic.FieldDurationMs:
+		return m.AddedDurationMs()
+	case traffic.FieldTtfbMs:
+		return m.AddedTtfbMs()
+	case traffic.FieldFrameSequence:
+		return m.AddedFrameSequence()
+	case traffic.FieldFrameOpcode:
+		return m.AddedFrameOpcode()
+	case traffic.FieldCloseCode:
+		return m.AddedCloseCode()
+	case traffic.FieldGrpcStatus:
+		return m.AddedGrpcStatus()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *TrafficMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case traffic.FieldRequestBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRequestBodySize(v)
+		return nil
+	case traffic.FieldRequestDecodedSize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRequestDecodedSize(v)
+		return nil
+	case traffic.FieldStreamID:
+		v, ok := value.(int32)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddStreamID(v)
+		return nil
+	case traffic.FieldStatusCode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddStatusCode(v)
+		return nil
+	case traffic.FieldResponseBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseBodySize(v)
+		return nil
+	case traffic.FieldResponseDecodedSize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseDecodedSize(v)
+		return nil
+	case traffic.FieldDurationMs:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddDurationMs(v)
+		return nil
+	case traffic.FieldTtfbMs:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTtfbMs(v)
+		return nil
+	case traffic.FieldFrameSequence:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddFrameSequence(v)
+		return nil
+	case traffic.FieldFrameOpcode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddFrameOpcode(v)
+		return nil
+	case traffic.FieldCloseCode:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddCloseCode(v)
+		return nil
+	case traffic.FieldGrpcStatus:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddGrpcStatus(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *TrafficMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(traffic.FieldQuery) {
+		fields = append(fields, traffic.FieldQuery)
+	}
+	if m.FieldCleared(traffic.FieldRequestHeaders) {
+		fields = append(fields, traffic.FieldRequestHeaders)
+	}
+	if m.FieldCleared(traffic.FieldRequestHeaderNames) {
+		fields = append(fields, traffic.FieldRequestHeaderNames)
+	}
+	if m.FieldCleared(traffic.FieldRequestBody) {
+		fields = append(fields, traffic.FieldRequestBody)
+	}
+	if m.FieldCleared(traffic.FieldContentType) {
+		fields = append(fields, traffic.FieldContentType)
+	}
+	if m.FieldCleared(traffic.FieldHTTPVersion) {
+		fields = append(fields, traffic.FieldHTTPVersion)
+	}
+	if m.FieldCleared(traffic.FieldStreamID) {
+		fields = append(fields, traffic.FieldStreamID)
+	}
+	if m.FieldCleared(traffic.FieldStatusText) {
+		fields = append(fields, traffic.FieldStatusText)
+	}
+	if m.FieldCleared(traffic.FieldResponseHTTPVersion) {
+		fields = append(fields, traffic.FieldResponseHTTPVersion)
+	}
+	if m.FieldCleared(traffic.FieldResponseHeaders) {
+		fields = append(fields, traffic.FieldResponseHeaders)
+	}
+	if m.FieldCleared(traffic.FieldResponseHeaderNames) {
+		fields = append(fields, traffic.FieldResponseHeaderNames)
+	}
+	if m.FieldCleared(traffic.FieldResponseBody) {
+		fields = append(fields, traffic.FieldResponseBody)
+	}
+	if m.FieldCleared(traffic.FieldResponseEvents) {
+		fields = append(fields, traffic.FieldResponseEvents)
+	}
+	if m.FieldCleared(traffic.FieldResponseContentType) {
+		fields = append(fields, traffic.FieldResponseContentType)
+	}
+	if m.FieldCleared(traffic.FieldTtfbMs) {
+		fields = append(fields, traffic.FieldTtfbMs)
+	}
+	if m.FieldCleared(traffic.FieldConnectionID) {
+		fields = append(fields, traffic.FieldConnectionID)
+	}
+	if m.FieldCleared(traffic.FieldFrameSequence) {
+		fields = append(fields, traffic.FieldFrameSequence)
+	}
+	if m.FieldCleared(traffic.FieldFrameDirection) {
+		fields = append(fields, traffic.FieldFrameDirection)
+	}
+	if m.FieldCleared(traffic.FieldFrameOpcode) {
+		fields = append(fields, traffic.FieldFrameOpcode)
+	}
+	if m.FieldCleared(traffic.FieldCloseCode) {
+		fields = append(fields, traffic.FieldCloseCode)
+	}
+	if m.FieldCleared(traffic.FieldCloseReason) {
+		fields = append(fields, traffic.FieldCloseReason)
+	}
+	if m.FieldCleared(traffic.FieldGrpcStatus) {
+		fields = append(fields, traffic.FieldGrpcStatus)
+	}
+	if m.FieldCleared(traffic.FieldGrpcMessage) {
+		fields = append(fields, traffic.FieldGrpcMessage)
+	}
+	if m.FieldCleared(traffic.FieldClientIP) {
+		fields = append(fields, traffic.FieldClientIP)
+	}
+	if m.FieldCleared(traffic.FieldAuthUser) {
+		fields = append(fields, traffic.FieldAuthUser)
+	}
+	if m.FieldCleared(traffic.FieldError) {
+		fields = append(fields, traffic.FieldError)
+	}
+	if m.FieldCleared(traffic.FieldTags) {
+		fields = append(fields, traffic.FieldTags)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *TrafficMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *TrafficMutation) ClearField(name string) error {
+	switch name {
+	case traffic.FieldQuery:
+		m.ClearQuery()
+		return nil
+	case traffic.FieldRequestHeaders:
+		m.ClearRequestHeaders()
+		return nil
+	case traffic.FieldRequestHeaderNames:
+		m.ClearRequestHeaderNames()
+		return nil
+	case traffic.FieldRequestBody:
+		m.ClearRequestBody()
+		return nil
+	case traffic.FieldContentType:
+		m.ClearContentType()
+		return nil
+	case traffic.FieldHTTPVersion:
+		m.ClearHTTPVersion()
+		return nil
+	case traffic.FieldStreamID:
+		m.ClearStreamID()
+		return nil
+	case traffic.FieldStatusText:
+		m.ClearStatusText()
+		return nil
+	case traffic.FieldResponseHTTPVersion:
+		m.ClearResponseHTTPVersion()
+		return nil
+	case traffic.FieldResponseHeaders:
+		m.ClearResponseHeaders()
+		return nil
+	case traffic.FieldResponseHeaderNames:
+		m.ClearResponseHeaderNames()
+		return nil
+	case traffic.FieldResponseBody:
+		m.ClearResponseBody()
+		return nil
+	case traffic.FieldResponseEvents:
+		m.ClearResponseEvents()
+		return nil
+	case traffic.FieldResponseContentType:
+		m.ClearResponseContentType()
+		return nil
+	case traffic.FieldTtfbMs:
+		m.ClearTtfbMs()
+		return nil
+	case traffic.FieldConnectionID:
+		m.ClearConnectionID()
+		return nil
+	case traffic.FieldFrameSequence:
+		m.ClearFrameSequence()
+		return nil
+	case traffic.FieldFrameDirection:
+		m.ClearFrameDirection()
+		return nil
+	case traffic.FieldFrameOpcode:
+		m.ClearFrameOpcode()
+		return nil
+	case traffic.FieldCloseCode:
+		m.ClearCloseCode()
+		return nil
+	case traffic.FieldCloseReason:
+		m.ClearCloseReason()
+		return nil
+	case traffic.FieldGrpcStatus:
+		m.ClearGrpcStatus()
+		return nil
+	case traffic.FieldGrpcMessage:
+		m.ClearGrpcMessage()
+		return nil
+	case traffic.FieldClientIP:
+		m.ClearClientIP()
+		return nil
+	case traffic.FieldAuthUser:
+		m.ClearAuthUser()
+		return nil
+	case traffic.FieldError:
+		m.ClearError()
+		return nil
+	case traffic.FieldTags:
+		m.ClearTags()
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *TrafficMutation) ResetField(name string) error {
+	switch name {
+	case traffic.FieldMethod:
+		m.ResetMethod()
+		return nil
 	case traffic.FieldURL:
-		return m.OldURL(ctx)
+		m.ResetURL()
+		return nil
 	case traffic.FieldScheme:
-		return m.OldScheme(ctx)
+		m.ResetScheme()
+		return nil
 	case traffic.FieldHost:
-		return m.OldHost(ctx)
+		m.ResetHost()
+		return nil
 	case traffic.FieldPath:
-		return m.OldPath(ctx)
+		m.ResetPath()
+		return nil
 	case traffic.FieldQuery:
-		return m.OldQuery(ctx)
+		m.ResetQuery()
+		return nil
 	case traffic.FieldRequestHeaders:
-		return m.OldRequestHeaders(ctx)
+		m.ResetRequestHeaders()
+		return nil
+	case traffic.FieldRequestHeaderNames:
+		m.ResetRequestHeaderNames()
+		return nil
 	case traffic.FieldRequestBody:
-		return m.OldRequestBody(ctx)
+		m.ResetRequestBody()
+		return nil
 	case traffic.FieldRequestBodySize:
-		return m.OldRequestBodySize(ctx)
+		m.ResetRequestBodySize()
+		return nil
 	case traffic.FieldRequestIsBinary:
-		return m.OldRequestIsBinary(ctx)
+		m.ResetRequestIsBinary()
+		return nil
+	case traffic.FieldRequestBodyTruncated:
+		m.ResetRequestBodyTruncated()
+		return nil
+	case traffic.FieldRequestDecodedSize:
+		m.ResetRequestDecodedSize()
+		return nil
 	case traffic.FieldContentType:
-		return m.OldContentType(ctx)
+		m.ResetContentType()
+		return nil
+	case traffic.FieldHTTPVersion:
+		m.ResetHTTPVersion()
+		return nil
+	case traffic.FieldStreamID:
+		m.ResetStreamID()
+		return nil
 	case traffic.FieldStatusCode:
-		return m.OldStatusCode(ctx)
+		m.ResetStatusCode()
+		return nil
 	case traffic.FieldStatusText:
-		return m.OldStatusText(ctx)
+		m.ResetStatusText()
+		return nil
+	case traffic.FieldResponseHTTPVersion:
+		m.ResetResponseHTTPVersion()
+		return nil
 	case traffic.FieldResponseHeaders:
-		return m.OldResponseHeaders(ctx)
+		m.ResetResponseHeaders()
+		return nil
+	case traffic.FieldResponseHeaderNames:
+		m.ResetResponseHeaderNames()
+		return nil
 	case traffic.FieldResponseBody:
-		return m.OldResponseBody(ctx)
+		m.ResetResponseBody()
+		return nil
 	case traffic.FieldResponseBodySize:
-		return m.OldResponseBodySize(ctx)
+		m.ResetResponseBodySize()
+		return nil
 	case traffic.FieldResponseIsBinary:
-		return m.OldResponseIsBinary(ctx)
+		m.ResetResponseIsBinary()
+		return nil
+	case traffic.FieldResponseBodyTruncated:
+		m.ResetResponseBodyTruncated()
+		return nil
+	case traffic.FieldResponseDecodedSize:
+		m.ResetResponseDecodedSize()
+		return nil
+	case traffic.FieldResponseEvents:
+		m.ResetResponseEvents()
+		return nil
 	case traffic.FieldResponseContentType:
-		return m.OldResponseContentType(ctx)
+		m.ResetResponseContentType()
+		return nil
 	case traffic.FieldStartedAt:
-		return m.OldStartedAt(ctx)
+		m.ResetStartedAt()
+		return nil
 	case traffic.FieldDurationMs:
-		return m.OldDurationMs(ctx)
+		m.ResetDurationMs()
+		return nil
 	case traffic.FieldTtfbMs:
-		return m.OldTtfbMs(ctx)
+		m.ResetTtfbMs()
+		return nil
+	case traffic.FieldRecordType:
+		m.ResetRecordType()
+		return nil
+	case traffic.FieldConnectionID:
+		m.ResetConnectionID()
+		return nil
+	case traffic.FieldFrameSequence:
+		m.ResetFrameSequence()
+		return nil
+	case traffic.FieldFrameDirection:
+		m.ResetFrameDirection()
+		return nil
+	case traffic.FieldFrameOpcode:
+		m.ResetFrameOpcode()
+		return nil
+	case traffic.FieldCloseCode:
+		m.ResetCloseCode()
+		return nil
+	case traffic.FieldCloseReason:
+		m.ResetCloseReason()
+		return nil
+	case traffic.FieldGrpcStatus:
+		m.ResetGrpcStatus()
+		return nil
+	case traffic.FieldGrpcMessage:
+		m.ResetGrpcMessage()
+		return nil
 	case traffic.FieldClientIP:
-		return m.OldClientIP(ctx)
+		m.ResetClientIP()
+		return nil
+	case traffic.FieldAuthUser:
+		m.ResetAuthUser()
+		return nil
 	case traffic.FieldError:
-		return m.OldError(ctx)
+		m.ResetError()
+		return nil
 	case traffic.FieldTags:
-		return m.OldTags(ctx)
+		m.ResetTags()
+		return nil
 	case traffic.FieldCreatedAt:
-		return m.OldCreatedAt(ctx)
+		m.ResetCreatedAt()
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *TrafficMutation) AddedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.proxy != nil {
+		edges = append(edges, traffic.EdgeProxy)
+	}
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *TrafficMutation) AddedIDs(name string) []ent.Value {
+	switch name {
+	case traffic.EdgeProxy:
+		if id := m.proxy; id != nil {
+			return []ent.Value{*id}
+		}
+	}
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *TrafficMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 1)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *TrafficMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *TrafficMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.clearedproxy {
+		edges = append(edges, traffic.EdgeProxy)
+	}
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *TrafficMutation) EdgeCleared(name string) bool {
+	switch name {
+	case traffic.EdgeProxy:
+		return m.clearedproxy
+	}
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *TrafficMutation) ClearEdge(name string) error {
+	switch name {
+	case traffic.EdgeProxy:
+		m.ClearProxy()
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *TrafficMutation) ResetEdge(name string) error {
+	switch name {
+	case traffic.EdgeProxy:
+		m.ResetProxy()
+		return nil
+	}
+	return fmt.Errorf("unknown Traffic edge %s", name)
+}
+
+// TrafficRollupMutation represents an operation that mutates the TrafficRollup nodes in the graph.
+type TrafficRollupMutation struct {
+	config
+	op                       Op
+	typ                      string
+	id                       *int
+	bucket                   *time.Time
+	host                     *string
+	method                   *string
+	status_code              *int
+	addstatus_code           *int
+	requests                 *int64
+	addrequests              *int64
+	duration_sum_ms          *float64
+	addduration_sum_ms       *float64
+	duration_max_ms          *float64
+	addduration_max_ms       *float64
+	duration_histogram       *[]int64
+	appendduration_histogram []int64
+	clearedFields            map[string]struct{}
+	proxy                    *int
+	clearedproxy             bool
+	done                     bool
+	oldValue                 func(context.Context) (*TrafficRollup, error)
+	predicates               []predicate.TrafficRollup
+}
+
+var _ ent.Mutation = (*TrafficRollupMutation)(nil)
+
+// trafficrollupOption allows management of the mutation configuration using functional options.
+type trafficrollupOption func(*TrafficRollupMutation)
+
+// newTrafficRollupMutation creates new mutation for the TrafficRollup entity.
+func newTrafficRollupMutation(c config, op Op, opts ...trafficrollupOption) *TrafficRollupMutation {
+	m := &TrafficRollupMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeTrafficRollup,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withTrafficRollupID sets the ID field of the mutation.
+func withTrafficRollupID(id int) trafficrollupOption {
+	return func(m *TrafficRollupMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *TrafficRollup
+		)
+		m.oldValue = func(ctx context.Context) (*TrafficRollup, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().TrafficRollup.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withTrafficRollup sets the old TrafficRollup of the mutation.
+func withTrafficRollup(node *TrafficRollup) trafficrollupOption {
+	return func(m *TrafficRollupMutation) {
+		m.oldValue = func(context.Context) (*TrafficRollup, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m TrafficRollupMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m TrafficRollupMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *TrafficRollupMutation) ID() (id int, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *TrafficRollupMutation) IDs(ctx context.Context) ([]int, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().TrafficRollup.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetBucket sets the "bucket" field.
+func (m *TrafficRollupMutation) SetBucket(t time.Time) {
+	m.bucket = &t
+}
+
+// Bucket returns the value of the "bucket" field in the mutation.
+func (m *TrafficRollupMutation) Bucket() (r time.Time, exists bool) {
+	v := m.bucket
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBucket returns the old "bucket" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldBucket(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBucket is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBucket requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBucket: %w", err)
+	}
+	return oldValue.Bucket, nil
+}
+
+// ResetBucket resets all changes to the "bucket" field.
+func (m *TrafficRollupMutation) ResetBucket() {
+	m.bucket = nil
+}
+
+// SetHost sets the "host" field.
+func (m *TrafficRollupMutation) SetHost(s string) {
+	m.host = &s
+}
+
+// Host returns the value of the "host" field in the mutation.
+func (m *TrafficRollupMutation) Host() (r string, exists bool) {
+	v := m.host
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHost returns the old "host" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldHost(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHost is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHost requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHost: %w", err)
+	}
+	return oldValue.Host, nil
+}
+
+// ResetHost resets all changes to the "host" field.
+func (m *TrafficRollupMutation) ResetHost() {
+	m.host = nil
+}
+
+// SetMethod sets the "method" field.
+func (m *TrafficRollupMutation) SetMethod(s string) {
+	m.method = &s
+}
+
+// Method returns the value of the "method" field in the mutation.
+func (m *TrafficRollupMutation) Method() (r string, exists bool) {
+	v := m.method
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMethod returns the old "method" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldMethod(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMethod is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMethod requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMethod: %w", err)
+	}
+	return oldValue.Method, nil
+}
+
+// ResetMethod resets all changes to the "method" field.
+func (m *TrafficRollupMutation) ResetMethod() {
+	m.method = nil
+}
+
+// SetStatusCode sets the "status_code" field.
+func (m *TrafficRollupMutation) SetStatusCode(i int) {
+	m.status_code = &i
+	m.addstatus_code = nil
+}
+
+// StatusCode returns the value of the "status_code" field in the mutation.
+func (m *TrafficRollupMutation) StatusCode() (r int, exists bool) {
+	v := m.status_code
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStatusCode returns the old "status_code" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldStatusCode(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStatusCode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStatusCode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStatusCode: %w", err)
+	}
+	return oldValue.StatusCode, nil
+}
+
+// AddStatusCode adds i to the "status_code" field.
+func (m *TrafficRollupMutation) AddStatusCode(i int) {
+	if m.addstatus_code != nil {
+		*m.addstatus_code += i
+	} else {
+		m.addstatus_code = &i
+	}
+}
+
+// AddedStatusCode returns the value that was added to the "status_code" field in this mutation.
+func (m *TrafficRollupMutation) AddedStatusCode() (r int, exists bool) {
+	v := m.addstatus_code
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetStatusCode resets all changes to the "status_code" field.
+func (m *TrafficRollupMutation) ResetStatusCode() {
+	m.status_code = nil
+	m.addstatus_code = nil
+}
+
+// SetRequests sets the "requests" field.
+func (m *TrafficRollupMutation) SetRequests(i int64) {
+	m.requests = &i
+	m.addrequests = nil
+}
+
+// Requests returns the value of the "requests" field in the mutation.
+func (m *TrafficRollupMutation) Requests() (r int64, exists bool) {
+	v := m.requests
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequests returns the old "requests" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldRequests(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequests is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequests requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequests: %w", err)
+	}
+	return oldValue.Requests, nil
+}
+
+// AddRequests adds i to the "requests" field.
+func (m *TrafficRollupMutation) AddRequests(i int64) {
+	if m.addrequests != nil {
+		*m.addrequests += i
+	} else {
+		m.addrequests = &i
+	}
+}
+
+// AddedRequests returns the value that was added to the "requests" field in this mutation.
+func (m *TrafficRollupMutation) AddedRequests() (r int64, exists bool) {
+	v := m.addrequests
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRequests resets all changes to the "requests" field.
+func (m *TrafficRollupMutation) ResetRequests() {
+	m.requests = nil
+	m.addrequests = nil
+}
+
+// SetDurationSumMs sets the "duration_sum_ms" field.
+func (m *TrafficRollupMutation) SetDurationSumMs(f float64) {
+	m.duration_sum_ms = &f
+	m.addduration_sum_ms = nil
+}
+
+// DurationSumMs returns the value of the "duration_sum_ms" field in the mutation.
+func (m *TrafficRollupMutation) DurationSumMs() (r float64, exists bool) {
+	v := m.duration_sum_ms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDurationSumMs returns the old "duration_sum_ms" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldDurationSumMs(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDurationSumMs is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDurationSumMs requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDurationSumMs: %w", err)
+	}
+	return oldValue.DurationSumMs, nil
+}
+
+// AddDurationSumMs adds f to the "duration_sum_ms" field.
+func (m *TrafficRollupMutation) AddDurationSumMs(f float64) {
+	if m.addduration_sum_ms != nil {
+		*m.addduration_sum_ms += f
+	} else {
+		m.addduration_sum_ms = &f
+	}
+}
+
+// AddedDurationSumMs returns the value that was added to the "duration_sum_ms" field in this mutation.
+func (m *TrafficRollupMutation) AddedDurationSumMs() (r float64, exists bool) {
+	v := m.addduration_sum_ms
+	if v == nil {
+		return
 	}
-	return nil, fmt.Errorf("unknown Traffic field %s", name)
+	return *v, true
 }
 
-// SetField sets the value of a field with the given name. It returns an error if
-// the field is not defined in the schema, or if the type mismatched the field
-// type.
-func (m *TrafficMutation) SetField(name string, value ent.Value) error {
+// ResetDurationSumMs resets all changes to the "duration_sum_ms" field.
+func (m *TrafficRollupMutation) ResetDurationSumMs() {
+	m.duration_sum_ms = nil
+	m.addduration_sum_ms = nil
+}
+
+// SetDurationMaxMs sets the "duration_max_ms" field.
+func (m *TrafficRollupMutation) SetDurationMaxMs(f float64) {
+	m.duration_max_ms = &f
+	m.addduration_max_ms = nil
+}
+
+// DurationMaxMs returns the value of the "duration_max_ms" field in the mutation.
+func (m *TrafficRollupMutation) DurationMaxMs() (r float64, exists bool) {
+	v := m.duration_max_ms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDurationMaxMs returns the old "duration_max_ms" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldDurationMaxMs(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDurationMaxMs is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDurationMaxMs requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDurationMaxMs: %w", err)
+	}
+	return oldValue.DurationMaxMs, nil
+}
+
+// AddDurationMaxMs adds f to the "duration_max_ms" field.
+func (m *TrafficRollupMutation) AddDurationMaxMs(f float64) {
+	if m.addduration_max_ms != nil {
+		*m.addduration_max_ms += f
+	} else {
+		m.addduration_max_ms = &f
+	}
+}
+
+// AddedDurationMaxMs returns the value that was added to the "duration_max_ms" field in this mutation.
+func (m *TrafficRollupMutation) AddedDurationMaxMs() (r float64, exists bool) {
+	v := m.addduration_max_ms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetDurationMaxMs resets all changes to the "duration_max_ms" field.
+func (m *TrafficRollupMutation) ResetDurationMaxMs() {
+	m.duration_max_ms = nil
+	m.addduration_max_ms = nil
+}
+
+// SetDurationHistogram sets the "duration_histogram" field.
+func (m *TrafficRollupMutation) SetDurationHistogram(i []int64) {
+	m.duration_histogram = &i
+	m.appendduration_histogram = nil
+}
+
+// DurationHistogram returns the value of the "duration_histogram" field in the mutation.
+func (m *TrafficRollupMutation) DurationHistogram() (r []int64, exists bool) {
+	v := m.duration_histogram
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDurationHistogram returns the old "duration_histogram" field's value of the TrafficRollup entity.
+// If the TrafficRollup object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *TrafficRollupMutation) OldDurationHistogram(ctx context.Context) (v []int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDurationHistogram is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDurationHistogram requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDurationHistogram: %w", err)
+	}
+	return oldValue.DurationHistogram, nil
+}
+
+// AppendDurationHistogram adds i to the "duration_histogram" field.
+func (m *TrafficRollupMutation) AppendDurationHistogram(i []int64) {
+	m.appendduration_histogram = append(m.appendduration_histogram, i...)
+}
+
+// AppendedDurationHistogram returns the list of values that were appended to the "duration_histogram" field in this mutation.
+func (m *TrafficRollupMutation) AppendedDurationHistogram() ([]int64, bool) {
+	if len(m.appendduration_histogram) == 0 {
+		return nil, false
+	}
+	return m.appendduration_histogram, true
+}
+
+// ResetDurationHistogram resets all changes to the "duration_histogram" field.
+func (m *TrafficRollupMutation) ResetDurationHistogram() {
+	m.duration_histogram = nil
+	m.appendduration_histogram = nil
+}
+
+// SetProxyID sets the "proxy" edge to the Proxy entity by id.
+func (m *TrafficRollupMutation) SetProxyID(id int) {
+	m.proxy = &id
+}
+
+// ClearProxy clears the "proxy" edge to the Proxy entity.
+func (m *TrafficRollupMutation) ClearProxy() {
+	m.clearedproxy = true
+}
+
+// ProxyCleared reports if the "proxy" edge to the Proxy entity was cleared.
+func (m *TrafficRollupMutation) ProxyCleared() bool {
+	return m.clearedproxy
+}
+
+// ProxyID returns the "proxy" edge ID in the mutation.
+func (m *TrafficRollupMutation) ProxyID() (id int, exists bool) {
+	if m.proxy != nil {
+		return *m.proxy, true
+	}
+	return
+}
+
+// ProxyIDs returns the "proxy" edge IDs in the mutation.
+// Note that IDs always returns len(IDs) <= 1 for unique edges, and you should use
+// ProxyID instead. It exists only for internal usage by the builders.
+func (m *TrafficRollupMutation) ProxyIDs() (ids []int) {
+	if id := m.proxy; id != nil {
+		ids = append(ids, *id)
+	}
+	return
+}
+
+// ResetProxy resets all changes to the "proxy" edge.
+func (m *TrafficRollupMutation) ResetProxy() {
+	m.proxy = nil
+	m.clearedproxy = false
+}
+
+// Where appends a list predicates to the TrafficRollupMutation builder.
+func (m *TrafficRollupMutation) Where(ps ...predicate.TrafficRollup) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the TrafficRollupMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *TrafficRollupMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.TrafficRollup, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *TrafficRollupMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *TrafficRollupMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (TrafficRollup).
+func (m *TrafficRollupMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *TrafficRollupMutation) Fields() []string {
+	fields := make([]string, 0, 8)
+	if m.bucket != nil {
+		fields = append(fields, trafficrollup.FieldBucket)
+	}
+	if m.host != nil {
+		fields = append(fields, trafficrollup.FieldHost)
+	}
+	if m.method != nil {
+		fields = append(fields, trafficrollup.FieldMethod)
+	}
+	if m.status_code != nil {
+		fields = append(fields, trafficrollup.FieldStatusCode)
+	}
+	if m.requests != nil {
+		fields = append(fields, trafficrollup.FieldRequests)
+	}
+	if m.duration_sum_ms != nil {
+		fields = append(fields, trafficrollup.FieldDurationSumMs)
+	}
+	if m.duration_max_ms != nil {
+		fields = append(fields, trafficrollup.FieldDurationMaxMs)
+	}
+	if m.duration_histogram != nil {
+		fields = append(fields, trafficrollup.FieldDurationHistogram)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *TrafficRollupMutation) Field(name string) (ent.Value, bool) {
 	switch name {
-	case traffic.FieldMethod:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetMethod(v)
-		return nil
-	case traffic.FieldURL:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetURL(v)
-		return nil
-	case traffic.FieldScheme:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetScheme(v)
-		return nil
-	case traffic.FieldHost:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetHost(v)
-		return nil
-	case traffic.FieldPath:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetPath(v)
-		return nil
-	case traffic.FieldQuery:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetQuery(v)
-		return nil
-	case traffic.FieldRequestHeaders:
-		v, ok := value.(map[string][]string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetRequestHeaders(v)
-		return nil
-	case traffic.FieldRequestBody:
-		v, ok := value.([]byte)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetRequestBody(v)
-		return nil
-	case traffic.FieldRequestBodySize:
-		v, ok := value.(int64)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetRequestBodySize(v)
-		return nil
-	case traffic.FieldRequestIsBinary:
-		v, ok := value.(bool)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetRequestIsBinary(v)
-		return nil
-	case traffic.FieldContentType:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetContentType(v)
-		return nil
-	case traffic.FieldStatusCode:
-		v, ok := value.(int)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetStatusCode(v)
-		return nil
-	case traffic.FieldStatusText:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetStatusText(v)
-		return nil
-	case traffic.FieldResponseHeaders:
-		v, ok := value.(map[string][]string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetResponseHeaders(v)
-		return nil
-	case traffic.FieldResponseBody:
-		v, ok := value.([]byte)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetResponseBody(v)
-		return nil
-	case traffic.FieldResponseBodySize:
-		v, ok := value.(int64)
+	case trafficrollup.FieldBucket:
+		return m.Bucket()
+	case trafficrollup.FieldHost:
+		return m.Host()
+	case trafficrollup.FieldMethod:
+		return m.Method()
+	case trafficrollup.FieldStatusCode:
+		return m.StatusCode()
+	case trafficrollup.FieldRequests:
+		return m.Requests()
+	case trafficrollup.FieldDurationSumMs:
+		return m.DurationSumMs()
+	case trafficrollup.FieldDurationMaxMs:
+		return m.DurationMaxMs()
+	case trafficrollup.FieldDurationHistogram:
+		return m.DurationHistogram()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *TrafficRollupMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case trafficrollup.FieldBucket:
+		return m.OldBucket(ctx)
+	case trafficrollup.FieldHost:
+		return m.OldHost(ctx)
+	case trafficrollup.FieldMethod:
+		return m.OldMethod(ctx)
+	case trafficrollup.FieldStatusCode:
+		return m.OldStatusCode(ctx)
+	case trafficrollup.FieldRequests:
+		return m.OldRequests(ctx)
+	case trafficrollup.FieldDurationSumMs:
+		return m.OldDurationSumMs(ctx)
+	case trafficrollup.FieldDurationMaxMs:
+		return m.OldDurationMaxMs(ctx)
+	case trafficrollup.FieldDurationHistogram:
+		return m.OldDurationHistogram(ctx)
+	}
+	return nil, fmt.Errorf("unknown TrafficRollup field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *TrafficRollupMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case trafficrollup.FieldBucket:
+		v, ok := value.(time.Time)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetResponseBodySize(v)
+		m.SetBucket(v)
 		return nil
-	case traffic.FieldResponseIsBinary:
-		v, ok := value.(bool)
+	case trafficrollup.FieldHost:
+		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetResponseIsBinary(v)
+		m.SetHost(v)
 		return nil
-	case traffic.FieldResponseContentType:
+	case trafficrollup.FieldMethod:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetResponseContentType(v)
+		m.SetMethod(v)
 		return nil
-	case traffic.FieldStartedAt:
-		v, ok := value.(time.Time)
+	case trafficrollup.FieldStatusCode:
+		v, ok := value.(int)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetStartedAt(v)
+		m.SetStatusCode(v)
 		return nil
-	case traffic.FieldDurationMs:
-		v, ok := value.(float64)
+	case trafficrollup.FieldRequests:
+		v, ok := value.(int64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetDurationMs(v)
+		m.SetRequests(v)
 		return nil
-	case traffic.FieldTtfbMs:
+	case trafficrollup.FieldDurationSumMs:
 		v, ok := value.(float64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetTtfbMs(v)
-		return nil
-	case traffic.FieldClientIP:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClientIP(v)
-		return nil
-	case traffic.FieldError:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetError(v)
+		m.SetDurationSumMs(v)
 		return nil
-	case traffic.FieldTags:
-		v, ok := value.([]string)
+	case trafficrollup.FieldDurationMaxMs:
+		v, ok := value.(float64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetTags(v)
+		m.SetDurationMaxMs(v)
 		return nil
-	case traffic.FieldCreatedAt:
-		v, ok := value.(time.Time)
+	case trafficrollup.FieldDurationHistogram:
+		v, ok := value.([]int64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetCreatedAt(v)
+		m.SetDurationHistogram(v)
 		return nil
 	}
-	return fmt.Errorf("unknown Traffic field %s", name)
+	return fmt.Errorf("unknown TrafficRollup field %s", name)
 }
 
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
-func (m *TrafficMutation) AddedFields() []string {
+func (m *TrafficRollupMutation) AddedFields() []string {
 	var fields []string
-	if m.addrequest_body_size != nil {
-		fields = append(fields, traffic.FieldRequestBodySize)
-	}
 	if m.addstatus_code != nil {
-		fields = append(fields, traffic.FieldStatusCode)
+		fields = append(fields, trafficrollup.FieldStatusCode)
 	}
-	if m.addresponse_body_size != nil {
-		fields = append(fields, traffic.FieldResponseBodySize)
+	if m.addrequests != nil {
+		fields = append(fields, trafficrollup.FieldRequests)
 	}
-	if m.addduration_ms != nil {
-		fields = append(fields, traffic.FieldDurationMs)
+	if m.addduration_sum_ms != nil {
+		fields = append(fields, trafficrollup.FieldDurationSumMs)
 	}
-	if m.addttfb_ms != nil {
-		fields = append(fields, traffic.FieldTtfbMs)
+	if m.addduration_max_ms != nil {
+		fields = append(fields, trafficrollup.FieldDurationMaxMs)
 	}
 	return fields
 }
@@ -5082,18 +7877,16 @@ func (m *TrafficMutation) AddedFields() []string {
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
-func (m *TrafficMutation) AddedField(name string) (ent.Value, bool) {
+func (m *TrafficRollupMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
-	case traffic.FieldRequestBodySize:
-		return m.AddedRequestBodySize()
-	case traffic.FieldStatusCode:
+	case trafficrollup.FieldStatusCode:
 		return m.AddedStatusCode()
-	case traffic.FieldResponseBodySize:
-		return m.AddedResponseBodySize()
-	case traffic.FieldDurationMs:
-		return m.AddedDurationMs()
-	case traffic.FieldTtfbMs:
-		return m.AddedTtfbMs()
+	case trafficrollup.FieldRequests:
+		return m.AddedRequests()
+	case trafficrollup.FieldDurationSumMs:
+		return m.AddedDurationSumMs()
+	case trafficrollup.FieldDurationMaxMs:
+		return m.AddedDurationMaxMs()
 	}
 	return nil, false
 }
@@ -5101,238 +7894,105 @@ func (m *TrafficMutation) AddedField(name string) (ent.Value, bool) {
 // AddField adds the value to the field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *TrafficMutation) AddField(name string, value ent.Value) error {
+func (m *TrafficRollupMutation) AddField(name string, value ent.Value) error {
 	switch name {
-	case traffic.FieldRequestBodySize:
-		v, ok := value.(int64)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.AddRequestBodySize(v)
-		return nil
-	case traffic.FieldStatusCode:
+	case trafficrollup.FieldStatusCode:
 		v, ok := value.(int)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
 		m.AddStatusCode(v)
 		return nil
-	case traffic.FieldResponseBodySize:
+	case trafficrollup.FieldRequests:
 		v, ok := value.(int64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.AddResponseBodySize(v)
+		m.AddRequests(v)
 		return nil
-	case traffic.FieldDurationMs:
+	case trafficrollup.FieldDurationSumMs:
 		v, ok := value.(float64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.AddDurationMs(v)
+		m.AddDurationSumMs(v)
 		return nil
-	case traffic.FieldTtfbMs:
+	case trafficrollup.FieldDurationMaxMs:
 		v, ok := value.(float64)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.AddTtfbMs(v)
+		m.AddDurationMaxMs(v)
 		return nil
 	}
-	return fmt.Errorf("unknown Traffic numeric field %s", name)
+	return fmt.Errorf("unknown TrafficRollup numeric field %s", name)
 }
 
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
-func (m *TrafficMutation) ClearedFields() []string {
-	var fields []string
-	if m.FieldCleared(traffic.FieldQuery) {
-		fields = append(fields, traffic.FieldQuery)
-	}
-	if m.FieldCleared(traffic.FieldRequestHeaders) {
-		fields = append(fields, traffic.FieldRequestHeaders)
-	}
-	if m.FieldCleared(traffic.FieldRequestBody) {
-		fields = append(fields, traffic.FieldRequestBody)
-	}
-	if m.FieldCleared(traffic.FieldContentType) {
-		fields = append(fields, traffic.FieldContentType)
-	}
-	if m.FieldCleared(traffic.FieldStatusText) {
-		fields = append(fields, traffic.FieldStatusText)
-	}
-	if m.FieldCleared(traffic.FieldResponseHeaders) {
-		fields = append(fields, traffic.FieldResponseHeaders)
-	}
-	if m.FieldCleared(traffic.FieldResponseBody) {
-		fields = append(fields, traffic.FieldResponseBody)
-	}
-	if m.FieldCleared(traffic.FieldResponseContentType) {
-		fields = append(fields, traffic.FieldResponseContentType)
-	}
-	if m.FieldCleared(traffic.FieldTtfbMs) {
-		fields = append(fields, traffic.FieldTtfbMs)
-	}
-	if m.FieldCleared(traffic.FieldClientIP) {
-		fields = append(fields, traffic.FieldClientIP)
-	}
-	if m.FieldCleared(traffic.FieldError) {
-		fields = append(fields, traffic.FieldError)
-	}
-	if m.FieldCleared(traffic.FieldTags) {
-		fields = append(fields, traffic.FieldTags)
-	}
-	return fields
+func (m *TrafficRollupMutation) ClearedFields() []string {
+	return nil
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
 // cleared in this mutation.
-func (m *TrafficMutation) FieldCleared(name string) bool {
+func (m *TrafficRollupMutation) FieldCleared(name string) bool {
 	_, ok := m.clearedFields[name]
 	return ok
 }
 
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
-func (m *TrafficMutation) ClearField(name string) error {
-	switch name {
-	case traffic.FieldQuery:
-		m.ClearQuery()
-		return nil
-	case traffic.FieldRequestHeaders:
-		m.ClearRequestHeaders()
-		return nil
-	case traffic.FieldRequestBody:
-		m.ClearRequestBody()
-		return nil
-	case traffic.FieldContentType:
-		m.ClearContentType()
-		return nil
-	case traffic.FieldStatusText:
-		m.ClearStatusText()
-		return nil
-	case traffic.FieldResponseHeaders:
-		m.ClearResponseHeaders()
-		return nil
-	case traffic.FieldResponseBody:
-		m.ClearResponseBody()
-		return nil
-	case traffic.FieldResponseContentType:
-		m.ClearResponseContentType()
-		return nil
-	case traffic.FieldTtfbMs:
-		m.ClearTtfbMs()
-		return nil
-	case traffic.FieldClientIP:
-		m.ClearClientIP()
-		return nil
-	case traffic.FieldError:
-		m.ClearError()
-		return nil
-	case traffic.FieldTags:
-		m.ClearTags()
-		return nil
-	}
-	return fmt.Errorf("unknown Traffic nullable field %s", name)
+func (m *TrafficRollupMutation) ClearField(name string) error {
+	return fmt.Errorf("unknown TrafficRollup nullable field %s", name)
 }
 
 // ResetField resets all changes in the mutation for the field with the given name.
 // It returns an error if the field is not defined in the schema.
-func (m *TrafficMutation) ResetField(name string) error {
+func (m *TrafficRollupMutation) ResetField(name string) error {
 	switch name {
-	case traffic.FieldMethod:
-		m.ResetMethod()
-		return nil
-	case traffic.FieldURL:
-		m.ResetURL()
-		return nil
-	case traffic.FieldScheme:
-		m.ResetScheme()
+	case trafficrollup.FieldBucket:
+		m.ResetBucket()
 		return nil
-	case traffic.FieldHost:
+	case trafficrollup.FieldHost:
 		m.ResetHost()
 		return nil
-	case traffic.FieldPath:
-		m.ResetPath()
-		return nil
-	case traffic.FieldQuery:
-		m.ResetQuery()
-		return nil
-	case traffic.FieldRequestHeaders:
-		m.ResetRequestHeaders()
-		return nil
-	case traffic.FieldRequestBody:
-		m.ResetRequestBody()
-		return nil
-	case traffic.FieldRequestBodySize:
-		m.ResetRequestBodySize()
-		return nil
-	case traffic.FieldRequestIsBinary:
-		m.ResetRequestIsBinary()
-		return nil
-	case traffic.FieldContentType:
-		m.ResetContentType()
+	case trafficrollup.FieldMethod:
+		m.ResetMethod()
 		return nil
-	case traffic.FieldStatusCode:
+	case trafficrollup.FieldStatusCode:
 		m.ResetStatusCode()
 		return nil
-	case traffic.FieldStatusText:
-		m.ResetStatusText()
-		return nil
-	case traffic.FieldResponseHeaders:
-		m.ResetResponseHeaders()
-		return nil
-	case traffic.FieldResponseBody:
-		m.ResetResponseBody()
-		return nil
-	case traffic.FieldResponseBodySize:
-		m.ResetResponseBodySize()
-		return nil
-	case traffic.FieldResponseIsBinary:
-		m.ResetResponseIsBinary()
-		return nil
-	case traffic.FieldResponseContentType:
-		m.ResetResponseContentType()
-		return nil
-	case traffic.FieldStartedAt:
-		m.ResetStartedAt()
-		return nil
-	case traffic.FieldDurationMs:
-		m.ResetDurationMs()
-		return nil
-	case traffic.FieldTtfbMs:
-		m.ResetTtfbMs()
-		return nil
-	case traffic.FieldClientIP:
-		m.ResetClientIP()
+	case trafficrollup.FieldRequests:
+		m.ResetRequests()
 		return nil
-	case traffic.FieldError:
-		m.ResetError()
+	case trafficrollup.FieldDurationSumMs:
+		m.ResetDurationSumMs()
 		return nil
-	case traffic.FieldTags:
-		m.ResetTags()
+	case trafficrollup.FieldDurationMaxMs:
+		m.ResetDurationMaxMs()
 		return nil
-	case traffic.FieldCreatedAt:
-		m.ResetCreatedAt()
+	case trafficrollup.FieldDurationHistogram:
+		m.ResetDurationHistogram()
 		return nil
 	}
-	return fmt.Errorf("unknown Traffic field %s", name)
+	return fmt.Errorf("unknown TrafficRollup field %s", name)
 }
 
 // AddedEdges returns all edge names that were set/added in this mutation.
-func (m *TrafficMutation) AddedEdges() []string {
+func (m *TrafficRollupMutation) AddedEdges() []string {
 	edges := make([]string, 0, 1)
 	if m.proxy != nil {
-		edges = append(edges, traffic.EdgeProxy)
+		edges = append(edges, trafficrollup.EdgeProxy)
 	}
 	return edges
 }
 
 // AddedIDs returns all IDs (to other nodes) that were added for the given edge
 // name in this mutation.
-func (m *TrafficMutation) AddedIDs(name string) []ent.Value {
+func (m *TrafficRollupMutation) AddedIDs(name string) []ent.Value {
 	switch name {
-	case traffic.EdgeProxy:
+	case trafficrollup.EdgeProxy:
 		if id := m.proxy; id != nil {
 			return []ent.Value{*id}
 		}
@@ -5341,31 +8001,31 @@ func (m *TrafficMutation) AddedIDs(name string) []ent.Value {
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
-func (m *TrafficMutation) RemovedEdges() []string {
+func (m *TrafficRollupMutation) RemovedEdges() []string {
 	edges := make([]string, 0, 1)
 	return edges
 }
 
 // RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
 // the given name in this mutation.
-func (m *TrafficMutation) RemovedIDs(name string) []ent.Value {
+func (m *TrafficRollupMutation) RemovedIDs(name string) []ent.Value {
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *TrafficMutation) ClearedEdges() []string {
+func (m *TrafficRollupMutation) ClearedEdges() []string {
 	edges := make([]string, 0, 1)
 	if m.clearedproxy {
-		edges = append(edges, traffic.EdgeProxy)
+		edges = append(edges, trafficrollup.EdgeProxy)
 	}
 	return edges
 }
 
 // EdgeCleared returns a boolean which indicates if the edge with the given name
 // was cleared in this mutation.
-func (m *TrafficMutation) EdgeCleared(name string) bool {
+func (m *TrafficRollupMutation) EdgeCleared(name string) bool {
 	switch name {
-	case traffic.EdgeProxy:
+	case trafficrollup.EdgeProxy:
 		return m.clearedproxy
 	}
 	return false
@@ -5373,24 +8033,24 @@ func (m *TrafficMutation) EdgeCleared(name string) bool {
 
 // ClearEdge clears the value of the edge with the given name. It returns an error
 // if that edge is not defined in the schema.
-func (m *TrafficMutation) ClearEdge(name string) error {
+func (m *TrafficRollupMutation) ClearEdge(name string) error {
 	switch name {
-	case traffic.EdgeProxy:
+	case trafficrollup.EdgeProxy:
 		m.ClearProxy()
 		return nil
 	}
-	return fmt.Errorf("unknown Traffic unique edge %s", name)
+	return fmt.Errorf("unknown TrafficRollup unique edge %s", name)
 }
 
 // ResetEdge resets all changes to the edge with the given name in this mutation.
 // It returns an error if the edge is not defined in the schema.
-func (m *TrafficMutation) ResetEdge(name string) error {
+func (m *TrafficRollupMutation) ResetEdge(name string) error {
 	switch name {
-	case traffic.EdgeProxy:
+	case trafficrollup.EdgeProxy:
 		m.ResetProxy()
 		return nil
 	}
-	return fmt.Errorf("unknown Traffic edge %s", name)
+	return fmt.Errorf("unknown TrafficRollup edge %s", name)
 }
 
 // UserMutation represents an operation that mutates the User nodes in the graph.
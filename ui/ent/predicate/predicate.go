@@ -18,5 +18,8 @@ type Session func(*sql.Selector)
 // Traffic is the predicate function for traffic builders.
 type Traffic func(*sql.Selector)
 
+// TrafficRollup is the predicate function for trafficrollup builders.
+type TrafficRollup func(*sql.Selector)
+
 // User is the predicate function for user builders.
 type User func(*sql.Selector)
@@ -66,9 +66,11 @@ type ProxyEdges struct {
 	Org *Org `json:"org,omitempty"`
 	// Traffic captured by this proxy
 	Traffic []*Traffic `json:"traffic,omitempty"`
+	// Hourly traffic aggregates of this proxy
+	Rollups []*TrafficRollup `json:"rollups,omitempty"`
 	// loadedTypes holds the information for reporting if a
 	// type was loaded (or requested) in eager-loading or not.
-	loadedTypes [2]bool
+	loadedTypes [3]bool
 }
 
 // OrgOrErr returns the Org value or an error if the edge
@@ -91,6 +93,15 @@ func (e ProxyEdges) TrafficOrErr() ([]*Traffic, error) {
 	return nil, &NotLoadedError{edge: "traffic"}
 }
 
+// RollupsOrErr returns the Rollups value or an error if the edge
+// was not loaded in eager-loading.
+func (e ProxyEdges) RollupsOrErr() ([]*TrafficRollup, error) {
+	if e.loadedTypes[2] {
+		return e.Rollups, nil
+	}
+	return nil, &NotLoadedError{edge: "rollups"}
+}
+
 // scanValues returns the types for scanning values from sql.Rows.
 func (*Proxy) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
@@ -272,6 +283,11 @@ func (_m *Proxy) QueryTraffic() *TrafficQuery {
 	return NewProxyClient(_m.config).QueryTraffic(_m)
 }
 
+// QueryRollups queries the "rollups" edge of the Proxy entity.
+func (_m *Proxy) QueryRollups() *TrafficRollupQuery {
+	return NewProxyClient(_m.config).QueryRollups(_m)
+}
+
 // Update returns a builder for updating this Proxy.
 // Note that you need to call Proxy.Unwrap() before calling this method if this Proxy
 // was returned from a transaction, and the transaction was committed or rolled back.
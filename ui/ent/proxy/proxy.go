@@ -53,6 +53,8 @@ const (
 	EdgeOrg = "org"
 	// EdgeTraffic holds the string denoting the traffic edge name in mutations.
 	EdgeTraffic = "traffic"
+	// EdgeRollups holds the string denoting the rollups edge name in mutations.
+	EdgeRollups = "rollups"
 	// Table holds the table name of the proxy in the database.
 	Table = "proxies"
 	// OrgTable is the table that holds the org relation/edge.
@@ -69,6 +71,13 @@ const (
 	TrafficInverseTable = "traffics"
 	// TrafficColumn is the table column denoting the traffic relation/edge.
 	TrafficColumn = "proxy_traffic"
+	// RollupsTable is the table that holds the rollups relation/edge.
+	RollupsTable = "traffic_rollups"
+	// RollupsInverseTable is the table name for the TrafficRollup entity.
+	// It exists in this package in order to avoid circular dependency with the "trafficrollup" package.
+	RollupsInverseTable = "traffic_rollups"
+	// RollupsColumn is the table column denoting the rollups relation/edge.
+	RollupsColumn = "proxy_rollups"
 )
 
 // Columns holds all SQL columns for proxy fields.
@@ -252,6 +261,20 @@ func ByTraffic(term sql.OrderTerm, terms ...sql.OrderTerm) OrderOption {
 		sqlgraph.OrderByNeighborTerms(s, newTrafficStep(), append([]sql.OrderTerm{term}, terms...)...)
 	}
 }
+
+// ByRollupsCount orders the results by rollups count.
+func ByRollupsCount(opts ...sql.OrderTermOption) OrderOption {
+	return func(s *sql.Selector) {
+		sqlgraph.OrderByNeighborsCount(s, newRollupsStep(), opts...)
+	}
+}
+
+// ByRollups orders the results by rollups terms.
+func ByRollups(term sql.OrderTerm, terms ...sql.OrderTerm) OrderOption {
+	return func(s *sql.Selector) {
+		sqlgraph.OrderByNeighborTerms(s, newRollupsStep(), append([]sql.OrderTerm{term}, terms...)...)
+	}
+}
 func newOrgStep() *sqlgraph.Step {
 	return sqlgraph.NewStep(
 		sqlgraph.From(Table, FieldID),
@@ -266,3 +289,10 @@ func newTrafficStep() *sqlgraph.Step {
 		sqlgraph.Edge(sqlgraph.O2M, false, TrafficTable, TrafficColumn),
 	)
 }
+func newRollupsStep() *sqlgraph.Step {
+	return sqlgraph.NewStep(
+		sqlgraph.From(Table, FieldID),
+		sqlgraph.To(RollupsInverseTable, FieldID),
+		sqlgraph.Edge(sqlgraph.O2M, false, RollupsTable, RollupsColumn),
+	)
+}
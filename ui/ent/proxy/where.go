@@ -696,6 +696,29 @@ func HasTrafficWith(preds ...predicate.Traffic) predicate.Proxy {
 	})
 }
 
+// HasRollups applies the HasEdge predicate on the "rollups" edge.
+func HasRollups() predicate.Proxy {
+	return predicate.Proxy(func(s *sql.Selector) {
+		step := sqlgraph.NewStep(
+			sqlgraph.From(Table, FieldID),
+			sqlgraph.Edge(sqlgraph.O2M, false, RollupsTable, RollupsColumn),
+		)
+		sqlgraph.HasNeighbors(s, step)
+	})
+}
+
+// HasRollupsWith applies the HasEdge predicate on the "rollups" edge with a given conditions (other predicates).
+func HasRollupsWith(preds ...predicate.TrafficRollup) predicate.Proxy {
+	return predicate.Proxy(func(s *sql.Selector) {
+		step := newRollupsStep()
+		sqlgraph.HasNeighborsWith(s, step, func(s *sql.Selector) {
+			for _, p := range preds {
+				p(s)
+			}
+		})
+	})
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.Proxy) predicate.Proxy {
 	return predicate.Proxy(sql.AndPredicates(predicates...))
@@ -13,6 +13,7 @@ import (
 	"github.com/grokify/omniproxy/ui/ent/org"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 )
 
 // ProxyCreate is the builder for creating a Proxy entity.
@@ -230,6 +231,21 @@ func (_c *ProxyCreate) AddTraffic(v ...*Traffic) *ProxyCreate {
 	return _c.AddTrafficIDs(ids...)
 }
 
+// AddRollupIDs adds the "rollups" edge to the TrafficRollup entity by IDs.
+func (_c *ProxyCreate) AddRollupIDs(ids ...int) *ProxyCreate {
+	_c.mutation.AddRollupIDs(ids...)
+	return _c
+}
+
+// AddRollups adds the "rollups" edges to the TrafficRollup entity.
+func (_c *ProxyCreate) AddRollups(v ...*TrafficRollup) *ProxyCreate {
+	ids := make([]int, len(v))
+	for i := range v {
+		ids[i] = v[i].ID
+	}
+	return _c.AddRollupIDs(ids...)
+}
+
 // Mutation returns the ProxyMutation object of the builder.
 func (_c *ProxyCreate) Mutation() *ProxyMutation {
 	return _c.mutation
@@ -476,6 +492,22 @@ func (_c *ProxyCreate) createSpec() (*Proxy, *sqlgraph.CreateSpec) {
 		}
 		_spec.Edges = append(_spec.Edges, edge)
 	}
+	if nodes := _c.mutation.RollupsIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges = append(_spec.Edges, edge)
+	}
 	return _node, _spec
 }
 
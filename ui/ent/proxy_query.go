@@ -16,6 +16,7 @@ import (
 	"github.com/grokify/omniproxy/ui/ent/predicate"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 )
 
 // ProxyQuery is the builder for querying Proxy entities.
@@ -27,6 +28,7 @@ type ProxyQuery struct {
 	predicates  []predicate.Proxy
 	withOrg     *OrgQuery
 	withTraffic *TrafficQuery
+	withRollups *TrafficRollupQuery
 	withFKs     bool
 	// intermediate query (i.e. traversal path).
 	sql  *sql.Selector
@@ -108,6 +110,28 @@ func (_q *ProxyQuery) QueryTraffic() *TrafficQuery {
 	return query
 }
 
+// QueryRollups chains the current query on the "rollups" edge.
+func (_q *ProxyQuery) QueryRollups() *TrafficRollupQuery {
+	query := (&TrafficRollupClient{config: _q.config}).Query()
+	query.path = func(ctx context.Context) (fromU *sql.Selector, err error) {
+		if err := _q.prepareQuery(ctx); err != nil {
+			return nil, err
+		}
+		selector := _q.sqlQuery(ctx)
+		if err := selector.Err(); err != nil {
+			return nil, err
+		}
+		step := sqlgraph.NewStep(
+			sqlgraph.From(proxy.Table, proxy.FieldID, selector),
+			sqlgraph.To(trafficrollup.Table, trafficrollup.FieldID),
+			sqlgraph.Edge(sqlgraph.O2M, false, proxy.RollupsTable, proxy.RollupsColumn),
+		)
+		fromU = sqlgraph.SetNeighbors(_q.driver.Dialect(), step)
+		return fromU, nil
+	}
+	return query
+}
+
 // First returns the first Proxy entity from the query.
 // Returns a *NotFoundError when no Proxy was found.
 func (_q *ProxyQuery) First(ctx context.Context) (*Proxy, error) {
@@ -302,6 +326,7 @@ func (_q *ProxyQuery) Clone() *ProxyQuery {
 		predicates:  append([]predicate.Proxy{}, _q.predicates...),
 		withOrg:     _q.withOrg.Clone(),
 		withTraffic: _q.withTraffic.Clone(),
+		withRollups: _q.withRollups.Clone(),
 		// clone intermediate query.
 		sql:  _q.sql.Clone(),
 		path: _q.path,
@@ -330,6 +355,17 @@ func (_q *ProxyQuery) WithTraffic(opts ...func(*TrafficQuery)) *ProxyQuery {
 	return _q
 }
 
+// WithRollups tells the query-builder to eager-load the nodes that are connected to
+// the "rollups" edge. The optional arguments are used to configure the query builder of the edge.
+func (_q *ProxyQuery) WithRollups(opts ...func(*TrafficRollupQuery)) *ProxyQuery {
+	query := (&TrafficRollupClient{config: _q.config}).Query()
+	for _, opt := range opts {
+		opt(query)
+	}
+	_q.withRollups = query
+	return _q
+}
+
 // GroupBy is used to group vertices by one or more fields/columns.
 // It is often used with aggregate functions, like: count, max, mean, min, sum.
 //
@@ -409,9 +445,10 @@ func (_q *ProxyQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Proxy,
 		nodes       = []*Proxy{}
 		withFKs     = _q.withFKs
 		_spec       = _q.querySpec()
-		loadedTypes = [2]bool{
+		loadedTypes = [3]bool{
 			_q.withOrg != nil,
 			_q.withTraffic != nil,
+			_q.withRollups != nil,
 		}
 	)
 	if _q.withOrg != nil {
@@ -451,6 +488,13 @@ func (_q *ProxyQuery) sqlAll(ctx context.Context, hooks ...queryHook) ([]*Proxy,
 			return nil, err
 		}
 	}
+	if query := _q.withRollups; query != nil {
+		if err := _q.loadRollups(ctx, query, nodes,
+			func(n *Proxy) { n.Edges.Rollups = []*TrafficRollup{} },
+			func(n *Proxy, e *TrafficRollup) { n.Edges.Rollups = append(n.Edges.Rollups, e) }); err != nil {
+			return nil, err
+		}
+	}
 	return nodes, nil
 }
 
@@ -517,6 +561,37 @@ func (_q *ProxyQuery) loadTraffic(ctx context.Context, query *TrafficQuery, node
 	}
 	return nil
 }
+func (_q *ProxyQuery) loadRollups(ctx context.Context, query *TrafficRollupQuery, nodes []*Proxy, init func(*Proxy), assign func(*Proxy, *TrafficRollup)) error {
+	fks := make([]driver.Value, 0, len(nodes))
+	nodeids := make(map[int]*Proxy)
+	for i := range nodes {
+		fks = append(fks, nodes[i].ID)
+		nodeids[nodes[i].ID] = nodes[i]
+		if init != nil {
+			init(nodes[i])
+		}
+	}
+	query.withFKs = true
+	query.Where(predicate.TrafficRollup(func(s *sql.Selector) {
+		s.Where(sql.InValues(s.C(proxy.RollupsColumn), fks...))
+	}))
+	neighbors, err := query.All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, n := range neighbors {
+		fk := n.proxy_rollups
+		if fk == nil {
+			return fmt.Errorf(`foreign-key "proxy_rollups" is nil for node %v`, n.ID)
+		}
+		node, ok := nodeids[*fk]
+		if !ok {
+			return fmt.Errorf(`unexpected referenced foreign-key "proxy_rollups" returned %v for node %v`, *fk, n.ID)
+		}
+		assign(node, n)
+	}
+	return nil
+}
 
 func (_q *ProxyQuery) sqlCount(ctx context.Context) (int, error) {
 	_spec := _q.querySpec()
@@ -16,6 +16,7 @@ import (
 	"github.com/grokify/omniproxy/ui/ent/predicate"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
 )
 
 // ProxyUpdate is the builder for updating Proxy entities.
@@ -312,6 +313,21 @@ func (_u *ProxyUpdate) AddTraffic(v ...*Traffic) *ProxyUpdate {
 	return _u.AddTrafficIDs(ids...)
 }
 
+// AddRollupIDs adds the "rollups" edge to the TrafficRollup entity by IDs.
+func (_u *ProxyUpdate) AddRollupIDs(ids ...int) *ProxyUpdate {
+	_u.mutation.AddRollupIDs(ids...)
+	return _u
+}
+
+// AddRollups adds the "rollups" edges to the TrafficRollup entity.
+func (_u *ProxyUpdate) AddRollups(v ...*TrafficRollup) *ProxyUpdate {
+	ids := make([]int, len(v))
+	for i := range v {
+		ids[i] = v[i].ID
+	}
+	return _u.AddRollupIDs(ids...)
+}
+
 // Mutation returns the ProxyMutation object of the builder.
 func (_u *ProxyUpdate) Mutation() *ProxyMutation {
 	return _u.mutation
@@ -344,6 +360,27 @@ func (_u *ProxyUpdate) RemoveTraffic(v ...*Traffic) *ProxyUpdate {
 	return _u.RemoveTrafficIDs(ids...)
 }
 
+// ClearRollups clears all "rollups" edges to the TrafficRollup entity.
+func (_u *ProxyUpdate) ClearRollups() *ProxyUpdate {
+	_u.mutation.ClearRollups()
+	return _u
+}
+
+// RemoveRollupIDs removes the "rollups" edge to TrafficRollup entities by IDs.
+func (_u *ProxyUpdate) RemoveRollupIDs(ids ...int) *ProxyUpdate {
+	_u.mutation.RemoveRollupIDs(ids...)
+	return _u
+}
+
+// RemoveRollups removes "rollups" edges to TrafficRollup entities.
+func (_u *ProxyUpdate) RemoveRollups(v ...*TrafficRollup) *ProxyUpdate {
+	ids := make([]int, len(v))
+	for i := range v {
+		ids[i] = v[i].ID
+	}
+	return _u.RemoveRollupIDs(ids...)
+}
+
 // Save executes the query and returns the number of nodes affected by the update operation.
 func (_u *ProxyUpdate) Save(ctx context.Context) (int, error) {
 	_u.defaults()
@@ -586,6 +623,51 @@ func (_u *ProxyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 		}
 		_spec.Edges.Add = append(_spec.Edges.Add, edge)
 	}
+	if _u.mutation.RollupsCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.RemovedRollupsIDs(); len(nodes) > 0 && !_u.mutation.RollupsCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.RollupsIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Add = append(_spec.Edges.Add, edge)
+	}
 	if _node, err = sqlgraph.UpdateNodes(ctx, _u.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{proxy.Label}
@@ -887,6 +969,21 @@ func (_u *ProxyUpdateOne) AddTraffic(v ...*Traffic) *ProxyUpdateOne {
 	return _u.AddTrafficIDs(ids...)
 }
 
+// AddRollupIDs adds the "rollups" edge to the TrafficRollup entity by IDs.
+func (_u *ProxyUpdateOne) AddRollupIDs(ids ...int) *ProxyUpdateOne {
+	_u.mutation.AddRollupIDs(ids...)
+	return _u
+}
+
+// AddRollups adds the "rollups" edges to the TrafficRollup entity.
+func (_u *ProxyUpdateOne) AddRollups(v ...*TrafficRollup) *ProxyUpdateOne {
+	ids := make([]int, len(v))
+	for i := range v {
+		ids[i] = v[i].ID
+	}
+	return _u.AddRollupIDs(ids...)
+}
+
 // Mutation returns the ProxyMutation object of the builder.
 func (_u *ProxyUpdateOne) Mutation() *ProxyMutation {
 	return _u.mutation
@@ -919,6 +1016,27 @@ func (_u *ProxyUpdateOne) RemoveTraffic(v ...*Traffic) *ProxyUpdateOne {
 	return _u.RemoveTrafficIDs(ids...)
 }
 
+// ClearRollups clears all "rollups" edges to the TrafficRollup entity.
+func (_u *ProxyUpdateOne) ClearRollups() *ProxyUpdateOne {
+	_u.mutation.ClearRollups()
+	return _u
+}
+
+// RemoveRollupIDs removes the "rollups" edge to TrafficRollup entities by IDs.
+func (_u *ProxyUpdateOne) RemoveRollupIDs(ids ...int) *ProxyUpdateOne {
+	_u.mutation.RemoveRollupIDs(ids...)
+	return _u
+}
+
+// RemoveRollups removes "rollups" edges to TrafficRollup entities.
+func (_u *ProxyUpdateOne) RemoveRollups(v ...*TrafficRollup) *ProxyUpdateOne {
+	ids := make([]int, len(v))
+	for i := range v {
+		ids[i] = v[i].ID
+	}
+	return _u.RemoveRollupIDs(ids...)
+}
+
 // Where appends a list predicates to the ProxyUpdate builder.
 func (_u *ProxyUpdateOne) Where(ps ...predicate.Proxy) *ProxyUpdateOne {
 	_u.mutation.Where(ps...)
@@ -1191,6 +1309,51 @@ func (_u *ProxyUpdateOne) sqlSave(ctx context.Context) (_node *Proxy, err error)
 		}
 		_spec.Edges.Add = append(_spec.Edges.Add, edge)
 	}
+	if _u.mutation.RollupsCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.RemovedRollupsIDs(); len(nodes) > 0 && !_u.mutation.RollupsCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.RollupsIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.O2M,
+			Inverse: false,
+			Table:   proxy.RollupsTable,
+			Columns: []string{proxy.RollupsColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Add = append(_spec.Edges.Add, edge)
+	}
 	_node = &Proxy{config: _u.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -118,23 +118,43 @@ func init() {
 	// traffic.HostValidator is a validator for the "host" field. It is called by the builders before save.
 	traffic.HostValidator = trafficDescHost.Validators[0].(func(string) error)
 	// trafficDescRequestBodySize is the schema descriptor for request_body_size field.
-	trafficDescRequestBodySize := trafficFields[8].Descriptor()
+	trafficDescRequestBodySize := trafficFields[9].Descriptor()
 	// traffic.DefaultRequestBodySize holds the default value on creation for the request_body_size field.
 	traffic.DefaultRequestBodySize = trafficDescRequestBodySize.Default.(int64)
 	// trafficDescRequestIsBinary is the schema descriptor for request_is_binary field.
-	trafficDescRequestIsBinary := trafficFields[9].Descriptor()
+	trafficDescRequestIsBinary := trafficFields[10].Descriptor()
 	// traffic.DefaultRequestIsBinary holds the default value on creation for the request_is_binary field.
 	traffic.DefaultRequestIsBinary = trafficDescRequestIsBinary.Default.(bool)
+	// trafficDescRequestBodyTruncated is the schema descriptor for request_body_truncated field.
+	trafficDescRequestBodyTruncated := trafficFields[11].Descriptor()
+	// traffic.DefaultRequestBodyTruncated holds the default value on creation for the request_body_truncated field.
+	traffic.DefaultRequestBodyTruncated = trafficDescRequestBodyTruncated.Default.(bool)
+	// trafficDescRequestDecodedSize is the schema descriptor for request_decoded_size field.
+	trafficDescRequestDecodedSize := trafficFields[12].Descriptor()
+	// traffic.DefaultRequestDecodedSize holds the default value on creation for the request_decoded_size field.
+	traffic.DefaultRequestDecodedSize = trafficDescRequestDecodedSize.Default.(int64)
 	// trafficDescResponseBodySize is the schema descriptor for response_body_size field.
-	trafficDescResponseBodySize := trafficFields[15].Descriptor()
+	trafficDescResponseBodySize := trafficFields[22].Descriptor()
 	// traffic.DefaultResponseBodySize holds the default value on creation for the response_body_size field.
 	traffic.DefaultResponseBodySize = trafficDescResponseBodySize.Default.(int64)
 	// trafficDescResponseIsBinary is the schema descriptor for response_is_binary field.
-	trafficDescResponseIsBinary := trafficFields[16].Descriptor()
+	trafficDescResponseIsBinary := trafficFields[23].Descriptor()
 	// traffic.DefaultResponseIsBinary holds the default value on creation for the response_is_binary field.
 	traffic.DefaultResponseIsBinary = trafficDescResponseIsBinary.Default.(bool)
+	// trafficDescResponseBodyTruncated is the schema descriptor for response_body_truncated field.
+	trafficDescResponseBodyTruncated := trafficFields[24].Descriptor()
+	// traffic.DefaultResponseBodyTruncated holds the default value on creation for the response_body_truncated field.
+	traffic.DefaultResponseBodyTruncated = trafficDescResponseBodyTruncated.Default.(bool)
+	// trafficDescResponseDecodedSize is the schema descriptor for response_decoded_size field.
+	trafficDescResponseDecodedSize := trafficFields[25].Descriptor()
+	// traffic.DefaultResponseDecodedSize holds the default value on creation for the response_decoded_size field.
+	traffic.DefaultResponseDecodedSize = trafficDescResponseDecodedSize.Default.(int64)
+	// trafficDescRecordType is the schema descriptor for record_type field.
+	trafficDescRecordType := trafficFields[31].Descriptor()
+	// traffic.DefaultRecordType holds the default value on creation for the record_type field.
+	traffic.DefaultRecordType = trafficDescRecordType.Default.(string)
 	// trafficDescCreatedAt is the schema descriptor for created_at field.
-	trafficDescCreatedAt := trafficFields[24].Descriptor()
+	trafficDescCreatedAt := trafficFields[44].Descriptor()
 	// traffic.DefaultCreatedAt holds the default value on creation for the created_at field.
 	traffic.DefaultCreatedAt = trafficDescCreatedAt.Default.(func() time.Time)
 	userFields := schema.User{}.Fields()
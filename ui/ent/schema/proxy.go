@@ -86,6 +86,8 @@ func (Proxy) Edges() []ent.Edge {
 			Comment("Organization this proxy belongs to"),
 		edge.To("traffic", Traffic.Type).
 			Comment("Traffic captured by this proxy"),
+		edge.To("rollups", TrafficRollup.Type).
+			Comment("Hourly traffic aggregates of this proxy"),
 	}
 }
 
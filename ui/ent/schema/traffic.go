@@ -7,10 +7,13 @@ import (
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
 	"entgo.io/ent/schema/index"
+
+	"github.com/grokify/omniproxy/pkg/capture"
 )
 
 // Traffic holds the schema definition for the Traffic entity.
-// A Traffic record represents a captured HTTP request/response pair.
+// A Traffic record represents a captured HTTP request/response pair or,
+// for WebSocket connections, a single frame.
 type Traffic struct {
 	ent.Schema
 }
@@ -37,7 +40,10 @@ func (Traffic) Fields() []ent.Field {
 			Comment("Query string"),
 		field.JSON("request_headers", map[string][]string{}).
 			Optional().
-			Comment("Request headers"),
+			Comment("Request headers, by lowercase name"),
+		field.JSON("request_header_names", []string{}).
+			Optional().
+			Comment("Request header names as captured, one per value, in order"),
 		field.Bytes("request_body").
 			Optional().
 			Comment("Request body (may be truncated)"),
@@ -47,9 +53,21 @@ func (Traffic) Fields() []ent.Field {
 		field.Bool("request_is_binary").
 			Default(false).
 			Comment("Whether request body is binary"),
+		field.Bool("request_body_truncated").
+			Default(false).
+			Comment("Whether only a prefix of the request body was captured"),
+		field.Int64("request_decoded_size").
+			Default(0).
+			Comment("Request body size after removing its Content-Encoding"),
 		field.String("content_type").
 			Optional().
 			Comment("Request Content-Type"),
+		field.String("http_version").
+			Optional().
+			Comment("Client protocol version (HTTP/1.1, HTTP/2.0)"),
+		field.Uint32("stream_id").
+			Optional().
+			Comment("HTTP/2 stream ID of the request"),
 
 		// Response fields
 		field.Int("status_code").
@@ -57,9 +75,15 @@ func (Traffic) Fields() []ent.Field {
 		field.String("status_text").
 			Optional().
 			Comment("HTTP status text"),
+		field.String("response_http_version").
+			Optional().
+			Comment("Upstream protocol version of the response"),
 		field.JSON("response_headers", map[string][]string{}).
 			Optional().
-			Comment("Response headers"),
+			Comment("Response headers, by lowercase name"),
+		field.JSON("response_header_names", []string{}).
+			Optional().
+			Comment("Response header names as captured, one per value, in order"),
 		field.Bytes("response_body").
 			Optional().
 			Comment("Response body (may be truncated)"),
@@ -69,6 +93,15 @@ func (Traffic) Fields() []ent.Field {
 		field.Bool("response_is_binary").
 			Default(false).
 			Comment("Whether response body is binary"),
+		field.Bool("response_body_truncated").
+			Default(false).
+			Comment("Whether only a prefix of the response body was captured"),
+		field.Int64("response_decoded_size").
+			Default(0).
+			Comment("Response body size after removing its Content-Encoding"),
+		field.JSON("response_events", []capture.StreamEvent{}).
+			Optional().
+			Comment("Events of a server-sent event or NDJSON stream response"),
 		field.String("response_content_type").
 			Optional().
 			Comment("Response Content-Type"),
@@ -81,12 +114,45 @@ func (Traffic) Fields() []ent.Field {
 		field.Float("ttfb_ms").
 			Optional().
 			Nillable().
-			Comment("Time to first byte in milliseconds"),
+			Comment("Time to first byte, or first event of streams, in milliseconds"),
+
+		// WebSocket fields
+		field.String("record_type").
+			Default("http").
+			Comment("Record type (http, websocket, websocket_frame)"),
+		field.String("connection_id").
+			Optional().
+			Comment("WebSocket connection ID shared by the upgrade and its frames"),
+		field.Int64("frame_sequence").
+			Optional().
+			Comment("Frame position within the WebSocket connection"),
+		field.String("frame_direction").
+			Optional().
+			Comment("Frame direction (client, server)"),
+		field.Int("frame_opcode").
+			Optional().
+			Comment("WebSocket frame opcode"),
+		field.Int("close_code").
+			Optional().
+			Comment("WebSocket close status code"),
+		field.String("close_reason").
+			Optional().
+			Comment("WebSocket close reason"),
+		field.Int("grpc_status").
+			Optional().
+			Nillable().
+			Comment("gRPC status code"),
+		field.String("grpc_message").
+			Optional().
+			Comment("gRPC status message"),
 
 		// Metadata
 		field.String("client_ip").
 			Optional().
 			Comment("Client IP address"),
+		field.String("auth_user").
+			Optional().
+			Comment("Authenticated proxy user"),
 		field.String("error").
 			Optional().
 			Comment("Error message if request failed"),
@@ -121,5 +187,7 @@ func (Traffic) Indexes() []ent.Index {
 		index.Fields("started_at"),
 		index.Fields("host", "path"),
 		index.Fields("method", "host", "path"),
+		index.Fields("connection_id"),
+		index.Fields("auth_user"),
 	}
 }
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// TrafficRollup holds the schema definition for the TrafficRollup entity.
+// A TrafficRollup aggregates one hour of a proxy's traffic to a host by
+// method and status code, so statistics outlive the traffic retention window.
+type TrafficRollup struct {
+	ent.Schema
+}
+
+// Fields of the TrafficRollup.
+func (TrafficRollup) Fields() []ent.Field {
+	return []ent.Field{
+		field.Time("bucket").
+			Comment("Start of the hour"),
+		field.String("host").
+			Comment("Target host"),
+		field.String("method").
+			Comment("HTTP method"),
+		field.Int("status_code").
+			Comment("HTTP response status code"),
+		field.Int64("requests").
+			Comment("Number of requests"),
+		field.Float("duration_sum_ms").
+			Comment("Sum of request durations in milliseconds"),
+		field.Float("duration_max_ms").
+			Comment("Longest request duration in milliseconds"),
+		field.JSON("duration_histogram", []int64{}).
+			Comment("Request counts per duration bucket (see backend.RollupDurationBounds)"),
+	}
+}
+
+// Edges of the TrafficRollup.
+func (TrafficRollup) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("proxy", Proxy.Type).
+			Ref("rollups").
+			Unique().
+			Required().
+			Comment("Proxy that captured the traffic"),
+	}
+}
+
+// Indexes of the TrafficRollup.
+func (TrafficRollup) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("bucket"),
+		index.Fields("host", "bucket"),
+		index.Fields("bucket", "host", "method", "status_code").
+			Edges("proxy").
+			Unique(),
+	}
+}
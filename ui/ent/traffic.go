@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
 )
@@ -31,38 +32,78 @@ type Traffic struct {
 	Path string `json:"path,omitempty"`
 	// Query string
 	Query string `json:"query,omitempty"`
-	// Request headers
+	// Request headers, by lowercase name
 	RequestHeaders map[string][]string `json:"request_headers,omitempty"`
+	// Request header names as captured, one per value, in order
+	RequestHeaderNames []string `json:"request_header_names,omitempty"`
 	// Request body (may be truncated)
 	RequestBody []byte `json:"request_body,omitempty"`
 	// Original request body size
 	RequestBodySize int64 `json:"request_body_size,omitempty"`
 	// Whether request body is binary
 	RequestIsBinary bool `json:"request_is_binary,omitempty"`
+	// Whether only a prefix of the request body was captured
+	RequestBodyTruncated bool `json:"request_body_truncated,omitempty"`
+	// Request body size after removing its Content-Encoding
+	RequestDecodedSize int64 `json:"request_decoded_size,omitempty"`
 	// Request Content-Type
 	ContentType string `json:"content_type,omitempty"`
+	// Client protocol version (HTTP/1.1, HTTP/2.0)
+	HTTPVersion string `json:"http_version,omitempty"`
+	// HTTP/2 stream ID of the request
+	StreamID uint32 `json:"stream_id,omitempty"`
 	// HTTP response status code
 	StatusCode int `json:"status_code,omitempty"`
 	// HTTP status text
 	StatusText string `json:"status_text,omitempty"`
-	// Response headers
+	// Upstream protocol version of the response
+	ResponseHTTPVersion string `json:"response_http_version,omitempty"`
+	// Response headers, by lowercase name
 	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	// Response header names as captured, one per value, in order
+	ResponseHeaderNames []string `json:"response_header_names,omitempty"`
 	// Response body (may be truncated)
 	ResponseBody []byte `json:"response_body,omitempty"`
 	// Original response body size
 	ResponseBodySize int64 `json:"response_body_size,omitempty"`
 	// Whether response body is binary
 	ResponseIsBinary bool `json:"response_is_binary,omitempty"`
+	// Whether only a prefix of the response body was captured
+	ResponseBodyTruncated bool `json:"response_body_truncated,omitempty"`
+	// Response body size after removing its Content-Encoding
+	ResponseDecodedSize int64 `json:"response_decoded_size,omitempty"`
+	// Events of a server-sent event or NDJSON stream response
+	ResponseEvents []capture.StreamEvent `json:"response_events,omitempty"`
 	// Response Content-Type
 	ResponseContentType string `json:"response_content_type,omitempty"`
 	// When the request started
 	StartedAt time.Time `json:"started_at,omitempty"`
 	// Total duration in milliseconds
 	DurationMs float64 `json:"duration_ms,omitempty"`
-	// Time to first byte in milliseconds
+	// Time to first byte, or first event of streams, in milliseconds
 	TtfbMs *float64 `json:"ttfb_ms,omitempty"`
+	// Record type (http, websocket, websocket_frame)
+	RecordType string `json:"record_type,omitempty"`
+	// WebSocket connection ID shared by the upgrade and its frames
+	ConnectionID string `json:"connection_id,omitempty"`
+	// Frame position within the WebSocket connection
+	FrameSequence int64 `json:"frame_sequence,omitempty"`
+	// Frame direction (client, server)
+	FrameDirection string `json:"frame_direction,omitempty"`
+	// WebSocket frame opcode
+	FrameOpcode int `json:"frame_opcode,omitempty"`
+	// WebSocket close status code
+	CloseCode int `json:"close_code,omitempty"`
+	// WebSocket close reason
+	CloseReason string `json:"close_reason,omitempty"`
+	// gRPC status code
+	GrpcStatus *int `json:"grpc_status,omitempty"`
+	// gRPC status message
+	GrpcMessage string `json:"grpc_message,omitempty"`
 	// Client IP address
 	ClientIP string `json:"client_ip,omitempty"`
+	// Authenticated proxy user
+	AuthUser string `json:"auth_user,omitempty"`
 	// Error message if request failed
 	Error string `json:"error,omitempty"`
 	// User-defined tags
@@ -101,15 +142,15 @@ func (*Traffic) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case traffic.FieldRequestHeaders, traffic.FieldRequestBody, traffic.FieldResponseHeaders, traffic.FieldResponseBody, traffic.FieldTags:
+		case traffic.FieldRequestHeaders, traffic.FieldRequestHeaderNames, traffic.FieldRequestBody, traffic.FieldResponseHeaders, traffic.FieldResponseHeaderNames, traffic.FieldResponseBody, traffic.FieldResponseEvents, traffic.FieldTags:
 			values[i] = new([]byte)
-		case traffic.FieldRequestIsBinary, traffic.FieldResponseIsBinary:
+		case traffic.FieldRequestIsBinary, traffic.FieldRequestBodyTruncated, traffic.FieldResponseIsBinary, traffic.FieldResponseBodyTruncated:
 			values[i] = new(sql.NullBool)
 		case traffic.FieldDurationMs, traffic.FieldTtfbMs:
 			values[i] = new(sql.NullFloat64)
-		case traffic.FieldID, traffic.FieldRequestBodySize, traffic.FieldStatusCode, traffic.FieldResponseBodySize:
+		case traffic.FieldID, traffic.FieldRequestBodySize, traffic.FieldRequestDecodedSize, traffic.FieldStreamID, traffic.FieldStatusCode, traffic.FieldResponseBodySize, traffic.FieldResponseDecodedSize, traffic.FieldFrameSequence, traffic.FieldFrameOpcode, traffic.FieldCloseCode, traffic.FieldGrpcStatus:
 			values[i] = new(sql.NullInt64)
-		case traffic.FieldMethod, traffic.FieldURL, traffic.FieldScheme, traffic.FieldHost, traffic.FieldPath, traffic.FieldQuery, traffic.FieldContentType, traffic.FieldStatusText, traffic.FieldResponseContentType, traffic.FieldClientIP, traffic.FieldError:
+		case traffic.FieldMethod, traffic.FieldURL, traffic.FieldScheme, traffic.FieldHost, traffic.FieldPath, traffic.FieldQuery, traffic.FieldContentType, traffic.FieldHTTPVersion, traffic.FieldStatusText, traffic.FieldResponseHTTPVersion, traffic.FieldResponseContentType, traffic.FieldRecordType, traffic.FieldConnectionID, traffic.FieldFrameDirection, traffic.FieldCloseReason, traffic.FieldGrpcMessage, traffic.FieldClientIP, traffic.FieldAuthUser, traffic.FieldError:
 			values[i] = new(sql.NullString)
 		case traffic.FieldStartedAt, traffic.FieldCreatedAt:
 			values[i] = new(sql.NullTime)
@@ -180,6 +221,14 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field request_headers: %w", err)
 				}
 			}
+		case traffic.FieldRequestHeaderNames:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field request_header_names", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.RequestHeaderNames); err != nil {
+					return fmt.Errorf("unmarshal field request_header_names: %w", err)
+				}
+			}
 		case traffic.FieldRequestBody:
 			if value, ok := values[i].(*[]byte); !ok {
 				return fmt.Errorf("unexpected type %T for field request_body", values[i])
@@ -198,12 +247,36 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.RequestIsBinary = value.Bool
 			}
+		case traffic.FieldRequestBodyTruncated:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field request_body_truncated", values[i])
+			} else if value.Valid {
+				_m.RequestBodyTruncated = value.Bool
+			}
+		case traffic.FieldRequestDecodedSize:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field request_decoded_size", values[i])
+			} else if value.Valid {
+				_m.RequestDecodedSize = value.Int64
+			}
 		case traffic.FieldContentType:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field content_type", values[i])
 			} else if value.Valid {
 				_m.ContentType = value.String
 			}
+		case traffic.FieldHTTPVersion:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field http_version", values[i])
+			} else if value.Valid {
+				_m.HTTPVersion = value.String
+			}
+		case traffic.FieldStreamID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field stream_id", values[i])
+			} else if value.Valid {
+				_m.StreamID = uint32(value.Int64)
+			}
 		case traffic.FieldStatusCode:
 			if value, ok := values[i].(*sql.NullInt64); !ok {
 				return fmt.Errorf("unexpected type %T for field status_code", values[i])
@@ -216,6 +289,12 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.StatusText = value.String
 			}
+		case traffic.FieldResponseHTTPVersion:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field response_http_version", values[i])
+			} else if value.Valid {
+				_m.ResponseHTTPVersion = value.String
+			}
 		case traffic.FieldResponseHeaders:
 			if value, ok := values[i].(*[]byte); !ok {
 				return fmt.Errorf("unexpected type %T for field response_headers", values[i])
@@ -224,6 +303,14 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field response_headers: %w", err)
 				}
 			}
+		case traffic.FieldResponseHeaderNames:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field response_header_names", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ResponseHeaderNames); err != nil {
+					return fmt.Errorf("unmarshal field response_header_names: %w", err)
+				}
+			}
 		case traffic.FieldResponseBody:
 			if value, ok := values[i].(*[]byte); !ok {
 				return fmt.Errorf("unexpected type %T for field response_body", values[i])
@@ -242,6 +329,26 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.ResponseIsBinary = value.Bool
 			}
+		case traffic.FieldResponseBodyTruncated:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field response_body_truncated", values[i])
+			} else if value.Valid {
+				_m.ResponseBodyTruncated = value.Bool
+			}
+		case traffic.FieldResponseDecodedSize:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_decoded_size", values[i])
+			} else if value.Valid {
+				_m.ResponseDecodedSize = value.Int64
+			}
+		case traffic.FieldResponseEvents:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field response_events", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ResponseEvents); err != nil {
+					return fmt.Errorf("unmarshal field response_events: %w", err)
+				}
+			}
 		case traffic.FieldResponseContentType:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field response_content_type", values[i])
@@ -267,12 +374,73 @@ func (_m *Traffic) assignValues(columns []string, values []any) error {
 				_m.TtfbMs = new(float64)
 				*_m.TtfbMs = value.Float64
 			}
+		case traffic.FieldRecordType:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field record_type", values[i])
+			} else if value.Valid {
+				_m.RecordType = value.String
+			}
+		case traffic.FieldConnectionID:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field connection_id", values[i])
+			} else if value.Valid {
+				_m.ConnectionID = value.String
+			}
+		case traffic.FieldFrameSequence:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field frame_sequence", values[i])
+			} else if value.Valid {
+				_m.FrameSequence = value.Int64
+			}
+		case traffic.FieldFrameDirection:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field frame_direction", values[i])
+			} else if value.Valid {
+				_m.FrameDirection = value.String
+			}
+		case traffic.FieldFrameOpcode:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field frame_opcode", values[i])
+			} else if value.Valid {
+				_m.FrameOpcode = int(value.Int64)
+			}
+		case traffic.FieldCloseCode:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field close_code", values[i])
+			} else if value.Valid {
+				_m.CloseCode = int(value.Int64)
+			}
+		case traffic.FieldCloseReason:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field close_reason", values[i])
+			} else if value.Valid {
+				_m.CloseReason = value.String
+			}
+		case traffic.FieldGrpcStatus:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field grpc_status", values[i])
+			} else if value.Valid {
+				_m.GrpcStatus = new(int)
+				*_m.GrpcStatus = int(value.Int64)
+			}
+		case traffic.FieldGrpcMessage:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field grpc_message", values[i])
+			} else if value.Valid {
+				_m.GrpcMessage = value.String
+			}
 		case traffic.FieldClientIP:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field client_ip", values[i])
 			} else if value.Valid {
 				_m.ClientIP = value.String
 			}
+		case traffic.FieldAuthUser:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field auth_user", values[i])
+			} else if value.Valid {
+				_m.AuthUser = value.String
+			}
 		case traffic.FieldError:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field error", values[i])
@@ -362,6 +530,9 @@ func (_m *Traffic) String() string {
 	builder.WriteString("request_headers=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RequestHeaders))
 	builder.WriteString(", ")
+	builder.WriteString("request_header_names=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestHeaderNames))
+	builder.WriteString(", ")
 	builder.WriteString("request_body=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RequestBody))
 	builder.WriteString(", ")
@@ -371,18 +542,36 @@ func (_m *Traffic) String() string {
 	builder.WriteString("request_is_binary=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RequestIsBinary))
 	builder.WriteString(", ")
+	builder.WriteString("request_body_truncated=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestBodyTruncated))
+	builder.WriteString(", ")
+	builder.WriteString("request_decoded_size=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestDecodedSize))
+	builder.WriteString(", ")
 	builder.WriteString("content_type=")
 	builder.WriteString(_m.ContentType)
 	builder.WriteString(", ")
+	builder.WriteString("http_version=")
+	builder.WriteString(_m.HTTPVersion)
+	builder.WriteString(", ")
+	builder.WriteString("stream_id=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StreamID))
+	builder.WriteString(", ")
 	builder.WriteString("status_code=")
 	builder.WriteString(fmt.Sprintf("%v", _m.StatusCode))
 	builder.WriteString(", ")
 	builder.WriteString("status_text=")
 	builder.WriteString(_m.StatusText)
 	builder.WriteString(", ")
+	builder.WriteString("response_http_version=")
+	builder.WriteString(_m.ResponseHTTPVersion)
+	builder.WriteString(", ")
 	builder.WriteString("response_headers=")
 	builder.WriteString(fmt.Sprintf("%v", _m.ResponseHeaders))
 	builder.WriteString(", ")
+	builder.WriteString("response_header_names=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseHeaderNames))
+	builder.WriteString(", ")
 	builder.WriteString("response_body=")
 	builder.WriteString(fmt.Sprintf("%v", _m.ResponseBody))
 	builder.WriteString(", ")
@@ -392,6 +581,15 @@ func (_m *Traffic) String() string {
 	builder.WriteString("response_is_binary=")
 	builder.WriteString(fmt.Sprintf("%v", _m.ResponseIsBinary))
 	builder.WriteString(", ")
+	builder.WriteString("response_body_truncated=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseBodyTruncated))
+	builder.WriteString(", ")
+	builder.WriteString("response_decoded_size=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseDecodedSize))
+	builder.WriteString(", ")
+	builder.WriteString("response_events=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseEvents))
+	builder.WriteString(", ")
 	builder.WriteString("response_content_type=")
 	builder.WriteString(_m.ResponseContentType)
 	builder.WriteString(", ")
@@ -406,9 +604,41 @@ func (_m *Traffic) String() string {
 		builder.WriteString(fmt.Sprintf("%v", *v))
 	}
 	builder.WriteString(", ")
+	builder.WriteString("record_type=")
+	builder.WriteString(_m.RecordType)
+	builder.WriteString(", ")
+	builder.WriteString("connection_id=")
+	builder.WriteString(_m.ConnectionID)
+	builder.WriteString(", ")
+	builder.WriteString("frame_sequence=")
+	builder.WriteString(fmt.Sprintf("%v", _m.FrameSequence))
+	builder.WriteString(", ")
+	builder.WriteString("frame_direction=")
+	builder.WriteString(_m.FrameDirection)
+	builder.WriteString(", ")
+	builder.WriteString("frame_opcode=")
+	builder.WriteString(fmt.Sprintf("%v", _m.FrameOpcode))
+	builder.WriteString(", ")
+	builder.WriteString("close_code=")
+	builder.WriteString(fmt.Sprintf("%v", _m.CloseCode))
+	builder.WriteString(", ")
+	builder.WriteString("close_reason=")
+	builder.WriteString(_m.CloseReason)
+	builder.WriteString(", ")
+	if v := _m.GrpcStatus; v != nil {
+		builder.WriteString("grpc_status=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("grpc_message=")
+	builder.WriteString(_m.GrpcMessage)
+	builder.WriteString(", ")
 	builder.WriteString("client_ip=")
 	builder.WriteString(_m.ClientIP)
 	builder.WriteString(", ")
+	builder.WriteString("auth_user=")
+	builder.WriteString(_m.AuthUser)
+	builder.WriteString(", ")
 	builder.WriteString("error=")
 	builder.WriteString(_m.Error)
 	builder.WriteString(", ")
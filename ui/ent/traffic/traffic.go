@@ -28,26 +28,46 @@ const (
 	FieldQuery = "query"
 	// FieldRequestHeaders holds the string denoting the request_headers field in the database.
 	FieldRequestHeaders = "request_headers"
+	// FieldRequestHeaderNames holds the string denoting the request_header_names field in the database.
+	FieldRequestHeaderNames = "request_header_names"
 	// FieldRequestBody holds the string denoting the request_body field in the database.
 	FieldRequestBody = "request_body"
 	// FieldRequestBodySize holds the string denoting the request_body_size field in the database.
 	FieldRequestBodySize = "request_body_size"
 	// FieldRequestIsBinary holds the string denoting the request_is_binary field in the database.
 	FieldRequestIsBinary = "request_is_binary"
+	// FieldRequestBodyTruncated holds the string denoting the request_body_truncated field in the database.
+	FieldRequestBodyTruncated = "request_body_truncated"
+	// FieldRequestDecodedSize holds the string denoting the request_decoded_size field in the database.
+	FieldRequestDecodedSize = "request_decoded_size"
 	// FieldContentType holds the string denoting the content_type field in the database.
 	FieldContentType = "content_type"
+	// FieldHTTPVersion holds the string denoting the http_version field in the database.
+	FieldHTTPVersion = "http_version"
+	// FieldStreamID holds the string denoting the stream_id field in the database.
+	FieldStreamID = "stream_id"
 	// FieldStatusCode holds the string denoting the status_code field in the database.
 	FieldStatusCode = "status_code"
 	// FieldStatusText holds the string denoting the status_text field in the database.
 	FieldStatusText = "status_text"
+	// FieldResponseHTTPVersion holds the string denoting the response_http_version field in the database.
+	FieldResponseHTTPVersion = "response_http_version"
 	// FieldResponseHeaders holds the string denoting the response_headers field in the database.
 	FieldResponseHeaders = "response_headers"
+	// FieldResponseHeaderNames holds the string denoting the response_header_names field in the database.
+	FieldResponseHeaderNames = "response_header_names"
 	// FieldResponseBody holds the string denoting the response_body field in the database.
 	FieldResponseBody = "response_body"
 	// FieldResponseBodySize holds the string denoting the response_body_size field in the database.
 	FieldResponseBodySize = "response_body_size"
 	// FieldResponseIsBinary holds the string denoting the response_is_binary field in the database.
 	FieldResponseIsBinary = "response_is_binary"
+	// FieldResponseBodyTruncated holds the string denoting the response_body_truncated field in the database.
+	FieldResponseBodyTruncated = "response_body_truncated"
+	// FieldResponseDecodedSize holds the string denoting the response_decoded_size field in the database.
+	FieldResponseDecodedSize = "response_decoded_size"
+	// FieldResponseEvents holds the string denoting the response_events field in the database.
+	FieldResponseEvents = "response_events"
 	// FieldResponseContentType holds the string denoting the response_content_type field in the database.
 	FieldResponseContentType = "response_content_type"
 	// FieldStartedAt holds the string denoting the started_at field in the database.
@@ -56,8 +76,28 @@ const (
 	FieldDurationMs = "duration_ms"
 	// FieldTtfbMs holds the string denoting the ttfb_ms field in the database.
 	FieldTtfbMs = "ttfb_ms"
+	// FieldRecordType holds the string denoting the record_type field in the database.
+	FieldRecordType = "record_type"
+	// FieldConnectionID holds the string denoting the connection_id field in the database.
+	FieldConnectionID = "connection_id"
+	// FieldFrameSequence holds the string denoting the frame_sequence field in the database.
+	FieldFrameSequence = "frame_sequence"
+	// FieldFrameDirection holds the string denoting the frame_direction field in the database.
+	FieldFrameDirection = "frame_direction"
+	// FieldFrameOpcode holds the string denoting the frame_opcode field in the database.
+	FieldFrameOpcode = "frame_opcode"
+	// FieldCloseCode holds the string denoting the close_code field in the database.
+	FieldCloseCode = "close_code"
+	// FieldCloseReason holds the string denoting the close_reason field in the database.
+	FieldCloseReason = "close_reason"
+	// FieldGrpcStatus holds the string denoting the grpc_status field in the database.
+	FieldGrpcStatus = "grpc_status"
+	// FieldGrpcMessage holds the string denoting the grpc_message field in the database.
+	FieldGrpcMessage = "grpc_message"
 	// FieldClientIP holds the string denoting the client_ip field in the database.
 	FieldClientIP = "client_ip"
+	// FieldAuthUser holds the string denoting the auth_user field in the database.
+	FieldAuthUser = "auth_user"
 	// FieldError holds the string denoting the error field in the database.
 	FieldError = "error"
 	// FieldTags holds the string denoting the tags field in the database.
@@ -87,21 +127,41 @@ var Columns = []string{
 	FieldPath,
 	FieldQuery,
 	FieldRequestHeaders,
+	FieldRequestHeaderNames,
 	FieldRequestBody,
 	FieldRequestBodySize,
 	FieldRequestIsBinary,
+	FieldRequestBodyTruncated,
+	FieldRequestDecodedSize,
 	FieldContentType,
+	FieldHTTPVersion,
+	FieldStreamID,
 	FieldStatusCode,
 	FieldStatusText,
+	FieldResponseHTTPVersion,
 	FieldResponseHeaders,
+	FieldResponseHeaderNames,
 	FieldResponseBody,
 	FieldResponseBodySize,
 	FieldResponseIsBinary,
+	FieldResponseBodyTruncated,
+	FieldResponseDecodedSize,
+	FieldResponseEvents,
 	FieldResponseContentType,
 	FieldStartedAt,
 	FieldDurationMs,
 	FieldTtfbMs,
+	FieldRecordType,
+	FieldConnectionID,
+	FieldFrameSequence,
+	FieldFrameDirection,
+	FieldFrameOpcode,
+	FieldCloseCode,
+	FieldCloseReason,
+	FieldGrpcStatus,
+	FieldGrpcMessage,
 	FieldClientIP,
+	FieldAuthUser,
 	FieldError,
 	FieldTags,
 	FieldCreatedAt,
@@ -139,10 +199,20 @@ var (
 	DefaultRequestBodySize int64
 	// DefaultRequestIsBinary holds the default value on creation for the "request_is_binary" field.
 	DefaultRequestIsBinary bool
+	// DefaultRequestBodyTruncated holds the default value on creation for the "request_body_truncated" field.
+	DefaultRequestBodyTruncated bool
+	// DefaultRequestDecodedSize holds the default value on creation for the "request_decoded_size" field.
+	DefaultRequestDecodedSize int64
 	// DefaultResponseBodySize holds the default value on creation for the "response_body_size" field.
 	DefaultResponseBodySize int64
 	// DefaultResponseIsBinary holds the default value on creation for the "response_is_binary" field.
 	DefaultResponseIsBinary bool
+	// DefaultResponseBodyTruncated holds the default value on creation for the "response_body_truncated" field.
+	DefaultResponseBodyTruncated bool
+	// DefaultResponseDecodedSize holds the default value on creation for the "response_decoded_size" field.
+	DefaultResponseDecodedSize int64
+	// DefaultRecordType holds the default value on creation for the "record_type" field.
+	DefaultRecordType string
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 )
@@ -195,11 +265,31 @@ func ByRequestIsBinary(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRequestIsBinary, opts...).ToFunc()
 }
 
+// ByRequestBodyTruncated orders the results by the request_body_truncated field.
+func ByRequestBodyTruncated(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequestBodyTruncated, opts...).ToFunc()
+}
+
+// ByRequestDecodedSize orders the results by the request_decoded_size field.
+func ByRequestDecodedSize(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequestDecodedSize, opts...).ToFunc()
+}
+
 // ByContentType orders the results by the content_type field.
 func ByContentType(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldContentType, opts...).ToFunc()
 }
 
+// ByHTTPVersion orders the results by the http_version field.
+func ByHTTPVersion(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldHTTPVersion, opts...).ToFunc()
+}
+
+// ByStreamID orders the results by the stream_id field.
+func ByStreamID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStreamID, opts...).ToFunc()
+}
+
 // ByStatusCode orders the results by the status_code field.
 func ByStatusCode(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldStatusCode, opts...).ToFunc()
@@ -210,6 +300,11 @@ func ByStatusText(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldStatusText, opts...).ToFunc()
 }
 
+// ByResponseHTTPVersion orders the results by the response_http_version field.
+func ByResponseHTTPVersion(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseHTTPVersion, opts...).ToFunc()
+}
+
 // ByResponseBodySize orders the results by the response_body_size field.
 func ByResponseBodySize(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldResponseBodySize, opts...).ToFunc()
@@ -220,6 +315,16 @@ func ByResponseIsBinary(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldResponseIsBinary, opts...).ToFunc()
 }
 
+// ByResponseBodyTruncated orders the results by the response_body_truncated field.
+func ByResponseBodyTruncated(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseBodyTruncated, opts...).ToFunc()
+}
+
+// ByResponseDecodedSize orders the results by the response_decoded_size field.
+func ByResponseDecodedSize(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseDecodedSize, opts...).ToFunc()
+}
+
 // ByResponseContentType orders the results by the response_content_type field.
 func ByResponseContentType(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldResponseContentType, opts...).ToFunc()
@@ -240,11 +345,61 @@ func ByTtfbMs(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldTtfbMs, opts...).ToFunc()
 }
 
+// ByRecordType orders the results by the record_type field.
+func ByRecordType(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRecordType, opts...).ToFunc()
+}
+
+// ByConnectionID orders the results by the connection_id field.
+func ByConnectionID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldConnectionID, opts...).ToFunc()
+}
+
+// ByFrameSequence orders the results by the frame_sequence field.
+func ByFrameSequence(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldFrameSequence, opts...).ToFunc()
+}
+
+// ByFrameDirection orders the results by the frame_direction field.
+func ByFrameDirection(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldFrameDirection, opts...).ToFunc()
+}
+
+// ByFrameOpcode orders the results by the frame_opcode field.
+func ByFrameOpcode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldFrameOpcode, opts...).ToFunc()
+}
+
+// ByCloseCode orders the results by the close_code field.
+func ByCloseCode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCloseCode, opts...).ToFunc()
+}
+
+// ByCloseReason orders the results by the close_reason field.
+func ByCloseReason(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCloseReason, opts...).ToFunc()
+}
+
+// ByGrpcStatus orders the results by the grpc_status field.
+func ByGrpcStatus(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldGrpcStatus, opts...).ToFunc()
+}
+
+// ByGrpcMessage orders the results by the grpc_message field.
+func ByGrpcMessage(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldGrpcMessage, opts...).ToFunc()
+}
+
 // ByClientIP orders the results by the client_ip field.
 func ByClientIP(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldClientIP, opts...).ToFunc()
 }
 
+// ByAuthUser orders the results by the auth_user field.
+func ByAuthUser(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAuthUser, opts...).ToFunc()
+}
+
 // ByError orders the results by the error field.
 func ByError(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldError, opts...).ToFunc()
@@ -100,11 +100,31 @@ func RequestIsBinary(v bool) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldRequestIsBinary, v))
 }
 
+// RequestBodyTruncated applies equality check predicate on the "request_body_truncated" field. It's identical to RequestBodyTruncatedEQ.
+func RequestBodyTruncated(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRequestBodyTruncated, v))
+}
+
+// RequestDecodedSize applies equality check predicate on the "request_decoded_size" field. It's identical to RequestDecodedSizeEQ.
+func RequestDecodedSize(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRequestDecodedSize, v))
+}
+
 // ContentType applies equality check predicate on the "content_type" field. It's identical to ContentTypeEQ.
 func ContentType(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldContentType, v))
 }
 
+// HTTPVersion applies equality check predicate on the "http_version" field. It's identical to HTTPVersionEQ.
+func HTTPVersion(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldHTTPVersion, v))
+}
+
+// StreamID applies equality check predicate on the "stream_id" field. It's identical to StreamIDEQ.
+func StreamID(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldStreamID, v))
+}
+
 // StatusCode applies equality check predicate on the "status_code" field. It's identical to StatusCodeEQ.
 func StatusCode(v int) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldStatusCode, v))
@@ -115,6 +135,11 @@ func StatusText(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldStatusText, v))
 }
 
+// ResponseHTTPVersion applies equality check predicate on the "response_http_version" field. It's identical to ResponseHTTPVersionEQ.
+func ResponseHTTPVersion(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseHTTPVersion, v))
+}
+
 // ResponseBody applies equality check predicate on the "response_body" field. It's identical to ResponseBodyEQ.
 func ResponseBody(v []byte) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldResponseBody, v))
@@ -130,6 +155,16 @@ func ResponseIsBinary(v bool) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldResponseIsBinary, v))
 }
 
+// ResponseBodyTruncated applies equality check predicate on the "response_body_truncated" field. It's identical to ResponseBodyTruncatedEQ.
+func ResponseBodyTruncated(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseBodyTruncated, v))
+}
+
+// ResponseDecodedSize applies equality check predicate on the "response_decoded_size" field. It's identical to ResponseDecodedSizeEQ.
+func ResponseDecodedSize(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseDecodedSize, v))
+}
+
 // ResponseContentType applies equality check predicate on the "response_content_type" field. It's identical to ResponseContentTypeEQ.
 func ResponseContentType(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldResponseContentType, v))
@@ -150,11 +185,61 @@ func TtfbMs(v float64) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldTtfbMs, v))
 }
 
+// RecordType applies equality check predicate on the "record_type" field. It's identical to RecordTypeEQ.
+func RecordType(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRecordType, v))
+}
+
+// ConnectionID applies equality check predicate on the "connection_id" field. It's identical to ConnectionIDEQ.
+func ConnectionID(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldConnectionID, v))
+}
+
+// FrameSequence applies equality check predicate on the "frame_sequence" field. It's identical to FrameSequenceEQ.
+func FrameSequence(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameSequence, v))
+}
+
+// FrameDirection applies equality check predicate on the "frame_direction" field. It's identical to FrameDirectionEQ.
+func FrameDirection(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameDirection, v))
+}
+
+// FrameOpcode applies equality check predicate on the "frame_opcode" field. It's identical to FrameOpcodeEQ.
+func FrameOpcode(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameOpcode, v))
+}
+
+// CloseCode applies equality check predicate on the "close_code" field. It's identical to CloseCodeEQ.
+func CloseCode(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldCloseCode, v))
+}
+
+// CloseReason applies equality check predicate on the "close_reason" field. It's identical to CloseReasonEQ.
+func CloseReason(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldCloseReason, v))
+}
+
+// GrpcStatus applies equality check predicate on the "grpc_status" field. It's identical to GrpcStatusEQ.
+func GrpcStatus(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldGrpcStatus, v))
+}
+
+// GrpcMessage applies equality check predicate on the "grpc_message" field. It's identical to GrpcMessageEQ.
+func GrpcMessage(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldGrpcMessage, v))
+}
+
 // ClientIP applies equality check predicate on the "client_ip" field. It's identical to ClientIPEQ.
 func ClientIP(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldClientIP, v))
 }
 
+// AuthUser applies equality check predicate on the "auth_user" field. It's identical to AuthUserEQ.
+func AuthUser(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldAuthUser, v))
+}
+
 // Error applies equality check predicate on the "error" field. It's identical to ErrorEQ.
 func Error(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldError, v))
@@ -575,6 +660,16 @@ func RequestHeadersNotNil() predicate.Traffic {
 	return predicate.Traffic(sql.FieldNotNull(FieldRequestHeaders))
 }
 
+// RequestHeaderNamesIsNil applies the IsNil predicate on the "request_header_names" field.
+func RequestHeaderNamesIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldRequestHeaderNames))
+}
+
+// RequestHeaderNamesNotNil applies the NotNil predicate on the "request_header_names" field.
+func RequestHeaderNamesNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldRequestHeaderNames))
+}
+
 // RequestBodyEQ applies the EQ predicate on the "request_body" field.
 func RequestBodyEQ(v []byte) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldRequestBody, v))
@@ -675,6 +770,56 @@ func RequestIsBinaryNEQ(v bool) predicate.Traffic {
 	return predicate.Traffic(sql.FieldNEQ(FieldRequestIsBinary, v))
 }
 
+// RequestBodyTruncatedEQ applies the EQ predicate on the "request_body_truncated" field.
+func RequestBodyTruncatedEQ(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRequestBodyTruncated, v))
+}
+
+// RequestBodyTruncatedNEQ applies the NEQ predicate on the "request_body_truncated" field.
+func RequestBodyTruncatedNEQ(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldRequestBodyTruncated, v))
+}
+
+// RequestDecodedSizeEQ applies the EQ predicate on the "request_decoded_size" field.
+func RequestDecodedSizeEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRequestDecodedSize, v))
+}
+
+// RequestDecodedSizeNEQ applies the NEQ predicate on the "request_decoded_size" field.
+func RequestDecodedSizeNEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldRequestDecodedSize, v))
+}
+
+// RequestDecodedSizeIn applies the In predicate on the "request_decoded_size" field.
+func RequestDecodedSizeIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldRequestDecodedSize, vs...))
+}
+
+// RequestDecodedSizeNotIn applies the NotIn predicate on the "request_decoded_size" field.
+func RequestDecodedSizeNotIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldRequestDecodedSize, vs...))
+}
+
+// RequestDecodedSizeGT applies the GT predicate on the "request_decoded_size" field.
+func RequestDecodedSizeGT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldRequestDecodedSize, v))
+}
+
+// RequestDecodedSizeGTE applies the GTE predicate on the "request_decoded_size" field.
+func RequestDecodedSizeGTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldRequestDecodedSize, v))
+}
+
+// RequestDecodedSizeLT applies the LT predicate on the "request_decoded_size" field.
+func RequestDecodedSizeLT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldRequestDecodedSize, v))
+}
+
+// RequestDecodedSizeLTE applies the LTE predicate on the "request_decoded_size" field.
+func RequestDecodedSizeLTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldRequestDecodedSize, v))
+}
+
 // ContentTypeEQ applies the EQ predicate on the "content_type" field.
 func ContentTypeEQ(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldContentType, v))
@@ -750,6 +895,131 @@ func ContentTypeContainsFold(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldContainsFold(FieldContentType, v))
 }
 
+// HTTPVersionEQ applies the EQ predicate on the "http_version" field.
+func HTTPVersionEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldHTTPVersion, v))
+}
+
+// HTTPVersionNEQ applies the NEQ predicate on the "http_version" field.
+func HTTPVersionNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldHTTPVersion, v))
+}
+
+// HTTPVersionIn applies the In predicate on the "http_version" field.
+func HTTPVersionIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldHTTPVersion, vs...))
+}
+
+// HTTPVersionNotIn applies the NotIn predicate on the "http_version" field.
+func HTTPVersionNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldHTTPVersion, vs...))
+}
+
+// HTTPVersionGT applies the GT predicate on the "http_version" field.
+func HTTPVersionGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldHTTPVersion, v))
+}
+
+// HTTPVersionGTE applies the GTE predicate on the "http_version" field.
+func HTTPVersionGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldHTTPVersion, v))
+}
+
+// HTTPVersionLT applies the LT predicate on the "http_version" field.
+func HTTPVersionLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldHTTPVersion, v))
+}
+
+// HTTPVersionLTE applies the LTE predicate on the "http_version" field.
+func HTTPVersionLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldHTTPVersion, v))
+}
+
+// HTTPVersionContains applies the Contains predicate on the "http_version" field.
+func HTTPVersionContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldHTTPVersion, v))
+}
+
+// HTTPVersionHasPrefix applies the HasPrefix predicate on the "http_version" field.
+func HTTPVersionHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldHTTPVersion, v))
+}
+
+// HTTPVersionHasSuffix applies the HasSuffix predicate on the "http_version" field.
+func HTTPVersionHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldHTTPVersion, v))
+}
+
+// HTTPVersionIsNil applies the IsNil predicate on the "http_version" field.
+func HTTPVersionIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldHTTPVersion))
+}
+
+// HTTPVersionNotNil applies the NotNil predicate on the "http_version" field.
+func HTTPVersionNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldHTTPVersion))
+}
+
+// HTTPVersionEqualFold applies the EqualFold predicate on the "http_version" field.
+func HTTPVersionEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldHTTPVersion, v))
+}
+
+// HTTPVersionContainsFold applies the ContainsFold predicate on the "http_version" field.
+func HTTPVersionContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldHTTPVersion, v))
+}
+
+// StreamIDEQ applies the EQ predicate on the "stream_id" field.
+func StreamIDEQ(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldStreamID, v))
+}
+
+// StreamIDNEQ applies the NEQ predicate on the "stream_id" field.
+func StreamIDNEQ(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldStreamID, v))
+}
+
+// StreamIDIn applies the In predicate on the "stream_id" field.
+func StreamIDIn(vs ...uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldStreamID, vs...))
+}
+
+// StreamIDNotIn applies the NotIn predicate on the "stream_id" field.
+func StreamIDNotIn(vs ...uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldStreamID, vs...))
+}
+
+// StreamIDGT applies the GT predicate on the "stream_id" field.
+func StreamIDGT(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldStreamID, v))
+}
+
+// StreamIDGTE applies the GTE predicate on the "stream_id" field.
+func StreamIDGTE(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldStreamID, v))
+}
+
+// StreamIDLT applies the LT predicate on the "stream_id" field.
+func StreamIDLT(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldStreamID, v))
+}
+
+// StreamIDLTE applies the LTE predicate on the "stream_id" field.
+func StreamIDLTE(v uint32) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldStreamID, v))
+}
+
+// StreamIDIsNil applies the IsNil predicate on the "stream_id" field.
+func StreamIDIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldStreamID))
+}
+
+// StreamIDNotNil applies the NotNil predicate on the "stream_id" field.
+func StreamIDNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldStreamID))
+}
+
 // StatusCodeEQ applies the EQ predicate on the "status_code" field.
 func StatusCodeEQ(v int) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldStatusCode, v))
@@ -865,6 +1135,81 @@ func StatusTextContainsFold(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldContainsFold(FieldStatusText, v))
 }
 
+// ResponseHTTPVersionEQ applies the EQ predicate on the "response_http_version" field.
+func ResponseHTTPVersionEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionNEQ applies the NEQ predicate on the "response_http_version" field.
+func ResponseHTTPVersionNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionIn applies the In predicate on the "response_http_version" field.
+func ResponseHTTPVersionIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldResponseHTTPVersion, vs...))
+}
+
+// ResponseHTTPVersionNotIn applies the NotIn predicate on the "response_http_version" field.
+func ResponseHTTPVersionNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldResponseHTTPVersion, vs...))
+}
+
+// ResponseHTTPVersionGT applies the GT predicate on the "response_http_version" field.
+func ResponseHTTPVersionGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionGTE applies the GTE predicate on the "response_http_version" field.
+func ResponseHTTPVersionGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionLT applies the LT predicate on the "response_http_version" field.
+func ResponseHTTPVersionLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionLTE applies the LTE predicate on the "response_http_version" field.
+func ResponseHTTPVersionLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionContains applies the Contains predicate on the "response_http_version" field.
+func ResponseHTTPVersionContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionHasPrefix applies the HasPrefix predicate on the "response_http_version" field.
+func ResponseHTTPVersionHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionHasSuffix applies the HasSuffix predicate on the "response_http_version" field.
+func ResponseHTTPVersionHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionIsNil applies the IsNil predicate on the "response_http_version" field.
+func ResponseHTTPVersionIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldResponseHTTPVersion))
+}
+
+// ResponseHTTPVersionNotNil applies the NotNil predicate on the "response_http_version" field.
+func ResponseHTTPVersionNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldResponseHTTPVersion))
+}
+
+// ResponseHTTPVersionEqualFold applies the EqualFold predicate on the "response_http_version" field.
+func ResponseHTTPVersionEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldResponseHTTPVersion, v))
+}
+
+// ResponseHTTPVersionContainsFold applies the ContainsFold predicate on the "response_http_version" field.
+func ResponseHTTPVersionContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldResponseHTTPVersion, v))
+}
+
 // ResponseHeadersIsNil applies the IsNil predicate on the "response_headers" field.
 func ResponseHeadersIsNil() predicate.Traffic {
 	return predicate.Traffic(sql.FieldIsNull(FieldResponseHeaders))
@@ -875,6 +1220,16 @@ func ResponseHeadersNotNil() predicate.Traffic {
 	return predicate.Traffic(sql.FieldNotNull(FieldResponseHeaders))
 }
 
+// ResponseHeaderNamesIsNil applies the IsNil predicate on the "response_header_names" field.
+func ResponseHeaderNamesIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldResponseHeaderNames))
+}
+
+// ResponseHeaderNamesNotNil applies the NotNil predicate on the "response_header_names" field.
+func ResponseHeaderNamesNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldResponseHeaderNames))
+}
+
 // ResponseBodyEQ applies the EQ predicate on the "response_body" field.
 func ResponseBodyEQ(v []byte) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldResponseBody, v))
@@ -975,6 +1330,66 @@ func ResponseIsBinaryNEQ(v bool) predicate.Traffic {
 	return predicate.Traffic(sql.FieldNEQ(FieldResponseIsBinary, v))
 }
 
+// ResponseBodyTruncatedEQ applies the EQ predicate on the "response_body_truncated" field.
+func ResponseBodyTruncatedEQ(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseBodyTruncated, v))
+}
+
+// ResponseBodyTruncatedNEQ applies the NEQ predicate on the "response_body_truncated" field.
+func ResponseBodyTruncatedNEQ(v bool) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldResponseBodyTruncated, v))
+}
+
+// ResponseDecodedSizeEQ applies the EQ predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldResponseDecodedSize, v))
+}
+
+// ResponseDecodedSizeNEQ applies the NEQ predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeNEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldResponseDecodedSize, v))
+}
+
+// ResponseDecodedSizeIn applies the In predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldResponseDecodedSize, vs...))
+}
+
+// ResponseDecodedSizeNotIn applies the NotIn predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeNotIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldResponseDecodedSize, vs...))
+}
+
+// ResponseDecodedSizeGT applies the GT predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeGT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldResponseDecodedSize, v))
+}
+
+// ResponseDecodedSizeGTE applies the GTE predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeGTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldResponseDecodedSize, v))
+}
+
+// ResponseDecodedSizeLT applies the LT predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeLT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldResponseDecodedSize, v))
+}
+
+// ResponseDecodedSizeLTE applies the LTE predicate on the "response_decoded_size" field.
+func ResponseDecodedSizeLTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldResponseDecodedSize, v))
+}
+
+// ResponseEventsIsNil applies the IsNil predicate on the "response_events" field.
+func ResponseEventsIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldResponseEvents))
+}
+
+// ResponseEventsNotNil applies the NotNil predicate on the "response_events" field.
+func ResponseEventsNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldResponseEvents))
+}
+
 // ResponseContentTypeEQ applies the EQ predicate on the "response_content_type" field.
 func ResponseContentTypeEQ(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldResponseContentType, v))
@@ -1180,6 +1595,571 @@ func TtfbMsNotNil() predicate.Traffic {
 	return predicate.Traffic(sql.FieldNotNull(FieldTtfbMs))
 }
 
+// RecordTypeEQ applies the EQ predicate on the "record_type" field.
+func RecordTypeEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldRecordType, v))
+}
+
+// RecordTypeNEQ applies the NEQ predicate on the "record_type" field.
+func RecordTypeNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldRecordType, v))
+}
+
+// RecordTypeIn applies the In predicate on the "record_type" field.
+func RecordTypeIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldRecordType, vs...))
+}
+
+// RecordTypeNotIn applies the NotIn predicate on the "record_type" field.
+func RecordTypeNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldRecordType, vs...))
+}
+
+// RecordTypeGT applies the GT predicate on the "record_type" field.
+func RecordTypeGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldRecordType, v))
+}
+
+// RecordTypeGTE applies the GTE predicate on the "record_type" field.
+func RecordTypeGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldRecordType, v))
+}
+
+// RecordTypeLT applies the LT predicate on the "record_type" field.
+func RecordTypeLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldRecordType, v))
+}
+
+// RecordTypeLTE applies the LTE predicate on the "record_type" field.
+func RecordTypeLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldRecordType, v))
+}
+
+// RecordTypeContains applies the Contains predicate on the "record_type" field.
+func RecordTypeContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldRecordType, v))
+}
+
+// RecordTypeHasPrefix applies the HasPrefix predicate on the "record_type" field.
+func RecordTypeHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldRecordType, v))
+}
+
+// RecordTypeHasSuffix applies the HasSuffix predicate on the "record_type" field.
+func RecordTypeHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldRecordType, v))
+}
+
+// RecordTypeEqualFold applies the EqualFold predicate on the "record_type" field.
+func RecordTypeEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldRecordType, v))
+}
+
+// RecordTypeContainsFold applies the ContainsFold predicate on the "record_type" field.
+func RecordTypeContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldRecordType, v))
+}
+
+// ConnectionIDEQ applies the EQ predicate on the "connection_id" field.
+func ConnectionIDEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldConnectionID, v))
+}
+
+// ConnectionIDNEQ applies the NEQ predicate on the "connection_id" field.
+func ConnectionIDNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldConnectionID, v))
+}
+
+// ConnectionIDIn applies the In predicate on the "connection_id" field.
+func ConnectionIDIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldConnectionID, vs...))
+}
+
+// ConnectionIDNotIn applies the NotIn predicate on the "connection_id" field.
+func ConnectionIDNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldConnectionID, vs...))
+}
+
+// ConnectionIDGT applies the GT predicate on the "connection_id" field.
+func ConnectionIDGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldConnectionID, v))
+}
+
+// ConnectionIDGTE applies the GTE predicate on the "connection_id" field.
+func ConnectionIDGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldConnectionID, v))
+}
+
+// ConnectionIDLT applies the LT predicate on the "connection_id" field.
+func ConnectionIDLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldConnectionID, v))
+}
+
+// ConnectionIDLTE applies the LTE predicate on the "connection_id" field.
+func ConnectionIDLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldConnectionID, v))
+}
+
+// ConnectionIDContains applies the Contains predicate on the "connection_id" field.
+func ConnectionIDContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldConnectionID, v))
+}
+
+// ConnectionIDHasPrefix applies the HasPrefix predicate on the "connection_id" field.
+func ConnectionIDHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldConnectionID, v))
+}
+
+// ConnectionIDHasSuffix applies the HasSuffix predicate on the "connection_id" field.
+func ConnectionIDHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldConnectionID, v))
+}
+
+// ConnectionIDIsNil applies the IsNil predicate on the "connection_id" field.
+func ConnectionIDIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldConnectionID))
+}
+
+// ConnectionIDNotNil applies the NotNil predicate on the "connection_id" field.
+func ConnectionIDNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldConnectionID))
+}
+
+// ConnectionIDEqualFold applies the EqualFold predicate on the "connection_id" field.
+func ConnectionIDEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldConnectionID, v))
+}
+
+// ConnectionIDContainsFold applies the ContainsFold predicate on the "connection_id" field.
+func ConnectionIDContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldConnectionID, v))
+}
+
+// FrameSequenceEQ applies the EQ predicate on the "frame_sequence" field.
+func FrameSequenceEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameSequence, v))
+}
+
+// FrameSequenceNEQ applies the NEQ predicate on the "frame_sequence" field.
+func FrameSequenceNEQ(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldFrameSequence, v))
+}
+
+// FrameSequenceIn applies the In predicate on the "frame_sequence" field.
+func FrameSequenceIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldFrameSequence, vs...))
+}
+
+// FrameSequenceNotIn applies the NotIn predicate on the "frame_sequence" field.
+func FrameSequenceNotIn(vs ...int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldFrameSequence, vs...))
+}
+
+// FrameSequenceGT applies the GT predicate on the "frame_sequence" field.
+func FrameSequenceGT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldFrameSequence, v))
+}
+
+// FrameSequenceGTE applies the GTE predicate on the "frame_sequence" field.
+func FrameSequenceGTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldFrameSequence, v))
+}
+
+// FrameSequenceLT applies the LT predicate on the "frame_sequence" field.
+func FrameSequenceLT(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldFrameSequence, v))
+}
+
+// FrameSequenceLTE applies the LTE predicate on the "frame_sequence" field.
+func FrameSequenceLTE(v int64) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldFrameSequence, v))
+}
+
+// FrameSequenceIsNil applies the IsNil predicate on the "frame_sequence" field.
+func FrameSequenceIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldFrameSequence))
+}
+
+// FrameSequenceNotNil applies the NotNil predicate on the "frame_sequence" field.
+func FrameSequenceNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldFrameSequence))
+}
+
+// FrameDirectionEQ applies the EQ predicate on the "frame_direction" field.
+func FrameDirectionEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameDirection, v))
+}
+
+// FrameDirectionNEQ applies the NEQ predicate on the "frame_direction" field.
+func FrameDirectionNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldFrameDirection, v))
+}
+
+// FrameDirectionIn applies the In predicate on the "frame_direction" field.
+func FrameDirectionIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldFrameDirection, vs...))
+}
+
+// FrameDirectionNotIn applies the NotIn predicate on the "frame_direction" field.
+func FrameDirectionNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldFrameDirection, vs...))
+}
+
+// FrameDirectionGT applies the GT predicate on the "frame_direction" field.
+func FrameDirectionGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldFrameDirection, v))
+}
+
+// FrameDirectionGTE applies the GTE predicate on the "frame_direction" field.
+func FrameDirectionGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldFrameDirection, v))
+}
+
+// FrameDirectionLT applies the LT predicate on the "frame_direction" field.
+func FrameDirectionLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldFrameDirection, v))
+}
+
+// FrameDirectionLTE applies the LTE predicate on the "frame_direction" field.
+func FrameDirectionLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldFrameDirection, v))
+}
+
+// FrameDirectionContains applies the Contains predicate on the "frame_direction" field.
+func FrameDirectionContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldFrameDirection, v))
+}
+
+// FrameDirectionHasPrefix applies the HasPrefix predicate on the "frame_direction" field.
+func FrameDirectionHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldFrameDirection, v))
+}
+
+// FrameDirectionHasSuffix applies the HasSuffix predicate on the "frame_direction" field.
+func FrameDirectionHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldFrameDirection, v))
+}
+
+// FrameDirectionIsNil applies the IsNil predicate on the "frame_direction" field.
+func FrameDirectionIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldFrameDirection))
+}
+
+// FrameDirectionNotNil applies the NotNil predicate on the "frame_direction" field.
+func FrameDirectionNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldFrameDirection))
+}
+
+// FrameDirectionEqualFold applies the EqualFold predicate on the "frame_direction" field.
+func FrameDirectionEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldFrameDirection, v))
+}
+
+// FrameDirectionContainsFold applies the ContainsFold predicate on the "frame_direction" field.
+func FrameDirectionContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldFrameDirection, v))
+}
+
+// FrameOpcodeEQ applies the EQ predicate on the "frame_opcode" field.
+func FrameOpcodeEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeNEQ applies the NEQ predicate on the "frame_opcode" field.
+func FrameOpcodeNEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeIn applies the In predicate on the "frame_opcode" field.
+func FrameOpcodeIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldFrameOpcode, vs...))
+}
+
+// FrameOpcodeNotIn applies the NotIn predicate on the "frame_opcode" field.
+func FrameOpcodeNotIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldFrameOpcode, vs...))
+}
+
+// FrameOpcodeGT applies the GT predicate on the "frame_opcode" field.
+func FrameOpcodeGT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeGTE applies the GTE predicate on the "frame_opcode" field.
+func FrameOpcodeGTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeLT applies the LT predicate on the "frame_opcode" field.
+func FrameOpcodeLT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeLTE applies the LTE predicate on the "frame_opcode" field.
+func FrameOpcodeLTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldFrameOpcode, v))
+}
+
+// FrameOpcodeIsNil applies the IsNil predicate on the "frame_opcode" field.
+func FrameOpcodeIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldFrameOpcode))
+}
+
+// FrameOpcodeNotNil applies the NotNil predicate on the "frame_opcode" field.
+func FrameOpcodeNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldFrameOpcode))
+}
+
+// CloseCodeEQ applies the EQ predicate on the "close_code" field.
+func CloseCodeEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldCloseCode, v))
+}
+
+// CloseCodeNEQ applies the NEQ predicate on the "close_code" field.
+func CloseCodeNEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldCloseCode, v))
+}
+
+// CloseCodeIn applies the In predicate on the "close_code" field.
+func CloseCodeIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldCloseCode, vs...))
+}
+
+// CloseCodeNotIn applies the NotIn predicate on the "close_code" field.
+func CloseCodeNotIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldCloseCode, vs...))
+}
+
+// CloseCodeGT applies the GT predicate on the "close_code" field.
+func CloseCodeGT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldCloseCode, v))
+}
+
+// CloseCodeGTE applies the GTE predicate on the "close_code" field.
+func CloseCodeGTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldCloseCode, v))
+}
+
+// CloseCodeLT applies the LT predicate on the "close_code" field.
+func CloseCodeLT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldCloseCode, v))
+}
+
+// CloseCodeLTE applies the LTE predicate on the "close_code" field.
+func CloseCodeLTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldCloseCode, v))
+}
+
+// CloseCodeIsNil applies the IsNil predicate on the "close_code" field.
+func CloseCodeIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldCloseCode))
+}
+
+// CloseCodeNotNil applies the NotNil predicate on the "close_code" field.
+func CloseCodeNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldCloseCode))
+}
+
+// CloseReasonEQ applies the EQ predicate on the "close_reason" field.
+func CloseReasonEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldCloseReason, v))
+}
+
+// CloseReasonNEQ applies the NEQ predicate on the "close_reason" field.
+func CloseReasonNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldCloseReason, v))
+}
+
+// CloseReasonIn applies the In predicate on the "close_reason" field.
+func CloseReasonIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldCloseReason, vs...))
+}
+
+// CloseReasonNotIn applies the NotIn predicate on the "close_reason" field.
+func CloseReasonNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldCloseReason, vs...))
+}
+
+// CloseReasonGT applies the GT predicate on the "close_reason" field.
+func CloseReasonGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldCloseReason, v))
+}
+
+// CloseReasonGTE applies the GTE predicate on the "close_reason" field.
+func CloseReasonGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldCloseReason, v))
+}
+
+// CloseReasonLT applies the LT predicate on the "close_reason" field.
+func CloseReasonLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldCloseReason, v))
+}
+
+// CloseReasonLTE applies the LTE predicate on the "close_reason" field.
+func CloseReasonLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldCloseReason, v))
+}
+
+// CloseReasonContains applies the Contains predicate on the "close_reason" field.
+func CloseReasonContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldCloseReason, v))
+}
+
+// CloseReasonHasPrefix applies the HasPrefix predicate on the "close_reason" field.
+func CloseReasonHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldCloseReason, v))
+}
+
+// CloseReasonHasSuffix applies the HasSuffix predicate on the "close_reason" field.
+func CloseReasonHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldCloseReason, v))
+}
+
+// CloseReasonIsNil applies the IsNil predicate on the "close_reason" field.
+func CloseReasonIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldCloseReason))
+}
+
+// CloseReasonNotNil applies the NotNil predicate on the "close_reason" field.
+func CloseReasonNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldCloseReason))
+}
+
+// CloseReasonEqualFold applies the EqualFold predicate on the "close_reason" field.
+func CloseReasonEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldCloseReason, v))
+}
+
+// CloseReasonContainsFold applies the ContainsFold predicate on the "close_reason" field.
+func CloseReasonContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldCloseReason, v))
+}
+
+// GrpcStatusEQ applies the EQ predicate on the "grpc_status" field.
+func GrpcStatusEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldGrpcStatus, v))
+}
+
+// GrpcStatusNEQ applies the NEQ predicate on the "grpc_status" field.
+func GrpcStatusNEQ(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldGrpcStatus, v))
+}
+
+// GrpcStatusIn applies the In predicate on the "grpc_status" field.
+func GrpcStatusIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldGrpcStatus, vs...))
+}
+
+// GrpcStatusNotIn applies the NotIn predicate on the "grpc_status" field.
+func GrpcStatusNotIn(vs ...int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldGrpcStatus, vs...))
+}
+
+// GrpcStatusGT applies the GT predicate on the "grpc_status" field.
+func GrpcStatusGT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldGrpcStatus, v))
+}
+
+// GrpcStatusGTE applies the GTE predicate on the "grpc_status" field.
+func GrpcStatusGTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldGrpcStatus, v))
+}
+
+// GrpcStatusLT applies the LT predicate on the "grpc_status" field.
+func GrpcStatusLT(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldGrpcStatus, v))
+}
+
+// GrpcStatusLTE applies the LTE predicate on the "grpc_status" field.
+func GrpcStatusLTE(v int) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldGrpcStatus, v))
+}
+
+// GrpcStatusIsNil applies the IsNil predicate on the "grpc_status" field.
+func GrpcStatusIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldGrpcStatus))
+}
+
+// GrpcStatusNotNil applies the NotNil predicate on the "grpc_status" field.
+func GrpcStatusNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldGrpcStatus))
+}
+
+// GrpcMessageEQ applies the EQ predicate on the "grpc_message" field.
+func GrpcMessageEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldGrpcMessage, v))
+}
+
+// GrpcMessageNEQ applies the NEQ predicate on the "grpc_message" field.
+func GrpcMessageNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldGrpcMessage, v))
+}
+
+// GrpcMessageIn applies the In predicate on the "grpc_message" field.
+func GrpcMessageIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldGrpcMessage, vs...))
+}
+
+// GrpcMessageNotIn applies the NotIn predicate on the "grpc_message" field.
+func GrpcMessageNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldGrpcMessage, vs...))
+}
+
+// GrpcMessageGT applies the GT predicate on the "grpc_message" field.
+func GrpcMessageGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldGrpcMessage, v))
+}
+
+// GrpcMessageGTE applies the GTE predicate on the "grpc_message" field.
+func GrpcMessageGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldGrpcMessage, v))
+}
+
+// GrpcMessageLT applies the LT predicate on the "grpc_message" field.
+func GrpcMessageLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldGrpcMessage, v))
+}
+
+// GrpcMessageLTE applies the LTE predicate on the "grpc_message" field.
+func GrpcMessageLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldGrpcMessage, v))
+}
+
+// GrpcMessageContains applies the Contains predicate on the "grpc_message" field.
+func GrpcMessageContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldGrpcMessage, v))
+}
+
+// GrpcMessageHasPrefix applies the HasPrefix predicate on the "grpc_message" field.
+func GrpcMessageHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldGrpcMessage, v))
+}
+
+// GrpcMessageHasSuffix applies the HasSuffix predicate on the "grpc_message" field.
+func GrpcMessageHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldGrpcMessage, v))
+}
+
+// GrpcMessageIsNil applies the IsNil predicate on the "grpc_message" field.
+func GrpcMessageIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldGrpcMessage))
+}
+
+// GrpcMessageNotNil applies the NotNil predicate on the "grpc_message" field.
+func GrpcMessageNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldGrpcMessage))
+}
+
+// GrpcMessageEqualFold applies the EqualFold predicate on the "grpc_message" field.
+func GrpcMessageEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldGrpcMessage, v))
+}
+
+// GrpcMessageContainsFold applies the ContainsFold predicate on the "grpc_message" field.
+func GrpcMessageContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldGrpcMessage, v))
+}
+
 // ClientIPEQ applies the EQ predicate on the "client_ip" field.
 func ClientIPEQ(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldClientIP, v))
@@ -1255,6 +2235,81 @@ func ClientIPContainsFold(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldContainsFold(FieldClientIP, v))
 }
 
+// AuthUserEQ applies the EQ predicate on the "auth_user" field.
+func AuthUserEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEQ(FieldAuthUser, v))
+}
+
+// AuthUserNEQ applies the NEQ predicate on the "auth_user" field.
+func AuthUserNEQ(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNEQ(FieldAuthUser, v))
+}
+
+// AuthUserIn applies the In predicate on the "auth_user" field.
+func AuthUserIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldIn(FieldAuthUser, vs...))
+}
+
+// AuthUserNotIn applies the NotIn predicate on the "auth_user" field.
+func AuthUserNotIn(vs ...string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotIn(FieldAuthUser, vs...))
+}
+
+// AuthUserGT applies the GT predicate on the "auth_user" field.
+func AuthUserGT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGT(FieldAuthUser, v))
+}
+
+// AuthUserGTE applies the GTE predicate on the "auth_user" field.
+func AuthUserGTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldGTE(FieldAuthUser, v))
+}
+
+// AuthUserLT applies the LT predicate on the "auth_user" field.
+func AuthUserLT(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLT(FieldAuthUser, v))
+}
+
+// AuthUserLTE applies the LTE predicate on the "auth_user" field.
+func AuthUserLTE(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldLTE(FieldAuthUser, v))
+}
+
+// AuthUserContains applies the Contains predicate on the "auth_user" field.
+func AuthUserContains(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContains(FieldAuthUser, v))
+}
+
+// AuthUserHasPrefix applies the HasPrefix predicate on the "auth_user" field.
+func AuthUserHasPrefix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasPrefix(FieldAuthUser, v))
+}
+
+// AuthUserHasSuffix applies the HasSuffix predicate on the "auth_user" field.
+func AuthUserHasSuffix(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldHasSuffix(FieldAuthUser, v))
+}
+
+// AuthUserIsNil applies the IsNil predicate on the "auth_user" field.
+func AuthUserIsNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldIsNull(FieldAuthUser))
+}
+
+// AuthUserNotNil applies the NotNil predicate on the "auth_user" field.
+func AuthUserNotNil() predicate.Traffic {
+	return predicate.Traffic(sql.FieldNotNull(FieldAuthUser))
+}
+
+// AuthUserEqualFold applies the EqualFold predicate on the "auth_user" field.
+func AuthUserEqualFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldEqualFold(FieldAuthUser, v))
+}
+
+// AuthUserContainsFold applies the ContainsFold predicate on the "auth_user" field.
+func AuthUserContainsFold(v string) predicate.Traffic {
+	return predicate.Traffic(sql.FieldContainsFold(FieldAuthUser, v))
+}
+
 // ErrorEQ applies the EQ predicate on the "error" field.
 func ErrorEQ(v string) predicate.Traffic {
 	return predicate.Traffic(sql.FieldEQ(FieldError, v))
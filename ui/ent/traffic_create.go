@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
 )
@@ -71,6 +72,12 @@ func (_c *TrafficCreate) SetRequestHeaders(v map[string][]string) *TrafficCreate
 	return _c
 }
 
+// SetRequestHeaderNames sets the "request_header_names" field.
+func (_c *TrafficCreate) SetRequestHeaderNames(v []string) *TrafficCreate {
+	_c.mutation.SetRequestHeaderNames(v)
+	return _c
+}
+
 // SetRequestBody sets the "request_body" field.
 func (_c *TrafficCreate) SetRequestBody(v []byte) *TrafficCreate {
 	_c.mutation.SetRequestBody(v)
@@ -105,6 +112,34 @@ func (_c *TrafficCreate) SetNillableRequestIsBinary(v *bool) *TrafficCreate {
 	return _c
 }
 
+// SetRequestBodyTruncated sets the "request_body_truncated" field.
+func (_c *TrafficCreate) SetRequestBodyTruncated(v bool) *TrafficCreate {
+	_c.mutation.SetRequestBodyTruncated(v)
+	return _c
+}
+
+// SetNillableRequestBodyTruncated sets the "request_body_truncated" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableRequestBodyTruncated(v *bool) *TrafficCreate {
+	if v != nil {
+		_c.SetRequestBodyTruncated(*v)
+	}
+	return _c
+}
+
+// SetRequestDecodedSize sets the "request_decoded_size" field.
+func (_c *TrafficCreate) SetRequestDecodedSize(v int64) *TrafficCreate {
+	_c.mutation.SetRequestDecodedSize(v)
+	return _c
+}
+
+// SetNillableRequestDecodedSize sets the "request_decoded_size" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableRequestDecodedSize(v *int64) *TrafficCreate {
+	if v != nil {
+		_c.SetRequestDecodedSize(*v)
+	}
+	return _c
+}
+
 // SetContentType sets the "content_type" field.
 func (_c *TrafficCreate) SetContentType(v string) *TrafficCreate {
 	_c.mutation.SetContentType(v)
@@ -119,6 +154,34 @@ func (_c *TrafficCreate) SetNillableContentType(v *string) *TrafficCreate {
 	return _c
 }
 
+// SetHTTPVersion sets the "http_version" field.
+func (_c *TrafficCreate) SetHTTPVersion(v string) *TrafficCreate {
+	_c.mutation.SetHTTPVersion(v)
+	return _c
+}
+
+// SetNillableHTTPVersion sets the "http_version" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableHTTPVersion(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetHTTPVersion(*v)
+	}
+	return _c
+}
+
+// SetStreamID sets the "stream_id" field.
+func (_c *TrafficCreate) SetStreamID(v uint32) *TrafficCreate {
+	_c.mutation.SetStreamID(v)
+	return _c
+}
+
+// SetNillableStreamID sets the "stream_id" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableStreamID(v *uint32) *TrafficCreate {
+	if v != nil {
+		_c.SetStreamID(*v)
+	}
+	return _c
+}
+
 // SetStatusCode sets the "status_code" field.
 func (_c *TrafficCreate) SetStatusCode(v int) *TrafficCreate {
 	_c.mutation.SetStatusCode(v)
@@ -139,12 +202,32 @@ func (_c *TrafficCreate) SetNillableStatusText(v *string) *TrafficCreate {
 	return _c
 }
 
+// SetResponseHTTPVersion sets the "response_http_version" field.
+func (_c *TrafficCreate) SetResponseHTTPVersion(v string) *TrafficCreate {
+	_c.mutation.SetResponseHTTPVersion(v)
+	return _c
+}
+
+// SetNillableResponseHTTPVersion sets the "response_http_version" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableResponseHTTPVersion(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetResponseHTTPVersion(*v)
+	}
+	return _c
+}
+
 // SetResponseHeaders sets the "response_headers" field.
 func (_c *TrafficCreate) SetResponseHeaders(v map[string][]string) *TrafficCreate {
 	_c.mutation.SetResponseHeaders(v)
 	return _c
 }
 
+// SetResponseHeaderNames sets the "response_header_names" field.
+func (_c *TrafficCreate) SetResponseHeaderNames(v []string) *TrafficCreate {
+	_c.mutation.SetResponseHeaderNames(v)
+	return _c
+}
+
 // SetResponseBody sets the "response_body" field.
 func (_c *TrafficCreate) SetResponseBody(v []byte) *TrafficCreate {
 	_c.mutation.SetResponseBody(v)
@@ -179,6 +262,40 @@ func (_c *TrafficCreate) SetNillableResponseIsBinary(v *bool) *TrafficCreate {
 	return _c
 }
 
+// SetResponseBodyTruncated sets the "response_body_truncated" field.
+func (_c *TrafficCreate) SetResponseBodyTruncated(v bool) *TrafficCreate {
+	_c.mutation.SetResponseBodyTruncated(v)
+	return _c
+}
+
+// SetNillableResponseBodyTruncated sets the "response_body_truncated" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableResponseBodyTruncated(v *bool) *TrafficCreate {
+	if v != nil {
+		_c.SetResponseBodyTruncated(*v)
+	}
+	return _c
+}
+
+// SetResponseDecodedSize sets the "response_decoded_size" field.
+func (_c *TrafficCreate) SetResponseDecodedSize(v int64) *TrafficCreate {
+	_c.mutation.SetResponseDecodedSize(v)
+	return _c
+}
+
+// SetNillableResponseDecodedSize sets the "response_decoded_size" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableResponseDecodedSize(v *int64) *TrafficCreate {
+	if v != nil {
+		_c.SetResponseDecodedSize(*v)
+	}
+	return _c
+}
+
+// SetResponseEvents sets the "response_events" field.
+func (_c *TrafficCreate) SetResponseEvents(v []capture.StreamEvent) *TrafficCreate {
+	_c.mutation.SetResponseEvents(v)
+	return _c
+}
+
 // SetResponseContentType sets the "response_content_type" field.
 func (_c *TrafficCreate) SetResponseContentType(v string) *TrafficCreate {
 	_c.mutation.SetResponseContentType(v)
@@ -219,6 +336,132 @@ func (_c *TrafficCreate) SetNillableTtfbMs(v *float64) *TrafficCreate {
 	return _c
 }
 
+// SetRecordType sets the "record_type" field.
+func (_c *TrafficCreate) SetRecordType(v string) *TrafficCreate {
+	_c.mutation.SetRecordType(v)
+	return _c
+}
+
+// SetNillableRecordType sets the "record_type" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableRecordType(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetRecordType(*v)
+	}
+	return _c
+}
+
+// SetConnectionID sets the "connection_id" field.
+func (_c *TrafficCreate) SetConnectionID(v string) *TrafficCreate {
+	_c.mutation.SetConnectionID(v)
+	return _c
+}
+
+// SetNillableConnectionID sets the "connection_id" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableConnectionID(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetConnectionID(*v)
+	}
+	return _c
+}
+
+// SetFrameSequence sets the "frame_sequence" field.
+func (_c *TrafficCreate) SetFrameSequence(v int64) *TrafficCreate {
+	_c.mutation.SetFrameSequence(v)
+	return _c
+}
+
+// SetNillableFrameSequence sets the "frame_sequence" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableFrameSequence(v *int64) *TrafficCreate {
+	if v != nil {
+		_c.SetFrameSequence(*v)
+	}
+	return _c
+}
+
+// SetFrameDirection sets the "frame_direction" field.
+func (_c *TrafficCreate) SetFrameDirection(v string) *TrafficCreate {
+	_c.mutation.SetFrameDirection(v)
+	return _c
+}
+
+// SetNillableFrameDirection sets the "frame_direction" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableFrameDirection(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetFrameDirection(*v)
+	}
+	return _c
+}
+
+// SetFrameOpcode sets the "frame_opcode" field.
+func (_c *TrafficCreate) SetFrameOpcode(v int) *TrafficCreate {
+	_c.mutation.SetFrameOpcode(v)
+	return _c
+}
+
+// SetNillableFrameOpcode sets the "frame_opcode" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableFrameOpcode(v *int) *TrafficCreate {
+	if v != nil {
+		_c.SetFrameOpcode(*v)
+	}
+	return _c
+}
+
+// SetCloseCode sets the "close_code" field.
+func (_c *TrafficCreate) SetCloseCode(v int) *TrafficCreate {
+	_c.mutation.SetCloseCode(v)
+	return _c
+}
+
+// SetNillableCloseCode sets the "close_code" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableCloseCode(v *int) *TrafficCreate {
+	if v != nil {
+		_c.SetCloseCode(*v)
+	}
+	return _c
+}
+
+// SetCloseReason sets the "close_reason" field.
+func (_c *TrafficCreate) SetCloseReason(v string) *TrafficCreate {
+	_c.mutation.SetCloseReason(v)
+	return _c
+}
+
+// SetNillableCloseReason sets the "close_reason" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableCloseReason(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetCloseReason(*v)
+	}
+	return _c
+}
+
+// SetGrpcStatus sets the "grpc_status" field.
+func (_c *TrafficCreate) SetGrpcStatus(v int) *TrafficCreate {
+	_c.mutation.SetGrpcStatus(v)
+	return _c
+}
+
+// SetNillableGrpcStatus sets the "grpc_status" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableGrpcStatus(v *int) *TrafficCreate {
+	if v != nil {
+		_c.SetGrpcStatus(*v)
+	}
+	return _c
+}
+
+// SetGrpcMessage sets the "grpc_message" field.
+func (_c *TrafficCreate) SetGrpcMessage(v string) *TrafficCreate {
+	_c.mutation.SetGrpcMessage(v)
+	return _c
+}
+
+// SetNillableGrpcMessage sets the "grpc_message" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableGrpcMessage(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetGrpcMessage(*v)
+	}
+	return _c
+}
+
 // SetClientIP sets the "client_ip" field.
 func (_c *TrafficCreate) SetClientIP(v string) *TrafficCreate {
 	_c.mutation.SetClientIP(v)
@@ -233,6 +476,20 @@ func (_c *TrafficCreate) SetNillableClientIP(v *string) *TrafficCreate {
 	return _c
 }
 
+// SetAuthUser sets the "auth_user" field.
+func (_c *TrafficCreate) SetAuthUser(v string) *TrafficCreate {
+	_c.mutation.SetAuthUser(v)
+	return _c
+}
+
+// SetNillableAuthUser sets the "auth_user" field if the given value is not nil.
+func (_c *TrafficCreate) SetNillableAuthUser(v *string) *TrafficCreate {
+	if v != nil {
+		_c.SetAuthUser(*v)
+	}
+	return _c
+}
+
 // SetError sets the "error" field.
 func (_c *TrafficCreate) SetError(v string) *TrafficCreate {
 	_c.mutation.SetError(v)
@@ -321,6 +578,14 @@ func (_c *TrafficCreate) defaults() {
 		v := traffic.DefaultRequestIsBinary
 		_c.mutation.SetRequestIsBinary(v)
 	}
+	if _, ok := _c.mutation.RequestBodyTruncated(); !ok {
+		v := traffic.DefaultRequestBodyTruncated
+		_c.mutation.SetRequestBodyTruncated(v)
+	}
+	if _, ok := _c.mutation.RequestDecodedSize(); !ok {
+		v := traffic.DefaultRequestDecodedSize
+		_c.mutation.SetRequestDecodedSize(v)
+	}
 	if _, ok := _c.mutation.ResponseBodySize(); !ok {
 		v := traffic.DefaultResponseBodySize
 		_c.mutation.SetResponseBodySize(v)
@@ -329,6 +594,18 @@ func (_c *TrafficCreate) defaults() {
 		v := traffic.DefaultResponseIsBinary
 		_c.mutation.SetResponseIsBinary(v)
 	}
+	if _, ok := _c.mutation.ResponseBodyTruncated(); !ok {
+		v := traffic.DefaultResponseBodyTruncated
+		_c.mutation.SetResponseBodyTruncated(v)
+	}
+	if _, ok := _c.mutation.ResponseDecodedSize(); !ok {
+		v := traffic.DefaultResponseDecodedSize
+		_c.mutation.SetResponseDecodedSize(v)
+	}
+	if _, ok := _c.mutation.RecordType(); !ok {
+		v := traffic.DefaultRecordType
+		_c.mutation.SetRecordType(v)
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		v := traffic.DefaultCreatedAt()
 		_c.mutation.SetCreatedAt(v)
@@ -373,6 +650,12 @@ func (_c *TrafficCreate) check() error {
 	if _, ok := _c.mutation.RequestIsBinary(); !ok {
 		return &ValidationError{Name: "request_is_binary", err: errors.New(`ent: missing required field "Traffic.request_is_binary"`)}
 	}
+	if _, ok := _c.mutation.RequestBodyTruncated(); !ok {
+		return &ValidationError{Name: "request_body_truncated", err: errors.New(`ent: missing required field "Traffic.request_body_truncated"`)}
+	}
+	if _, ok := _c.mutation.RequestDecodedSize(); !ok {
+		return &ValidationError{Name: "request_decoded_size", err: errors.New(`ent: missing required field "Traffic.request_decoded_size"`)}
+	}
 	if _, ok := _c.mutation.StatusCode(); !ok {
 		return &ValidationError{Name: "status_code", err: errors.New(`ent: missing required field "Traffic.status_code"`)}
 	}
@@ -382,12 +665,21 @@ func (_c *TrafficCreate) check() error {
 	if _, ok := _c.mutation.ResponseIsBinary(); !ok {
 		return &ValidationError{Name: "response_is_binary", err: errors.New(`ent: missing required field "Traffic.response_is_binary"`)}
 	}
+	if _, ok := _c.mutation.ResponseBodyTruncated(); !ok {
+		return &ValidationError{Name: "response_body_truncated", err: errors.New(`ent: missing required field "Traffic.response_body_truncated"`)}
+	}
+	if _, ok := _c.mutation.ResponseDecodedSize(); !ok {
+		return &ValidationError{Name: "response_decoded_size", err: errors.New(`ent: missing required field "Traffic.response_decoded_size"`)}
+	}
 	if _, ok := _c.mutation.StartedAt(); !ok {
 		return &ValidationError{Name: "started_at", err: errors.New(`ent: missing required field "Traffic.started_at"`)}
 	}
 	if _, ok := _c.mutation.DurationMs(); !ok {
 		return &ValidationError{Name: "duration_ms", err: errors.New(`ent: missing required field "Traffic.duration_ms"`)}
 	}
+	if _, ok := _c.mutation.RecordType(); !ok {
+		return &ValidationError{Name: "record_type", err: errors.New(`ent: missing required field "Traffic.record_type"`)}
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "Traffic.created_at"`)}
 	}
@@ -448,6 +740,10 @@ func (_c *TrafficCreate) createSpec() (*Traffic, *sqlgraph.CreateSpec) {
 		_spec.SetField(traffic.FieldRequestHeaders, field.TypeJSON, value)
 		_node.RequestHeaders = value
 	}
+	if value, ok := _c.mutation.RequestHeaderNames(); ok {
+		_spec.SetField(traffic.FieldRequestHeaderNames, field.TypeJSON, value)
+		_node.RequestHeaderNames = value
+	}
 	if value, ok := _c.mutation.RequestBody(); ok {
 		_spec.SetField(traffic.FieldRequestBody, field.TypeBytes, value)
 		_node.RequestBody = value
@@ -460,10 +756,26 @@ func (_c *TrafficCreate) createSpec() (*Traffic, *sqlgraph.CreateSpec) {
 		_spec.SetField(traffic.FieldRequestIsBinary, field.TypeBool, value)
 		_node.RequestIsBinary = value
 	}
+	if value, ok := _c.mutation.RequestBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldRequestBodyTruncated, field.TypeBool, value)
+		_node.RequestBodyTruncated = value
+	}
+	if value, ok := _c.mutation.RequestDecodedSize(); ok {
+		_spec.SetField(traffic.FieldRequestDecodedSize, field.TypeInt64, value)
+		_node.RequestDecodedSize = value
+	}
 	if value, ok := _c.mutation.ContentType(); ok {
 		_spec.SetField(traffic.FieldContentType, field.TypeString, value)
 		_node.ContentType = value
 	}
+	if value, ok := _c.mutation.HTTPVersion(); ok {
+		_spec.SetField(traffic.FieldHTTPVersion, field.TypeString, value)
+		_node.HTTPVersion = value
+	}
+	if value, ok := _c.mutation.StreamID(); ok {
+		_spec.SetField(traffic.FieldStreamID, field.TypeUint32, value)
+		_node.StreamID = value
+	}
 	if value, ok := _c.mutation.StatusCode(); ok {
 		_spec.SetField(traffic.FieldStatusCode, field.TypeInt, value)
 		_node.StatusCode = value
@@ -472,10 +784,18 @@ func (_c *TrafficCreate) createSpec() (*Traffic, *sqlgraph.CreateSpec) {
 		_spec.SetField(traffic.FieldStatusText, field.TypeString, value)
 		_node.StatusText = value
 	}
+	if value, ok := _c.mutation.ResponseHTTPVersion(); ok {
+		_spec.SetField(traffic.FieldResponseHTTPVersion, field.TypeString, value)
+		_node.ResponseHTTPVersion = value
+	}
 	if value, ok := _c.mutation.ResponseHeaders(); ok {
 		_spec.SetField(traffic.FieldResponseHeaders, field.TypeJSON, value)
 		_node.ResponseHeaders = value
 	}
+	if value, ok := _c.mutation.ResponseHeaderNames(); ok {
+		_spec.SetField(traffic.FieldResponseHeaderNames, field.TypeJSON, value)
+		_node.ResponseHeaderNames = value
+	}
 	if value, ok := _c.mutation.ResponseBody(); ok {
 		_spec.SetField(traffic.FieldResponseBody, field.TypeBytes, value)
 		_node.ResponseBody = value
@@ -488,6 +808,18 @@ func (_c *TrafficCreate) createSpec() (*Traffic, *sqlgraph.CreateSpec) {
 		_spec.SetField(traffic.FieldResponseIsBinary, field.TypeBool, value)
 		_node.ResponseIsBinary = value
 	}
+	if value, ok := _c.mutation.ResponseBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldResponseBodyTruncated, field.TypeBool, value)
+		_node.ResponseBodyTruncated = value
+	}
+	if value, ok := _c.mutation.ResponseDecodedSize(); ok {
+		_spec.SetField(traffic.FieldResponseDecodedSize, field.TypeInt64, value)
+		_node.ResponseDecodedSize = value
+	}
+	if value, ok := _c.mutation.ResponseEvents(); ok {
+		_spec.SetField(traffic.FieldResponseEvents, field.TypeJSON, value)
+		_node.ResponseEvents = value
+	}
 	if value, ok := _c.mutation.ResponseContentType(); ok {
 		_spec.SetField(traffic.FieldResponseContentType, field.TypeString, value)
 		_node.ResponseContentType = value
@@ -504,10 +836,50 @@ func (_c *TrafficCreate) createSpec() (*Traffic, *sqlgraph.CreateSpec) {
 		_spec.SetField(traffic.FieldTtfbMs, field.TypeFloat64, value)
 		_node.TtfbMs = &value
 	}
+	if value, ok := _c.mutation.RecordType(); ok {
+		_spec.SetField(traffic.FieldRecordType, field.TypeString, value)
+		_node.RecordType = value
+	}
+	if value, ok := _c.mutation.ConnectionID(); ok {
+		_spec.SetField(traffic.FieldConnectionID, field.TypeString, value)
+		_node.ConnectionID = value
+	}
+	if value, ok := _c.mutation.FrameSequence(); ok {
+		_spec.SetField(traffic.FieldFrameSequence, field.TypeInt64, value)
+		_node.FrameSequence = value
+	}
+	if value, ok := _c.mutation.FrameDirection(); ok {
+		_spec.SetField(traffic.FieldFrameDirection, field.TypeString, value)
+		_node.FrameDirection = value
+	}
+	if value, ok := _c.mutation.FrameOpcode(); ok {
+		_spec.SetField(traffic.FieldFrameOpcode, field.TypeInt, value)
+		_node.FrameOpcode = value
+	}
+	if value, ok := _c.mutation.CloseCode(); ok {
+		_spec.SetField(traffic.FieldCloseCode, field.TypeInt, value)
+		_node.CloseCode = value
+	}
+	if value, ok := _c.mutation.CloseReason(); ok {
+		_spec.SetField(traffic.FieldCloseReason, field.TypeString, value)
+		_node.CloseReason = value
+	}
+	if value, ok := _c.mutation.GrpcStatus(); ok {
+		_spec.SetField(traffic.FieldGrpcStatus, field.TypeInt, value)
+		_node.GrpcStatus = &value
+	}
+	if value, ok := _c.mutation.GrpcMessage(); ok {
+		_spec.SetField(traffic.FieldGrpcMessage, field.TypeString, value)
+		_node.GrpcMessage = value
+	}
 	if value, ok := _c.mutation.ClientIP(); ok {
 		_spec.SetField(traffic.FieldClientIP, field.TypeString, value)
 		_node.ClientIP = value
 	}
+	if value, ok := _c.mutation.AuthUser(); ok {
+		_spec.SetField(traffic.FieldAuthUser, field.TypeString, value)
+		_node.AuthUser = value
+	}
 	if value, ok := _c.mutation.Error(); ok {
 		_spec.SetField(traffic.FieldError, field.TypeString, value)
 		_node.Error = value
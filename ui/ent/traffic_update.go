@@ -12,6 +12,7 @@ import (
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
+	"github.com/grokify/omniproxy/pkg/capture"
 	"github.com/grokify/omniproxy/ui/ent/predicate"
 	"github.com/grokify/omniproxy/ui/ent/proxy"
 	"github.com/grokify/omniproxy/ui/ent/traffic"
@@ -132,6 +133,24 @@ func (_u *TrafficUpdate) ClearRequestHeaders() *TrafficUpdate {
 	return _u
 }
 
+// SetRequestHeaderNames sets the "request_header_names" field.
+func (_u *TrafficUpdate) SetRequestHeaderNames(v []string) *TrafficUpdate {
+	_u.mutation.SetRequestHeaderNames(v)
+	return _u
+}
+
+// AppendRequestHeaderNames appends value to the "request_header_names" field.
+func (_u *TrafficUpdate) AppendRequestHeaderNames(v []string) *TrafficUpdate {
+	_u.mutation.AppendRequestHeaderNames(v)
+	return _u
+}
+
+// ClearRequestHeaderNames clears the value of the "request_header_names" field.
+func (_u *TrafficUpdate) ClearRequestHeaderNames() *TrafficUpdate {
+	_u.mutation.ClearRequestHeaderNames()
+	return _u
+}
+
 // SetRequestBody sets the "request_body" field.
 func (_u *TrafficUpdate) SetRequestBody(v []byte) *TrafficUpdate {
 	_u.mutation.SetRequestBody(v)
@@ -179,6 +198,41 @@ func (_u *TrafficUpdate) SetNillableRequestIsBinary(v *bool) *TrafficUpdate {
 	return _u
 }
 
+// SetRequestBodyTruncated sets the "request_body_truncated" field.
+func (_u *TrafficUpdate) SetRequestBodyTruncated(v bool) *TrafficUpdate {
+	_u.mutation.SetRequestBodyTruncated(v)
+	return _u
+}
+
+// SetNillableRequestBodyTruncated sets the "request_body_truncated" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableRequestBodyTruncated(v *bool) *TrafficUpdate {
+	if v != nil {
+		_u.SetRequestBodyTruncated(*v)
+	}
+	return _u
+}
+
+// SetRequestDecodedSize sets the "request_decoded_size" field.
+func (_u *TrafficUpdate) SetRequestDecodedSize(v int64) *TrafficUpdate {
+	_u.mutation.ResetRequestDecodedSize()
+	_u.mutation.SetRequestDecodedSize(v)
+	return _u
+}
+
+// SetNillableRequestDecodedSize sets the "request_decoded_size" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableRequestDecodedSize(v *int64) *TrafficUpdate {
+	if v != nil {
+		_u.SetRequestDecodedSize(*v)
+	}
+	return _u
+}
+
+// AddRequestDecodedSize adds value to the "request_decoded_size" field.
+func (_u *TrafficUpdate) AddRequestDecodedSize(v int64) *TrafficUpdate {
+	_u.mutation.AddRequestDecodedSize(v)
+	return _u
+}
+
 // SetContentType sets the "content_type" field.
 func (_u *TrafficUpdate) SetContentType(v string) *TrafficUpdate {
 	_u.mutation.SetContentType(v)
@@ -199,6 +253,53 @@ func (_u *TrafficUpdate) ClearContentType() *TrafficUpdate {
 	return _u
 }
 
+// SetHTTPVersion sets the "http_version" field.
+func (_u *TrafficUpdate) SetHTTPVersion(v string) *TrafficUpdate {
+	_u.mutation.SetHTTPVersion(v)
+	return _u
+}
+
+// SetNillableHTTPVersion sets the "http_version" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableHTTPVersion(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetHTTPVersion(*v)
+	}
+	return _u
+}
+
+// ClearHTTPVersion clears the value of the "http_version" field.
+func (_u *TrafficUpdate) ClearHTTPVersion() *TrafficUpdate {
+	_u.mutation.ClearHTTPVersion()
+	return _u
+}
+
+// SetStreamID sets the "stream_id" field.
+func (_u *TrafficUpdate) SetStreamID(v uint32) *TrafficUpdate {
+	_u.mutation.ResetStreamID()
+	_u.mutation.SetStreamID(v)
+	return _u
+}
+
+// SetNillableStreamID sets the "stream_id" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableStreamID(v *uint32) *TrafficUpdate {
+	if v != nil {
+		_u.SetStreamID(*v)
+	}
+	return _u
+}
+
+// AddStreamID adds value to the "stream_id" field.
+func (_u *TrafficUpdate) AddStreamID(v int32) *TrafficUpdate {
+	_u.mutation.AddStreamID(v)
+	return _u
+}
+
+// ClearStreamID clears the value of the "stream_id" field.
+func (_u *TrafficUpdate) ClearStreamID() *TrafficUpdate {
+	_u.mutation.ClearStreamID()
+	return _u
+}
+
 // SetStatusCode sets the "status_code" field.
 func (_u *TrafficUpdate) SetStatusCode(v int) *TrafficUpdate {
 	_u.mutation.ResetStatusCode()
@@ -240,6 +341,26 @@ func (_u *TrafficUpdate) ClearStatusText() *TrafficUpdate {
 	return _u
 }
 
+// SetResponseHTTPVersion sets the "response_http_version" field.
+func (_u *TrafficUpdate) SetResponseHTTPVersion(v string) *TrafficUpdate {
+	_u.mutation.SetResponseHTTPVersion(v)
+	return _u
+}
+
+// SetNillableResponseHTTPVersion sets the "response_http_version" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableResponseHTTPVersion(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetResponseHTTPVersion(*v)
+	}
+	return _u
+}
+
+// ClearResponseHTTPVersion clears the value of the "response_http_version" field.
+func (_u *TrafficUpdate) ClearResponseHTTPVersion() *TrafficUpdate {
+	_u.mutation.ClearResponseHTTPVersion()
+	return _u
+}
+
 // SetResponseHeaders sets the "response_headers" field.
 func (_u *TrafficUpdate) SetResponseHeaders(v map[string][]string) *TrafficUpdate {
 	_u.mutation.SetResponseHeaders(v)
@@ -252,6 +373,24 @@ func (_u *TrafficUpdate) ClearResponseHeaders() *TrafficUpdate {
 	return _u
 }
 
+// SetResponseHeaderNames sets the "response_header_names" field.
+func (_u *TrafficUpdate) SetResponseHeaderNames(v []string) *TrafficUpdate {
+	_u.mutation.SetResponseHeaderNames(v)
+	return _u
+}
+
+// AppendResponseHeaderNames appends value to the "response_header_names" field.
+func (_u *TrafficUpdate) AppendResponseHeaderNames(v []string) *TrafficUpdate {
+	_u.mutation.AppendResponseHeaderNames(v)
+	return _u
+}
+
+// ClearResponseHeaderNames clears the value of the "response_header_names" field.
+func (_u *TrafficUpdate) ClearResponseHeaderNames() *TrafficUpdate {
+	_u.mutation.ClearResponseHeaderNames()
+	return _u
+}
+
 // SetResponseBody sets the "response_body" field.
 func (_u *TrafficUpdate) SetResponseBody(v []byte) *TrafficUpdate {
 	_u.mutation.SetResponseBody(v)
@@ -299,6 +438,59 @@ func (_u *TrafficUpdate) SetNillableResponseIsBinary(v *bool) *TrafficUpdate {
 	return _u
 }
 
+// SetResponseBodyTruncated sets the "response_body_truncated" field.
+func (_u *TrafficUpdate) SetResponseBodyTruncated(v bool) *TrafficUpdate {
+	_u.mutation.SetResponseBodyTruncated(v)
+	return _u
+}
+
+// SetNillableResponseBodyTruncated sets the "response_body_truncated" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableResponseBodyTruncated(v *bool) *TrafficUpdate {
+	if v != nil {
+		_u.SetResponseBodyTruncated(*v)
+	}
+	return _u
+}
+
+// SetResponseDecodedSize sets the "response_decoded_size" field.
+func (_u *TrafficUpdate) SetResponseDecodedSize(v int64) *TrafficUpdate {
+	_u.mutation.ResetResponseDecodedSize()
+	_u.mutation.SetResponseDecodedSize(v)
+	return _u
+}
+
+// SetNillableResponseDecodedSize sets the "response_decoded_size" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableResponseDecodedSize(v *int64) *TrafficUpdate {
+	if v != nil {
+		_u.SetResponseDecodedSize(*v)
+	}
+	return _u
+}
+
+// AddResponseDecodedSize adds value to the "response_decoded_size" field.
+func (_u *TrafficUpdate) AddResponseDecodedSize(v int64) *TrafficUpdate {
+	_u.mutation.AddResponseDecodedSize(v)
+	return _u
+}
+
+// SetResponseEvents sets the "response_events" field.
+func (_u *TrafficUpdate) SetResponseEvents(v []capture.StreamEvent) *TrafficUpdate {
+	_u.mutation.SetResponseEvents(v)
+	return _u
+}
+
+// AppendResponseEvents appends value to the "response_events" field.
+func (_u *TrafficUpdate) AppendResponseEvents(v []capture.StreamEvent) *TrafficUpdate {
+	_u.mutation.AppendResponseEvents(v)
+	return _u
+}
+
+// ClearResponseEvents clears the value of the "response_events" field.
+func (_u *TrafficUpdate) ClearResponseEvents() *TrafficUpdate {
+	_u.mutation.ClearResponseEvents()
+	return _u
+}
+
 // SetResponseContentType sets the "response_content_type" field.
 func (_u *TrafficUpdate) SetResponseContentType(v string) *TrafficUpdate {
 	_u.mutation.SetResponseContentType(v)
@@ -381,6 +573,208 @@ func (_u *TrafficUpdate) ClearTtfbMs() *TrafficUpdate {
 	return _u
 }
 
+// SetRecordType sets the "record_type" field.
+func (_u *TrafficUpdate) SetRecordType(v string) *TrafficUpdate {
+	_u.mutation.SetRecordType(v)
+	return _u
+}
+
+// SetNillableRecordType sets the "record_type" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableRecordType(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetRecordType(*v)
+	}
+	return _u
+}
+
+// SetConnectionID sets the "connection_id" field.
+func (_u *TrafficUpdate) SetConnectionID(v string) *TrafficUpdate {
+	_u.mutation.SetConnectionID(v)
+	return _u
+}
+
+// SetNillableConnectionID sets the "connection_id" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableConnectionID(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetConnectionID(*v)
+	}
+	return _u
+}
+
+// ClearConnectionID clears the value of the "connection_id" field.
+func (_u *TrafficUpdate) ClearConnectionID() *TrafficUpdate {
+	_u.mutation.ClearConnectionID()
+	return _u
+}
+
+// SetFrameSequence sets the "frame_sequence" field.
+func (_u *TrafficUpdate) SetFrameSequence(v int64) *TrafficUpdate {
+	_u.mutation.ResetFrameSequence()
+	_u.mutation.SetFrameSequence(v)
+	return _u
+}
+
+// SetNillableFrameSequence sets the "frame_sequence" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableFrameSequence(v *int64) *TrafficUpdate {
+	if v != nil {
+		_u.SetFrameSequence(*v)
+	}
+	return _u
+}
+
+// AddFrameSequence adds value to the "frame_sequence" field.
+func (_u *TrafficUpdate) AddFrameSequence(v int64) *TrafficUpdate {
+	_u.mutation.AddFrameSequence(v)
+	return _u
+}
+
+// ClearFrameSequence clears the value of the "frame_sequence" field.
+func (_u *TrafficUpdate) ClearFrameSequence() *TrafficUpdate {
+	_u.mutation.ClearFrameSequence()
+	return _u
+}
+
+// SetFrameDirection sets the "frame_direction" field.
+func (_u *TrafficUpdate) SetFrameDirection(v string) *TrafficUpdate {
+	_u.mutation.SetFrameDirection(v)
+	return _u
+}
+
+// SetNillableFrameDirection sets the "frame_direction" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableFrameDirection(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetFrameDirection(*v)
+	}
+	return _u
+}
+
+// ClearFrameDirection clears the value of the "frame_direction" field.
+func (_u *TrafficUpdate) ClearFrameDirection() *TrafficUpdate {
+	_u.mutation.ClearFrameDirection()
+	return _u
+}
+
+// SetFrameOpcode sets the "frame_opcode" field.
+func (_u *TrafficUpdate) SetFrameOpcode(v int) *TrafficUpdate {
+	_u.mutation.ResetFrameOpcode()
+	_u.mutation.SetFrameOpcode(v)
+	return _u
+}
+
+// SetNillableFrameOpcode sets the "frame_opcode" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableFrameOpcode(v *int) *TrafficUpdate {
+	if v != nil {
+		_u.SetFrameOpcode(*v)
+	}
+	return _u
+}
+
+// AddFrameOpcode adds value to the "frame_opcode" field.
+func (_u *TrafficUpdate) AddFrameOpcode(v int) *TrafficUpdate {
+	_u.mutation.AddFrameOpcode(v)
+	return _u
+}
+
+// ClearFrameOpcode clears the value of the "frame_opcode" field.
+func (_u *TrafficUpdate) ClearFrameOpcode() *TrafficUpdate {
+	_u.mutation.ClearFrameOpcode()
+	return _u
+}
+
+// SetCloseCode sets the "close_code" field.
+func (_u *TrafficUpdate) SetCloseCode(v int) *TrafficUpdate {
+	_u.mutation.ResetCloseCode()
+	_u.mutation.SetCloseCode(v)
+	return _u
+}
+
+// SetNillableCloseCode sets the "close_code" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableCloseCode(v *int) *TrafficUpdate {
+	if v != nil {
+		_u.SetCloseCode(*v)
+	}
+	return _u
+}
+
+// AddCloseCode adds value to the "close_code" field.
+func (_u *TrafficUpdate) AddCloseCode(v int) *TrafficUpdate {
+	_u.mutation.AddCloseCode(v)
+	return _u
+}
+
+// ClearCloseCode clears the value of the "close_code" field.
+func (_u *TrafficUpdate) ClearCloseCode() *TrafficUpdate {
+	_u.mutation.ClearCloseCode()
+	return _u
+}
+
+// SetCloseReason sets the "close_reason" field.
+func (_u *TrafficUpdate) SetCloseReason(v string) *TrafficUpdate {
+	_u.mutation.SetCloseReason(v)
+	return _u
+}
+
+// SetNillableCloseReason sets the "close_reason" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableCloseReason(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetCloseReason(*v)
+	}
+	return _u
+}
+
+// ClearCloseReason clears the value of the "close_reason" field.
+func (_u *TrafficUpdate) ClearCloseReason() *TrafficUpdate {
+	_u.mutation.ClearCloseReason()
+	return _u
+}
+
+// SetGrpcStatus sets the "grpc_status" field.
+func (_u *TrafficUpdate) SetGrpcStatus(v int) *TrafficUpdate {
+	_u.mutation.ResetGrpcStatus()
+	_u.mutation.SetGrpcStatus(v)
+	return _u
+}
+
+// SetNillableGrpcStatus sets the "grpc_status" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableGrpcStatus(v *int) *TrafficUpdate {
+	if v != nil {
+		_u.SetGrpcStatus(*v)
+	}
+	return _u
+}
+
+// AddGrpcStatus adds value to the "grpc_status" field.
+func (_u *TrafficUpdate) AddGrpcStatus(v int) *TrafficUpdate {
+	_u.mutation.AddGrpcStatus(v)
+	return _u
+}
+
+// ClearGrpcStatus clears the value of the "grpc_status" field.
+func (_u *TrafficUpdate) ClearGrpcStatus() *TrafficUpdate {
+	_u.mutation.ClearGrpcStatus()
+	return _u
+}
+
+// SetGrpcMessage sets the "grpc_message" field.
+func (_u *TrafficUpdate) SetGrpcMessage(v string) *TrafficUpdate {
+	_u.mutation.SetGrpcMessage(v)
+	return _u
+}
+
+// SetNillableGrpcMessage sets the "grpc_message" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableGrpcMessage(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetGrpcMessage(*v)
+	}
+	return _u
+}
+
+// ClearGrpcMessage clears the value of the "grpc_message" field.
+func (_u *TrafficUpdate) ClearGrpcMessage() *TrafficUpdate {
+	_u.mutation.ClearGrpcMessage()
+	return _u
+}
+
 // SetClientIP sets the "client_ip" field.
 func (_u *TrafficUpdate) SetClientIP(v string) *TrafficUpdate {
 	_u.mutation.SetClientIP(v)
@@ -401,6 +795,26 @@ func (_u *TrafficUpdate) ClearClientIP() *TrafficUpdate {
 	return _u
 }
 
+// SetAuthUser sets the "auth_user" field.
+func (_u *TrafficUpdate) SetAuthUser(v string) *TrafficUpdate {
+	_u.mutation.SetAuthUser(v)
+	return _u
+}
+
+// SetNillableAuthUser sets the "auth_user" field if the given value is not nil.
+func (_u *TrafficUpdate) SetNillableAuthUser(v *string) *TrafficUpdate {
+	if v != nil {
+		_u.SetAuthUser(*v)
+	}
+	return _u
+}
+
+// ClearAuthUser clears the value of the "auth_user" field.
+func (_u *TrafficUpdate) ClearAuthUser() *TrafficUpdate {
+	_u.mutation.ClearAuthUser()
+	return _u
+}
+
 // SetError sets the "error" field.
 func (_u *TrafficUpdate) SetError(v string) *TrafficUpdate {
 	_u.mutation.SetError(v)
@@ -550,6 +964,17 @@ func (_u *TrafficUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.RequestHeadersCleared() {
 		_spec.ClearField(traffic.FieldRequestHeaders, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.RequestHeaderNames(); ok {
+		_spec.SetField(traffic.FieldRequestHeaderNames, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRequestHeaderNames(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldRequestHeaderNames, value)
+		})
+	}
+	if _u.mutation.RequestHeaderNamesCleared() {
+		_spec.ClearField(traffic.FieldRequestHeaderNames, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.RequestBody(); ok {
 		_spec.SetField(traffic.FieldRequestBody, field.TypeBytes, value)
 	}
@@ -565,12 +990,36 @@ func (_u *TrafficUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.RequestIsBinary(); ok {
 		_spec.SetField(traffic.FieldRequestIsBinary, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.RequestBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldRequestBodyTruncated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.RequestDecodedSize(); ok {
+		_spec.SetField(traffic.FieldRequestDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestDecodedSize(); ok {
+		_spec.AddField(traffic.FieldRequestDecodedSize, field.TypeInt64, value)
+	}
 	if value, ok := _u.mutation.ContentType(); ok {
 		_spec.SetField(traffic.FieldContentType, field.TypeString, value)
 	}
 	if _u.mutation.ContentTypeCleared() {
 		_spec.ClearField(traffic.FieldContentType, field.TypeString)
 	}
+	if value, ok := _u.mutation.HTTPVersion(); ok {
+		_spec.SetField(traffic.FieldHTTPVersion, field.TypeString, value)
+	}
+	if _u.mutation.HTTPVersionCleared() {
+		_spec.ClearField(traffic.FieldHTTPVersion, field.TypeString)
+	}
+	if value, ok := _u.mutation.StreamID(); ok {
+		_spec.SetField(traffic.FieldStreamID, field.TypeUint32, value)
+	}
+	if value, ok := _u.mutation.AddedStreamID(); ok {
+		_spec.AddField(traffic.FieldStreamID, field.TypeUint32, value)
+	}
+	if _u.mutation.StreamIDCleared() {
+		_spec.ClearField(traffic.FieldStreamID, field.TypeUint32)
+	}
 	if value, ok := _u.mutation.StatusCode(); ok {
 		_spec.SetField(traffic.FieldStatusCode, field.TypeInt, value)
 	}
@@ -583,12 +1032,29 @@ func (_u *TrafficUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.StatusTextCleared() {
 		_spec.ClearField(traffic.FieldStatusText, field.TypeString)
 	}
+	if value, ok := _u.mutation.ResponseHTTPVersion(); ok {
+		_spec.SetField(traffic.FieldResponseHTTPVersion, field.TypeString, value)
+	}
+	if _u.mutation.ResponseHTTPVersionCleared() {
+		_spec.ClearField(traffic.FieldResponseHTTPVersion, field.TypeString)
+	}
 	if value, ok := _u.mutation.ResponseHeaders(); ok {
 		_spec.SetField(traffic.FieldResponseHeaders, field.TypeJSON, value)
 	}
 	if _u.mutation.ResponseHeadersCleared() {
 		_spec.ClearField(traffic.FieldResponseHeaders, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.ResponseHeaderNames(); ok {
+		_spec.SetField(traffic.FieldResponseHeaderNames, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedResponseHeaderNames(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldResponseHeaderNames, value)
+		})
+	}
+	if _u.mutation.ResponseHeaderNamesCleared() {
+		_spec.ClearField(traffic.FieldResponseHeaderNames, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.ResponseBody(); ok {
 		_spec.SetField(traffic.FieldResponseBody, field.TypeBytes, value)
 	}
@@ -604,6 +1070,26 @@ func (_u *TrafficUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.ResponseIsBinary(); ok {
 		_spec.SetField(traffic.FieldResponseIsBinary, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.ResponseBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldResponseBodyTruncated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseDecodedSize(); ok {
+		_spec.SetField(traffic.FieldResponseDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseDecodedSize(); ok {
+		_spec.AddField(traffic.FieldResponseDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ResponseEvents(); ok {
+		_spec.SetField(traffic.FieldResponseEvents, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedResponseEvents(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldResponseEvents, value)
+		})
+	}
+	if _u.mutation.ResponseEventsCleared() {
+		_spec.ClearField(traffic.FieldResponseEvents, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.ResponseContentType(); ok {
 		_spec.SetField(traffic.FieldResponseContentType, field.TypeString, value)
 	}
@@ -628,17 +1114,86 @@ func (_u *TrafficUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.TtfbMsCleared() {
 		_spec.ClearField(traffic.FieldTtfbMs, field.TypeFloat64)
 	}
-	if value, ok := _u.mutation.ClientIP(); ok {
-		_spec.SetField(traffic.FieldClientIP, field.TypeString, value)
+	if value, ok := _u.mutation.RecordType(); ok {
+		_spec.SetField(traffic.FieldRecordType, field.TypeString, value)
 	}
-	if _u.mutation.ClientIPCleared() {
-		_spec.ClearField(traffic.FieldClientIP, field.TypeString)
+	if value, ok := _u.mutation.ConnectionID(); ok {
+		_spec.SetField(traffic.FieldConnectionID, field.TypeString, value)
 	}
-	if value, ok := _u.mutation.Error(); ok {
-		_spec.SetField(traffic.FieldError, field.TypeString, value)
+	if _u.mutation.ConnectionIDCleared() {
+		_spec.ClearField(traffic.FieldConnectionID, field.TypeString)
 	}
-	if _u.mutation.ErrorCleared() {
-		_spec.ClearField(traffic.FieldError, field.TypeString)
+	if value, ok := _u.mutation.FrameSequence(); ok {
+		_spec.SetField(traffic.FieldFrameSequence, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedFrameSequence(); ok {
+		_spec.AddField(traffic.FieldFrameSequence, field.TypeInt64, value)
+	}
+	if _u.mutation.FrameSequenceCleared() {
+		_spec.ClearField(traffic.FieldFrameSequence, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.FrameDirection(); ok {
+		_spec.SetField(traffic.FieldFrameDirection, field.TypeString, value)
+	}
+	if _u.mutation.FrameDirectionCleared() {
+		_spec.ClearField(traffic.FieldFrameDirection, field.TypeString)
+	}
+	if value, ok := _u.mutation.FrameOpcode(); ok {
+		_spec.SetField(traffic.FieldFrameOpcode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedFrameOpcode(); ok {
+		_spec.AddField(traffic.FieldFrameOpcode, field.TypeInt, value)
+	}
+	if _u.mutation.FrameOpcodeCleared() {
+		_spec.ClearField(traffic.FieldFrameOpcode, field.TypeInt)
+	}
+	if value, ok := _u.mutation.CloseCode(); ok {
+		_spec.SetField(traffic.FieldCloseCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedCloseCode(); ok {
+		_spec.AddField(traffic.FieldCloseCode, field.TypeInt, value)
+	}
+	if _u.mutation.CloseCodeCleared() {
+		_spec.ClearField(traffic.FieldCloseCode, field.TypeInt)
+	}
+	if value, ok := _u.mutation.CloseReason(); ok {
+		_spec.SetField(traffic.FieldCloseReason, field.TypeString, value)
+	}
+	if _u.mutation.CloseReasonCleared() {
+		_spec.ClearField(traffic.FieldCloseReason, field.TypeString)
+	}
+	if value, ok := _u.mutation.GrpcStatus(); ok {
+		_spec.SetField(traffic.FieldGrpcStatus, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedGrpcStatus(); ok {
+		_spec.AddField(traffic.FieldGrpcStatus, field.TypeInt, value)
+	}
+	if _u.mutation.GrpcStatusCleared() {
+		_spec.ClearField(traffic.FieldGrpcStatus, field.TypeInt)
+	}
+	if value, ok := _u.mutation.GrpcMessage(); ok {
+		_spec.SetField(traffic.FieldGrpcMessage, field.TypeString, value)
+	}
+	if _u.mutation.GrpcMessageCleared() {
+		_spec.ClearField(traffic.FieldGrpcMessage, field.TypeString)
+	}
+	if value, ok := _u.mutation.ClientIP(); ok {
+		_spec.SetField(traffic.FieldClientIP, field.TypeString, value)
+	}
+	if _u.mutation.ClientIPCleared() {
+		_spec.ClearField(traffic.FieldClientIP, field.TypeString)
+	}
+	if value, ok := _u.mutation.AuthUser(); ok {
+		_spec.SetField(traffic.FieldAuthUser, field.TypeString, value)
+	}
+	if _u.mutation.AuthUserCleared() {
+		_spec.ClearField(traffic.FieldAuthUser, field.TypeString)
+	}
+	if value, ok := _u.mutation.Error(); ok {
+		_spec.SetField(traffic.FieldError, field.TypeString, value)
+	}
+	if _u.mutation.ErrorCleared() {
+		_spec.ClearField(traffic.FieldError, field.TypeString)
 	}
 	if value, ok := _u.mutation.Tags(); ok {
 		_spec.SetField(traffic.FieldTags, field.TypeJSON, value)
@@ -802,6 +1357,24 @@ func (_u *TrafficUpdateOne) ClearRequestHeaders() *TrafficUpdateOne {
 	return _u
 }
 
+// SetRequestHeaderNames sets the "request_header_names" field.
+func (_u *TrafficUpdateOne) SetRequestHeaderNames(v []string) *TrafficUpdateOne {
+	_u.mutation.SetRequestHeaderNames(v)
+	return _u
+}
+
+// AppendRequestHeaderNames appends value to the "request_header_names" field.
+func (_u *TrafficUpdateOne) AppendRequestHeaderNames(v []string) *TrafficUpdateOne {
+	_u.mutation.AppendRequestHeaderNames(v)
+	return _u
+}
+
+// ClearRequestHeaderNames clears the value of the "request_header_names" field.
+func (_u *TrafficUpdateOne) ClearRequestHeaderNames() *TrafficUpdateOne {
+	_u.mutation.ClearRequestHeaderNames()
+	return _u
+}
+
 // SetRequestBody sets the "request_body" field.
 func (_u *TrafficUpdateOne) SetRequestBody(v []byte) *TrafficUpdateOne {
 	_u.mutation.SetRequestBody(v)
@@ -849,6 +1422,41 @@ func (_u *TrafficUpdateOne) SetNillableRequestIsBinary(v *bool) *TrafficUpdateOn
 	return _u
 }
 
+// SetRequestBodyTruncated sets the "request_body_truncated" field.
+func (_u *TrafficUpdateOne) SetRequestBodyTruncated(v bool) *TrafficUpdateOne {
+	_u.mutation.SetRequestBodyTruncated(v)
+	return _u
+}
+
+// SetNillableRequestBodyTruncated sets the "request_body_truncated" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableRequestBodyTruncated(v *bool) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetRequestBodyTruncated(*v)
+	}
+	return _u
+}
+
+// SetRequestDecodedSize sets the "request_decoded_size" field.
+func (_u *TrafficUpdateOne) SetRequestDecodedSize(v int64) *TrafficUpdateOne {
+	_u.mutation.ResetRequestDecodedSize()
+	_u.mutation.SetRequestDecodedSize(v)
+	return _u
+}
+
+// SetNillableRequestDecodedSize sets the "request_decoded_size" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableRequestDecodedSize(v *int64) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetRequestDecodedSize(*v)
+	}
+	return _u
+}
+
+// AddRequestDecodedSize adds value to the "request_decoded_size" field.
+func (_u *TrafficUpdateOne) AddRequestDecodedSize(v int64) *TrafficUpdateOne {
+	_u.mutation.AddRequestDecodedSize(v)
+	return _u
+}
+
 // SetContentType sets the "content_type" field.
 func (_u *TrafficUpdateOne) SetContentType(v string) *TrafficUpdateOne {
 	_u.mutation.SetContentType(v)
@@ -869,6 +1477,53 @@ func (_u *TrafficUpdateOne) ClearContentType() *TrafficUpdateOne {
 	return _u
 }
 
+// SetHTTPVersion sets the "http_version" field.
+func (_u *TrafficUpdateOne) SetHTTPVersion(v string) *TrafficUpdateOne {
+	_u.mutation.SetHTTPVersion(v)
+	return _u
+}
+
+// SetNillableHTTPVersion sets the "http_version" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableHTTPVersion(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetHTTPVersion(*v)
+	}
+	return _u
+}
+
+// ClearHTTPVersion clears the value of the "http_version" field.
+func (_u *TrafficUpdateOne) ClearHTTPVersion() *TrafficUpdateOne {
+	_u.mutation.ClearHTTPVersion()
+	return _u
+}
+
+// SetStreamID sets the "stream_id" field.
+func (_u *TrafficUpdateOne) SetStreamID(v uint32) *TrafficUpdateOne {
+	_u.mutation.ResetStreamID()
+	_u.mutation.SetStreamID(v)
+	return _u
+}
+
+// SetNillableStreamID sets the "stream_id" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableStreamID(v *uint32) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetStreamID(*v)
+	}
+	return _u
+}
+
+// AddStreamID adds value to the "stream_id" field.
+func (_u *TrafficUpdateOne) AddStreamID(v int32) *TrafficUpdateOne {
+	_u.mutation.AddStreamID(v)
+	return _u
+}
+
+// ClearStreamID clears the value of the "stream_id" field.
+func (_u *TrafficUpdateOne) ClearStreamID() *TrafficUpdateOne {
+	_u.mutation.ClearStreamID()
+	return _u
+}
+
 // SetStatusCode sets the "status_code" field.
 func (_u *TrafficUpdateOne) SetStatusCode(v int) *TrafficUpdateOne {
 	_u.mutation.ResetStatusCode()
@@ -910,6 +1565,26 @@ func (_u *TrafficUpdateOne) ClearStatusText() *TrafficUpdateOne {
 	return _u
 }
 
+// SetResponseHTTPVersion sets the "response_http_version" field.
+func (_u *TrafficUpdateOne) SetResponseHTTPVersion(v string) *TrafficUpdateOne {
+	_u.mutation.SetResponseHTTPVersion(v)
+	return _u
+}
+
+// SetNillableResponseHTTPVersion sets the "response_http_version" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableResponseHTTPVersion(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetResponseHTTPVersion(*v)
+	}
+	return _u
+}
+
+// ClearResponseHTTPVersion clears the value of the "response_http_version" field.
+func (_u *TrafficUpdateOne) ClearResponseHTTPVersion() *TrafficUpdateOne {
+	_u.mutation.ClearResponseHTTPVersion()
+	return _u
+}
+
 // SetResponseHeaders sets the "response_headers" field.
 func (_u *TrafficUpdateOne) SetResponseHeaders(v map[string][]string) *TrafficUpdateOne {
 	_u.mutation.SetResponseHeaders(v)
@@ -922,6 +1597,24 @@ func (_u *TrafficUpdateOne) ClearResponseHeaders() *TrafficUpdateOne {
 	return _u
 }
 
+// SetResponseHeaderNames sets the "response_header_names" field.
+func (_u *TrafficUpdateOne) SetResponseHeaderNames(v []string) *TrafficUpdateOne {
+	_u.mutation.SetResponseHeaderNames(v)
+	return _u
+}
+
+// AppendResponseHeaderNames appends value to the "response_header_names" field.
+func (_u *TrafficUpdateOne) AppendResponseHeaderNames(v []string) *TrafficUpdateOne {
+	_u.mutation.AppendResponseHeaderNames(v)
+	return _u
+}
+
+// ClearResponseHeaderNames clears the value of the "response_header_names" field.
+func (_u *TrafficUpdateOne) ClearResponseHeaderNames() *TrafficUpdateOne {
+	_u.mutation.ClearResponseHeaderNames()
+	return _u
+}
+
 // SetResponseBody sets the "response_body" field.
 func (_u *TrafficUpdateOne) SetResponseBody(v []byte) *TrafficUpdateOne {
 	_u.mutation.SetResponseBody(v)
@@ -969,6 +1662,59 @@ func (_u *TrafficUpdateOne) SetNillableResponseIsBinary(v *bool) *TrafficUpdateO
 	return _u
 }
 
+// SetResponseBodyTruncated sets the "response_body_truncated" field.
+func (_u *TrafficUpdateOne) SetResponseBodyTruncated(v bool) *TrafficUpdateOne {
+	_u.mutation.SetResponseBodyTruncated(v)
+	return _u
+}
+
+// SetNillableResponseBodyTruncated sets the "response_body_truncated" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableResponseBodyTruncated(v *bool) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetResponseBodyTruncated(*v)
+	}
+	return _u
+}
+
+// SetResponseDecodedSize sets the "response_decoded_size" field.
+func (_u *TrafficUpdateOne) SetResponseDecodedSize(v int64) *TrafficUpdateOne {
+	_u.mutation.ResetResponseDecodedSize()
+	_u.mutation.SetResponseDecodedSize(v)
+	return _u
+}
+
+// SetNillableResponseDecodedSize sets the "response_decoded_size" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableResponseDecodedSize(v *int64) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetResponseDecodedSize(*v)
+	}
+	return _u
+}
+
+// AddResponseDecodedSize adds value to the "response_decoded_size" field.
+func (_u *TrafficUpdateOne) AddResponseDecodedSize(v int64) *TrafficUpdateOne {
+	_u.mutation.AddResponseDecodedSize(v)
+	return _u
+}
+
+// SetResponseEvents sets the "response_events" field.
+func (_u *TrafficUpdateOne) SetResponseEvents(v []capture.StreamEvent) *TrafficUpdateOne {
+	_u.mutation.SetResponseEvents(v)
+	return _u
+}
+
+// AppendResponseEvents appends value to the "response_events" field.
+func (_u *TrafficUpdateOne) AppendResponseEvents(v []capture.StreamEvent) *TrafficUpdateOne {
+	_u.mutation.AppendResponseEvents(v)
+	return _u
+}
+
+// ClearResponseEvents clears the value of the "response_events" field.
+func (_u *TrafficUpdateOne) ClearResponseEvents() *TrafficUpdateOne {
+	_u.mutation.ClearResponseEvents()
+	return _u
+}
+
 // SetResponseContentType sets the "response_content_type" field.
 func (_u *TrafficUpdateOne) SetResponseContentType(v string) *TrafficUpdateOne {
 	_u.mutation.SetResponseContentType(v)
@@ -1051,6 +1797,208 @@ func (_u *TrafficUpdateOne) ClearTtfbMs() *TrafficUpdateOne {
 	return _u
 }
 
+// SetRecordType sets the "record_type" field.
+func (_u *TrafficUpdateOne) SetRecordType(v string) *TrafficUpdateOne {
+	_u.mutation.SetRecordType(v)
+	return _u
+}
+
+// SetNillableRecordType sets the "record_type" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableRecordType(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetRecordType(*v)
+	}
+	return _u
+}
+
+// SetConnectionID sets the "connection_id" field.
+func (_u *TrafficUpdateOne) SetConnectionID(v string) *TrafficUpdateOne {
+	_u.mutation.SetConnectionID(v)
+	return _u
+}
+
+// SetNillableConnectionID sets the "connection_id" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableConnectionID(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetConnectionID(*v)
+	}
+	return _u
+}
+
+// ClearConnectionID clears the value of the "connection_id" field.
+func (_u *TrafficUpdateOne) ClearConnectionID() *TrafficUpdateOne {
+	_u.mutation.ClearConnectionID()
+	return _u
+}
+
+// SetFrameSequence sets the "frame_sequence" field.
+func (_u *TrafficUpdateOne) SetFrameSequence(v int64) *TrafficUpdateOne {
+	_u.mutation.ResetFrameSequence()
+	_u.mutation.SetFrameSequence(v)
+	return _u
+}
+
+// SetNillableFrameSequence sets the "frame_sequence" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableFrameSequence(v *int64) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetFrameSequence(*v)
+	}
+	return _u
+}
+
+// AddFrameSequence adds value to the "frame_sequence" field.
+func (_u *TrafficUpdateOne) AddFrameSequence(v int64) *TrafficUpdateOne {
+	_u.mutation.AddFrameSequence(v)
+	return _u
+}
+
+// ClearFrameSequence clears the value of the "frame_sequence" field.
+func (_u *TrafficUpdateOne) ClearFrameSequence() *TrafficUpdateOne {
+	_u.mutation.ClearFrameSequence()
+	return _u
+}
+
+// SetFrameDirection sets the "frame_direction" field.
+func (_u *TrafficUpdateOne) SetFrameDirection(v string) *TrafficUpdateOne {
+	_u.mutation.SetFrameDirection(v)
+	return _u
+}
+
+// SetNillableFrameDirection sets the "frame_direction" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableFrameDirection(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetFrameDirection(*v)
+	}
+	return _u
+}
+
+// ClearFrameDirection clears the value of the "frame_direction" field.
+func (_u *TrafficUpdateOne) ClearFrameDirection() *TrafficUpdateOne {
+	_u.mutation.ClearFrameDirection()
+	return _u
+}
+
+// SetFrameOpcode sets the "frame_opcode" field.
+func (_u *TrafficUpdateOne) SetFrameOpcode(v int) *TrafficUpdateOne {
+	_u.mutation.ResetFrameOpcode()
+	_u.mutation.SetFrameOpcode(v)
+	return _u
+}
+
+// SetNillableFrameOpcode sets the "frame_opcode" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableFrameOpcode(v *int) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetFrameOpcode(*v)
+	}
+	return _u
+}
+
+// AddFrameOpcode adds value to the "frame_opcode" field.
+func (_u *TrafficUpdateOne) AddFrameOpcode(v int) *TrafficUpdateOne {
+	_u.mutation.AddFrameOpcode(v)
+	return _u
+}
+
+// ClearFrameOpcode clears the value of the "frame_opcode" field.
+func (_u *TrafficUpdateOne) ClearFrameOpcode() *TrafficUpdateOne {
+	_u.mutation.ClearFrameOpcode()
+	return _u
+}
+
+// SetCloseCode sets the "close_code" field.
+func (_u *TrafficUpdateOne) SetCloseCode(v int) *TrafficUpdateOne {
+	_u.mutation.ResetCloseCode()
+	_u.mutation.SetCloseCode(v)
+	return _u
+}
+
+// SetNillableCloseCode sets the "close_code" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableCloseCode(v *int) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetCloseCode(*v)
+	}
+	return _u
+}
+
+// AddCloseCode adds value to the "close_code" field.
+func (_u *TrafficUpdateOne) AddCloseCode(v int) *TrafficUpdateOne {
+	_u.mutation.AddCloseCode(v)
+	return _u
+}
+
+// ClearCloseCode clears the value of the "close_code" field.
+func (_u *TrafficUpdateOne) ClearCloseCode() *TrafficUpdateOne {
+	_u.mutation.ClearCloseCode()
+	return _u
+}
+
+// SetCloseReason sets the "close_reason" field.
+func (_u *TrafficUpdateOne) SetCloseReason(v string) *TrafficUpdateOne {
+	_u.mutation.SetCloseReason(v)
+	return _u
+}
+
+// SetNillableCloseReason sets the "close_reason" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableCloseReason(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetCloseReason(*v)
+	}
+	return _u
+}
+
+// ClearCloseReason clears the value of the "close_reason" field.
+func (_u *TrafficUpdateOne) ClearCloseReason() *TrafficUpdateOne {
+	_u.mutation.ClearCloseReason()
+	return _u
+}
+
+// SetGrpcStatus sets the "grpc_status" field.
+func (_u *TrafficUpdateOne) SetGrpcStatus(v int) *TrafficUpdateOne {
+	_u.mutation.ResetGrpcStatus()
+	_u.mutation.SetGrpcStatus(v)
+	return _u
+}
+
+// SetNillableGrpcStatus sets the "grpc_status" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableGrpcStatus(v *int) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetGrpcStatus(*v)
+	}
+	return _u
+}
+
+// AddGrpcStatus adds value to the "grpc_status" field.
+func (_u *TrafficUpdateOne) AddGrpcStatus(v int) *TrafficUpdateOne {
+	_u.mutation.AddGrpcStatus(v)
+	return _u
+}
+
+// ClearGrpcStatus clears the value of the "grpc_status" field.
+func (_u *TrafficUpdateOne) ClearGrpcStatus() *TrafficUpdateOne {
+	_u.mutation.ClearGrpcStatus()
+	return _u
+}
+
+// SetGrpcMessage sets the "grpc_message" field.
+func (_u *TrafficUpdateOne) SetGrpcMessage(v string) *TrafficUpdateOne {
+	_u.mutation.SetGrpcMessage(v)
+	return _u
+}
+
+// SetNillableGrpcMessage sets the "grpc_message" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableGrpcMessage(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetGrpcMessage(*v)
+	}
+	return _u
+}
+
+// ClearGrpcMessage clears the value of the "grpc_message" field.
+func (_u *TrafficUpdateOne) ClearGrpcMessage() *TrafficUpdateOne {
+	_u.mutation.ClearGrpcMessage()
+	return _u
+}
+
 // SetClientIP sets the "client_ip" field.
 func (_u *TrafficUpdateOne) SetClientIP(v string) *TrafficUpdateOne {
 	_u.mutation.SetClientIP(v)
@@ -1071,6 +2019,26 @@ func (_u *TrafficUpdateOne) ClearClientIP() *TrafficUpdateOne {
 	return _u
 }
 
+// SetAuthUser sets the "auth_user" field.
+func (_u *TrafficUpdateOne) SetAuthUser(v string) *TrafficUpdateOne {
+	_u.mutation.SetAuthUser(v)
+	return _u
+}
+
+// SetNillableAuthUser sets the "auth_user" field if the given value is not nil.
+func (_u *TrafficUpdateOne) SetNillableAuthUser(v *string) *TrafficUpdateOne {
+	if v != nil {
+		_u.SetAuthUser(*v)
+	}
+	return _u
+}
+
+// ClearAuthUser clears the value of the "auth_user" field.
+func (_u *TrafficUpdateOne) ClearAuthUser() *TrafficUpdateOne {
+	_u.mutation.ClearAuthUser()
+	return _u
+}
+
 // SetError sets the "error" field.
 func (_u *TrafficUpdateOne) SetError(v string) *TrafficUpdateOne {
 	_u.mutation.SetError(v)
@@ -1250,6 +2218,17 @@ func (_u *TrafficUpdateOne) sqlSave(ctx context.Context) (_node *Traffic, err er
 	if _u.mutation.RequestHeadersCleared() {
 		_spec.ClearField(traffic.FieldRequestHeaders, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.RequestHeaderNames(); ok {
+		_spec.SetField(traffic.FieldRequestHeaderNames, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRequestHeaderNames(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldRequestHeaderNames, value)
+		})
+	}
+	if _u.mutation.RequestHeaderNamesCleared() {
+		_spec.ClearField(traffic.FieldRequestHeaderNames, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.RequestBody(); ok {
 		_spec.SetField(traffic.FieldRequestBody, field.TypeBytes, value)
 	}
@@ -1265,12 +2244,36 @@ func (_u *TrafficUpdateOne) sqlSave(ctx context.Context) (_node *Traffic, err er
 	if value, ok := _u.mutation.RequestIsBinary(); ok {
 		_spec.SetField(traffic.FieldRequestIsBinary, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.RequestBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldRequestBodyTruncated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.RequestDecodedSize(); ok {
+		_spec.SetField(traffic.FieldRequestDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestDecodedSize(); ok {
+		_spec.AddField(traffic.FieldRequestDecodedSize, field.TypeInt64, value)
+	}
 	if value, ok := _u.mutation.ContentType(); ok {
 		_spec.SetField(traffic.FieldContentType, field.TypeString, value)
 	}
 	if _u.mutation.ContentTypeCleared() {
 		_spec.ClearField(traffic.FieldContentType, field.TypeString)
 	}
+	if value, ok := _u.mutation.HTTPVersion(); ok {
+		_spec.SetField(traffic.FieldHTTPVersion, field.TypeString, value)
+	}
+	if _u.mutation.HTTPVersionCleared() {
+		_spec.ClearField(traffic.FieldHTTPVersion, field.TypeString)
+	}
+	if value, ok := _u.mutation.StreamID(); ok {
+		_spec.SetField(traffic.FieldStreamID, field.TypeUint32, value)
+	}
+	if value, ok := _u.mutation.AddedStreamID(); ok {
+		_spec.AddField(traffic.FieldStreamID, field.TypeUint32, value)
+	}
+	if _u.mutation.StreamIDCleared() {
+		_spec.ClearField(traffic.FieldStreamID, field.TypeUint32)
+	}
 	if value, ok := _u.mutation.StatusCode(); ok {
 		_spec.SetField(traffic.FieldStatusCode, field.TypeInt, value)
 	}
@@ -1283,12 +2286,29 @@ func (_u *TrafficUpdateOne) sqlSave(ctx context.Context) (_node *Traffic, err er
 	if _u.mutation.StatusTextCleared() {
 		_spec.ClearField(traffic.FieldStatusText, field.TypeString)
 	}
+	if value, ok := _u.mutation.ResponseHTTPVersion(); ok {
+		_spec.SetField(traffic.FieldResponseHTTPVersion, field.TypeString, value)
+	}
+	if _u.mutation.ResponseHTTPVersionCleared() {
+		_spec.ClearField(traffic.FieldResponseHTTPVersion, field.TypeString)
+	}
 	if value, ok := _u.mutation.ResponseHeaders(); ok {
 		_spec.SetField(traffic.FieldResponseHeaders, field.TypeJSON, value)
 	}
 	if _u.mutation.ResponseHeadersCleared() {
 		_spec.ClearField(traffic.FieldResponseHeaders, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.ResponseHeaderNames(); ok {
+		_spec.SetField(traffic.FieldResponseHeaderNames, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedResponseHeaderNames(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldResponseHeaderNames, value)
+		})
+	}
+	if _u.mutation.ResponseHeaderNamesCleared() {
+		_spec.ClearField(traffic.FieldResponseHeaderNames, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.ResponseBody(); ok {
 		_spec.SetField(traffic.FieldResponseBody, field.TypeBytes, value)
 	}
@@ -1304,6 +2324,26 @@ func (_u *TrafficUpdateOne) sqlSave(ctx context.Context) (_node *Traffic, err er
 	if value, ok := _u.mutation.ResponseIsBinary(); ok {
 		_spec.SetField(traffic.FieldResponseIsBinary, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.ResponseBodyTruncated(); ok {
+		_spec.SetField(traffic.FieldResponseBodyTruncated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseDecodedSize(); ok {
+		_spec.SetField(traffic.FieldResponseDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseDecodedSize(); ok {
+		_spec.AddField(traffic.FieldResponseDecodedSize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ResponseEvents(); ok {
+		_spec.SetField(traffic.FieldResponseEvents, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedResponseEvents(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, traffic.FieldResponseEvents, value)
+		})
+	}
+	if _u.mutation.ResponseEventsCleared() {
+		_spec.ClearField(traffic.FieldResponseEvents, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.ResponseContentType(); ok {
 		_spec.SetField(traffic.FieldResponseContentType, field.TypeString, value)
 	}
@@ -1328,12 +2368,81 @@ func (_u *TrafficUpdateOne) sqlSave(ctx context.Context) (_node *Traffic, err er
 	if _u.mutation.TtfbMsCleared() {
 		_spec.ClearField(traffic.FieldTtfbMs, field.TypeFloat64)
 	}
+	if value, ok := _u.mutation.RecordType(); ok {
+		_spec.SetField(traffic.FieldRecordType, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ConnectionID(); ok {
+		_spec.SetField(traffic.FieldConnectionID, field.TypeString, value)
+	}
+	if _u.mutation.ConnectionIDCleared() {
+		_spec.ClearField(traffic.FieldConnectionID, field.TypeString)
+	}
+	if value, ok := _u.mutation.FrameSequence(); ok {
+		_spec.SetField(traffic.FieldFrameSequence, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedFrameSequence(); ok {
+		_spec.AddField(traffic.FieldFrameSequence, field.TypeInt64, value)
+	}
+	if _u.mutation.FrameSequenceCleared() {
+		_spec.ClearField(traffic.FieldFrameSequence, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.FrameDirection(); ok {
+		_spec.SetField(traffic.FieldFrameDirection, field.TypeString, value)
+	}
+	if _u.mutation.FrameDirectionCleared() {
+		_spec.ClearField(traffic.FieldFrameDirection, field.TypeString)
+	}
+	if value, ok := _u.mutation.FrameOpcode(); ok {
+		_spec.SetField(traffic.FieldFrameOpcode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedFrameOpcode(); ok {
+		_spec.AddField(traffic.FieldFrameOpcode, field.TypeInt, value)
+	}
+	if _u.mutation.FrameOpcodeCleared() {
+		_spec.ClearField(traffic.FieldFrameOpcode, field.TypeInt)
+	}
+	if value, ok := _u.mutation.CloseCode(); ok {
+		_spec.SetField(traffic.FieldCloseCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedCloseCode(); ok {
+		_spec.AddField(traffic.FieldCloseCode, field.TypeInt, value)
+	}
+	if _u.mutation.CloseCodeCleared() {
+		_spec.ClearField(traffic.FieldCloseCode, field.TypeInt)
+	}
+	if value, ok := _u.mutation.CloseReason(); ok {
+		_spec.SetField(traffic.FieldCloseReason, field.TypeString, value)
+	}
+	if _u.mutation.CloseReasonCleared() {
+		_spec.ClearField(traffic.FieldCloseReason, field.TypeString)
+	}
+	if value, ok := _u.mutation.GrpcStatus(); ok {
+		_spec.SetField(traffic.FieldGrpcStatus, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedGrpcStatus(); ok {
+		_spec.AddField(traffic.FieldGrpcStatus, field.TypeInt, value)
+	}
+	if _u.mutation.GrpcStatusCleared() {
+		_spec.ClearField(traffic.FieldGrpcStatus, field.TypeInt)
+	}
+	if value, ok := _u.mutation.GrpcMessage(); ok {
+		_spec.SetField(traffic.FieldGrpcMessage, field.TypeString, value)
+	}
+	if _u.mutation.GrpcMessageCleared() {
+		_spec.ClearField(traffic.FieldGrpcMessage, field.TypeString)
+	}
 	if value, ok := _u.mutation.ClientIP(); ok {
 		_spec.SetField(traffic.FieldClientIP, field.TypeString, value)
 	}
 	if _u.mutation.ClientIPCleared() {
 		_spec.ClearField(traffic.FieldClientIP, field.TypeString)
 	}
+	if value, ok := _u.mutation.AuthUser(); ok {
+		_spec.SetField(traffic.FieldAuthUser, field.TypeString, value)
+	}
+	if _u.mutation.AuthUserCleared() {
+		_spec.ClearField(traffic.FieldAuthUser, field.TypeString)
+	}
 	if value, ok := _u.mutation.Error(); ok {
 		_spec.SetField(traffic.FieldError, field.TypeString, value)
 	}
@@ -0,0 +1,229 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"github.com/grokify/omniproxy/ui/ent/proxy"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
+)
+
+// TrafficRollup is the model entity for the TrafficRollup schema.
+type TrafficRollup struct {
+	config `json:"-"`
+	// ID of the ent.
+	ID int `json:"id,omitempty"`
+	// Start of the hour
+	Bucket time.Time `json:"bucket,omitempty"`
+	// Target host
+	Host string `json:"host,omitempty"`
+	// HTTP method
+	Method string `json:"method,omitempty"`
+	// HTTP response status code
+	StatusCode int `json:"status_code,omitempty"`
+	// Number of requests
+	Requests int64 `json:"requests,omitempty"`
+	// Sum of request durations in milliseconds
+	DurationSumMs float64 `json:"duration_sum_ms,omitempty"`
+	// Longest request duration in milliseconds
+	DurationMaxMs float64 `json:"duration_max_ms,omitempty"`
+	// Request counts per duration bucket (see backend.RollupDurationBounds)
+	DurationHistogram []int64 `json:"duration_histogram,omitempty"`
+	// Edges holds the relations/edges for other nodes in the graph.
+	// The values are being populated by the TrafficRollupQuery when eager-loading is set.
+	Edges         TrafficRollupEdges `json:"edges"`
+	proxy_rollups *int
+	selectValues  sql.SelectValues
+}
+
+// TrafficRollupEdges holds the relations/edges for other nodes in the graph.
+type TrafficRollupEdges struct {
+	// Proxy that captured the traffic
+	Proxy *Proxy `json:"proxy,omitempty"`
+	// loadedTypes holds the information for reporting if a
+	// type was loaded (or requested) in eager-loading or not.
+	loadedTypes [1]bool
+}
+
+// ProxyOrErr returns the Proxy value or an error if the edge
+// was not loaded in eager-loading, or loaded but was not found.
+func (e TrafficRollupEdges) ProxyOrErr() (*Proxy, error) {
+	if e.Proxy != nil {
+		return e.Proxy, nil
+	} else if e.loadedTypes[0] {
+		return nil, &NotFoundError{label: proxy.Label}
+	}
+	return nil, &NotLoadedError{edge: "proxy"}
+}
+
+// scanValues returns the types for scanning values from sql.Rows.
+func (*TrafficRollup) scanValues(columns []string) ([]any, error) {
+	values := make([]any, len(columns))
+	for i := range columns {
+		switch columns[i] {
+		case trafficrollup.FieldDurationHistogram:
+			values[i] = new([]byte)
+		case trafficrollup.FieldDurationSumMs, trafficrollup.FieldDurationMaxMs:
+			values[i] = new(sql.NullFloat64)
+		case trafficrollup.FieldID, trafficrollup.FieldStatusCode, trafficrollup.FieldRequests:
+			values[i] = new(sql.NullInt64)
+		case trafficrollup.FieldHost, trafficrollup.FieldMethod:
+			values[i] = new(sql.NullString)
+		case trafficrollup.FieldBucket:
+			values[i] = new(sql.NullTime)
+		case trafficrollup.ForeignKeys[0]: // proxy_rollups
+			values[i] = new(sql.NullInt64)
+		default:
+			values[i] = new(sql.UnknownType)
+		}
+	}
+	return values, nil
+}
+
+// assignValues assigns the values that were returned from sql.Rows (after scanning)
+// to the TrafficRollup fields.
+func (_m *TrafficRollup) assignValues(columns []string, values []any) error {
+	if m, n := len(values), len(columns); m < n {
+		return fmt.Errorf("mismatch number of scan values: %d != %d", m, n)
+	}
+	for i := range columns {
+		switch columns[i] {
+		case trafficrollup.FieldID:
+			value, ok := values[i].(*sql.NullInt64)
+			if !ok {
+				return fmt.Errorf("unexpected type %T for field id", value)
+			}
+			_m.ID = int(value.Int64)
+		case trafficrollup.FieldBucket:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field bucket", values[i])
+			} else if value.Valid {
+				_m.Bucket = value.Time
+			}
+		case trafficrollup.FieldHost:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field host", values[i])
+			} else if value.Valid {
+				_m.Host = value.String
+			}
+		case trafficrollup.FieldMethod:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field method", values[i])
+			} else if value.Valid {
+				_m.Method = value.String
+			}
+		case trafficrollup.FieldStatusCode:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field status_code", values[i])
+			} else if value.Valid {
+				_m.StatusCode = int(value.Int64)
+			}
+		case trafficrollup.FieldRequests:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field requests", values[i])
+			} else if value.Valid {
+				_m.Requests = value.Int64
+			}
+		case trafficrollup.FieldDurationSumMs:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field duration_sum_ms", values[i])
+			} else if value.Valid {
+				_m.DurationSumMs = value.Float64
+			}
+		case trafficrollup.FieldDurationMaxMs:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field duration_max_ms", values[i])
+			} else if value.Valid {
+				_m.DurationMaxMs = value.Float64
+			}
+		case trafficrollup.FieldDurationHistogram:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field duration_histogram", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.DurationHistogram); err != nil {
+					return fmt.Errorf("unmarshal field duration_histogram: %w", err)
+				}
+			}
+		case trafficrollup.ForeignKeys[0]:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for edge-field proxy_rollups", value)
+			} else if value.Valid {
+				_m.proxy_rollups = new(int)
+				*_m.proxy_rollups = int(value.Int64)
+			}
+		default:
+			_m.selectValues.Set(columns[i], values[i])
+		}
+	}
+	return nil
+}
+
+// Value returns the ent.Value that was dynamically selected and assigned to the TrafficRollup.
+// This includes values selected through modifiers, order, etc.
+func (_m *TrafficRollup) Value(name string) (ent.Value, error) {
+	return _m.selectValues.Get(name)
+}
+
+// QueryProxy queries the "proxy" edge of the TrafficRollup entity.
+func (_m *TrafficRollup) QueryProxy() *ProxyQuery {
+	return NewTrafficRollupClient(_m.config).QueryProxy(_m)
+}
+
+// Update returns a builder for updating this TrafficRollup.
+// Note that you need to call TrafficRollup.Unwrap() before calling this method if this TrafficRollup
+// was returned from a transaction, and the transaction was committed or rolled back.
+func (_m *TrafficRollup) Update() *TrafficRollupUpdateOne {
+	return NewTrafficRollupClient(_m.config).UpdateOne(_m)
+}
+
+// Unwrap unwraps the TrafficRollup entity that was returned from a transaction after it was closed,
+// so that all future queries will be executed through the driver which created the transaction.
+func (_m *TrafficRollup) Unwrap() *TrafficRollup {
+	_tx, ok := _m.config.driver.(*txDriver)
+	if !ok {
+		panic("ent: TrafficRollup is not a transactional entity")
+	}
+	_m.config.driver = _tx.drv
+	return _m
+}
+
+// String implements the fmt.Stringer.
+func (_m *TrafficRollup) String() string {
+	var builder strings.Builder
+	builder.WriteString("TrafficRollup(")
+	builder.WriteString(fmt.Sprintf("id=%v, ", _m.ID))
+	builder.WriteString("bucket=")
+	builder.WriteString(_m.Bucket.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("host=")
+	builder.WriteString(_m.Host)
+	builder.WriteString(", ")
+	builder.WriteString("method=")
+	builder.WriteString(_m.Method)
+	builder.WriteString(", ")
+	builder.WriteString("status_code=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StatusCode))
+	builder.WriteString(", ")
+	builder.WriteString("requests=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Requests))
+	builder.WriteString(", ")
+	builder.WriteString("duration_sum_ms=")
+	builder.WriteString(fmt.Sprintf("%v", _m.DurationSumMs))
+	builder.WriteString(", ")
+	builder.WriteString("duration_max_ms=")
+	builder.WriteString(fmt.Sprintf("%v", _m.DurationMaxMs))
+	builder.WriteString(", ")
+	builder.WriteString("duration_histogram=")
+	builder.WriteString(fmt.Sprintf("%v", _m.DurationHistogram))
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+// TrafficRollups is a parsable slice of TrafficRollup.
+type TrafficRollups []*TrafficRollup
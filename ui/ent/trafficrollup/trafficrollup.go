@@ -0,0 +1,133 @@
+// Code generated by ent, DO NOT EDIT.
+
+package trafficrollup
+
+import (
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+)
+
+const (
+	// Label holds the string label denoting the trafficrollup type in the database.
+	Label = "traffic_rollup"
+	// FieldID holds the string denoting the id field in the database.
+	FieldID = "id"
+	// FieldBucket holds the string denoting the bucket field in the database.
+	FieldBucket = "bucket"
+	// FieldHost holds the string denoting the host field in the database.
+	FieldHost = "host"
+	// FieldMethod holds the string denoting the method field in the database.
+	FieldMethod = "method"
+	// FieldStatusCode holds the string denoting the status_code field in the database.
+	FieldStatusCode = "status_code"
+	// FieldRequests holds the string denoting the requests field in the database.
+	FieldRequests = "requests"
+	// FieldDurationSumMs holds the string denoting the duration_sum_ms field in the database.
+	FieldDurationSumMs = "duration_sum_ms"
+	// FieldDurationMaxMs holds the string denoting the duration_max_ms field in the database.
+	FieldDurationMaxMs = "duration_max_ms"
+	// FieldDurationHistogram holds the string denoting the duration_histogram field in the database.
+	FieldDurationHistogram = "duration_histogram"
+	// EdgeProxy holds the string denoting the proxy edge name in mutations.
+	EdgeProxy = "proxy"
+	// Table holds the table name of the trafficrollup in the database.
+	Table = "traffic_rollups"
+	// ProxyTable is the table that holds the proxy relation/edge.
+	ProxyTable = "traffic_rollups"
+	// ProxyInverseTable is the table name for the Proxy entity.
+	// It exists in this package in order to avoid circular dependency with the "proxy" package.
+	ProxyInverseTable = "proxies"
+	// ProxyColumn is the table column denoting the proxy relation/edge.
+	ProxyColumn = "proxy_rollups"
+)
+
+// Columns holds all SQL columns for trafficrollup fields.
+var Columns = []string{
+	FieldID,
+	FieldBucket,
+	FieldHost,
+	FieldMethod,
+	FieldStatusCode,
+	FieldRequests,
+	FieldDurationSumMs,
+	FieldDurationMaxMs,
+	FieldDurationHistogram,
+}
+
+// ForeignKeys holds the SQL foreign-keys that are owned by the "traffic_rollups"
+// table and are not defined as standalone fields in the schema.
+var ForeignKeys = []string{
+	"proxy_rollups",
+}
+
+// ValidColumn reports if the column name is valid (part of the table columns).
+func ValidColumn(column string) bool {
+	for i := range Columns {
+		if column == Columns[i] {
+			return true
+		}
+	}
+	for i := range ForeignKeys {
+		if column == ForeignKeys[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderOption defines the ordering options for the TrafficRollup queries.
+type OrderOption func(*sql.Selector)
+
+// ByID orders the results by the id field.
+func ByID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldID, opts...).ToFunc()
+}
+
+// ByBucket orders the results by the bucket field.
+func ByBucket(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBucket, opts...).ToFunc()
+}
+
+// ByHost orders the results by the host field.
+func ByHost(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldHost, opts...).ToFunc()
+}
+
+// ByMethod orders the results by the method field.
+func ByMethod(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMethod, opts...).ToFunc()
+}
+
+// ByStatusCode orders the results by the status_code field.
+func ByStatusCode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStatusCode, opts...).ToFunc()
+}
+
+// ByRequests orders the results by the requests field.
+func ByRequests(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequests, opts...).ToFunc()
+}
+
+// ByDurationSumMs orders the results by the duration_sum_ms field.
+func ByDurationSumMs(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDurationSumMs, opts...).ToFunc()
+}
+
+// ByDurationMaxMs orders the results by the duration_max_ms field.
+func ByDurationMaxMs(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDurationMaxMs, opts...).ToFunc()
+}
+
+// ByProxyField orders the results by proxy field.
+func ByProxyField(field string, opts ...sql.OrderTermOption) OrderOption {
+	return func(s *sql.Selector) {
+		sqlgraph.OrderByNeighborTerms(s, newProxyStep(), sql.OrderByField(field, opts...))
+	}
+}
+func newProxyStep() *sqlgraph.Step {
+	return sqlgraph.NewStep(
+		sqlgraph.From(Table, FieldID),
+		sqlgraph.To(ProxyInverseTable, FieldID),
+		sqlgraph.Edge(sqlgraph.M2O, true, ProxyTable, ProxyColumn),
+	)
+}
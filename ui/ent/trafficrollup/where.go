@@ -0,0 +1,459 @@
+// Code generated by ent, DO NOT EDIT.
+
+package trafficrollup
+
+import (
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+)
+
+// ID filters vertices based on their ID field.
+func ID(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldID, id))
+}
+
+// IDEQ applies the EQ predicate on the ID field.
+func IDEQ(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldID, id))
+}
+
+// IDNEQ applies the NEQ predicate on the ID field.
+func IDNEQ(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldID, id))
+}
+
+// IDIn applies the In predicate on the ID field.
+func IDIn(ids ...int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldID, ids...))
+}
+
+// IDNotIn applies the NotIn predicate on the ID field.
+func IDNotIn(ids ...int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldID, ids...))
+}
+
+// IDGT applies the GT predicate on the ID field.
+func IDGT(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldID, id))
+}
+
+// IDGTE applies the GTE predicate on the ID field.
+func IDGTE(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldID, id))
+}
+
+// IDLT applies the LT predicate on the ID field.
+func IDLT(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldID, id))
+}
+
+// IDLTE applies the LTE predicate on the ID field.
+func IDLTE(id int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldID, id))
+}
+
+// Bucket applies equality check predicate on the "bucket" field. It's identical to BucketEQ.
+func Bucket(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldBucket, v))
+}
+
+// Host applies equality check predicate on the "host" field. It's identical to HostEQ.
+func Host(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldHost, v))
+}
+
+// Method applies equality check predicate on the "method" field. It's identical to MethodEQ.
+func Method(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldMethod, v))
+}
+
+// StatusCode applies equality check predicate on the "status_code" field. It's identical to StatusCodeEQ.
+func StatusCode(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldStatusCode, v))
+}
+
+// Requests applies equality check predicate on the "requests" field. It's identical to RequestsEQ.
+func Requests(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldRequests, v))
+}
+
+// DurationSumMs applies equality check predicate on the "duration_sum_ms" field. It's identical to DurationSumMsEQ.
+func DurationSumMs(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldDurationSumMs, v))
+}
+
+// DurationMaxMs applies equality check predicate on the "duration_max_ms" field. It's identical to DurationMaxMsEQ.
+func DurationMaxMs(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldDurationMaxMs, v))
+}
+
+// BucketEQ applies the EQ predicate on the "bucket" field.
+func BucketEQ(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldBucket, v))
+}
+
+// BucketNEQ applies the NEQ predicate on the "bucket" field.
+func BucketNEQ(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldBucket, v))
+}
+
+// BucketIn applies the In predicate on the "bucket" field.
+func BucketIn(vs ...time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldBucket, vs...))
+}
+
+// BucketNotIn applies the NotIn predicate on the "bucket" field.
+func BucketNotIn(vs ...time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldBucket, vs...))
+}
+
+// BucketGT applies the GT predicate on the "bucket" field.
+func BucketGT(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldBucket, v))
+}
+
+// BucketGTE applies the GTE predicate on the "bucket" field.
+func BucketGTE(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldBucket, v))
+}
+
+// BucketLT applies the LT predicate on the "bucket" field.
+func BucketLT(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldBucket, v))
+}
+
+// BucketLTE applies the LTE predicate on the "bucket" field.
+func BucketLTE(v time.Time) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldBucket, v))
+}
+
+// HostEQ applies the EQ predicate on the "host" field.
+func HostEQ(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldHost, v))
+}
+
+// HostNEQ applies the NEQ predicate on the "host" field.
+func HostNEQ(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldHost, v))
+}
+
+// HostIn applies the In predicate on the "host" field.
+func HostIn(vs ...string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldHost, vs...))
+}
+
+// HostNotIn applies the NotIn predicate on the "host" field.
+func HostNotIn(vs ...string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldHost, vs...))
+}
+
+// HostGT applies the GT predicate on the "host" field.
+func HostGT(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldHost, v))
+}
+
+// HostGTE applies the GTE predicate on the "host" field.
+func HostGTE(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldHost, v))
+}
+
+// HostLT applies the LT predicate on the "host" field.
+func HostLT(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldHost, v))
+}
+
+// HostLTE applies the LTE predicate on the "host" field.
+func HostLTE(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldHost, v))
+}
+
+// HostContains applies the Contains predicate on the "host" field.
+func HostContains(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldContains(FieldHost, v))
+}
+
+// HostHasPrefix applies the HasPrefix predicate on the "host" field.
+func HostHasPrefix(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldHasPrefix(FieldHost, v))
+}
+
+// HostHasSuffix applies the HasSuffix predicate on the "host" field.
+func HostHasSuffix(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldHasSuffix(FieldHost, v))
+}
+
+// HostEqualFold applies the EqualFold predicate on the "host" field.
+func HostEqualFold(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEqualFold(FieldHost, v))
+}
+
+// HostContainsFold applies the ContainsFold predicate on the "host" field.
+func HostContainsFold(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldContainsFold(FieldHost, v))
+}
+
+// MethodEQ applies the EQ predicate on the "method" field.
+func MethodEQ(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldMethod, v))
+}
+
+// MethodNEQ applies the NEQ predicate on the "method" field.
+func MethodNEQ(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldMethod, v))
+}
+
+// MethodIn applies the In predicate on the "method" field.
+func MethodIn(vs ...string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldMethod, vs...))
+}
+
+// MethodNotIn applies the NotIn predicate on the "method" field.
+func MethodNotIn(vs ...string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldMethod, vs...))
+}
+
+// MethodGT applies the GT predicate on the "method" field.
+func MethodGT(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldMethod, v))
+}
+
+// MethodGTE applies the GTE predicate on the "method" field.
+func MethodGTE(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldMethod, v))
+}
+
+// MethodLT applies the LT predicate on the "method" field.
+func MethodLT(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldMethod, v))
+}
+
+// MethodLTE applies the LTE predicate on the "method" field.
+func MethodLTE(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldMethod, v))
+}
+
+// MethodContains applies the Contains predicate on the "method" field.
+func MethodContains(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldContains(FieldMethod, v))
+}
+
+// MethodHasPrefix applies the HasPrefix predicate on the "method" field.
+func MethodHasPrefix(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldHasPrefix(FieldMethod, v))
+}
+
+// MethodHasSuffix applies the HasSuffix predicate on the "method" field.
+func MethodHasSuffix(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldHasSuffix(FieldMethod, v))
+}
+
+// MethodEqualFold applies the EqualFold predicate on the "method" field.
+func MethodEqualFold(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEqualFold(FieldMethod, v))
+}
+
+// MethodContainsFold applies the ContainsFold predicate on the "method" field.
+func MethodContainsFold(v string) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldContainsFold(FieldMethod, v))
+}
+
+// StatusCodeEQ applies the EQ predicate on the "status_code" field.
+func StatusCodeEQ(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldStatusCode, v))
+}
+
+// StatusCodeNEQ applies the NEQ predicate on the "status_code" field.
+func StatusCodeNEQ(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldStatusCode, v))
+}
+
+// StatusCodeIn applies the In predicate on the "status_code" field.
+func StatusCodeIn(vs ...int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldStatusCode, vs...))
+}
+
+// StatusCodeNotIn applies the NotIn predicate on the "status_code" field.
+func StatusCodeNotIn(vs ...int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldStatusCode, vs...))
+}
+
+// StatusCodeGT applies the GT predicate on the "status_code" field.
+func StatusCodeGT(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldStatusCode, v))
+}
+
+// StatusCodeGTE applies the GTE predicate on the "status_code" field.
+func StatusCodeGTE(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldStatusCode, v))
+}
+
+// StatusCodeLT applies the LT predicate on the "status_code" field.
+func StatusCodeLT(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldStatusCode, v))
+}
+
+// StatusCodeLTE applies the LTE predicate on the "status_code" field.
+func StatusCodeLTE(v int) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldStatusCode, v))
+}
+
+// RequestsEQ applies the EQ predicate on the "requests" field.
+func RequestsEQ(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldRequests, v))
+}
+
+// RequestsNEQ applies the NEQ predicate on the "requests" field.
+func RequestsNEQ(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldRequests, v))
+}
+
+// RequestsIn applies the In predicate on the "requests" field.
+func RequestsIn(vs ...int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldRequests, vs...))
+}
+
+// RequestsNotIn applies the NotIn predicate on the "requests" field.
+func RequestsNotIn(vs ...int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldRequests, vs...))
+}
+
+// RequestsGT applies the GT predicate on the "requests" field.
+func RequestsGT(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldRequests, v))
+}
+
+// RequestsGTE applies the GTE predicate on the "requests" field.
+func RequestsGTE(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldRequests, v))
+}
+
+// RequestsLT applies the LT predicate on the "requests" field.
+func RequestsLT(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldRequests, v))
+}
+
+// RequestsLTE applies the LTE predicate on the "requests" field.
+func RequestsLTE(v int64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldRequests, v))
+}
+
+// DurationSumMsEQ applies the EQ predicate on the "duration_sum_ms" field.
+func DurationSumMsEQ(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldDurationSumMs, v))
+}
+
+// DurationSumMsNEQ applies the NEQ predicate on the "duration_sum_ms" field.
+func DurationSumMsNEQ(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldDurationSumMs, v))
+}
+
+// DurationSumMsIn applies the In predicate on the "duration_sum_ms" field.
+func DurationSumMsIn(vs ...float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldDurationSumMs, vs...))
+}
+
+// DurationSumMsNotIn applies the NotIn predicate on the "duration_sum_ms" field.
+func DurationSumMsNotIn(vs ...float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldDurationSumMs, vs...))
+}
+
+// DurationSumMsGT applies the GT predicate on the "duration_sum_ms" field.
+func DurationSumMsGT(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldDurationSumMs, v))
+}
+
+// DurationSumMsGTE applies the GTE predicate on the "duration_sum_ms" field.
+func DurationSumMsGTE(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldDurationSumMs, v))
+}
+
+// DurationSumMsLT applies the LT predicate on the "duration_sum_ms" field.
+func DurationSumMsLT(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldDurationSumMs, v))
+}
+
+// DurationSumMsLTE applies the LTE predicate on the "duration_sum_ms" field.
+func DurationSumMsLTE(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldDurationSumMs, v))
+}
+
+// DurationMaxMsEQ applies the EQ predicate on the "duration_max_ms" field.
+func DurationMaxMsEQ(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldEQ(FieldDurationMaxMs, v))
+}
+
+// DurationMaxMsNEQ applies the NEQ predicate on the "duration_max_ms" field.
+func DurationMaxMsNEQ(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNEQ(FieldDurationMaxMs, v))
+}
+
+// DurationMaxMsIn applies the In predicate on the "duration_max_ms" field.
+func DurationMaxMsIn(vs ...float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldIn(FieldDurationMaxMs, vs...))
+}
+
+// DurationMaxMsNotIn applies the NotIn predicate on the "duration_max_ms" field.
+func DurationMaxMsNotIn(vs ...float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldNotIn(FieldDurationMaxMs, vs...))
+}
+
+// DurationMaxMsGT applies the GT predicate on the "duration_max_ms" field.
+func DurationMaxMsGT(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGT(FieldDurationMaxMs, v))
+}
+
+// DurationMaxMsGTE applies the GTE predicate on the "duration_max_ms" field.
+func DurationMaxMsGTE(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldGTE(FieldDurationMaxMs, v))
+}
+
+// DurationMaxMsLT applies the LT predicate on the "duration_max_ms" field.
+func DurationMaxMsLT(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLT(FieldDurationMaxMs, v))
+}
+
+// DurationMaxMsLTE applies the LTE predicate on the "duration_max_ms" field.
+func DurationMaxMsLTE(v float64) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.FieldLTE(FieldDurationMaxMs, v))
+}
+
+// HasProxy applies the HasEdge predicate on the "proxy" edge.
+func HasProxy() predicate.TrafficRollup {
+	return predicate.TrafficRollup(func(s *sql.Selector) {
+		step := sqlgraph.NewStep(
+			sqlgraph.From(Table, FieldID),
+			sqlgraph.Edge(sqlgraph.M2O, true, ProxyTable, ProxyColumn),
+		)
+		sqlgraph.HasNeighbors(s, step)
+	})
+}
+
+// HasProxyWith applies the HasEdge predicate on the "proxy" edge with a given conditions (other predicates).
+func HasProxyWith(preds ...predicate.Proxy) predicate.TrafficRollup {
+	return predicate.TrafficRollup(func(s *sql.Selector) {
+		step := newProxyStep()
+		sqlgraph.HasNeighborsWith(s, step, func(s *sql.Selector) {
+			for _, p := range preds {
+				p(s)
+			}
+		})
+	})
+}
+
+// And groups predicates with the AND operator between them.
+func And(predicates ...predicate.TrafficRollup) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.AndPredicates(predicates...))
+}
+
+// Or groups predicates with the OR operator between them.
+func Or(predicates ...predicate.TrafficRollup) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.OrPredicates(predicates...))
+}
+
+// Not applies the not operator on the given predicate.
+func Not(p predicate.TrafficRollup) predicate.TrafficRollup {
+	return predicate.TrafficRollup(sql.NotPredicates(p))
+}
@@ -0,0 +1,307 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/grokify/omniproxy/ui/ent/proxy"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
+)
+
+// TrafficRollupCreate is the builder for creating a TrafficRollup entity.
+type TrafficRollupCreate struct {
+	config
+	mutation *TrafficRollupMutation
+	hooks    []Hook
+}
+
+// SetBucket sets the "bucket" field.
+func (_c *TrafficRollupCreate) SetBucket(v time.Time) *TrafficRollupCreate {
+	_c.mutation.SetBucket(v)
+	return _c
+}
+
+// SetHost sets the "host" field.
+func (_c *TrafficRollupCreate) SetHost(v string) *TrafficRollupCreate {
+	_c.mutation.SetHost(v)
+	return _c
+}
+
+// SetMethod sets the "method" field.
+func (_c *TrafficRollupCreate) SetMethod(v string) *TrafficRollupCreate {
+	_c.mutation.SetMethod(v)
+	return _c
+}
+
+// SetStatusCode sets the "status_code" field.
+func (_c *TrafficRollupCreate) SetStatusCode(v int) *TrafficRollupCreate {
+	_c.mutation.SetStatusCode(v)
+	return _c
+}
+
+// SetRequests sets the "requests" field.
+func (_c *TrafficRollupCreate) SetRequests(v int64) *TrafficRollupCreate {
+	_c.mutation.SetRequests(v)
+	return _c
+}
+
+// SetDurationSumMs sets the "duration_sum_ms" field.
+func (_c *TrafficRollupCreate) SetDurationSumMs(v float64) *TrafficRollupCreate {
+	_c.mutation.SetDurationSumMs(v)
+	return _c
+}
+
+// SetDurationMaxMs sets the "duration_max_ms" field.
+func (_c *TrafficRollupCreate) SetDurationMaxMs(v float64) *TrafficRollupCreate {
+	_c.mutation.SetDurationMaxMs(v)
+	return _c
+}
+
+// SetDurationHistogram sets the "duration_histogram" field.
+func (_c *TrafficRollupCreate) SetDurationHistogram(v []int64) *TrafficRollupCreate {
+	_c.mutation.SetDurationHistogram(v)
+	return _c
+}
+
+// SetProxyID sets the "proxy" edge to the Proxy entity by ID.
+func (_c *TrafficRollupCreate) SetProxyID(id int) *TrafficRollupCreate {
+	_c.mutation.SetProxyID(id)
+	return _c
+}
+
+// SetProxy sets the "proxy" edge to the Proxy entity.
+func (_c *TrafficRollupCreate) SetProxy(v *Proxy) *TrafficRollupCreate {
+	return _c.SetProxyID(v.ID)
+}
+
+// Mutation returns the TrafficRollupMutation object of the builder.
+func (_c *TrafficRollupCreate) Mutation() *TrafficRollupMutation {
+	return _c.mutation
+}
+
+// Save creates the TrafficRollup in the database.
+func (_c *TrafficRollupCreate) Save(ctx context.Context) (*TrafficRollup, error) {
+	return withHooks(ctx, _c.sqlSave, _c.mutation, _c.hooks)
+}
+
+// SaveX calls Save and panics if Save returns an error.
+func (_c *TrafficRollupCreate) SaveX(ctx context.Context) *TrafficRollup {
+	v, err := _c.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (_c *TrafficRollupCreate) Exec(ctx context.Context) error {
+	_, err := _c.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_c *TrafficRollupCreate) ExecX(ctx context.Context) {
+	if err := _c.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (_c *TrafficRollupCreate) check() error {
+	if _, ok := _c.mutation.Bucket(); !ok {
+		return &ValidationError{Name: "bucket", err: errors.New(`ent: missing required field "TrafficRollup.bucket"`)}
+	}
+	if _, ok := _c.mutation.Host(); !ok {
+		return &ValidationError{Name: "host", err: errors.New(`ent: missing required field "TrafficRollup.host"`)}
+	}
+	if _, ok := _c.mutation.Method(); !ok {
+		return &ValidationError{Name: "method", err: errors.New(`ent: missing required field "TrafficRollup.method"`)}
+	}
+	if _, ok := _c.mutation.StatusCode(); !ok {
+		return &ValidationError{Name: "status_code", err: errors.New(`ent: missing required field "TrafficRollup.status_code"`)}
+	}
+	if _, ok := _c.mutation.Requests(); !ok {
+		return &ValidationError{Name: "requests", err: errors.New(`ent: missing required field "TrafficRollup.requests"`)}
+	}
+	if _, ok := _c.mutation.DurationSumMs(); !ok {
+		return &ValidationError{Name: "duration_sum_ms", err: errors.New(`ent: missing required field "TrafficRollup.duration_sum_ms"`)}
+	}
+	if _, ok := _c.mutation.DurationMaxMs(); !ok {
+		return &ValidationError{Name: "duration_max_ms", err: errors.New(`ent: missing required field "TrafficRollup.duration_max_ms"`)}
+	}
+	if _, ok := _c.mutation.DurationHistogram(); !ok {
+		return &ValidationError{Name: "duration_histogram", err: errors.New(`ent: missing required field "TrafficRollup.duration_histogram"`)}
+	}
+	if len(_c.mutation.ProxyIDs()) == 0 {
+		return &ValidationError{Name: "proxy", err: errors.New(`ent: missing required edge "TrafficRollup.proxy"`)}
+	}
+	return nil
+}
+
+func (_c *TrafficRollupCreate) sqlSave(ctx context.Context) (*TrafficRollup, error) {
+	if err := _c.check(); err != nil {
+		return nil, err
+	}
+	_node, _spec := _c.createSpec()
+	if err := sqlgraph.CreateNode(ctx, _c.driver, _spec); err != nil {
+		if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	id := _spec.ID.Value.(int64)
+	_node.ID = int(id)
+	_c.mutation.id = &_node.ID
+	_c.mutation.done = true
+	return _node, nil
+}
+
+func (_c *TrafficRollupCreate) createSpec() (*TrafficRollup, *sqlgraph.CreateSpec) {
+	var (
+		_node = &TrafficRollup{config: _c.config}
+		_spec = sqlgraph.NewCreateSpec(trafficrollup.Table, sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt))
+	)
+	if value, ok := _c.mutation.Bucket(); ok {
+		_spec.SetField(trafficrollup.FieldBucket, field.TypeTime, value)
+		_node.Bucket = value
+	}
+	if value, ok := _c.mutation.Host(); ok {
+		_spec.SetField(trafficrollup.FieldHost, field.TypeString, value)
+		_node.Host = value
+	}
+	if value, ok := _c.mutation.Method(); ok {
+		_spec.SetField(trafficrollup.FieldMethod, field.TypeString, value)
+		_node.Method = value
+	}
+	if value, ok := _c.mutation.StatusCode(); ok {
+		_spec.SetField(trafficrollup.FieldStatusCode, field.TypeInt, value)
+		_node.StatusCode = value
+	}
+	if value, ok := _c.mutation.Requests(); ok {
+		_spec.SetField(trafficrollup.FieldRequests, field.TypeInt64, value)
+		_node.Requests = value
+	}
+	if value, ok := _c.mutation.DurationSumMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationSumMs, field.TypeFloat64, value)
+		_node.DurationSumMs = value
+	}
+	if value, ok := _c.mutation.DurationMaxMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationMaxMs, field.TypeFloat64, value)
+		_node.DurationMaxMs = value
+	}
+	if value, ok := _c.mutation.DurationHistogram(); ok {
+		_spec.SetField(trafficrollup.FieldDurationHistogram, field.TypeJSON, value)
+		_node.DurationHistogram = value
+	}
+	if nodes := _c.mutation.ProxyIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.M2O,
+			Inverse: true,
+			Table:   trafficrollup.ProxyTable,
+			Columns: []string{trafficrollup.ProxyColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(proxy.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_node.proxy_rollups = &nodes[0]
+		_spec.Edges = append(_spec.Edges, edge)
+	}
+	return _node, _spec
+}
+
+// TrafficRollupCreateBulk is the builder for creating many TrafficRollup entities in bulk.
+type TrafficRollupCreateBulk struct {
+	config
+	err      error
+	builders []*TrafficRollupCreate
+}
+
+// Save creates the TrafficRollup entities in the database.
+func (_c *TrafficRollupCreateBulk) Save(ctx context.Context) ([]*TrafficRollup, error) {
+	if _c.err != nil {
+		return nil, _c.err
+	}
+	specs := make([]*sqlgraph.CreateSpec, len(_c.builders))
+	nodes := make([]*TrafficRollup, len(_c.builders))
+	mutators := make([]Mutator, len(_c.builders))
+	for i := range _c.builders {
+		func(i int, root context.Context) {
+			builder := _c.builders[i]
+			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
+				mutation, ok := m.(*TrafficRollupMutation)
+				if !ok {
+					return nil, fmt.Errorf("unexpected mutation type %T", m)
+				}
+				if err := builder.check(); err != nil {
+					return nil, err
+				}
+				builder.mutation = mutation
+				var err error
+				nodes[i], specs[i] = builder.createSpec()
+				if i < len(mutators)-1 {
+					_, err = mutators[i+1].Mutate(root, _c.builders[i+1].mutation)
+				} else {
+					spec := &sqlgraph.BatchCreateSpec{Nodes: specs}
+					// Invoke the actual operation on the latest mutation in the chain.
+					if err = sqlgraph.BatchCreate(ctx, _c.driver, spec); err != nil {
+						if sqlgraph.IsConstraintError(err) {
+							err = &ConstraintError{msg: err.Error(), wrap: err}
+						}
+					}
+				}
+				if err != nil {
+					return nil, err
+				}
+				mutation.id = &nodes[i].ID
+				if specs[i].ID.Value != nil {
+					id := specs[i].ID.Value.(int64)
+					nodes[i].ID = int(id)
+				}
+				mutation.done = true
+				return nodes[i], nil
+			})
+			for i := len(builder.hooks) - 1; i >= 0; i-- {
+				mut = builder.hooks[i](mut)
+			}
+			mutators[i] = mut
+		}(i, ctx)
+	}
+	if len(mutators) > 0 {
+		if _, err := mutators[0].Mutate(ctx, _c.builders[0].mutation); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (_c *TrafficRollupCreateBulk) SaveX(ctx context.Context) []*TrafficRollup {
+	v, err := _c.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (_c *TrafficRollupCreateBulk) Exec(ctx context.Context) error {
+	_, err := _c.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_c *TrafficRollupCreateBulk) ExecX(ctx context.Context) {
+	if err := _c.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
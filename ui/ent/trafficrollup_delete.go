@@ -0,0 +1,88 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
+)
+
+// TrafficRollupDelete is the builder for deleting a TrafficRollup entity.
+type TrafficRollupDelete struct {
+	config
+	hooks    []Hook
+	mutation *TrafficRollupMutation
+}
+
+// Where appends a list predicates to the TrafficRollupDelete builder.
+func (_d *TrafficRollupDelete) Where(ps ...predicate.TrafficRollup) *TrafficRollupDelete {
+	_d.mutation.Where(ps...)
+	return _d
+}
+
+// Exec executes the deletion query and returns how many vertices were deleted.
+func (_d *TrafficRollupDelete) Exec(ctx context.Context) (int, error) {
+	return withHooks(ctx, _d.sqlExec, _d.mutation, _d.hooks)
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_d *TrafficRollupDelete) ExecX(ctx context.Context) int {
+	n, err := _d.Exec(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func (_d *TrafficRollupDelete) sqlExec(ctx context.Context) (int, error) {
+	_spec := sqlgraph.NewDeleteSpec(trafficrollup.Table, sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt))
+	if ps := _d.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	affected, err := sqlgraph.DeleteNodes(ctx, _d.driver, _spec)
+	if err != nil && sqlgraph.IsConstraintError(err) {
+		err = &ConstraintError{msg: err.Error(), wrap: err}
+	}
+	_d.mutation.done = true
+	return affected, err
+}
+
+// TrafficRollupDeleteOne is the builder for deleting a single TrafficRollup entity.
+type TrafficRollupDeleteOne struct {
+	_d *TrafficRollupDelete
+}
+
+// Where appends a list predicates to the TrafficRollupDelete builder.
+func (_d *TrafficRollupDeleteOne) Where(ps ...predicate.TrafficRollup) *TrafficRollupDeleteOne {
+	_d._d.mutation.Where(ps...)
+	return _d
+}
+
+// Exec executes the deletion query.
+func (_d *TrafficRollupDeleteOne) Exec(ctx context.Context) error {
+	n, err := _d._d.Exec(ctx)
+	switch {
+	case err != nil:
+		return err
+	case n == 0:
+		return &NotFoundError{trafficrollup.Label}
+	default:
+		return nil
+	}
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_d *TrafficRollupDeleteOne) ExecX(ctx context.Context) {
+	if err := _d.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
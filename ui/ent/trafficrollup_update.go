@@ -0,0 +1,650 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/dialect/sql/sqljson"
+	"entgo.io/ent/schema/field"
+	"github.com/grokify/omniproxy/ui/ent/predicate"
+	"github.com/grokify/omniproxy/ui/ent/proxy"
+	"github.com/grokify/omniproxy/ui/ent/trafficrollup"
+)
+
+// TrafficRollupUpdate is the builder for updating TrafficRollup entities.
+type TrafficRollupUpdate struct {
+	config
+	hooks    []Hook
+	mutation *TrafficRollupMutation
+}
+
+// Where appends a list predicates to the TrafficRollupUpdate builder.
+func (_u *TrafficRollupUpdate) Where(ps ...predicate.TrafficRollup) *TrafficRollupUpdate {
+	_u.mutation.Where(ps...)
+	return _u
+}
+
+// SetBucket sets the "bucket" field.
+func (_u *TrafficRollupUpdate) SetBucket(v time.Time) *TrafficRollupUpdate {
+	_u.mutation.SetBucket(v)
+	return _u
+}
+
+// SetNillableBucket sets the "bucket" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableBucket(v *time.Time) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetBucket(*v)
+	}
+	return _u
+}
+
+// SetHost sets the "host" field.
+func (_u *TrafficRollupUpdate) SetHost(v string) *TrafficRollupUpdate {
+	_u.mutation.SetHost(v)
+	return _u
+}
+
+// SetNillableHost sets the "host" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableHost(v *string) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetHost(*v)
+	}
+	return _u
+}
+
+// SetMethod sets the "method" field.
+func (_u *TrafficRollupUpdate) SetMethod(v string) *TrafficRollupUpdate {
+	_u.mutation.SetMethod(v)
+	return _u
+}
+
+// SetNillableMethod sets the "method" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableMethod(v *string) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetMethod(*v)
+	}
+	return _u
+}
+
+// SetStatusCode sets the "status_code" field.
+func (_u *TrafficRollupUpdate) SetStatusCode(v int) *TrafficRollupUpdate {
+	_u.mutation.ResetStatusCode()
+	_u.mutation.SetStatusCode(v)
+	return _u
+}
+
+// SetNillableStatusCode sets the "status_code" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableStatusCode(v *int) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetStatusCode(*v)
+	}
+	return _u
+}
+
+// AddStatusCode adds value to the "status_code" field.
+func (_u *TrafficRollupUpdate) AddStatusCode(v int) *TrafficRollupUpdate {
+	_u.mutation.AddStatusCode(v)
+	return _u
+}
+
+// SetRequests sets the "requests" field.
+func (_u *TrafficRollupUpdate) SetRequests(v int64) *TrafficRollupUpdate {
+	_u.mutation.ResetRequests()
+	_u.mutation.SetRequests(v)
+	return _u
+}
+
+// SetNillableRequests sets the "requests" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableRequests(v *int64) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetRequests(*v)
+	}
+	return _u
+}
+
+// AddRequests adds value to the "requests" field.
+func (_u *TrafficRollupUpdate) AddRequests(v int64) *TrafficRollupUpdate {
+	_u.mutation.AddRequests(v)
+	return _u
+}
+
+// SetDurationSumMs sets the "duration_sum_ms" field.
+func (_u *TrafficRollupUpdate) SetDurationSumMs(v float64) *TrafficRollupUpdate {
+	_u.mutation.ResetDurationSumMs()
+	_u.mutation.SetDurationSumMs(v)
+	return _u
+}
+
+// SetNillableDurationSumMs sets the "duration_sum_ms" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableDurationSumMs(v *float64) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetDurationSumMs(*v)
+	}
+	return _u
+}
+
+// AddDurationSumMs adds value to the "duration_sum_ms" field.
+func (_u *TrafficRollupUpdate) AddDurationSumMs(v float64) *TrafficRollupUpdate {
+	_u.mutation.AddDurationSumMs(v)
+	return _u
+}
+
+// SetDurationMaxMs sets the "duration_max_ms" field.
+func (_u *TrafficRollupUpdate) SetDurationMaxMs(v float64) *TrafficRollupUpdate {
+	_u.mutation.ResetDurationMaxMs()
+	_u.mutation.SetDurationMaxMs(v)
+	return _u
+}
+
+// SetNillableDurationMaxMs sets the "duration_max_ms" field if the given value is not nil.
+func (_u *TrafficRollupUpdate) SetNillableDurationMaxMs(v *float64) *TrafficRollupUpdate {
+	if v != nil {
+		_u.SetDurationMaxMs(*v)
+	}
+	return _u
+}
+
+// AddDurationMaxMs adds value to the "duration_max_ms" field.
+func (_u *TrafficRollupUpdate) AddDurationMaxMs(v float64) *TrafficRollupUpdate {
+	_u.mutation.AddDurationMaxMs(v)
+	return _u
+}
+
+// SetDurationHistogram sets the "duration_histogram" field.
+func (_u *TrafficRollupUpdate) SetDurationHistogram(v []int64) *TrafficRollupUpdate {
+	_u.mutation.SetDurationHistogram(v)
+	return _u
+}
+
+// AppendDurationHistogram appends value to the "duration_histogram" field.
+func (_u *TrafficRollupUpdate) AppendDurationHistogram(v []int64) *TrafficRollupUpdate {
+	_u.mutation.AppendDurationHistogram(v)
+	return _u
+}
+
+// SetProxyID sets the "proxy" edge to the Proxy entity by ID.
+func (_u *TrafficRollupUpdate) SetProxyID(id int) *TrafficRollupUpdate {
+	_u.mutation.SetProxyID(id)
+	return _u
+}
+
+// SetProxy sets the "proxy" edge to the Proxy entity.
+func (_u *TrafficRollupUpdate) SetProxy(v *Proxy) *TrafficRollupUpdate {
+	return _u.SetProxyID(v.ID)
+}
+
+// Mutation returns the TrafficRollupMutation object of the builder.
+func (_u *TrafficRollupUpdate) Mutation() *TrafficRollupMutation {
+	return _u.mutation
+}
+
+// ClearProxy clears the "proxy" edge to the Proxy entity.
+func (_u *TrafficRollupUpdate) ClearProxy() *TrafficRollupUpdate {
+	_u.mutation.ClearProxy()
+	return _u
+}
+
+// Save executes the query and returns the number of nodes affected by the update operation.
+func (_u *TrafficRollupUpdate) Save(ctx context.Context) (int, error) {
+	return withHooks(ctx, _u.sqlSave, _u.mutation, _u.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (_u *TrafficRollupUpdate) SaveX(ctx context.Context) int {
+	affected, err := _u.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return affected
+}
+
+// Exec executes the query.
+func (_u *TrafficRollupUpdate) Exec(ctx context.Context) error {
+	_, err := _u.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_u *TrafficRollupUpdate) ExecX(ctx context.Context) {
+	if err := _u.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (_u *TrafficRollupUpdate) check() error {
+	if _u.mutation.ProxyCleared() && len(_u.mutation.ProxyIDs()) > 0 {
+		return errors.New(`ent: clearing a required unique edge "TrafficRollup.proxy"`)
+	}
+	return nil
+}
+
+func (_u *TrafficRollupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
+	if err := _u.check(); err != nil {
+		return _node, err
+	}
+	_spec := sqlgraph.NewUpdateSpec(trafficrollup.Table, trafficrollup.Columns, sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt))
+	if ps := _u.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := _u.mutation.Bucket(); ok {
+		_spec.SetField(trafficrollup.FieldBucket, field.TypeTime, value)
+	}
+	if value, ok := _u.mutation.Host(); ok {
+		_spec.SetField(trafficrollup.FieldHost, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.Method(); ok {
+		_spec.SetField(trafficrollup.FieldMethod, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.StatusCode(); ok {
+		_spec.SetField(trafficrollup.FieldStatusCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedStatusCode(); ok {
+		_spec.AddField(trafficrollup.FieldStatusCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.Requests(); ok {
+		_spec.SetField(trafficrollup.FieldRequests, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequests(); ok {
+		_spec.AddField(trafficrollup.FieldRequests, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.DurationSumMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationSumMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedDurationSumMs(); ok {
+		_spec.AddField(trafficrollup.FieldDurationSumMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.DurationMaxMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationMaxMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedDurationMaxMs(); ok {
+		_spec.AddField(trafficrollup.FieldDurationMaxMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.DurationHistogram(); ok {
+		_spec.SetField(trafficrollup.FieldDurationHistogram, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedDurationHistogram(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, trafficrollup.FieldDurationHistogram, value)
+		})
+	}
+	if _u.mutation.ProxyCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.M2O,
+			Inverse: true,
+			Table:   trafficrollup.ProxyTable,
+			Columns: []string{trafficrollup.ProxyColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(proxy.FieldID, field.TypeInt),
+			},
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.ProxyIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.M2O,
+			Inverse: true,
+			Table:   trafficrollup.ProxyTable,
+			Columns: []string{trafficrollup.ProxyColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(proxy.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Add = append(_spec.Edges.Add, edge)
+	}
+	if _node, err = sqlgraph.UpdateNodes(ctx, _u.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{trafficrollup.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return 0, err
+	}
+	_u.mutation.done = true
+	return _node, nil
+}
+
+// TrafficRollupUpdateOne is the builder for updating a single TrafficRollup entity.
+type TrafficRollupUpdateOne struct {
+	config
+	fields   []string
+	hooks    []Hook
+	mutation *TrafficRollupMutation
+}
+
+// SetBucket sets the "bucket" field.
+func (_u *TrafficRollupUpdateOne) SetBucket(v time.Time) *TrafficRollupUpdateOne {
+	_u.mutation.SetBucket(v)
+	return _u
+}
+
+// SetNillableBucket sets the "bucket" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableBucket(v *time.Time) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetBucket(*v)
+	}
+	return _u
+}
+
+// SetHost sets the "host" field.
+func (_u *TrafficRollupUpdateOne) SetHost(v string) *TrafficRollupUpdateOne {
+	_u.mutation.SetHost(v)
+	return _u
+}
+
+// SetNillableHost sets the "host" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableHost(v *string) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetHost(*v)
+	}
+	return _u
+}
+
+// SetMethod sets the "method" field.
+func (_u *TrafficRollupUpdateOne) SetMethod(v string) *TrafficRollupUpdateOne {
+	_u.mutation.SetMethod(v)
+	return _u
+}
+
+// SetNillableMethod sets the "method" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableMethod(v *string) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetMethod(*v)
+	}
+	return _u
+}
+
+// SetStatusCode sets the "status_code" field.
+func (_u *TrafficRollupUpdateOne) SetStatusCode(v int) *TrafficRollupUpdateOne {
+	_u.mutation.ResetStatusCode()
+	_u.mutation.SetStatusCode(v)
+	return _u
+}
+
+// SetNillableStatusCode sets the "status_code" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableStatusCode(v *int) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetStatusCode(*v)
+	}
+	return _u
+}
+
+// AddStatusCode adds value to the "status_code" field.
+func (_u *TrafficRollupUpdateOne) AddStatusCode(v int) *TrafficRollupUpdateOne {
+	_u.mutation.AddStatusCode(v)
+	return _u
+}
+
+// SetRequests sets the "requests" field.
+func (_u *TrafficRollupUpdateOne) SetRequests(v int64) *TrafficRollupUpdateOne {
+	_u.mutation.ResetRequests()
+	_u.mutation.SetRequests(v)
+	return _u
+}
+
+// SetNillableRequests sets the "requests" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableRequests(v *int64) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetRequests(*v)
+	}
+	return _u
+}
+
+// AddRequests adds value to the "requests" field.
+func (_u *TrafficRollupUpdateOne) AddRequests(v int64) *TrafficRollupUpdateOne {
+	_u.mutation.AddRequests(v)
+	return _u
+}
+
+// SetDurationSumMs sets the "duration_sum_ms" field.
+func (_u *TrafficRollupUpdateOne) SetDurationSumMs(v float64) *TrafficRollupUpdateOne {
+	_u.mutation.ResetDurationSumMs()
+	_u.mutation.SetDurationSumMs(v)
+	return _u
+}
+
+// SetNillableDurationSumMs sets the "duration_sum_ms" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableDurationSumMs(v *float64) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetDurationSumMs(*v)
+	}
+	return _u
+}
+
+// AddDurationSumMs adds value to the "duration_sum_ms" field.
+func (_u *TrafficRollupUpdateOne) AddDurationSumMs(v float64) *TrafficRollupUpdateOne {
+	_u.mutation.AddDurationSumMs(v)
+	return _u
+}
+
+// SetDurationMaxMs sets the "duration_max_ms" field.
+func (_u *TrafficRollupUpdateOne) SetDurationMaxMs(v float64) *TrafficRollupUpdateOne {
+	_u.mutation.ResetDurationMaxMs()
+	_u.mutation.SetDurationMaxMs(v)
+	return _u
+}
+
+// SetNillableDurationMaxMs sets the "duration_max_ms" field if the given value is not nil.
+func (_u *TrafficRollupUpdateOne) SetNillableDurationMaxMs(v *float64) *TrafficRollupUpdateOne {
+	if v != nil {
+		_u.SetDurationMaxMs(*v)
+	}
+	return _u
+}
+
+// AddDurationMaxMs adds value to the "duration_max_ms" field.
+func (_u *TrafficRollupUpdateOne) AddDurationMaxMs(v float64) *TrafficRollupUpdateOne {
+	_u.mutation.AddDurationMaxMs(v)
+	return _u
+}
+
+// SetDurationHistogram sets the "duration_histogram" field.
+func (_u *TrafficRollupUpdateOne) SetDurationHistogram(v []int64) *TrafficRollupUpdateOne {
+	_u.mutation.SetDurationHistogram(v)
+	return _u
+}
+
+// AppendDurationHistogram appends value to the "duration_histogram" field.
+func (_u *TrafficRollupUpdateOne) AppendDurationHistogram(v []int64) *TrafficRollupUpdateOne {
+	_u.mutation.AppendDurationHistogram(v)
+	return _u
+}
+
+// SetProxyID sets the "proxy" edge to the Proxy entity by ID.
+func (_u *TrafficRollupUpdateOne) SetProxyID(id int) *TrafficRollupUpdateOne {
+	_u.mutation.SetProxyID(id)
+	return _u
+}
+
+// SetProxy sets the "proxy" edge to the Proxy entity.
+func (_u *TrafficRollupUpdateOne) SetProxy(v *Proxy) *TrafficRollupUpdateOne {
+	return _u.SetProxyID(v.ID)
+}
+
+// Mutation returns the TrafficRollupMutation object of the builder.
+func (_u *TrafficRollupUpdateOne) Mutation() *TrafficRollupMutation {
+	return _u.mutation
+}
+
+// ClearProxy clears the "proxy" edge to the Proxy entity.
+func (_u *TrafficRollupUpdateOne) ClearProxy() *TrafficRollupUpdateOne {
+	_u.mutation.ClearProxy()
+	return _u
+}
+
+// Where appends a list predicates to the TrafficRollupUpdate builder.
+func (_u *TrafficRollupUpdateOne) Where(ps ...predicate.TrafficRollup) *TrafficRollupUpdateOne {
+	_u.mutation.Where(ps...)
+	return _u
+}
+
+// Select allows selecting one or more fields (columns) of the returned entity.
+// The default is selecting all fields defined in the entity schema.
+func (_u *TrafficRollupUpdateOne) Select(field string, fields ...string) *TrafficRollupUpdateOne {
+	_u.fields = append([]string{field}, fields...)
+	return _u
+}
+
+// Save executes the query and returns the updated TrafficRollup entity.
+func (_u *TrafficRollupUpdateOne) Save(ctx context.Context) (*TrafficRollup, error) {
+	return withHooks(ctx, _u.sqlSave, _u.mutation, _u.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (_u *TrafficRollupUpdateOne) SaveX(ctx context.Context) *TrafficRollup {
+	node, err := _u.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// Exec executes the query on the entity.
+func (_u *TrafficRollupUpdateOne) Exec(ctx context.Context) error {
+	_, err := _u.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (_u *TrafficRollupUpdateOne) ExecX(ctx context.Context) {
+	if err := _u.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (_u *TrafficRollupUpdateOne) check() error {
+	if _u.mutation.ProxyCleared() && len(_u.mutation.ProxyIDs()) > 0 {
+		return errors.New(`ent: clearing a required unique edge "TrafficRollup.proxy"`)
+	}
+	return nil
+}
+
+func (_u *TrafficRollupUpdateOne) sqlSave(ctx context.Context) (_node *TrafficRollup, err error) {
+	if err := _u.check(); err != nil {
+		return _node, err
+	}
+	_spec := sqlgraph.NewUpdateSpec(trafficrollup.Table, trafficrollup.Columns, sqlgraph.NewFieldSpec(trafficrollup.FieldID, field.TypeInt))
+	id, ok := _u.mutation.ID()
+	if !ok {
+		return nil, &ValidationError{Name: "id", err: errors.New(`ent: missing "TrafficRollup.id" for update`)}
+	}
+	_spec.Node.ID.Value = id
+	if fields := _u.fields; len(fields) > 0 {
+		_spec.Node.Columns = make([]string, 0, len(fields))
+		_spec.Node.Columns = append(_spec.Node.Columns, trafficrollup.FieldID)
+		for _, f := range fields {
+			if !trafficrollup.ValidColumn(f) {
+				return nil, &ValidationError{Name: f, err: fmt.Errorf("ent: invalid field %q for query", f)}
+			}
+			if f != trafficrollup.FieldID {
+				_spec.Node.Columns = append(_spec.Node.Columns, f)
+			}
+		}
+	}
+	if ps := _u.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := _u.mutation.Bucket(); ok {
+		_spec.SetField(trafficrollup.FieldBucket, field.TypeTime, value)
+	}
+	if value, ok := _u.mutation.Host(); ok {
+		_spec.SetField(trafficrollup.FieldHost, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.Method(); ok {
+		_spec.SetField(trafficrollup.FieldMethod, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.StatusCode(); ok {
+		_spec.SetField(trafficrollup.FieldStatusCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedStatusCode(); ok {
+		_spec.AddField(trafficrollup.FieldStatusCode, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.Requests(); ok {
+		_spec.SetField(trafficrollup.FieldRequests, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequests(); ok {
+		_spec.AddField(trafficrollup.FieldRequests, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.DurationSumMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationSumMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedDurationSumMs(); ok {
+		_spec.AddField(trafficrollup.FieldDurationSumMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.DurationMaxMs(); ok {
+		_spec.SetField(trafficrollup.FieldDurationMaxMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedDurationMaxMs(); ok {
+		_spec.AddField(trafficrollup.FieldDurationMaxMs, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.DurationHistogram(); ok {
+		_spec.SetField(trafficrollup.FieldDurationHistogram, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedDurationHistogram(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, trafficrollup.FieldDurationHistogram, value)
+		})
+	}
+	if _u.mutation.ProxyCleared() {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.M2O,
+			Inverse: true,
+			Table:   trafficrollup.ProxyTable,
+			Columns: []string{trafficrollup.ProxyColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(proxy.FieldID, field.TypeInt),
+			},
+		}
+		_spec.Edges.Clear = append(_spec.Edges.Clear, edge)
+	}
+	if nodes := _u.mutation.ProxyIDs(); len(nodes) > 0 {
+		edge := &sqlgraph.EdgeSpec{
+			Rel:     sqlgraph.M2O,
+			Inverse: true,
+			Table:   trafficrollup.ProxyTable,
+			Columns: []string{trafficrollup.ProxyColumn},
+			Bidi:    false,
+			Target: &sqlgraph.EdgeTarget{
+				IDSpec: sqlgraph.NewFieldSpec(proxy.FieldID, field.TypeInt),
+			},
+		}
+		for _, k := range nodes {
+			edge.Target.Nodes = append(edge.Target.Nodes, k)
+		}
+		_spec.Edges.Add = append(_spec.Edges.Add, edge)
+	}
+	_node = &TrafficRollup{config: _u.config}
+	_spec.Assign = _node.assignValues
+	_spec.ScanValues = _node.scanValues
+	if err = sqlgraph.UpdateNode(ctx, _u.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{trafficrollup.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	_u.mutation.done = true
+	return _node, nil
+}
@@ -20,6 +20,8 @@ type Tx struct {
 	Session *SessionClient
 	// Traffic is the client for interacting with the Traffic builders.
 	Traffic *TrafficClient
+	// TrafficRollup is the client for interacting with the TrafficRollup builders.
+	TrafficRollup *TrafficRollupClient
 	// User is the client for interacting with the User builders.
 	User *UserClient
 
@@ -157,6 +159,7 @@ func (tx *Tx) init() {
 	tx.Proxy = NewProxyClient(tx.config)
 	tx.Session = NewSessionClient(tx.config)
 	tx.Traffic = NewTrafficClient(tx.config)
+	tx.TrafficRollup = NewTrafficRollupClient(tx.config)
 	tx.User = NewUserClient(tx.config)
 }
 
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"time"
 
@@ -53,22 +52,15 @@ func (h *TrafficHandler) Store(ctx context.Context, rec *capture.Record) error {
 		SetDurationMs(rec.DurationMs).
 		SetStatusCode(rec.Response.Status)
 
-	// Query string from map
+	// Query string, in its original order
 	if len(rec.Request.Query) > 0 {
-		vals := url.Values{}
-		for k, v := range rec.Request.Query {
-			vals.Set(k, v)
-		}
-		create.SetQuery(vals.Encode())
+		create.SetQuery(rec.Request.Query.Encode())
 	}
 
-	// Convert headers from map[string]string to map[string][]string
+	// Headers by lowercase name, plus their names in order
 	if len(rec.Request.Headers) > 0 {
-		headers := make(map[string][]string)
-		for k, v := range rec.Request.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetRequestHeaders(headers)
+		create.SetRequestHeaders(rec.Request.Headers.Multi())
+		create.SetRequestHeaderNames(rec.Request.Headers.Names())
 	}
 
 	// Request body - handle interface{} type
@@ -84,6 +76,12 @@ func (h *TrafficHandler) Store(ctx context.Context, rec *capture.Record) error {
 	if rec.Request.IsBinary {
 		create.SetRequestIsBinary(true)
 	}
+	if rec.Request.Truncated {
+		create.SetRequestBodyTruncated(true)
+	}
+	if rec.Request.DecodedSize > 0 {
+		create.SetRequestDecodedSize(rec.Request.DecodedSize)
+	}
 	if rec.Request.ContentType != "" {
 		create.SetContentType(rec.Request.ContentType)
 	}
@@ -93,11 +91,8 @@ func (h *TrafficHandler) Store(ctx context.Context, rec *capture.Record) error {
 		create.SetStatusText(rec.Response.StatusText)
 	}
 	if len(rec.Response.Headers) > 0 {
-		headers := make(map[string][]string)
-		for k, v := range rec.Response.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetResponseHeaders(headers)
+		create.SetResponseHeaders(rec.Response.Headers.Multi())
+		create.SetResponseHeaderNames(rec.Response.Headers.Names())
 	}
 	if rec.Response.Body != nil {
 		body := convertBodyToBytes(rec.Response.Body)
@@ -111,6 +106,15 @@ func (h *TrafficHandler) Store(ctx context.Context, rec *capture.Record) error {
 	if rec.Response.IsBinary {
 		create.SetResponseIsBinary(true)
 	}
+	if rec.Response.Truncated {
+		create.SetResponseBodyTruncated(true)
+	}
+	if rec.Response.DecodedSize > 0 {
+		create.SetResponseDecodedSize(rec.Response.DecodedSize)
+	}
+	if rec.Response.Events != nil {
+		create.SetResponseEvents(rec.Response.Events)
+	}
 	if rec.Response.ContentType != "" {
 		create.SetResponseContentType(rec.Response.ContentType)
 	}
@@ -178,22 +182,15 @@ func (h *TrafficHandler) storeInTx(ctx context.Context, tx *ent.Tx, rec *capture
 		SetDurationMs(rec.DurationMs).
 		SetStatusCode(rec.Response.Status)
 
-	// Query string from map
+	// Query string, in its original order
 	if len(rec.Request.Query) > 0 {
-		vals := url.Values{}
-		for k, v := range rec.Request.Query {
-			vals.Set(k, v)
-		}
-		create.SetQuery(vals.Encode())
+		create.SetQuery(rec.Request.Query.Encode())
 	}
 
-	// Convert headers
+	// Headers by lowercase name, plus their names in order
 	if len(rec.Request.Headers) > 0 {
-		headers := make(map[string][]string)
-		for k, v := range rec.Request.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetRequestHeaders(headers)
+		create.SetRequestHeaders(rec.Request.Headers.Multi())
+		create.SetRequestHeaderNames(rec.Request.Headers.Names())
 	}
 
 	if rec.Request.Body != nil {
@@ -208,6 +205,12 @@ func (h *TrafficHandler) storeInTx(ctx context.Context, tx *ent.Tx, rec *capture
 	if rec.Request.IsBinary {
 		create.SetRequestIsBinary(true)
 	}
+	if rec.Request.Truncated {
+		create.SetRequestBodyTruncated(true)
+	}
+	if rec.Request.DecodedSize > 0 {
+		create.SetRequestDecodedSize(rec.Request.DecodedSize)
+	}
 	if rec.Request.ContentType != "" {
 		create.SetContentType(rec.Request.ContentType)
 	}
@@ -217,11 +220,8 @@ func (h *TrafficHandler) storeInTx(ctx context.Context, tx *ent.Tx, rec *capture
 		create.SetStatusText(rec.Response.StatusText)
 	}
 	if len(rec.Response.Headers) > 0 {
-		headers := make(map[string][]string)
-		for k, v := range rec.Response.Headers {
-			headers[k] = []string{v}
-		}
-		create.SetResponseHeaders(headers)
+		create.SetResponseHeaders(rec.Response.Headers.Multi())
+		create.SetResponseHeaderNames(rec.Response.Headers.Names())
 	}
 	if rec.Response.Body != nil {
 		body := convertBodyToBytes(rec.Response.Body)
@@ -235,6 +235,15 @@ func (h *TrafficHandler) storeInTx(ctx context.Context, tx *ent.Tx, rec *capture
 	if rec.Response.IsBinary {
 		create.SetResponseIsBinary(true)
 	}
+	if rec.Response.Truncated {
+		create.SetResponseBodyTruncated(true)
+	}
+	if rec.Response.DecodedSize > 0 {
+		create.SetResponseDecodedSize(rec.Response.DecodedSize)
+	}
+	if rec.Response.Events != nil {
+		create.SetResponseEvents(rec.Response.Events)
+	}
 	if rec.Response.ContentType != "" {
 		create.SetResponseContentType(rec.Response.ContentType)
 	}